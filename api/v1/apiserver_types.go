@@ -18,11 +18,111 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // APIServerSpec defines the desired state of Tigera API server.
 type APIServerSpec struct {
+	// Replicas is the number of replicas of the API server. If not specified, the ControlPlaneReplicas of the
+	// Installation is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources are the compute resources of the API server container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// QueryServerResources are the compute resources of the query server container. Only used by Calico Enterprise.
+	// +optional
+	QueryServerResources *corev1.ResourceRequirements `json:"queryServerResources,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget of the API server. It takes precedence over the
+	// ControlPlaneAvailability of the Installation.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// TLS configures the TLS versions and cipher suites that the API server accepts.
+	// +optional
+	TLS *APIServerTLS `json:"tls,omitempty"`
+
+	// HostNetwork determines whether the API server runs in the host network namespace. If not specified, the API
+	// server only uses the host network when the platform requires it, e.g. on EKS with the Calico CNI. It cannot be
+	// false when the platform requires the host network.
+	// +optional
+	HostNetwork *bool `json:"hostNetwork,omitempty"`
+
+	// Port is the port that the API server listens on. It must not clash with a port of the node when the API server
+	// uses the host network.
+	// Default: 5443
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+
+	// Audit configures the audit logging of changes to projectcalico.org resources. Only used by Calico Enterprise.
+	// +optional
+	Audit *APIServerAudit `json:"audit,omitempty"`
+
+	// PacketCaptureAutoscaling, if specified, scales the replicas of the packet capture API with a
+	// HorizontalPodAutoscaler. Only applies to Calico Enterprise.
+	// +optional
+	PacketCaptureAutoscaling *Autoscaling `json:"packetCaptureAutoscaling,omitempty"`
+
+	// ComponentNetworkPolicy controls whether the operator renders Calico network policies in the allow-tigera tier
+	// for the components managed by this APIServer. Of these, only the packet capture API of Calico Enterprise has such
+	// policies. If not specified, the value from the Installation is used.
+	// +optional
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	ComponentNetworkPolicy *ComponentNetworkPolicyType `json:"componentNetworkPolicy,omitempty"`
+}
+
+// TLSVersion is a version of TLS.
+// +kubebuilder:validation:Enum=VersionTLS12;VersionTLS13
+type TLSVersion string
+
+const (
+	TLSVersion12 TLSVersion = "VersionTLS12"
+	TLSVersion13 TLSVersion = "VersionTLS13"
+)
+
+// APIServerTLS configures the TLS of the API server.
+type APIServerTLS struct {
+	// MinVersion is the minimum version of TLS that the API server accepts.
+	// Default: VersionTLS12
+	// +optional
+	MinVersion *TLSVersion `json:"minVersion,omitempty"`
+
+	// CipherSuites are the cipher suites that the API server accepts for TLS 1.2, by their IANA names, e.g.
+	// TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. The cipher suites of TLS 1.3 are not configurable. If not specified,
+	// the default cipher suites of the API server are used.
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+}
+
+// APIServerAuditLevel is the level at which requests are audit logged.
+// +kubebuilder:validation:Enum=None;Metadata;Request;RequestResponse
+type APIServerAuditLevel string
+
+const (
+	APIServerAuditLevelNone            APIServerAuditLevel = "None"
+	APIServerAuditLevelMetadata        APIServerAuditLevel = "Metadata"
+	APIServerAuditLevelRequest         APIServerAuditLevel = "Request"
+	APIServerAuditLevelRequestResponse APIServerAuditLevel = "RequestResponse"
+)
+
+// APIServerAudit configures the audit logging of the API server.
+type APIServerAudit struct {
+	// Level is the level at which changes to the resources are logged. None disables the audit logging.
+	// Default: RequestResponse
+	// +optional
+	Level *APIServerAuditLevel `json:"level,omitempty"`
+
+	// Resources are the projectcalico.org resources whose changes are logged, e.g. globalnetworkpolicies. If not
+	// specified, the changes to policies, tiers, network sets and host endpoints are logged.
+	// +optional
+	Resources []string `json:"resources,omitempty"`
 }
 
 // APIServerStatus defines the observed state of Tigera API server.
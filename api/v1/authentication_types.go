@@ -58,6 +58,16 @@ type AuthenticationSpec struct {
 	// LDAP contains the configuration needed to setup LDAP authentication.
 	// +optional
 	LDAP *AuthenticationLDAP `json:"ldap,omitempty"`
+
+	// ComponentNetworkPolicy controls whether the operator renders Calico network policies for the Dex identity provider.
+	// If not specified, the value from the Installation is used.
+	// +optional
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	ComponentNetworkPolicy *ComponentNetworkPolicyType `json:"componentNetworkPolicy,omitempty"`
+
+	// DexAutoscaling, if specified, scales the replicas of the Dex identity provider with a HorizontalPodAutoscaler.
+	// +optional
+	DexAutoscaling *Autoscaling `json:"dexAutoscaling,omitempty"`
 }
 
 // AuthenticationStatus defines the observed state of Authentication
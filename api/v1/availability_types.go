@@ -0,0 +1,81 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ControlPlaneComponentName is the name of a control plane component whose availability can be configured.
+// +kubebuilder:validation:Enum=APIServer;Manager;KubeControllers;ESGateway;ComplianceServer;Guardian;Dex;PacketCapture
+type ControlPlaneComponentName string
+
+const (
+	ControlPlaneComponentAPIServer        ControlPlaneComponentName = "APIServer"
+	ControlPlaneComponentManager          ControlPlaneComponentName = "Manager"
+	ControlPlaneComponentKubeControllers  ControlPlaneComponentName = "KubeControllers"
+	ControlPlaneComponentESGateway        ControlPlaneComponentName = "ESGateway"
+	ControlPlaneComponentComplianceServer ControlPlaneComponentName = "ComplianceServer"
+	ControlPlaneComponentGuardian         ControlPlaneComponentName = "Guardian"
+	ControlPlaneComponentDex              ControlPlaneComponentName = "Dex"
+	ControlPlaneComponentPacketCapture    ControlPlaneComponentName = "PacketCapture"
+)
+
+// ControlPlaneAvailabilityMode determines whether the operator protects the availability of a control plane component.
+// +kubebuilder:validation:Enum=Enabled;Disabled
+type ControlPlaneAvailabilityMode string
+
+const (
+	ControlPlaneAvailabilityEnabled  ControlPlaneAvailabilityMode = "Enabled"
+	ControlPlaneAvailabilityDisabled ControlPlaneAvailabilityMode = "Disabled"
+)
+
+// ControlPlaneAvailability configures how a control plane component with more than one replica remains available
+// during node drains and zone outages. By default, the operator creates a PodDisruptionBudget that allows one pod of
+// the component to be unavailable, and spreads the pods of the component across zones where possible.
+type ControlPlaneAvailability struct {
+	// ComponentName is the name of the component.
+	ComponentName ControlPlaneComponentName `json:"componentName"`
+
+	// Mode determines whether the operator creates a PodDisruptionBudget and topology spread constraints for the
+	// component.
+	// Default: Enabled
+	// +optional
+	Mode *ControlPlaneAvailabilityMode `json:"mode,omitempty"`
+
+	// PodDisruptionBudget overrides the budget of the PodDisruptionBudget of the component.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+
+	// TopologySpreadConstraints override the default constraint, which spreads the pods of the component across
+	// zones. The pods of the component are selected by constraints that do not specify a label selector.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+}
+
+// PodDisruptionBudgetSpec is the disruption budget of the pods of a component. Only one of MinAvailable and
+// MaxUnavailable may be specified.
+type PodDisruptionBudgetSpec struct {
+	// MinAvailable is the number or percentage of pods that must remain available during a voluntary disruption.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number or percentage of pods that may be unavailable during a voluntary disruption.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
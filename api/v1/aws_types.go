@@ -0,0 +1,55 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// AWSSecurityGroups configures the AWS security groups of the nodes of the cluster, whose rules the operator manages.
+// Each of the security groups allows the traffic that Calico needs from all of the security groups: BGP when it is
+// enabled, IP-in-IP or VXLAN when an IP pool uses them, Typha, and WireGuard when it is enabled in the default
+// FelixConfiguration. The operator adds back rules that are removed, but does not remove rules that are no longer
+// needed.
+type AWSSecurityGroups struct {
+	// Region is the AWS region of the security groups.
+	// Default: the region of the instance that the operator runs on.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// VPCID is the ID of the VPC that security groups are selected from by their tags.
+	// Default: the VPC of the instance that the operator runs on.
+	// +optional
+	VPCID string `json:"vpcID,omitempty"`
+
+	// SecurityGroupIDs are the IDs of security groups.
+	// +optional
+	SecurityGroupIDs []string `json:"securityGroupIDs,omitempty"`
+
+	// SecurityGroupSelectors select security groups by their tags.
+	// +optional
+	SecurityGroupSelectors []AWSSecurityGroupSelector `json:"securityGroupSelectors,omitempty"`
+
+	// CredentialsSecretName is the name of a secret in the operator namespace with the aws_access_key_id and
+	// aws_secret_access_key of the credentials that the security groups are managed with. If not specified, the
+	// default credentials of the AWS SDK are used, e.g. those of an IAM role for the service account of the operator.
+	// +optional
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+}
+
+// AWSSecurityGroupSelector selects the security groups that have all of its tags.
+type AWSSecurityGroupSelector struct {
+	// Tags are the keys and values of the tags of the security groups. The values may contain the * and ? wildcards,
+	// e.g. a Name of *-worker-sg.
+	Tags map[string]string `json:"tags"`
+}
@@ -17,11 +17,215 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // ComplianceSpec defines the desired state of Tigera compliance reporting capabilities.
 type ComplianceSpec struct {
+	// Reports are installed as GlobalReports, which generate compliance reports on a schedule.
+	// +optional
+	Reports []ComplianceReport `json:"reports,omitempty"`
+
+	// ReportTypes are installed as GlobalReportTypes alongside the built-in inventory, network-access, policy-audit
+	// and cis-benchmark report types. Their names must not clash with the names of the built-in report types.
+	// +optional
+	ReportTypes []ComplianceReportType `json:"reportTypes,omitempty"`
+
+	// Snapshotter configures how often the snapshotter records the configuration of the cluster.
+	// +optional
+	Snapshotter *ComplianceSnapshotter `json:"snapshotter,omitempty"`
+
+	// Benchmarker configures the nodes that the CIS benchmarker runs on.
+	// +optional
+	Benchmarker *ComplianceBenchmarker `json:"benchmarker,omitempty"`
+
+	// ReportRetention is the number of days that archived compliance reports are kept. If set, it takes precedence
+	// over retention.complianceReports of the LogStorage.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ReportRetention *int32 `json:"reportRetention,omitempty"`
+
+	// ReportExport configures the export of finished compliance reports to object storage. Each report is exported
+	// once, as the files of its download templates along with a SHA256SUMS file of their hashes.
+	// Not supported on managed clusters, whose reports are kept in the management cluster.
+	// +optional
+	ReportExport *ComplianceReportExport `json:"reportExport,omitempty"`
+
+	// ComponentNetworkPolicy controls whether the operator renders Calico network policies for the compliance components.
+	// If not specified, the value from the Installation is used.
+	// +optional
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	ComponentNetworkPolicy *ComponentNetworkPolicyType `json:"componentNetworkPolicy,omitempty"`
+
+	// LogLevel is the log level of the compliance components. It overrides the log level of the Installation.
+	// +optional
+	// +kubebuilder:validation:Enum=Error;Warn;Info;Debug
+	LogLevel *LogLevel `json:"logLevel,omitempty"`
+
+	// ServerAutoscaling, if specified, scales the replicas of the compliance server with a HorizontalPodAutoscaler.
+	// +optional
+	ServerAutoscaling *Autoscaling `json:"serverAutoscaling,omitempty"`
+}
+
+// ComplianceReport is a compliance report that is generated on a schedule.
+type ComplianceReport struct {
+	// Name is the name of the GlobalReport.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// ReportType is the name of a built-in report type or of one of the ReportTypes.
+	ReportType string `json:"reportType"`
+
+	// Schedule is the schedule of the report in cron format, such as "0 0 * * *". The end time of one report is the
+	// start time of the next one. At most two values can be set for the minute.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// EndpointSelector selects the endpoints in scope of the report by their labels. If not specified, all
+	// endpoints are in scope.
+	// +optional
+	EndpointSelector string `json:"endpointSelector,omitempty"`
+
+	// Namespaces restricts the endpoints in scope of the report to those in the namespaces.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NamespaceSelector restricts the endpoints in scope of the report to those in the namespaces with these labels.
+	// +optional
+	NamespaceSelector string `json:"namespaceSelector,omitempty"`
+
+	// JobNodeSelector selects the nodes that the jobs generating the report run on.
+	// +optional
+	JobNodeSelector map[string]string `json:"jobNodeSelector,omitempty"`
+
+	// Suspend stops further reports from being generated. Reports missed while suspended are generated once resumed.
+	// +optional
+	Suspend *bool `json:"suspend,omitempty"`
+}
+
+// ComplianceReportType is a custom type of compliance report.
+type ComplianceReportType struct {
+	// Name is the name of the GlobalReportType.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// UISummaryTemplate renders the JSON summary of a report that the UI displays. Its name must end in .json.
+	UISummaryTemplate ComplianceReportTemplate `json:"uiSummaryTemplate"`
+
+	// DownloadTemplates render the files that a report can be downloaded as. Their names must end in .csv or .json,
+	// and be unique.
+	// +optional
+	DownloadTemplates []ComplianceReportTemplate `json:"downloadTemplates,omitempty"`
+
+	// IncludeEndpointData includes the endpoints in scope in the report data.
+	// +optional
+	IncludeEndpointData bool `json:"includeEndpointData,omitempty"`
+
+	// IncludeEndpointFlowLogData includes the flows between endpoints in the report data.
+	// +optional
+	IncludeEndpointFlowLogData bool `json:"includeEndpointFlowLogData,omitempty"`
+
+	// IncludeCISBenchmarkData includes the results of the CIS benchmarks in the report data.
+	// +optional
+	IncludeCISBenchmarkData bool `json:"includeCISBenchmarkData,omitempty"`
+}
+
+// ComplianceReportTemplate is a Go template that renders the data of a report.
+type ComplianceReportTemplate struct {
+	// Name is the name of the template, which the UI uses as the suffix of the file name.
+	Name string `json:"name"`
+
+	// Description is a user facing description of the template.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Template is the Go template.
+	Template string `json:"template"`
+}
+
+// ComplianceSnapshotter configures the compliance snapshotter.
+type ComplianceSnapshotter struct {
+	// Interval is how often a snapshot is taken. It must be between 1h and 24h.
+	// Default: 24h
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Hour is the hour of the day, in UTC, at which the daily snapshot is taken. Only used with an interval of 24h.
+	// Default: 0
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=23
+	Hour *int32 `json:"hour,omitempty"`
+}
+
+// ComplianceBenchmarker configures the CIS benchmarker DaemonSet.
+type ComplianceBenchmarker struct {
+	// NodeSelector restricts the benchmarker to the nodes with these labels.
+	// If not specified, the benchmarker runs on all nodes.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the benchmarker pods.
+	// If not specified, the pods tolerate all taints.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// ComplianceReportExport configures where and how often compliance reports are exported. Exactly one of S3 and
+// PersistentVolumeClaim must be set.
+type ComplianceReportExport struct {
+	// Schedule is the schedule, in cron format, on which new reports are exported.
+	// Default: "0 * * * *"
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// S3 exports the reports to an S3-compatible bucket.
+	// +optional
+	S3 *ComplianceReportExportS3 `json:"s3,omitempty"`
+
+	// PersistentVolumeClaim exports the reports to a PersistentVolumeClaim in the tigera-compliance namespace.
+	// +optional
+	PersistentVolumeClaim *ComplianceReportExportPVC `json:"persistentVolumeClaim,omitempty"`
+}
+
+// ComplianceReportExportS3 is an S3-compatible bucket that compliance reports are exported to.
+type ComplianceReportExportS3 struct {
+	// Endpoint is the URL of the S3-compatible service, such as https://minio.example.com:9000.
+	// If not specified, the AWS S3 endpoint of the region is used.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Region of the bucket.
+	// Default: us-east-1
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Bucket is the name of the bucket.
+	Bucket string `json:"bucket"`
+
+	// Path is the prefix of the keys that the reports are written under.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// CredentialsSecretName is the name of a secret in the tigera-operator namespace with the access-key-id and
+	// secret-access-key used to write to the bucket.
+	CredentialsSecretName string `json:"credentialsSecretName"`
+
+	// ForcePathStyle addresses the bucket in the path of the URL rather than in the host name, which most
+	// S3-compatible services other than AWS S3 require.
+	// +optional
+	ForcePathStyle bool `json:"forcePathStyle,omitempty"`
+}
+
+// ComplianceReportExportPVC is a PersistentVolumeClaim that compliance reports are exported to.
+type ComplianceReportExportPVC struct {
+	// ClaimName is the name of the PersistentVolumeClaim in the tigera-compliance namespace.
+	ClaimName string `json:"claimName"`
+
+	// Path is the directory in the volume that the reports are written under.
+	// +optional
+	Path string `json:"path,omitempty"`
 }
 
 // ComplianceStatus defines the observed state of Tigera compliance reporting capabilities.
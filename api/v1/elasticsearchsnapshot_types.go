@@ -0,0 +1,173 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ElasticsearchSnapshots configures periodic snapshots of the Tigera indices in Elasticsearch to a snapshot
+// repository, and the restore of a snapshot from that repository.
+type ElasticsearchSnapshots struct {
+	// Repository is where the snapshots are stored.
+	Repository SnapshotRepository `json:"repository"`
+
+	// Schedule is the Elasticsearch cron expression that determines when a snapshot is taken.
+	// See https://www.elastic.co/guide/en/elasticsearch/reference/current/trigger-schedule.html#schedule-cron
+	// Default: 0 30 1 * * ?
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Retention determines which snapshots are removed from the repository.
+	// +optional
+	Retention *SnapshotRetention `json:"retention,omitempty"`
+
+	// Restore, if specified, restores the indices of a snapshot in the repository. A restore is performed once
+	// for each snapshot name; its progress is reported in the LogStorage status. The indices are restored next to
+	// the current ones, under the name restored-<snapshot>-<index>.
+	// +optional
+	Restore *SnapshotRestore `json:"restore,omitempty"`
+}
+
+// SnapshotRepository is the snapshot repository of the Elasticsearch cluster. Exactly one of its fields must be
+// specified.
+type SnapshotRepository struct {
+	// S3 stores the snapshots in S3 compatible storage. The credentials are read from the
+	// elasticsearch-snapshot-s3-credentials Secret in the tigera-operator namespace, which holds the access key id
+	// under `key-id` and the secret access key under `key-secret`.
+	// +optional
+	S3 *S3SnapshotRepository `json:"s3,omitempty"`
+
+	// PersistentVolumeClaim stores the snapshots on a shared filesystem. The claim must be in the
+	// tigera-elasticsearch namespace and support the ReadWriteMany access mode, since it is mounted by all the
+	// Elasticsearch nodes.
+	// +optional
+	PersistentVolumeClaim *PVCSnapshotRepository `json:"persistentVolumeClaim,omitempty"`
+}
+
+// S3SnapshotRepository configures a snapshot repository in S3 compatible storage.
+type S3SnapshotRepository struct {
+	// BucketName is the name of the bucket to store the snapshots in.
+	BucketName string `json:"bucketName"`
+
+	// BasePath is the path in the bucket under which the snapshots are stored.
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+
+	// Endpoint is the host name, and optionally port, of the S3 compatible storage. If not specified, Amazon S3
+	// is used.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// PathStyleAccess uses path style instead of virtual hosted style URLs to access the bucket, which some S3
+	// compatible storage requires.
+	// +optional
+	PathStyleAccess bool `json:"pathStyleAccess,omitempty"`
+}
+
+// PVCSnapshotRepository configures a snapshot repository on a PersistentVolumeClaim.
+type PVCSnapshotRepository struct {
+	// ClaimName is the name of the PersistentVolumeClaim in the tigera-elasticsearch namespace.
+	ClaimName string `json:"claimName"`
+}
+
+// SnapshotRetention determines which snapshots are removed from the repository. Snapshots that are older than
+// ExpireAfterDays are removed, unless fewer than MinCount snapshots would remain. The oldest snapshots are removed
+// when there are more than MaxCount.
+type SnapshotRetention struct {
+	// ExpireAfterDays is the number of days after which a snapshot is removed.
+	// Default: 30
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ExpireAfterDays *int32 `json:"expireAfterDays,omitempty"`
+
+	// MinCount is the number of snapshots that are kept regardless of their age.
+	// Default: 5
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinCount *int32 `json:"minCount,omitempty"`
+
+	// MaxCount is the maximum number of snapshots that are kept. If not specified, the number of snapshots is
+	// not limited.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxCount *int32 `json:"maxCount,omitempty"`
+}
+
+// SnapshotRestore restores the indices of a snapshot.
+type SnapshotRestore struct {
+	// Snapshot is the name of the snapshot in the repository to restore.
+	Snapshot string `json:"snapshot"`
+
+	// Indices are the names or wildcard patterns of the indices to restore, e.g. tigera_secure_ee_flows*.
+	// +kubebuilder:validation:MinItems=1
+	Indices []string `json:"indices"`
+}
+
+// SnapshotRestoreState is the state of a snapshot restore.
+type SnapshotRestoreState string
+
+const (
+	SnapshotRestoreInProgress SnapshotRestoreState = "InProgress"
+	SnapshotRestoreCompleted  SnapshotRestoreState = "Completed"
+	SnapshotRestoreFailed     SnapshotRestoreState = "Failed"
+)
+
+// ElasticsearchSnapshotsStatus is the observed state of the snapshots of the Elasticsearch cluster.
+type ElasticsearchSnapshotsStatus struct {
+	// LastSnapshot is the name of the last snapshot that was taken successfully.
+	// +optional
+	LastSnapshot string `json:"lastSnapshot,omitempty"`
+
+	// LastSnapshotTime is when the last successful snapshot was taken.
+	// +optional
+	LastSnapshotTime *metav1.Time `json:"lastSnapshotTime,omitempty"`
+
+	// LastFailure describes the last snapshot that failed.
+	// +optional
+	LastFailure string `json:"lastFailure,omitempty"`
+
+	// LastFailureTime is when the last snapshot failed.
+	// +optional
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+
+	// Restore is the progress of the restore requested in the LogStorage spec.
+	// +optional
+	Restore *SnapshotRestoreStatus `json:"restore,omitempty"`
+}
+
+// SnapshotRestoreStatus is the progress of a snapshot restore.
+type SnapshotRestoreStatus struct {
+	// Snapshot is the name of the snapshot that is restored.
+	Snapshot string `json:"snapshot"`
+
+	// State of the restore.
+	// +kubebuilder:validation:Enum=InProgress;Completed;Failed
+	State SnapshotRestoreState `json:"state"`
+
+	// Message provides details about the state of the restore.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// StartTime is when the restore was started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the restore completed or failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
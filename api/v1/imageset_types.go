@@ -37,6 +37,12 @@ type Image struct {
 	// Digest is the image identifier that will be used for the Image.
 	// The field should not include a leading `@` and must be prefixed with `sha256:`.
 	Digest string `json:"digest"`
+
+	// Registry is the registry that the image is pulled from. If specified, it overrides the registry
+	// of the Installation for this image, which allows images to be mirrored across multiple registries.
+	// The value must end with a slash, e.g. `registry.example.com/`.
+	// +optional
+	Registry string `json:"registry,omitempty"`
 }
 
 // +kubebuilder:object:root=true
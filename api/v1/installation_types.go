@@ -131,6 +131,10 @@ type InstallationSpec struct {
 	// +optional
 	NodeUpdateStrategy appsv1.DaemonSetUpdateStrategy `json:"nodeUpdateStrategy,omitempty"`
 
+	// WindowsNodeUpgrade controls when the operator upgrades Calico on Windows nodes.
+	// +optional
+	WindowsNodeUpgrade *WindowsNodeUpgrade `json:"windowsNodeUpgrade,omitempty"`
+
 	// ComponentResources can be used to customize the resource requirements for each component.
 	// Node, Typha, and KubeControllers are supported for installations.
 	// +optional
@@ -145,6 +149,55 @@ type InstallationSpec struct {
 	// NonPrivileged configures Calico to be run in non-privileged containers as non-root users where possible.
 	// +optional
 	NonPrivileged *NonPrivilegedType `json:"nonPrivileged,omitempty"`
+
+	// ComponentNetworkPolicy controls whether the operator renders Calico network policies for the components it
+	// manages. When Enabled, each operator managed namespace gets a default-deny policy and the policies required by
+	// its components, all in the allow-tigera tier. Component resources such as Manager or LogCollector may override
+	// this value for their own components. Only supported for the TigeraSecureEnterprise variant.
+	// Default: Enabled
+	// +optional
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	ComponentNetworkPolicy *ComponentNetworkPolicyType `json:"componentNetworkPolicy,omitempty"`
+
+	// PodSecurityStandards configures the pod security admission labels of the namespaces managed by the operator.
+	// Namespaces that are not listed use the enforce level required by their components, pinned to the latest version.
+	// When the enforce level of a namespace is configured, the operator verifies that the pods of its components
+	// satisfy that level and reports a degraded status if they don't.
+	// +optional
+	PodSecurityStandards []NamespacePodSecurityStandard `json:"podSecurityStandards,omitempty"`
+
+	// ImageSignatureVerification, if specified, makes the operator verify the cosign signatures of the images of
+	// its components before deploying them. Components with images that are unsigned, or not signed with the
+	// configured key, are not deployed and a degraded status lists those images. Verified images are deployed by
+	// the digest that was verified, even if they are referenced by tag.
+	// +optional
+	ImageSignatureVerification *ImageSignatureVerification `json:"imageSignatureVerification,omitempty"`
+
+	// Logging configures the log level of the components that the operator manages.
+	// +optional
+	Logging *Logging `json:"logging,omitempty"`
+
+	// ControlPlaneAvailability configures the PodDisruptionBudgets and topology spread constraints of the control
+	// plane components that run more than one replica. Components that are not listed use the defaults.
+	// +optional
+	ControlPlaneAvailability []ControlPlaneAvailability `json:"controlPlaneAvailability,omitempty"`
+
+	// AWSSecurityGroups, if specified, configures the operator to manage the rules of the AWS security groups of the
+	// nodes of the cluster, e.g. on EKS or on a self-managed cluster on AWS.
+	// +optional
+	AWSSecurityGroups *AWSSecurityGroups `json:"awsSecurityGroups,omitempty"`
+
+	// PacketCapture configures where calico-node writes the packet capture files on the nodes and how they are
+	// rotated. It is only used by Calico Enterprise.
+	// +optional
+	PacketCapture *PacketCaptureSpec `json:"packetCapture,omitempty"`
+}
+
+// ImageSignatureVerification configures the verification of image signatures.
+type ImageSignatureVerification struct {
+	// PublicKeySecretName is the name of a Secret in the tigera-operator namespace that holds the PEM encoded
+	// cosign public key under the key `cosign.pub`.
+	PublicKeySecretName string `json:"publicKeySecretName"`
 }
 
 // TyphaAffinity allows configuration of node affinity characteristics for Typha pods.
@@ -232,6 +285,59 @@ const (
 	NonPrivilegedDisabled NonPrivilegedType = "Disabled"
 )
 
+// ComponentNetworkPolicyType specifies whether the operator renders network policies for its components.
+//
+// One of: Enabled, Disabled
+type ComponentNetworkPolicyType string
+
+const (
+	ComponentNetworkPolicyEnabled  ComponentNetworkPolicyType = "Enabled"
+	ComponentNetworkPolicyDisabled ComponentNetworkPolicyType = "Disabled"
+)
+
+// PodSecurityStandardLevel is one of the levels defined by the Kubernetes pod security standards.
+//
+// One of: privileged, baseline, restricted
+// +kubebuilder:validation:Enum=privileged;baseline;restricted
+type PodSecurityStandardLevel string
+
+const (
+	PodSecurityStandardPrivileged PodSecurityStandardLevel = "privileged"
+	PodSecurityStandardBaseline   PodSecurityStandardLevel = "baseline"
+	PodSecurityStandardRestricted PodSecurityStandardLevel = "restricted"
+)
+
+// NamespacePodSecurityStandard configures the pod security admission modes of an operator managed namespace.
+type NamespacePodSecurityStandard struct {
+	// Namespace is the name of the operator managed namespace the settings apply to.
+	Namespace string `json:"namespace"`
+
+	// Enforce is the level that pods must satisfy to be admitted to the namespace. If not specified, the level
+	// required by the components in the namespace is used.
+	// +optional
+	Enforce *PodSecurityStandardMode `json:"enforce,omitempty"`
+
+	// Audit is the level at which violations are recorded in the audit log. If not specified, no audit label is set.
+	// +optional
+	Audit *PodSecurityStandardMode `json:"audit,omitempty"`
+
+	// Warn is the level at which violations are returned as warnings to the user. If not specified, no warn label is set.
+	// +optional
+	Warn *PodSecurityStandardMode `json:"warn,omitempty"`
+}
+
+// PodSecurityStandardMode is the level and version of the pod security standard used by a pod security admission mode.
+type PodSecurityStandardMode struct {
+	// Level is the pod security standard level.
+	Level PodSecurityStandardLevel `json:"level"`
+
+	// Version pins the Kubernetes version of the pod security standard, for example v1.25.
+	// Default: latest
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(latest|v[0-9]+\.[0-9]+)$`
+	Version string `json:"version,omitempty"`
+}
+
 // ContainerIPForwardingType specifies whether the CNI config for container ip forwarding is enabled.
 type ContainerIPForwardingType string
 
@@ -27,12 +27,220 @@ type IntrusionDetectionSpec struct {
 	// Only DeepPacketInspection is supported for this spec.
 	// +optional
 	ComponentResources []IntrusionDetectionComponentResource `json:"componentResources,omitempty"`
+
+	// AnomalyDetection configures the built-in anomaly detectors. Anomaly detection only runs on management and
+	// standalone clusters.
+	// +optional
+	AnomalyDetection *AnomalyDetectionSpec `json:"anomalyDetection,omitempty"`
+
+	// DisabledGlobalAlertTemplates lists the names of built-in GlobalAlertTemplates, such as policy.pod or network.ssh,
+	// that are not installed. Templates of anomaly detectors are installed according to AnomalyDetection instead.
+	// +optional
+	DisabledGlobalAlertTemplates []string `json:"disabledGlobalAlertTemplates,omitempty"`
+
+	// GlobalAlertTemplatesConfigMapName is the name of a ConfigMap in the tigera-operator namespace with user defined
+	// GlobalAlertTemplates, one YAML or JSON manifest per key. The templates are validated and installed alongside
+	// the built-in templates. Their names must not clash with the names of built-in templates.
+	// +optional
+	GlobalAlertTemplatesConfigMapName string `json:"globalAlertTemplatesConfigMapName,omitempty"`
+
+	// ThreatFeeds are installed as GlobalThreatFeeds, which the intrusion detection controller pulls periodically and
+	// alerts on traffic to.
+	// +optional
+	ThreatFeeds []ThreatFeed `json:"threatFeeds,omitempty"`
+
+	// DeepPacketInspection configures where and how the deep packet inspection DaemonSet runs. Deep packet inspection
+	// is only deployed while DeepPacketInspection resources exist.
+	// +optional
+	DeepPacketInspection *DeepPacketInspectionSpec `json:"deepPacketInspection,omitempty"`
+
+	// ComponentNetworkPolicy controls whether the operator renders Calico network policies for the intrusion detection components.
+	// If not specified, the value from the Installation is used.
+	// +optional
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	ComponentNetworkPolicy *ComponentNetworkPolicyType `json:"componentNetworkPolicy,omitempty"`
+
+	// LogLevel is the log level of the intrusion detection components. It overrides the log level of the Installation.
+	// +optional
+	// +kubebuilder:validation:Enum=Error;Warn;Info;Debug
+	LogLevel *LogLevel `json:"logLevel,omitempty"`
+}
+
+// DeepPacketInspectionSpec configures the deep packet inspection DaemonSets.
+type DeepPacketInspectionSpec struct {
+	// NodeSelector restricts deep packet inspection to the nodes with these labels.
+	// If not specified, deep packet inspection runs on all nodes.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the deep packet inspection pods.
+	// If not specified, the pods tolerate all taints.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// NodePools size deep packet inspection per pool of nodes. Each pool runs in its own DaemonSet with the
+	// resources of the pool. Nodes that are in no pool use the resources of the DeepPacketInspection component in
+	// ComponentResources. The pools must not overlap.
+	// +optional
+	NodePools []DeepPacketInspectionNodePool `json:"nodePools,omitempty"`
+}
+
+// DeepPacketInspectionNodePool is a pool of nodes that runs deep packet inspection with its own resources.
+type DeepPacketInspectionNodePool struct {
+	// Name is the name of the pool, used in the name of its DaemonSet.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=40
+	Name string `json:"name"`
+
+	// NodeSelector selects the nodes in the pool.
+	// +kubebuilder:validation:MinProperties=1
+	NodeSelector map[string]string `json:"nodeSelector"`
+
+	// ResourceRequirements are the resources of the deep packet inspection pods in the pool.
+	// If not specified, the resources of the DeepPacketInspection component in ComponentResources are used.
+	// +optional
+	ResourceRequirements *corev1.ResourceRequirements `json:"resourceRequirements,omitempty"`
+}
+
+// ThreatFeed is a list of IP addresses or domain names of known threats. The feed is pulled from either an HTTP URL or
+// a ConfigMap, with one entry per line.
+type ThreatFeed struct {
+	// Name is the name of the GlobalThreatFeed.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=50
+	Name string `json:"name"`
+
+	// Content is the kind of entries of the feed.
+	// Default: IPSet
+	// +optional
+	Content *ThreatFeedContent `json:"content,omitempty"`
+
+	// URL is the HTTP or HTTPS URL the feed is pulled from. Exactly one of URL and ConfigMap must be set.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// ConfigMap selects the key of a ConfigMap in the tigera-operator namespace that holds the feed. Exactly one of
+	// URL and ConfigMap must be set.
+	// +optional
+	ConfigMap *corev1.ConfigMapKeySelector `json:"configMap,omitempty"`
+
+	// PullPeriod is how often the feed is pulled. It must be at least 5m.
+	// Default: 24h
+	// +optional
+	PullPeriod *metav1.Duration `json:"pullPeriod,omitempty"`
+
+	// Headers are sent with the requests for the feed. Only used with URL.
+	// +optional
+	Headers []ThreatFeedHeader `json:"headers,omitempty"`
+
+	// GlobalNetworkSet, if set, syncs the entries of the feed into a GlobalNetworkSet with the labels, which network
+	// policies can select to block traffic to the threats. Only supported for IPSet feeds.
+	// +optional
+	GlobalNetworkSet *ThreatFeedGlobalNetworkSet `json:"globalNetworkSet,omitempty"`
 }
 
+// +kubebuilder:validation:Enum=IPSet;DomainNameSet
+type ThreatFeedContent string
+
+const (
+	ThreatFeedContentIPSet         ThreatFeedContent = "IPSet"
+	ThreatFeedContentDomainNameSet ThreatFeedContent = "DomainNameSet"
+)
+
+// ThreatFeedHeader is an HTTP header sent with the requests for a threat feed.
+type ThreatFeedHeader struct {
+	// Name is the name of the header.
+	Name string `json:"name"`
+
+	// Value is the value of the header. Exactly one of Value and SecretKeyRef must be set.
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// SecretKeyRef selects the key of a secret in the tigera-operator namespace that holds the value of the header,
+	// such as an API token. Exactly one of Value and SecretKeyRef must be set.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// ThreatFeedGlobalNetworkSet configures the GlobalNetworkSet that the entries of a threat feed are synced into.
+type ThreatFeedGlobalNetworkSet struct {
+	// Labels are the labels of the GlobalNetworkSet.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// AnomalyDetectionSpec configures the built-in anomaly detectors.
+type AnomalyDetectionSpec struct {
+	// Detectors selects the built-in detectors that can run, and overrides their settings. If not specified, all
+	// built-in detectors can run.
+	// +optional
+	Detectors []AnomalyDetector `json:"detectors,omitempty"`
+
+	// TrainingPeriod is how often the models of the detectors are trained. It is rounded down to whole minutes.
+	// Default: 24h
+	// +optional
+	TrainingPeriod *metav1.Duration `json:"trainingPeriod,omitempty"`
+
+	// DetectionPeriod is how often the detectors look for anomalies.
+	// Default: 15m
+	// +optional
+	DetectionPeriod *metav1.Duration `json:"detectionPeriod,omitempty"`
+
+	// Sensitivity is how readily the detectors that have a threshold report anomalies: ip_sweep, port_scan,
+	// process_restarts, dns_latency and l7_latency. A higher sensitivity reports more anomalies, and more false
+	// positives. The other detectors are not affected.
+	// Default: Medium
+	// +optional
+	Sensitivity *AnomalyDetectorSensitivity `json:"sensitivity,omitempty"`
+}
+
+// AnomalyDetector selects a built-in anomaly detector and overrides its settings.
+type AnomalyDetector struct {
+	// Name is the name of the detector.
+	// +kubebuilder:validation:Enum=dga;http_connection_spike;http_response_codes;http_verbs;ip_sweep;port_scan;generic_dns;time_series_dns;generic_flows;time_series_flows;generic_l7;dns_latency;l7_bytes;l7_latency;process_restarts
+	Name string `json:"name"`
+
+	// DetectionPeriod overrides AnomalyDetectionSpec.DetectionPeriod for this detector.
+	// +optional
+	DetectionPeriod *metav1.Duration `json:"detectionPeriod,omitempty"`
+
+	// Sensitivity overrides AnomalyDetectionSpec.Sensitivity for this detector. It can only be set for the detectors
+	// that have a threshold: ip_sweep, port_scan, process_restarts, dns_latency and l7_latency.
+	// +optional
+	Sensitivity *AnomalyDetectorSensitivity `json:"sensitivity,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Low;Medium;High
+type AnomalyDetectorSensitivity string
+
+const (
+	AnomalyDetectorSensitivityLow    AnomalyDetectorSensitivity = "Low"
+	AnomalyDetectorSensitivityMedium AnomalyDetectorSensitivity = "Medium"
+	AnomalyDetectorSensitivityHigh   AnomalyDetectorSensitivity = "High"
+)
+
 // IntrusionDetectionStatus defines the observed state of Tigera intrusion detection capabilities.
 type IntrusionDetectionStatus struct {
 	// State provides user-readable status.
 	State string `json:"state,omitempty"`
+
+	// ThreatFeeds is the state of the threat feeds of the spec.
+	// +optional
+	ThreatFeeds []ThreatFeedStatus `json:"threatFeeds,omitempty"`
+}
+
+// ThreatFeedStatus is the state of a threat feed.
+type ThreatFeedStatus struct {
+	// Name is the name of the threat feed.
+	Name string `json:"name"`
+
+	// LastSuccessfulSync is when the feed was last pulled successfully.
+	// +optional
+	LastSuccessfulSync *metav1.Time `json:"lastSuccessfulSync,omitempty"`
+
+	// Errors are the errors of the last attempts to pull and sync the feed.
+	// +optional
+	Errors []string `json:"errors,omitempty"`
 }
 
 // +kubebuilder:object:root=true
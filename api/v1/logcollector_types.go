@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -36,6 +37,165 @@ type LogCollectorSpec struct {
 	// +optional
 	// +kubebuilder:validation:Enum=Enabled;Disabled
 	CollectProcessPath *CollectProcessPathOption `json:"collectProcessPath,omitempty"`
+
+	// ComponentNetworkPolicy controls whether the operator renders Calico network policies for the log collection components.
+	// If not specified, the value from the Installation is used.
+	// +optional
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	ComponentNetworkPolicy *ComponentNetworkPolicyType `json:"componentNetworkPolicy,omitempty"`
+
+	// Filters configures which flow, DNS, L7 and audit logs are collected, how they are sampled and which of their
+	// fields are redacted. They apply to the logs sent to Elasticsearch and to the additional stores, and are applied
+	// before the filters in the fluentd-filters ConfigMap.
+	// +optional
+	Filters *LogCollectorFilters `json:"filters,omitempty"`
+
+	// Buffer configures how fluentd buffers the logs before sending them to Elasticsearch and the additional stores,
+	// and how it retries when they are unavailable.
+	// +optional
+	Buffer *FluentdBufferSpec `json:"buffer,omitempty"`
+
+	// LogLevel is the log level of the log collector. It overrides the log level of the Installation.
+	// +optional
+	// +kubebuilder:validation:Enum=Error;Warn;Info;Debug
+	LogLevel *LogLevel `json:"logLevel,omitempty"`
+}
+
+// FluentdBufferSpec configures the buffers of the fluentd outputs.
+type FluentdBufferSpec struct {
+	// FlushInterval is how often the buffered logs are sent. If not specified, the logs are sent to the additional
+	// stores every 5s and to Elasticsearch at the default interval of fluentd.
+	// +optional
+	FlushInterval *metav1.Duration `json:"flushInterval,omitempty"`
+
+	// ChunkLimitSize is the maximum size of each chunk of buffered logs.
+	// +optional
+	ChunkLimitSize *resource.Quantity `json:"chunkLimitSize,omitempty"`
+
+	// TotalLimitSize is the maximum size of the buffer of each output. When it is reached, the OverflowAction is taken.
+	// +optional
+	TotalLimitSize *resource.Quantity `json:"totalLimitSize,omitempty"`
+
+	// RetryMaxInterval is the maximum interval between the retries to send a chunk, which back off exponentially.
+	// +optional
+	RetryMaxInterval *metav1.Duration `json:"retryMaxInterval,omitempty"`
+
+	// RetryTimeout is how long fluentd retries to send a chunk before dropping it. It cannot be set with RetryForever.
+	// +optional
+	RetryTimeout *metav1.Duration `json:"retryTimeout,omitempty"`
+
+	// RetryForever makes fluentd retry to send chunks until they are sent.
+	// +optional
+	RetryForever *bool `json:"retryForever,omitempty"`
+
+	// OverflowAction is what fluentd does with new logs when the buffer of an output is full.
+	// +optional
+	// +kubebuilder:validation:Enum=ThrowException;Block;DropOldestChunk
+	OverflowAction *FluentdBufferOverflowAction `json:"overflowAction,omitempty"`
+
+	// Storage stores the buffers in files, so that they are not lost when fluentd restarts.
+	// If not specified, the logs are buffered in memory.
+	// +optional
+	Storage *FluentdBufferStorage `json:"storage,omitempty"`
+
+	// DegradedThresholdPercent marks the LogCollector as degraded when the buffer of an output of any fluentd pod is
+	// fuller than this percentage of its total limit. If not specified, the usage of the buffers is not checked.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	DegradedThresholdPercent *int32 `json:"degradedThresholdPercent,omitempty"`
+}
+
+type FluentdBufferOverflowAction string
+
+const (
+	FluentdBufferOverflowThrowException  FluentdBufferOverflowAction = "ThrowException"
+	FluentdBufferOverflowBlock           FluentdBufferOverflowAction = "Block"
+	FluentdBufferOverflowDropOldestChunk FluentdBufferOverflowAction = "DropOldestChunk"
+)
+
+type FluentdBufferStorageType string
+
+const (
+	FluentdBufferStorageHostPath FluentdBufferStorageType = "HostPath"
+	FluentdBufferStorageEmptyDir FluentdBufferStorageType = "EmptyDir"
+)
+
+// FluentdBufferStorage configures the volume of the file-backed buffers.
+type FluentdBufferStorage struct {
+	// Type is the type of the volume. HostPath keeps the buffers on the node when the fluentd pod is replaced,
+	// EmptyDir only keeps them while the pod exists.
+	// +kubebuilder:validation:Enum=HostPath;EmptyDir
+	Type FluentdBufferStorageType `json:"type"`
+
+	// SizeLimit is the size limit of the EmptyDir volume. It is not supported for HostPath volumes.
+	// +optional
+	SizeLimit *resource.Quantity `json:"sizeLimit,omitempty"`
+}
+
+// LogCollectorFilters configures the filters of each type of log.
+type LogCollectorFilters struct {
+	// Flows filters the flow logs. Namespaces and labels match the source or destination endpoint.
+	// +optional
+	Flows *LogFilter `json:"flows,omitempty"`
+
+	// DNS filters the DNS logs. Namespaces and labels match the client endpoint.
+	// +optional
+	DNS *LogFilter `json:"dns,omitempty"`
+
+	// L7 filters the L7 logs. Namespaces match the source or destination endpoint. Labels are not supported.
+	// +optional
+	L7 *LogFilter `json:"l7,omitempty"`
+
+	// Audit filters the audit logs. Namespaces match the namespace of the object of the request. Labels are not supported.
+	// +optional
+	Audit *LogFilter `json:"audit,omitempty"`
+}
+
+// LogFilter selects, samples and redacts the logs of one type.
+type LogFilter struct {
+	// IncludeNamespaces restricts the logs to those of endpoints in these namespaces.
+	// +optional
+	IncludeNamespaces []string `json:"includeNamespaces,omitempty"`
+
+	// ExcludeNamespaces drops the logs of endpoints in these namespaces.
+	// +optional
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+
+	// IncludeLabels restricts the logs to those of endpoints with all of these labels.
+	// +optional
+	IncludeLabels map[string]string `json:"includeLabels,omitempty"`
+
+	// ExcludeLabels drops the logs of endpoints with any of these labels.
+	// +optional
+	ExcludeLabels map[string]string `json:"excludeLabels,omitempty"`
+
+	// SampleRate keeps one in every SampleRate logs, e.g. 10 keeps 10% of the logs. If not specified, all logs are kept.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	SampleRate *int32 `json:"sampleRate,omitempty"`
+
+	// Redactions redact fields of the logs.
+	// +optional
+	Redactions []LogFieldRedaction `json:"redactions,omitempty"`
+}
+
+// LogFieldRedaction redacts a field of the logs.
+type LogFieldRedaction struct {
+	// Field is the name of the field, such as url or qname.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_]+$`
+	Field string `json:"field"`
+
+	// Pattern is a regular expression whose matches in the field are replaced. It is validated with the RE2 syntax
+	// and applied by fluentd with Ruby regular expressions, so it should only use the syntax they share.
+	// If not specified, the field is removed.
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+
+	// Replacement replaces the matches of the pattern. It may refer to the groups of the pattern as \1, \2, etc.
+	// Default: [REDACTED]
+	// +optional
+	Replacement *string `json:"replacement,omitempty"`
 }
 
 type CollectProcessPathOption string
@@ -0,0 +1,57 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LogLevel is the level of the logs a component emits.
+type LogLevel string
+
+const (
+	LogLevelError LogLevel = "Error"
+	LogLevelWarn  LogLevel = "Warn"
+	LogLevelInfo  LogLevel = "Info"
+	LogLevelDebug LogLevel = "Debug"
+)
+
+// Logging configures the log level of the components that the operator manages. The operator translates the level
+// into the format of each component.
+type Logging struct {
+	// Level is the log level of the components. The custom resources of some components, e.g. the Manager, may
+	// override it for their own components. If not specified, each component logs at its default level.
+	// +optional
+	// +kubebuilder:validation:Enum=Error;Warn;Info;Debug
+	Level *LogLevel `json:"level,omitempty"`
+
+	// TemporaryDebug makes all components log at the Debug level for a limited time, regardless of their configured
+	// log level. The operator removes it once it has expired.
+	// +optional
+	TemporaryDebug *TemporaryDebugLogging `json:"temporaryDebug,omitempty"`
+}
+
+// TemporaryDebugLogging is a period of time during which the components log at the Debug level.
+type TemporaryDebugLogging struct {
+	// Duration is how long the components log at the Debug level.
+	Duration metav1.Duration `json:"duration"`
+
+	// Until is when the components stop logging at the Debug level. The operator sets it when temporary debug
+	// logging starts.
+	// +optional
+	Until *metav1.Time `json:"until,omitempty"`
+}
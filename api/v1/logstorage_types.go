@@ -52,6 +52,22 @@ type LogStorageSpec struct {
 	// Only ECKOperator is supported for this spec.
 	// +optional
 	ComponentResources []LogStorageComponentResource `json:"componentResources,omitempty"`
+
+	// ComponentNetworkPolicy controls whether the operator renders Calico network policies for the Elasticsearch, Kibana and related log storage components.
+	// If not specified, the value from the Installation is used.
+	// +optional
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	ComponentNetworkPolicy *ComponentNetworkPolicyType `json:"componentNetworkPolicy,omitempty"`
+
+	// ESGatewayAutoscaling, if specified, scales the replicas of the Elasticsearch gateway with a
+	// HorizontalPodAutoscaler.
+	// +optional
+	ESGatewayAutoscaling *Autoscaling `json:"esGatewayAutoscaling,omitempty"`
+
+	// ElasticsearchSnapshots, if specified, takes periodic snapshots of the Tigera indices to a snapshot repository
+	// and restores them on request.
+	// +optional
+	ElasticsearchSnapshots *ElasticsearchSnapshots `json:"elasticsearchSnapshots,omitempty"`
 }
 
 // LogStorageStatus defines the observed state of Tigera flow and DNS log storage.
@@ -66,6 +82,10 @@ type LogStorageStatus struct {
 	// KibanaHash represents the current revision and configuration of the installed Kibana dashboard. This
 	// is an opaque string which can be monitored for changes to perform actions when Kibana is modified.
 	KibanaHash string `json:"kibanaHash,omitempty"`
+
+	// ElasticsearchSnapshots reports the last snapshots taken and the progress of a requested restore.
+	// +optional
+	ElasticsearchSnapshots *ElasticsearchSnapshotsStatus `json:"elasticsearchSnapshots,omitempty"`
 }
 
 // Nodes defines the configuration for a set of identical Elasticsearch cluster nodes, each of type master, data, and ingest.
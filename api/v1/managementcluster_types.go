@@ -27,6 +27,26 @@ type ManagementClusterSpec struct {
 	// Valid examples are: "0.0.0.0:31000", "example.com:32000", "[::1]:32500"
 	// +optional
 	Address string `json:"address,omitempty"`
+
+	// Enrollment enables the endpoint that managed clusters enroll through with a bootstrap token. The endpoint issues
+	// the tunnel certificates of managed clusters, signed by the tunnel CA, and renews them. It is served by the
+	// manager pods on port 9450, which the operator does not expose: expose it the same way as the tunnel port 9449,
+	// for example by adding it to the service that exposes the tunnel.
+	// +optional
+	Enrollment *ManagedClusterEnrollment `json:"enrollment,omitempty"`
+}
+
+// ManagedClusterEnrollment configures the enrollment endpoint of a management cluster. Managed clusters enroll with
+// a token from a secret of type operator.tigera.io/managed-cluster-bootstrap-token in the
+// tigera-managed-cluster-enrollment namespace.
+// The secret holds the "cluster-name" of the managed cluster, the "token" and optionally the RFC 3339 "expiration"
+// of the token. Once a managed cluster has enrolled with the token, it is only accepted for the same key, so that the
+// managed cluster can retry its enrollment. The secret is deleted once the managed cluster confirms its certificate.
+type ManagedClusterEnrollment struct {
+	// CertificateValidity is how long the tunnel certificates issued to managed clusters are valid for.
+	// Default: 2160h
+	// +optional
+	CertificateValidity *metav1.Duration `json:"certificateValidity,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -26,6 +26,63 @@ type ManagementClusterConnectionSpec struct {
 	// should be able to access this address. This field is used by managed clusters only.
 	// +optional
 	ManagementClusterAddr string `json:"managementClusterAddr,omitempty"`
+
+	// ComponentNetworkPolicy controls whether the operator renders Calico network policies for the Guardian component.
+	// If not specified, the value from the Installation is used.
+	// +optional
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	ComponentNetworkPolicy *ComponentNetworkPolicyType `json:"componentNetworkPolicy,omitempty"`
+
+	// TLSServerName overrides the server name that guardian verifies the certificate of the management cluster
+	// against. By default the host of ManagementClusterAddr is used. This is needed when the management cluster is
+	// reached through an address that is not in its certificate, e.g. a load balancer IP.
+	// +optional
+	TLSServerName string `json:"tlsServerName,omitempty"`
+
+	// Proxy configures guardian to reach the management cluster through an HTTP CONNECT proxy.
+	// +optional
+	Proxy *ManagementClusterConnectionProxy `json:"proxy,omitempty"`
+
+	// LogLevel is the log level of guardian. It overrides the log level of the Installation.
+	// Default: Info
+	// +optional
+	// +kubebuilder:validation:Enum=Error;Warn;Info;Debug
+	LogLevel *LogLevel `json:"logLevel,omitempty"`
+
+	// CertificateRenewBefore is how long before it expires that a tunnel certificate obtained through the enrollment
+	// endpoint of the management cluster is renewed. A managed cluster enrolls when the
+	// tigera-managed-cluster-connection secret does not exist and the tigera-managed-cluster-bootstrap secret in the
+	// tigera-operator namespace holds the "token" to enroll with, the "ca.crt" of the management cluster tunnel and
+	// the "address" that the enrollment endpoint of the management cluster is exposed at.
+	// Default: 720h
+	// +optional
+	CertificateRenewBefore *metav1.Duration `json:"certificateRenewBefore,omitempty"`
+}
+
+// ManagementClusterConnectionProxy is an egress HTTP CONNECT proxy that guardian tunnels its connection to the
+// management cluster through.
+type ManagementClusterConnectionProxy struct {
+	// URL of the proxy, e.g. "http://proxy.example.com:3128".
+	// +required
+	URL string `json:"url"`
+
+	// CredentialsSecretName is the name of a secret in the tigera-operator namespace with the "username" and
+	// "password" used to authenticate to the proxy. If not specified, guardian does not authenticate to the proxy.
+	// +optional
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+}
+
+// ManagementClusterConnectionStatus defines the observed state of ManagementClusterConnection
+type ManagementClusterConnectionStatus struct {
+	// LastGuardianAvailableTime is when the guardian deployment last became available, as reported by its Available
+	// condition. It does not reflect the state of the tunnel to the management cluster.
+	// +optional
+	LastGuardianAvailableTime *metav1.Time `json:"lastGuardianAvailableTime,omitempty"`
+
+	// TunnelCertificateExpiry is when the certificate that guardian authenticates to the management cluster with
+	// expires. A new certificate must be installed in the tigera-managed-cluster-connection secret before then.
+	// +optional
+	TunnelCertificateExpiry *metav1.Time `json:"tunnelCertificateExpiry,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -38,7 +95,8 @@ type ManagementClusterConnection struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec ManagementClusterConnectionSpec `json:"spec,omitempty"`
+	Spec   ManagementClusterConnectionSpec   `json:"spec,omitempty"`
+	Status ManagementClusterConnectionStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -25,6 +25,134 @@ type ManagerSpec struct {
 	// Deprecated. Please use the Authentication CR for configuring authentication.
 	// +optional
 	Auth *Auth `json:"auth,omitempty"`
+
+	// ComponentNetworkPolicy controls whether the operator renders Calico network policies for the Tigera Secure manager.
+	// If not specified, the value from the Installation is used.
+	// +optional
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	ComponentNetworkPolicy *ComponentNetworkPolicyType `json:"componentNetworkPolicy,omitempty"`
+
+	// Exposure configures how the manager UI is exposed to users outside of the cluster.
+	// If not specified, the manager is only reachable through the tigera-manager ClusterIP service.
+	// +optional
+	Exposure *ManagerExposure `json:"exposure,omitempty"`
+
+	// LogLevel is the log level of the manager. It overrides the log level of the Installation.
+	// +optional
+	// +kubebuilder:validation:Enum=Error;Warn;Info;Debug
+	LogLevel *LogLevel `json:"logLevel,omitempty"`
+
+	// Autoscaling, if specified, scales the replicas of the manager with a HorizontalPodAutoscaler.
+	// +optional
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty"`
+}
+
+// ManagerExposureType is the way the manager UI is exposed.
+type ManagerExposureType string
+
+const (
+	ManagerExposureClusterIP    ManagerExposureType = "ClusterIP"
+	ManagerExposureLoadBalancer ManagerExposureType = "LoadBalancer"
+	ManagerExposureNodePort     ManagerExposureType = "NodePort"
+	ManagerExposureIngress      ManagerExposureType = "Ingress"
+	ManagerExposureGateway      ManagerExposureType = "Gateway"
+)
+
+// ManagerExposure configures how the manager UI is exposed. The manager only serves HTTPS.
+type ManagerExposure struct {
+	// Type is the way the manager UI is exposed.
+	// +kubebuilder:validation:Enum=ClusterIP;LoadBalancer;NodePort;Ingress;Gateway
+	Type ManagerExposureType `json:"type"`
+
+	// Hostname is the DNS name that users reach the manager UI at. It is required for the Ingress and Gateway
+	// types. The operator adds it to the certificate of the manager, when the operator issues it, and to the
+	// redirect URIs of Dex.
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+
+	// LoadBalancer configures the service of the manager when the type is LoadBalancer.
+	// +optional
+	LoadBalancer *ManagerLoadBalancer `json:"loadBalancer,omitempty"`
+
+	// NodePort is the node port of the service of the manager when the type is NodePort.
+	// If not specified, Kubernetes allocates one.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	NodePort *int32 `json:"nodePort,omitempty"`
+
+	// Ingress configures the Ingress of the manager when the type is Ingress.
+	// +optional
+	Ingress *ManagerIngress `json:"ingress,omitempty"`
+
+	// Gateway configures the Gateway API route of the manager when the type is Gateway.
+	// +optional
+	Gateway *ManagerGateway `json:"gateway,omitempty"`
+}
+
+// ManagerLoadBalancer configures the LoadBalancer service of the manager.
+type ManagerLoadBalancer struct {
+	// Annotations are added to the service, e.g. to configure the load balancer of the cloud provider.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// SourceRanges restricts the client IPs that the load balancer accepts traffic from.
+	// +optional
+	SourceRanges []string `json:"sourceRanges,omitempty"`
+}
+
+// ManagerIngress configures the Ingress of the manager.
+type ManagerIngress struct {
+	// IngressClassName is the name of the IngressClass of the Ingress.
+	// If not specified, the default IngressClass of the cluster is used.
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// TLSSecretName is the name of the secret in the tigera-manager namespace with the certificate that the
+	// ingress controller presents for the hostname. If not specified, the ingress controller uses its default.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// Annotations are added to the Ingress. The ingress controller must connect to the manager with HTTPS;
+	// the operator sets the annotation for the NGINX ingress controller, other controllers need their own.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ManagerGatewayRouteType is the kind of Gateway API route of the manager.
+type ManagerGatewayRouteType string
+
+const (
+	ManagerGatewayHTTPRoute ManagerGatewayRouteType = "HTTPRoute"
+	ManagerGatewayTLSRoute  ManagerGatewayRouteType = "TLSRoute"
+)
+
+// ManagerGateway configures the Gateway API route of the manager.
+type ManagerGateway struct {
+	// RouteType is the kind of route. An HTTPRoute terminates TLS at the gateway and connects to the manager
+	// with HTTPS, which requires support for BackendTLSPolicy. A TLSRoute passes TLS through to the manager.
+	// Default: HTTPRoute
+	// +optional
+	// +kubebuilder:validation:Enum=HTTPRoute;TLSRoute
+	RouteType ManagerGatewayRouteType `json:"routeType,omitempty"`
+
+	// ParentRefs are the gateways that the route attaches to.
+	// +kubebuilder:validation:MinItems=1
+	ParentRefs []ManagerGatewayParentRef `json:"parentRefs"`
+}
+
+// ManagerGatewayParentRef references a Gateway.
+type ManagerGatewayParentRef struct {
+	// Name is the name of the Gateway.
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Gateway. If not specified, the tigera-manager namespace is used.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName is the name of the listener of the Gateway.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
 }
 
 // ManagerStatus defines the observed state of the Calico Enterprise manager GUI.
@@ -35,6 +163,11 @@ type ManagerStatus struct {
 
 	// State provides user-readable status.
 	State string `json:"state,omitempty"`
+
+	// URL is the URL that the manager UI is reachable at from outside of the cluster, when it is exposed and
+	// the address is known.
+	// +optional
+	URL string `json:"url,omitempty"`
 }
 
 // Auth defines authentication configuration.
@@ -22,6 +22,11 @@ import (
 
 // MonitorSpec defines the desired state of Tigera monitor.
 type MonitorSpec struct {
+	// ComponentNetworkPolicy controls whether the operator renders Calico network policies for Prometheus and Alertmanager.
+	// If not specified, the value from the Installation is used.
+	// +optional
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	ComponentNetworkPolicy *ComponentNetworkPolicyType `json:"componentNetworkPolicy,omitempty"`
 }
 
 // MonitorStatus defines the observed state of Tigera monitor.
@@ -0,0 +1,53 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PacketCaptureSpec configures where calico-node writes the capture files on each node and how they are rotated.
+// Files are removed only by the rotation: a capture keeps at most MaxFilesPerCapture files of MaxFileSizeBytes
+// on each node, and a file is rotated at the latest after RotationPeriod.
+type PacketCaptureSpec struct {
+	// StorageDirectory is the directory under /var/log/calico on each node where the capture files are written.
+	// The packet capture API reads the files from the directory that calico-node reports in the status of each
+	// capture.
+	// Default: pcap
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.-]+(/[a-zA-Z0-9_.-]+)*$`
+	// +optional
+	StorageDirectory string `json:"storageDirectory,omitempty"`
+
+	// MaxFileSizeBytes is the size in bytes at which a capture file is rotated.
+	// Default: 10000000
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxFileSizeBytes *int64 `json:"maxFileSizeBytes,omitempty"`
+
+	// MaxFilesPerCapture is the number of rotated files that are kept for each capture on a node. The oldest file
+	// is removed when the limit is reached.
+	// Default: 2
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxFilesPerCapture *int32 `json:"maxFilesPerCapture,omitempty"`
+
+	// RotationPeriod is how long a capture file is written to before it is rotated. It is rounded down to whole
+	// seconds.
+	// Default: 1h
+	// +optional
+	RotationPeriod *metav1.Duration `json:"rotationPeriod,omitempty"`
+}
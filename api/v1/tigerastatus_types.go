@@ -74,12 +74,24 @@ const (
 
 	// Degraded means the component is not operating as desired and user action is required.
 	ComponentDegraded StatusConditionType = "Degraded"
+
+	// NodesDegraded means that calico/node reports problems on some of the nodes, e.g. BGP peers that are down.
+	ComponentNodesDegraded StatusConditionType = "NodesDegraded"
+
+	// PodSecurityRelaxed means that some of the component's pods need a less restrictive pod security standard than
+	// "restricted" from their namespace. The message lists the workloads and the reasons.
+	ComponentPodSecurityRelaxed StatusConditionType = "PodSecurityRelaxed"
+
+	// ThreatFeedsFailing means that some of the threat feeds of intrusion detection fail to sync. The message lists the
+	// feeds and their errors.
+	ComponentThreatFeedsFailing StatusConditionType = "ThreatFeedsFailing"
 )
 
 // TigeraStatusCondition represents a condition attached to a particular component.
 // +k8s:deepcopy-gen=true
 type TigeraStatusCondition struct {
-	// The type of condition. May be Available, Progressing, or Degraded.
+	// The type of condition. May be Available, Progressing, Degraded, NodesDegraded, PodSecurityRelaxed, or
+	// ThreatFeedsFailing.
 	Type StatusConditionType `json:"type"`
 
 	// The status of the condition. May be True, False, or Unknown.
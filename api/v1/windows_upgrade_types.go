@@ -0,0 +1,63 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WindowsNodeUpgrade controls when the operator upgrades Calico on Windows nodes.
+type WindowsNodeUpgrade struct {
+	// Paused stops the operator from starting the upgrade of more Windows nodes. The upgrades that are in progress
+	// are completed.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// MaintenanceWindows, if specified, are the only times at which the operator starts the upgrade of Windows nodes.
+	// The upgrades that are in progress at the end of a window are completed.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// CanaryNodeSelector selects Windows nodes that are upgraded first. The other Windows nodes are only upgraded
+	// once all of the selected nodes have been upgraded successfully.
+	// +optional
+	CanaryNodeSelector map[string]string `json:"canaryNodeSelector,omitempty"`
+
+	// NodeTimeout is how long the upgrade of a Windows node may take before the node is reported as failed. A failed
+	// node keeps counting towards the maximum number of unavailable nodes, and a failed canary node stops the upgrade
+	// of the other nodes. If not specified, the upgrade of a node does not time out.
+	// +optional
+	NodeTimeout *metav1.Duration `json:"nodeTimeout,omitempty"`
+}
+
+// DayOfWeek is a day of the week.
+// +kubebuilder:validation:Enum=Sunday;Monday;Tuesday;Wednesday;Thursday;Friday;Saturday
+type DayOfWeek string
+
+// MaintenanceWindow is a recurring time range.
+type MaintenanceWindow struct {
+	// Days are the days of the week on which the window starts. If not specified, the window starts every day.
+	// +optional
+	Days []DayOfWeek `json:"days,omitempty"`
+
+	// Start is the time of day at which the window starts, in UTC and in the HH:MM format.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// Duration is the length of the window.
+	Duration metav1.Duration `json:"duration"`
+}
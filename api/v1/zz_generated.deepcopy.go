@@ -23,7 +23,9 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -31,7 +33,7 @@ func (in *APIServer) DeepCopyInto(out *APIServer) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
@@ -53,6 +55,31 @@ func (in *APIServer) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIServerAudit) DeepCopyInto(out *APIServerAudit) {
+	*out = *in
+	if in.Level != nil {
+		in, out := &in.Level, &out.Level
+		*out = new(APIServerAuditLevel)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIServerAudit.
+func (in *APIServerAudit) DeepCopy() *APIServerAudit {
+	if in == nil {
+		return nil
+	}
+	out := new(APIServerAudit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIServerList) DeepCopyInto(out *APIServerList) {
 	*out = *in
@@ -88,6 +115,56 @@ func (in *APIServerList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIServerSpec) DeepCopyInto(out *APIServerSpec) {
 	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QueryServerResources != nil {
+		in, out := &in.QueryServerResources, &out.QueryServerResources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(APIServerTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostNetwork != nil {
+		in, out := &in.HostNetwork, &out.HostNetwork
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(APIServerAudit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PacketCaptureAutoscaling != nil {
+		in, out := &in.PacketCaptureAutoscaling, &out.PacketCaptureAutoscaling
+		*out = new(Autoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ComponentNetworkPolicy != nil {
+		in, out := &in.ComponentNetworkPolicy, &out.ComponentNetworkPolicy
+		*out = new(ComponentNetworkPolicyType)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIServerSpec.
@@ -115,6 +192,80 @@ func (in *APIServerStatus) DeepCopy() *APIServerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIServerTLS) DeepCopyInto(out *APIServerTLS) {
+	*out = *in
+	if in.MinVersion != nil {
+		in, out := &in.MinVersion, &out.MinVersion
+		*out = new(TLSVersion)
+		**out = **in
+	}
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIServerTLS.
+func (in *APIServerTLS) DeepCopy() *APIServerTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(APIServerTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSSecurityGroupSelector) DeepCopyInto(out *AWSSecurityGroupSelector) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSSecurityGroupSelector.
+func (in *AWSSecurityGroupSelector) DeepCopy() *AWSSecurityGroupSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSSecurityGroupSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSSecurityGroups) DeepCopyInto(out *AWSSecurityGroups) {
+	*out = *in
+	if in.SecurityGroupIDs != nil {
+		in, out := &in.SecurityGroupIDs, &out.SecurityGroupIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityGroupSelectors != nil {
+		in, out := &in.SecurityGroupSelectors, &out.SecurityGroupSelectors
+		*out = make([]AWSSecurityGroupSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSSecurityGroups.
+func (in *AWSSecurityGroups) DeepCopy() *AWSSecurityGroups {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSSecurityGroups)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdditionalLogSourceSpec) DeepCopyInto(out *AdditionalLogSourceSpec) {
 	*out = *in
@@ -264,6 +415,68 @@ func (in *AmazonCloudIntegrationStatus) DeepCopy() *AmazonCloudIntegrationStatus
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnomalyDetectionSpec) DeepCopyInto(out *AnomalyDetectionSpec) {
+	*out = *in
+	if in.Detectors != nil {
+		in, out := &in.Detectors, &out.Detectors
+		*out = make([]AnomalyDetector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TrainingPeriod != nil {
+		in, out := &in.TrainingPeriod, &out.TrainingPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DetectionPeriod != nil {
+		in, out := &in.DetectionPeriod, &out.DetectionPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Sensitivity != nil {
+		in, out := &in.Sensitivity, &out.Sensitivity
+		*out = new(AnomalyDetectorSensitivity)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnomalyDetectionSpec.
+func (in *AnomalyDetectionSpec) DeepCopy() *AnomalyDetectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AnomalyDetectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnomalyDetector) DeepCopyInto(out *AnomalyDetector) {
+	*out = *in
+	if in.DetectionPeriod != nil {
+		in, out := &in.DetectionPeriod, &out.DetectionPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Sensitivity != nil {
+		in, out := &in.Sensitivity, &out.Sensitivity
+		*out = new(AnomalyDetectorSensitivity)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnomalyDetector.
+func (in *AnomalyDetector) DeepCopy() *AnomalyDetector {
+	if in == nil {
+		return nil
+	}
+	out := new(AnomalyDetector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationLayer) DeepCopyInto(out *ApplicationLayer) {
 	*out = *in
@@ -530,6 +743,16 @@ func (in *AuthenticationSpec) DeepCopyInto(out *AuthenticationSpec) {
 		*out = new(AuthenticationLDAP)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ComponentNetworkPolicy != nil {
+		in, out := &in.ComponentNetworkPolicy, &out.ComponentNetworkPolicy
+		*out = new(ComponentNetworkPolicyType)
+		**out = **in
+	}
+	if in.DexAutoscaling != nil {
+		in, out := &in.DexAutoscaling, &out.DexAutoscaling
+		*out = new(Autoscaling)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthenticationSpec.
@@ -557,6 +780,36 @@ func (in *AuthenticationStatus) DeepCopy() *AuthenticationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Autoscaling) DeepCopyInto(out *Autoscaling) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetMemoryUtilizationPercentage != nil {
+		in, out := &in.TargetMemoryUtilizationPercentage, &out.TargetMemoryUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Autoscaling.
+func (in *Autoscaling) DeepCopy() *Autoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(Autoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CNISpec) DeepCopyInto(out *CNISpec) {
 	*out = *in
@@ -664,7 +917,7 @@ func (in *Compliance) DeepCopyInto(out *Compliance) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
@@ -686,6 +939,35 @@ func (in *Compliance) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceBenchmarker) DeepCopyInto(out *ComplianceBenchmarker) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceBenchmarker.
+func (in *ComplianceBenchmarker) DeepCopy() *ComplianceBenchmarker {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceBenchmarker)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceList) DeepCopyInto(out *ComplianceList) {
 	*out = *in
@@ -719,141 +1001,567 @@ func (in *ComplianceList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ComplianceSpec) DeepCopyInto(out *ComplianceSpec) {
+func (in *ComplianceReport) DeepCopyInto(out *ComplianceReport) {
 	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.JobNodeSelector != nil {
+		in, out := &in.JobNodeSelector, &out.JobNodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceSpec.
-func (in *ComplianceSpec) DeepCopy() *ComplianceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceReport.
+func (in *ComplianceReport) DeepCopy() *ComplianceReport {
 	if in == nil {
 		return nil
 	}
-	out := new(ComplianceSpec)
+	out := new(ComplianceReport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ComplianceStatus) DeepCopyInto(out *ComplianceStatus) {
+func (in *ComplianceReportExport) DeepCopyInto(out *ComplianceReportExport) {
 	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(ComplianceReportExportS3)
+		**out = **in
+	}
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(ComplianceReportExportPVC)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceStatus.
-func (in *ComplianceStatus) DeepCopy() *ComplianceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceReportExport.
+func (in *ComplianceReportExport) DeepCopy() *ComplianceReportExport {
 	if in == nil {
 		return nil
 	}
-	out := new(ComplianceStatus)
+	out := new(ComplianceReportExport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ComponentResource) DeepCopyInto(out *ComponentResource) {
+func (in *ComplianceReportExportPVC) DeepCopyInto(out *ComplianceReportExportPVC) {
 	*out = *in
-	if in.ResourceRequirements != nil {
-		in, out := &in.ResourceRequirements, &out.ResourceRequirements
-		*out = new(corev1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentResource.
-func (in *ComponentResource) DeepCopy() *ComponentResource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceReportExportPVC.
+func (in *ComplianceReportExportPVC) DeepCopy() *ComplianceReportExportPVC {
 	if in == nil {
 		return nil
 	}
-	out := new(ComponentResource)
+	out := new(ComplianceReportExportPVC)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EksCloudwatchLogsSpec) DeepCopyInto(out *EksCloudwatchLogsSpec) {
+func (in *ComplianceReportExportS3) DeepCopyInto(out *ComplianceReportExportS3) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EksCloudwatchLogsSpec.
-func (in *EksCloudwatchLogsSpec) DeepCopy() *EksCloudwatchLogsSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceReportExportS3.
+func (in *ComplianceReportExportS3) DeepCopy() *ComplianceReportExportS3 {
 	if in == nil {
 		return nil
 	}
-	out := new(EksCloudwatchLogsSpec)
+	out := new(ComplianceReportExportS3)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GroupSearch) DeepCopyInto(out *GroupSearch) {
+func (in *ComplianceReportTemplate) DeepCopyInto(out *ComplianceReportTemplate) {
 	*out = *in
-	if in.UserMatchers != nil {
-		in, out := &in.UserMatchers, &out.UserMatchers
-		*out = make([]UserMatch, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupSearch.
-func (in *GroupSearch) DeepCopy() *GroupSearch {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceReportTemplate.
+func (in *ComplianceReportTemplate) DeepCopy() *ComplianceReportTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(GroupSearch)
+	out := new(ComplianceReportTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IPAMSpec) DeepCopyInto(out *IPAMSpec) {
+func (in *ComplianceReportType) DeepCopyInto(out *ComplianceReportType) {
 	*out = *in
+	out.UISummaryTemplate = in.UISummaryTemplate
+	if in.DownloadTemplates != nil {
+		in, out := &in.DownloadTemplates, &out.DownloadTemplates
+		*out = make([]ComplianceReportTemplate, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMSpec.
-func (in *IPAMSpec) DeepCopy() *IPAMSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceReportType.
+func (in *ComplianceReportType) DeepCopy() *ComplianceReportType {
 	if in == nil {
 		return nil
 	}
-	out := new(IPAMSpec)
+	out := new(ComplianceReportType)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IPPool) DeepCopyInto(out *IPPool) {
+func (in *ComplianceSnapshotter) DeepCopyInto(out *ComplianceSnapshotter) {
 	*out = *in
-	if in.BlockSize != nil {
-		in, out := &in.BlockSize, &out.BlockSize
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Hour != nil {
+		in, out := &in.Hour, &out.Hour
 		*out = new(int32)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPool.
-func (in *IPPool) DeepCopy() *IPPool {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceSnapshotter.
+func (in *ComplianceSnapshotter) DeepCopy() *ComplianceSnapshotter {
 	if in == nil {
 		return nil
 	}
-	out := new(IPPool)
+	out := new(ComplianceSnapshotter)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Image) DeepCopyInto(out *Image) {
+func (in *ComplianceSpec) DeepCopyInto(out *ComplianceSpec) {
 	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Image.
-func (in *Image) DeepCopy() *Image {
-	if in == nil {
-		return nil
+	if in.Reports != nil {
+		in, out := &in.Reports, &out.Reports
+		*out = make([]ComplianceReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out := new(Image)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+	if in.ReportTypes != nil {
+		in, out := &in.ReportTypes, &out.ReportTypes
+		*out = make([]ComplianceReportType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Snapshotter != nil {
+		in, out := &in.Snapshotter, &out.Snapshotter
+		*out = new(ComplianceSnapshotter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Benchmarker != nil {
+		in, out := &in.Benchmarker, &out.Benchmarker
+		*out = new(ComplianceBenchmarker)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReportRetention != nil {
+		in, out := &in.ReportRetention, &out.ReportRetention
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReportExport != nil {
+		in, out := &in.ReportExport, &out.ReportExport
+		*out = new(ComplianceReportExport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ComponentNetworkPolicy != nil {
+		in, out := &in.ComponentNetworkPolicy, &out.ComponentNetworkPolicy
+		*out = new(ComponentNetworkPolicyType)
+		**out = **in
+	}
+	if in.LogLevel != nil {
+		in, out := &in.LogLevel, &out.LogLevel
+		*out = new(LogLevel)
+		**out = **in
+	}
+	if in.ServerAutoscaling != nil {
+		in, out := &in.ServerAutoscaling, &out.ServerAutoscaling
+		*out = new(Autoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceSpec.
+func (in *ComplianceSpec) DeepCopy() *ComplianceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceStatus) DeepCopyInto(out *ComplianceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceStatus.
+func (in *ComplianceStatus) DeepCopy() *ComplianceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentResource) DeepCopyInto(out *ComponentResource) {
+	*out = *in
+	if in.ResourceRequirements != nil {
+		in, out := &in.ResourceRequirements, &out.ResourceRequirements
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentResource.
+func (in *ComponentResource) DeepCopy() *ComponentResource {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControlPlaneAvailability) DeepCopyInto(out *ControlPlaneAvailability) {
+	*out = *in
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(ControlPlaneAvailabilityMode)
+		**out = **in
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlPlaneAvailability.
+func (in *ControlPlaneAvailability) DeepCopy() *ControlPlaneAvailability {
+	if in == nil {
+		return nil
+	}
+	out := new(ControlPlaneAvailability)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeepPacketInspectionNodePool) DeepCopyInto(out *DeepPacketInspectionNodePool) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResourceRequirements != nil {
+		in, out := &in.ResourceRequirements, &out.ResourceRequirements
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeepPacketInspectionNodePool.
+func (in *DeepPacketInspectionNodePool) DeepCopy() *DeepPacketInspectionNodePool {
+	if in == nil {
+		return nil
+	}
+	out := new(DeepPacketInspectionNodePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeepPacketInspectionSpec) DeepCopyInto(out *DeepPacketInspectionSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]DeepPacketInspectionNodePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeepPacketInspectionSpec.
+func (in *DeepPacketInspectionSpec) DeepCopy() *DeepPacketInspectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeepPacketInspectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EksCloudwatchLogsSpec) DeepCopyInto(out *EksCloudwatchLogsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EksCloudwatchLogsSpec.
+func (in *EksCloudwatchLogsSpec) DeepCopy() *EksCloudwatchLogsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EksCloudwatchLogsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchSnapshots) DeepCopyInto(out *ElasticsearchSnapshots) {
+	*out = *in
+	in.Repository.DeepCopyInto(&out.Repository)
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(SnapshotRetention)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(SnapshotRestore)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchSnapshots.
+func (in *ElasticsearchSnapshots) DeepCopy() *ElasticsearchSnapshots {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchSnapshots)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchSnapshotsStatus) DeepCopyInto(out *ElasticsearchSnapshotsStatus) {
+	*out = *in
+	if in.LastSnapshotTime != nil {
+		in, out := &in.LastSnapshotTime, &out.LastSnapshotTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(SnapshotRestoreStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchSnapshotsStatus.
+func (in *ElasticsearchSnapshotsStatus) DeepCopy() *ElasticsearchSnapshotsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchSnapshotsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentdBufferSpec) DeepCopyInto(out *FluentdBufferSpec) {
+	*out = *in
+	if in.FlushInterval != nil {
+		in, out := &in.FlushInterval, &out.FlushInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ChunkLimitSize != nil {
+		in, out := &in.ChunkLimitSize, &out.ChunkLimitSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.TotalLimitSize != nil {
+		in, out := &in.TotalLimitSize, &out.TotalLimitSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.RetryMaxInterval != nil {
+		in, out := &in.RetryMaxInterval, &out.RetryMaxInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RetryTimeout != nil {
+		in, out := &in.RetryTimeout, &out.RetryTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RetryForever != nil {
+		in, out := &in.RetryForever, &out.RetryForever
+		*out = new(bool)
+		**out = **in
+	}
+	if in.OverflowAction != nil {
+		in, out := &in.OverflowAction, &out.OverflowAction
+		*out = new(FluentdBufferOverflowAction)
+		**out = **in
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(FluentdBufferStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DegradedThresholdPercent != nil {
+		in, out := &in.DegradedThresholdPercent, &out.DegradedThresholdPercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdBufferSpec.
+func (in *FluentdBufferSpec) DeepCopy() *FluentdBufferSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentdBufferSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentdBufferStorage) DeepCopyInto(out *FluentdBufferStorage) {
+	*out = *in
+	if in.SizeLimit != nil {
+		in, out := &in.SizeLimit, &out.SizeLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdBufferStorage.
+func (in *FluentdBufferStorage) DeepCopy() *FluentdBufferStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(FluentdBufferStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GroupSearch) DeepCopyInto(out *GroupSearch) {
+	*out = *in
+	if in.UserMatchers != nil {
+		in, out := &in.UserMatchers, &out.UserMatchers
+		*out = make([]UserMatch, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupSearch.
+func (in *GroupSearch) DeepCopy() *GroupSearch {
+	if in == nil {
+		return nil
+	}
+	out := new(GroupSearch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPAMSpec) DeepCopyInto(out *IPAMSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMSpec.
+func (in *IPAMSpec) DeepCopy() *IPAMSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IPAMSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPPool) DeepCopyInto(out *IPPool) {
+	*out = *in
+	if in.BlockSize != nil {
+		in, out := &in.BlockSize, &out.BlockSize
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPool.
+func (in *IPPool) DeepCopy() *IPPool {
+	if in == nil {
+		return nil
+	}
+	out := new(IPPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Image) DeepCopyInto(out *Image) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Image.
+func (in *Image) DeepCopy() *Image {
+	if in == nil {
+		return nil
+	}
+	out := new(Image)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageSet) DeepCopyInto(out *ImageSet) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
@@ -931,6 +1639,21 @@ func (in *ImageSetSpec) DeepCopy() *ImageSetSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageSignatureVerification) DeepCopyInto(out *ImageSignatureVerification) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSignatureVerification.
+func (in *ImageSignatureVerification) DeepCopy() *ImageSignatureVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageSignatureVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Indices) DeepCopyInto(out *Indices) {
 	*out = *in
@@ -1063,6 +1786,11 @@ func (in *InstallationSpec) DeepCopyInto(out *InstallationSpec) {
 		**out = **in
 	}
 	in.NodeUpdateStrategy.DeepCopyInto(&out.NodeUpdateStrategy)
+	if in.WindowsNodeUpgrade != nil {
+		in, out := &in.WindowsNodeUpgrade, &out.WindowsNodeUpgrade
+		*out = new(WindowsNodeUpgrade)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ComponentResources != nil {
 		in, out := &in.ComponentResources, &out.ComponentResources
 		*out = make([]ComponentResource, len(*in))
@@ -1080,6 +1808,45 @@ func (in *InstallationSpec) DeepCopyInto(out *InstallationSpec) {
 		*out = new(NonPrivilegedType)
 		**out = **in
 	}
+	if in.ComponentNetworkPolicy != nil {
+		in, out := &in.ComponentNetworkPolicy, &out.ComponentNetworkPolicy
+		*out = new(ComponentNetworkPolicyType)
+		**out = **in
+	}
+	if in.PodSecurityStandards != nil {
+		in, out := &in.PodSecurityStandards, &out.PodSecurityStandards
+		*out = make([]NamespacePodSecurityStandard, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImageSignatureVerification != nil {
+		in, out := &in.ImageSignatureVerification, &out.ImageSignatureVerification
+		*out = new(ImageSignatureVerification)
+		**out = **in
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(Logging)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ControlPlaneAvailability != nil {
+		in, out := &in.ControlPlaneAvailability, &out.ControlPlaneAvailability
+		*out = make([]ControlPlaneAvailability, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AWSSecurityGroups != nil {
+		in, out := &in.AWSSecurityGroups, &out.AWSSecurityGroups
+		*out = new(AWSSecurityGroups)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PacketCapture != nil {
+		in, out := &in.PacketCapture, &out.PacketCapture
+		*out = new(PacketCaptureSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationSpec.
@@ -1118,7 +1885,7 @@ func (in *IntrusionDetection) DeepCopyInto(out *IntrusionDetection) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetection.
@@ -1201,6 +1968,38 @@ func (in *IntrusionDetectionSpec) DeepCopyInto(out *IntrusionDetectionSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.AnomalyDetection != nil {
+		in, out := &in.AnomalyDetection, &out.AnomalyDetection
+		*out = new(AnomalyDetectionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DisabledGlobalAlertTemplates != nil {
+		in, out := &in.DisabledGlobalAlertTemplates, &out.DisabledGlobalAlertTemplates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ThreatFeeds != nil {
+		in, out := &in.ThreatFeeds, &out.ThreatFeeds
+		*out = make([]ThreatFeed, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeepPacketInspection != nil {
+		in, out := &in.DeepPacketInspection, &out.DeepPacketInspection
+		*out = new(DeepPacketInspectionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ComponentNetworkPolicy != nil {
+		in, out := &in.ComponentNetworkPolicy, &out.ComponentNetworkPolicy
+		*out = new(ComponentNetworkPolicyType)
+		**out = **in
+	}
+	if in.LogLevel != nil {
+		in, out := &in.LogLevel, &out.LogLevel
+		*out = new(LogLevel)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionSpec.
@@ -1216,6 +2015,13 @@ func (in *IntrusionDetectionSpec) DeepCopy() *IntrusionDetectionSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IntrusionDetectionStatus) DeepCopyInto(out *IntrusionDetectionStatus) {
 	*out = *in
+	if in.ThreatFeeds != nil {
+		in, out := &in.ThreatFeeds, &out.ThreatFeeds
+		*out = make([]ThreatFeedStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionStatus.
@@ -1285,6 +2091,41 @@ func (in *LogCollector) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogCollectorFilters) DeepCopyInto(out *LogCollectorFilters) {
+	*out = *in
+	if in.Flows != nil {
+		in, out := &in.Flows, &out.Flows
+		*out = new(LogFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(LogFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.L7 != nil {
+		in, out := &in.L7, &out.L7
+		*out = new(LogFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(LogFilter)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorFilters.
+func (in *LogCollectorFilters) DeepCopy() *LogCollectorFilters {
+	if in == nil {
+		return nil
+	}
+	out := new(LogCollectorFilters)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogCollectorList) DeepCopyInto(out *LogCollectorList) {
 	*out = *in
@@ -1335,6 +2176,26 @@ func (in *LogCollectorSpec) DeepCopyInto(out *LogCollectorSpec) {
 		*out = new(CollectProcessPathOption)
 		**out = **in
 	}
+	if in.ComponentNetworkPolicy != nil {
+		in, out := &in.ComponentNetworkPolicy, &out.ComponentNetworkPolicy
+		*out = new(ComponentNetworkPolicyType)
+		**out = **in
+	}
+	if in.Filters != nil {
+		in, out := &in.Filters, &out.Filters
+		*out = new(LogCollectorFilters)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Buffer != nil {
+		in, out := &in.Buffer, &out.Buffer
+		*out = new(FluentdBufferSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LogLevel != nil {
+		in, out := &in.LogLevel, &out.LogLevel
+		*out = new(LogLevel)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorSpec.
@@ -1362,13 +2223,84 @@ func (in *LogCollectorStatus) DeepCopy() *LogCollectorStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogFieldRedaction) DeepCopyInto(out *LogFieldRedaction) {
+	*out = *in
+	if in.Replacement != nil {
+		in, out := &in.Replacement, &out.Replacement
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogFieldRedaction.
+func (in *LogFieldRedaction) DeepCopy() *LogFieldRedaction {
+	if in == nil {
+		return nil
+	}
+	out := new(LogFieldRedaction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogFilter) DeepCopyInto(out *LogFilter) {
+	*out = *in
+	if in.IncludeNamespaces != nil {
+		in, out := &in.IncludeNamespaces, &out.IncludeNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeNamespaces != nil {
+		in, out := &in.ExcludeNamespaces, &out.ExcludeNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IncludeLabels != nil {
+		in, out := &in.IncludeLabels, &out.IncludeLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExcludeLabels != nil {
+		in, out := &in.ExcludeLabels, &out.ExcludeLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SampleRate != nil {
+		in, out := &in.SampleRate, &out.SampleRate
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Redactions != nil {
+		in, out := &in.Redactions, &out.Redactions
+		*out = make([]LogFieldRedaction, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogFilter.
+func (in *LogFilter) DeepCopy() *LogFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(LogFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogStorage) DeepCopyInto(out *LogStorage) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorage.
@@ -1473,6 +2405,21 @@ func (in *LogStorageSpec) DeepCopyInto(out *LogStorageSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ComponentNetworkPolicy != nil {
+		in, out := &in.ComponentNetworkPolicy, &out.ComponentNetworkPolicy
+		*out = new(ComponentNetworkPolicyType)
+		**out = **in
+	}
+	if in.ESGatewayAutoscaling != nil {
+		in, out := &in.ESGatewayAutoscaling, &out.ESGatewayAutoscaling
+		*out = new(Autoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ElasticsearchSnapshots != nil {
+		in, out := &in.ElasticsearchSnapshots, &out.ElasticsearchSnapshots
+		*out = new(ElasticsearchSnapshots)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageSpec.
@@ -1488,6 +2435,11 @@ func (in *LogStorageSpec) DeepCopy() *LogStorageSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogStorageStatus) DeepCopyInto(out *LogStorageStatus) {
 	*out = *in
+	if in.ElasticsearchSnapshots != nil {
+		in, out := &in.ElasticsearchSnapshots, &out.ElasticsearchSnapshots
+		*out = new(ElasticsearchSnapshotsStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageStatus.
@@ -1500,12 +2452,78 @@ func (in *LogStorageStatus) DeepCopy() *LogStorageStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Logging) DeepCopyInto(out *Logging) {
+	*out = *in
+	if in.Level != nil {
+		in, out := &in.Level, &out.Level
+		*out = new(LogLevel)
+		**out = **in
+	}
+	if in.TemporaryDebug != nil {
+		in, out := &in.TemporaryDebug, &out.TemporaryDebug
+		*out = new(TemporaryDebugLogging)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Logging.
+func (in *Logging) DeepCopy() *Logging {
+	if in == nil {
+		return nil
+	}
+	out := new(Logging)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]DayOfWeek, len(*in))
+		copy(*out, *in)
+	}
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedClusterEnrollment) DeepCopyInto(out *ManagedClusterEnrollment) {
+	*out = *in
+	if in.CertificateValidity != nil {
+		in, out := &in.CertificateValidity, &out.CertificateValidity
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedClusterEnrollment.
+func (in *ManagedClusterEnrollment) DeepCopy() *ManagedClusterEnrollment {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedClusterEnrollment)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagementCluster) DeepCopyInto(out *ManagementCluster) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementCluster.
@@ -1531,7 +2549,8 @@ func (in *ManagementClusterConnection) DeepCopyInto(out *ManagementClusterConnec
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterConnection.
@@ -1584,9 +2603,44 @@ func (in *ManagementClusterConnectionList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementClusterConnectionProxy) DeepCopyInto(out *ManagementClusterConnectionProxy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterConnectionProxy.
+func (in *ManagementClusterConnectionProxy) DeepCopy() *ManagementClusterConnectionProxy {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementClusterConnectionProxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagementClusterConnectionSpec) DeepCopyInto(out *ManagementClusterConnectionSpec) {
 	*out = *in
+	if in.ComponentNetworkPolicy != nil {
+		in, out := &in.ComponentNetworkPolicy, &out.ComponentNetworkPolicy
+		*out = new(ComponentNetworkPolicyType)
+		**out = **in
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ManagementClusterConnectionProxy)
+		**out = **in
+	}
+	if in.LogLevel != nil {
+		in, out := &in.LogLevel, &out.LogLevel
+		*out = new(LogLevel)
+		**out = **in
+	}
+	if in.CertificateRenewBefore != nil {
+		in, out := &in.CertificateRenewBefore, &out.CertificateRenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterConnectionSpec.
@@ -1599,6 +2653,29 @@ func (in *ManagementClusterConnectionSpec) DeepCopy() *ManagementClusterConnecti
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementClusterConnectionStatus) DeepCopyInto(out *ManagementClusterConnectionStatus) {
+	*out = *in
+	if in.LastGuardianAvailableTime != nil {
+		in, out := &in.LastGuardianAvailableTime, &out.LastGuardianAvailableTime
+		*out = (*in).DeepCopy()
+	}
+	if in.TunnelCertificateExpiry != nil {
+		in, out := &in.TunnelCertificateExpiry, &out.TunnelCertificateExpiry
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterConnectionStatus.
+func (in *ManagementClusterConnectionStatus) DeepCopy() *ManagementClusterConnectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementClusterConnectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagementClusterList) DeepCopyInto(out *ManagementClusterList) {
 	*out = *in
@@ -1634,6 +2711,11 @@ func (in *ManagementClusterList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagementClusterSpec) DeepCopyInto(out *ManagementClusterSpec) {
 	*out = *in
+	if in.Enrollment != nil {
+		in, out := &in.Enrollment, &out.Enrollment
+		*out = new(ManagedClusterEnrollment)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterSpec.
@@ -1673,6 +2755,103 @@ func (in *Manager) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagerExposure) DeepCopyInto(out *ManagerExposure) {
+	*out = *in
+	if in.LoadBalancer != nil {
+		in, out := &in.LoadBalancer, &out.LoadBalancer
+		*out = new(ManagerLoadBalancer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePort != nil {
+		in, out := &in.NodePort, &out.NodePort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(ManagerIngress)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Gateway != nil {
+		in, out := &in.Gateway, &out.Gateway
+		*out = new(ManagerGateway)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerExposure.
+func (in *ManagerExposure) DeepCopy() *ManagerExposure {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagerExposure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagerGateway) DeepCopyInto(out *ManagerGateway) {
+	*out = *in
+	if in.ParentRefs != nil {
+		in, out := &in.ParentRefs, &out.ParentRefs
+		*out = make([]ManagerGatewayParentRef, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerGateway.
+func (in *ManagerGateway) DeepCopy() *ManagerGateway {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagerGateway)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagerGatewayParentRef) DeepCopyInto(out *ManagerGatewayParentRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerGatewayParentRef.
+func (in *ManagerGatewayParentRef) DeepCopy() *ManagerGatewayParentRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagerGatewayParentRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagerIngress) DeepCopyInto(out *ManagerIngress) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerIngress.
+func (in *ManagerIngress) DeepCopy() *ManagerIngress {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagerIngress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagerList) DeepCopyInto(out *ManagerList) {
 	*out = *in
@@ -1705,6 +2884,33 @@ func (in *ManagerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagerLoadBalancer) DeepCopyInto(out *ManagerLoadBalancer) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SourceRanges != nil {
+		in, out := &in.SourceRanges, &out.SourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerLoadBalancer.
+func (in *ManagerLoadBalancer) DeepCopy() *ManagerLoadBalancer {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagerLoadBalancer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagerSpec) DeepCopyInto(out *ManagerSpec) {
 	*out = *in
@@ -1713,6 +2919,26 @@ func (in *ManagerSpec) DeepCopyInto(out *ManagerSpec) {
 		*out = new(Auth)
 		**out = **in
 	}
+	if in.ComponentNetworkPolicy != nil {
+		in, out := &in.ComponentNetworkPolicy, &out.ComponentNetworkPolicy
+		*out = new(ComponentNetworkPolicyType)
+		**out = **in
+	}
+	if in.Exposure != nil {
+		in, out := &in.Exposure, &out.Exposure
+		*out = new(ManagerExposure)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LogLevel != nil {
+		in, out := &in.LogLevel, &out.LogLevel
+		*out = new(LogLevel)
+		**out = **in
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(Autoscaling)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerSpec.
@@ -1750,7 +2976,7 @@ func (in *Monitor) DeepCopyInto(out *Monitor) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
@@ -1807,6 +3033,11 @@ func (in *MonitorList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MonitorSpec) DeepCopyInto(out *MonitorSpec) {
 	*out = *in
+	if in.ComponentNetworkPolicy != nil {
+		in, out := &in.ComponentNetworkPolicy, &out.ComponentNetworkPolicy
+		*out = new(ComponentNetworkPolicyType)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorSpec.
@@ -1834,6 +3065,36 @@ func (in *MonitorStatus) DeepCopy() *MonitorStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespacePodSecurityStandard) DeepCopyInto(out *NamespacePodSecurityStandard) {
+	*out = *in
+	if in.Enforce != nil {
+		in, out := &in.Enforce, &out.Enforce
+		*out = new(PodSecurityStandardMode)
+		**out = **in
+	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(PodSecurityStandardMode)
+		**out = **in
+	}
+	if in.Warn != nil {
+		in, out := &in.Warn, &out.Warn
+		*out = new(PodSecurityStandardMode)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacePodSecurityStandard.
+func (in *NamespacePodSecurityStandard) DeepCopy() *NamespacePodSecurityStandard {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespacePodSecurityStandard)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeAddressAutodetection) DeepCopyInto(out *NodeAddressAutodetection) {
 	*out = *in
@@ -1953,6 +3214,91 @@ func (in *Nodes) DeepCopy() *Nodes {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCSnapshotRepository) DeepCopyInto(out *PVCSnapshotRepository) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCSnapshotRepository.
+func (in *PVCSnapshotRepository) DeepCopy() *PVCSnapshotRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCSnapshotRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PacketCaptureSpec) DeepCopyInto(out *PacketCaptureSpec) {
+	*out = *in
+	if in.MaxFileSizeBytes != nil {
+		in, out := &in.MaxFileSizeBytes, &out.MaxFileSizeBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxFilesPerCapture != nil {
+		in, out := &in.MaxFilesPerCapture, &out.MaxFilesPerCapture
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RotationPeriod != nil {
+		in, out := &in.RotationPeriod, &out.RotationPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PacketCaptureSpec.
+func (in *PacketCaptureSpec) DeepCopy() *PacketCaptureSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PacketCaptureSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetSpec) DeepCopyInto(out *PodDisruptionBudgetSpec) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetSpec.
+func (in *PodDisruptionBudgetSpec) DeepCopy() *PodDisruptionBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodSecurityStandardMode) DeepCopyInto(out *PodSecurityStandardMode) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodSecurityStandardMode.
+func (in *PodSecurityStandardMode) DeepCopy() *PodSecurityStandardMode {
+	if in == nil {
+		return nil
+	}
+	out := new(PodSecurityStandardMode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Retention) DeepCopyInto(out *Retention) {
 	*out = *in
@@ -1998,6 +3344,21 @@ func (in *Retention) DeepCopy() *Retention {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3SnapshotRepository) DeepCopyInto(out *S3SnapshotRepository) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3SnapshotRepository.
+func (in *S3SnapshotRepository) DeepCopy() *S3SnapshotRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(S3SnapshotRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3StoreSpec) DeepCopyInto(out *S3StoreSpec) {
 	*out = *in
@@ -2013,6 +3374,104 @@ func (in *S3StoreSpec) DeepCopy() *S3StoreSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRepository) DeepCopyInto(out *SnapshotRepository) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3SnapshotRepository)
+		**out = **in
+	}
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(PVCSnapshotRepository)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRepository.
+func (in *SnapshotRepository) DeepCopy() *SnapshotRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRestore) DeepCopyInto(out *SnapshotRestore) {
+	*out = *in
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRestore.
+func (in *SnapshotRestore) DeepCopy() *SnapshotRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRestoreStatus) DeepCopyInto(out *SnapshotRestoreStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRestoreStatus.
+func (in *SnapshotRestoreStatus) DeepCopy() *SnapshotRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRetention) DeepCopyInto(out *SnapshotRetention) {
+	*out = *in
+	if in.ExpireAfterDays != nil {
+		in, out := &in.ExpireAfterDays, &out.ExpireAfterDays
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinCount != nil {
+		in, out := &in.MinCount, &out.MinCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxCount != nil {
+		in, out := &in.MaxCount, &out.MaxCount
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRetention.
+func (in *SnapshotRetention) DeepCopy() *SnapshotRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SplunkStoreSpec) DeepCopyInto(out *SplunkStoreSpec) {
 	*out = *in
@@ -2053,6 +3512,134 @@ func (in *SyslogStoreSpec) DeepCopy() *SyslogStoreSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemporaryDebugLogging) DeepCopyInto(out *TemporaryDebugLogging) {
+	*out = *in
+	out.Duration = in.Duration
+	if in.Until != nil {
+		in, out := &in.Until, &out.Until
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemporaryDebugLogging.
+func (in *TemporaryDebugLogging) DeepCopy() *TemporaryDebugLogging {
+	if in == nil {
+		return nil
+	}
+	out := new(TemporaryDebugLogging)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThreatFeed) DeepCopyInto(out *ThreatFeed) {
+	*out = *in
+	if in.Content != nil {
+		in, out := &in.Content, &out.Content
+		*out = new(ThreatFeedContent)
+		**out = **in
+	}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PullPeriod != nil {
+		in, out := &in.PullPeriod, &out.PullPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]ThreatFeedHeader, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GlobalNetworkSet != nil {
+		in, out := &in.GlobalNetworkSet, &out.GlobalNetworkSet
+		*out = new(ThreatFeedGlobalNetworkSet)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThreatFeed.
+func (in *ThreatFeed) DeepCopy() *ThreatFeed {
+	if in == nil {
+		return nil
+	}
+	out := new(ThreatFeed)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThreatFeedGlobalNetworkSet) DeepCopyInto(out *ThreatFeedGlobalNetworkSet) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThreatFeedGlobalNetworkSet.
+func (in *ThreatFeedGlobalNetworkSet) DeepCopy() *ThreatFeedGlobalNetworkSet {
+	if in == nil {
+		return nil
+	}
+	out := new(ThreatFeedGlobalNetworkSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThreatFeedHeader) DeepCopyInto(out *ThreatFeedHeader) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThreatFeedHeader.
+func (in *ThreatFeedHeader) DeepCopy() *ThreatFeedHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(ThreatFeedHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThreatFeedStatus) DeepCopyInto(out *ThreatFeedStatus) {
+	*out = *in
+	if in.LastSuccessfulSync != nil {
+		in, out := &in.LastSuccessfulSync, &out.LastSuccessfulSync
+		*out = (*in).DeepCopy()
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThreatFeedStatus.
+func (in *ThreatFeedStatus) DeepCopy() *ThreatFeedStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ThreatFeedStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TigeraStatus) DeepCopyInto(out *TigeraStatus) {
 	*out = *in
@@ -2214,3 +3801,37 @@ func (in *UserSearch) DeepCopy() *UserSearch {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WindowsNodeUpgrade) DeepCopyInto(out *WindowsNodeUpgrade) {
+	*out = *in
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CanaryNodeSelector != nil {
+		in, out := &in.CanaryNodeSelector, &out.CanaryNodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeTimeout != nil {
+		in, out := &in.NodeTimeout, &out.NodeTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WindowsNodeUpgrade.
+func (in *WindowsNodeUpgrade) DeepCopy() *WindowsNodeUpgrade {
+	if in == nil {
+		return nil
+	}
+	out := new(WindowsNodeUpgrade)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/go-logr/logr"
+
+	"github.com/tigera/operator/pkg/controller/awssecuritygroup"
+	"github.com/tigera/operator/pkg/controller/options"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AWSSecurityGroupReconciler reconciles the AWS security groups configured on the Installation.
+type AWSSecurityGroupReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+func (r *AWSSecurityGroupReconciler) SetupWithManager(mgr ctrl.Manager, opts options.AddOptions) error {
+	return awssecuritygroup.Add(mgr, opts)
+}
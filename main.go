@@ -16,12 +16,17 @@ package main
 
 import (
 	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
 	"flag"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	goruntime "runtime"
 	"strings"
+	"time"
 
 	"github.com/cloudflare/cfssl/log"
 	"github.com/ghodss/yaml"
@@ -30,6 +35,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -44,11 +50,16 @@ import (
 	"github.com/tigera/operator/pkg/apis"
 	"github.com/tigera/operator/pkg/awssgsetup"
 	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/compliance/export"
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/controller/options"
 	"github.com/tigera/operator/pkg/controller/utils"
 	"github.com/tigera/operator/pkg/crds"
 	"github.com/tigera/operator/pkg/dns"
+	"github.com/tigera/operator/pkg/enrollment"
+	"github.com/tigera/operator/pkg/imagemirror"
+	"github.com/tigera/operator/pkg/imageregistry"
+	"github.com/tigera/operator/pkg/threatfeed"
 	"github.com/tigera/operator/version"
 	// +kubebuilder:scaffold:imports
 )
@@ -83,10 +94,19 @@ func main() {
 	var urlOnlyKubeconfig string
 	var showVersion bool
 	var printImages string
+	var mirrorCfg imagemirror.Config
+	var mirrorVariant string
+	var mirrorOverrides string
+	var mirrorDigestSource string
+	var mirrorInsecure bool
 	var printCalicoCRDs string
 	var printEnterpriseCRDs string
 	var sgSetup bool
 	var manageCRDs bool
+	var enrollmentCfg enrollmentConfig
+	var threatFeedsAddr string
+	var threatFeedsDir string
+	var exportCfg exportConfig
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", true,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -95,7 +115,23 @@ func main() {
 	flag.BoolVar(&showVersion, "version", false,
 		"Show version information")
 	flag.StringVar(&printImages, "print-images", "",
-		"Print the default images the operator could deploy and exit. Possible values: list")
+		"Print the default images the operator could deploy and exit. Possible values: list, imageset, copylist. "+
+			"The imageset and copylist values require --image-mirror; imageset prints an ImageSet with the digests of the mirrored images "+
+			"and copylist prints the source and destination of each image to mirror, e.g. for `xargs -n2 crane copy`.")
+	flag.StringVar(&mirrorCfg.Registry, "image-mirror", "",
+		"Registry that the images are mirrored to, used with --print-images=imageset|copylist.")
+	flag.StringVar(&mirrorCfg.ImagePath, "image-mirror-path", "",
+		"Image path of the mirrored images, as set in the Installation imagePath.")
+	flag.StringVar(&mirrorCfg.ImagePrefix, "image-mirror-prefix", "",
+		"Image prefix of the mirrored images, as set in the Installation imagePrefix.")
+	flag.StringVar(&mirrorOverrides, "image-mirror-overrides", "",
+		"Comma separated list of <image>=<registry> for images mirrored to other registries. An image ending with a slash, e.g. tigera/, matches all images with that prefix.")
+	flag.StringVar(&mirrorVariant, "image-variant", string(operatorv1.Calico),
+		"Variant whose images are mirrored. Possible values: Calico, TigeraSecureEnterprise")
+	flag.StringVar(&mirrorDigestSource, "image-digest-source", "registry",
+		"Where the digests of the mirrored images are resolved. Possible values: registry, oci:<path to OCI image layout>")
+	flag.BoolVar(&mirrorInsecure, "image-mirror-insecure", false,
+		"Use plain HTTP to resolve digests from the mirror registry.")
 	flag.StringVar(&printCalicoCRDs, "print-calico-crds", "",
 		"Print the Calico CRDs the operator has bundled then exit. Possible values: all, <crd prefix>. If a value other than 'all' is specified, the first CRD with a prefix of the specified value will be printed.")
 	flag.StringVar(&printEnterpriseCRDs, "print-enterprise-crds", "",
@@ -104,6 +140,42 @@ func main() {
 		"Setup Security Groups in AWS (should only be used on OpenShift).")
 	flag.BoolVar(&manageCRDs, "manage-crds", false,
 		"Operator should manage the projectcalico.org and operator.tigera.io CRDs.")
+	flag.BoolVar(&enrollmentCfg.enabled, "managed-cluster-enrollment", false,
+		"Serve the endpoint managed clusters enroll through (should only be used in the manager pod of a management cluster).")
+	flag.StringVar(&enrollmentCfg.address, "enrollment-address", fmt.Sprintf(":%d", enrollment.DefaultPort),
+		"Address the managed cluster enrollment endpoint listens on.")
+	flag.StringVar(&enrollmentCfg.certFile, "enrollment-cert", "",
+		"Path to the certificate of the tunnel CA, which the enrollment endpoint serves with and signs managed cluster certificates with.")
+	flag.StringVar(&enrollmentCfg.keyFile, "enrollment-key", "",
+		"Path to the private key of the tunnel CA.")
+	flag.StringVar(&enrollmentCfg.namespace, "enrollment-namespace", "",
+		"Namespace of the managed cluster bootstrap token secrets.")
+	flag.DurationVar(&enrollmentCfg.validity, "enrollment-certificate-validity", enrollment.DefaultCertificateValidity,
+		"How long the certificates issued to managed clusters are valid for.")
+	flag.StringVar(&threatFeedsAddr, "serve-threat-feeds", "",
+		"Serve the threat feeds kept in ConfigMaps on the address (should only be used in the intrusion detection controller pod).")
+	flag.StringVar(&threatFeedsDir, "threat-feeds-dir", threatfeed.DefaultDir,
+		"Directory the ConfigMaps of the threat feeds are mounted in.")
+	flag.BoolVar(&exportCfg.enabled, "export-compliance-reports", false,
+		"Export the compliance reports that have not been exported yet and exit (should only be used in the compliance report exporter job).")
+	flag.StringVar(&exportCfg.serverURL, "export-server-url", "",
+		"URL of the compliance server the reports are exported from.")
+	flag.StringVar(&exportCfg.caFile, "export-ca-file", "",
+		"Path to the CA bundle the certificate of the compliance server is verified with.")
+	flag.StringVar(&exportCfg.tokenFile, "export-token-file", "/var/run/secrets/kubernetes.io/serviceaccount/token",
+		"Path to the token the compliance server authorizes the exporter with.")
+	flag.StringVar(&exportCfg.dir, "export-dir", "",
+		"Directory the reports are exported to. Either this or --export-s3-bucket must be set.")
+	flag.StringVar(&exportCfg.s3Endpoint, "export-s3-endpoint", "",
+		"URL of the S3-compatible service the reports are exported to. Defaults to AWS S3.")
+	flag.StringVar(&exportCfg.s3Region, "export-s3-region", "us-east-1",
+		"Region of the bucket the reports are exported to.")
+	flag.StringVar(&exportCfg.s3Bucket, "export-s3-bucket", "",
+		"Bucket the reports are exported to. The credentials are taken from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.")
+	flag.StringVar(&exportCfg.s3Path, "export-s3-path", "",
+		"Prefix of the keys the reports are exported under.")
+	flag.BoolVar(&exportCfg.s3ForcePathStyle, "export-s3-force-path-style", false,
+		"Address the bucket in the path of the URL rather than in the host name.")
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
@@ -129,6 +201,20 @@ func main() {
 			}
 			os.Exit(0)
 		}
+		if strings.ToLower(printImages) == "imageset" || strings.ToLower(printImages) == "copylist" {
+			mirrorCfg.Variant = operatorv1.ProductVariant(mirrorVariant)
+			overrides, err := imagemirror.ParseRegistryOverrides(mirrorOverrides)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			mirrorCfg.RegistryOverrides = overrides
+			if err := showMirrorImages(mirrorCfg, strings.ToLower(printImages), mirrorDigestSource, mirrorInsecure); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
 		fmt.Println("Invalid option for --print-images flag", printImages)
 		os.Exit(1)
 	}
@@ -148,6 +234,26 @@ func main() {
 		os.Exit(0)
 	}
 
+	// The threat feeds are served from the intrusion detection controller pod, and do not need access to the cluster.
+	if threatFeedsAddr != "" {
+		log.Info("Serving threat feeds", "dir", threatFeedsDir)
+		if err := threatfeed.Serve(ctrl.SetupSignalHandler(), threatFeedsAddr, threatFeedsDir); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// The exporter only talks to the compliance server and the object storage, not to the cluster.
+	if exportCfg.enabled {
+		log.Info("Exporting compliance reports")
+		if err := exportComplianceReports(ctrl.SetupSignalHandler(), exportCfg); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	if urlOnlyKubeconfig != "" {
 		if err := setKubernetesServiceEnv(urlOnlyKubeconfig); err != nil {
 			setupLog.Error(err, "Terminating")
@@ -191,6 +297,17 @@ func main() {
 		os.Exit(0)
 	}
 
+	// The enrollment endpoint runs in the manager pod, next to voltron, rather than as part of the operator.
+	if enrollmentCfg.enabled {
+		log.Info("Serving the managed cluster enrollment endpoint")
+
+		if err = serveEnrollment(ctrl.SetupSignalHandler(), cfg, enrollmentCfg); err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	sigHandler := ctrl.SetupSignalHandler()
 	active.WaitUntilActive(cs, c, sigHandler, setupLog)
 	log.Info("Active operator: proceeding")
@@ -339,6 +456,49 @@ func metricsAddr() string {
 	return fmt.Sprintf("%s:%s", metricsHost, metricsPort)
 }
 
+func showMirrorImages(cfg imagemirror.Config, outputType, digestSource string, insecure bool) error {
+	if cfg.Registry == "" {
+		return fmt.Errorf("--image-mirror must be specified")
+	}
+	if cfg.Variant != operatorv1.Calico && cfg.Variant != operatorv1.TigeraSecureEnterprise {
+		return fmt.Errorf("Invalid option for --image-variant flag %s", cfg.Variant)
+	}
+	images, err := imagemirror.Images(cfg)
+	if err != nil {
+		return err
+	}
+	if outputType == "copylist" {
+		return imagemirror.WriteCopyList(os.Stdout, images)
+	}
+
+	var resolver imagemirror.Resolver
+	switch {
+	case digestSource == "registry":
+		resolver = &imagemirror.RegistryResolver{Client: imageregistry.Client{
+			Insecure: insecure,
+			Username: os.Getenv("IMAGE_MIRROR_USERNAME"),
+			Password: os.Getenv("IMAGE_MIRROR_PASSWORD"),
+		}}
+	case strings.HasPrefix(digestSource, "oci:"):
+		if resolver, err = imagemirror.NewOCILayoutResolver(strings.TrimPrefix(digestSource, "oci:")); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Invalid option for --image-digest-source flag %s", digestSource)
+	}
+
+	is, err := imagemirror.BuildImageSet(context.Background(), cfg.Variant, images, resolver)
+	if err != nil {
+		return err
+	}
+	b, err := yaml.Marshal(is)
+	if err != nil {
+		return fmt.Errorf("Failed to Marshal ImageSet: %v", err)
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
 func showCRDs(variant operatorv1.ProductVariant, outputType string) error {
 	first := true
 	for _, v := range crds.GetCRDs(variant) {
@@ -366,3 +526,99 @@ func showCRDs(variant operatorv1.ProductVariant, outputType string) error {
 
 	return nil
 }
+
+type enrollmentConfig struct {
+	enabled   bool
+	address   string
+	certFile  string
+	keyFile   string
+	namespace string
+	validity  time.Duration
+}
+
+// serveEnrollment serves the managed cluster enrollment endpoint with the tunnel CA until the context is done.
+func serveEnrollment(ctx context.Context, cfg *rest.Config, ec enrollmentConfig) error {
+	cert, err := tls.LoadX509KeyPair(ec.certFile, ec.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load the tunnel CA: %w", err)
+	}
+	ca, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse the tunnel CA: %w", err)
+	}
+	signer, ok := cert.PrivateKey.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("the tunnel CA key cannot sign certificates")
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	srv := &enrollment.Server{
+		Client:    c,
+		Namespace: ec.namespace,
+		CA:        ca,
+		CAKey:     signer,
+		Validity:  ec.validity,
+	}
+	return srv.Serve(ctx, ec.address, cert)
+}
+
+type exportConfig struct {
+	enabled          bool
+	serverURL        string
+	caFile           string
+	tokenFile        string
+	dir              string
+	s3Endpoint       string
+	s3Region         string
+	s3Bucket         string
+	s3Path           string
+	s3ForcePathStyle bool
+}
+
+// exportComplianceReports exports the compliance reports that have not been exported yet to a directory or bucket.
+func exportComplianceReports(ctx context.Context, ec exportConfig) error {
+	var store export.Store
+	switch {
+	case ec.dir != "" && ec.s3Bucket != "":
+		return fmt.Errorf("only one of --export-dir and --export-s3-bucket can be set")
+	case ec.dir != "":
+		store = &export.FileStore{Dir: ec.dir}
+	case ec.s3Bucket != "":
+		s3Store, err := export.NewS3Store(ec.s3Endpoint, ec.s3Region, ec.s3Bucket, ec.s3Path, ec.s3ForcePathStyle)
+		if err != nil {
+			return err
+		}
+		store = s3Store
+	default:
+		return fmt.Errorf("one of --export-dir and --export-s3-bucket must be set")
+	}
+
+	tlsCfg := &tls.Config{}
+	if ec.caFile != "" {
+		pem, err := os.ReadFile(ec.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the CA bundle: %w", err)
+		}
+		tlsCfg.RootCAs = x509.NewCertPool()
+		if !tlsCfg.RootCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", ec.caFile)
+		}
+	}
+	token, err := os.ReadFile(ec.tokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the token: %w", err)
+	}
+
+	e := &export.Exporter{
+		Client:    &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}, Timeout: 5 * time.Minute},
+		ServerURL: ec.serverURL,
+		Token:     strings.TrimSpace(string(token)),
+		Store:     store,
+	}
+	n, err := e.Run(ctx)
+	log.Info("Exported compliance reports", "count", n)
+	return err
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	KindCalicoNodeStatus     = "CalicoNodeStatus"
+	KindCalicoNodeStatusList = "CalicoNodeStatusList"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CalicoNodeStatus is a request for calico/node to report its status into the object.
+type CalicoNodeStatus struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Specification of the CalicoNodeStatus.
+	Spec CalicoNodeStatusSpec `json:"spec,omitempty"`
+	// Status of the CalicoNodeStatus, written by calico/node.
+	Status CalicoNodeStatusStatus `json:"status,omitempty"`
+}
+
+// CalicoNodeStatusSpec contains the specification for a CalicoNodeStatus resource.
+type CalicoNodeStatusSpec struct {
+	// The node name identifies the Calico node instance for node status.
+	Node string `json:"node,omitempty"`
+
+	// Classes declares the types of information to monitor for this calico/node.
+	Classes []NodeStatusClassType `json:"classes,omitempty"`
+
+	// UpdatePeriodSeconds is the period at which CalicoNodeStatus should be updated.
+	// Set to 0 to disable CalicoNodeStatus refresh. Maximum update period is one day.
+	UpdatePeriodSeconds *uint32 `json:"updatePeriodSeconds,omitempty"`
+}
+
+// CalicoNodeStatusStatus defines the observed state of CalicoNodeStatus.
+type CalicoNodeStatusStatus struct {
+	// LastUpdated is a timestamp representing the server time when CalicoNodeStatus object last updated.
+	// +nullable
+	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+
+	// Agent holds agent status on the node.
+	Agent CalicoNodeAgentStatus `json:"agent,omitempty"`
+
+	// BGP holds node BGP status.
+	BGP CalicoNodeBGPStatus `json:"bgp,omitempty"`
+}
+
+// CalicoNodeAgentStatus defines the observed state of agent status on the node.
+type CalicoNodeAgentStatus struct {
+	// BIRDV4 represents the latest observed status of bird4.
+	BIRDV4 BGPDaemonStatus `json:"birdV4,omitempty"`
+
+	// BIRDV6 represents the latest observed status of bird6.
+	BIRDV6 BGPDaemonStatus `json:"birdV6,omitempty"`
+}
+
+// BGPDaemonStatus defines the observed state of a BGP daemon.
+type BGPDaemonStatus struct {
+	// The state of the BGP daemon.
+	State BGPDaemonState `json:"state,omitempty"`
+}
+
+// CalicoNodeBGPStatus defines the observed state of BGP status on the node.
+type CalicoNodeBGPStatus struct {
+	// The total number of IPv4 established bgp sessions.
+	NumberEstablishedV4 int `json:"numberEstablishedV4"`
+
+	// The total number of IPv4 non-established bgp sessions.
+	NumberNotEstablishedV4 int `json:"numberNotEstablishedV4"`
+
+	// The total number of IPv6 established bgp sessions.
+	NumberEstablishedV6 int `json:"numberEstablishedV6"`
+
+	// The total number of IPv6 non-established bgp sessions.
+	NumberNotEstablishedV6 int `json:"numberNotEstablishedV6"`
+}
+
+type NodeStatusClassType string
+
+const (
+	NodeStatusClassTypeAgent  NodeStatusClassType = "Agent"
+	NodeStatusClassTypeBGP    NodeStatusClassType = "BGP"
+	NodeStatusClassTypeRoutes NodeStatusClassType = "Routes"
+)
+
+type BGPDaemonState string
+
+const (
+	BGPDaemonStateReady    BGPDaemonState = "Ready"
+	BGPDaemonStateNotReady BGPDaemonState = "NotReady"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CalicoNodeStatusList contains a list of CalicoNodeStatus resources.
+type CalicoNodeStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []CalicoNodeStatus `json:"items"`
+}
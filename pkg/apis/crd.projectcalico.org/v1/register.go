@@ -42,6 +42,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&KubeControllersConfigurationList{},
 		&BGPConfiguration{},
 		&BGPConfigurationList{},
+		&CalicoNodeStatus{},
+		&CalicoNodeStatusList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -149,6 +149,21 @@ func (in *BGPConfigurationSpec) DeepCopy() *BGPConfigurationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BGPDaemonStatus) DeepCopyInto(out *BGPDaemonStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BGPDaemonStatus.
+func (in *BGPDaemonStatus) DeepCopy() *BGPDaemonStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BGPDaemonStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BGPPassword) DeepCopyInto(out *BGPPassword) {
 	*out = *in
@@ -169,6 +184,140 @@ func (in *BGPPassword) DeepCopy() *BGPPassword {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalicoNodeAgentStatus) DeepCopyInto(out *CalicoNodeAgentStatus) {
+	*out = *in
+	out.BIRDV4 = in.BIRDV4
+	out.BIRDV6 = in.BIRDV6
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoNodeAgentStatus.
+func (in *CalicoNodeAgentStatus) DeepCopy() *CalicoNodeAgentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CalicoNodeAgentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalicoNodeBGPStatus) DeepCopyInto(out *CalicoNodeBGPStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoNodeBGPStatus.
+func (in *CalicoNodeBGPStatus) DeepCopy() *CalicoNodeBGPStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CalicoNodeBGPStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalicoNodeStatus) DeepCopyInto(out *CalicoNodeStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoNodeStatus.
+func (in *CalicoNodeStatus) DeepCopy() *CalicoNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CalicoNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CalicoNodeStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalicoNodeStatusList) DeepCopyInto(out *CalicoNodeStatusList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CalicoNodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoNodeStatusList.
+func (in *CalicoNodeStatusList) DeepCopy() *CalicoNodeStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(CalicoNodeStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CalicoNodeStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalicoNodeStatusSpec) DeepCopyInto(out *CalicoNodeStatusSpec) {
+	*out = *in
+	if in.Classes != nil {
+		in, out := &in.Classes, &out.Classes
+		*out = make([]NodeStatusClassType, len(*in))
+		copy(*out, *in)
+	}
+	if in.UpdatePeriodSeconds != nil {
+		in, out := &in.UpdatePeriodSeconds, &out.UpdatePeriodSeconds
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoNodeStatusSpec.
+func (in *CalicoNodeStatusSpec) DeepCopy() *CalicoNodeStatusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CalicoNodeStatusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalicoNodeStatusStatus) DeepCopyInto(out *CalicoNodeStatusStatus) {
+	*out = *in
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+	out.Agent = in.Agent
+	out.BGP = in.BGP
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoNodeStatusStatus.
+func (in *CalicoNodeStatusStatus) DeepCopy() *CalicoNodeStatusStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CalicoNodeStatusStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Community) DeepCopyInto(out *Community) {
 	*out = *in
@@ -25,6 +25,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -46,37 +47,16 @@ func SetupAWSSecurityGroups(ctx context.Context, client client.Client) error {
 		return fmt.Errorf("failed to get AWS credentials: %v", err)
 	}
 
-	metaSess, err := session.NewSession()
-	if err != nil {
-		return fmt.Errorf("failed to get metadata session: %v", err)
-	}
-
-	meta := ec2metadata.New(metaSess)
-	if !meta.Available() {
-		return fmt.Errorf("Instance metadata is not available, unable to configure Security Groups")
-	}
-
-	doc, err := meta.GetInstanceIdentityDocument()
-	if err != nil {
-		return fmt.Errorf("failed to get metadata document: %v", err)
-	}
-
-	region := doc.Region
-	vpcId, err := getVPCid(meta)
+	region, vpcId, err := InstanceMetadata()
 	if err != nil {
 		return fmt.Errorf("failed to update AWS SecurityGroups: %v", err)
 	}
 
-	sess, err := session.NewSession(&aws.Config{
-		Region:      aws.String(region),
-		Credentials: credentials.NewStaticCredentials(awsKeyId, awsSecret, ""),
-	})
+	ec2Cli, err := NewEC2Client(region, credentials.NewStaticCredentials(awsKeyId, awsSecret, ""))
 	if err != nil {
 		return fmt.Errorf("failed to update AWS SecurityGroups: %v", err)
 	}
 
-	ec2Cli := ec2.New(sess)
-
 	// Get SG ids in VPC
 	// Get one with filter tag:Name with *-master-sg
 	// Get one with filter tag:Name with *-worker-sg
@@ -90,44 +70,9 @@ func SetupAWSSecurityGroups(ctx context.Context, client client.Client) error {
 	}
 
 	// # Add rules to master and worker SG that allow incoming from master and worker for BGP, IPIP, Typha comms
-	src := []ingressSrc{
-		{
-			srcSGId:  aws.StringValue(masterSg.GroupId),
-			protocol: "tcp",
-			port:     aws.Int64(179),
-		},
-		{
-			srcSGId:  aws.StringValue(masterSg.GroupId),
-			protocol: "4",
-		},
-		{
-			srcSGId:  aws.StringValue(masterSg.GroupId),
-			protocol: "tcp",
-			port:     aws.Int64(5473),
-		},
-		{
-			srcSGId:  aws.StringValue(workerSg.GroupId),
-			protocol: "tcp",
-			port:     aws.Int64(179),
-		},
-		{
-			srcSGId:  aws.StringValue(workerSg.GroupId),
-			protocol: "4",
-		},
-		{
-			srcSGId:  aws.StringValue(workerSg.GroupId),
-			protocol: "tcp",
-			port:     aws.Int64(5473),
-		},
-	}
-	err = allowIngressToSG(ec2Cli, masterSg, src)
-	if err != nil {
-		return fmt.Errorf("failed to update master AWS SecurityGroup: %v", err)
-	}
-
-	err = allowIngressToSG(ec2Cli, workerSg, src)
-	if err != nil {
-		return fmt.Errorf("failed to update worker AWS SecurityGroup: %v", err)
+	rules := []Rule{bgpRule, ipipRule, typhaRule}
+	if _, err := AllowIngress(ec2Cli, []*ec2.SecurityGroup{masterSg, workerSg}, rules); err != nil {
+		return fmt.Errorf("failed to update AWS SecurityGroups: %v", err)
 	}
 
 	return nil
@@ -157,6 +102,30 @@ func getAWSCreds(ctx context.Context, client client.Client) (id, secret string,
 	return string(idByte), string(secretByte), nil
 }
 
+// InstanceMetadata returns the region and the VPC id of the instance the operator is running on.
+func InstanceMetadata() (region, vpcID string, err error) {
+	metaSess, err := session.NewSession()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get metadata session: %v", err)
+	}
+
+	meta := ec2metadata.New(metaSess)
+	if !meta.Available() {
+		return "", "", fmt.Errorf("Instance metadata is not available, unable to configure Security Groups")
+	}
+
+	doc, err := meta.GetInstanceIdentityDocument()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get metadata document: %v", err)
+	}
+
+	vpcID, err = getVPCid(meta)
+	if err != nil {
+		return "", "", err
+	}
+	return doc.Region, vpcID, nil
+}
+
 // getVPCid gets the VPC id by querying the instance metadata.
 func getVPCid(meta *ec2metadata.EC2Metadata) (string, error) {
 	mac, err := meta.GetMetadata("mac")
@@ -178,7 +147,7 @@ func getVPCid(meta *ec2metadata.EC2Metadata) (string, error) {
 
 // getSGGroup returns the first SG that is in the specified VPC and matches the nameFilter.
 // nameFilter matches tag:Name.
-func getSGGroup(cli *ec2.EC2, vpcId string, nameFilter string) (*ec2.SecurityGroup, error) {
+func getSGGroup(cli ec2iface.EC2API, vpcId string, nameFilter string) (*ec2.SecurityGroup, error) {
 	in := &ec2.DescribeSecurityGroupsInput{}
 	in.SetFilters([]*ec2.Filter{
 		&ec2.Filter{
@@ -243,8 +212,9 @@ func ingressSrcMatchesIpPermission(s ingressSrc, ipp *ec2.IpPermission) bool {
 
 // allowIngressToSG adds rules to the toSG Security Group for each element of sources.
 // Before attempting to add a rule the function checks the toSG to see if the rule already exists.
-// If there is an error adding the rules then an error is returned.
-func allowIngressToSG(cli *ec2.EC2, toSG *ec2.SecurityGroup, sources []ingressSrc) error {
+// If there is an error adding the rules then an error is returned. The number of rules added is returned.
+func allowIngressToSG(cli ec2iface.EC2API, toSG *ec2.SecurityGroup, sources []ingressSrc) (int, error) {
+	added := 0
 	in := &ec2.AuthorizeSecurityGroupIngressInput{}
 	sgId := aws.StringValue(toSG.GroupId)
 	in.SetGroupId(sgId)
@@ -274,10 +244,13 @@ func allowIngressToSG(cli *ec2.EC2, toSG *ec2.SecurityGroup, sources []ingressSr
 		}})
 		_, err := cli.AuthorizeSecurityGroupIngress(in)
 		if err != nil {
-			return fmt.Errorf("Failed to add to SG '%s' the ingress rule '%s': %v: %v", sgId, s.String(), toSG, err)
+			return added, fmt.Errorf("Failed to add to SG '%s' the ingress rule '%s': %v: %v", sgId, s.String(), toSG, err)
 		}
+		added++
 		log.V(DEBUG).Info("Added Ingress rule", "toSG.GroupId", sgId, "ingressSrc", s.String())
 	}
-	log.Info("Ingress configured for Security Group", "toSG.GroupId", sgId)
-	return nil
+	if added > 0 {
+		log.Info("Ingress configured for Security Group", "toSG.GroupId", sgId, "added", added)
+	}
+	return added, nil
 }
@@ -0,0 +1,209 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awssgsetup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	crdv1 "github.com/tigera/operator/pkg/apis/crd.projectcalico.org/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+)
+
+const (
+	defaultVXLANPort     = 4789
+	defaultWireguardPort = 51820
+)
+
+var (
+	bgpRule   = Rule{Protocol: "tcp", Port: aws.Int64(179)}
+	ipipRule  = Rule{Protocol: "4"}
+	typhaRule = Rule{Protocol: "tcp", Port: aws.Int64(int64(render.TyphaPort))}
+)
+
+// Rule is an ingress rule that must be allowed between the nodes of the cluster.
+// A nil Port means the rule applies to the whole protocol.
+type Rule struct {
+	Protocol string
+	Port     *int64
+}
+
+// Rules returns the ingress rules Calico needs between the nodes of the cluster for the
+// given installation and FelixConfiguration. The FelixConfiguration may be nil.
+func Rules(installation *operatorv1.InstallationSpec, felix *crdv1.FelixConfiguration) []Rule {
+	var rules []Rule
+	if cn := installation.CalicoNetwork; cn != nil {
+		if cn.BGP != nil && *cn.BGP == operatorv1.BGPEnabled {
+			rules = append(rules, bgpRule)
+		}
+
+		var ipip, vxlan bool
+		for _, pool := range cn.IPPools {
+			switch pool.Encapsulation {
+			case operatorv1.EncapsulationIPIP, operatorv1.EncapsulationIPIPCrossSubnet:
+				ipip = true
+			case operatorv1.EncapsulationVXLAN, operatorv1.EncapsulationVXLANCrossSubnet:
+				vxlan = true
+			}
+		}
+		if ipip {
+			rules = append(rules, ipipRule)
+		}
+		if vxlan {
+			port := defaultVXLANPort
+			if felix != nil && felix.Spec.VXLANPort != nil {
+				port = *felix.Spec.VXLANPort
+			}
+			rules = append(rules, Rule{Protocol: "udp", Port: aws.Int64(int64(port))})
+		}
+	}
+
+	rules = append(rules, typhaRule)
+
+	if felix != nil && felix.Spec.WireguardEnabled != nil && *felix.Spec.WireguardEnabled {
+		port := defaultWireguardPort
+		if felix.Spec.WireguardListeningPort != nil {
+			port = *felix.Spec.WireguardListeningPort
+		}
+		rules = append(rules, Rule{Protocol: "udp", Port: aws.Int64(int64(port))})
+	}
+	return rules
+}
+
+// AllowIngress adds the rules to every one of the groups, allowing traffic from all of the groups.
+// Rules that already exist are left alone, so calling it again adds back any rule that was removed.
+// The number of rules added is returned.
+func AllowIngress(cli ec2iface.EC2API, groups []*ec2.SecurityGroup, rules []Rule) (int, error) {
+	var sources []ingressSrc
+	for _, from := range groups {
+		for _, rule := range rules {
+			sources = append(sources, ingressSrc{
+				srcSGId:  aws.StringValue(from.GroupId),
+				protocol: rule.Protocol,
+				port:     rule.Port,
+			})
+		}
+	}
+
+	added := 0
+	for _, to := range groups {
+		n, err := allowIngressToSG(cli, to, sources)
+		added += n
+		if err != nil {
+			return added, err
+		}
+	}
+	return added, nil
+}
+
+// SecurityGroups returns the security groups with the given ids, along with the security groups in
+// the VPC that have all the tags of one of the selectors. Each group is returned once.
+func SecurityGroups(cli ec2iface.EC2API, vpcID string, ids []string, selectors []operatorv1.AWSSecurityGroupSelector) ([]*ec2.SecurityGroup, error) {
+	var groups []*ec2.SecurityGroup
+	seen := map[string]bool{}
+	add := func(out *ec2.DescribeSecurityGroupsOutput) {
+		for _, sg := range out.SecurityGroups {
+			if !seen[aws.StringValue(sg.GroupId)] {
+				seen[aws.StringValue(sg.GroupId)] = true
+				groups = append(groups, sg)
+			}
+		}
+	}
+
+	if len(ids) > 0 {
+		out, err := cli.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{GroupIds: aws.StringSlice(ids)})
+		if err != nil {
+			return nil, err
+		}
+		add(out)
+	}
+
+	for _, selector := range selectors {
+		filters := []*ec2.Filter{{
+			Name:   aws.String("vpc-id"),
+			Values: []*string{aws.String(vpcID)},
+		}}
+		var keys []string
+		for k := range selector.Tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			filters = append(filters, &ec2.Filter{
+				Name:   aws.String("tag:" + k),
+				Values: []*string{aws.String(selector.Tags[k])},
+			})
+		}
+
+		out, err := cli.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{Filters: filters})
+		if err != nil {
+			return nil, err
+		}
+		if len(out.SecurityGroups) == 0 {
+			return nil, fmt.Errorf("no security groups in VPC %s match the tags %v", vpcID, selector.Tags)
+		}
+		log.V(TRACE).Info("DescribeSecurityGroups", "SecurityGroupOutput", out)
+		add(out)
+	}
+	return groups, nil
+}
+
+// NewEC2Client returns an EC2 client for the region. When creds is nil the default credential chain
+// is used, which picks up an IAM role for the operator's service account (IRSA) or the instance role.
+func NewEC2Client(region string, creds *credentials.Credentials) (ec2iface.EC2API, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(region),
+		Credentials: creds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ec2.New(sess), nil
+}
+
+// Credentials reads the AWS credentials from the named secret in the operator namespace. It returns
+// nil credentials when no secret is named.
+func Credentials(ctx context.Context, cli client.Client, secretName string) (*credentials.Credentials, error) {
+	if secretName == "" {
+		return nil, nil
+	}
+
+	secret := &v1.Secret{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: secretName, Namespace: common.OperatorNamespace()}, secret); err != nil {
+		return nil, err
+	}
+
+	id, ok := secret.Data["aws_access_key_id"]
+	if !ok {
+		return nil, fmt.Errorf("secret %s does not have key aws_access_key_id", secretName)
+	}
+	key, ok := secret.Data["aws_secret_access_key"]
+	if !ok {
+		return nil, fmt.Errorf("secret %s does not have key aws_secret_access_key", secretName)
+	}
+	return credentials.NewStaticCredentials(string(id), string(key), string(secret.Data["aws_session_token"])), nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awssgsetup
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	crdv1 "github.com/tigera/operator/pkg/apis/crd.projectcalico.org/v1"
+)
+
+var _ = Describe("AWS security group rules", func() {
+	bgpEnabled := operatorv1.BGPEnabled
+	bgpDisabled := operatorv1.BGPDisabled
+	vxlanPort := 4790
+	wireguardEnabled := true
+	wireguardPort := 51830
+
+	network := func(bgp *operatorv1.BGPOption, encaps ...operatorv1.EncapsulationType) *operatorv1.InstallationSpec {
+		cn := &operatorv1.CalicoNetworkSpec{BGP: bgp}
+		for _, e := range encaps {
+			cn.IPPools = append(cn.IPPools, operatorv1.IPPool{Encapsulation: e})
+		}
+		return &operatorv1.InstallationSpec{CalicoNetwork: cn}
+	}
+
+	udp := func(port int64) Rule { return Rule{Protocol: "udp", Port: aws.Int64(port)} }
+
+	table.DescribeTable("derives the rules from the installation", func(installation *operatorv1.InstallationSpec, felix *crdv1.FelixConfiguration, expected []Rule) {
+		Expect(Rules(installation, felix)).To(Equal(expected))
+	},
+		table.Entry("no network", &operatorv1.InstallationSpec{}, nil, []Rule{typhaRule}),
+		table.Entry("BGP with IPIP", network(&bgpEnabled, operatorv1.EncapsulationIPIP), nil, []Rule{bgpRule, ipipRule, typhaRule}),
+		table.Entry("VXLAN without BGP", network(&bgpDisabled, operatorv1.EncapsulationVXLANCrossSubnet), nil, []Rule{udp(4789), typhaRule}),
+		table.Entry("VXLAN on the configured port", network(&bgpDisabled, operatorv1.EncapsulationVXLAN),
+			&crdv1.FelixConfiguration{Spec: crdv1.FelixConfigurationSpec{VXLANPort: &vxlanPort}},
+			[]Rule{udp(4790), typhaRule}),
+		table.Entry("unencapsulated pools", network(&bgpEnabled, operatorv1.EncapsulationNone), nil, []Rule{bgpRule, typhaRule}),
+		table.Entry("IPIP and VXLAN pools", network(&bgpEnabled, operatorv1.EncapsulationVXLAN, operatorv1.EncapsulationIPIPCrossSubnet), nil,
+			[]Rule{bgpRule, ipipRule, udp(4789), typhaRule}),
+		table.Entry("WireGuard", network(&bgpDisabled),
+			&crdv1.FelixConfiguration{Spec: crdv1.FelixConfigurationSpec{WireguardEnabled: &wireguardEnabled}},
+			[]Rule{typhaRule, udp(51820)}),
+		table.Entry("WireGuard on the configured port", network(&bgpDisabled),
+			&crdv1.FelixConfiguration{Spec: crdv1.FelixConfigurationSpec{WireguardEnabled: &wireguardEnabled, WireguardListeningPort: &wireguardPort}},
+			[]Rule{typhaRule, udp(51830)}),
+	)
+})
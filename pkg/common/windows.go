@@ -34,6 +34,7 @@ const (
 	CalicoWindowsUpgradeVolumePath            = `c:\CalicoUpgrade`
 	CalicoWindowsUpgradeLabel                 = "projectcalico.org/windows-upgrade"
 	CalicoWindowsUpgradeLabelInProgress       = "in-progress"
+	CalicoWindowsUpgradeStartedAnnotation     = "projectcalico.org/windows-upgrade-started"
 	CalicoVersionAnnotation                   = "projectcalico.org/version"
 	CalicoVariantAnnotation                   = "projectcalico.org/variant"
 	CalicoWindowsUpgradeTaintKey              = "projectcalico.org/windows-upgrade"
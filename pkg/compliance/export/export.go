@@ -0,0 +1,195 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package export exports the finished compliance reports from the compliance server to object storage. It runs in
+// the compliance report exporter CronJob that the compliance controller renders.
+package export
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// ChecksumFile is the file with the SHA256 hashes of the exported files of a report, in the format of sha256sum.
+	// It is written last, so a report is only considered exported once it exists.
+	ChecksumFile = "SHA256SUMS"
+
+	// maxItems is the number of reports requested per page.
+	maxItems = 100
+)
+
+var log = logf.Log.WithName("compliance_export")
+
+// Report is a finished report as listed by the compliance server.
+type Report struct {
+	ID              string           `json:"id"`
+	Name            string           `json:"name"`
+	DownloadFormats []DownloadFormat `json:"downloadFormats"`
+}
+
+// DownloadFormat is a format, rendered by a download template, that a report can be downloaded as.
+type DownloadFormat struct {
+	Name string `json:"name"`
+}
+
+type reportList struct {
+	Reports  []Report `json:"reports"`
+	LastPage int      `json:"lastPage"`
+}
+
+// Exporter exports the reports of the compliance server at ServerURL to Store.
+type Exporter struct {
+	Client    *http.Client
+	ServerURL string
+	// Token is the bearer token the compliance server authorizes the requests with.
+	Token string
+	Store Store
+}
+
+// Run exports the reports that have not been exported yet, and returns the number of reports it exported. A report
+// that fails to export does not stop the others from being exported, and is retried on the next run.
+func (e *Exporter) Run(ctx context.Context) (int, error) {
+	reports, err := e.listReports(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var exported int
+	var failed []string
+	for _, r := range reports {
+		ok, err := e.Store.Exists(ctx, path.Join(reportDir(r), ChecksumFile))
+		if err != nil {
+			return exported, fmt.Errorf("failed to check whether report %s was exported: %w", r.ID, err)
+		}
+		if ok {
+			continue
+		}
+		if err := e.export(ctx, r); err != nil {
+			log.Error(err, "Failed to export report", "name", r.Name, "id", r.ID)
+			failed = append(failed, r.ID)
+			continue
+		}
+		log.Info("Exported report", "name", r.Name, "id", r.ID)
+		exported++
+	}
+	if len(failed) > 0 {
+		return exported, fmt.Errorf("failed to export reports: %s", strings.Join(failed, ", "))
+	}
+	return exported, nil
+}
+
+// reportDir is the directory the files of a report are exported to.
+func reportDir(r Report) string {
+	return path.Join(r.Name, r.ID)
+}
+
+// safeName returns whether name can be used as a single path element.
+func safeName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+func (e *Exporter) export(ctx context.Context, r Report) error {
+	if !safeName(r.Name) || !safeName(r.ID) {
+		return fmt.Errorf("invalid report name %q or id %q", r.Name, r.ID)
+	}
+
+	sums := map[string]string{}
+	for _, f := range r.DownloadFormats {
+		if !safeName(f.Name) || f.Name == ChecksumFile {
+			return fmt.Errorf("invalid download format %q", f.Name)
+		}
+		data, err := e.get(ctx, fmt.Sprintf("/compliance/reports/%s/download", url.PathEscape(r.ID)), url.Values{"format": {f.Name}})
+		if err != nil {
+			return err
+		}
+		if err := e.Store.Put(ctx, path.Join(reportDir(r), f.Name), data); err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		sums[f.Name] = hex.EncodeToString(sum[:])
+	}
+	return e.Store.Put(ctx, path.Join(reportDir(r), ChecksumFile), checksums(sums))
+}
+
+// checksums returns the hashes in the format of sha256sum, so they can be verified with sha256sum --check.
+func checksums(sums map[string]string) []byte {
+	var names []string
+	for n := range sums {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, n := range names {
+		fmt.Fprintf(&b, "%s  %s\n", sums[n], n)
+	}
+	return []byte(b.String())
+}
+
+func (e *Exporter) listReports(ctx context.Context) ([]Report, error) {
+	var reports []Report
+	for page := 0; ; page++ {
+		data, err := e.get(ctx, "/compliance/reports", url.Values{
+			"page":     {fmt.Sprint(page)},
+			"maxItems": {fmt.Sprint(maxItems)},
+		})
+		if err != nil {
+			return nil, err
+		}
+		var l reportList
+		if err := json.Unmarshal(data, &l); err != nil {
+			return nil, fmt.Errorf("failed to parse the list of reports: %w", err)
+		}
+		reports = append(reports, l.Reports...)
+		if page >= l.LastPage {
+			return reports, nil
+		}
+	}
+}
+
+func (e *Exporter) get(ctx context.Context, p string, query url.Values) ([]byte, error) {
+	u := strings.TrimSuffix(e.ServerURL, "/") + p + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if e.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.Token)
+	}
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", p, resp.Status)
+	}
+	return data, nil
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeComplianceServer serves the reports like the compliance server does, with one report per page.
+type fakeComplianceServer struct {
+	reports   []Report
+	contents  map[string]string
+	downloads int
+}
+
+func (f *fakeComplianceServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.URL.Path == "/compliance/reports" {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		l := reportList{LastPage: len(f.reports) - 1}
+		if page < len(f.reports) {
+			l.Reports = []Report{f.reports[page]}
+		}
+		_ = json.NewEncoder(w).Encode(l)
+		return
+	}
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/compliance/reports/"), "/download")
+	content, ok := f.contents[id+"/"+r.URL.Query().Get("format")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	f.downloads++
+	_, _ = io.WriteString(w, content)
+}
+
+// fakeS3 keeps the objects in memory.
+type fakeS3 struct {
+	s3iface.S3API
+	objects map[string]string
+}
+
+func (f *fakeS3) HeadObjectWithContext(_ aws.Context, in *s3.HeadObjectInput, _ ...request.Option) (*s3.HeadObjectOutput, error) {
+	if _, ok := f.objects[*in.Bucket+"/"+*in.Key]; !ok {
+		return nil, awserr.New("NotFound", "Not Found", nil)
+	}
+	return &s3.HeadObjectOutput{}, nil
+}
+
+func (f *fakeS3) PutObjectWithContext(_ aws.Context, in *s3.PutObjectInput, _ ...request.Option) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.objects[*in.Bucket+"/"+*in.Key] = string(data)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func sha(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+var _ = Describe("Compliance report export", func() {
+	var server *fakeComplianceServer
+	var srv *httptest.Server
+
+	BeforeEach(func() {
+		server = &fakeComplianceServer{
+			reports: []Report{
+				{ID: "r1", Name: "daily-inventory", DownloadFormats: []DownloadFormat{{Name: "summary.csv"}, {Name: "endpoints.json"}}},
+				{ID: "r2", Name: "weekly-audit", DownloadFormats: []DownloadFormat{{Name: "events.csv"}}},
+			},
+			contents: map[string]string{
+				"r1/summary.csv":    "a,b\n1,2\n",
+				"r1/endpoints.json": `{"endpoints":[]}`,
+				"r2/events.csv":     "event\n",
+			},
+		}
+		srv = httptest.NewServer(server)
+	})
+
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	exporter := func(store Store) *Exporter {
+		return &Exporter{Client: srv.Client(), ServerURL: srv.URL, Token: "token", Store: store}
+	}
+
+	It("should export the reports to a directory with their hashes, once", func() {
+		dir, err := os.MkdirTemp("", "compliance-export")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		n, err := exporter(&FileStore{Dir: dir}).Run(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(2))
+
+		data, err := os.ReadFile(filepath.Join(dir, "daily-inventory", "r1", "summary.csv"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("a,b\n1,2\n"))
+		data, err = os.ReadFile(filepath.Join(dir, "daily-inventory", "r1", ChecksumFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(sha(`{"endpoints":[]}`) + "  endpoints.json\n" + sha("a,b\n1,2\n") + "  summary.csv\n"))
+		data, err = os.ReadFile(filepath.Join(dir, "weekly-audit", "r2", ChecksumFile))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(sha("event\n") + "  events.csv\n"))
+		Expect(server.downloads).To(Equal(3))
+
+		// The reports have been exported, so they are not downloaded again.
+		n, err = exporter(&FileStore{Dir: dir}).Run(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(0))
+		Expect(server.downloads).To(Equal(3))
+	})
+
+	It("should export the reports to a bucket under the prefix", func() {
+		s3 := &fakeS3{objects: map[string]string{}}
+		store := &S3Store{S3: s3, Bucket: "audit", Prefix: "cluster-a"}
+
+		n, err := exporter(store).Run(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(2))
+		Expect(s3.objects).To(Equal(map[string]string{
+			"audit/cluster-a/daily-inventory/r1/summary.csv":    "a,b\n1,2\n",
+			"audit/cluster-a/daily-inventory/r1/endpoints.json": `{"endpoints":[]}`,
+			"audit/cluster-a/daily-inventory/r1/SHA256SUMS":     sha(`{"endpoints":[]}`) + "  endpoints.json\n" + sha("a,b\n1,2\n") + "  summary.csv\n",
+			"audit/cluster-a/weekly-audit/r2/events.csv":        "event\n",
+			"audit/cluster-a/weekly-audit/r2/SHA256SUMS":        sha("event\n") + "  events.csv\n",
+		}))
+
+		n, err = exporter(store).Run(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(0))
+	})
+
+	It("should not record the hashes of a report that failed to export, and export the others", func() {
+		delete(server.contents, "r1/endpoints.json")
+		s3 := &fakeS3{objects: map[string]string{}}
+
+		n, err := exporter(&S3Store{S3: s3, Bucket: "audit"}).Run(context.Background())
+		Expect(err).To(MatchError(ContainSubstring("r1")))
+		Expect(n).To(Equal(1))
+		Expect(s3.objects).NotTo(HaveKey("audit/daily-inventory/r1/SHA256SUMS"))
+		Expect(s3.objects).To(HaveKey("audit/weekly-audit/r2/SHA256SUMS"))
+	})
+
+	It("should not write outside of the store", func() {
+		server.reports = []Report{{ID: "..", Name: "daily-inventory", DownloadFormats: []DownloadFormat{{Name: "summary.csv"}}}}
+		s3 := &fakeS3{objects: map[string]string{}}
+
+		_, err := exporter(&S3Store{S3: s3, Bucket: "audit"}).Run(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(s3.objects).To(BeEmpty())
+	})
+})
@@ -0,0 +1,123 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// Store is where the files of exported reports are written to. Keys are slash separated paths.
+type Store interface {
+	// Exists returns whether the file with the key exists.
+	Exists(ctx context.Context, key string) (bool, error)
+	// Put writes the file with the key.
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// FileStore stores the files under a directory, such as the mount of a PersistentVolumeClaim.
+type FileStore struct {
+	Dir string
+}
+
+func (s *FileStore) Exists(_ context.Context, key string) (bool, error) {
+	_, err := os.Stat(filepath.Join(s.Dir, filepath.FromSlash(key)))
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (s *FileStore) Put(_ context.Context, key string, data []byte) error {
+	p := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so that a partially written file is never mistaken for an exported one.
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+// S3Store stores the files as objects in an S3-compatible bucket, with the keys under Prefix.
+type S3Store struct {
+	S3     s3iface.S3API
+	Bucket string
+	Prefix string
+}
+
+// NewS3Store returns a store for the bucket of the S3-compatible service at endpoint, or of AWS S3 if endpoint is
+// empty. The credentials are taken from the environment, i.e. AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+func NewS3Store(endpoint, region, bucket, prefix string, forcePathStyle bool) (*S3Store, error) {
+	cfg := &aws.Config{
+		Region:           aws.String(region),
+		S3ForcePathStyle: aws.Bool(forcePathStyle),
+	}
+	if endpoint != "" {
+		cfg.Endpoint = aws.String(endpoint)
+	}
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &S3Store{S3: s3.New(sess), Bucket: bucket, Prefix: prefix}, nil
+}
+
+func (s *S3Store) key(key string) string {
+	if s.Prefix == "" {
+		return key
+	}
+	return path.Join(s.Prefix, key)
+}
+
+func (s *S3Store) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := s.S3.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.key(key)),
+	})
+	if err == nil {
+		return true, nil
+	}
+	var aerr awserr.Error
+	// HEAD responses have no body, so a missing object is reported with the status code rather than NoSuchKey.
+	if errors.As(err, &aerr) && (aerr.Code() == "NotFound" || aerr.Code() == s3.ErrCodeNoSuchKey) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	_, err := s.S3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.key(key)),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
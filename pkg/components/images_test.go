@@ -125,4 +125,23 @@ var _ = Describe("test GetReference", func() {
 			Entry("a CSR init image correctly", ComponentCSRInitContainer, "userpath/key-cert-provisioner", "@sha256:tigerakeycertprovisionerhash"),
 		)
 	})
+
+	Context("ImageSet with registry overrides", func() {
+		is := &op.ImageSet{
+			Spec: op.ImageSetSpec{
+				Images: []op.Image{
+					{Image: "calico/node", Digest: "sha256:caliconodehash", Registry: "calico.mirror.io/"},
+					{Image: "tigera/cnx-node", Digest: "sha256:tigeracnxnodehash"},
+				},
+			},
+		}
+
+		It("should use the registry of the image", func() {
+			Expect(GetReference(ComponentCalicoNode, "quay.io/extra/", "", "", is)).To(Equal("calico.mirror.io/calico/node@sha256:caliconodehash"))
+		})
+
+		It("should use the Installation registry for images without a registry", func() {
+			Expect(GetReference(ComponentTigeraNode, "quay.io/extra/", "", "", is)).To(Equal("quay.io/extra/tigera/cnx-node@sha256:tigeracnxnodehash"))
+		})
+	})
 })
@@ -66,6 +66,9 @@ func GetReference(c component, registry, imagePath, imagePrefix string, is *oper
 
 	for _, img := range is.Spec.Images {
 		if img.Image == c.Image {
+			if img.Registry != "" {
+				registry = img.Registry
+			}
 			return fmt.Sprintf("%s%s@%s", registry, image, img.Digest), nil
 		}
 	}
@@ -90,3 +93,16 @@ func insertPrefix(image, prefix string) string {
 	subs = append(subs[:len(subs)-1], fmt.Sprintf("%s%s", prefix, subs[len(subs)-1]))
 	return strings.Join(subs, "/")
 }
+
+// VariantComponents returns the components of all the images the operator could deploy for the variant.
+func VariantComponents(v operator.ProductVariant) []component {
+	var cmpnts []component
+	if v == operator.TigeraSecureEnterprise {
+		// Enterprise installations also use the Calico flex volume and operator init images.
+		cmpnts = append(cmpnts, EnterpriseComponents...)
+		cmpnts = append(cmpnts, ComponentFlexVolume, ComponentOperatorInit)
+	} else {
+		cmpnts = append(cmpnts, CalicoComponents...)
+	}
+	return append(cmpnts, CommonComponents...)
+}
@@ -67,6 +67,7 @@ var _ = Describe("amazoncloudintegration controller tests", func() {
 		mockStatus.On("AddDeployments", mock.Anything).Return()
 		mockStatus.On("AddStatefulSets", mock.Anything).Return()
 		mockStatus.On("AddCronJobs", mock.Anything)
+		mockStatus.On("SetPodSecurityRequirements", mock.Anything)
 		mockStatus.On("IsAvailable").Return(true)
 		mockStatus.On("OnCRFound").Return()
 		mockStatus.On("ClearDegraded")
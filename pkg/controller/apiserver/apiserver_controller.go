@@ -31,9 +31,11 @@ import (
 	"github.com/tigera/operator/pkg/render"
 	rcertificatemanagement "github.com/tigera/operator/pkg/render/certificatemanagement"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -61,6 +63,7 @@ func newReconciler(mgr manager.Manager, opts options.AddOptions) *ReconcileAPISe
 		enterpriseCRDsExist: opts.EnterpriseCRDExists,
 		status:              status.New(mgr.GetClient(), "apiserver", opts.KubernetesVersion),
 		clusterDomain:       opts.ClusterDomain,
+		tierWatchReady:      &utils.ReadyFlag{},
 		usePSP:              opts.UsePSP,
 	}
 	r.status.Run(opts.ShutdownContext)
@@ -123,6 +126,12 @@ func add(mgr manager.Manager, r *ReconcileAPIServer) error {
 		if err != nil {
 			return fmt.Errorf("apiserver-controller failed to watch resource: %w", err)
 		}
+
+		k8sClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+		if err != nil {
+			return fmt.Errorf("apiserver-controller failed to establish a connection to k8s: %w", err)
+		}
+		go utils.WaitToAddTierWatch(c, k8sClient, log, r.tierWatchReady)
 	}
 
 	for _, secretName := range []string{
@@ -156,6 +165,7 @@ type ReconcileAPIServer struct {
 	enterpriseCRDsExist bool
 	status              status.StatusManager
 	clusterDomain       string
+	tierWatchReady      *utils.ReadyFlag
 	usePSP              bool
 }
 
@@ -182,6 +192,12 @@ func (r *ReconcileAPIServer) Reconcile(ctx context.Context, request reconcile.Re
 	r.status.OnCRFound()
 	reqLogger.V(2).Info("Loaded config", "config", instance)
 
+	if err = render.ValidateAPIServer(&instance.Spec); err != nil {
+		reqLogger.Error(err, "Invalid APIServer provided")
+		r.status.SetDegraded("Invalid APIServer provided", err.Error())
+		return reconcile.Result{}, err
+	}
+
 	// Query for the installation object.
 	variant, network, err := utils.GetInstallation(context.Background(), r.client)
 	if err != nil {
@@ -198,6 +214,14 @@ func (r *ReconcileAPIServer) Reconcile(ctx context.Context, request reconcile.Re
 	}
 	ns := rmeta.APIServerNamespace(variant)
 
+	if h := instance.Spec.HostNetwork; h != nil && !*h && render.APIServerRequiresHostNetwork(network) {
+		err = fmt.Errorf("hostNetwork cannot be false on %s with the %s CNI, the API server must run in the host network for the Kubernetes API server to reach it",
+			network.KubernetesProvider, network.CNI.Type)
+		reqLogger.Error(err, "Invalid APIServer provided")
+		r.status.SetDegraded("Invalid APIServer provided", err.Error())
+		return reconcile.Result{}, err
+	}
+
 	certificateManager, err := certificatemanager.Create(r.client, network, r.clusterDomain)
 	if err != nil {
 		log.Error(err, "unable to create the Tigera CA")
@@ -287,7 +311,7 @@ func (r *ReconcileAPIServer) Reconcile(ctx context.Context, request reconcile.Re
 		return reconcile.Result{}, err
 	}
 	// Create a component handler to manage the rendered component.
-	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance)
+	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance, network)
 
 	// Render the desired objects from the CRD and create or update them.
 	reqLogger.V(3).Info("rendering components")
@@ -303,6 +327,7 @@ func (r *ReconcileAPIServer) Reconcile(ctx context.Context, request reconcile.Re
 		PullSecrets:                 pullSecrets,
 		Openshift:                   r.provider == operatorv1.ProviderOpenShift,
 		TunnelCASecret:              tunnelCASecret,
+		APIServer:                   &instance.Spec,
 		UsePSP:                      r.usePSP,
 	}
 
@@ -352,6 +377,13 @@ func (r *ReconcileAPIServer) Reconcile(ctx context.Context, request reconcile.Re
 			return reconcile.Result{}, err
 		}
 
+		networkPolicyState, err := utils.GetNetworkPolicyState(ctx, r.client, r.tierWatchReady, network, instance.Spec.ComponentNetworkPolicy)
+		if err != nil {
+			log.Error(err, "Error querying allow-tigera tier")
+			r.status.SetDegraded("Error querying allow-tigera tier", err.Error())
+			return reconcile.Result{}, err
+		}
+
 		packetCaptureApiCfg := &render.PacketCaptureApiConfiguration{
 			PullSecrets:        pullSecrets,
 			Openshift:          r.provider == operatorv1.ProviderOpenShift,
@@ -359,6 +391,8 @@ func (r *ReconcileAPIServer) Reconcile(ctx context.Context, request reconcile.Re
 			KeyValidatorConfig: keyValidatorConfig,
 			ServerCertSecret:   packetCaptureCertSecret,
 			ClusterDomain:      r.clusterDomain,
+			Autoscaling:        instance.Spec.PacketCaptureAutoscaling,
+			NetworkPolicyState: networkPolicyState,
 		}
 		pc := render.PacketCaptureAPI(packetCaptureApiCfg)
 		components = append(components, pc,
@@ -394,6 +428,15 @@ func (r *ReconcileAPIServer) Reconcile(ctx context.Context, request reconcile.Re
 		return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 
+	// The tier for the policies of the operator managed components can only be created once the API server is
+	// serving the projectcalico.org API.
+	if variant == operatorv1.TigeraSecureEnterprise {
+		if err := handler.CreateOrUpdateOrDelete(ctx, render.NewPassthrough(networkpolicy.AllowTigeraTier()), nil); err != nil {
+			r.status.SetDegraded("Error creating allow-tigera tier", err.Error())
+			return reconcile.Result{}, err
+		}
+	}
+
 	// Everything is available - update the CRD status.
 	instance.Status.State = operatorv1.TigeraStatusReady
 	if err = r.client.Status().Update(ctx, instance); err != nil {
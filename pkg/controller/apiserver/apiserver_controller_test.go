@@ -106,6 +106,7 @@ var _ = Describe("apiserver controller tests", func() {
 		mockStatus.On("AddDeployments", mock.Anything).Return()
 		mockStatus.On("AddStatefulSets", mock.Anything).Return()
 		mockStatus.On("AddCronJobs", mock.Anything)
+		mockStatus.On("SetPodSecurityRequirements", mock.Anything)
 		mockStatus.On("IsAvailable").Return(true)
 		mockStatus.On("OnCRFound").Return()
 		mockStatus.On("ClearDegraded")
@@ -323,5 +324,72 @@ var _ = Describe("apiserver controller tests", func() {
 			Expect(cli.Get(ctx, client.ObjectKey{Namespace: common.OperatorNamespace(), Name: render.PacketCaptureCertSecret}, secret)).ShouldNot(HaveOccurred())
 			Expect(secret.GetOwnerReferences()).To(HaveLen(1))
 		})
+
+		It("should apply the configuration of the APIServer CR", func() {
+			Expect(cli.Create(ctx, installation)).To(BeNil())
+
+			apiServer := &operatorv1.APIServer{}
+			Expect(cli.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, apiServer)).To(BeNil())
+			var replicas int32 = 3
+			apiServer.Spec.Replicas = &replicas
+			Expect(cli.Update(ctx, apiServer)).To(BeNil())
+
+			r := ReconcileAPIServer{
+				client:   cli,
+				scheme:   scheme,
+				provider: operatorv1.ProviderNone,
+				status:   mockStatus,
+			}
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			d := appsv1.Deployment{}
+			Expect(cli.Get(ctx, client.ObjectKey{Name: "tigera-apiserver", Namespace: "tigera-system"}, &d)).To(BeNil())
+			Expect(*d.Spec.Replicas).To(Equal(replicas))
+		})
+
+		It("should degrade when the APIServer CR is invalid", func() {
+			Expect(cli.Create(ctx, installation)).To(BeNil())
+
+			apiServer := &operatorv1.APIServer{}
+			Expect(cli.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, apiServer)).To(BeNil())
+			apiServer.Spec.PodDisruptionBudget = &operatorv1.PodDisruptionBudgetSpec{}
+			Expect(cli.Update(ctx, apiServer)).To(BeNil())
+
+			mockStatus.On("SetDegraded", "Invalid APIServer provided", mock.Anything).Return()
+			r := ReconcileAPIServer{
+				client:   cli,
+				scheme:   scheme,
+				provider: operatorv1.ProviderNone,
+				status:   mockStatus,
+			}
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).Should(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", "Invalid APIServer provided", mock.Anything)
+		})
+
+		It("should degrade when the APIServer CR turns off the host network that the platform requires", func() {
+			installation.Spec.KubernetesProvider = operatorv1.ProviderEKS
+			installation.Spec.CNI = &operatorv1.CNISpec{Type: operatorv1.PluginCalico}
+			Expect(cli.Create(ctx, installation)).To(BeNil())
+
+			apiServer := &operatorv1.APIServer{}
+			Expect(cli.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, apiServer)).To(BeNil())
+			hostNetwork := false
+			apiServer.Spec.HostNetwork = &hostNetwork
+			Expect(cli.Update(ctx, apiServer)).To(BeNil())
+
+			mockStatus.On("SetDegraded", "Invalid APIServer provided", mock.Anything).Return()
+			r := ReconcileAPIServer{
+				client:   cli,
+				scheme:   scheme,
+				provider: operatorv1.ProviderEKS,
+				status:   mockStatus,
+			}
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("hostNetwork cannot be false on EKS with the Calico CNI")))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", "Invalid APIServer provided", mock.Anything)
+			Expect(cli.Get(ctx, client.ObjectKey{Name: "tigera-apiserver", Namespace: "tigera-system"}, &appsv1.Deployment{})).NotTo(Succeed())
+		})
 	})
 })
@@ -68,6 +68,7 @@ var _ = Describe("Application layer controller tests", func() {
 			mockStatus.On("IsAvailable").Return(true)
 			mockStatus.On("AddStatefulSets", mock.Anything).Return()
 			mockStatus.On("AddCronJobs", mock.Anything)
+			mockStatus.On("SetPodSecurityRequirements", mock.Anything)
 			mockStatus.On("OnCRFound").Return()
 			mockStatus.On("OnCRNotFound").Return()
 			mockStatus.On("ClearDegraded")
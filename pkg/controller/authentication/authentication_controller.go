@@ -31,12 +31,14 @@ import (
 	"github.com/tigera/operator/pkg/dns"
 	"github.com/tigera/operator/pkg/render"
 	rcertificatemanagement "github.com/tigera/operator/pkg/render/certificatemanagement"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -67,11 +69,12 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager, opts options.AddOptions) *ReconcileAuthentication {
 	r := &ReconcileAuthentication{
-		client:        mgr.GetClient(),
-		scheme:        mgr.GetScheme(),
-		provider:      opts.DetectedProvider,
-		status:        status.New(mgr.GetClient(), "authentication", opts.KubernetesVersion),
-		clusterDomain: opts.ClusterDomain,
+		client:         mgr.GetClient(),
+		scheme:         mgr.GetScheme(),
+		provider:       opts.DetectedProvider,
+		status:         status.New(mgr.GetClient(), "authentication", opts.KubernetesVersion),
+		clusterDomain:  opts.ClusterDomain,
+		tierWatchReady: &utils.ReadyFlag{},
 	}
 	r.status.Run(opts.ShutdownContext)
 	return r
@@ -84,6 +87,13 @@ func add(mgr manager.Manager, r *ReconcileAuthentication) error {
 		return fmt.Errorf("failed to create %s: %w", controllerName, err)
 	}
 
+	k8sClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return fmt.Errorf("%s failed to establish a connection to k8s: %w", controllerName, err)
+	}
+
+	go utils.WaitToAddTierWatch(c, k8sClient, log, r.tierWatchReady)
+
 	err = c.Watch(&source.Kind{Type: &oprv1.Authentication{}}, &handler.EnqueueRequestForObject{})
 	if err != nil {
 		return fmt.Errorf("%s failed to watch resource: %w", controllerName, err)
@@ -98,6 +108,12 @@ func add(mgr manager.Manager, r *ReconcileAuthentication) error {
 		return fmt.Errorf("%s failed to watch resource: %w", controllerName, err)
 	}
 
+	// The redirect URIs of dex include the URL that the manager is exposed at.
+	err = c.Watch(&source.Kind{Type: &oprv1.Manager{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return fmt.Errorf("%s failed to watch resource: %w", controllerName, err)
+	}
+
 	for _, namespace := range []string{common.OperatorNamespace(), render.DexNamespace} {
 		for _, secretName := range []string{
 			render.DexTLSSecretName, render.OIDCSecretName, render.OpenshiftSecretName,
@@ -121,11 +137,12 @@ var _ reconcile.Reconciler = &ReconcileAuthentication{}
 
 // ReconcileAuthentication reconciles an Authentication object
 type ReconcileAuthentication struct {
-	client        client.Client
-	scheme        *runtime.Scheme
-	provider      oprv1.Provider
-	status        status.StatusManager
-	clusterDomain string
+	client         client.Client
+	scheme         *runtime.Scheme
+	provider       oprv1.Provider
+	status         status.StatusManager
+	clusterDomain  string
+	tierWatchReady *utils.ReadyFlag
 }
 
 // Reconciles the cluster state with the Authentication object that is found in the cluster.
@@ -255,20 +272,37 @@ func (r *ReconcileAuthentication) Reconcile(ctx context.Context, request reconci
 		disableDex = true
 	}
 
+	managerURL, err := r.managerURL(ctx)
+	if err != nil {
+		log.Error(err, "Error querying Manager")
+		r.status.SetDegraded("Error querying Manager", err.Error())
+		return reconcile.Result{}, err
+	}
+
 	// DexConfig adds convenience methods around dex related objects in k8s and can be used to configure Dex.
 	dexCfg := render.NewDexConfig(install.CertificateManagement, authentication, dexSecret, idpSecret, r.clusterDomain)
 
+	networkPolicyState, err := utils.GetNetworkPolicyState(ctx, r.client, r.tierWatchReady, install, authentication.Spec.ComponentNetworkPolicy)
+	if err != nil {
+		log.Error(err, "Error querying allow-tigera tier")
+		r.status.SetDegraded("Error querying allow-tigera tier", err.Error())
+		return reconcile.Result{}, err
+	}
+
 	// Create a component handler to manage the rendered component.
-	hlr := utils.NewComponentHandler(log, r.client, r.scheme, authentication)
+	hlr := utils.NewComponentHandler(log, r.client, r.scheme, authentication, install)
 
 	dexComponentCfg := &render.DexComponentConfiguration{
-		PullSecrets:   pullSecrets,
-		Openshift:     r.provider == oprv1.ProviderOpenShift,
-		Installation:  install,
-		DexConfig:     dexCfg,
-		ClusterDomain: r.clusterDomain,
-		DeleteDex:     disableDex,
-		TLSKeyPair:    tlsKeyPair,
+		PullSecrets:        pullSecrets,
+		Openshift:          r.provider == oprv1.ProviderOpenShift,
+		Installation:       install,
+		DexConfig:          dexCfg,
+		ClusterDomain:      r.clusterDomain,
+		DeleteDex:          disableDex,
+		TLSKeyPair:         tlsKeyPair,
+		ManagerURL:         managerURL,
+		NetworkPolicyState: networkPolicyState,
+		Autoscaling:        authentication.Spec.DexAutoscaling,
 	}
 
 	// Render the desired objects from the CRD and create or update them.
@@ -402,5 +436,29 @@ func validateAuthentication(authentication *oprv1.Authentication) error {
 		}
 	}
 
+	if err := autoscaling.Validate(authentication.Spec.DexAutoscaling); err != nil {
+		return fmt.Errorf("invalid dex autoscaling: %w", err)
+	}
+
 	return nil
 }
+
+// managerURL returns the URL that the manager is exposed at through the Manager CR, or an empty string if it is not
+// exposed or its address is not known yet.
+func (r *ReconcileAuthentication) managerURL(ctx context.Context) (string, error) {
+	managerCR := &oprv1.Manager{}
+	if err := r.client.Get(ctx, utils.DefaultTSEEInstanceKey, managerCR); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if managerCR.Spec.Exposure == nil {
+		return "", nil
+	}
+	if url := render.ManagerExposureURL(managerCR.Spec.Exposure); url != "" {
+		return url, nil
+	}
+	// E.g. the address of a load balancer, which the manager controller reports once it is known.
+	return managerCR.Status.URL, nil
+}
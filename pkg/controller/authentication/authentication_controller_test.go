@@ -71,6 +71,7 @@ var _ = Describe("authentication controller tests", func() {
 		mockStatus.On("AddDeployments", mock.Anything).Return()
 		mockStatus.On("AddStatefulSets", mock.Anything).Return()
 		mockStatus.On("AddCronJobs", mock.Anything)
+		mockStatus.On("SetPodSecurityRequirements", mock.Anything)
 		mockStatus.On("IsAvailable").Return(true)
 		mockStatus.On("OnCRFound").Return()
 		mockStatus.On("ClearDegraded")
@@ -127,7 +128,7 @@ var _ = Describe("authentication controller tests", func() {
 			Expect(cli.Create(ctx, auth)).ToNot(HaveOccurred())
 
 			// Reconcile
-			r := &ReconcileAuthentication{cli, scheme, operatorv1.ProviderNone, mockStatus, ""}
+			r := &ReconcileAuthentication{cli, scheme, operatorv1.ProviderNone, mockStatus, "", &utils.ReadyFlag{}}
 			_, err := r.Reconcile(ctx, reconcile.Request{})
 			Expect(err).ShouldNot(HaveOccurred())
 			authentication, err := utils.GetAuthentication(ctx, cli)
@@ -138,6 +139,41 @@ var _ = Describe("authentication controller tests", func() {
 			Expect(authentication.Spec.UsernamePrefix).To(Equal("u"))
 			Expect(authentication.Spec.GroupsPrefix).To(Equal("g"))
 		})
+
+		It("should redirect to the URL that the manager is exposed at", func() {
+			Expect(cli.Create(ctx, &operatorv1.Installation{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "default",
+				},
+				Status: operatorv1.InstallationStatus{
+					Variant:  operatorv1.TigeraSecureEnterprise,
+					Computed: &operatorv1.InstallationSpec{},
+				},
+				Spec: operatorv1.InstallationSpec{
+					ControlPlaneReplicas: &replicas,
+					Variant:              operatorv1.TigeraSecureEnterprise,
+				},
+			})).ToNot(HaveOccurred())
+			Expect(cli.Create(ctx, idpSecret)).ToNot(HaveOccurred())
+			Expect(cli.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tigera-dex"}})).ToNot(HaveOccurred())
+			auth.Spec.OIDC = &operatorv1.AuthenticationOIDC{IssuerURL: "https://example.com", UsernameClaim: "email"}
+			Expect(cli.Create(ctx, auth)).ToNot(HaveOccurred())
+			Expect(cli.Create(ctx, &operatorv1.Manager{
+				ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+				Spec: operatorv1.ManagerSpec{
+					Exposure: &operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureIngress, Hostname: "manager.example.org"},
+				},
+			})).ToNot(HaveOccurred())
+
+			r := &ReconcileAuthentication{cli, scheme, operatorv1.ProviderNone, mockStatus, "", &utils.ReadyFlag{}}
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			cm := &corev1.ConfigMap{}
+			Expect(cli.Get(ctx, client.ObjectKey{Name: render.DexObjectName, Namespace: render.DexNamespace}, cm)).ToNot(HaveOccurred())
+			Expect(cm.Data["config.yaml"]).To(ContainSubstring("https://example.com/login/oidc/callback"))
+			Expect(cm.Data["config.yaml"]).To(ContainSubstring("https://manager.example.org/login/oidc/callback"))
+		})
 	})
 
 	Context("image reconciliation", func() {
@@ -273,7 +309,7 @@ var _ = Describe("authentication controller tests", func() {
 		Expect(cli.Create(ctx, idpSecret)).ToNot(HaveOccurred())
 		Expect(cli.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tigera-dex"}})).ToNot(HaveOccurred())
 		Expect(cli.Create(ctx, auth)).ToNot(HaveOccurred())
-		r := &ReconcileAuthentication{cli, scheme, operatorv1.ProviderNone, mockStatus, ""}
+		r := &ReconcileAuthentication{cli, scheme, operatorv1.ProviderNone, mockStatus, "", &utils.ReadyFlag{}}
 		_, err := r.Reconcile(ctx, reconcile.Request{})
 		if expectReconcilePass {
 			Expect(err).ToNot(HaveOccurred())
@@ -372,6 +408,8 @@ var _ = Describe("authentication controller tests", func() {
 		Entry("Expect prompt type to be used without other values", &operatorv1.Authentication{Spec: operatorv1.AuthenticationSpec{OIDC: copyAndAddPromptTypes(oidc, []operatorv1.PromptType{operatorv1.PromptTypeNone})}}, true),
 		Entry("Expect prompt type to fail when none is combined", &operatorv1.Authentication{Spec: operatorv1.AuthenticationSpec{OIDC: copyAndAddPromptTypes(oidc, []operatorv1.PromptType{operatorv1.PromptTypeNone, operatorv1.PromptTypeLogin})}}, false),
 		Entry("Expect prompt type to be able to be combined", &operatorv1.Authentication{Spec: operatorv1.AuthenticationSpec{OIDC: copyAndAddPromptTypes(oidc, []operatorv1.PromptType{operatorv1.PromptTypeSelectAccount, operatorv1.PromptTypeLogin})}}, true),
+		Entry("Expect dex autoscaling to pass validation", &operatorv1.Authentication{Spec: operatorv1.AuthenticationSpec{OIDC: oidc, DexAutoscaling: &operatorv1.Autoscaling{MaxReplicas: 3}}}, true),
+		Entry("Expect dex autoscaling without maxReplicas to fail validation", &operatorv1.Authentication{Spec: operatorv1.AuthenticationSpec{OIDC: oidc, DexAutoscaling: &operatorv1.Autoscaling{}}}, false),
 	)
 })
 
@@ -0,0 +1,184 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awssecuritygroup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	crdv1 "github.com/tigera/operator/pkg/apis/crd.projectcalico.org/v1"
+	"github.com/tigera/operator/pkg/awssgsetup"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/options"
+	"github.com/tigera/operator/pkg/controller/status"
+	"github.com/tigera/operator/pkg/controller/utils"
+)
+
+var log = logf.Log.WithName("controller_awssecuritygroup")
+
+// driftCheckInterval is how often the security groups are checked for rules that were removed.
+const driftCheckInterval = 5 * time.Minute
+
+// Add creates a new AWS security group Controller and adds it to the Manager.
+// The Manager will set fields on the Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager, opts options.AddOptions) error {
+	reconciler := newReconciler(mgr, opts)
+
+	c, err := controller.New("awssecuritygroup-controller", mgr, controller.Options{Reconciler: reconciler})
+	if err != nil {
+		return err
+	}
+
+	return add(c)
+}
+
+// newReconciler returns a new reconcile.Reconciler.
+func newReconciler(mgr manager.Manager, opts options.AddOptions) reconcile.Reconciler {
+	r := &ReconcileAWSSecurityGroups{
+		client:           mgr.GetClient(),
+		status:           status.New(mgr.GetClient(), "aws-security-groups", opts.KubernetesVersion),
+		newEC2Client:     awssgsetup.NewEC2Client,
+		instanceMetadata: awssgsetup.InstanceMetadata,
+	}
+	r.status.Run(opts.ShutdownContext)
+	return r
+}
+
+// add adds watches for resources that are available at startup.
+func add(c controller.Controller) error {
+	if err := utils.AddNetworkWatch(c); err != nil {
+		return fmt.Errorf("awssecuritygroup-controller failed to watch Installation resource: %w", err)
+	}
+
+	// The credentials secret is named in the Installation, so watch all secrets in the operator namespace.
+	if err := utils.AddSecretsWatch(c, "", common.OperatorNamespace()); err != nil {
+		return fmt.Errorf("awssecuritygroup-controller failed to watch secrets: %w", err)
+	}
+
+	// The VXLAN and WireGuard ports come from the FelixConfiguration.
+	if err := c.Watch(&source.Kind{Type: &crdv1.FelixConfiguration{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return fmt.Errorf("awssecuritygroup-controller failed to watch FelixConfiguration resource: %w", err)
+	}
+
+	return nil
+}
+
+// Blank assignment to verify that ReconcileAWSSecurityGroups implements reconcile.Reconciler.
+var _ reconcile.Reconciler = &ReconcileAWSSecurityGroups{}
+
+// ReconcileAWSSecurityGroups adds the rules Calico needs to the AWS security groups configured
+// on the Installation.
+type ReconcileAWSSecurityGroups struct {
+	client           client.Client
+	status           status.StatusManager
+	newEC2Client     func(region string, creds *credentials.Credentials) (ec2iface.EC2API, error)
+	instanceMetadata func() (region, vpcID string, err error)
+}
+
+// Reconcile makes sure the security groups configured on the Installation allow the traffic Calico
+// needs between the nodes. It requeues itself so that rules removed outside of the operator are added back.
+func (r *ReconcileAWSSecurityGroups) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.V(1).Info("Reconciling AWS security groups")
+
+	_, installation, err := utils.GetInstallation(ctx, r.client)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.status.OnCRNotFound()
+			return reconcile.Result{}, nil
+		}
+		r.status.SetDegraded("Error querying installation", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	config := installation.AWSSecurityGroups
+	if config == nil {
+		r.status.OnCRNotFound()
+		return reconcile.Result{}, nil
+	}
+	r.status.OnCRFound()
+
+	felix := &crdv1.FelixConfiguration{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: "default"}, felix); err != nil {
+		if !apierrors.IsNotFound(err) {
+			r.status.SetDegraded("Error querying FelixConfiguration", err.Error())
+			return reconcile.Result{}, err
+		}
+		felix = nil
+	}
+
+	creds, err := awssgsetup.Credentials(ctx, r.client, config.CredentialsSecretName)
+	if err != nil {
+		r.status.SetDegraded("Error reading the AWS credentials", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	region, vpcID := config.Region, config.VPCID
+	if region == "" || (vpcID == "" && len(config.SecurityGroupSelectors) > 0) {
+		metaRegion, metaVPCID, err := r.instanceMetadata()
+		if err != nil {
+			r.status.SetDegraded("Error reading the region and VPC from the instance metadata", err.Error())
+			return reconcile.Result{}, err
+		}
+		if region == "" {
+			region = metaRegion
+		}
+		if vpcID == "" {
+			vpcID = metaVPCID
+		}
+	}
+
+	cli, err := r.newEC2Client(region, creds)
+	if err != nil {
+		r.status.SetDegraded("Error creating the EC2 client", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	groups, err := awssgsetup.SecurityGroups(cli, vpcID, config.SecurityGroupIDs, config.SecurityGroupSelectors)
+	if err != nil {
+		r.status.SetDegraded("Error getting the AWS security groups", err.Error())
+		return reconcile.Result{}, err
+	}
+	if len(groups) == 0 {
+		r.status.SetDegraded("No AWS security groups found", "")
+		return reconcile.Result{RequeueAfter: driftCheckInterval}, nil
+	}
+
+	added, err := awssgsetup.AllowIngress(cli, groups, awssgsetup.Rules(installation, felix))
+	if err != nil {
+		r.status.SetDegraded("Error adding rules to the AWS security groups", err.Error())
+		return reconcile.Result{}, err
+	}
+	if added > 0 {
+		reqLogger.Info("Added rules to the AWS security groups", "added", added)
+	}
+
+	r.status.ReadyToMonitor()
+	r.status.ClearDegraded()
+	return reconcile.Result{RequeueAfter: driftCheckInterval}, nil
+}
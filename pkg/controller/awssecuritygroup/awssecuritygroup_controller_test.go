@@ -0,0 +1,276 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package awssecuritygroup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/apis"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/status"
+)
+
+// fakeEC2 implements the parts of the EC2 API used to manage security group rules.
+type fakeEC2 struct {
+	ec2iface.EC2API
+	groups map[string]*ec2.SecurityGroup
+}
+
+func (f *fakeEC2) DescribeSecurityGroups(in *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	out := &ec2.DescribeSecurityGroupsOutput{}
+	for _, id := range in.GroupIds {
+		sg, ok := f.groups[aws.StringValue(id)]
+		if !ok {
+			return nil, fmt.Errorf("InvalidGroup.NotFound: %s", aws.StringValue(id))
+		}
+		out.SecurityGroups = append(out.SecurityGroups, f.copy(sg))
+	}
+	if len(in.Filters) > 0 {
+		for _, sg := range f.groups {
+			if f.matches(sg, in.Filters) {
+				out.SecurityGroups = append(out.SecurityGroups, f.copy(sg))
+			}
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeEC2) AuthorizeSecurityGroupIngress(in *ec2.AuthorizeSecurityGroupIngressInput) (*ec2.AuthorizeSecurityGroupIngressOutput, error) {
+	sg := f.groups[aws.StringValue(in.GroupId)]
+	sg.IpPermissions = append(sg.IpPermissions, in.IpPermissions...)
+	return &ec2.AuthorizeSecurityGroupIngressOutput{}, nil
+}
+
+func (f *fakeEC2) copy(sg *ec2.SecurityGroup) *ec2.SecurityGroup {
+	c := *sg
+	c.IpPermissions = append([]*ec2.IpPermission{}, sg.IpPermissions...)
+	return &c
+}
+
+func (f *fakeEC2) matches(sg *ec2.SecurityGroup, filters []*ec2.Filter) bool {
+	for _, filter := range filters {
+		name, value := aws.StringValue(filter.Name), aws.StringValue(filter.Values[0])
+		if name == "vpc-id" {
+			if aws.StringValue(sg.VpcId) != value {
+				return false
+			}
+			continue
+		}
+		found := false
+		for _, tag := range sg.Tags {
+			if "tag:"+aws.StringValue(tag.Key) == name && aws.StringValue(tag.Value) == value {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ingress returns the rules allowed into the group, in the form "<protocol>/<port> from <group>".
+func (f *fakeEC2) ingress(id string) []string {
+	var rules []string
+	for _, p := range f.groups[id].IpPermissions {
+		rule := aws.StringValue(p.IpProtocol)
+		if p.FromPort != nil {
+			rule = fmt.Sprintf("%s/%d", rule, aws.Int64Value(p.FromPort))
+		}
+		for _, pair := range p.UserIdGroupPairs {
+			rules = append(rules, fmt.Sprintf("%s from %s", rule, aws.StringValue(pair.GroupId)))
+		}
+	}
+	return rules
+}
+
+var _ = Describe("AWS security group controller tests", func() {
+	var (
+		c          client.Client
+		ctx        context.Context
+		r          *ReconcileAWSSecurityGroups
+		mockStatus *status.MockStatus
+		ec2Cli     *fakeEC2
+		region     string
+		creds      *credentials.Credentials
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(corev1.SchemeBuilder.AddToScheme(scheme)).NotTo(HaveOccurred())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		ctx = context.Background()
+
+		mockStatus = &status.MockStatus{}
+		mockStatus.On("OnCRFound").Return()
+		mockStatus.On("OnCRNotFound").Return()
+		mockStatus.On("ReadyToMonitor")
+		mockStatus.On("ClearDegraded")
+
+		ec2Cli = &fakeEC2{groups: map[string]*ec2.SecurityGroup{
+			"sg-nodes": {
+				GroupId: aws.String("sg-nodes"),
+				VpcId:   aws.String("vpc-1"),
+				Tags:    []*ec2.Tag{{Key: aws.String("kubernetes.io/cluster/test"), Value: aws.String("owned")}},
+			},
+			"sg-other-vpc": {
+				GroupId: aws.String("sg-other-vpc"),
+				VpcId:   aws.String("vpc-2"),
+				Tags:    []*ec2.Tag{{Key: aws.String("kubernetes.io/cluster/test"), Value: aws.String("owned")}},
+			},
+			"sg-control-plane": {
+				GroupId: aws.String("sg-control-plane"),
+				VpcId:   aws.String("vpc-1"),
+			},
+		}}
+		region, creds = "", nil
+
+		r = &ReconcileAWSSecurityGroups{
+			client: c,
+			status: mockStatus,
+			newEC2Client: func(rgn string, cr *credentials.Credentials) (ec2iface.EC2API, error) {
+				region, creds = rgn, cr
+				return ec2Cli, nil
+			},
+			instanceMetadata: func() (string, string, error) {
+				return "us-east-1", "vpc-1", nil
+			},
+		}
+	})
+
+	createInstallation := func(sg *operatorv1.AWSSecurityGroups) {
+		bgp := operatorv1.BGPEnabled
+		Expect(c.Create(ctx, &operatorv1.Installation{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: operatorv1.InstallationSpec{
+				CalicoNetwork: &operatorv1.CalicoNetworkSpec{
+					BGP:     &bgp,
+					IPPools: []operatorv1.IPPool{{Encapsulation: operatorv1.EncapsulationIPIP}},
+				},
+				AWSSecurityGroups: sg,
+			},
+		})).NotTo(HaveOccurred())
+	}
+
+	It("does nothing when the security groups are not configured", func() {
+		createInstallation(nil)
+		r.newEC2Client = nil
+
+		result, err := r.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(reconcile.Result{}))
+		mockStatus.AssertCalled(GinkgoT(), "OnCRNotFound")
+	})
+
+	It("allows Calico traffic between the selected groups and adds back removed rules", func() {
+		createInstallation(&operatorv1.AWSSecurityGroups{
+			SecurityGroupIDs: []string{"sg-control-plane"},
+			SecurityGroupSelectors: []operatorv1.AWSSecurityGroupSelector{
+				{Tags: map[string]string{"kubernetes.io/cluster/test": "owned"}},
+			},
+		})
+
+		result, err := r.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(driftCheckInterval))
+		Expect(region).To(Equal("us-east-1"))
+		Expect(creds).To(BeNil())
+
+		expected := []string{
+			"tcp/179 from sg-control-plane", "4 from sg-control-plane", "tcp/5473 from sg-control-plane",
+			"tcp/179 from sg-nodes", "4 from sg-nodes", "tcp/5473 from sg-nodes",
+		}
+		Expect(ec2Cli.ingress("sg-nodes")).To(ConsistOf(expected))
+		Expect(ec2Cli.ingress("sg-control-plane")).To(ConsistOf(expected))
+		Expect(ec2Cli.ingress("sg-other-vpc")).To(BeEmpty())
+
+		By("not adding rules that already exist")
+		_, err = r.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ec2Cli.ingress("sg-nodes")).To(ConsistOf(expected))
+
+		By("adding back a rule that was removed")
+		ec2Cli.groups["sg-nodes"].IpPermissions = ec2Cli.groups["sg-nodes"].IpPermissions[1:]
+		Expect(ec2Cli.ingress("sg-nodes")).To(HaveLen(5))
+		_, err = r.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ec2Cli.ingress("sg-nodes")).To(ConsistOf(expected))
+	})
+
+	It("uses the credentials from the configured secret", func() {
+		createInstallation(&operatorv1.AWSSecurityGroups{
+			Region:                "eu-west-1",
+			SecurityGroupIDs:      []string{"sg-nodes"},
+			CredentialsSecretName: "aws-sg-creds",
+		})
+		mockStatus.On("SetDegraded", "Error reading the AWS credentials", mock.Anything).Return()
+
+		_, err := r.Reconcile(ctx, reconcile.Request{})
+		Expect(err).To(HaveOccurred())
+
+		Expect(c.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "aws-sg-creds", Namespace: common.OperatorNamespace()},
+			Data: map[string][]byte{
+				"aws_access_key_id":     []byte("id"),
+				"aws_secret_access_key": []byte("secret"),
+			},
+		})).NotTo(HaveOccurred())
+
+		_, err = r.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(region).To(Equal("eu-west-1"))
+		Expect(creds).NotTo(BeNil())
+		v, err := creds.Get()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.AccessKeyID).To(Equal("id"))
+		Expect(v.SecretAccessKey).To(Equal("secret"))
+		Expect(ec2Cli.ingress("sg-nodes")).To(HaveLen(3))
+	})
+
+	It("degrades when no security group matches a selector", func() {
+		createInstallation(&operatorv1.AWSSecurityGroups{
+			SecurityGroupSelectors: []operatorv1.AWSSecurityGroupSelector{
+				{Tags: map[string]string{"kubernetes.io/cluster/missing": "owned"}},
+			},
+		})
+		mockStatus.On("SetDegraded", "Error getting the AWS security groups", mock.MatchedBy(func(msg string) bool {
+			return strings.Contains(msg, "no security groups in VPC vpc-1")
+		})).Return()
+
+		_, err := r.Reconcile(ctx, reconcile.Request{})
+		Expect(err).To(HaveOccurred())
+		mockStatus.AssertCalled(GinkgoT(), "SetDegraded", "Error getting the AWS security groups", mock.Anything)
+		mockStatus.AssertNotCalled(GinkgoT(), "ClearDegraded")
+	})
+})
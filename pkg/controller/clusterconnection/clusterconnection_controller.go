@@ -16,7 +16,10 @@ package clusterconnection
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"reflect"
 	"time"
 
 	operatorv1 "github.com/tigera/operator/api/v1"
@@ -26,18 +29,24 @@ import (
 	"github.com/tigera/operator/pkg/controller/status"
 	"github.com/tigera/operator/pkg/controller/utils"
 	"github.com/tigera/operator/pkg/controller/utils/imageset"
+	"github.com/tigera/operator/pkg/enrollment"
 	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -54,30 +63,40 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		return nil
 	}
 	statusManager := status.New(mgr.GetClient(), "management-cluster-connection", opts.KubernetesVersion)
-	return add(mgr, newReconciler(mgr.GetClient(), mgr.GetScheme(), statusManager, opts.DetectedProvider, opts))
+	tierWatchReady := &utils.ReadyFlag{}
+	return add(mgr, newReconciler(mgr.GetClient(), mgr.GetScheme(), statusManager, opts.DetectedProvider, opts, tierWatchReady), tierWatchReady)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(cli client.Client, schema *runtime.Scheme, statusMgr status.StatusManager, p operatorv1.Provider, opts options.AddOptions) reconcile.Reconciler {
+func newReconciler(cli client.Client, schema *runtime.Scheme, statusMgr status.StatusManager, p operatorv1.Provider, opts options.AddOptions, tierWatchReady *utils.ReadyFlag) reconcile.Reconciler {
 	c := &ReconcileConnection{
-		Client:        cli,
-		Scheme:        schema,
-		Provider:      p,
-		status:        statusMgr,
-		clusterDomain: opts.ClusterDomain,
+		Client:           cli,
+		Scheme:           schema,
+		Provider:         p,
+		status:           statusMgr,
+		clusterDomain:    opts.ClusterDomain,
+		tierWatchReady:   tierWatchReady,
+		enrollmentClient: enrollment.NewClient(),
 	}
 	c.status.Run(opts.ShutdownContext)
 	return c
 }
 
 // add adds a new controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, tierWatchReady *utils.ReadyFlag) error {
 	// Create a new controller
 	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r})
 	if err != nil {
 		return fmt.Errorf("failed to create %s: %w", controllerName, err)
 	}
 
+	k8sClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return fmt.Errorf("%s failed to establish a connection to k8s: %w", controllerName, err)
+	}
+
+	go utils.WaitToAddTierWatch(c, k8sClient, log, tierWatchReady)
+
 	// Watch for changes to primary resource ManagementCluster
 	err = c.Watch(&source.Kind{Type: &operatorv1.ManagementCluster{}}, &handler.EnqueueRequestForObject{})
 	if err != nil {
@@ -91,21 +110,25 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	}
 
 	// Watch for changes to the secrets associated with the ManagementClusterConnection.
-	if err = utils.AddSecretsWatch(c, render.GuardianSecretName, common.OperatorNamespace()); err != nil {
-		return fmt.Errorf("%s failed to watch Secret resource %s: %w", controllerName, render.GuardianSecretName, err)
+	for _, secretName := range []string{
+		render.GuardianSecretName, render.GuardianBootstrapSecretName, render.PacketCaptureCertSecret,
+		render.PrometheusTLSSecretName, certificatemanagement.CASecretName,
+	} {
+		if err = utils.AddSecretsWatch(c, secretName, common.OperatorNamespace()); err != nil {
+			return fmt.Errorf("%s failed to watch Secret resource %s: %w", controllerName, secretName, err)
+		}
 	}
 
-	// Watch for changes to the secrets associated with the PacketCapture APIs.
-	if err = utils.AddSecretsWatch(c, render.PacketCaptureCertSecret, common.OperatorNamespace()); err != nil {
-		return fmt.Errorf("%s failed to watch Secret resource %s: %w", controllerName, render.PacketCaptureCertSecret, err)
-	}
-	// Watch for changes to the secrets associated with Prometheus.
-	if err = utils.AddSecretsWatch(c, render.PrometheusTLSSecretName, common.OperatorNamespace()); err != nil {
-		return fmt.Errorf("%s failed to watch Secret resource %s: %w", controllerName, render.PrometheusTLSSecretName, err)
+	if err = addProxyCredentialsWatch(c, mgr.GetClient()); err != nil {
+		return fmt.Errorf("%s failed to watch the proxy credentials Secret: %w", controllerName, err)
 	}
 
-	if err = utils.AddSecretsWatch(c, certificatemanagement.CASecretName, common.OperatorNamespace()); err != nil {
-		return fmt.Errorf("%s failed to watch Secret resource %s: %w", controllerName, certificatemanagement.CASecretName, err)
+	// Watch guardian so that the state of the tunnel is updated when its health changes.
+	if err = utils.AddNamespacedWatch(c, &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: render.GuardianDeploymentName, Namespace: render.GuardianNamespace},
+	}); err != nil {
+		return fmt.Errorf("%s failed to watch Deployment resource %s: %w", controllerName, render.GuardianDeploymentName, err)
 	}
 
 	if err = utils.AddNetworkWatch(c); err != nil {
@@ -119,16 +142,35 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	return nil
 }
 
+// addProxyCredentialsWatch watches the secret with the proxy credentials. Its name is user provided, so the watch
+// only passes on events for the secret that the ManagementClusterConnection currently references.
+func addProxyCredentialsWatch(c controller.Controller, cli client.Client) error {
+	return c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForObject{}, predicate.NewPredicateFuncs(func(o client.Object) bool {
+		if o.GetNamespace() != common.OperatorNamespace() {
+			return false
+		}
+		mcc, err := utils.GetManagementClusterConnection(context.Background(), cli)
+		if err != nil || mcc == nil || mcc.Spec.Proxy == nil {
+			return false
+		}
+		return mcc.Spec.Proxy.CredentialsSecretName == o.GetName()
+	}))
+}
+
 // blank assignment to verify that ReconcileConnection implements reconcile.Reconciler
 var _ reconcile.Reconciler = &ReconcileConnection{}
 
 // ReconcileConnection reconciles a ManagementClusterConnection object
 type ReconcileConnection struct {
-	Client        client.Client
-	Scheme        *runtime.Scheme
-	Provider      operatorv1.Provider
-	status        status.StatusManager
-	clusterDomain string
+	Client         client.Client
+	Scheme         *runtime.Scheme
+	Provider       operatorv1.Provider
+	status         status.StatusManager
+	clusterDomain  string
+	tierWatchReady *utils.ReadyFlag
+
+	// enrollmentClient obtains and renews the tunnel certificate from the management cluster.
+	enrollmentClient enrollment.Client
 }
 
 // Reconcile reads that state of the cluster for a ManagementClusterConnection object and makes changes based on the
@@ -186,9 +228,20 @@ func (r *ReconcileConnection) Reconcile(ctx context.Context, request reconcile.R
 		return reconcile.Result{}, err
 	}
 
-	// Copy the secret from the operator namespace to the guardian namespace if it is present.
+	// Copy the secret from the operator namespace to the guardian namespace if it is present. If it is not, enroll with
+	// the management cluster to obtain it when a bootstrap token is available.
 	tunnelSecret := &corev1.Secret{}
 	err = r.Client.Get(ctx, types.NamespacedName{Name: render.GuardianSecretName, Namespace: common.OperatorNamespace()}, tunnelSecret)
+	if k8serrors.IsNotFound(err) {
+		notFoundErr := err
+		if tunnelSecret, err = r.enroll(ctx, managementClusterConnection); err != nil {
+			reqLogger.Error(err, "Failed to enroll with the management cluster")
+			r.status.SetDegraded("Failed to enroll with the management cluster", err.Error())
+			return result, err
+		} else if tunnelSecret == nil {
+			err = notFoundErr
+		}
+	}
 	if err != nil {
 		r.status.SetDegraded("Error retrieving secrets from guardian namespace", err.Error())
 		if !k8serrors.IsNotFound(err) {
@@ -197,6 +250,26 @@ func (r *ReconcileConnection) Reconcile(ctx context.Context, request reconcile.R
 		return result, err
 	}
 
+	renewAt, err := r.renewTunnelCertificate(ctx, managementClusterConnection, tunnelSecret)
+	if err != nil {
+		reqLogger.Error(err, "Failed to renew the tunnel certificate")
+		r.status.SetDegraded("Failed to renew the tunnel certificate", err.Error())
+		return result, err
+	}
+	if !renewAt.IsZero() {
+		result.RequeueAfter = time.Until(renewAt)
+	}
+
+	var proxyCredentials *corev1.Secret
+	if proxy := managementClusterConnection.Spec.Proxy; proxy != nil && proxy.CredentialsSecretName != "" {
+		proxyCredentials, err = getProxyCredentials(ctx, r.Client, proxy.CredentialsSecretName)
+		if err != nil {
+			reqLogger.Error(err, "Error retrieving proxy credentials")
+			r.status.SetDegraded("Error retrieving proxy credentials", err.Error())
+			return reconcile.Result{}, err
+		}
+	}
+
 	trustedCertBundle := certificateManager.CreateTrustedBundle()
 	for _, secretName := range []string{render.PacketCaptureCertSecret, render.PrometheusTLSSecretName} {
 		secret, err := certificateManager.GetCertificate(r.Client, secretName, common.OperatorNamespace())
@@ -212,14 +285,28 @@ func (r *ReconcileConnection) Reconcile(ctx context.Context, request reconcile.R
 		trustedCertBundle.AddCertificates(secret)
 	}
 
-	ch := utils.NewComponentHandler(log, r.Client, r.Scheme, managementClusterConnection)
+	networkPolicyState, err := utils.GetNetworkPolicyState(ctx, r.Client, r.tierWatchReady, instl, managementClusterConnection.Spec.ComponentNetworkPolicy)
+	if err != nil {
+		log.Error(err, "Error querying allow-tigera tier")
+		r.status.SetDegraded("Error querying allow-tigera tier", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	ch := utils.NewComponentHandler(log, r.Client, r.Scheme, managementClusterConnection, instl)
 	guardianCfg := &render.GuardianConfiguration{
-		URL:               managementClusterConnection.Spec.ManagementClusterAddr,
-		PullSecrets:       pullSecrets,
-		Openshift:         r.Provider == operatorv1.ProviderOpenShift,
-		Installation:      instl,
-		TunnelSecret:      tunnelSecret,
-		TrustedCertBundle: trustedCertBundle,
+		URL:                managementClusterConnection.Spec.ManagementClusterAddr,
+		PullSecrets:        pullSecrets,
+		Openshift:          r.Provider == operatorv1.ProviderOpenShift,
+		Installation:       instl,
+		TunnelSecret:       tunnelSecret,
+		TrustedCertBundle:  trustedCertBundle,
+		NetworkPolicyState: networkPolicyState,
+		TLSServerName:      managementClusterConnection.Spec.TLSServerName,
+		ProxyCredentials:   proxyCredentials,
+		LogLevel:           loglevel.Resolve(instl, managementClusterConnection.Spec.LogLevel),
+	}
+	if managementClusterConnection.Spec.Proxy != nil {
+		guardianCfg.ProxyURL = managementClusterConnection.Spec.Proxy.URL
 	}
 	component := render.Guardian(guardianCfg)
 
@@ -234,8 +321,264 @@ func (r *ReconcileConnection) Reconcile(ctx context.Context, request reconcile.R
 		return result, err
 	}
 
+	expiry, err := tunnelCertificateExpiry(tunnelSecret)
+	if err != nil {
+		reqLogger.Error(err, "Error parsing the tunnel certificate")
+		r.status.SetDegraded("Error parsing the tunnel certificate", err.Error())
+		return reconcile.Result{}, err
+	}
+	if err = r.updateStatus(ctx, managementClusterConnection, expiry); err != nil {
+		reqLogger.Error(err, "Error updating the ManagementClusterConnection status")
+		r.status.SetDegraded("Error updating the ManagementClusterConnection status", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	if expiry != nil && expiry.Time.Before(time.Now()) {
+		r.status.SetDegraded(fmt.Sprintf("The certificate in secret %s expired at %s", render.GuardianSecretName, expiry.Time.Format(time.RFC3339)), "")
+		return reconcile.Result{}, nil
+	}
+
 	r.status.ClearDegraded()
 
 	//We should create the Guardian deployment.
 	return result, nil
 }
+
+// updateStatus records when the guardian deployment last became available and the expiry of the tunnel certificate
+// in the status of the ManagementClusterConnection.
+func (r *ReconcileConnection) updateStatus(ctx context.Context, mcc *operatorv1.ManagementClusterConnection, certExpiry *metav1.Time) error {
+	status := mcc.Status.DeepCopy()
+	status.TunnelCertificateExpiry = certExpiry
+
+	d := &appsv1.Deployment{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: render.GuardianDeploymentName, Namespace: render.GuardianNamespace}, d)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable && c.Status == corev1.ConditionTrue {
+			t := c.LastTransitionTime
+			status.LastGuardianAvailableTime = &t
+		}
+	}
+
+	if reflect.DeepEqual(*status, mcc.Status) {
+		return nil
+	}
+	mcc.Status = *status
+	return r.Client.Status().Update(ctx, mcc)
+}
+
+// getProxyCredentials returns the secret in the operator namespace with the credentials for the proxy.
+func getProxyCredentials(ctx context.Context, cli client.Client, name string) (*corev1.Secret, error) {
+	s := &corev1.Secret{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: name, Namespace: common.OperatorNamespace()}, s); err != nil {
+		return nil, fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+	for _, k := range []string{render.GuardianProxyUsernameKey, render.GuardianProxyPasswordKey} {
+		if len(s.Data[k]) == 0 {
+			return nil, fmt.Errorf("secret %s does not have a value for %q", name, k)
+		}
+	}
+	return s, nil
+}
+
+// tunnelCertificateExpiry returns when the certificate guardian authenticates to the management cluster with expires,
+// or nil if the secret does not contain it.
+func tunnelCertificateExpiry(s *corev1.Secret) (*metav1.Time, error) {
+	for _, k := range []string{render.GuardianTunnelCertKey, corev1.TLSCertKey} {
+		if len(s.Data[k]) == 0 {
+			continue
+		}
+		cert, err := certificatemanagement.ParseCertificate(s.Data[k])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s of secret %s: %w", k, s.Name, err)
+		}
+		expiry := metav1.NewTime(cert.NotAfter)
+		return &expiry, nil
+	}
+	return nil, nil
+}
+
+// enroll obtains the tunnel certificate from the enrollment endpoint of the management cluster with the token of the
+// bootstrap secret, and stores it in the tunnel secret. It returns nil if there is no bootstrap secret. The key is
+// stored before enrolling, so that the enrollment can be retried with the same key, which the enrollment endpoint
+// issues a certificate for again, if the certificate is not received or stored. The enrolled certificate is stored as
+// unconfirmed, so that it is confirmed with the enrollment endpoint like a renewed one.
+func (r *ReconcileConnection) enroll(ctx context.Context, mcc *operatorv1.ManagementClusterConnection) (*corev1.Secret, error) {
+	bootstrap := &corev1.Secret{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: render.GuardianBootstrapSecretName, Namespace: common.OperatorNamespace()}, bootstrap)
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	address := string(bootstrap.Data[enrollment.BootstrapAddressKey])
+	if address == "" {
+		return nil, fmt.Errorf("secret %s does not have a value for %q", bootstrap.Name, enrollment.BootstrapAddressKey)
+	}
+	keySecret, err := r.enrollmentKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ep := enrollment.Endpoint{
+		Address:    address,
+		CA:         bootstrap.Data[enrollment.BootstrapCAKey],
+		ServerName: mcc.Spec.TLSServerName,
+	}
+	resp, err := r.enrollmentClient.Enroll(ctx, ep, string(bootstrap.Data[enrollment.BootstrapTokenKey]), keySecret.Data[render.GuardianEnrollmentCSRKey])
+	if err != nil {
+		return nil, err
+	}
+
+	tunnelSecret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      render.GuardianSecretName,
+			Namespace: common.OperatorNamespace(),
+			Annotations: map[string]string{
+				enrollment.AddressAnnotation:     address,
+				enrollment.UnconfirmedAnnotation: "true",
+			},
+		},
+		Data: map[string][]byte{
+			render.GuardianTunnelCertKey:   []byte(resp.Certificate),
+			render.GuardianTunnelKeyKey:    keySecret.Data[render.GuardianTunnelKeyKey],
+			render.GuardianManagementCAKey: []byte(resp.CA),
+		},
+	}
+	if err := r.Client.Create(ctx, tunnelSecret); err != nil {
+		return nil, err
+	}
+	// The token is only accepted for the key it enrolled, which is now stored in the tunnel secret.
+	for _, s := range []*corev1.Secret{keySecret, bootstrap} {
+		if err := r.Client.Delete(ctx, s); err != nil && !k8serrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	log.Info("Enrolled with the management cluster", "address", address)
+	return tunnelSecret, nil
+}
+
+// enrollmentKey returns the secret with the key and certificate signing request to enroll with, creating it if needed.
+func (r *ReconcileConnection) enrollmentKey(ctx context.Context) (*corev1.Secret, error) {
+	keySecret := &corev1.Secret{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: render.GuardianEnrollmentSecretName, Namespace: common.OperatorNamespace()}, keySecret)
+	if err == nil {
+		return keySecret, nil
+	} else if !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	key, csr, err := enrollment.NewKey()
+	if err != nil {
+		return nil, err
+	}
+	keySecret = &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: render.GuardianEnrollmentSecretName, Namespace: common.OperatorNamespace()},
+		Data: map[string][]byte{
+			render.GuardianTunnelKeyKey:     key,
+			render.GuardianEnrollmentCSRKey: csr,
+		},
+	}
+	if err := r.Client.Create(ctx, keySecret); err != nil {
+		return nil, err
+	}
+	return keySecret, nil
+}
+
+// renewTunnelCertificate renews the tunnel certificate through the enrollment endpoint that issued it once it is due,
+// and returns when the certificate is due for renewal next. It returns the zero time for certificates that were not
+// obtained by enrolling, which are not renewed. The renewed certificate is stored before it is confirmed with the
+// enrollment endpoint, which keeps accepting the previous certificate until then. If storing or confirming the renewed
+// certificate fails, guardian keeps running with the previous certificate and the renewal or confirmation is retried.
+func (r *ReconcileConnection) renewTunnelCertificate(ctx context.Context, mcc *operatorv1.ManagementClusterConnection, tunnelSecret *corev1.Secret) (time.Time, error) {
+	address := tunnelSecret.Annotations[enrollment.AddressAnnotation]
+	if address == "" {
+		return time.Time{}, nil
+	}
+	renewBefore := enrollment.DefaultRenewBefore
+	if mcc.Spec.CertificateRenewBefore != nil {
+		renewBefore = mcc.Spec.CertificateRenewBefore.Duration
+	}
+
+	current, err := tls.X509KeyPair(tunnelSecret.Data[render.GuardianTunnelCertKey], tunnelSecret.Data[render.GuardianTunnelKeyKey])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to load the tunnel certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(current.Certificate[0])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse the tunnel certificate: %w", err)
+	}
+	ep := enrollment.Endpoint{
+		Address:    address,
+		CA:         tunnelSecret.Data[render.GuardianManagementCAKey],
+		ServerName: mcc.Spec.TLSServerName,
+	}
+	if err := r.confirmTunnelCertificate(ctx, ep, current, tunnelSecret); err != nil {
+		return time.Time{}, err
+	}
+	renewAt := renewalTime(cert, renewBefore)
+	if time.Now().Before(renewAt) {
+		return renewAt, nil
+	}
+
+	key, csr, err := enrollment.NewKey()
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp, err := r.enrollmentClient.Renew(ctx, ep, current, csr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	cert, err = certificatemanagement.ParseCertificate([]byte(resp.Certificate))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse the renewed tunnel certificate: %w", err)
+	}
+	renewed, err := tls.X509KeyPair([]byte(resp.Certificate), key)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to load the renewed tunnel certificate: %w", err)
+	}
+
+	tunnelSecret.Annotations[enrollment.UnconfirmedAnnotation] = "true"
+	tunnelSecret.Data[render.GuardianTunnelCertKey] = []byte(resp.Certificate)
+	tunnelSecret.Data[render.GuardianTunnelKeyKey] = key
+	tunnelSecret.Data[render.GuardianManagementCAKey] = []byte(resp.CA)
+	if err := r.Client.Update(ctx, tunnelSecret); err != nil {
+		return time.Time{}, err
+	}
+	log.Info("Renewed the tunnel certificate", "expiry", cert.NotAfter)
+	if err := r.confirmTunnelCertificate(ctx, ep, renewed, tunnelSecret); err != nil {
+		return time.Time{}, err
+	}
+	return renewalTime(cert, renewBefore), nil
+}
+
+// confirmTunnelCertificate confirms the enrolled or renewed certificate of the tunnel secret with the enrollment
+// endpoint if it has not been confirmed yet, and then removes the UnconfirmedAnnotation from the secret.
+func (r *ReconcileConnection) confirmTunnelCertificate(ctx context.Context, ep enrollment.Endpoint, cert tls.Certificate, tunnelSecret *corev1.Secret) error {
+	if _, ok := tunnelSecret.Annotations[enrollment.UnconfirmedAnnotation]; !ok {
+		return nil
+	}
+	if err := r.enrollmentClient.Confirm(ctx, ep, cert); err != nil {
+		return fmt.Errorf("failed to confirm the tunnel certificate: %w", err)
+	}
+	delete(tunnelSecret.Annotations, enrollment.UnconfirmedAnnotation)
+	if err := r.Client.Update(ctx, tunnelSecret); err != nil {
+		return err
+	}
+	log.Info("Confirmed the tunnel certificate")
+	return nil
+}
+
+// renewalTime returns when the certificate should be renewed. Certificates whose lifetime is shorter than renewBefore
+// are renewed halfway through their lifetime, rather than continuously.
+func renewalTime(cert *x509.Certificate, renewBefore time.Duration) time.Time {
+	renewAt := cert.NotAfter.Add(-renewBefore)
+	if !renewAt.After(cert.NotBefore) {
+		renewAt = cert.NotBefore.Add(cert.NotAfter.Sub(cert.NotBefore) / 2)
+	}
+	return renewAt
+}
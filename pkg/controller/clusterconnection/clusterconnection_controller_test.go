@@ -15,14 +15,24 @@
 package clusterconnection_test
 
 import (
+	"bytes"
 	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"time"
 
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
 	"github.com/tigera/operator/pkg/controller/status"
 	"github.com/tigera/operator/pkg/dns"
+	"github.com/tigera/operator/pkg/enrollment"
 	"github.com/tigera/operator/test"
 
 	"github.com/tigera/operator/pkg/controller/clusterconnection"
@@ -37,8 +47,11 @@ import (
 	. "github.com/onsi/gomega"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -69,6 +82,7 @@ var _ = Describe("ManagementClusterConnection controller tests", func() {
 		mockStatus.On("AddDeployments", mock.Anything)
 		mockStatus.On("AddStatefulSets", mock.Anything)
 		mockStatus.On("AddCronJobs", mock.Anything)
+		mockStatus.On("SetPodSecurityRequirements", mock.Anything)
 		mockStatus.On("ClearDegraded", mock.Anything)
 		mockStatus.On("SetDegraded", mock.Anything, mock.Anything)
 		mockStatus.On("OnCRFound").Return()
@@ -197,4 +211,402 @@ var _ = Describe("ManagementClusterConnection controller tests", func() {
 					"sha256:guardianhash")))
 		})
 	})
+
+	Context("status", func() {
+		It("should report when guardian became available and the certificate expiry", func() {
+			r = clusterconnection.NewReconcilerWithShims(statusPreservingClient{c}, scheme, mockStatus, operatorv1.ProviderNone)
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			tunnelSecret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.GuardianSecretName, Namespace: common.OperatorNamespace()}}
+			Expect(test.GetResource(c, &tunnelSecret)).To(BeNil())
+			cert, err := certificatemanagement.ParseCertificate(tunnelSecret.Data[corev1.TLSCertKey])
+			Expect(err).NotTo(HaveOccurred())
+
+			mcc := &operatorv1.ManagementClusterConnection{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, mcc)).To(Succeed())
+			Expect(mcc.Status.LastGuardianAvailableTime).To(BeNil())
+			Expect(mcc.Status.TunnelCertificateExpiry.Time.Unix()).To(Equal(cert.NotAfter.Unix()))
+
+			By("marking guardian as available")
+			d := &appsv1.Deployment{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: render.GuardianDeploymentName, Namespace: render.GuardianNamespace}, d)).To(Succeed())
+			available := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+			d.Status.Conditions = []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue, LastTransitionTime: available},
+			}
+			Expect(c.Status().Update(ctx, d)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, mcc)).To(Succeed())
+			Expect(mcc.Status.LastGuardianAvailableTime.Equal(&available)).To(BeTrue())
+
+			By("keeping the available time once guardian becomes unavailable")
+			Expect(c.Get(ctx, client.ObjectKey{Name: render.GuardianDeploymentName, Namespace: render.GuardianNamespace}, d)).To(Succeed())
+			d.Status.Conditions[0].Status = corev1.ConditionFalse
+			d.Status.Conditions[0].LastTransitionTime = metav1.Now()
+			Expect(c.Status().Update(ctx, d)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, mcc)).To(Succeed())
+			Expect(mcc.Status.LastGuardianAvailableTime.Equal(&available)).To(BeTrue())
+		})
+	})
+
+	Context("proxy", func() {
+		var setProxy = func(proxy *operatorv1.ManagementClusterConnectionProxy) {
+			mcc := &operatorv1.ManagementClusterConnection{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, mcc)).To(Succeed())
+			mcc.Spec.Proxy = proxy
+			Expect(c.Update(ctx, mcc)).To(Succeed())
+		}
+
+		AfterEach(func() {
+			setProxy(nil)
+		})
+
+		It("should fail when the proxy credentials are incomplete", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "incomplete-proxy-creds", Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{render.GuardianProxyUsernameKey: []byte("user")},
+			})).To(Succeed())
+			setProxy(&operatorv1.ManagementClusterConnectionProxy{URL: "http://proxy:3128", CredentialsSecretName: "incomplete-proxy-creds"})
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`does not have a value for "password"`))
+		})
+
+		It("should render guardian with the proxy and its credentials", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "proxy-creds", Namespace: common.OperatorNamespace()},
+				Data: map[string][]byte{
+					render.GuardianProxyUsernameKey: []byte("user"),
+					render.GuardianProxyPasswordKey: []byte("pass"),
+				},
+			})).To(Succeed())
+			setProxy(&operatorv1.ManagementClusterConnectionProxy{URL: "http://proxy:3128", CredentialsSecretName: "proxy-creds"})
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			copied := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.GuardianProxyCredentialsSecretName, Namespace: render.GuardianNamespace}}
+			Expect(test.GetResource(c, copied)).To(BeNil())
+			Expect(copied.Data).To(HaveKeyWithValue(render.GuardianProxyPasswordKey, []byte("pass")))
+			d := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.GuardianDeploymentName, Namespace: render.GuardianNamespace}}
+			Expect(test.GetResource(c, &d)).To(BeNil())
+			Expect(d.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "GUARDIAN_PROXY_URL", Value: "http://proxy:3128"}))
+
+			By("deleting the copy of the credentials once the proxy is removed")
+			setProxy(nil)
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(copied), &corev1.Secret{})).NotTo(Succeed())
+		})
+	})
+
+	Context("enrollment", func() {
+		var enroller *fakeEnrollmentClient
+		var caPEM []byte
+
+		BeforeEach(func() {
+			caSecret, err := certificatemanagement.CreateSelfSignedSecret("tunnel", common.OperatorNamespace(), "tigera-voltron", []string{"voltron"})
+			Expect(err).NotTo(HaveOccurred())
+			caPEM = caSecret.Data[corev1.TLSCertKey]
+			ca, err := tls.X509KeyPair(caPEM, caSecret.Data[corev1.TLSPrivateKeyKey])
+			Expect(err).NotTo(HaveOccurred())
+			enroller = &fakeEnrollmentClient{ca: ca, caPEM: caPEM, validity: 2 * time.Hour}
+			r = clusterconnection.NewReconcilerWithEnrollmentShims(c, scheme, mockStatus, operatorv1.ProviderNone, enroller)
+
+			// Start without a tunnel secret, like a managed cluster that has not enrolled yet.
+			Expect(c.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.GuardianSecretName, Namespace: common.OperatorNamespace()}})).To(Succeed())
+		})
+
+		It("should enroll with the bootstrap token and renew the certificate before it expires", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.GuardianBootstrapSecretName, Namespace: common.OperatorNamespace()},
+				Data: map[string][]byte{
+					enrollment.BootstrapTokenKey:   []byte("secret-token"),
+					enrollment.BootstrapCAKey:      caPEM,
+					enrollment.BootstrapAddressKey: []byte("mgmt.example.com:9450"),
+				},
+			})).To(Succeed())
+
+			result, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(enroller.enrolled).To(Equal(1))
+			Expect(enroller.endpoint.Address).To(Equal("mgmt.example.com:9450"))
+			// The certificate is valid for less than the default renewal period, so it is renewed halfway through.
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+			tunnelSecret := &corev1.Secret{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: render.GuardianSecretName, Namespace: common.OperatorNamespace()}, tunnelSecret)).To(Succeed())
+			Expect(tunnelSecret.Annotations).To(HaveKeyWithValue(enrollment.AddressAnnotation, "mgmt.example.com:9450"))
+			Expect(tunnelSecret.Data).To(HaveKeyWithValue(render.GuardianManagementCAKey, caPEM))
+			_, err = tls.X509KeyPair(tunnelSecret.Data[render.GuardianTunnelCertKey], tunnelSecret.Data[render.GuardianTunnelKeyKey])
+			Expect(err).NotTo(HaveOccurred())
+
+			By("confirming the enrolled certificate")
+			Expect(enroller.confirmed).To(Equal([]string{string(tunnelSecret.Data[render.GuardianTunnelCertKey])}))
+			Expect(tunnelSecret.Annotations).NotTo(HaveKey(enrollment.UnconfirmedAnnotation))
+
+			for _, name := range []string{render.GuardianBootstrapSecretName, render.GuardianEnrollmentSecretName} {
+				err = c.Get(ctx, client.ObjectKey{Name: name, Namespace: common.OperatorNamespace()}, &corev1.Secret{})
+				Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			}
+
+			By("renewing a certificate that is due right after enrolling")
+			enroller.notBefore = -2 * time.Hour
+			enroller.validity = 3 * time.Hour
+			Expect(c.Delete(ctx, tunnelSecret)).To(Succeed())
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.GuardianBootstrapSecretName, Namespace: common.OperatorNamespace()},
+				Data: map[string][]byte{
+					enrollment.BootstrapTokenKey:   []byte("secret-token"),
+					enrollment.BootstrapCAKey:      caPEM,
+					enrollment.BootstrapAddressKey: []byte("mgmt.example.com:31450"),
+				},
+			})).To(Succeed())
+			result, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(enroller.enrolled).To(Equal(2))
+			Expect(enroller.renewed).To(Equal(1))
+			Expect(enroller.endpoint.Address).To(Equal("mgmt.example.com:31450"))
+
+			Expect(c.Get(ctx, client.ObjectKey{Name: render.GuardianSecretName, Namespace: common.OperatorNamespace()}, tunnelSecret)).To(Succeed())
+			cert, err := certificatemanagement.ParseCertificate(tunnelSecret.Data[render.GuardianTunnelCertKey])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cert.NotAfter).To(BeTemporally("~", time.Now().Add(3*time.Hour), time.Minute))
+			Expect(result.RequeueAfter).To(BeNumerically("~", 90*time.Minute, time.Minute))
+		})
+
+		It("should keep the current certificate when storing or confirming the renewed one fails", func() {
+			// Enroll with a certificate that is due for renewal.
+			enroller.notBefore = -2 * time.Hour
+			enroller.validity = 3 * time.Hour
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.GuardianBootstrapSecretName, Namespace: common.OperatorNamespace()},
+				Data: map[string][]byte{
+					enrollment.BootstrapTokenKey:   []byte("secret-token"),
+					enrollment.BootstrapCAKey:      caPEM,
+					enrollment.BootstrapAddressKey: []byte("mgmt.example.com:9450"),
+				},
+			})).To(Succeed())
+			fc := &failingSecretUpdateClient{Client: c, fail: true}
+			r = clusterconnection.NewReconcilerWithEnrollmentShims(fc, scheme, mockStatus, operatorv1.ProviderNone, enroller)
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError("update failed"))
+			Expect(enroller.enrolled).To(Equal(1))
+			Expect(enroller.renewed).To(Equal(1))
+
+			key := client.ObjectKey{Name: render.GuardianSecretName, Namespace: common.OperatorNamespace()}
+			tunnelSecret := &corev1.Secret{}
+			Expect(c.Get(ctx, key, tunnelSecret)).To(Succeed())
+			enrolledCert := tunnelSecret.Data[render.GuardianTunnelCertKey]
+			Expect(enroller.confirmed).To(Equal([]string{string(enrolledCert)}))
+			Expect(tunnelSecret.Annotations).NotTo(HaveKey(enrollment.UnconfirmedAnnotation))
+
+			By("renewing again with the current certificate")
+			fc.fail = false
+			enroller.confirmErr = fmt.Errorf("confirm failed")
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("confirm failed")))
+			Expect(enroller.renewed).To(Equal(2))
+			Expect(enroller.renewedWith).To(Equal([]string{string(enrolledCert), string(enrolledCert)}))
+
+			By("storing the renewed certificate until it is confirmed")
+			tunnelSecret = &corev1.Secret{}
+			Expect(c.Get(ctx, key, tunnelSecret)).To(Succeed())
+			renewedCert := tunnelSecret.Data[render.GuardianTunnelCertKey]
+			Expect(renewedCert).NotTo(Equal(enrolledCert))
+			Expect(tunnelSecret.Annotations).To(HaveKey(enrollment.UnconfirmedAnnotation))
+
+			By("confirming the stored certificate without renewing it again")
+			enroller.confirmErr = nil
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(enroller.renewed).To(Equal(2))
+			Expect(enroller.confirmed).To(Equal([]string{string(enrolledCert), string(renewedCert)}))
+			tunnelSecret = &corev1.Secret{}
+			Expect(c.Get(ctx, key, tunnelSecret)).To(Succeed())
+			Expect(tunnelSecret.Data[render.GuardianTunnelCertKey]).To(Equal(renewedCert))
+			Expect(tunnelSecret.Annotations).NotTo(HaveKey(enrollment.UnconfirmedAnnotation))
+		})
+
+		It("should enroll again with the same key when the certificate was not stored", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.GuardianBootstrapSecretName, Namespace: common.OperatorNamespace()},
+				Data: map[string][]byte{
+					enrollment.BootstrapTokenKey:   []byte("secret-token"),
+					enrollment.BootstrapCAKey:      caPEM,
+					enrollment.BootstrapAddressKey: []byte("mgmt.example.com:9450"),
+				},
+			})).To(Succeed())
+			fc := &failingSecretCreateClient{Client: c, name: render.GuardianSecretName}
+			r = clusterconnection.NewReconcilerWithEnrollmentShims(fc, scheme, mockStatus, operatorv1.ProviderNone, enroller)
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError("create failed"))
+			Expect(enroller.enrolled).To(Equal(1))
+			keySecret := &corev1.Secret{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: render.GuardianEnrollmentSecretName, Namespace: common.OperatorNamespace()}, keySecret)).To(Succeed())
+
+			fc.name = ""
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(enroller.enrolled).To(Equal(2))
+			Expect(enroller.csrs).To(Equal([]string{
+				string(keySecret.Data[render.GuardianEnrollmentCSRKey]),
+				string(keySecret.Data[render.GuardianEnrollmentCSRKey]),
+			}))
+			tunnelSecret := &corev1.Secret{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: render.GuardianSecretName, Namespace: common.OperatorNamespace()}, tunnelSecret)).To(Succeed())
+			Expect(tunnelSecret.Data[render.GuardianTunnelKeyKey]).To(Equal(keySecret.Data[render.GuardianTunnelKeyKey]))
+		})
+
+		It("should report the error when enrolling fails", func() {
+			bootstrap := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.GuardianBootstrapSecretName, Namespace: common.OperatorNamespace()},
+				Data: map[string][]byte{
+					enrollment.BootstrapTokenKey: []byte("invalid-token"),
+					enrollment.BootstrapCAKey:    caPEM,
+				},
+			}
+			Expect(c.Create(ctx, bootstrap)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring(`does not have a value for "address"`)))
+			Expect(enroller.enrolled).To(Equal(0))
+
+			bootstrap.Data[enrollment.BootstrapAddressKey] = []byte("mgmt.example.com:9450")
+			Expect(c.Update(ctx, bootstrap)).To(Succeed())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("invalid token")))
+			Expect(c.Get(ctx, client.ObjectKey{Name: render.GuardianBootstrapSecretName, Namespace: common.OperatorNamespace()}, &corev1.Secret{})).To(Succeed())
+		})
+	})
 })
+
+// fakeEnrollmentClient issues certificates signed by the CA that are valid for the validity. Enrolled certificates
+// are valid from notBefore, renewed certificates from now. It records the CSRs of enrollments and the PEM encoded
+// certificates that renewals and confirmations authenticate with, and fails confirmations with confirmErr.
+type fakeEnrollmentClient struct {
+	ca        tls.Certificate
+	caPEM     []byte
+	notBefore time.Duration
+	validity  time.Duration
+
+	confirmErr error
+
+	endpoint    enrollment.Endpoint
+	enrolled    int
+	csrs        []string
+	renewed     int
+	renewedWith []string
+	confirmed   []string
+}
+
+func (f *fakeEnrollmentClient) Enroll(_ context.Context, ep enrollment.Endpoint, token string, csr []byte) (*enrollment.Response, error) {
+	if token != "secret-token" {
+		return nil, fmt.Errorf("enroll failed: 401 Unauthorized: invalid token")
+	}
+	f.endpoint = ep
+	f.enrolled++
+	f.csrs = append(f.csrs, string(csr))
+	return f.issue(csr, time.Now().Add(f.notBefore))
+}
+
+func (f *fakeEnrollmentClient) Renew(_ context.Context, ep enrollment.Endpoint, current tls.Certificate, csr []byte) (*enrollment.Response, error) {
+	f.endpoint = ep
+	f.renewed++
+	f.renewedWith = append(f.renewedWith, certificatePEM(current))
+	return f.issue(csr, time.Now())
+}
+
+func (f *fakeEnrollmentClient) Confirm(_ context.Context, ep enrollment.Endpoint, renewed tls.Certificate) error {
+	f.endpoint = ep
+	if f.confirmErr != nil {
+		return f.confirmErr
+	}
+	f.confirmed = append(f.confirmed, certificatePEM(renewed))
+	return nil
+}
+
+func certificatePEM(cert tls.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}))
+}
+
+func (f *fakeEnrollmentClient) issue(csrPEM []byte, notBefore time.Time) (*enrollment.Response, error) {
+	block, _ := pem.Decode(csrPEM)
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := x509.ParseCertificate(f.ca.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "managed-cluster"},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(f.validity),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, csr.PublicKey, f.ca.PrivateKey.(crypto.Signer))
+	if err != nil {
+		return nil, err
+	}
+	var certPEM bytes.Buffer
+	if err := pem.Encode(&certPEM, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+		return nil, err
+	}
+	return &enrollment.Response{Certificate: certPEM.String(), CA: string(f.caPEM)}, nil
+}
+
+// statusPreservingClient keeps the status of deployments when they are updated, like the API server does for
+// resources with a status subresource.
+type statusPreservingClient struct {
+	client.Client
+}
+
+func (c statusPreservingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if d, ok := obj.(*appsv1.Deployment); ok {
+		cur := &appsv1.Deployment{}
+		if err := c.Client.Get(ctx, client.ObjectKeyFromObject(d), cur); err == nil {
+			d.Status = cur.Status
+		}
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// failingSecretUpdateClient fails storing renewed certificates, which are updates of secrets that mark them as
+// unconfirmed, while fail is set.
+type failingSecretUpdateClient struct {
+	client.Client
+	fail bool
+}
+
+func (c *failingSecretUpdateClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if _, ok := obj.(*corev1.Secret); ok && c.fail {
+		if _, unconfirmed := obj.GetAnnotations()[enrollment.UnconfirmedAnnotation]; unconfirmed {
+			return fmt.Errorf("update failed")
+		}
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// failingSecretCreateClient fails creating the secret with the name.
+type failingSecretCreateClient struct {
+	client.Client
+	name string
+}
+
+func (c *failingSecretCreateClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*corev1.Secret); ok && obj.GetName() == c.name {
+		return fmt.Errorf("create failed")
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
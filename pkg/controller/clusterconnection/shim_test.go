@@ -23,6 +23,8 @@ import (
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/controller/options"
 	"github.com/tigera/operator/pkg/controller/status"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/enrollment"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -33,5 +35,11 @@ func NewReconcilerWithShims(cli client.Client, schema *runtime.Scheme, status st
 		ShutdownContext: context.Background(),
 	}
 
-	return newReconciler(cli, schema, status, provider, opts)
+	return newReconciler(cli, schema, status, provider, opts, &utils.ReadyFlag{})
+}
+
+func NewReconcilerWithEnrollmentShims(cli client.Client, schema *runtime.Scheme, status status.StatusManager, provider operatorv1.Provider, enrollmentClient enrollment.Client) reconcile.Reconciler {
+	r := NewReconcilerWithShims(cli, schema, status, provider).(*ReconcileConnection)
+	r.enrollmentClient = enrollmentClient
+	return r
 }
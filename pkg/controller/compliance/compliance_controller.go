@@ -17,8 +17,13 @@ package compliance
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"text/template"
 	"time"
 
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
@@ -29,8 +34,11 @@ import (
 	"github.com/tigera/operator/pkg/dns"
 	"github.com/tigera/operator/pkg/render"
 	rcertificatemanagement "github.com/tigera/operator/pkg/render/certificatemanagement"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
@@ -53,8 +61,9 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		return nil
 	}
 	licenseAPIReady := &utils.ReadyFlag{}
+	tierWatchReady := &utils.ReadyFlag{}
 	// create the reconciler
-	reconciler := newReconciler(mgr, opts, licenseAPIReady)
+	reconciler := newReconciler(mgr, opts, licenseAPIReady, tierWatchReady)
 
 	// Create a new controller
 	controller, err := controller.New("compliance-controller", mgr, controller.Options{Reconciler: reconcile.Reconciler(reconciler)})
@@ -69,12 +78,13 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	}
 
 	go utils.WaitToAddLicenseKeyWatch(controller, k8sClient, log, licenseAPIReady)
+	go utils.WaitToAddTierWatch(controller, k8sClient, log, tierWatchReady)
 
 	return add(mgr, controller)
 }
 
 // newReconciler returns a new *reconcile.Reconciler
-func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady *utils.ReadyFlag) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady *utils.ReadyFlag, tierWatchReady *utils.ReadyFlag) reconcile.Reconciler {
 	r := &ReconcileCompliance{
 		client:          mgr.GetClient(),
 		scheme:          mgr.GetScheme(),
@@ -82,6 +92,7 @@ func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady
 		status:          status.New(mgr.GetClient(), "compliance", opts.KubernetesVersion),
 		clusterDomain:   opts.ClusterDomain,
 		licenseAPIReady: licenseAPIReady,
+		tierWatchReady:  tierWatchReady,
 		usePSP:          opts.UsePSP,
 	}
 	r.status.Run(opts.ShutdownContext)
@@ -124,6 +135,12 @@ func add(mgr manager.Manager, c controller.Controller) error {
 		}
 	}
 
+	// Watch all secrets in the operator namespace, since the name of the secret with the credentials of the bucket
+	// that reports are exported to is configurable.
+	if err = utils.AddSecretsWatch(c, "", common.OperatorNamespace()); err != nil {
+		return fmt.Errorf("compliance-controller failed to watch the Secret resource: %w", err)
+	}
+
 	if err = utils.AddConfigMapWatch(c, relasticsearch.ClusterConfigConfigMapName, common.OperatorNamespace()); err != nil {
 		return fmt.Errorf("compliance-controller failed to watch the ConfigMap resource: %w", err)
 	}
@@ -161,6 +178,7 @@ type ReconcileCompliance struct {
 	status          status.StatusManager
 	clusterDomain   string
 	licenseAPIReady *utils.ReadyFlag
+	tierWatchReady  *utils.ReadyFlag
 	usePSP          bool
 }
 
@@ -331,6 +349,10 @@ func (r *ReconcileCompliance) Reconcile(ctx context.Context, request reconcile.R
 			return reconcile.Result{}, err
 		}
 	}
+	if complianceServerCertSecret != nil && instance.Spec.ReportExport != nil {
+		// The report exporter connects to the compliance server, whose certificate may not be issued by the operator CA.
+		trustedBundle.AddCertificates(complianceServerCertSecret)
+	}
 	certificateManager.AddToStatusManager(r.status, render.ComplianceNamespace)
 
 	// Fetch the Authentication spec. If present, we use to configure user authentication.
@@ -345,7 +367,7 @@ func (r *ReconcileCompliance) Reconcile(ctx context.Context, request reconcile.R
 	}
 
 	// Create a component handler to manage the rendered component.
-	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance)
+	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance, network)
 
 	keyValidatorConfig, err := utils.GetKeyValidatorConfig(ctx, r.client, authenticationCR, r.clusterDomain)
 	if err != nil {
@@ -354,6 +376,36 @@ func (r *ReconcileCompliance) Reconcile(ctx context.Context, request reconcile.R
 		return reconcile.Result{}, err
 	}
 
+	if err := validateCompliance(&instance.Spec); err != nil {
+		log.Error(err, "Invalid Compliance configuration")
+		r.status.SetDegraded("Invalid Compliance configuration", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	var reportExportCredentials *corev1.Secret
+	if e := instance.Spec.ReportExport; e != nil && e.S3 != nil && managementClusterConnection == nil {
+		reportExportCredentials, err = getReportExportCredentials(ctx, r.client, e.S3.CredentialsSecretName)
+		if err != nil {
+			log.Error(err, "Invalid Compliance configuration")
+			r.status.SetDegraded("Invalid Compliance configuration", err.Error())
+			return reconcile.Result{}, err
+		}
+	}
+
+	removedReports, removedReportTypes, err := r.removedReports(ctx, &instance.Spec)
+	if err != nil {
+		log.Error(err, "Failed to list GlobalReports and GlobalReportTypes")
+		r.status.SetDegraded("Failed to list GlobalReports and GlobalReportTypes", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	networkPolicyState, err := utils.GetNetworkPolicyState(ctx, r.client, r.tierWatchReady, network, instance.Spec.ComponentNetworkPolicy)
+	if err != nil {
+		reqLogger.Error(err, "Error querying allow-tigera tier")
+		r.status.SetDegraded("Error querying allow-tigera tier", err.Error())
+		return reconcile.Result{}, err
+	}
+
 	reqLogger.V(3).Info("rendering components")
 	hasNoLicense := !utils.IsFeatureActive(license, common.ComplianceFeature)
 	openshift := r.provider == operatorv1.ProviderOpenShift
@@ -370,7 +422,14 @@ func (r *ReconcileCompliance) Reconcile(ctx context.Context, request reconcile.R
 		KeyValidatorConfig:          keyValidatorConfig,
 		ClusterDomain:               r.clusterDomain,
 		HasNoLicense:                hasNoLicense,
+		Compliance:                  instance,
+		RemovedReports:              removedReports,
+		RemovedReportTypes:          removedReportTypes,
+		ReportExportCredentials:     reportExportCredentials,
+		LogLevel:                    loglevel.Resolve(network, instance.Spec.LogLevel),
+		ServerAutoscaling:           instance.Spec.ServerAutoscaling,
 		UsePSP:                      r.usePSP,
+		NetworkPolicyState:          networkPolicyState,
 	}
 	// Render the desired objects from the CRD and create or update them.
 	comp, err := render.Compliance(complianceCfg)
@@ -423,3 +482,166 @@ func (r *ReconcileCompliance) Reconcile(ctx context.Context, request reconcile.R
 	}
 	return reconcile.Result{}, nil
 }
+
+// removedReports returns the names of the GlobalReports and GlobalReportTypes that were created for the Compliance CR
+// but have since been removed from it.
+func (r *ReconcileCompliance) removedReports(ctx context.Context, spec *operatorv1.ComplianceSpec) ([]string, []string, error) {
+	current := map[string]bool{}
+	for _, report := range spec.Reports {
+		current[report.Name] = true
+	}
+	reports := &v3.GlobalReportList{}
+	if err := r.client.List(ctx, reports, client.HasLabels{render.ComplianceReportLabel}); err != nil {
+		return nil, nil, err
+	}
+	var removedReports []string
+	for _, report := range reports.Items {
+		if !current[report.Name] {
+			removedReports = append(removedReports, report.Name)
+		}
+	}
+
+	current = map[string]bool{}
+	for _, rt := range spec.ReportTypes {
+		current[rt.Name] = true
+	}
+	reportTypes := &v3.GlobalReportTypeList{}
+	if err := r.client.List(ctx, reportTypes, client.HasLabels{render.ComplianceReportLabel}); err != nil {
+		return nil, nil, err
+	}
+	var removedReportTypes []string
+	for _, rt := range reportTypes.Items {
+		if !current[rt.Name] {
+			removedReportTypes = append(removedReportTypes, rt.Name)
+		}
+	}
+	return removedReports, removedReportTypes, nil
+}
+
+// validateCompliance validates the reports, report types and component settings of the Compliance CR.
+func validateCompliance(spec *operatorv1.ComplianceSpec) error {
+	reportTypes := map[string]bool{}
+	for _, name := range render.ComplianceBuiltInReportTypeNames() {
+		reportTypes[name] = true
+	}
+	for _, rt := range spec.ReportTypes {
+		if reportTypes[rt.Name] {
+			return fmt.Errorf("report type %s is defined more than once or has the name of a built-in report type", rt.Name)
+		}
+		reportTypes[rt.Name] = true
+
+		if !strings.HasSuffix(rt.UISummaryTemplate.Name, ".json") {
+			return fmt.Errorf("the UI summary template of report type %s must have a name ending in .json", rt.Name)
+		}
+		if err := parseReportTemplate(rt.UISummaryTemplate); err != nil {
+			return fmt.Errorf("the UI summary template of report type %s is invalid: %w", rt.Name, err)
+		}
+		templates := map[string]bool{}
+		for _, t := range rt.DownloadTemplates {
+			if !strings.HasSuffix(t.Name, ".csv") && !strings.HasSuffix(t.Name, ".json") {
+				return fmt.Errorf("download template %s of report type %s must have a name ending in .csv or .json", t.Name, rt.Name)
+			}
+			if templates[t.Name] {
+				return fmt.Errorf("download template %s of report type %s is defined more than once", t.Name, rt.Name)
+			}
+			templates[t.Name] = true
+			if err := parseReportTemplate(t); err != nil {
+				return fmt.Errorf("download template %s of report type %s is invalid: %w", t.Name, rt.Name, err)
+			}
+		}
+	}
+
+	reports := map[string]bool{}
+	for _, report := range spec.Reports {
+		if reports[report.Name] {
+			return fmt.Errorf("report %s is defined more than once", report.Name)
+		}
+		reports[report.Name] = true
+		if !reportTypes[report.ReportType] {
+			return fmt.Errorf("report %s has an unknown report type: %s", report.Name, report.ReportType)
+		}
+		if report.Schedule != "" && len(strings.Fields(report.Schedule)) != 5 {
+			return fmt.Errorf("the schedule %q of report %s must have five fields in cron format", report.Schedule, report.Name)
+		}
+	}
+
+	if spec.Snapshotter != nil && spec.Snapshotter.Interval != nil {
+		if i := spec.Snapshotter.Interval.Duration; i < time.Hour || i > 24*time.Hour {
+			return fmt.Errorf("the snapshot interval %s must be between 1h and 24h", i)
+		}
+	}
+
+	if e := spec.ReportExport; e != nil {
+		if (e.S3 == nil) == (e.PersistentVolumeClaim == nil) {
+			return fmt.Errorf("the report export must have exactly one of s3 and persistentVolumeClaim")
+		}
+		if e.Schedule != "" && len(strings.Fields(e.Schedule)) != 5 {
+			return fmt.Errorf("the schedule %q of the report export must have five fields in cron format", e.Schedule)
+		}
+		if s3 := e.S3; s3 != nil {
+			if s3.Bucket == "" || s3.CredentialsSecretName == "" {
+				return fmt.Errorf("the s3 report export must have a bucket and credentialsSecretName")
+			}
+			if s3.Endpoint != "" {
+				u, err := url.Parse(s3.Endpoint)
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("the s3 report export has an invalid endpoint %q, it must be an HTTP or HTTPS URL", s3.Endpoint)
+				}
+			}
+		}
+		if pvc := e.PersistentVolumeClaim; pvc != nil {
+			if pvc.ClaimName == "" {
+				return fmt.Errorf("the persistentVolumeClaim report export must have a claimName")
+			}
+			for _, elem := range strings.Split(pvc.Path, "/") {
+				if elem == ".." {
+					return fmt.Errorf("the path %q of the persistentVolumeClaim report export must not leave the volume", pvc.Path)
+				}
+			}
+		}
+	}
+	if err := autoscaling.Validate(spec.ServerAutoscaling); err != nil {
+		return fmt.Errorf("the compliance server autoscaling is invalid: %w", err)
+	}
+	return nil
+}
+
+// getReportExportCredentials returns the secret, in the operator namespace, with the credentials of the bucket that
+// reports are exported to.
+func getReportExportCredentials(ctx context.Context, cli client.Client, name string) (*corev1.Secret, error) {
+	s, err := utils.GetSecret(ctx, cli, name, common.OperatorNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("the report export credentials secret %s was not found in %s", name, common.OperatorNamespace())
+	}
+	for _, key := range []string{"access-key-id", "secret-access-key"} {
+		if len(s.Data[key]) == 0 {
+			return nil, fmt.Errorf("the report export credentials secret %s does not have a value for %q", name, key)
+		}
+	}
+	return s, nil
+}
+
+var undefinedTemplateFunction = regexp.MustCompile(`function "([^"]+)" not defined`)
+
+// parseReportTemplate checks the syntax of a report template. The functions that the compliance reporter provides to
+// templates are not known here, so any function the template calls is accepted.
+func parseReportTemplate(t operatorv1.ComplianceReportTemplate) error {
+	if strings.TrimSpace(t.Template) == "" {
+		return fmt.Errorf("the template is empty")
+	}
+	funcs := template.FuncMap{}
+	for {
+		_, err := template.New(t.Name).Funcs(funcs).Parse(t.Template)
+		if err == nil {
+			return nil
+		}
+		m := undefinedTemplateFunction.FindStringSubmatch(err.Error())
+		if m == nil || funcs[m[1]] != nil {
+			return err
+		}
+		funcs[m[1]] = func(...interface{}) interface{} { return nil }
+	}
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/tigera/operator/pkg/render"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -66,6 +67,7 @@ var _ = Describe("Compliance controller tests", func() {
 		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
 		Expect(appsv1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
 		Expect(rbacv1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		Expect(batchv1beta.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
 		Expect(operatorv1.SchemeBuilder.AddToScheme(scheme)).NotTo(HaveOccurred())
 
 		// Create a client that will have a crud interface of k8s objects.
@@ -80,6 +82,8 @@ var _ = Describe("Compliance controller tests", func() {
 		mockStatus.On("AddStatefulSets", mock.Anything).Return()
 		mockStatus.On("RemoveCertificateSigningRequests", mock.Anything).Return()
 		mockStatus.On("AddCronJobs", mock.Anything)
+		mockStatus.On("SetPodSecurityRequirements", mock.Anything)
+		mockStatus.On("RemoveCronJobs", mock.Anything)
 		mockStatus.On("IsAvailable").Return(true)
 		mockStatus.On("OnCRFound").Return()
 		mockStatus.On("ClearDegraded")
@@ -330,6 +334,158 @@ var _ = Describe("Compliance controller tests", func() {
 		Expect(dpl.Spec.Template.ObjectMeta.Name).To(Equal(render.ComplianceControllerName))
 	})
 
+	Context("reports", func() {
+		setSpec := func(spec operatorv1.ComplianceSpec) {
+			cr := &operatorv1.Compliance{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, cr)).To(Succeed())
+			cr.Spec = spec
+			Expect(c.Update(ctx, cr)).To(Succeed())
+		}
+
+		customType := operatorv1.ComplianceReportType{
+			Name:              "egress",
+			UISummaryTemplate: operatorv1.ComplianceReportTemplate{Name: "ui-summary.json", Template: `{"heading":"Egress","count":{{ len .Endpoints }}}`},
+			DownloadTemplates: []operatorv1.ComplianceReportTemplate{
+				{Name: "endpoints.csv", Template: `{{ $c := csv }}{{- $c := $c.AddColumn "endpoint" "{{ .Endpoint }}" }}{{- $c.Render .Endpoints }}`},
+			},
+			IncludeEndpointData: true,
+		}
+
+		It("should create the reports and custom report types, and delete removed ones", func() {
+			setSpec(operatorv1.ComplianceSpec{
+				ReportTypes: []operatorv1.ComplianceReportType{customType},
+				Reports: []operatorv1.ComplianceReport{
+					{Name: "daily-inventory", ReportType: "inventory", Schedule: "0 0 * * *", Namespaces: []string{"prod"}},
+					{Name: "hourly-egress", ReportType: "egress", Schedule: "0 * * * *", EndpointSelector: "app == 'web'"},
+				},
+			})
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			rt := &v3.GlobalReportType{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "egress"}, rt)).To(Succeed())
+			Expect(rt.Labels).To(HaveKey(render.ComplianceReportLabel))
+			Expect(rt.Spec.DownloadTemplates).To(HaveLen(1))
+			inventory := &v3.GlobalReport{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "daily-inventory"}, inventory)).To(Succeed())
+			Expect(inventory.Spec.ReportType).To(Equal("inventory"))
+			Expect(inventory.Spec.Endpoints.Namespaces.Names).To(Equal([]string{"prod"}))
+			egress := &v3.GlobalReport{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "hourly-egress"}, egress)).To(Succeed())
+			Expect(egress.Spec.Endpoints.Selector).To(Equal("app == 'web'"))
+
+			By("deleting the removed report and report type")
+			setSpec(operatorv1.ComplianceSpec{
+				Reports: []operatorv1.ComplianceReport{{Name: "daily-inventory", ReportType: "inventory", Schedule: "0 0 * * *"}},
+			})
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "daily-inventory"}, inventory)).To(Succeed())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "hourly-egress"}, egress)).NotTo(Succeed())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "egress"}, rt)).NotTo(Succeed())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "inventory"}, rt)).To(Succeed())
+		})
+
+		It("should reject invalid reports and report types", func() {
+			mockStatus.On("SetDegraded", "Invalid Compliance configuration", mock.Anything).Return()
+
+			setSpec(operatorv1.ComplianceSpec{Reports: []operatorv1.ComplianceReport{{Name: "r", ReportType: "unknown"}}})
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError("report r has an unknown report type: unknown"))
+
+			builtIn := customType
+			builtIn.Name = "inventory"
+			setSpec(operatorv1.ComplianceSpec{ReportTypes: []operatorv1.ComplianceReportType{builtIn}})
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("has the name of a built-in report type")))
+
+			badTemplate := customType
+			badTemplate.DownloadTemplates = []operatorv1.ComplianceReportTemplate{{Name: "endpoints.csv", Template: "{{ range .Endpoints }}"}}
+			setSpec(operatorv1.ComplianceSpec{ReportTypes: []operatorv1.ComplianceReportType{badTemplate}})
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("download template endpoints.csv of report type egress is invalid")))
+
+			badName := customType
+			badName.DownloadTemplates = []operatorv1.ComplianceReportTemplate{{Name: "endpoints.txt", Template: "{{ .Endpoints }}"}}
+			setSpec(operatorv1.ComplianceSpec{ReportTypes: []operatorv1.ComplianceReportType{badName}})
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("must have a name ending in .csv or .json")))
+
+			setSpec(operatorv1.ComplianceSpec{Snapshotter: &operatorv1.ComplianceSnapshotter{Interval: &metav1.Duration{Duration: time.Minute}}})
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError("the snapshot interval 1m0s must be between 1h and 24h"))
+		})
+
+		It("should export the reports to S3 with the copied credentials, and stop exporting once removed", func() {
+			mockStatus.On("SetDegraded", "Invalid Compliance configuration", mock.Anything).Return()
+			export := &operatorv1.ComplianceReportExport{
+				Schedule: "30 * * * *",
+				S3: &operatorv1.ComplianceReportExportS3{
+					Endpoint:              "http://minio.minio.svc:9000",
+					Bucket:                "audit",
+					Path:                  "cluster-a",
+					CredentialsSecretName: "minio-credentials",
+					ForcePathStyle:        true,
+				},
+			}
+			setSpec(operatorv1.ComplianceSpec{ReportExport: export})
+
+			By("waiting for the credentials")
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("minio-credentials was not found")))
+
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "minio-credentials", Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{"access-key-id": []byte("minio"), "secret-access-key": []byte("minio123")},
+			})).To(Succeed())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			creds := &corev1.Secret{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: render.ComplianceReportExportCredentialsSecret, Namespace: render.ComplianceNamespace}, creds)).To(Succeed())
+			Expect(creds.Data).To(HaveKeyWithValue("secret-access-key", []byte("minio123")))
+			cj := &batchv1beta.CronJob{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: render.ComplianceReportExporterName, Namespace: render.ComplianceNamespace}, cj)).To(Succeed())
+			Expect(cj.Spec.Schedule).To(Equal("30 * * * *"))
+			container := cj.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+			Expect(container.Image).To(Equal(fmt.Sprintf("some.registry.org/%s:%s", components.ComponentOperatorInit.Image, components.ComponentOperatorInit.Version)))
+			Expect(container.Args).To(ContainElements(
+				"--export-compliance-reports",
+				"--export-s3-endpoint=http://minio.minio.svc:9000",
+				"--export-s3-bucket=audit",
+				"--export-s3-path=cluster-a",
+				"--export-s3-force-path-style",
+			))
+
+			By("stopping the export once removed")
+			setSpec(operatorv1.ComplianceSpec{})
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, client.ObjectKey{Name: render.ComplianceReportExporterName, Namespace: render.ComplianceNamespace}, cj)).NotTo(Succeed())
+			Expect(c.Get(ctx, client.ObjectKey{Name: render.ComplianceReportExportCredentialsSecret, Namespace: render.ComplianceNamespace}, creds)).NotTo(Succeed())
+		})
+
+		It("should reject an invalid report export", func() {
+			mockStatus.On("SetDegraded", "Invalid Compliance configuration", mock.Anything).Return()
+
+			setSpec(operatorv1.ComplianceSpec{ReportExport: &operatorv1.ComplianceReportExport{}})
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError("the report export must have exactly one of s3 and persistentVolumeClaim"))
+
+			setSpec(operatorv1.ComplianceSpec{ReportExport: &operatorv1.ComplianceReportExport{
+				PersistentVolumeClaim: &operatorv1.ComplianceReportExportPVC{ClaimName: "reports", Path: "../etc"},
+			}})
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("must not leave the volume")))
+
+			setSpec(operatorv1.ComplianceSpec{ReportExport: &operatorv1.ComplianceReportExport{
+				S3: &operatorv1.ComplianceReportExportS3{Endpoint: "minio:9000", Bucket: "audit", CredentialsSecretName: "creds"},
+			}})
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("invalid endpoint")))
+		})
+	})
+
 	Context("image reconciliation", func() {
 		It("should use builtin images", func() {
 			_, err := r.Reconcile(ctx, reconcile.Request{})
@@ -145,8 +145,8 @@ func newReconciler(mgr manager.Manager, opts options.AddOptions) (*ReconcileInst
 		return nil, err
 	}
 
-	// Create the SharedIndexInformer used by the typhaAutoscaler and
-	// calicoWindowsUpgrader.
+	// Create the SharedIndexInformer used by the typhaAutoscaler,
+	// calicoWindowsUpgrader and nodeHealthMonitor.
 	nodeListWatch := cache.NewListWatchFromClient(cs.CoreV1().RESTClient(), "nodes", "", fields.Everything())
 	nodeIndexInformer := cache.NewSharedIndexInformer(nodeListWatch, &corev1.Node{}, 0, cache.Indexers{})
 	go nodeIndexInformer.Run(opts.ShutdownContext.Done())
@@ -158,6 +158,9 @@ func newReconciler(mgr manager.Manager, opts options.AddOptions) (*ReconcileInst
 	// Create a Calico Windows upgrader.
 	calicoWindowsUpgrader := windows.NewCalicoWindowsUpgrader(cs, mgr.GetClient(), nodeIndexInformer, statusManager)
 
+	// Create a monitor of the health that calico/node reports on each node.
+	nodeHealth := newNodeHealthMonitor(mgr.GetClient(), nodeIndexInformer, statusManager)
+
 	r := &ReconcileInstallation{
 		config:                mgr.GetConfig(),
 		client:                mgr.GetClient(),
@@ -173,10 +176,12 @@ func newReconciler(mgr manager.Manager, opts options.AddOptions) (*ReconcileInst
 		clusterDomain:         opts.ClusterDomain,
 		manageCRDs:            opts.ManageCRDs,
 		usePSP:                opts.UsePSP,
+		tierWatchReady:        &utils.ReadyFlag{},
 	}
 	r.status.Run(opts.ShutdownContext)
 	r.typhaAutoscaler.start(opts.ShutdownContext)
 	r.calicoWindowsUpgrader.Start(opts.ShutdownContext)
+	nodeHealth.start(opts.ShutdownContext)
 	return r, nil
 }
 
@@ -282,6 +287,12 @@ func add(mgr manager.Manager, r *ReconcileInstallation) error {
 	}
 
 	if r.enterpriseCRDsExist {
+		cs, err := kubernetes.NewForConfig(mgr.GetConfig())
+		if err != nil {
+			return fmt.Errorf("tigera-installation-controller failed to establish a connection to k8s: %w", err)
+		}
+		go utils.WaitToAddTierWatch(c, cs, log, r.tierWatchReady)
+
 		// Watch for changes to primary resource ManagementCluster
 		err = c.Watch(&source.Kind{Type: &operator.ManagementCluster{}}, &handler.EnqueueRequestForObject{})
 		if err != nil {
@@ -362,6 +373,7 @@ type ReconcileInstallation struct {
 	clusterDomain         string
 	manageCRDs            bool
 	usePSP                bool
+	tierWatchReady        *utils.ReadyFlag
 }
 
 // updateInstallationWithDefaults returns the default installation instance with defaults populated.
@@ -818,6 +830,9 @@ func (r *ReconcileInstallation) Reconcile(ctx context.Context, request reconcile
 		setInstallationFinalizer(instance)
 	}
 
+	// Start or end the temporary debug logging. It is written back to the API with the defaults below.
+	temporaryDebugRemaining := updateTemporaryDebug(instance, time.Now())
+
 	// Write the discovered configuration back to the API. This is essentially a poor-man's defaulting, and
 	// ensures that we don't surprise anyone by changing defaults in a future version of the operator.
 	// Note that we only write the 'base' installation back. We don't want to write the changes from 'overlay', as those should only
@@ -1238,6 +1253,12 @@ func (r *ReconcileInstallation) Reconcile(ctx context.Context, request reconcile
 			TrustedBundle: typhaNodeTLS.TrustedBundle,
 		}))
 
+	networkPolicyState, err := utils.GetNetworkPolicyState(ctx, r.client, r.tierWatchReady, &instance.Spec, nil)
+	if err != nil {
+		r.SetDegraded("Error querying allow-tigera tier", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+
 	// Build a configuration for rendering calico/kube-controllers.
 	kubeControllersCfg := kubecontrollers.KubeControllersConfiguration{
 		K8sServiceEp:                k8sapi.Endpoint,
@@ -1249,6 +1270,7 @@ func (r *ReconcileInstallation) Reconcile(ctx context.Context, request reconcile
 		ManagerInternalSecret:       managerInternalTLSSecret,
 		Terminating:                 terminating,
 		UsePSP:                      r.usePSP,
+		NetworkPolicyState:          networkPolicyState,
 	}
 	components = append(components, kubecontrollers.NewCalicoKubeControllers(&kubeControllersCfg))
 
@@ -1275,7 +1297,7 @@ func (r *ReconcileInstallation) Reconcile(ctx context.Context, request reconcile
 	}
 
 	// Create a component handler to create or update the rendered components.
-	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance)
+	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance, &instance.Spec)
 	for _, component := range components {
 		if err := handler.CreateOrUpdateOrDelete(ctx, component, nil); err != nil {
 			r.SetDegraded("Error creating / updating resource", err, reqLogger)
@@ -1393,6 +1415,10 @@ func (r *ReconcileInstallation) Reconcile(ctx context.Context, request reconcile
 	if terminating {
 		return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
 	}
+	// Requeue when the temporary debug logging ends, so that the components return to their configured log level.
+	if temporaryDebugRemaining > 0 && temporaryDebugRemaining < 5*time.Minute {
+		return reconcile.Result{RequeueAfter: temporaryDebugRemaining}, nil
+	}
 	return reconcile.Result{RequeueAfter: 5 * time.Minute}, nil
 }
 
@@ -1590,7 +1616,7 @@ func (r *ReconcileInstallation) updateCRDs(ctx context.Context, variant operator
 	crdComponent := render.NewPassthrough(crds.ToRuntimeObjects(crds.GetCRDs(variant)...)...)
 	// Specify nil for the CR so no ownership is put on the CRDs. We do this so removing the
 	// Installation CR will not remove the CRDs.
-	handler := utils.NewComponentHandler(log, r.client, r.scheme, nil)
+	handler := utils.NewComponentHandler(log, r.client, r.scheme, nil, nil)
 	if err := handler.CreateOrUpdateOrDelete(ctx, crdComponent, nil); err != nil {
 		r.SetDegraded("Error creating / updating CRD resource", err, log)
 		return err
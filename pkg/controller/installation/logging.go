@@ -0,0 +1,42 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operator "github.com/tigera/operator/api/v1"
+)
+
+// updateTemporaryDebug sets when the temporary debug logging of the Installation ends if it has just started, and
+// removes it once it has ended. It returns how long the temporary debug logging has left, or 0 if there is none.
+func updateTemporaryDebug(instance *operator.Installation, now time.Time) time.Duration {
+	l := instance.Spec.Logging
+	if l == nil || l.TemporaryDebug == nil {
+		return 0
+	}
+	if l.TemporaryDebug.Until == nil {
+		until := metav1.NewTime(now.Add(l.TemporaryDebug.Duration.Duration))
+		l.TemporaryDebug.Until = &until
+	}
+	remaining := l.TemporaryDebug.Until.Sub(now)
+	if remaining <= 0 {
+		l.TemporaryDebug = nil
+		return 0
+	}
+	return remaining
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operator "github.com/tigera/operator/api/v1"
+)
+
+var _ = Describe("temporary debug logging", func() {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	It("does nothing without temporary debug logging", func() {
+		instance := &operator.Installation{}
+		Expect(updateTemporaryDebug(instance, now)).To(BeZero())
+		Expect(instance.Spec.Logging).To(BeNil())
+	})
+
+	It("sets when the temporary debug logging ends when it starts", func() {
+		instance := &operator.Installation{Spec: operator.InstallationSpec{Logging: &operator.Logging{
+			TemporaryDebug: &operator.TemporaryDebugLogging{Duration: metav1.Duration{Duration: time.Hour}},
+		}}}
+		Expect(updateTemporaryDebug(instance, now)).To(Equal(time.Hour))
+		Expect(instance.Spec.Logging.TemporaryDebug.Until.Time).To(Equal(now.Add(time.Hour)))
+
+		// The end does not move on later reconciles.
+		Expect(updateTemporaryDebug(instance, now.Add(20*time.Minute))).To(Equal(40 * time.Minute))
+		Expect(instance.Spec.Logging.TemporaryDebug.Until.Time).To(Equal(now.Add(time.Hour)))
+	})
+
+	It("removes the temporary debug logging once it has ended", func() {
+		level := operator.LogLevelWarn
+		until := metav1.NewTime(now.Add(-time.Second))
+		instance := &operator.Installation{Spec: operator.InstallationSpec{Logging: &operator.Logging{
+			Level:          &level,
+			TemporaryDebug: &operator.TemporaryDebugLogging{Duration: metav1.Duration{Duration: time.Hour}, Until: &until},
+		}}}
+		Expect(updateTemporaryDebug(instance, now)).To(BeZero())
+		Expect(instance.Spec.Logging.TemporaryDebug).To(BeNil())
+		Expect(*instance.Spec.Logging.Level).To(Equal(operator.LogLevelWarn))
+	})
+})
@@ -0,0 +1,236 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	operator "github.com/tigera/operator/api/v1"
+	crdv1 "github.com/tigera/operator/pkg/apis/crd.projectcalico.org/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/status"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/render"
+)
+
+var nodeHealthLog = logf.Log.WithName("node_health")
+
+const (
+	defaultNodeHealthSyncPeriod = 30 * time.Second
+
+	// nodeStatusUpdatePeriodSeconds is how often calico/node updates the CalicoNodeStatus objects
+	// created by the operator. Status that is older than a few periods is ignored.
+	nodeStatusUpdatePeriodSeconds uint32 = 60
+
+	// nodeStatusPrefix prefixes the names of the CalicoNodeStatus objects created by the operator.
+	nodeStatusPrefix = "tigera-operator-"
+	// nodeStatusLabel marks the CalicoNodeStatus objects created by the operator.
+	nodeStatusLabel = "operator.tigera.io/node-health"
+)
+
+// nodeHealthMonitor periodically collects the health that calico/node reports on each Linux node and passes
+// a summary to the status manager. BGP health is read from a CalicoNodeStatus object per node, which the
+// monitor creates when BGP is enabled, and the readiness of Felix from the calico-node pods.
+type nodeHealthMonitor struct {
+	client            client.Client
+	syncPeriod        time.Duration
+	statusManager     status.StatusManager
+	nodeIndexInformer cache.SharedIndexInformer
+	now               func() time.Time
+}
+
+type nodeHealthMonitorOption func(*nodeHealthMonitor)
+
+// nodeHealthMonitorPeriod is an option that sets a custom sync period for the node health monitor.
+func nodeHealthMonitorPeriod(syncPeriod time.Duration) nodeHealthMonitorOption {
+	return func(m *nodeHealthMonitor) {
+		m.syncPeriod = syncPeriod
+	}
+}
+
+// newNodeHealthMonitor creates a new node health monitor. The default sync period is 30 seconds.
+func newNodeHealthMonitor(c client.Client, nodeIndexInformer cache.SharedIndexInformer, statusManager status.StatusManager, options ...nodeHealthMonitorOption) *nodeHealthMonitor {
+	m := &nodeHealthMonitor{
+		client:            c,
+		syncPeriod:        defaultNodeHealthSyncPeriod,
+		statusManager:     statusManager,
+		nodeIndexInformer: nodeIndexInformer,
+		now:               time.Now,
+	}
+	for _, option := range options {
+		option(m)
+	}
+	return m
+}
+
+// start starts the node health monitor, updating the health of the nodes every sync period.
+func (m *nodeHealthMonitor) start(ctx context.Context) {
+	go func() {
+		for !m.nodeIndexInformer.HasSynced() {
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		ticker := time.NewTicker(m.syncPeriod)
+		defer ticker.Stop()
+		nodeHealthLog.Info("Starting node health monitor", "syncPeriod", m.syncPeriod)
+		for {
+			select {
+			case <-ticker.C:
+				if err := m.update(ctx); err != nil {
+					nodeHealthLog.Error(err, "Failed to update the node health")
+				}
+			case <-ctx.Done():
+				nodeHealthLog.Info("Stopping node health monitor")
+				return
+			}
+		}
+	}()
+}
+
+// update collects the health of the nodes and passes it to the status manager.
+func (m *nodeHealthMonitor) update(ctx context.Context) error {
+	_, install, err := utils.GetInstallation(ctx, m.client)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			m.statusManager.SetNodeHealth(nil)
+			return nil
+		}
+		return err
+	}
+
+	nodes := []string{}
+	for _, obj := range m.nodeIndexInformer.GetIndexer().List() {
+		node, ok := obj.(*corev1.Node)
+		if !ok || node.Labels[corev1.LabelOSStable] == "windows" {
+			continue
+		}
+		nodes = append(nodes, node.Name)
+	}
+	sort.Strings(nodes)
+
+	bgp := install.CalicoNetwork != nil && install.CalicoNetwork.BGP != nil && *install.CalicoNetwork.BGP == operator.BGPEnabled
+	nodeStatuses, err := m.reconcileNodeStatuses(ctx, nodes, bgp)
+	if err != nil {
+		return err
+	}
+
+	pods := corev1.PodList{}
+	if err := m.client.List(ctx, &pods, client.InNamespace(common.CalicoNamespace), client.MatchingLabels{"k8s-app": render.CalicoNodeObjectName}); err != nil {
+		return fmt.Errorf("failed to list the calico-node pods: %w", err)
+	}
+	notReady := map[string]bool{}
+	for _, pod := range pods.Items {
+		for _, c := range pod.Status.ContainerStatuses {
+			if c.Name == render.CalicoNodeObjectName && !c.Ready {
+				notReady[pod.Spec.NodeName] = true
+			}
+		}
+	}
+
+	health := &status.NodeHealth{}
+	for _, node := range nodes {
+		if notReady[node] {
+			health.NotReady = append(health.NotReady, node)
+		}
+
+		ns, ok := nodeStatuses[node]
+		if !ok || !m.isRecent(ns) {
+			// calico/node has not reported the status of the node, e.g. because it is starting or
+			// it does not support CalicoNodeStatus.
+			continue
+		}
+		agent := ns.Status.Agent
+		if isNotReady(agent.BIRDV4.State) || isNotReady(agent.BIRDV6.State) {
+			health.BGPDaemonNotReady = append(health.BGPDaemonNotReady, node)
+		} else if ns.Status.BGP.NumberNotEstablishedV4+ns.Status.BGP.NumberNotEstablishedV6 > 0 {
+			health.BGPPeersDown = append(health.BGPPeersDown, node)
+		}
+	}
+
+	m.statusManager.SetNodeHealth(health)
+	return nil
+}
+
+// reconcileNodeStatuses makes sure there is a CalicoNodeStatus for each of the nodes when BGP is enabled,
+// and none otherwise. It returns the CalicoNodeStatus objects by node.
+func (m *nodeHealthMonitor) reconcileNodeStatuses(ctx context.Context, nodes []string, bgp bool) (map[string]*crdv1.CalicoNodeStatus, error) {
+	list := crdv1.CalicoNodeStatusList{}
+	if err := m.client.List(ctx, &list, client.HasLabels{nodeStatusLabel}); err != nil {
+		return nil, fmt.Errorf("failed to list CalicoNodeStatus: %w", err)
+	}
+
+	wanted := map[string]bool{}
+	if bgp {
+		for _, node := range nodes {
+			wanted[node] = true
+		}
+	}
+
+	statuses := map[string]*crdv1.CalicoNodeStatus{}
+	for i := range list.Items {
+		ns := &list.Items[i]
+		if !wanted[ns.Spec.Node] {
+			if err := m.client.Delete(ctx, ns); err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to delete CalicoNodeStatus %s: %w", ns.Name, err)
+			}
+			continue
+		}
+		statuses[ns.Spec.Node] = ns
+	}
+
+	for _, node := range nodes {
+		if !wanted[node] || statuses[node] != nil {
+			continue
+		}
+		period := nodeStatusUpdatePeriodSeconds
+		ns := &crdv1.CalicoNodeStatus{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   nodeStatusPrefix + node,
+				Labels: map[string]string{nodeStatusLabel: "true"},
+			},
+			Spec: crdv1.CalicoNodeStatusSpec{
+				Node:                node,
+				Classes:             []crdv1.NodeStatusClassType{crdv1.NodeStatusClassTypeAgent, crdv1.NodeStatusClassTypeBGP},
+				UpdatePeriodSeconds: &period,
+			},
+		}
+		if err := m.client.Create(ctx, ns); err != nil && !apierrors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("failed to create CalicoNodeStatus %s: %w", ns.Name, err)
+		}
+	}
+	return statuses, nil
+}
+
+// isRecent returns whether calico/node has updated the status within the last few update periods.
+func (m *nodeHealthMonitor) isRecent(ns *crdv1.CalicoNodeStatus) bool {
+	if ns.Status.LastUpdated.IsZero() {
+		return false
+	}
+	return m.now().Sub(ns.Status.LastUpdated.Time) < 3*time.Duration(nodeStatusUpdatePeriodSeconds)*time.Second
+}
+
+func isNotReady(state crdv1.BGPDaemonState) bool {
+	return state != "" && state != crdv1.BGPDaemonStateReady
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	. "github.com/tigera/operator/test"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	operator "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/apis"
+	crdv1 "github.com/tigera/operator/pkg/apis/crd.projectcalico.org/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/status"
+)
+
+var _ = Describe("Node health monitor", func() {
+	var (
+		c                 client.Client
+		ctx               context.Context
+		cancel            context.CancelFunc
+		statusManager     *status.MockStatus
+		nodeIndexInformer cache.SharedIndexInformer
+		monitor           *nodeHealthMonitor
+		health            *status.NodeHealth
+		now               time.Time
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(corev1.SchemeBuilder.AddToScheme(scheme)).NotTo(HaveOccurred())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		cs := kfake.NewSimpleClientset()
+		CreateNode(cs, "node1", map[string]string{"kubernetes.io/os": "linux"}, nil)
+		CreateNode(cs, "node2", map[string]string{"kubernetes.io/os": "linux"}, nil)
+		CreateNode(cs, "win1", map[string]string{"kubernetes.io/os": "windows"}, nil)
+
+		ctx, cancel = context.WithCancel(context.Background())
+		nodeIndexInformer = cache.NewSharedIndexInformer(NewNodeListWatch(cs), &corev1.Node{}, 0, cache.Indexers{})
+		go nodeIndexInformer.Run(ctx.Done())
+		for !nodeIndexInformer.HasSynced() {
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		health = nil
+		statusManager = new(status.MockStatus)
+		statusManager.On("SetNodeHealth", mock.Anything).Run(func(args mock.Arguments) {
+			health = args.Get(0).(*status.NodeHealth)
+		})
+
+		now = time.Now()
+		monitor = newNodeHealthMonitor(c, nodeIndexInformer, statusManager)
+		monitor.now = func() time.Time { return now }
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	createInstallation := func(bgp operator.BGPOption) {
+		Expect(c.Create(ctx, &operator.Installation{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: operator.InstallationSpec{
+				CalicoNetwork: &operator.CalicoNetworkSpec{BGP: &bgp},
+			},
+		})).NotTo(HaveOccurred())
+	}
+
+	nodeStatuses := func() map[string]crdv1.CalicoNodeStatus {
+		list := crdv1.CalicoNodeStatusList{}
+		Expect(c.List(ctx, &list)).NotTo(HaveOccurred())
+		statuses := map[string]crdv1.CalicoNodeStatus{}
+		for _, ns := range list.Items {
+			statuses[ns.Spec.Node] = ns
+		}
+		return statuses
+	}
+
+	calicoNodePod := func(node string, ready bool) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "calico-node-" + node,
+				Namespace: common.CalicoNamespace,
+				Labels:    map[string]string{"k8s-app": "calico-node"},
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "calico-node", Ready: ready}},
+			},
+		}
+	}
+
+	It("reports unknown health without an Installation", func() {
+		health = &status.NodeHealth{}
+		Expect(monitor.update(ctx)).NotTo(HaveOccurred())
+		Expect(health).To(BeNil())
+	})
+
+	It("creates a CalicoNodeStatus for each Linux node when BGP is enabled", func() {
+		createInstallation(operator.BGPEnabled)
+		Expect(monitor.update(ctx)).NotTo(HaveOccurred())
+
+		statuses := nodeStatuses()
+		Expect(statuses).To(HaveLen(2))
+		Expect(statuses).To(HaveKey("node1"))
+		Expect(statuses).To(HaveKey("node2"))
+		ns := statuses["node1"]
+		Expect(ns.Name).To(Equal("tigera-operator-node1"))
+		Expect(ns.Spec.Classes).To(ConsistOf(crdv1.NodeStatusClassTypeAgent, crdv1.NodeStatusClassTypeBGP))
+		Expect(*ns.Spec.UpdatePeriodSeconds).To(BeEquivalentTo(60))
+		Expect(health).To(Equal(&status.NodeHealth{}))
+	})
+
+	It("removes the CalicoNodeStatus objects when BGP is disabled", func() {
+		createInstallation(operator.BGPEnabled)
+		Expect(monitor.update(ctx)).NotTo(HaveOccurred())
+		Expect(nodeStatuses()).To(HaveLen(2))
+
+		install := &operator.Installation{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "default"}, install)).NotTo(HaveOccurred())
+		disabled := operator.BGPDisabled
+		install.Spec.CalicoNetwork.BGP = &disabled
+		Expect(c.Update(ctx, install)).NotTo(HaveOccurred())
+
+		Expect(monitor.update(ctx)).NotTo(HaveOccurred())
+		Expect(nodeStatuses()).To(BeEmpty())
+	})
+
+	It("reports the nodes with BGP problems and calico-node not ready", func() {
+		createInstallation(operator.BGPEnabled)
+		Expect(monitor.update(ctx)).NotTo(HaveOccurred())
+
+		statuses := nodeStatuses()
+		ns := statuses["node1"]
+		ns.Status.LastUpdated = metav1.NewTime(now.Add(-time.Minute))
+		ns.Status.Agent.BIRDV4.State = crdv1.BGPDaemonStateReady
+		ns.Status.BGP.NumberEstablishedV4 = 1
+		ns.Status.BGP.NumberNotEstablishedV4 = 1
+		Expect(c.Update(ctx, &ns)).NotTo(HaveOccurred())
+
+		ns = statuses["node2"]
+		ns.Status.LastUpdated = metav1.NewTime(now.Add(-time.Minute))
+		ns.Status.Agent.BIRDV4.State = crdv1.BGPDaemonStateNotReady
+		ns.Status.BGP.NumberNotEstablishedV4 = 2
+		Expect(c.Update(ctx, &ns)).NotTo(HaveOccurred())
+
+		Expect(c.Create(ctx, calicoNodePod("node1", true))).NotTo(HaveOccurred())
+		Expect(c.Create(ctx, calicoNodePod("node2", false))).NotTo(HaveOccurred())
+
+		Expect(monitor.update(ctx)).NotTo(HaveOccurred())
+		Expect(health).To(Equal(&status.NodeHealth{
+			BGPPeersDown:      []string{"node1"},
+			BGPDaemonNotReady: []string{"node2"},
+			NotReady:          []string{"node2"},
+		}))
+
+		By("ignoring BGP status that calico/node has stopped updating")
+		now = now.Add(time.Hour)
+		Expect(monitor.update(ctx)).NotTo(HaveOccurred())
+		Expect(health).To(Equal(&status.NodeHealth{NotReady: []string{"node2"}}))
+	})
+})
@@ -19,6 +19,7 @@ import (
 	"net"
 	"path"
 	"strings"
+	"time"
 
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/render"
@@ -361,6 +362,72 @@ func validateCustomResource(instance *operatorv1.Installation) error {
 		}
 	}
 
+	if l := instance.Spec.Logging; l != nil && l.TemporaryDebug != nil && l.TemporaryDebug.Duration.Duration <= 0 {
+		return fmt.Errorf("Installation spec.Logging.TemporaryDebug.Duration should be greater than 0")
+	}
+
+	availability := map[operatorv1.ControlPlaneComponentName]bool{}
+	for _, a := range instance.Spec.ControlPlaneAvailability {
+		if availability[a.ComponentName] {
+			return fmt.Errorf("Installation spec.ControlPlaneAvailability has more than one entry for %s", a.ComponentName)
+		}
+		availability[a.ComponentName] = true
+		if pdb := a.PodDisruptionBudget; pdb != nil && (pdb.MinAvailable == nil) == (pdb.MaxUnavailable == nil) {
+			return fmt.Errorf("Installation spec.ControlPlaneAvailability.PodDisruptionBudget of %s must set exactly one of MinAvailable and MaxUnavailable", a.ComponentName)
+		}
+	}
+
+	if upgrade := instance.Spec.WindowsNodeUpgrade; upgrade != nil {
+		for _, mw := range upgrade.MaintenanceWindows {
+			if _, err := time.Parse("15:04", mw.Start); err != nil {
+				return fmt.Errorf("Installation spec.WindowsNodeUpgrade.MaintenanceWindows start %q is not in the HH:MM format", mw.Start)
+			}
+			if mw.Duration.Duration <= 0 {
+				return fmt.Errorf("Installation spec.WindowsNodeUpgrade.MaintenanceWindows duration must be greater than 0")
+			}
+		}
+		if upgrade.NodeTimeout != nil && upgrade.NodeTimeout.Duration <= 0 {
+			return fmt.Errorf("Installation spec.WindowsNodeUpgrade.NodeTimeout must be greater than 0")
+		}
+	}
+
+	if sg := instance.Spec.AWSSecurityGroups; sg != nil {
+		if len(sg.SecurityGroupIDs) == 0 && len(sg.SecurityGroupSelectors) == 0 {
+			return fmt.Errorf("Installation spec.AWSSecurityGroups must set SecurityGroupIDs or SecurityGroupSelectors")
+		}
+		for _, id := range sg.SecurityGroupIDs {
+			if id == "" {
+				return fmt.Errorf("Installation spec.AWSSecurityGroups.SecurityGroupIDs must not contain an empty id")
+			}
+		}
+		for _, selector := range sg.SecurityGroupSelectors {
+			if len(selector.Tags) == 0 {
+				return fmt.Errorf("Installation spec.AWSSecurityGroups.SecurityGroupSelectors must each set at least one tag")
+			}
+		}
+	}
+
+	if pc := instance.Spec.PacketCapture; pc != nil {
+		if instance.Spec.Variant != operatorv1.TigeraSecureEnterprise {
+			return fmt.Errorf("Installation spec.PacketCapture is only supported for spec.Variant=%s", operatorv1.TigeraSecureEnterprise)
+		}
+		if pc.MaxFileSizeBytes != nil && *pc.MaxFileSizeBytes <= 0 {
+			return fmt.Errorf("Installation spec.PacketCapture.MaxFileSizeBytes must be greater than 0")
+		}
+		if pc.MaxFilesPerCapture != nil && *pc.MaxFilesPerCapture <= 0 {
+			return fmt.Errorf("Installation spec.PacketCapture.MaxFilesPerCapture must be greater than 0")
+		}
+		if pc.RotationPeriod != nil && pc.RotationPeriod.Duration < time.Second {
+			return fmt.Errorf("Installation spec.PacketCapture.RotationPeriod must be at least 1s")
+		}
+		if pc.StorageDirectory != "" {
+			dir := path.Clean(pc.StorageDirectory)
+			if path.IsAbs(dir) || dir == "." || dir == ".." || strings.HasPrefix(dir, "../") {
+				return fmt.Errorf("Installation spec.PacketCapture.StorageDirectory %q must be a directory under /var/log/calico", pc.StorageDirectory)
+			}
+		}
+	}
+
 	return nil
 }
 
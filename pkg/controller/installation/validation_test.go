@@ -15,11 +15,14 @@
 package installation
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	operator "github.com/tigera/operator/api/v1"
 )
@@ -613,6 +616,82 @@ var _ = Describe("Installation validation tests", func() {
 			Expect(validateCustomResource(instance)).To(BeNil())
 		})
 
+		It("should return an error when the temporary debug logging has no duration", func() {
+			instance.Spec.Logging = &operator.Logging{TemporaryDebug: &operator.TemporaryDebugLogging{}}
+			Expect(validateCustomResource(instance)).ToNot(BeNil())
+		})
+
+		It("should return an error when the availability of a control plane component is configured twice", func() {
+			instance.Spec.ControlPlaneAvailability = []operator.ControlPlaneAvailability{
+				{ComponentName: operator.ControlPlaneComponentManager},
+				{ComponentName: operator.ControlPlaneComponentManager},
+			}
+			Expect(validateCustomResource(instance)).ToNot(BeNil())
+		})
+
+		It("should return an error when a control plane PodDisruptionBudget sets no budget", func() {
+			instance.Spec.ControlPlaneAvailability = []operator.ControlPlaneAvailability{
+				{ComponentName: operator.ControlPlaneComponentManager, PodDisruptionBudget: &operator.PodDisruptionBudgetSpec{}},
+			}
+			Expect(validateCustomResource(instance)).ToNot(BeNil())
+		})
+
+		It("should validate the Windows node upgrade", func() {
+			instance.Spec.WindowsNodeUpgrade = &operator.WindowsNodeUpgrade{
+				MaintenanceWindows: []operator.MaintenanceWindow{{Start: "22:30", Duration: metav1.Duration{Duration: time.Hour}}},
+				NodeTimeout:        &metav1.Duration{Duration: 30 * time.Minute},
+			}
+			Expect(validateCustomResource(instance)).To(BeNil())
+
+			instance.Spec.WindowsNodeUpgrade.MaintenanceWindows[0].Start = "10pm"
+			Expect(validateCustomResource(instance)).ToNot(BeNil())
+
+			instance.Spec.WindowsNodeUpgrade.MaintenanceWindows[0] = operator.MaintenanceWindow{Start: "22:30"}
+			Expect(validateCustomResource(instance)).ToNot(BeNil())
+
+			instance.Spec.WindowsNodeUpgrade.MaintenanceWindows = nil
+			instance.Spec.WindowsNodeUpgrade.NodeTimeout = &metav1.Duration{}
+			Expect(validateCustomResource(instance)).ToNot(BeNil())
+		})
+
+		It("should validate the AWS security groups", func() {
+			instance.Spec.AWSSecurityGroups = &operator.AWSSecurityGroups{SecurityGroupIDs: []string{"sg-1234"}}
+			Expect(validateCustomResource(instance)).To(BeNil())
+
+			instance.Spec.AWSSecurityGroups = &operator.AWSSecurityGroups{Region: "us-west-2"}
+			Expect(validateCustomResource(instance)).ToNot(BeNil())
+
+			instance.Spec.AWSSecurityGroups = &operator.AWSSecurityGroups{SecurityGroupIDs: []string{""}}
+			Expect(validateCustomResource(instance)).ToNot(BeNil())
+
+			instance.Spec.AWSSecurityGroups = &operator.AWSSecurityGroups{
+				SecurityGroupSelectors: []operator.AWSSecurityGroupSelector{{}},
+			}
+			Expect(validateCustomResource(instance)).ToNot(BeNil())
+		})
+
+		It("should validate the packet capture configuration", func() {
+			maxFiles := int32(4)
+			instance.Spec.PacketCapture = &operator.PacketCaptureSpec{MaxFilesPerCapture: &maxFiles}
+			Expect(validateCustomResource(instance)).ToNot(BeNil())
+
+			instance.Spec.Variant = operator.TigeraSecureEnterprise
+			Expect(validateCustomResource(instance)).To(BeNil())
+
+			var zero int64
+			instance.Spec.PacketCapture = &operator.PacketCaptureSpec{MaxFileSizeBytes: &zero}
+			Expect(validateCustomResource(instance)).ToNot(BeNil())
+
+			instance.Spec.PacketCapture = &operator.PacketCaptureSpec{StorageDirectory: "captures/../../etc"}
+			Expect(validateCustomResource(instance)).ToNot(BeNil())
+
+			instance.Spec.PacketCapture = &operator.PacketCaptureSpec{RotationPeriod: &metav1.Duration{Duration: time.Millisecond}}
+			Expect(validateCustomResource(instance)).ToNot(BeNil())
+
+			instance.Spec.PacketCapture = &operator.PacketCaptureSpec{StorageDirectory: "captures", RotationPeriod: &metav1.Duration{Duration: time.Minute}}
+			Expect(validateCustomResource(instance)).To(BeNil())
+		})
+
 		It("should return an error when an invalid ComponentName is present", func() {
 			instance.Spec.ComponentResources = append(instance.Spec.ComponentResources, operator.ComponentResource{
 				ComponentName: "invalid-componentName",
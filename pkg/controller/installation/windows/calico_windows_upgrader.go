@@ -66,6 +66,9 @@ type calicoWindowsUpgrader struct {
 	install           *operatorv1.InstallationSpec
 	isDegraded        bool
 	lock              sync.Mutex
+
+	// now returns the current time. It is used for the maintenance windows and the node upgrade timeout.
+	now func() time.Time
 }
 
 type calicoWindowsUpgraderOption func(*calicoWindowsUpgrader)
@@ -87,6 +90,7 @@ func NewCalicoWindowsUpgrader(cs kubernetes.Interface, c client.Client, indexInf
 		nodeIndexInformer: indexInformer,
 		syncPeriod:        10 * time.Second,
 		installChan:       make(chan *operatorv1.InstallationSpec, 100),
+		now:               time.Now,
 	}
 
 	for _, o := range options {
@@ -171,6 +175,87 @@ func (w *calicoWindowsUpgrader) isUpgradeFromCalicoToEnterprise(node *corev1.Nod
 	return false
 }
 
+// upgradeTimedOut returns whether the upgrade of the in-progress node has taken longer than the
+// configured node timeout. Nodes without a start time, e.g. because their upgrade was started by an
+// older operator, do not time out.
+func (w *calicoWindowsUpgrader) upgradeTimedOut(node *corev1.Node) bool {
+	upgrade := w.install.WindowsNodeUpgrade
+	if upgrade == nil || upgrade.NodeTimeout == nil {
+		return false
+	}
+	started, err := time.Parse(time.RFC3339, node.Annotations[common.CalicoWindowsUpgradeStartedAnnotation])
+	if err != nil {
+		return false
+	}
+	return w.now().Sub(started) > upgrade.NodeTimeout.Duration
+}
+
+// upgradesBlockedReason returns why new node upgrades cannot be started, or an empty string if
+// they can be.
+func (w *calicoWindowsUpgrader) upgradesBlockedReason() string {
+	upgrade := w.install.WindowsNodeUpgrade
+	if upgrade == nil {
+		return ""
+	}
+	if upgrade.Paused {
+		return "Windows node upgrades are paused"
+	}
+	if len(upgrade.MaintenanceWindows) > 0 && !inMaintenanceWindow(upgrade.MaintenanceWindows, w.now()) {
+		return "Windows node upgrades are outside of the maintenance windows"
+	}
+	return ""
+}
+
+// isCanary returns whether the node is selected by the canary node selector.
+func (w *calicoWindowsUpgrader) isCanary(node *corev1.Node) bool {
+	upgrade := w.install.WindowsNodeUpgrade
+	if upgrade == nil || len(upgrade.CanaryNodeSelector) == 0 {
+		return false
+	}
+	for k, v := range upgrade.CanaryNodeSelector {
+		if node.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// inMaintenanceWindow returns whether t is within one of the maintenance windows.
+func inMaintenanceWindow(windows []operatorv1.MaintenanceWindow, t time.Time) bool {
+	t = t.UTC()
+	for _, mw := range windows {
+		start, err := time.Parse("15:04", mw.Start)
+		if err != nil {
+			windowsLog.Info(fmt.Sprintf("Ignoring maintenance window with invalid start %q", mw.Start))
+			continue
+		}
+		// The window that t is in may have started on one of the previous days.
+		for days := 0; days <= int(mw.Duration.Duration/(24*time.Hour))+1; days++ {
+			day := t.AddDate(0, 0, -days)
+			from := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, time.UTC)
+			if !startsOn(mw.Days, from.Weekday()) {
+				continue
+			}
+			if !t.Before(from) && t.Before(from.Add(mw.Duration.Duration)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func startsOn(days []operatorv1.DayOfWeek, weekday time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if string(d) == weekday.String() {
+			return true
+		}
+	}
+	return false
+}
+
 func (w *calicoWindowsUpgrader) getExpectedVersion() string {
 	// Use the component's version rather than top-level version values
 	// `EnterpriseRelease` or `CalicoRelease` since for dev releases we use
@@ -222,6 +307,15 @@ func (w *calicoWindowsUpgrader) updateWindowsNodes() {
 		}
 	}
 
+	// Nodes that have been upgrading for too long are reported as failed. They remain in-progress,
+	// so they keep counting towards maxUnavailable.
+	failed := []string{}
+	for _, nodeName := range sortedSliceFromMap(inProgress) {
+		if w.upgradeTimedOut(inProgress[nodeName]) {
+			failed = append(failed, nodeName)
+		}
+	}
+
 	var maxUnavailable int32 = defaultMaxUnavailable
 	// Get the total # of windows nodes we can have upgrading using the
 	// maxUnavailable value, if the node upgrade strategy was respected.
@@ -239,9 +333,31 @@ func (w *calicoWindowsUpgrader) updateWindowsNodes() {
 		}
 	}
 
+	// While any canary node has not been upgraded, only canary nodes are upgraded.
+	canariesRemaining := false
+	for _, node := range inProgress {
+		canariesRemaining = canariesRemaining || w.isCanary(node)
+	}
+	for _, node := range pending {
+		canariesRemaining = canariesRemaining || w.isCanary(node)
+	}
+
+	blockedReason := w.upgradesBlockedReason()
+	if blockedReason != "" && len(pending) > 0 {
+		windowsLog.V(1).Info(fmt.Sprintf("Not starting the upgrade of %v pending nodes: %v", len(pending), blockedReason))
+	}
+
 	for _, nodeName := range sortedSliceFromMap(pending) {
 		node := pending[nodeName]
 
+		if blockedReason != "" {
+			break
+		}
+		if canariesRemaining && !w.isCanary(node) {
+			windowsLog.V(1).Info(fmt.Sprintf("Waiting for the canary nodes to be upgraded before upgrading node %v", node.Name))
+			continue
+		}
+
 		// For upgrades from Calico -> Enterprise, we always upgrade regardless
 		// of maxUnavailable. For other upgrades, check that we have room
 		// available.
@@ -258,14 +374,20 @@ func (w *calicoWindowsUpgrader) updateWindowsNodes() {
 		}
 	}
 
-	// Notify status manager of upgrades status.
+	// Notify status manager of upgrades status. Failed nodes degrade the status, but they do not
+	// degrade the upgrader since it is still able to process the other nodes.
+	var failedErr error
+	if len(failed) > 0 {
+		failedErr = fmt.Errorf("Calico for Windows upgrade did not complete within %v on nodes: %v",
+			w.install.WindowsNodeUpgrade.NodeTimeout.Duration, strings.Join(failed, ", "))
+	}
 	w.isDegraded = false
-	w.statusManager.SetWindowsUpgradeStatus(sortedSliceFromMap(pending), sortedSliceFromMap(inProgress), sortedSliceFromMap(inSync), nil)
+	w.statusManager.SetWindowsUpgradeStatus(sortedSliceFromMap(pending), sortedSliceFromMap(inProgress), sortedSliceFromMap(inSync), failedErr)
 }
 
 func (w *calicoWindowsUpgrader) startUpgrade(ctx context.Context, node *corev1.Node) error {
 	windowsLog.Info(fmt.Sprintf("Starting Calico Windows upgrade on node %v", node.Name))
-	if err := patchNodeToStartUpgrade(ctx, w.clientset, node.Name); err != nil {
+	if err := patchNodeToStartUpgrade(ctx, w.clientset, node.Name, w.now()); err != nil {
 		return fmt.Errorf("Unable to patch node %v to start upgrade: %w", node.Name, err)
 	}
 
@@ -318,8 +440,9 @@ func (w *calicoWindowsUpgrader) IsDegraded() bool {
 }
 
 // patchNodeToStartUpgrade patches a Windows node to prepare it for the calico
-// windows upgrade. It applies a NoSchedule taint and adds the upgrade label.
-func patchNodeToStartUpgrade(ctx context.Context, client kubernetes.Interface, nodeName string) error {
+// windows upgrade. It applies a NoSchedule taint, adds the upgrade label and
+// records the time the upgrade started.
+func patchNodeToStartUpgrade(ctx context.Context, client kubernetes.Interface, nodeName string, started time.Time) error {
 	return wait.PollImmediate(1*time.Second, 1*time.Minute, func() (bool, error) {
 		node, err := client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
 		if err != nil {
@@ -381,7 +504,25 @@ func patchNodeToStartUpgrade(ctx context.Context, client kubernetes.Interface, n
 			patches = append(patches, p)
 		}
 
-		// If either the taint or label do not exist, patch the node to add them.
+		if _, ok := node.Annotations[common.CalicoWindowsUpgradeStartedAnnotation]; !ok {
+			startedAt := started.UTC().Format(time.RFC3339)
+			if node.Annotations == nil {
+				patches = append(patches, objPatch{
+					Op:    "add",
+					Path:  "/metadata/annotations",
+					Value: map[string]string{common.CalicoWindowsUpgradeStartedAnnotation: startedAt},
+				})
+			} else {
+				annotationKey := strings.Replace(common.CalicoWindowsUpgradeStartedAnnotation, "/", "~1", -1)
+				patches = append(patches, objPatch{
+					Op:    "add",
+					Path:  fmt.Sprintf("/metadata/annotations/%s", annotationKey),
+					Value: startedAt,
+				})
+			}
+		}
+
+		// If the taint, label or annotation do not exist, patch the node to add them.
 		if len(patches) > 0 {
 			windowsLog.V(1).Info(fmt.Sprintf("Patching node %v to add upgrade taint and/or label", nodeName))
 
@@ -465,7 +606,16 @@ func patchNodeToCompleteUpgrade(ctx context.Context, client kubernetes.Interface
 			patches = append(patches, p)
 		}
 
-		// If either the taint or label exist, patch the node to remove them.
+		if _, ok := node.Annotations[common.CalicoWindowsUpgradeStartedAnnotation]; ok {
+			annotationKey := strings.Replace(common.CalicoWindowsUpgradeStartedAnnotation, "/", "~1", -1)
+			patches = append(patches, objPatch{
+				// Remove the upgrade start time.
+				Op:   "remove",
+				Path: fmt.Sprintf("/metadata/annotations/%s", annotationKey),
+			})
+		}
+
+		// If the taint, label or annotation exist, patch the node to remove them.
 		if len(patches) > 0 {
 			windowsLog.V(1).Info(fmt.Sprintf("Patching node %v to remove upgrade taint and/or label", nodeName))
 
@@ -25,6 +25,7 @@ import (
 	"github.com/tigera/operator/pkg/controller/status"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	operator "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
@@ -494,6 +495,162 @@ var _ = Describe("Calico windows upgrader", func() {
 
 		})
 	})
+
+	Context("upgrade controls", func() {
+		var w *calicoWindowsUpgrader
+		var now time.Time
+		outdated := "v3.0.0"
+
+		BeforeEach(func() {
+			w = c.(*calicoWindowsUpgrader)
+			now = time.Date(2022, time.March, 5, 23, 30, 0, 0, time.UTC)
+			w.now = func() time.Time { return now }
+			two := intstr.FromInt(2)
+			cr.NodeUpdateStrategy.RollingUpdate.MaxUnavailable = &two
+			cr.Variant = operator.TigeraSecureEnterprise
+			cr.WindowsNodeUpgrade = &operator.WindowsNodeUpgrade{}
+			mockStatus.On("SetWindowsUpgradeStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		// sync runs the upgrader once, after waiting for the informer to have the nodes in their current state.
+		sync := func() {
+			nodes, err := cs.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			for i := range nodes.Items {
+				node := nodes.Items[i]
+				Eventually(func() *corev1.Node {
+					obj, _, _ := nodeIndexInformer.GetIndexer().GetByKey(node.Name)
+					if obj == nil {
+						return nil
+					}
+					return obj.(*corev1.Node)
+				}, 5*time.Second).Should(Equal(&node))
+			}
+			w.install = cr
+			w.updateWindowsNodes()
+		}
+
+		upgrading := func() []string {
+			nodes, err := cs.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			names := []string{}
+			for _, node := range nodes.Items {
+				if node.Labels[common.CalicoWindowsUpgradeLabel] == common.CalicoWindowsUpgradeLabelInProgress {
+					names = append(names, node.Name)
+				}
+			}
+			return names
+		}
+
+		lastStatusErr := func() error {
+			call := mockStatus.Calls[len(mockStatus.Calls)-1]
+			Expect(call.Method).To(Equal("SetWindowsUpgradeStatus"))
+			err, _ := call.Arguments.Get(3).(error)
+			return err
+		}
+
+		It("should not start upgrades while paused", func() {
+			test.CreateWindowsNode(cs, "node1", operator.TigeraSecureEnterprise, outdated)
+			cr.WindowsNodeUpgrade.Paused = true
+
+			sync()
+			Expect(upgrading()).To(BeEmpty())
+			mockStatus.AssertCalled(GinkgoT(), "SetWindowsUpgradeStatus", []string{"node1"}, []string{}, []string{}, nil)
+
+			cr.WindowsNodeUpgrade.Paused = false
+			sync()
+			Expect(upgrading()).To(ConsistOf("node1"))
+		})
+
+		It("should only start upgrades within a maintenance window", func() {
+			test.CreateWindowsNode(cs, "node1", operator.TigeraSecureEnterprise, outdated)
+			cr.WindowsNodeUpgrade.MaintenanceWindows = []operator.MaintenanceWindow{{
+				Days:     []operator.DayOfWeek{"Sunday"},
+				Start:    "00:00",
+				Duration: metav1.Duration{Duration: 2 * time.Hour},
+			}}
+
+			sync()
+			Expect(upgrading()).To(BeEmpty())
+
+			now = now.Add(time.Hour)
+			sync()
+			Expect(upgrading()).To(ConsistOf("node1"))
+		})
+
+		It("should upgrade the canary nodes first", func() {
+			test.CreateWindowsNode(cs, "node1", operator.TigeraSecureEnterprise, outdated)
+			canary := test.CreateNode(cs, "node2",
+				map[string]string{"kubernetes.io/os": "windows", "canary": "true"},
+				map[string]string{
+					common.CalicoVersionAnnotation: outdated,
+					common.CalicoVariantAnnotation: string(operator.TigeraSecureEnterprise),
+				})
+			cr.WindowsNodeUpgrade.CanaryNodeSelector = map[string]string{"canary": "true"}
+
+			sync()
+			Expect(upgrading()).To(ConsistOf("node2"))
+
+			By("continuing to wait for the canary node while it is upgrading")
+			sync()
+			Expect(upgrading()).To(ConsistOf("node2"))
+
+			By("upgrading the other nodes once the canary node has been upgraded")
+			setNodeVariantAndVersion(cs, nodeIndexInformer, canary, operator.TigeraSecureEnterprise, components.ComponentTigeraWindowsUpgrade.Version)
+			sync()
+			Expect(upgrading()).To(ConsistOf("node1"))
+			Expect(assertNodesFinishedUpgrade(cs, canary)).To(Succeed())
+		})
+
+		It("should report nodes whose upgrade takes longer than the timeout as failed", func() {
+			n1 := test.CreateWindowsNode(cs, "node1", operator.TigeraSecureEnterprise, outdated)
+			cr.WindowsNodeUpgrade.NodeTimeout = &metav1.Duration{Duration: 10 * time.Minute}
+
+			sync()
+			Expect(upgrading()).To(ConsistOf("node1"))
+			node, err := cs.CoreV1().Nodes().Get(ctx, "node1", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(node.Annotations).To(HaveKeyWithValue(common.CalicoWindowsUpgradeStartedAnnotation, "2022-03-05T23:30:00Z"))
+			Expect(lastStatusErr()).NotTo(HaveOccurred())
+
+			now = now.Add(11 * time.Minute)
+			sync()
+			Expect(lastStatusErr()).To(MatchError("Calico for Windows upgrade did not complete within 10m0s on nodes: node1"))
+
+			By("clearing the failure once the node has been upgraded")
+			setNodeVariantAndVersion(cs, nodeIndexInformer, n1, operator.TigeraSecureEnterprise, components.ComponentTigeraWindowsUpgrade.Version)
+			sync()
+			Expect(lastStatusErr()).NotTo(HaveOccurred())
+			node, err = cs.CoreV1().Nodes().Get(ctx, "node1", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(node.Annotations).NotTo(HaveKey(common.CalicoWindowsUpgradeStartedAnnotation))
+		})
+	})
+
+	DescribeTable("maintenance windows",
+		func(windows []operator.MaintenanceWindow, t time.Time, expected bool) {
+			Expect(inMaintenanceWindow(windows, t)).To(Equal(expected))
+		},
+		Entry("no windows", nil, time.Date(2022, time.March, 5, 23, 30, 0, 0, time.UTC), false),
+		Entry("within a daily window",
+			[]operator.MaintenanceWindow{{Start: "23:00", Duration: metav1.Duration{Duration: time.Hour}}},
+			time.Date(2022, time.March, 5, 23, 30, 0, 0, time.UTC), true),
+		Entry("at the end of a daily window",
+			[]operator.MaintenanceWindow{{Start: "23:00", Duration: metav1.Duration{Duration: time.Hour}}},
+			time.Date(2022, time.March, 6, 0, 0, 0, 0, time.UTC), false),
+		Entry("within a window that started the day before",
+			[]operator.MaintenanceWindow{{Days: []operator.DayOfWeek{"Saturday"}, Start: "23:00", Duration: metav1.Duration{Duration: 2 * time.Hour}}},
+			time.Date(2022, time.March, 6, 0, 30, 0, 0, time.UTC), true),
+		Entry("on another day of the week",
+			[]operator.MaintenanceWindow{{Days: []operator.DayOfWeek{"Monday"}, Start: "23:00", Duration: metav1.Duration{Duration: 2 * time.Hour}}},
+			time.Date(2022, time.March, 5, 23, 30, 0, 0, time.UTC), false),
+		Entry("in a time zone other than UTC",
+			[]operator.MaintenanceWindow{{Start: "23:00", Duration: metav1.Duration{Duration: time.Hour}}},
+			time.Date(2022, time.March, 5, 15, 30, 0, 0, time.FixedZone("PST", -8*60*60)), true),
+		Entry("within a window that lasts several days",
+			[]operator.MaintenanceWindow{{Days: []operator.DayOfWeek{"Friday"}, Start: "18:00", Duration: metav1.Duration{Duration: 60 * time.Hour}}},
+			time.Date(2022, time.March, 6, 12, 0, 0, 0, time.UTC), true),
+	)
 })
 
 func countNodesUpgrading(nodeIndexInformer cache.SharedIndexInformer) int {
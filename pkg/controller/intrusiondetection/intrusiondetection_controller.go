@@ -17,8 +17,13 @@ package intrusiondetection
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/ghodss/yaml"
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/common"
@@ -33,27 +38,35 @@ import (
 	"github.com/tigera/operator/pkg/render"
 	rcertificatemanagement "github.com/tigera/operator/pkg/render/certificatemanagement"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	"github.com/tigera/operator/pkg/render/intrusiondetection/dpi"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 
+	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 var log = logf.Log.WithName("controller_intrusiondetection")
 
+// maxThreatFeedNameLength keeps the names of the volumes of threat feeds, threat-feed-<name>, within the 63 characters
+// allowed for volume names.
+const maxThreatFeedNameLength = 50
+
 // Add creates a new IntrusionDetection Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager, opts options.AddOptions) error {
@@ -64,9 +77,11 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 
 	licenseAPIReady := &utils.ReadyFlag{}
 	dpiAPIReady := &utils.ReadyFlag{}
+	threatFeedAPIReady := &utils.ReadyFlag{}
+	tierWatchReady := &utils.ReadyFlag{}
 
 	// create the reconciler
-	reconciler := newReconciler(mgr, opts, licenseAPIReady, dpiAPIReady)
+	reconciler := newReconciler(mgr, opts, licenseAPIReady, dpiAPIReady, threatFeedAPIReady, tierWatchReady)
 
 	// Create a new controller
 	controller, err := controller.New("intrusiondetection-controller", mgr, controller.Options{Reconciler: reconcile.Reconciler(reconciler)})
@@ -81,26 +96,34 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	}
 
 	go utils.WaitToAddLicenseKeyWatch(controller, k8sClient, log, licenseAPIReady)
+	go utils.WaitToAddTierWatch(controller, k8sClient, log, tierWatchReady)
 
 	go utils.WaitToAddResourceWatch(controller, k8sClient, log, dpiAPIReady,
 		&v3.DeepPacketInspection{TypeMeta: metav1.TypeMeta{Kind: v3.KindDeepPacketInspection}})
 
+	go utils.WaitToAddResourceWatch(controller, k8sClient, log, threatFeedAPIReady,
+		&v3.GlobalThreatFeed{TypeMeta: metav1.TypeMeta{Kind: v3.KindGlobalThreatFeed}})
+
 	return add(mgr, controller)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady *utils.ReadyFlag, dpiAPIReady *utils.ReadyFlag) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady *utils.ReadyFlag, dpiAPIReady *utils.ReadyFlag, threatFeedAPIReady *utils.ReadyFlag, tierWatchReady *utils.ReadyFlag) reconcile.Reconciler {
 	r := &ReconcileIntrusionDetection{
-		client:          mgr.GetClient(),
-		scheme:          mgr.GetScheme(),
-		provider:        opts.DetectedProvider,
-		status:          status.New(mgr.GetClient(), "intrusion-detection", opts.KubernetesVersion),
-		clusterDomain:   opts.ClusterDomain,
-		licenseAPIReady: licenseAPIReady,
-		dpiAPIReady:     dpiAPIReady,
-		usePSP:          opts.UsePSP,
+		client:             mgr.GetClient(),
+		scheme:             mgr.GetScheme(),
+		provider:           opts.DetectedProvider,
+		status:             status.New(mgr.GetClient(), "intrusion-detection", opts.KubernetesVersion),
+		dpiStatus:          status.New(mgr.GetClient(), "deep-packet-inspection", opts.KubernetesVersion),
+		clusterDomain:      opts.ClusterDomain,
+		licenseAPIReady:    licenseAPIReady,
+		dpiAPIReady:        dpiAPIReady,
+		threatFeedAPIReady: threatFeedAPIReady,
+		tierWatchReady:     tierWatchReady,
+		usePSP:             opts.UsePSP,
 	}
 	r.status.Run(opts.ShutdownContext)
+	r.dpiStatus.Run(opts.ShutdownContext)
 	return r
 }
 
@@ -182,9 +205,54 @@ func add(mgr manager.Manager, c controller.Controller) error {
 		return fmt.Errorf("intrusiondetection-controller failed to watch the Secret resource: %v", err)
 	}
 
+	// Watch the ConfigMaps and Secrets in the operator namespace that the IntrusionDetection reads the user defined
+	// GlobalAlertTemplates, the threat feeds and the threat feed headers from. Their names are configurable, so the
+	// events are filtered by the names that the IntrusionDetection references when they occur.
+	referenced := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return isReferencedSource(mgr.GetClient(), obj)
+	})
+	if err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForObject{}, referenced); err != nil {
+		return fmt.Errorf("intrusiondetection-controller failed to watch the ConfigMap resource: %v", err)
+	}
+	if err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForObject{}, referenced); err != nil {
+		return fmt.Errorf("intrusiondetection-controller failed to watch the Secret resource: %v", err)
+	}
+
 	return nil
 }
 
+// isReferencedSource returns whether obj is a ConfigMap or Secret in the operator namespace that the IntrusionDetection
+// reads user defined GlobalAlertTemplates, threat feeds or threat feed headers from.
+func isReferencedSource(cli client.Client, obj client.Object) bool {
+	if obj.GetNamespace() != common.OperatorNamespace() {
+		return false
+	}
+	ids := &operatorv1.IntrusionDetection{}
+	if err := cli.Get(context.Background(), utils.DefaultTSEEInstanceKey, ids); err != nil {
+		return false
+	}
+	switch obj.(type) {
+	case *corev1.ConfigMap:
+		if ids.Spec.GlobalAlertTemplatesConfigMapName == obj.GetName() {
+			return true
+		}
+		for _, f := range ids.Spec.ThreatFeeds {
+			if f.ConfigMap != nil && f.ConfigMap.Name == obj.GetName() {
+				return true
+			}
+		}
+	case *corev1.Secret:
+		for _, f := range ids.Spec.ThreatFeeds {
+			for _, h := range f.Headers {
+				if h.SecretKeyRef != nil && h.SecretKeyRef.Name == obj.GetName() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 // blank assignment to verify that ReconcileIntrusionDetection implements reconcile.Reconciler
 var _ reconcile.Reconciler = &ReconcileIntrusionDetection{}
 
@@ -199,7 +267,15 @@ type ReconcileIntrusionDetection struct {
 	clusterDomain   string
 	licenseAPIReady *utils.ReadyFlag
 	dpiAPIReady     *utils.ReadyFlag
+	tierWatchReady  *utils.ReadyFlag
 	usePSP          bool
+
+	// threatFeedAPIReady is set once the GlobalThreatFeeds are watched, after which their status is reported.
+	threatFeedAPIReady *utils.ReadyFlag
+
+	// dpiStatus reports the deep packet inspection DaemonSets, which run on their own nodes and schedule, separately
+	// from the rest of intrusion detection.
+	dpiStatus status.StatusManager
 }
 
 // Reconcile reads that state of the cluster for a IntrusionDetection object and makes changes based on the state read
@@ -221,6 +297,7 @@ func (r *ReconcileIntrusionDetection) Reconcile(ctx context.Context, request rec
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
 			r.status.OnCRNotFound()
+			r.dpiStatus.OnCRNotFound()
 			return reconcile.Result{}, nil
 		}
 		reqLogger.V(3).Info("failed to get IntrusionDetection CR", "err", err)
@@ -381,26 +458,88 @@ func (r *ReconcileIntrusionDetection) Reconcile(ctx context.Context, request rec
 		trustedBundle.AddCertificates(managerInternalTLSSecret)
 	}
 
+	if err := validateAnomalyDetection(instance.Spec.AnomalyDetection); err != nil {
+		log.Error(err, "Invalid anomaly detection configuration")
+		r.status.SetDegraded("Invalid anomaly detection configuration", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	userAlertTemplates, err := getUserGlobalAlertTemplates(ctx, r.client, instance.Spec.GlobalAlertTemplatesConfigMapName)
+	if err != nil {
+		log.Error(err, "Invalid user defined GlobalAlertTemplates")
+		r.status.SetDegraded("Invalid user defined GlobalAlertTemplates", err.Error())
+		return reconcile.Result{}, err
+	}
+	removedAlertTemplates, err := r.removedUserGlobalAlertTemplates(ctx, userAlertTemplates)
+	if err != nil {
+		log.Error(err, "Failed to list GlobalAlertTemplates")
+		r.status.SetDegraded("Failed to list GlobalAlertTemplates", err.Error())
+		return reconcile.Result{}, err
+	}
+	adGlobalAlerts, err := r.anomalyDetectionGlobalAlerts(ctx)
+	if err != nil {
+		log.Error(err, "Failed to list GlobalAlerts")
+		r.status.SetDegraded("Failed to list GlobalAlerts", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	threatFeedConfigMaps, threatFeedSecrets, err := getThreatFeedSources(ctx, r.client, instance.Spec.ThreatFeeds)
+	if err != nil {
+		log.Error(err, "Invalid threat feeds")
+		r.status.SetDegraded("Invalid threat feeds", err.Error())
+		return reconcile.Result{}, err
+	}
+	removedThreatFeeds, err := r.removedThreatFeeds(ctx, instance.Spec.ThreatFeeds)
+	if err != nil {
+		log.Error(err, "Failed to list GlobalThreatFeeds")
+		r.status.SetDegraded("Failed to list GlobalThreatFeeds", err.Error())
+		return reconcile.Result{}, err
+	}
+	removedThreatFeedConfigMaps, removedThreatFeedSecrets, err := r.removedThreatFeedSources(ctx, threatFeedConfigMaps, threatFeedSecrets)
+	if err != nil {
+		log.Error(err, "Failed to list the sources of threat feeds")
+		r.status.SetDegraded("Failed to list the sources of threat feeds", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	networkPolicyState, err := utils.GetNetworkPolicyState(ctx, r.client, r.tierWatchReady, network, instance.Spec.ComponentNetworkPolicy)
+	if err != nil {
+		reqLogger.Error(err, "Error querying allow-tigera tier")
+		r.status.SetDegraded("Error querying allow-tigera tier", err.Error())
+		return reconcile.Result{}, err
+	}
+
 	// Create a component handler to manage the rendered component.
-	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance)
+	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance, network)
 
 	reqLogger.V(3).Info("rendering components")
 	// Render the desired objects from the CRD and create or update them.
 	hasNoLicense := !utils.IsFeatureActive(license, common.ThreatDefenseFeature)
 	intrusionDetectionCfg := &render.IntrusionDetectionConfiguration{
-		LogCollector:          lc,
-		ESSecrets:             esSecrets,
-		Installation:          network,
-		ESClusterConfig:       esClusterConfig,
-		PullSecrets:           pullSecrets,
-		Openshift:             r.provider == operatorv1.ProviderOpenShift,
-		ClusterDomain:         r.clusterDomain,
-		ESLicenseType:         esLicenseType,
-		ManagedCluster:        managementClusterConnection != nil,
-		HasNoLicense:          hasNoLicense,
-		TrustedCertBundle:     trustedBundle,
-		ADAPIServerCertSecret: adAPIServerTLSSecret,
-		UsePSP:                r.usePSP,
+		LogCollector:                    lc,
+		ESSecrets:                       esSecrets,
+		Installation:                    network,
+		ESClusterConfig:                 esClusterConfig,
+		PullSecrets:                     pullSecrets,
+		Openshift:                       r.provider == operatorv1.ProviderOpenShift,
+		ClusterDomain:                   r.clusterDomain,
+		ESLicenseType:                   esLicenseType,
+		ManagedCluster:                  managementClusterConnection != nil,
+		HasNoLicense:                    hasNoLicense,
+		TrustedCertBundle:               trustedBundle,
+		ADAPIServerCertSecret:           adAPIServerTLSSecret,
+		IntrusionDetection:              instance,
+		UserGlobalAlertTemplates:        userAlertTemplates,
+		RemovedUserGlobalAlertTemplates: removedAlertTemplates,
+		AnomalyDetectionGlobalAlerts:    adGlobalAlerts,
+		ThreatFeedConfigMaps:            threatFeedConfigMaps,
+		ThreatFeedSecrets:               threatFeedSecrets,
+		RemovedThreatFeeds:              removedThreatFeeds,
+		RemovedThreatFeedConfigMaps:     removedThreatFeedConfigMaps,
+		RemovedThreatFeedSecrets:        removedThreatFeedSecrets,
+		LogLevel:                        loglevel.Resolve(network, instance.Spec.LogLevel),
+		UsePSP:                          r.usePSP,
+		NetworkPolicyState:              networkPolicyState,
 	}
 	comp := render.IntrusionDetection(intrusionDetectionCfg)
 
@@ -425,23 +564,14 @@ func (r *ReconcileIntrusionDetection) Reconcile(ctx context.Context, request rec
 		return reconcile.Result{}, err
 	}
 	hasNoDPIResource := len(dpiList.Items) == 0
-
-	dpiComponent := dpi.DPI(&dpi.DPIConfig{
-		IntrusionDetection: instance,
-		Installation:       network,
-		TyphaNodeTLS:       typhaNodeTLS,
-		PullSecrets:        pullSecrets,
-		Openshift:          r.provider == operatorv1.ProviderOpenShift,
-		HasNoLicense:       hasNoLicense,
-		HasNoDPIResource:   hasNoDPIResource,
-		ESClusterConfig:    esClusterConfig,
-		ESSecrets:          esSecrets,
-		ClusterDomain:      r.clusterDomain,
-	})
+	if hasNoDPIResource || hasNoLicense {
+		r.dpiStatus.OnCRNotFound()
+	} else {
+		r.dpiStatus.OnCRFound()
+	}
 
 	components := []render.Component{
 		comp,
-		dpiComponent,
 		rcertificatemanagement.CertificateManagement(&rcertificatemanagement.Config{
 			Namespace:       render.IntrusionDetectionNamespace,
 			ServiceAccounts: []string{render.IntrusionDetectionName, render.ADAPIObjectName},
@@ -450,20 +580,50 @@ func (r *ReconcileIntrusionDetection) Reconcile(ctx context.Context, request rec
 			},
 			TrustedBundle: trustedBundle,
 		}),
-		rcertificatemanagement.CertificateManagement(&rcertificatemanagement.Config{
-			Namespace:       dpi.DeepPacketInspectionNamespace,
-			ServiceAccounts: []string{dpi.DeepPacketInspectionName},
-			KeyPairOptions: []rcertificatemanagement.KeyPairOption{
-				rcertificatemanagement.NewKeyPairOption(typhaNodeTLS.NodeSecret, false, true),
-			},
-			TrustedBundle: typhaNodeTLS.TrustedBundle,
-		}),
 	}
 
-	if err = imageset.ApplyImageSet(ctx, r.client, variant, dpiComponent); err != nil {
-		reqLogger.Error(err, "Error with images from ImageSet")
-		r.status.SetDegraded("Error with images from ImageSet", err.Error())
-		return reconcile.Result{}, err
+	// An invalid deep packet inspection configuration only degrades deep packet inspection, which is left as is
+	// until the configuration is fixed.
+	var dpiComponents []render.Component
+	dpiConfigErr := validateDeepPacketInspection(instance.Spec.DeepPacketInspection)
+	if dpiConfigErr == nil {
+		removedNodePools, err := r.removedDPINodePools(ctx, instance.Spec.DeepPacketInspection)
+		if err != nil {
+			r.status.SetDegraded("Failed to list the deep packet inspection DaemonSets", err.Error())
+			return reconcile.Result{}, err
+		}
+
+		dpiComponent := dpi.DPI(&dpi.DPIConfig{
+			IntrusionDetection: instance,
+			Installation:       network,
+			TyphaNodeTLS:       typhaNodeTLS,
+			PullSecrets:        pullSecrets,
+			Openshift:          r.provider == operatorv1.ProviderOpenShift,
+			HasNoLicense:       hasNoLicense,
+			HasNoDPIResource:   hasNoDPIResource,
+			ESClusterConfig:    esClusterConfig,
+			ESSecrets:          esSecrets,
+			ClusterDomain:      r.clusterDomain,
+			RemovedNodePools:   removedNodePools,
+		})
+
+		if err = imageset.ApplyImageSet(ctx, r.client, variant, dpiComponent); err != nil {
+			reqLogger.Error(err, "Error with images from ImageSet")
+			r.status.SetDegraded("Error with images from ImageSet", err.Error())
+			return reconcile.Result{}, err
+		}
+
+		dpiComponents = []render.Component{
+			dpiComponent,
+			rcertificatemanagement.CertificateManagement(&rcertificatemanagement.Config{
+				Namespace:       dpi.DeepPacketInspectionNamespace,
+				ServiceAccounts: []string{dpi.DeepPacketInspectionName},
+				KeyPairOptions: []rcertificatemanagement.KeyPairOption{
+					rcertificatemanagement.NewKeyPairOption(typhaNodeTLS.NodeSecret, false, true),
+				},
+				TrustedBundle: typhaNodeTLS.TrustedBundle,
+			}),
+		}
 	}
 
 	for _, comp := range components {
@@ -472,6 +632,18 @@ func (r *ReconcileIntrusionDetection) Reconcile(ctx context.Context, request rec
 			return reconcile.Result{}, err
 		}
 	}
+	for _, comp := range dpiComponents {
+		if err := handler.CreateOrUpdateOrDelete(context.Background(), comp, r.dpiStatus); err != nil {
+			r.dpiStatus.SetDegraded("Error creating / updating resource", err.Error())
+			return reconcile.Result{}, err
+		}
+	}
+	if dpiConfigErr != nil {
+		log.Error(dpiConfigErr, "Invalid deep packet inspection configuration")
+		r.dpiStatus.SetDegraded("Invalid deep packet inspection configuration", dpiConfigErr.Error())
+	} else {
+		r.dpiStatus.ClearDegraded()
+	}
 
 	if hasNoLicense {
 		log.V(4).Info("IntrusionDetection is not activated as part of this license")
@@ -479,6 +651,18 @@ func (r *ReconcileIntrusionDetection) Reconcile(ctx context.Context, request rec
 		return reconcile.Result{}, nil
 	}
 
+	if r.threatFeedAPIReady.IsReady() {
+		failing, err := r.updateThreatFeedStatus(ctx, instance)
+		if err != nil {
+			log.Error(err, "Failed to update the status of the threat feeds")
+			r.status.SetDegraded("Failed to update the status of the threat feeds", err.Error())
+			return reconcile.Result{}, err
+		}
+		// A failing feed doesn't affect the other feeds or the rest of intrusion detection, so it is reported in its own
+		// condition of the TigeraStatus rather than degrading it.
+		r.status.SetThreatFeedErrors(failing)
+	}
+
 	// Clear the degraded bit if we've reached this far.
 	r.status.ClearDegraded()
 
@@ -522,3 +706,346 @@ func (r *ReconcileIntrusionDetection) setDefaultsOnIntrusionDetection(ctx contex
 
 	return nil
 }
+
+// getUserGlobalAlertTemplates reads and validates the user defined GlobalAlertTemplates in the ConfigMap, which holds
+// a template manifest per key.
+func getUserGlobalAlertTemplates(ctx context.Context, cli client.Client, configMapName string) ([]*v3.GlobalAlertTemplate, error) {
+	if configMapName == "" {
+		return nil, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := cli.Get(ctx, client.ObjectKey{Name: configMapName, Namespace: common.OperatorNamespace()}, cm); err != nil {
+		return nil, fmt.Errorf("failed to read ConfigMap %s: %w", configMapName, err)
+	}
+
+	builtIn := map[string]bool{}
+	for _, name := range render.IntrusionDetectionBuiltInGlobalAlertTemplateNames() {
+		builtIn[name] = true
+	}
+	keys := make([]string, 0, len(cm.Data))
+	for k := range cm.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var templates []*v3.GlobalAlertTemplate
+	names := map[string]string{}
+	for _, k := range keys {
+		t := &v3.GlobalAlertTemplate{}
+		if err := yaml.Unmarshal([]byte(cm.Data[k]), t); err != nil {
+			return nil, fmt.Errorf("key %q of ConfigMap %s is not a GlobalAlertTemplate: %w", k, configMapName, err)
+		}
+		if err := validateGlobalAlertTemplate(t); err != nil {
+			return nil, fmt.Errorf("GlobalAlertTemplate in key %q of ConfigMap %s is invalid: %w", k, configMapName, err)
+		}
+		if builtIn[t.Name] || strings.HasPrefix(t.Name, render.ADDetectorPrefixName) {
+			return nil, fmt.Errorf("GlobalAlertTemplate in key %q of ConfigMap %s has the name of a built-in template: %s", k, configMapName, t.Name)
+		}
+		if other, ok := names[t.Name]; ok {
+			return nil, fmt.Errorf("GlobalAlertTemplate %s is defined in both key %q and key %q of ConfigMap %s", t.Name, other, k, configMapName)
+		}
+		names[t.Name] = k
+
+		// Only keep the fields that the operator owns.
+		labels := map[string]string{render.UserGlobalAlertTemplateLabel: "true"}
+		for k, v := range t.Labels {
+			labels[k] = v
+		}
+		templates = append(templates, &v3.GlobalAlertTemplate{
+			TypeMeta:   metav1.TypeMeta{Kind: v3.KindGlobalAlertTemplate, APIVersion: v3.GroupVersionCurrent},
+			ObjectMeta: metav1.ObjectMeta{Name: t.Name, Labels: labels, Annotations: t.Annotations},
+			Spec:       t.Spec,
+		})
+	}
+	return templates, nil
+}
+
+func validateGlobalAlertTemplate(t *v3.GlobalAlertTemplate) error {
+	if t.Kind != "" && t.Kind != v3.KindGlobalAlertTemplate {
+		return fmt.Errorf("unexpected kind %s", t.Kind)
+	}
+	if t.APIVersion != "" && t.APIVersion != v3.GroupVersionCurrent {
+		return fmt.Errorf("unexpected apiVersion %s", t.APIVersion)
+	}
+	if t.Name == "" {
+		return fmt.Errorf("metadata.name is required")
+	}
+	if t.Spec.Description == "" {
+		return fmt.Errorf("spec.description is required")
+	}
+	if t.Spec.Severity < 1 || t.Spec.Severity > 100 {
+		return fmt.Errorf("spec.severity must be between 1 and 100")
+	}
+	switch t.Spec.Type {
+	case v3.GlobalAlertTypeAnomalyDetection:
+		if t.Spec.Detector == nil || t.Spec.Detector.Name == "" {
+			return fmt.Errorf("spec.detector is required for alerts of type %s", t.Spec.Type)
+		}
+	case "", v3.GlobalAlertTypeRuleBased:
+		if t.Spec.DataSet == "" {
+			return fmt.Errorf("spec.dataSet is required")
+		}
+	default:
+		return fmt.Errorf("unexpected spec.type %s", t.Spec.Type)
+	}
+	return nil
+}
+
+// getThreatFeedSources validates the threat feeds and returns the ConfigMaps that hold feeds and the Secrets that hold
+// the values of headers.
+func getThreatFeedSources(ctx context.Context, cli client.Client, feeds []operatorv1.ThreatFeed) ([]*corev1.ConfigMap, []*corev1.Secret, error) {
+	var configMaps []*corev1.ConfigMap
+	var secrets []*corev1.Secret
+	seenConfigMaps := map[string]bool{}
+	seenSecrets := map[string]bool{}
+	names := map[string]bool{}
+
+	for _, f := range feeds {
+		if errs := validation.IsDNS1123Label(f.Name); len(errs) != 0 {
+			return nil, nil, fmt.Errorf("threat feed %s has an invalid name: %s", f.Name, strings.Join(errs, ", "))
+		}
+		if len(f.Name) > maxThreatFeedNameLength {
+			return nil, nil, fmt.Errorf("threat feed %s has a name longer than %d characters", f.Name, maxThreatFeedNameLength)
+		}
+		if names[f.Name] {
+			return nil, nil, fmt.Errorf("threat feed %s is defined more than once", f.Name)
+		}
+		names[f.Name] = true
+
+		if (f.URL == "") == (f.ConfigMap == nil) {
+			return nil, nil, fmt.Errorf("threat feed %s must have exactly one of url and configMap", f.Name)
+		}
+		if f.URL != "" {
+			u, err := url.Parse(f.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, nil, fmt.Errorf("threat feed %s has an invalid url %q, it must be an HTTP or HTTPS URL", f.Name, f.URL)
+			}
+		}
+		if f.PullPeriod != nil && f.PullPeriod.Duration < v3.MinPullPeriod {
+			return nil, nil, fmt.Errorf("threat feed %s has a pullPeriod of %s, it must be at least %s", f.Name, f.PullPeriod.Duration, v3.MinPullPeriod)
+		}
+		if f.GlobalNetworkSet != nil && f.Content != nil && *f.Content != operatorv1.ThreatFeedContentIPSet {
+			return nil, nil, fmt.Errorf("threat feed %s can only be synced into a GlobalNetworkSet if its content is %s", f.Name, operatorv1.ThreatFeedContentIPSet)
+		}
+
+		if f.ConfigMap != nil {
+			if len(f.Headers) != 0 {
+				return nil, nil, fmt.Errorf("threat feed %s can only have headers if it is pulled from a url", f.Name)
+			}
+			cm := &corev1.ConfigMap{}
+			if err := cli.Get(ctx, client.ObjectKey{Name: f.ConfigMap.Name, Namespace: common.OperatorNamespace()}, cm); err != nil {
+				return nil, nil, fmt.Errorf("failed to read ConfigMap %s of threat feed %s: %w", f.ConfigMap.Name, f.Name, err)
+			}
+			if _, ok := cm.Data[f.ConfigMap.Key]; !ok {
+				return nil, nil, fmt.Errorf("ConfigMap %s of threat feed %s does not have a value for %q", f.ConfigMap.Name, f.Name, f.ConfigMap.Key)
+			}
+			if !seenConfigMaps[cm.Name] {
+				seenConfigMaps[cm.Name] = true
+				configMaps = append(configMaps, cm)
+			}
+		}
+
+		for _, h := range f.Headers {
+			if (h.Value == "") == (h.SecretKeyRef == nil) {
+				return nil, nil, fmt.Errorf("header %s of threat feed %s must have exactly one of value and secretKeyRef", h.Name, f.Name)
+			}
+			if h.SecretKeyRef == nil {
+				continue
+			}
+			s := &corev1.Secret{}
+			if err := cli.Get(ctx, client.ObjectKey{Name: h.SecretKeyRef.Name, Namespace: common.OperatorNamespace()}, s); err != nil {
+				return nil, nil, fmt.Errorf("failed to read Secret %s of threat feed %s: %w", h.SecretKeyRef.Name, f.Name, err)
+			}
+			if _, ok := s.Data[h.SecretKeyRef.Key]; !ok {
+				return nil, nil, fmt.Errorf("secret %s of threat feed %s does not have a value for %q", h.SecretKeyRef.Name, f.Name, h.SecretKeyRef.Key)
+			}
+			if !seenSecrets[s.Name] {
+				seenSecrets[s.Name] = true
+				secrets = append(secrets, s)
+			}
+		}
+	}
+	return configMaps, secrets, nil
+}
+
+// validateAnomalyDetection validates the anomaly detection section of the IntrusionDetection CR.
+func validateAnomalyDetection(spec *operatorv1.AnomalyDetectionSpec) error {
+	if spec == nil {
+		return nil
+	}
+	if spec.TrainingPeriod != nil && spec.TrainingPeriod.Duration < time.Minute {
+		return fmt.Errorf("training period %s is shorter than a minute", spec.TrainingPeriod.Duration)
+	}
+	for _, d := range spec.Detectors {
+		if d.Sensitivity != nil && !render.AnomalyDetectorHasSensitivity(d.Name) {
+			return fmt.Errorf("the sensitivity of detector %s is not configurable", d.Name)
+		}
+	}
+	return nil
+}
+
+// validateDeepPacketInspection validates the deep packet inspection section of the IntrusionDetection CR.
+func validateDeepPacketInspection(spec *operatorv1.DeepPacketInspectionSpec) error {
+	if spec == nil {
+		return nil
+	}
+	names := map[string]bool{}
+	for _, pool := range spec.NodePools {
+		if names[pool.Name] {
+			return fmt.Errorf("node pool %s is defined more than once", pool.Name)
+		}
+		names[pool.Name] = true
+		if len(pool.NodeSelector) == 0 {
+			return fmt.Errorf("node pool %s has no node selector", pool.Name)
+		}
+	}
+	return nil
+}
+
+// removedDPINodePools returns the names of the node pools that still have a deep packet inspection DaemonSet but
+// have since been removed from the IntrusionDetection CR.
+func (r *ReconcileIntrusionDetection) removedDPINodePools(ctx context.Context, spec *operatorv1.DeepPacketInspectionSpec) ([]string, error) {
+	list := &appsv1.DaemonSetList{}
+	if err := r.client.List(ctx, list, client.InNamespace(dpi.DeepPacketInspectionNamespace), client.HasLabels{dpi.NodePoolLabel}); err != nil {
+		return nil, err
+	}
+	current := map[string]bool{}
+	if spec != nil {
+		for _, pool := range spec.NodePools {
+			current[pool.Name] = true
+		}
+	}
+	var removed []string
+	for _, ds := range list.Items {
+		if pool := ds.Labels[dpi.NodePoolLabel]; !current[pool] {
+			removed = append(removed, pool)
+		}
+	}
+	return removed, nil
+}
+
+// removedUserGlobalAlertTemplates returns the names of the user defined GlobalAlertTemplates that the operator
+// installed but that have since been removed from their ConfigMap.
+func (r *ReconcileIntrusionDetection) removedUserGlobalAlertTemplates(ctx context.Context, templates []*v3.GlobalAlertTemplate) ([]string, error) {
+	list := &v3.GlobalAlertTemplateList{}
+	if err := r.client.List(ctx, list, client.HasLabels{render.UserGlobalAlertTemplateLabel}); err != nil {
+		return nil, err
+	}
+	current := map[string]bool{}
+	for _, t := range templates {
+		current[t.Name] = true
+	}
+	var removed []string
+	for _, t := range list.Items {
+		if !current[t.Name] {
+			removed = append(removed, t.Name)
+		}
+	}
+	return removed, nil
+}
+
+// anomalyDetectionGlobalAlerts returns the GlobalAlerts that run anomaly detectors, including those that users created
+// from the built-in anomaly detection GlobalAlertTemplates.
+func (r *ReconcileIntrusionDetection) anomalyDetectionGlobalAlerts(ctx context.Context) ([]*v3.GlobalAlert, error) {
+	list := &v3.GlobalAlertList{}
+	if err := r.client.List(ctx, list); err != nil {
+		return nil, err
+	}
+	var alerts []*v3.GlobalAlert
+	for i := range list.Items {
+		if list.Items[i].Spec.Type == v3.GlobalAlertTypeAnomalyDetection {
+			alerts = append(alerts, &list.Items[i])
+		}
+	}
+	return alerts, nil
+}
+
+// removedThreatFeeds returns the names of the GlobalThreatFeeds that the operator created for threat feeds which have
+// since been removed from the IntrusionDetection CR.
+func (r *ReconcileIntrusionDetection) removedThreatFeeds(ctx context.Context, feeds []operatorv1.ThreatFeed) ([]string, error) {
+	if !r.threatFeedAPIReady.IsReady() {
+		return nil, nil
+	}
+	list := &v3.GlobalThreatFeedList{}
+	if err := r.client.List(ctx, list, client.HasLabels{render.ThreatFeedLabel}); err != nil {
+		return nil, err
+	}
+	current := map[string]bool{}
+	for _, f := range feeds {
+		current[f.Name] = true
+	}
+	var removed []string
+	for _, f := range list.Items {
+		if !current[f.Name] {
+			removed = append(removed, f.Name)
+		}
+	}
+	return removed, nil
+}
+
+// removedThreatFeedSources returns the names of the copies of ConfigMaps and secrets in the intrusion detection
+// namespace that no threat feed reads from anymore.
+func (r *ReconcileIntrusionDetection) removedThreatFeedSources(ctx context.Context, configMaps []*corev1.ConfigMap, secrets []*corev1.Secret) ([]string, []string, error) {
+	current := map[string]bool{}
+	for _, cm := range configMaps {
+		current[cm.Name] = true
+	}
+	cmList := &corev1.ConfigMapList{}
+	if err := r.client.List(ctx, cmList, client.InNamespace(render.IntrusionDetectionNamespace), client.HasLabels{render.ThreatFeedLabel}); err != nil {
+		return nil, nil, err
+	}
+	var removedConfigMaps []string
+	for _, cm := range cmList.Items {
+		if !current[cm.Name] {
+			removedConfigMaps = append(removedConfigMaps, cm.Name)
+		}
+	}
+
+	current = map[string]bool{}
+	for _, s := range secrets {
+		current[s.Name] = true
+	}
+	secretList := &corev1.SecretList{}
+	if err := r.client.List(ctx, secretList, client.InNamespace(render.IntrusionDetectionNamespace), client.HasLabels{render.ThreatFeedLabel}); err != nil {
+		return nil, nil, err
+	}
+	var removedSecrets []string
+	for _, s := range secretList.Items {
+		if !current[s.Name] {
+			removedSecrets = append(removedSecrets, s.Name)
+		}
+	}
+	return removedConfigMaps, removedSecrets, nil
+}
+
+// updateThreatFeedStatus records the state of the GlobalThreatFeeds of the threat feeds in the status of the
+// IntrusionDetection CR, and returns the errors of the feeds that are failing, keyed by feed name. Errors reading a
+// GlobalThreatFeed are recorded on the status entry of that feed only.
+func (r *ReconcileIntrusionDetection) updateThreatFeedStatus(ctx context.Context, ids *operatorv1.IntrusionDetection) (map[string][]string, error) {
+	var statuses []operatorv1.ThreatFeedStatus
+	failing := map[string][]string{}
+	for _, f := range ids.Spec.ThreatFeeds {
+		status := operatorv1.ThreatFeedStatus{Name: f.Name}
+		gtf := &v3.GlobalThreatFeed{}
+		if err := r.client.Get(ctx, client.ObjectKey{Name: f.Name}, gtf); err != nil && !errors.IsNotFound(err) {
+			status.Errors = append(status.Errors, fmt.Sprintf("Failed to read the GlobalThreatFeed: %s", err))
+		} else if err == nil {
+			status.LastSuccessfulSync = gtf.Status.LastSuccessfulSync
+			for _, c := range gtf.Status.ErrorConditions {
+				status.Errors = append(status.Errors, fmt.Sprintf("%s: %s", c.Type, c.Message))
+			}
+		}
+		if len(status.Errors) != 0 {
+			failing[f.Name] = status.Errors
+		}
+		statuses = append(statuses, status)
+	}
+
+	if !reflect.DeepEqual(statuses, ids.Status.ThreatFeeds) {
+		ids.Status.ThreatFeeds = statuses
+		if err := r.client.Status().Update(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+	return failing, nil
+}
@@ -17,6 +17,7 @@ package intrusiondetection
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
@@ -58,6 +59,7 @@ var _ = Describe("IntrusionDetection controller tests", func() {
 	var r ReconcileIntrusionDetection
 	var scheme *runtime.Scheme
 	var mockStatus *status.MockStatus
+	var mockDPIStatus *status.MockStatus
 
 	BeforeEach(func() {
 		// The schema contains all objects that should be known to the fake client when the test runs.
@@ -79,21 +81,37 @@ var _ = Describe("IntrusionDetection controller tests", func() {
 		mockStatus.On("RemoveDeployments", mock.Anything).Return()
 		mockStatus.On("AddStatefulSets", mock.Anything).Return()
 		mockStatus.On("AddCronJobs", mock.Anything)
+		mockStatus.On("SetPodSecurityRequirements", mock.Anything)
+		mockStatus.On("SetThreatFeedErrors", mock.Anything)
 		mockStatus.On("IsAvailable").Return(true)
 		mockStatus.On("OnCRFound").Return()
 		mockStatus.On("ClearDegraded")
 		mockStatus.On("SetDegraded", "Waiting for LicenseKeyAPI to be ready", "").Return().Maybe()
 		mockStatus.On("ReadyToMonitor")
 
+		mockDPIStatus = &status.MockStatus{}
+		mockDPIStatus.On("AddDaemonsets", mock.Anything).Return().Maybe()
+		mockDPIStatus.On("RemoveDaemonsets", mock.Anything).Return().Maybe()
+		mockDPIStatus.On("AddDeployments", mock.Anything).Return().Maybe()
+		mockDPIStatus.On("AddStatefulSets", mock.Anything).Return().Maybe()
+		mockDPIStatus.On("AddCronJobs", mock.Anything).Return().Maybe()
+		mockDPIStatus.On("SetPodSecurityRequirements", mock.Anything).Return().Maybe()
+		mockDPIStatus.On("OnCRFound").Return().Maybe()
+		mockDPIStatus.On("OnCRNotFound").Return().Maybe()
+		mockDPIStatus.On("ClearDegraded").Return().Maybe()
+		mockDPIStatus.On("ReadyToMonitor").Return().Maybe()
+
 		// Create an object we can use throughout the test to do the compliance reconcile loops.
 		// As the parameters in the client changes, we expect the outcomes of the reconcile loops to change.
 		r = ReconcileIntrusionDetection{
-			client:          c,
-			scheme:          scheme,
-			provider:        operatorv1.ProviderNone,
-			status:          mockStatus,
-			licenseAPIReady: &utils.ReadyFlag{},
-			dpiAPIReady:     &utils.ReadyFlag{},
+			client:             c,
+			scheme:             scheme,
+			provider:           operatorv1.ProviderNone,
+			status:             mockStatus,
+			dpiStatus:          mockDPIStatus,
+			licenseAPIReady:    &utils.ReadyFlag{},
+			dpiAPIReady:        &utils.ReadyFlag{},
+			threatFeedAPIReady: &utils.ReadyFlag{},
 		}
 
 		// We start off with a 'standard' installation, with nothing special
@@ -154,6 +172,7 @@ var _ = Describe("IntrusionDetection controller tests", func() {
 		// mark that the watch for license key and dpi was successful
 		r.licenseAPIReady.MarkAsReady()
 		r.dpiAPIReady.MarkAsReady()
+		r.threatFeedAPIReady.MarkAsReady()
 	})
 
 	Context("image reconciliation", func() {
@@ -562,4 +581,384 @@ var _ = Describe("IntrusionDetection controller tests", func() {
 			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Limits.Memory()).Should(Equal(resource.MustParse(memoryLimit)))
 		})
 	})
+
+	Context("user defined GlobalAlertTemplates", func() {
+		BeforeEach(func() {
+			mockStatus.On("SetDegraded", mock.Anything, mock.Anything).Return()
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator"}})).NotTo(HaveOccurred())
+		})
+
+		It("should install the user defined GlobalAlertTemplates of the ConfigMap", func() {
+			Expect(c.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "user-alert-templates", Namespace: common.OperatorNamespace()},
+				Data: map[string]string{
+					"user.yaml": `
+apiVersion: projectcalico.org/v3
+kind: GlobalAlertTemplate
+metadata:
+  name: user.template
+spec:
+  description: Alerts on flows to the example namespace
+  severity: 50
+  dataSet: flows
+  query: dest_namespace="example"
+`,
+				},
+			})).To(Succeed())
+			ids := &operatorv1.IntrusionDetection{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, ids)).To(Succeed())
+			ids.Spec.GlobalAlertTemplatesConfigMapName = "user-alert-templates"
+			Expect(c.Update(ctx, ids)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			t := &v3.GlobalAlertTemplate{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "user.template"}, t)).To(Succeed())
+			Expect(t.Labels).To(HaveKey(render.UserGlobalAlertTemplateLabel))
+			Expect(t.Spec.Query).To(Equal(`dest_namespace="example"`))
+			Expect(t.Spec.Severity).To(Equal(50))
+
+			By("deleting the templates removed from the ConfigMap")
+			cm := &corev1.ConfigMap{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "user-alert-templates", Namespace: common.OperatorNamespace()}, cm)).To(Succeed())
+			cm.Data = map[string]string{}
+			Expect(c.Update(ctx, cm)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "user.template"}, t)).NotTo(Succeed())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "policy.pod"}, &v3.GlobalAlertTemplate{})).To(Succeed())
+		})
+
+		It("should delete the GlobalAlerts that run disabled anomaly detectors", func() {
+			for name, detector := range map[string]string{"my-dga": "dga", "my-port-scan": "port_scan"} {
+				Expect(c.Create(ctx, &v3.GlobalAlert{
+					ObjectMeta: metav1.ObjectMeta{Name: name},
+					Spec: v3.GlobalAlertSpec{
+						Type:        v3.GlobalAlertTypeAnomalyDetection,
+						Description: name,
+						Severity:    100,
+						Detector:    &v3.DetectorParams{Name: detector},
+					},
+				})).To(Succeed())
+			}
+			Expect(c.Create(ctx, &v3.GlobalAlert{
+				ObjectMeta: metav1.ObjectMeta{Name: "rule-based"},
+				Spec:       v3.GlobalAlertSpec{Description: "rule based", Severity: 50, DataSet: "flows"},
+			})).To(Succeed())
+
+			ids := &operatorv1.IntrusionDetection{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, ids)).To(Succeed())
+			ids.Spec.AnomalyDetection = &operatorv1.AnomalyDetectionSpec{
+				Detectors: []operatorv1.AnomalyDetector{{Name: "dga"}},
+			}
+			Expect(c.Update(ctx, ids)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "my-dga"}, &v3.GlobalAlert{})).To(Succeed())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "rule-based"}, &v3.GlobalAlert{})).To(Succeed())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "my-port-scan"}, &v3.GlobalAlert{})).NotTo(Succeed())
+		})
+
+		It("should reject anomaly detector settings that are not configurable", func() {
+			high := operatorv1.AnomalyDetectorSensitivityHigh
+			ids := &operatorv1.IntrusionDetection{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, ids)).To(Succeed())
+			ids.Spec.AnomalyDetection = &operatorv1.AnomalyDetectionSpec{
+				Detectors: []operatorv1.AnomalyDetector{{Name: "dga", Sensitivity: &high}},
+			}
+			Expect(c.Update(ctx, ids)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError("the sensitivity of detector dga is not configurable"))
+
+			By("rejecting training periods shorter than a minute")
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, ids)).To(Succeed())
+			ids.Spec.AnomalyDetection = &operatorv1.AnomalyDetectionSpec{
+				TrainingPeriod: &metav1.Duration{Duration: 30 * time.Second},
+			}
+			Expect(c.Update(ctx, ids)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError("training period 30s is shorter than a minute"))
+		})
+
+		It("should reject user defined GlobalAlertTemplates that are invalid", func() {
+			Expect(c.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "user-alert-templates", Namespace: common.OperatorNamespace()},
+				Data: map[string]string{
+					"builtin.yaml": "metadata:\n  name: policy.pod\nspec:\n  description: clash\n  severity: 10\n  dataSet: audit\n",
+				},
+			})).To(Succeed())
+			ids := &operatorv1.IntrusionDetection{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, ids)).To(Succeed())
+			ids.Spec.GlobalAlertTemplatesConfigMapName = "user-alert-templates"
+			Expect(c.Update(ctx, ids)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("has the name of a built-in template: policy.pod")))
+
+			By("rejecting templates without a severity")
+			cm := &corev1.ConfigMap{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "user-alert-templates", Namespace: common.OperatorNamespace()}, cm)).To(Succeed())
+			cm.Data = map[string]string{"user.yaml": "metadata:\n  name: user.template\nspec:\n  description: no severity\n  dataSet: audit\n"}
+			Expect(c.Update(ctx, cm)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("spec.severity must be between 1 and 100")))
+		})
+	})
+
+	Context("threat feeds", func() {
+		BeforeEach(func() {
+			mockStatus.On("SetDegraded", mock.Anything, mock.Anything).Return()
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator"}})).NotTo(HaveOccurred())
+		})
+
+		setThreatFeeds := func(feeds ...operatorv1.ThreatFeed) {
+			ids := &operatorv1.IntrusionDetection{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, ids)).To(Succeed())
+			ids.Spec.ThreatFeeds = feeds
+			Expect(c.Update(ctx, ids)).To(Succeed())
+		}
+
+		It("should create GlobalThreatFeeds for the threat feeds and delete removed ones", func() {
+			Expect(c.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "feeds", Namespace: common.OperatorNamespace()},
+				Data:       map[string]string{"blocklist": "1.2.3.4\n"},
+			})).To(Succeed())
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "feed-token", Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{"token": []byte("secret")},
+			})).To(Succeed())
+			domains := operatorv1.ThreatFeedContentDomainNameSet
+			setThreatFeeds(
+				operatorv1.ThreatFeed{
+					Name:             "remote",
+					URL:              "https://feeds.example.com/ips",
+					PullPeriod:       &metav1.Duration{Duration: time.Hour},
+					Headers:          []operatorv1.ThreatFeedHeader{{Name: "Authorization", SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "feed-token"}, Key: "token"}}},
+					GlobalNetworkSet: &operatorv1.ThreatFeedGlobalNetworkSet{Labels: map[string]string{"threat": "remote"}},
+				},
+				operatorv1.ThreatFeed{
+					Name:      "local",
+					Content:   &domains,
+					ConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "feeds"}, Key: "blocklist"},
+				},
+			)
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			remote := &v3.GlobalThreatFeed{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "remote"}, remote)).To(Succeed())
+			Expect(remote.Labels).To(HaveKey(render.ThreatFeedLabel))
+			Expect(remote.Spec.Content).To(Equal(v3.ThreatFeedContentIPset))
+			Expect(remote.Spec.Pull.Period).To(Equal("1h0m0s"))
+			Expect(remote.Spec.Pull.HTTP.URL).To(Equal("https://feeds.example.com/ips"))
+			Expect(remote.Spec.Pull.HTTP.Headers[0].ValueFrom.SecretKeyRef.Name).To(Equal("feed-token"))
+			Expect(remote.Spec.GlobalNetworkSet.Labels).To(Equal(map[string]string{"threat": "remote"}))
+			Expect(c.Get(ctx, client.ObjectKey{Name: "feed-token", Namespace: render.IntrusionDetectionNamespace}, &corev1.Secret{})).To(Succeed())
+
+			local := &v3.GlobalThreatFeed{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "local"}, local)).To(Succeed())
+			Expect(local.Spec.Content).To(Equal(v3.ThreatFeedContentDomainNameSet))
+			Expect(local.Spec.Pull.HTTP.URL).To(Equal("http://127.0.0.1:8089/local"))
+			Expect(c.Get(ctx, client.ObjectKey{Name: "feeds", Namespace: render.IntrusionDetectionNamespace}, &corev1.ConfigMap{})).To(Succeed())
+
+			By("deleting the GlobalThreatFeeds of removed feeds")
+			setThreatFeeds(operatorv1.ThreatFeed{Name: "remote", URL: "https://feeds.example.com/ips"})
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "remote"}, remote)).To(Succeed())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "local"}, local)).NotTo(Succeed())
+
+			By("deleting the copies of the ConfigMaps and secrets that no feed reads from")
+			Expect(c.Get(ctx, client.ObjectKey{Name: "feeds", Namespace: render.IntrusionDetectionNamespace}, &corev1.ConfigMap{})).NotTo(Succeed())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "feed-token", Namespace: render.IntrusionDetectionNamespace}, &corev1.Secret{})).NotTo(Succeed())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "feeds", Namespace: common.OperatorNamespace()}, &corev1.ConfigMap{})).To(Succeed())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "feed-token", Namespace: common.OperatorNamespace()}, &corev1.Secret{})).To(Succeed())
+		})
+
+		It("should report the state of each threat feed without degrading intrusion detection", func() {
+			r.client = statusPreservingClient{c}
+			setThreatFeeds(
+				operatorv1.ThreatFeed{Name: "remote", URL: "https://feeds.example.com/ips"},
+				operatorv1.ThreatFeed{Name: "other", URL: "https://feeds.example.com/domains"},
+			)
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			gtf := &v3.GlobalThreatFeed{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "remote"}, gtf)).To(Succeed())
+			gtf.Status.ErrorConditions = []v3.ErrorCondition{{Type: "PullFailed", Message: "404 Not Found"}}
+			Expect(c.Update(ctx, gtf)).To(Succeed())
+			now := metav1.Now()
+			other := &v3.GlobalThreatFeed{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "other"}, other)).To(Succeed())
+			other.Status.LastSuccessfulSync = &now
+			Expect(c.Update(ctx, other)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			mockStatus.AssertNotCalled(GinkgoT(), "SetDegraded", mock.MatchedBy(func(reason string) bool {
+				return strings.Contains(reason, "Threat feeds")
+			}), mock.Anything)
+			mockStatus.AssertCalled(GinkgoT(), "SetThreatFeedErrors", map[string][]string{"remote": {"PullFailed: 404 Not Found"}})
+
+			ids := &operatorv1.IntrusionDetection{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, ids)).To(Succeed())
+			Expect(ids.Status.State).To(Equal(operatorv1.TigeraStatusReady))
+			Expect(ids.Status.ThreatFeeds).To(HaveLen(2))
+			Expect(ids.Status.ThreatFeeds[0]).To(Equal(operatorv1.ThreatFeedStatus{Name: "remote", Errors: []string{"PullFailed: 404 Not Found"}}))
+			Expect(ids.Status.ThreatFeeds[1].Name).To(Equal("other"))
+			Expect(ids.Status.ThreatFeeds[1].Errors).To(BeEmpty())
+			Expect(ids.Status.ThreatFeeds[1].LastSuccessfulSync).NotTo(BeNil())
+		})
+
+		It("should only watch the ConfigMaps and secrets that the threat feeds and alert templates read from", func() {
+			ids := &operatorv1.IntrusionDetection{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, ids)).To(Succeed())
+			ids.Spec.GlobalAlertTemplatesConfigMapName = "user-alert-templates"
+			Expect(c.Update(ctx, ids)).To(Succeed())
+			setThreatFeeds(
+				operatorv1.ThreatFeed{
+					Name: "remote", URL: "https://feeds.example.com/ips",
+					Headers: []operatorv1.ThreatFeedHeader{{
+						Name:         "Authorization",
+						SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "feed-token"}, Key: "token"},
+					}},
+				},
+				operatorv1.ThreatFeed{
+					Name:      "local",
+					ConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "feeds"}, Key: "blocklist"},
+				},
+			)
+
+			configMap := func(name, namespace string) client.Object {
+				return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+			}
+			secret := func(name, namespace string) client.Object {
+				return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+			}
+			Expect(isReferencedSource(c, configMap("feeds", common.OperatorNamespace()))).To(BeTrue())
+			Expect(isReferencedSource(c, configMap("user-alert-templates", common.OperatorNamespace()))).To(BeTrue())
+			Expect(isReferencedSource(c, secret("feed-token", common.OperatorNamespace()))).To(BeTrue())
+			Expect(isReferencedSource(c, configMap("feeds", render.IntrusionDetectionNamespace))).To(BeFalse())
+			Expect(isReferencedSource(c, configMap("other", common.OperatorNamespace()))).To(BeFalse())
+			Expect(isReferencedSource(c, secret("feeds", common.OperatorNamespace()))).To(BeFalse())
+			Expect(isReferencedSource(c, configMap("feed-token", common.OperatorNamespace()))).To(BeFalse())
+		})
+
+		It("should reject invalid threat feeds", func() {
+			setThreatFeeds(operatorv1.ThreatFeed{Name: "Remote.Feed", URL: "https://feeds.example.com/ips"})
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("threat feed Remote.Feed has an invalid name")))
+
+			setThreatFeeds(operatorv1.ThreatFeed{Name: strings.Repeat("a", 51), URL: "https://feeds.example.com/ips"})
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("has a name longer than 50 characters")))
+
+			setThreatFeeds(operatorv1.ThreatFeed{Name: "remote", URL: "ftp://feeds.example.com/ips"})
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("it must be an HTTP or HTTPS URL")))
+
+			setThreatFeeds(operatorv1.ThreatFeed{Name: "remote", URL: "https://feeds.example.com/ips", PullPeriod: &metav1.Duration{Duration: time.Minute}})
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("it must be at least 5m0s")))
+
+			setThreatFeeds(operatorv1.ThreatFeed{Name: "local", ConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}, Key: "feed"}})
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError(ContainSubstring("failed to read ConfigMap missing of threat feed local")))
+		})
+	})
+
+	Context("deep packet inspection", func() {
+		BeforeEach(func() {
+			mockStatus.On("SetDegraded", mock.Anything, mock.Anything).Return()
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator"}})).NotTo(HaveOccurred())
+		})
+
+		setDPI := func(spec *operatorv1.DeepPacketInspectionSpec) {
+			ids := &operatorv1.IntrusionDetection{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, ids)).To(Succeed())
+			ids.Spec.DeepPacketInspection = spec
+			Expect(c.Update(ctx, ids)).To(Succeed())
+		}
+
+		It("should render the node pools and track them in their own status", func() {
+			setDPI(&operatorv1.DeepPacketInspectionSpec{
+				NodePools: []operatorv1.DeepPacketInspectionNodePool{
+					{Name: "large", NodeSelector: map[string]string{"pool": "large"}},
+				},
+			})
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			ds := &appsv1.DaemonSet{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: dpi.DeepPacketInspectionName, Namespace: dpi.DeepPacketInspectionNamespace}, ds)).To(Succeed())
+			pool := &appsv1.DaemonSet{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-dpi-large", Namespace: dpi.DeepPacketInspectionNamespace}, pool)).To(Succeed())
+			Expect(pool.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue("pool", "large"))
+			Expect(pool.Spec.Template.Spec.Containers[0].Resources).To(Equal(ds.Spec.Template.Spec.Containers[0].Resources))
+
+			mockDPIStatus.AssertCalled(GinkgoT(), "OnCRFound")
+			mockDPIStatus.AssertCalled(GinkgoT(), "ClearDegraded")
+			for _, call := range mockStatus.Calls {
+				if call.Method == "AddDaemonsets" {
+					Expect(call.Arguments.Get(0)).To(BeEmpty())
+				}
+			}
+
+			By("deleting the DaemonSets of removed node pools")
+			setDPI(nil)
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-dpi-large", Namespace: dpi.DeepPacketInspectionNamespace}, pool)).NotTo(Succeed())
+		})
+
+		It("should only degrade deep packet inspection when its configuration is invalid", func() {
+			mockDPIStatus.On("SetDegraded", "Invalid deep packet inspection configuration", mock.Anything).Return()
+			setDPI(&operatorv1.DeepPacketInspectionSpec{
+				NodePools: []operatorv1.DeepPacketInspectionNodePool{
+					{Name: "large", NodeSelector: map[string]string{"pool": "large"}},
+					{Name: "large", NodeSelector: map[string]string{"pool": "larger"}},
+				},
+			})
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			mockDPIStatus.AssertCalled(GinkgoT(), "SetDegraded", "Invalid deep packet inspection configuration", "node pool large is defined more than once")
+			mockStatus.AssertCalled(GinkgoT(), "ClearDegraded")
+			Expect(c.Get(ctx, client.ObjectKey{Name: dpi.DeepPacketInspectionName, Namespace: dpi.DeepPacketInspectionNamespace}, &appsv1.DaemonSet{})).NotTo(Succeed())
+		})
+	})
 })
+
+// statusPreservingClient keeps the status of GlobalThreatFeeds when they are updated, like the API server does for
+// resources with a status subresource.
+type statusPreservingClient struct {
+	client.Client
+}
+
+func (c statusPreservingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if f, ok := obj.(*v3.GlobalThreatFeed); ok {
+		cur := &v3.GlobalThreatFeed{}
+		if err := c.Client.Get(ctx, client.ObjectKeyFromObject(f), cur); err == nil {
+			f.Status = cur.Status
+		}
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
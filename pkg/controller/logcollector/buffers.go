@@ -0,0 +1,253 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/monitor"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+const (
+	// bufferSpaceMetric is the percentage of the total limit of the buffer of an output that is available.
+	bufferSpaceMetric = "fluentd_output_status_buffer_available_space_ratio"
+
+	// bufferUsageCheckInterval is how often the usage of the buffers is checked when a threshold is configured.
+	bufferUsageCheckInterval = time.Minute
+	// bufferUsageCheckTimeout bounds a check of the usage of the buffers of all the fluentd pods.
+	bufferUsageCheckTimeout = 30 * time.Second
+	// maxConcurrentScrapes is how many fluentd pods are scraped at once.
+	maxConcurrentScrapes = 20
+)
+
+// bufferUsageFunc returns the highest usage of the buffers of the outputs of a fluentd pod, as a percentage of their
+// total limit.
+type bufferUsageFunc func(ctx context.Context, tlsConfig *tls.Config, pod *corev1.Pod) (float64, error)
+
+// fluentdMetricsTLSConfig returns the TLS configuration to scrape the metrics of fluentd. The operator authenticates
+// with the client certificate of Prometheus, which it issued. Nil if the private key of that certificate is not
+// available to the operator, e.g. when the certificates are signed by the cluster.
+func fluentdMetricsTLSConfig(ctx context.Context, cli client.Client, ca, serverTLS certificatemanagement.CertificateInterface) (*tls.Config, error) {
+	secret := &corev1.Secret{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: monitor.PrometheusClientTLSSecretName, Namespace: common.OperatorNamespace()}, secret); err != nil {
+		return nil, err
+	}
+	if len(secret.Data[corev1.TLSCertKey]) == 0 || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		return nil, nil
+	}
+	cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.GetCertificatePEM())
+	// A certificate provided by the user may be self-signed.
+	roots.AppendCertsFromPEM(serverTLS.GetCertificatePEM())
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      roots,
+		ServerName:   render.FluentdPrometheusTLSSecretName,
+	}, nil
+}
+
+// scrapeFluentdBufferUsage scrapes the metrics of a fluentd pod for the usage of its buffers.
+func scrapeFluentdBufferUsage(ctx context.Context, tlsConfig *tls.Config, pod *corev1.Pod) (float64, error) {
+	h := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+	url := fmt.Sprintf("https://%s/metrics", net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(render.FluentdMetricsPortNumber)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := h.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+	return parseBufferUsage(resp.Body)
+}
+
+// parseBufferUsage returns the highest buffer usage in metrics of the Prometheus text format.
+func parseBufferUsage(r io.Reader) (float64, error) {
+	var usage float64
+	found := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, bufferSpaceMetric+"{") && !strings.HasPrefix(line, bufferSpaceMetric+" ") {
+			continue
+		}
+		// The value follows the labels, which may contain spaces, and may be followed by a timestamp.
+		fields := strings.Fields(line[strings.LastIndex(line, "}")+1:])
+		if len(fields) == 0 {
+			return 0, fmt.Errorf("metric %q has no value", line)
+		}
+		available, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return 0, fmt.Errorf("metric %q has an invalid value: %w", line, err)
+		}
+		if u := 100 - available; !found || u > usage {
+			usage = u
+		}
+		found = true
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, fmt.Errorf("fluentd does not expose %s", bufferSpaceMetric)
+	}
+	return usage, nil
+}
+
+// bufferChecker checks the usage of the fluentd buffers in the background, so that slow or unreachable fluentd pods
+// don't hold up the reconciliation of the LogCollector. Reconcile reports the result of the last check.
+type bufferChecker struct {
+	client client.Client
+	usage  bufferUsageFunc
+
+	mu      sync.Mutex
+	running sync.WaitGroup
+	busy    bool
+	started time.Time
+	last    *bufferCheck
+}
+
+// bufferCheck is the result of a check of the usage of the fluentd buffers.
+type bufferCheck struct {
+	// usage is the usage of the buffers of the fluentd pod of each node that was scraped, in the order of the pods.
+	usage []nodeBufferUsage
+	// unscraped are the errors scraping the pods whose buffer usage is unknown, e.g. because a network policy blocks
+	// the operator.
+	unscraped []string
+	err       error
+}
+
+type nodeBufferUsage struct {
+	node  string
+	usage float64
+}
+
+func newBufferChecker(cli client.Client, usage bufferUsageFunc) *bufferChecker {
+	return &bufferChecker{client: cli, usage: usage}
+}
+
+// check returns the result of the last check, nil if there is none yet, and starts a new check in the background if
+// none is running and the last one started at least bufferUsageCheckInterval ago.
+func (b *bufferChecker) check(tlsConfig *tls.Config) *bufferCheck {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.busy && time.Since(b.started) >= bufferUsageCheckInterval {
+		b.busy = true
+		b.started = time.Now()
+		b.running.Add(1)
+		go func() {
+			defer b.running.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), bufferUsageCheckTimeout)
+			defer cancel()
+			result := b.scrape(ctx, tlsConfig)
+
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.last = result
+			b.busy = false
+		}()
+	}
+	return b.last
+}
+
+// wait waits for the running check to finish.
+func (b *bufferChecker) wait() {
+	b.running.Wait()
+}
+
+// scrape scrapes the running fluentd pods concurrently for the usage of their buffers.
+func (b *bufferChecker) scrape(ctx context.Context, tlsConfig *tls.Config) *bufferCheck {
+	pods := &corev1.PodList{}
+	if err := b.client.List(ctx, pods, client.InNamespace(render.LogCollectorNamespace), client.MatchingLabels{"k8s-app": render.FluentdNodeName}); err != nil {
+		return &bufferCheck{err: err}
+	}
+
+	usage := make([]float64, len(pods.Items))
+	errs := make([]error, len(pods.Items))
+	sem := make(chan struct{}, maxConcurrentScrapes)
+	var wg sync.WaitGroup
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, pod *corev1.Pod) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				usage[i], errs[i] = b.usage(ctx, tlsConfig, pod)
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+			}
+		}(i, pod)
+	}
+	wg.Wait()
+
+	result := &bufferCheck{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		if errs[i] != nil {
+			result.unscraped = append(result.unscraped, fmt.Sprintf("%s: %v", pod.Name, errs[i]))
+			continue
+		}
+		result.usage = append(result.usage, nodeBufferUsage{node: pod.Spec.NodeName, usage: usage[i]})
+	}
+	return result
+}
+
+// fullBuffers returns the names of the nodes whose fluentd buffers are fuller than the threshold.
+func (c *bufferCheck) fullBuffers(threshold int32) []string {
+	var nodes []string
+	for _, u := range c.usage {
+		if u.usage > float64(threshold) {
+			nodes = append(nodes, u.node)
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("fluentd buffer usage", func() {
+	It("should return the highest usage of the outputs", func() {
+		usage, err := parseBufferUsage(strings.NewReader(`# HELP fluentd_output_status_buffer_available_space_ratio Ratio of available space in buffer.
+# TYPE fluentd_output_status_buffer_available_space_ratio gauge
+fluentd_output_status_buffer_available_space_ratio{hostname="node-a",plugin_id="es flows",type="elasticsearch"} 87.5
+fluentd_output_status_buffer_available_space_ratio{hostname="node-a",plugin_id="s3",type="s3"} 12.5 1650000000000
+fluentd_output_status_buffer_total_bytes{hostname="node-a",plugin_id="s3",type="s3"} 1.2e+08
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(usage).To(Equal(87.5))
+	})
+
+	It("should fail without buffer metrics", func() {
+		_, err := parseBufferUsage(strings.NewReader("fluentd_output_status_retry_count{plugin_id=\"s3\"} 0\n"))
+		Expect(err).To(MatchError("fluentd does not expose fluentd_output_status_buffer_available_space_ratio"))
+	})
+
+	It("should fail on invalid values", func() {
+		_, err := parseBufferUsage(strings.NewReader("fluentd_output_status_buffer_available_space_ratio{plugin_id=\"s3\"} full\n"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -44,6 +44,7 @@ import (
 	"github.com/tigera/operator/pkg/render"
 	rcertificatemanagement "github.com/tigera/operator/pkg/render/certificatemanagement"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	"github.com/tigera/operator/pkg/render/monitor"
 	"github.com/tigera/operator/pkg/url"
@@ -60,9 +61,10 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	}
 
 	licenseAPIReady := &utils.ReadyFlag{}
+	tierWatchReady := &utils.ReadyFlag{}
 
 	// create the reconciler
-	reconciler := newReconciler(mgr, opts, licenseAPIReady)
+	reconciler := newReconciler(mgr, opts, licenseAPIReady, tierWatchReady)
 
 	// Create a new controller
 	controller, err := controller.New("logcollector-controller", mgr, controller.Options{Reconciler: reconcile.Reconciler(reconciler)})
@@ -77,12 +79,13 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	}
 
 	go utils.WaitToAddLicenseKeyWatch(controller, k8sClient, log, licenseAPIReady)
+	go utils.WaitToAddTierWatch(controller, k8sClient, log, tierWatchReady)
 
 	return add(mgr, controller)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady *utils.ReadyFlag) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady *utils.ReadyFlag, tierWatchReady *utils.ReadyFlag) reconcile.Reconciler {
 	c := &ReconcileLogCollector{
 		client:          mgr.GetClient(),
 		scheme:          mgr.GetScheme(),
@@ -90,8 +93,10 @@ func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady
 		status:          status.New(mgr.GetClient(), "log-collector", opts.KubernetesVersion),
 		clusterDomain:   opts.ClusterDomain,
 		licenseAPIReady: licenseAPIReady,
+		tierWatchReady:  tierWatchReady,
 		usePSP:          opts.UsePSP,
 	}
+	c.buffers = newBufferChecker(c.client, scrapeFluentdBufferUsage)
 	c.status.Run(opts.ShutdownContext)
 	return c
 }
@@ -158,7 +163,9 @@ type ReconcileLogCollector struct {
 	status          status.StatusManager
 	clusterDomain   string
 	licenseAPIReady *utils.ReadyFlag
+	tierWatchReady  *utils.ReadyFlag
 	usePSP          bool
+	buffers         *bufferChecker
 }
 
 // GetLogCollector returns the default LogCollector instance with defaults populated.
@@ -442,6 +449,16 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 		r.status.SetDegraded("Error retrieving Fluentd filters", err.Error())
 		return reconcile.Result{}, err
 	}
+	if err := render.ValidateLogCollectorFilters(instance.Spec.Filters); err != nil {
+		log.Error(err, "Invalid LogCollector filters")
+		r.status.SetDegraded("Invalid LogCollector filters", err.Error())
+		return reconcile.Result{}, err
+	}
+	if err := render.ValidateLogCollectorBuffer(instance.Spec.Buffer); err != nil {
+		log.Error(err, "Invalid LogCollector buffer")
+		r.status.SetDegraded("Invalid LogCollector buffer", err.Error())
+		return reconcile.Result{}, err
+	}
 
 	var eksConfig *render.EksCloudwatchLogConfig
 	if installation.KubernetesProvider == operatorv1.ProviderEKS {
@@ -463,23 +480,32 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 	}
 
 	// Create a component handler to manage the rendered component.
-	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance)
+	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance, installation)
+
+	networkPolicyState, err := utils.GetNetworkPolicyState(ctx, r.client, r.tierWatchReady, installation, instance.Spec.ComponentNetworkPolicy)
+	if err != nil {
+		reqLogger.Error(err, "Error querying allow-tigera tier")
+		r.status.SetDegraded("Error querying allow-tigera tier", err.Error())
+		return reconcile.Result{}, err
+	}
 
 	fluentdCfg := &render.FluentdConfiguration{
-		LogCollector:     instance,
-		ESSecrets:        esSecrets,
-		ESClusterConfig:  esClusterConfig,
-		S3Credential:     s3Credential,
-		SplkCredential:   splunkCredential,
-		Filters:          filters,
-		EKSConfig:        eksConfig,
-		PullSecrets:      pullSecrets,
-		Installation:     installation,
-		ClusterDomain:    r.clusterDomain,
-		OSType:           rmeta.OSTypeLinux,
-		MetricsServerTLS: fluentdPrometheusTLS,
-		TrustedBundle:    trustedBundle,
-		UsePSP:           r.usePSP,
+		LogCollector:       instance,
+		ESSecrets:          esSecrets,
+		ESClusterConfig:    esClusterConfig,
+		S3Credential:       s3Credential,
+		SplkCredential:     splunkCredential,
+		Filters:            filters,
+		EKSConfig:          eksConfig,
+		PullSecrets:        pullSecrets,
+		Installation:       installation,
+		ClusterDomain:      r.clusterDomain,
+		OSType:             rmeta.OSTypeLinux,
+		MetricsServerTLS:   fluentdPrometheusTLS,
+		TrustedBundle:      trustedBundle,
+		LogLevel:           loglevel.Resolve(installation, instance.Spec.LogLevel),
+		UsePSP:             r.usePSP,
+		NetworkPolicyState: networkPolicyState,
 	}
 	// Render the fluentd component for Linux
 	comp := render.Fluentd(fluentdCfg)
@@ -516,19 +542,21 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 
 	if hasWindowsNodes {
 		fluentdCfg = &render.FluentdConfiguration{
-			LogCollector:    instance,
-			ESSecrets:       esSecrets,
-			ESClusterConfig: esClusterConfig,
-			S3Credential:    s3Credential,
-			SplkCredential:  splunkCredential,
-			Filters:         filters,
-			EKSConfig:       eksConfig,
-			PullSecrets:     pullSecrets,
-			Installation:    installation,
-			ClusterDomain:   r.clusterDomain,
-			OSType:          rmeta.OSTypeWindows,
-			TrustedBundle:   trustedBundle,
-			UsePSP:          r.usePSP,
+			LogCollector:       instance,
+			ESSecrets:          esSecrets,
+			ESClusterConfig:    esClusterConfig,
+			S3Credential:       s3Credential,
+			SplkCredential:     splunkCredential,
+			Filters:            filters,
+			EKSConfig:          eksConfig,
+			PullSecrets:        pullSecrets,
+			Installation:       installation,
+			ClusterDomain:      r.clusterDomain,
+			OSType:             rmeta.OSTypeWindows,
+			TrustedBundle:      trustedBundle,
+			LogLevel:           loglevel.Resolve(installation, instance.Spec.LogLevel),
+			UsePSP:             r.usePSP,
+			NetworkPolicyState: networkPolicyState,
 		}
 		comp = render.Fluentd(fluentdCfg)
 
@@ -539,7 +567,7 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 		}
 
 		// Create a component handler to manage the rendered component.
-		handler = utils.NewComponentHandler(log, r.client, r.scheme, instance)
+		handler = utils.NewComponentHandler(log, r.client, r.scheme, instance, installation)
 
 		if err := handler.CreateOrUpdateOrDelete(ctx, comp, r.status); err != nil {
 			r.status.SetDegraded("Error creating / updating resource", err.Error())
@@ -547,6 +575,38 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 		}
 	}
 
+	// Check the usage of the buffers last, so that the configuration is rolled out even when fluentd is backed up.
+	var result reconcile.Result
+	if buffer := instance.Spec.Buffer; buffer != nil && buffer.DegradedThresholdPercent != nil {
+		result.RequeueAfter = bufferUsageCheckInterval
+		// Only full buffers degrade the LogCollector. Failing to check them, e.g. because fluentd is unreachable, is
+		// logged and left to the alerts on the fluentd metrics.
+		tlsConfig, err := fluentdMetricsTLSConfig(ctx, r.client, certificateManager.KeyPair(), fluentdPrometheusTLS)
+		if err != nil {
+			log.Error(err, "Error creating the TLS configuration to scrape fluentd, the usage of the fluentd buffers is not checked")
+		} else if tlsConfig == nil {
+			log.Info("The Prometheus client key is not available, the usage of the fluentd buffers is not checked")
+		} else if check := r.buffers.check(tlsConfig); check == nil {
+			// The buffers are checked in the background, come back for the result of the first check.
+			result.RequeueAfter = bufferUsageCheckTimeout
+		} else {
+			// The result of the last check is reported while the next one runs in the background.
+			if check.err != nil {
+				log.Error(check.err, "Error checking the usage of the fluentd buffers")
+			}
+			if len(check.unscraped) != 0 {
+				log.Info("Unable to check the usage of some fluentd buffers", "errors", strings.Join(check.unscraped, "; "))
+			}
+			if nodes := check.fullBuffers(*buffer.DegradedThresholdPercent); len(nodes) != 0 {
+				r.status.SetDegraded(
+					fmt.Sprintf("Fluentd buffers are more than %d%% full", *buffer.DegradedThresholdPercent),
+					fmt.Sprintf("Nodes: %s", strings.Join(nodes, ", ")),
+				)
+				return result, nil
+			}
+		}
+	}
+
 	// Clear the degraded bit if we've reached this far.
 	r.status.ClearDegraded()
 
@@ -561,7 +621,7 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 	if err = r.client.Status().Update(ctx, instance); err != nil {
 		return reconcile.Result{}, err
 	}
-	return reconcile.Result{}, nil
+	return result, nil
 }
 
 func hasWindowsNodes(c client.Client) (bool, error) {
@@ -669,8 +729,10 @@ func getFluentdFilters(client client.Client) (*render.FluentdFilters, error) {
 	}
 
 	return &render.FluentdFilters{
-		Flow: cm.Data[render.FluentdFilterFlowName],
-		DNS:  cm.Data[render.FluentdFilterDNSName],
+		Flow:  cm.Data[render.FluentdFilterFlowName],
+		DNS:   cm.Data[render.FluentdFilterDNSName],
+		L7:    cm.Data[render.FluentdFilterL7Name],
+		Audit: cm.Data[render.FluentdFilterAuditName],
 	}, nil
 }
 
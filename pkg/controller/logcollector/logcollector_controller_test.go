@@ -16,7 +16,9 @@ package logcollector
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -72,6 +74,7 @@ var _ = Describe("LogCollector controller tests", func() {
 			mockStatus.On("AddDeployments", mock.Anything).Return()
 			mockStatus.On("AddStatefulSets", mock.Anything).Return()
 			mockStatus.On("AddCronJobs", mock.Anything)
+			mockStatus.On("SetPodSecurityRequirements", mock.Anything)
 			mockStatus.On("RemoveCertificateSigningRequests", mock.Anything).Return()
 			mockStatus.On("AddCertificateSigningRequests", mock.Anything).Return()
 			mockStatus.On("IsAvailable").Return(true)
@@ -213,6 +216,124 @@ var _ = Describe("LogCollector controller tests", func() {
 					"sha256:fluentdwindowshash")))
 		})
 
+		It("should not roll out invalid filters", func() {
+			mockStatus.On("SetDegraded", "Invalid LogCollector filters", mock.Anything).Return()
+			lc := &operatorv1.LogCollector{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, lc)).To(Succeed())
+			lc.Spec.Filters = &operatorv1.LogCollectorFilters{
+				L7: &operatorv1.LogFilter{IncludeLabels: map[string]string{"app": "web"}},
+			}
+			Expect(c.Update(ctx, lc)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError("invalid l7 filter: l7 logs cannot be filtered by labels"))
+			Expect(test.GetResource(c, &appsv1.DaemonSet{
+				TypeMeta:   metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "fluentd-node", Namespace: render.LogCollectorNamespace},
+			})).NotTo(BeNil())
+		})
+
+		Context("buffer usage", func() {
+			var usage map[string]float64
+
+			BeforeEach(func() {
+				usage = map[string]float64{}
+				r.buffers = newBufferChecker(c, func(_ context.Context, tlsConfig *tls.Config, pod *corev1.Pod) (float64, error) {
+					Expect(tlsConfig.Certificates).To(HaveLen(1))
+					Expect(tlsConfig.ServerName).To(Equal(render.FluentdPrometheusTLSSecretName))
+					u, ok := usage[pod.Name]
+					if !ok {
+						return 0, fmt.Errorf("no metrics")
+					}
+					return u, nil
+				})
+				for _, name := range []string{"fluentd-node-a", "fluentd-node-b", "fluentd-node-c"} {
+					Expect(c.Create(ctx, &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      name,
+							Namespace: render.LogCollectorNamespace,
+							Labels:    map[string]string{"k8s-app": render.FluentdNodeName},
+						},
+						Spec:   corev1.PodSpec{NodeName: "node-" + name[len(name)-1:]},
+						Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1"},
+					})).To(Succeed())
+				}
+				lc := &operatorv1.LogCollector{}
+				Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, lc)).To(Succeed())
+				threshold := int32(80)
+				lc.Spec.Buffer = &operatorv1.FluentdBufferSpec{DegradedThresholdPercent: &threshold}
+				Expect(c.Update(ctx, lc)).To(Succeed())
+			})
+
+			// reconcileAfterCheck reconciles to start a check of the buffers in the background, waits for it and reconciles
+			// again to report its result.
+			reconcileAfterCheck := func() reconcile.Result {
+				result, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(bufferUsageCheckTimeout))
+				r.buffers.wait()
+				result, err = r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).ShouldNot(HaveOccurred())
+				return result
+			}
+
+			It("should be degraded when buffers exceed the threshold", func() {
+				usage["fluentd-node-a"] = 95
+				usage["fluentd-node-b"] = 10
+				usage["fluentd-node-c"] = 10
+				mockStatus.On("SetDegraded", "Fluentd buffers are more than 80% full", "Nodes: node-a").Return()
+
+				result := reconcileAfterCheck()
+				Expect(result.RequeueAfter).To(Equal(bufferUsageCheckInterval))
+				mockStatus.AssertCalled(GinkgoT(), "SetDegraded", "Fluentd buffers are more than 80% full", "Nodes: node-a")
+				// Only the reconcile before the first check clears the degraded bit.
+				mockStatus.AssertNumberOfCalls(GinkgoT(), "ClearDegraded", 1)
+			})
+
+			It("should not be degraded when buffers are below the threshold", func() {
+				usage["fluentd-node-a"] = 80
+				usage["fluentd-node-b"] = 10
+				usage["fluentd-node-c"] = 10
+
+				result := reconcileAfterCheck()
+				Expect(result.RequeueAfter).To(Equal(bufferUsageCheckInterval))
+				mockStatus.AssertCalled(GinkgoT(), "ClearDegraded")
+			})
+
+			It("should not be degraded when the buffer usage of a pod cannot be checked", func() {
+				usage["fluentd-node-a"] = 10
+				usage["fluentd-node-b"] = 10
+
+				result := reconcileAfterCheck()
+				Expect(result.RequeueAfter).To(Equal(bufferUsageCheckInterval))
+				mockStatus.AssertNotCalled(GinkgoT(), "SetDegraded", mock.Anything, mock.Anything)
+				mockStatus.AssertNumberOfCalls(GinkgoT(), "ClearDegraded", 2)
+			})
+
+			It("should still be degraded by the full buffers of the pods that were checked", func() {
+				usage["fluentd-node-a"] = 95
+				mockStatus.On("SetDegraded", "Fluentd buffers are more than 80% full", "Nodes: node-a").Return()
+
+				reconcileAfterCheck()
+				mockStatus.AssertCalled(GinkgoT(), "SetDegraded", "Fluentd buffers are more than 80% full", "Nodes: node-a")
+			})
+		})
+
+		It("should not roll out an invalid buffer configuration", func() {
+			mockStatus.On("SetDegraded", "Invalid LogCollector buffer", mock.Anything).Return()
+			lc := &operatorv1.LogCollector{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-secure"}, lc)).To(Succeed())
+			retryForever := true
+			lc.Spec.Buffer = &operatorv1.FluentdBufferSpec{
+				RetryForever: &retryForever,
+				RetryTimeout: &metav1.Duration{Duration: time.Hour},
+			}
+			Expect(c.Update(ctx, lc)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(MatchError("retryTimeout cannot be set when retryForever is true"))
+		})
+
 		Context("Forward to S3", func() {
 
 			var s3Vars = []corev1.EnvVar{
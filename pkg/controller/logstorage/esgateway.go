@@ -30,6 +30,7 @@ import (
 	"github.com/tigera/operator/pkg/controller/utils"
 	"github.com/tigera/operator/pkg/controller/utils/imageset"
 	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	"github.com/tigera/operator/pkg/render/logstorage/esgateway"
 )
 
@@ -39,6 +40,8 @@ func (r *ReconcileLogStorage) createEsGateway(
 	pullSecrets []*corev1.Secret,
 	esAdminUserSecret *corev1.Secret,
 	hdler utils.ComponentHandler,
+	networkPolicyState networkpolicy.State,
+	autoscalingSpec *operatorv1.Autoscaling,
 	reqLogger logr.Logger,
 	ctx context.Context,
 	certificateManager certificatemanager.CertificateManager,
@@ -100,6 +103,8 @@ func (r *ReconcileLogStorage) createEsGateway(
 		ClusterDomain:              r.clusterDomain,
 		EsAdminUserName:            esAdminUserName,
 		ESGatewayKeyPair:           gatewayKeyPair,
+		NetworkPolicyState:         networkPolicyState,
+		Autoscaling:                autoscalingSpec,
 	}
 
 	esGatewayComponent := esgateway.EsGateway(cfg)
@@ -28,6 +28,7 @@ import (
 	"github.com/tigera/operator/pkg/dns"
 	"github.com/tigera/operator/pkg/render"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	"github.com/tigera/operator/pkg/render/kubecontrollers"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -40,6 +41,7 @@ func (r *ReconcileLogStorage) createEsKubeControllers(
 	managementCluster *operatorv1.ManagementCluster,
 	authentication *operatorv1.Authentication,
 	esLicenseType render.ElasticsearchLicenseType,
+	networkPolicyState networkpolicy.State,
 	ctx context.Context,
 ) (reconcile.Result, bool, error) {
 	kubeControllersUserSecret, err := utils.GetSecret(ctx, r.client, kubecontrollers.ElasticsearchKubeControllersUserSecret, common.OperatorNamespace())
@@ -90,6 +92,7 @@ func (r *ReconcileLogStorage) createEsKubeControllers(
 		KubeControllersGatewaySecret: kubeControllersUserSecret,
 		LogStorageExists:             true,
 		TrustedBundle:                trustedBundle,
+		NetworkPolicyState:           networkPolicyState,
 	}
 	esKubeControllerComponents := kubecontrollers.NewElasticsearchKubeControllers(&kubeControllersCfg)
 
@@ -32,6 +32,7 @@ import (
 	"github.com/tigera/operator/pkg/render"
 	rcertificatemanagement "github.com/tigera/operator/pkg/render/certificatemanagement"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	"github.com/tigera/operator/pkg/render/logstorage/esmetrics"
 	"github.com/tigera/operator/pkg/render/monitor"
 )
@@ -44,6 +45,7 @@ func (r *ReconcileLogStorage) createEsMetrics(
 	clusterConfig *relasticsearch.ClusterConfig,
 	ctx context.Context,
 	hdler utils.ComponentHandler,
+	networkPolicyState networkpolicy.State,
 	clusterDomain string,
 ) (reconcile.Result, bool, error) {
 	esMetricsSecret, err := utils.GetSecret(context.Background(), r.client, esmetrics.ElasticsearchMetricsSecret, common.OperatorNamespace())
@@ -104,6 +106,7 @@ func (r *ReconcileLogStorage) createEsMetrics(
 		ClusterDomain:        r.clusterDomain,
 		ServerTLS:            serverTLS,
 		TrustedBundle:        trustedBundle,
+		NetworkPolicyState:   networkPolicyState,
 	}
 	esMetricsComponent := esmetrics.ElasticsearchMetrics(esMetricsCfg)
 	components := []render.Component{esMetricsComponent,
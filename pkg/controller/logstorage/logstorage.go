@@ -46,6 +46,7 @@ import (
 	"github.com/tigera/operator/pkg/render"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 )
 
 // createLogStorage Is called by Reconcile() in the Logstorage controller to render its components
@@ -71,6 +72,7 @@ func (r *ReconcileLogStorage) createLogStorage(
 	pullSecrets []*corev1.Secret,
 	authentication *operatorv1.Authentication,
 	hdler utils.ComponentHandler,
+	networkPolicyState networkpolicy.State,
 	reqLogger logr.Logger,
 	ctx context.Context,
 	certificateManager certificatemanager.CertificateManager,
@@ -110,6 +112,16 @@ func (r *ReconcileLogStorage) createLogStorage(
 		trustedBundle = certificateManager.CreateTrustedBundle(elasticKeyPair, kibanaKeyPair)
 	}
 
+	var snapshotS3Credential *render.S3Credential
+	if managementClusterConnection == nil && ls.DeletionTimestamp == nil &&
+		ls.Spec.ElasticsearchSnapshots != nil && ls.Spec.ElasticsearchSnapshots.Repository.S3 != nil {
+		if snapshotS3Credential, err = getSnapshotS3Credential(ctx, r.client); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Error with the Elasticsearch snapshot S3 credential secret", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, err
+		}
+	}
+
 	elasticsearch, err := r.getElasticsearch(ctx)
 	if err != nil {
 		reqLogger.Error(err, err.Error())
@@ -182,7 +194,9 @@ func (r *ReconcileLogStorage) createLogStorage(
 		ElasticLicenseType:          esLicenseType,
 		TrustedBundle:               trustedBundle,
 		UnusedTLSSecret:             unusedTLSSecret,
+		SnapshotS3Credential:        snapshotS3Credential,
 		UsePSP:                      r.usePSP,
+		NetworkPolicyState:          networkPolicyState,
 	}
 
 	component := render.LogStorage(logStorageCfg)
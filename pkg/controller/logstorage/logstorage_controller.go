@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -45,6 +46,7 @@ import (
 	"github.com/tigera/operator/pkg/controller/utils"
 	"github.com/tigera/operator/pkg/controller/utils/imageset"
 	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rsecret "github.com/tigera/operator/pkg/render/common/secret"
 	"github.com/tigera/operator/pkg/render/logstorage/esgateway"
@@ -72,19 +74,20 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		return err
 	}
 
-	return add(mgr, r)
+	return add(mgr, r, r.tierWatchReady)
 }
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(cli client.Client, schema *runtime.Scheme, statusMgr status.StatusManager, opts options.AddOptions, esCliCreator utils.ElasticsearchClientCreator) (*ReconcileLogStorage, error) {
 	c := &ReconcileLogStorage{
-		client:        cli,
-		scheme:        schema,
-		status:        statusMgr,
-		provider:      opts.DetectedProvider,
-		esCliCreator:  esCliCreator,
-		clusterDomain: opts.ClusterDomain,
-		usePSP:        opts.UsePSP,
+		client:         cli,
+		scheme:         schema,
+		status:         statusMgr,
+		provider:       opts.DetectedProvider,
+		esCliCreator:   esCliCreator,
+		clusterDomain:  opts.ClusterDomain,
+		usePSP:         opts.UsePSP,
+		tierWatchReady: &utils.ReadyFlag{},
 	}
 
 	c.status.Run(opts.ShutdownContext)
@@ -92,12 +95,19 @@ func newReconciler(cli client.Client, schema *runtime.Scheme, statusMgr status.S
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, tierWatchReady *utils.ReadyFlag) error {
 	c, err := controller.New("log-storage-controller", mgr, controller.Options{Reconciler: r})
 	if err != nil {
 		return err
 	}
 
+	k8sClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return fmt.Errorf("log-storage-controller failed to establish a connection to k8s: %w", err)
+	}
+
+	go utils.WaitToAddTierWatch(c, k8sClient, log, tierWatchReady)
+
 	// Watch for changes to primary resource LogStorage
 	err = c.Watch(&source.Kind{Type: &operatorv1.LogStorage{}}, &handler.EnqueueRequestForObject{})
 	if err != nil {
@@ -140,6 +150,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	for _, secretName := range []string{
 		render.TigeraElasticsearchGatewaySecret, render.TigeraKibanaCertSecret,
 		render.OIDCSecretName, render.DexObjectName, esmetrics.ElasticsearchMetricsServerTLSSecret,
+		render.ElasticsearchSnapshotS3CredentialSecret,
 	} {
 		if err = utils.AddSecretsWatch(c, secretName, common.OperatorNamespace()); err != nil {
 			return fmt.Errorf("log-storage-controller failed to watch the Secret resource: %w", err)
@@ -187,6 +198,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return fmt.Errorf("log-storage-controller failed to watch primary resource: %w", err)
 	}
 
+	// The Compliance CR can set the retention of compliance reports.
+	err = c.Watch(&source.Kind{Type: &operatorv1.Compliance{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch Compliance resource: %w", err)
+	}
+
 	return nil
 }
 
@@ -197,13 +214,14 @@ var _ reconcile.Reconciler = &ReconcileLogStorage{}
 type ReconcileLogStorage struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client        client.Client
-	scheme        *runtime.Scheme
-	status        status.StatusManager
-	provider      operatorv1.Provider
-	esCliCreator  utils.ElasticsearchClientCreator
-	clusterDomain string
-	usePSP        bool
+	client         client.Client
+	scheme         *runtime.Scheme
+	status         status.StatusManager
+	provider       operatorv1.Provider
+	esCliCreator   utils.ElasticsearchClientCreator
+	clusterDomain  string
+	usePSP         bool
+	tierWatchReady *utils.ReadyFlag
 }
 
 // fillDefaults populates the default values onto an LogStorage object.
@@ -254,6 +272,10 @@ func fillDefaults(opr *operatorv1.LogStorage) {
 		opr.Spec.Nodes = &operatorv1.Nodes{Count: 1}
 	}
 
+	if opr.Spec.ElasticsearchSnapshots != nil {
+		fillSnapshotDefaults(opr.Spec.ElasticsearchSnapshots)
+	}
+
 	if opr.Spec.ComponentResources == nil {
 		limits := corev1.ResourceList{}
 		requests := corev1.ResourceList{}
@@ -306,6 +328,9 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 
 	var preDefaultPatchFrom client.Patch
 
+	// effectiveLS is the LogStorage that Elasticsearch is configured with, which may differ from the CR in settings
+	// taken from other CRs.
+	var effectiveLS *operatorv1.LogStorage
 	ls := &operatorv1.LogStorage{}
 	err := r.client.Get(ctx, utils.DefaultTSEEInstanceKey, ls)
 	if err != nil {
@@ -329,6 +354,15 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 			r.status.SetDegraded("An error occurred while validating LogStorage", err.Error())
 			return reconcile.Result{}, err
 		}
+		if err = autoscaling.Validate(ls.Spec.ESGatewayAutoscaling); err != nil {
+			err = fmt.Errorf("LogStorage spec.ESGatewayAutoscaling is invalid: %w", err)
+			r.status.SetDegraded("An error occurred while validating LogStorage", err.Error())
+			return reconcile.Result{}, err
+		}
+		if err = validateElasticsearchSnapshots(ls.Spec.ElasticsearchSnapshots); err != nil {
+			r.status.SetDegraded("An error occurred while validating LogStorage", err.Error())
+			return reconcile.Result{}, err
+		}
 
 		setLogStorageFinalizer(ls)
 
@@ -338,6 +372,18 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 			r.status.SetDegraded("Failed to write defaults", err.Error())
 			return reconcile.Result{}, err
 		}
+
+		// The retention of compliance reports on the Compliance CR takes precedence. It is not written back to the
+		// LogStorage, so that removing it from the Compliance CR restores the retention of the LogStorage.
+		effectiveLS = ls
+		compliance := &operatorv1.Compliance{}
+		if err := r.client.Get(ctx, utils.DefaultTSEEInstanceKey, compliance); err != nil && !errors.IsNotFound(err) {
+			r.status.SetDegraded("An error occurred while querying Compliance", err.Error())
+			return reconcile.Result{}, err
+		} else if err == nil && compliance.Spec.ReportRetention != nil {
+			effectiveLS = ls.DeepCopy()
+			effectiveLS.Spec.Retention.ComplianceReports = compliance.Spec.ReportRetention
+		}
 	}
 
 	variant, install, err := utils.GetInstallation(context.Background(), r.client)
@@ -449,9 +495,9 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 	// create the ComponentHandler from the managementClusterConnection.
 	var hdler utils.ComponentHandler
 	if ls != nil {
-		hdler = utils.NewComponentHandler(reqLogger, r.client, r.scheme, ls)
+		hdler = utils.NewComponentHandler(reqLogger, r.client, r.scheme, ls, install)
 	} else {
-		hdler = utils.NewComponentHandler(reqLogger, r.client, r.scheme, managementClusterConnection)
+		hdler = utils.NewComponentHandler(reqLogger, r.client, r.scheme, managementClusterConnection, install)
 	}
 
 	authentication, err := utils.GetAuthentication(ctx, r.client)
@@ -468,8 +514,21 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 	}
 	certificateManager.AddToStatusManager(r.status, render.ElasticsearchNamespace)
 
+	var networkPolicyOverride *operatorv1.ComponentNetworkPolicyType
+	var esGatewayAutoscaling *operatorv1.Autoscaling
+	if ls != nil {
+		networkPolicyOverride = ls.Spec.ComponentNetworkPolicy
+		esGatewayAutoscaling = ls.Spec.ESGatewayAutoscaling
+	}
+	networkPolicyState, err := utils.GetNetworkPolicyState(ctx, r.client, r.tierWatchReady, install, networkPolicyOverride)
+	if err != nil {
+		reqLogger.Error(err, "Error querying allow-tigera tier")
+		r.status.SetDegraded("Error querying allow-tigera tier", err.Error())
+		return reconcile.Result{}, err
+	}
+
 	result, proceed, finalizerCleanup, err := r.createLogStorage(
-		ls,
+		effectiveLS,
 		install,
 		variant,
 		clusterConfig,
@@ -483,6 +542,7 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 		pullSecrets,
 		authentication,
 		hdler,
+		networkPolicyState,
 		reqLogger,
 		ctx,
 		certificateManager,
@@ -503,6 +563,7 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 		return result, err
 	}
 
+	var snapshotsResult reconcile.Result
 	if managementClusterConnection == nil {
 		result, proceed, err = r.createEsKubeControllers(
 			install,
@@ -511,6 +572,7 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 			managementCluster,
 			authentication,
 			esLicenseType,
+			networkPolicyState,
 			ctx,
 		)
 		if err != nil || !proceed {
@@ -523,6 +585,8 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 			pullSecrets,
 			esAdminUserSecret,
 			hdler,
+			networkPolicyState,
+			esGatewayAutoscaling,
 			reqLogger,
 			ctx,
 			certificateManager,
@@ -531,11 +595,16 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 			return result, err
 		}
 
-		result, proceed, err = r.applyILMPolicies(ls, reqLogger, ctx)
+		result, proceed, err = r.applyILMPolicies(effectiveLS, reqLogger, ctx)
 		if err != nil || !proceed {
 			return result, err
 		}
 
+		snapshotsResult, proceed, err = r.applySnapshots(ls, reqLogger, ctx)
+		if err != nil || !proceed {
+			return snapshotsResult, err
+		}
+
 		result, proceed, err = r.validateLogStorage(curatorSecrets, esLicenseType, reqLogger, ctx)
 		if err != nil || !proceed {
 			return result, err
@@ -549,6 +618,7 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 			clusterConfig,
 			ctx,
 			hdler,
+			networkPolicyState,
 			r.clusterDomain,
 		)
 		if err != nil || !proceed {
@@ -570,7 +640,7 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 		}
 	}
 
-	return reconcile.Result{}, nil
+	return snapshotsResult, nil
 }
 
 func (r *ReconcileLogStorage) getElasticsearch(ctx context.Context) (*esv1.Elasticsearch, error) {
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -90,6 +91,12 @@ func mockEsCliCreator(client client.Client, ctx context.Context, elasticHTTPSEnd
 }
 
 type mockESClient struct {
+	snapshotPolicy       *operatorv1.ElasticsearchSnapshots
+	snapshotPolicyStatus *utils.SnapshotPolicyStatus
+	restores             []string
+	restoreErr           error
+	restoreDone          int
+	restoreTotal         int
 }
 
 var _ = Describe("LogStorage controller", func() {
@@ -234,6 +241,7 @@ var _ = Describe("LogStorage controller", func() {
 						mockStatus.On("AddStatefulSets", mock.Anything).Return()
 						mockStatus.On("RemoveCertificateSigningRequests", mock.Anything).Return()
 						mockStatus.On("AddCronJobs", mock.Anything)
+						mockStatus.On("SetPodSecurityRequirements", mock.Anything)
 						mockStatus.On("OnCRNotFound").Return()
 						mockStatus.On("ClearDegraded")
 						mockStatus.On("ReadyToMonitor")
@@ -279,6 +287,7 @@ var _ = Describe("LogStorage controller", func() {
 						mockStatus.On("AddStatefulSets", mock.Anything).Return()
 						mockStatus.On("RemoveCertificateSigningRequests", mock.Anything).Return()
 						mockStatus.On("AddCronJobs", mock.Anything)
+						mockStatus.On("SetPodSecurityRequirements", mock.Anything)
 						mockStatus.On("ClearDegraded", mock.Anything).Return()
 						mockStatus.On("ReadyToMonitor")
 
@@ -357,6 +366,7 @@ var _ = Describe("LogStorage controller", func() {
 					mockStatus.On("AddStatefulSets", mock.Anything)
 					mockStatus.On("RemoveCertificateSigningRequests", mock.Anything).Return()
 					mockStatus.On("AddCronJobs", mock.Anything)
+					mockStatus.On("SetPodSecurityRequirements", mock.Anything)
 					mockStatus.On("OnCRFound").Return()
 					mockStatus.On("ReadyToMonitor")
 				})
@@ -456,6 +466,254 @@ var _ = Describe("LogStorage controller", func() {
 					mockStatus.AssertExpectations(GinkgoT())
 				})
 
+				It("should take the retention of compliance reports from the Compliance CR", func() {
+					Expect(cli.Create(ctx, &storagev1.StorageClass{
+						ObjectMeta: metav1.ObjectMeta{Name: storageClassName},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &operatorv1.LogStorage{
+						ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+						Spec: operatorv1.LogStorageSpec{
+							Nodes:            &operatorv1.Nodes{Count: int64(1)},
+							StorageClassName: storageClassName,
+						},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: render.ECKOperatorNamespace, Name: render.ECKLicenseConfigMapName},
+						Data:       map[string]string{"eck_license_level": string(render.ElasticsearchLicenseTypeEnterprise)},
+					})).ShouldNot(HaveOccurred())
+					retention := int32(30)
+					Expect(cli.Create(ctx, &operatorv1.Compliance{
+						ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+						Spec:       operatorv1.ComplianceSpec{ReportRetention: &retention},
+					})).ShouldNot(HaveOccurred())
+
+					r, err := NewReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, mockEsCliCreator, dns.DefaultClusterDomain)
+					Expect(err).ShouldNot(HaveOccurred())
+					mockStatus.On("SetDegraded", mock.Anything, mock.Anything).Return()
+					mockStatus.On("ClearDegraded")
+
+					_, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					es := &esv1.Elasticsearch{}
+					Expect(cli.Get(ctx, esObjKey, es)).ShouldNot(HaveOccurred())
+					es.Status.Phase = esv1.ElasticsearchReadyPhase
+					Expect(cli.Update(ctx, es)).ShouldNot(HaveOccurred())
+					kb := &kbv1.Kibana{}
+					Expect(cli.Get(ctx, kbObjKey, kb)).ShouldNot(HaveOccurred())
+					kb.Status.AssociationStatus = cmnv1.AssociationEstablished
+					Expect(cli.Update(ctx, kb)).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchAdminUserSecret, Namespace: render.ElasticsearchNamespace},
+						Data:       map[string][]byte{"elastic": []byte("password")},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: curatorUsrSecretObjMeta})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: esMetricsUsrSecretObjMeta})).ShouldNot(HaveOccurred())
+
+					_, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+
+					curator := &batchv1beta.CronJob{}
+					Expect(cli.Get(ctx, curatorObjKey, curator)).ShouldNot(HaveOccurred())
+					Expect(curator.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+						corev1.EnvVar{Name: "EE_COMPLIANCE_REPORT_INDEX_RETENTION_PERIOD", Value: "30"}))
+
+					By("keeping the retention of the LogStorage CR")
+					ls := &operatorv1.LogStorage{}
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, ls)).ShouldNot(HaveOccurred())
+					Expect(*ls.Spec.Retention.ComplianceReports).To(Equal(int32(91)))
+				})
+
+				It("should configure Elasticsearch snapshots and restore a snapshot", func() {
+					Expect(cli.Create(ctx, &storagev1.StorageClass{
+						ObjectMeta: metav1.ObjectMeta{Name: storageClassName},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &operatorv1.LogStorage{
+						ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+						Spec: operatorv1.LogStorageSpec{
+							Nodes:            &operatorv1.Nodes{Count: int64(1)},
+							StorageClassName: storageClassName,
+							ElasticsearchSnapshots: &operatorv1.ElasticsearchSnapshots{
+								Repository: operatorv1.SnapshotRepository{
+									S3: &operatorv1.S3SnapshotRepository{BucketName: "backups"},
+								},
+							},
+						},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: render.ECKOperatorNamespace, Name: render.ECKLicenseConfigMapName},
+						Data:       map[string]string{"eck_license_level": string(render.ElasticsearchLicenseTypeEnterprise)},
+					})).ShouldNot(HaveOccurred())
+
+					lastSnapshotTime := time.Unix(1650000000, 0)
+					esCli := &mockESClient{
+						snapshotPolicyStatus: &utils.SnapshotPolicyStatus{
+							LastSuccess: &utils.SnapshotInvocation{Snapshot: "tigera-secure-snapshot-1", Time: lastSnapshotTime},
+						},
+					}
+					esCliCreator := func(client.Client, context.Context, string) (utils.ElasticClient, error) {
+						return esCli, nil
+					}
+					r, err := NewReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, esCliCreator, dns.DefaultClusterDomain)
+					Expect(err).ShouldNot(HaveOccurred())
+					mockStatus.On("SetDegraded", mock.Anything, mock.Anything).Return()
+					mockStatus.On("ClearDegraded")
+
+					By("requiring the S3 credential secret")
+					_, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).Should(HaveOccurred())
+					mockStatus.AssertCalled(GinkgoT(), "SetDegraded", "Error with the Elasticsearch snapshot S3 credential secret", mock.Anything)
+
+					Expect(cli.Create(ctx, &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchSnapshotS3CredentialSecret, Namespace: common.OperatorNamespace()},
+						Data: map[string][]byte{
+							render.S3KeyIdName:     []byte("id"),
+							render.S3KeySecretName: []byte("secret"),
+						},
+					})).ShouldNot(HaveOccurred())
+					_, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+
+					es := &esv1.Elasticsearch{}
+					Expect(cli.Get(ctx, esObjKey, es)).ShouldNot(HaveOccurred())
+					Expect(es.Spec.SecureSettings).To(HaveLen(1))
+					Expect(es.Spec.SecureSettings[0].SecretName).To(Equal(render.ElasticsearchSnapshotS3CredentialSecret))
+					Expect(cli.Get(ctx, types.NamespacedName{Name: render.ElasticsearchSnapshotS3CredentialSecret, Namespace: render.ElasticsearchNamespace}, &corev1.Secret{})).ShouldNot(HaveOccurred())
+
+					es.Status.Phase = esv1.ElasticsearchReadyPhase
+					Expect(cli.Update(ctx, es)).ShouldNot(HaveOccurred())
+					kb := &kbv1.Kibana{}
+					Expect(cli.Get(ctx, kbObjKey, kb)).ShouldNot(HaveOccurred())
+					kb.Status.AssociationStatus = cmnv1.AssociationEstablished
+					Expect(cli.Update(ctx, kb)).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchAdminUserSecret, Namespace: render.ElasticsearchNamespace},
+						Data:       map[string][]byte{"elastic": []byte("password")},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: curatorUsrSecretObjMeta})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: esMetricsUsrSecretObjMeta})).ShouldNot(HaveOccurred())
+
+					By("configuring the snapshot policy and reporting the last snapshot")
+					result, err := r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(result.RequeueAfter).To(Equal(snapshotStatusSyncPeriod))
+					Expect(esCli.snapshotPolicy).NotTo(BeNil())
+					Expect(esCli.snapshotPolicy.Schedule).To(Equal(defaultSnapshotSchedule))
+
+					ls := &operatorv1.LogStorage{}
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, ls)).ShouldNot(HaveOccurred())
+					Expect(ls.Status.ElasticsearchSnapshots).NotTo(BeNil())
+					Expect(ls.Status.ElasticsearchSnapshots.LastSnapshot).To(Equal("tigera-secure-snapshot-1"))
+					Expect(ls.Status.ElasticsearchSnapshots.LastSnapshotTime.Time.Equal(lastSnapshotTime)).To(BeTrue())
+					Expect(ls.Status.ElasticsearchSnapshots.Restore).To(BeNil())
+
+					By("starting the restore of a snapshot")
+					ls.Spec.ElasticsearchSnapshots.Restore = &operatorv1.SnapshotRestore{Snapshot: "tigera-secure-snapshot-1", Indices: []string{"tigera_secure_ee_flows*"}}
+					Expect(cli.Update(ctx, ls)).ShouldNot(HaveOccurred())
+					esCli.restoreDone, esCli.restoreTotal = 1, 2
+
+					result, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(result.RequeueAfter).To(Equal(snapshotRestoreProgressSyncPeriod))
+					Expect(esCli.restores).To(Equal([]string{"tigera-secure-snapshot-1"}))
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, ls)).ShouldNot(HaveOccurred())
+					Expect(ls.Status.ElasticsearchSnapshots.Restore.State).To(Equal(operatorv1.SnapshotRestoreInProgress))
+
+					By("reporting the progress of the restore")
+					_, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, ls)).ShouldNot(HaveOccurred())
+					Expect(ls.Status.ElasticsearchSnapshots.Restore.State).To(Equal(operatorv1.SnapshotRestoreInProgress))
+					Expect(ls.Status.ElasticsearchSnapshots.Restore.Message).To(Equal("Restored 1 of 2 shards"))
+
+					esCli.restoreDone = 2
+					result, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(result.RequeueAfter).To(Equal(snapshotStatusSyncPeriod))
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, ls)).ShouldNot(HaveOccurred())
+					Expect(ls.Status.ElasticsearchSnapshots.Restore.State).To(Equal(operatorv1.SnapshotRestoreCompleted))
+					Expect(ls.Status.ElasticsearchSnapshots.Restore.CompletionTime).NotTo(BeNil())
+
+					By("not restoring the snapshot again")
+					_, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(esCli.restores).To(HaveLen(1))
+
+					By("removing the snapshot policy and status when snapshots are disabled")
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, ls)).ShouldNot(HaveOccurred())
+					ls.Spec.ElasticsearchSnapshots = nil
+					Expect(cli.Update(ctx, ls)).ShouldNot(HaveOccurred())
+					result, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(result).To(Equal(reconcile.Result{}))
+					Expect(esCli.snapshotPolicy).To(BeNil())
+					ls = &operatorv1.LogStorage{}
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, ls)).ShouldNot(HaveOccurred())
+					Expect(ls.Status.ElasticsearchSnapshots).To(BeNil())
+					es = &esv1.Elasticsearch{}
+					Expect(cli.Get(ctx, esObjKey, es)).ShouldNot(HaveOccurred())
+					Expect(es.Spec.SecureSettings).To(BeEmpty())
+					Expect(cli.Get(ctx, types.NamespacedName{Name: render.ElasticsearchSnapshotS3CredentialSecret, Namespace: render.ElasticsearchNamespace}, &corev1.Secret{})).Should(HaveOccurred())
+				})
+
+				It("should report a failed restore and not retry it", func() {
+					Expect(cli.Create(ctx, &storagev1.StorageClass{
+						ObjectMeta: metav1.ObjectMeta{Name: storageClassName},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &operatorv1.LogStorage{
+						ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+						Spec: operatorv1.LogStorageSpec{
+							Nodes:            &operatorv1.Nodes{Count: int64(1)},
+							StorageClassName: storageClassName,
+							ElasticsearchSnapshots: &operatorv1.ElasticsearchSnapshots{
+								Repository: operatorv1.SnapshotRepository{
+									PersistentVolumeClaim: &operatorv1.PVCSnapshotRepository{ClaimName: "backups"},
+								},
+								Restore: &operatorv1.SnapshotRestore{Snapshot: "missing", Indices: []string{"tigera_secure_ee_flows*"}},
+							},
+						},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: render.ECKOperatorNamespace, Name: render.ECKLicenseConfigMapName},
+						Data:       map[string]string{"eck_license_level": string(render.ElasticsearchLicenseTypeEnterprise)},
+					})).ShouldNot(HaveOccurred())
+
+					esCli := &mockESClient{restoreErr: fmt.Errorf("snapshot missing not found")}
+					esCliCreator := func(client.Client, context.Context, string) (utils.ElasticClient, error) {
+						return esCli, nil
+					}
+					r, err := NewReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, esCliCreator, dns.DefaultClusterDomain)
+					Expect(err).ShouldNot(HaveOccurred())
+					mockStatus.On("SetDegraded", mock.Anything, mock.Anything).Return()
+					mockStatus.On("ClearDegraded")
+
+					_, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					es := &esv1.Elasticsearch{}
+					Expect(cli.Get(ctx, esObjKey, es)).ShouldNot(HaveOccurred())
+					es.Status.Phase = esv1.ElasticsearchReadyPhase
+					Expect(cli.Update(ctx, es)).ShouldNot(HaveOccurred())
+					kb := &kbv1.Kibana{}
+					Expect(cli.Get(ctx, kbObjKey, kb)).ShouldNot(HaveOccurred())
+					kb.Status.AssociationStatus = cmnv1.AssociationEstablished
+					Expect(cli.Update(ctx, kb)).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchAdminUserSecret, Namespace: render.ElasticsearchNamespace},
+						Data:       map[string][]byte{"elastic": []byte("password")},
+					})).ShouldNot(HaveOccurred())
+
+					By("recording the failed restore even if a later step is not ready")
+					_, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					ls := &operatorv1.LogStorage{}
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, ls)).ShouldNot(HaveOccurred())
+					Expect(ls.Status.ElasticsearchSnapshots.Restore.State).To(Equal(operatorv1.SnapshotRestoreFailed))
+					Expect(ls.Status.ElasticsearchSnapshots.Restore.Message).To(ContainSubstring("snapshot missing not found"))
+
+					_, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(esCli.restores).To(Equal([]string{"missing"}))
+				})
+
 				It("test LogStorage reconciles successfully for elasticsearch basic license", func() {
 
 					Expect(cli.Create(ctx, &operatorv1.Authentication{
@@ -1335,6 +1593,7 @@ var _ = Describe("LogStorage controller", func() {
 					mockStatus.On("AddStatefulSets", mock.Anything)
 					mockStatus.On("RemoveCertificateSigningRequests", mock.Anything)
 					mockStatus.On("AddCronJobs", mock.Anything)
+					mockStatus.On("SetPodSecurityRequirements", mock.Anything)
 					mockStatus.On("ClearDegraded", mock.Anything)
 					mockStatus.On("OnCRFound").Return()
 					mockStatus.On("ReadyToMonitor")
@@ -1433,6 +1692,45 @@ var _ = Describe("LogStorage controller", func() {
 			Expect(validateComponentResources(&ls.Spec)).To(BeNil())
 		})
 	})
+	Context("LogStorageSpec, validateElasticsearchSnapshots", func() {
+		var minCount, maxCount int32 = 5, 2
+		s3 := operatorv1.SnapshotRepository{S3: &operatorv1.S3SnapshotRepository{BucketName: "backups"}}
+		pvc := operatorv1.SnapshotRepository{PersistentVolumeClaim: &operatorv1.PVCSnapshotRepository{ClaimName: "backups"}}
+
+		DescribeTable("validates the snapshot configuration",
+			func(snapshots *operatorv1.ElasticsearchSnapshots, valid bool) {
+				err := validateElasticsearchSnapshots(snapshots)
+				if valid {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(err).To(HaveOccurred())
+				}
+			},
+			Entry("not set", nil, true),
+			Entry("S3 repository", &operatorv1.ElasticsearchSnapshots{Repository: s3}, true),
+			Entry("PersistentVolumeClaim repository", &operatorv1.ElasticsearchSnapshots{Repository: pvc}, true),
+			Entry("no repository", &operatorv1.ElasticsearchSnapshots{}, false),
+			Entry("both repositories", &operatorv1.ElasticsearchSnapshots{Repository: operatorv1.SnapshotRepository{S3: s3.S3, PersistentVolumeClaim: pvc.PersistentVolumeClaim}}, false),
+			Entry("S3 repository without a bucket", &operatorv1.ElasticsearchSnapshots{Repository: operatorv1.SnapshotRepository{S3: &operatorv1.S3SnapshotRepository{}}}, false),
+			Entry("PersistentVolumeClaim repository without a claim", &operatorv1.ElasticsearchSnapshots{Repository: operatorv1.SnapshotRepository{PersistentVolumeClaim: &operatorv1.PVCSnapshotRepository{}}}, false),
+			Entry("minCount greater than maxCount", &operatorv1.ElasticsearchSnapshots{Repository: s3, Retention: &operatorv1.SnapshotRetention{MinCount: &minCount, MaxCount: &maxCount}}, false),
+			Entry("restore", &operatorv1.ElasticsearchSnapshots{Repository: s3, Restore: &operatorv1.SnapshotRestore{Snapshot: "snap", Indices: []string{"tigera_secure_ee_flows*"}}}, true),
+			Entry("restore without a snapshot", &operatorv1.ElasticsearchSnapshots{Repository: s3, Restore: &operatorv1.SnapshotRestore{Indices: []string{"tigera_secure_ee_flows*"}}}, false),
+			Entry("restore without indices", &operatorv1.ElasticsearchSnapshots{Repository: s3, Restore: &operatorv1.SnapshotRestore{Snapshot: "snap"}}, false),
+			Entry("restore of all indices", &operatorv1.ElasticsearchSnapshots{Repository: s3, Restore: &operatorv1.SnapshotRestore{Snapshot: "snap", Indices: []string{"*"}}}, false),
+		)
+
+		It("should fill the default schedule and retention", func() {
+			ls := operatorv1.LogStorage{Spec: operatorv1.LogStorageSpec{
+				ElasticsearchSnapshots: &operatorv1.ElasticsearchSnapshots{Repository: s3},
+			}}
+			fillDefaults(&ls)
+			Expect(ls.Spec.ElasticsearchSnapshots.Schedule).To(Equal("0 30 1 * * ?"))
+			Expect(*ls.Spec.ElasticsearchSnapshots.Retention.ExpireAfterDays).To(Equal(int32(30)))
+			Expect(*ls.Spec.ElasticsearchSnapshots.Retention.MinCount).To(Equal(int32(5)))
+			Expect(ls.Spec.ElasticsearchSnapshots.Retention.MaxCount).To(BeNil())
+		})
+	})
 	Context("LogStorageSpec, fillDefaults", func() {
 		ls := operatorv1.LogStorage{Spec: operatorv1.LogStorageSpec{}}
 		fillDefaults(&ls)
@@ -1627,3 +1925,21 @@ func createKibanaSecrets() []client.Object {
 func (*mockESClient) SetILMPolicies(ctx context.Context, ls *operatorv1.LogStorage) error {
 	return nil
 }
+
+func (m *mockESClient) SetSnapshotPolicy(ctx context.Context, ls *operatorv1.LogStorage) error {
+	m.snapshotPolicy = ls.Spec.ElasticsearchSnapshots
+	return nil
+}
+
+func (m *mockESClient) GetSnapshotPolicyStatus(ctx context.Context) (*utils.SnapshotPolicyStatus, error) {
+	return m.snapshotPolicyStatus, nil
+}
+
+func (m *mockESClient) RestoreSnapshot(ctx context.Context, snapshot string, indices []string) error {
+	m.restores = append(m.restores, snapshot)
+	return m.restoreErr
+}
+
+func (m *mockESClient) GetSnapshotRestoreProgress(ctx context.Context, snapshot string, indices []string) (int, int, error) {
+	return m.restoreDone, m.restoreTotal, nil
+}
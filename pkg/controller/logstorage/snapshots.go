@@ -0,0 +1,223 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+const (
+	defaultSnapshotSchedule                 = "0 30 1 * * ?"
+	defaultSnapshotExpireAfterDays    int32 = 30
+	defaultSnapshotMinCount           int32 = 5
+	snapshotStatusSyncPeriod                = 10 * time.Minute
+	snapshotRestoreProgressSyncPeriod       = 30 * time.Second
+
+	// snapshotRestoreStartTimeout is how long a restore may go without any shards being recovered from the snapshot
+	// before it is considered failed, e.g. because none of the requested indices are in the snapshot.
+	snapshotRestoreStartTimeout = 5 * time.Minute
+)
+
+// fillSnapshotDefaults populates the default values onto the ElasticsearchSnapshots of a LogStorage object.
+func fillSnapshotDefaults(snapshots *operatorv1.ElasticsearchSnapshots) {
+	if snapshots.Schedule == "" {
+		snapshots.Schedule = defaultSnapshotSchedule
+	}
+	if snapshots.Retention == nil {
+		snapshots.Retention = &operatorv1.SnapshotRetention{}
+	}
+	if snapshots.Retention.ExpireAfterDays == nil {
+		expireAfterDays := defaultSnapshotExpireAfterDays
+		snapshots.Retention.ExpireAfterDays = &expireAfterDays
+	}
+	if snapshots.Retention.MinCount == nil {
+		minCount := defaultSnapshotMinCount
+		snapshots.Retention.MinCount = &minCount
+	}
+}
+
+func validateElasticsearchSnapshots(snapshots *operatorv1.ElasticsearchSnapshots) error {
+	if snapshots == nil {
+		return nil
+	}
+
+	repo := snapshots.Repository
+	if (repo.S3 == nil) == (repo.PersistentVolumeClaim == nil) {
+		return fmt.Errorf("LogStorage spec.ElasticsearchSnapshots.Repository must specify exactly one of S3 and PersistentVolumeClaim")
+	}
+	if repo.S3 != nil && repo.S3.BucketName == "" {
+		return fmt.Errorf("LogStorage spec.ElasticsearchSnapshots.Repository.S3.BucketName must be specified")
+	}
+	if repo.PersistentVolumeClaim != nil && repo.PersistentVolumeClaim.ClaimName == "" {
+		return fmt.Errorf("LogStorage spec.ElasticsearchSnapshots.Repository.PersistentVolumeClaim.ClaimName must be specified")
+	}
+
+	if r := snapshots.Retention; r != nil && r.MinCount != nil && r.MaxCount != nil && *r.MinCount > *r.MaxCount {
+		return fmt.Errorf("LogStorage spec.ElasticsearchSnapshots.Retention.MinCount must not be greater than MaxCount")
+	}
+
+	if r := snapshots.Restore; r != nil {
+		if r.Snapshot == "" {
+			return fmt.Errorf("LogStorage spec.ElasticsearchSnapshots.Restore.Snapshot must be specified")
+		}
+		if len(r.Indices) == 0 {
+			return fmt.Errorf("LogStorage spec.ElasticsearchSnapshots.Restore.Indices must be specified")
+		}
+		for _, index := range r.Indices {
+			// Restoring all the indices would also restore the system indices of the snapshot.
+			if index == "" || index == "*" || index == "_all" || strings.HasPrefix(index, "-") {
+				return fmt.Errorf("LogStorage spec.ElasticsearchSnapshots.Restore.Indices %q must name the indices to restore", index)
+			}
+		}
+	}
+	return nil
+}
+
+// getSnapshotS3Credential reads the credentials of the S3 snapshot repository from the operator namespace.
+func getSnapshotS3Credential(ctx context.Context, cli client.Client) (*render.S3Credential, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: render.ElasticsearchSnapshotS3CredentialSecret, Namespace: common.OperatorNamespace()}
+	if err := cli.Get(ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("failed to read secret %q: %w", render.ElasticsearchSnapshotS3CredentialSecret, err)
+	}
+
+	for _, k := range []string{render.S3KeyIdName, render.S3KeySecretName} {
+		if len(secret.Data[k]) == 0 {
+			return nil, fmt.Errorf("expected secret %q to have a field named %q", render.ElasticsearchSnapshotS3CredentialSecret, k)
+		}
+	}
+	return &render.S3Credential{
+		KeyId:     secret.Data[render.S3KeyIdName],
+		KeySecret: secret.Data[render.S3KeySecretName],
+	}, nil
+}
+
+// applySnapshots configures the snapshot repository and policy of Elasticsearch, reports the last snapshots in the
+// LogStorage status and starts or follows the restore that is requested in the LogStorage spec. While snapshots are
+// configured, the returned result requeues the request so that the status is kept up to date.
+func (r *ReconcileLogStorage) applySnapshots(ls *operatorv1.LogStorage, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if err = esClient.SetSnapshotPolicy(ctx, ls); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch snapshot policy")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch snapshot policy", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	snapshots := ls.Spec.ElasticsearchSnapshots
+	if snapshots == nil {
+		ls.Status.ElasticsearchSnapshots = nil
+		return reconcile.Result{}, true, nil
+	}
+	if ls.Status.ElasticsearchSnapshots == nil {
+		ls.Status.ElasticsearchSnapshots = &operatorv1.ElasticsearchSnapshotsStatus{}
+	}
+	snapshotsStatus := ls.Status.ElasticsearchSnapshots
+
+	policyStatus, err := esClient.GetSnapshotPolicyStatus(ctx)
+	if err != nil {
+		reqLogger.Error(err, "failed to get the Elasticsearch snapshot policy status")
+		r.status.SetDegraded("Failed to get the Elasticsearch snapshot policy status", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	if policyStatus != nil {
+		if s := policyStatus.LastSuccess; s != nil {
+			snapshotsStatus.LastSnapshot = s.Snapshot
+			snapshotsStatus.LastSnapshotTime = &metav1.Time{Time: s.Time}
+		}
+		if f := policyStatus.LastFailure; f != nil {
+			snapshotsStatus.LastFailure = fmt.Sprintf("%s: %s", f.Snapshot, f.Details)
+			snapshotsStatus.LastFailureTime = &metav1.Time{Time: f.Time}
+		}
+	}
+
+	result := reconcile.Result{RequeueAfter: snapshotStatusSyncPeriod}
+	restore := snapshots.Restore
+	switch {
+	case restore == nil:
+		snapshotsStatus.Restore = nil
+	case snapshotsStatus.Restore == nil || snapshotsStatus.Restore.Snapshot != restore.Snapshot:
+		now := metav1.Now()
+		snapshotsStatus.Restore = &operatorv1.SnapshotRestoreStatus{
+			Snapshot:  restore.Snapshot,
+			State:     operatorv1.SnapshotRestoreInProgress,
+			Message:   "Waiting for the restore to start",
+			StartTime: &now,
+		}
+		if err := esClient.RestoreSnapshot(ctx, restore.Snapshot, restore.Indices); err != nil {
+			reqLogger.Error(err, "failed to restore the Elasticsearch snapshot", "snapshot", restore.Snapshot)
+			snapshotsStatus.Restore.State = operatorv1.SnapshotRestoreFailed
+			snapshotsStatus.Restore.Message = err.Error()
+			snapshotsStatus.Restore.CompletionTime = &now
+		} else {
+			result.RequeueAfter = snapshotRestoreProgressSyncPeriod
+		}
+
+		// A restore is started once for each snapshot, so that it is not repeated if a later step of the
+		// reconcile fails. Record it right away.
+		if err := r.client.Status().Update(ctx, ls); err != nil {
+			reqLogger.Error(err, "Error updating the log-storage status")
+			r.status.SetDegraded("Error updating the log-storage status", err.Error())
+			return reconcile.Result{}, false, err
+		}
+	case snapshotsStatus.Restore.State == operatorv1.SnapshotRestoreInProgress:
+		done, total, err := esClient.GetSnapshotRestoreProgress(ctx, restore.Snapshot, restore.Indices)
+		if err != nil {
+			reqLogger.Error(err, "failed to get the progress of the Elasticsearch snapshot restore")
+			r.status.SetDegraded("Failed to get the progress of the Elasticsearch snapshot restore", err.Error())
+			return reconcile.Result{}, false, err
+		}
+
+		now := metav1.Now()
+		switch {
+		case total > 0 && done == total:
+			snapshotsStatus.Restore.State = operatorv1.SnapshotRestoreCompleted
+			snapshotsStatus.Restore.Message = fmt.Sprintf("Restored %d shards", total)
+			snapshotsStatus.Restore.CompletionTime = &now
+		case total == 0 && snapshotsStatus.Restore.StartTime != nil && now.Sub(snapshotsStatus.Restore.StartTime.Time) > snapshotRestoreStartTimeout:
+			snapshotsStatus.Restore.State = operatorv1.SnapshotRestoreFailed
+			snapshotsStatus.Restore.Message = "No shards were restored from the snapshot"
+			snapshotsStatus.Restore.CompletionTime = &now
+		default:
+			if total > 0 {
+				snapshotsStatus.Restore.Message = fmt.Sprintf("Restored %d of %d shards", done, total)
+			}
+			result.RequeueAfter = snapshotRestoreProgressSyncPeriod
+		}
+	}
+
+	return result, true, nil
+}
@@ -31,7 +31,9 @@ import (
 	"github.com/tigera/operator/pkg/render"
 	rcertificatemanagement "github.com/tigera/operator/pkg/render/certificatemanagement"
 	tigerakvc "github.com/tigera/operator/pkg/render/common/authentication/tigera/key_validator_config"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	corev1 "k8s.io/api/core/v1"
 
@@ -58,9 +60,10 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	}
 
 	licenseAPIReady := &utils.ReadyFlag{}
+	tierWatchReady := &utils.ReadyFlag{}
 
 	// create the reconciler
-	reconciler := newReconciler(mgr, opts, licenseAPIReady)
+	reconciler := newReconciler(mgr, opts, licenseAPIReady, tierWatchReady)
 
 	// Create a new controller
 	controller, err := controller.New("cmanager-controller", mgr, controller.Options{Reconciler: reconcile.Reconciler(reconciler)})
@@ -75,12 +78,13 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	}
 
 	go utils.WaitToAddLicenseKeyWatch(controller, k8sClient, log, licenseAPIReady)
+	go utils.WaitToAddTierWatch(controller, k8sClient, log, tierWatchReady)
 
 	return add(mgr, controller)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady *utils.ReadyFlag) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady *utils.ReadyFlag, tierWatchReady *utils.ReadyFlag) reconcile.Reconciler {
 	c := &ReconcileManager{
 		client:          mgr.GetClient(),
 		scheme:          mgr.GetScheme(),
@@ -88,6 +92,7 @@ func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady
 		status:          status.New(mgr.GetClient(), "manager", opts.KubernetesVersion),
 		clusterDomain:   opts.ClusterDomain,
 		licenseAPIReady: licenseAPIReady,
+		tierWatchReady:  tierWatchReady,
 		usePSP:          opts.UsePSP,
 	}
 	c.status.Run(opts.ShutdownContext)
@@ -169,6 +174,11 @@ func add(mgr manager.Manager, c controller.Controller) error {
 		return fmt.Errorf("manager-controller failed to watch the ConfigMap resource: %v", err)
 	}
 
+	// The address of the load balancer of the manager is reported in the status of the Manager.
+	if err = utils.AddServiceWatch(c, render.ManagerServiceName, render.ManagerNamespace); err != nil {
+		return fmt.Errorf("manager-controller failed to watch the Service resource: %w", err)
+	}
+
 	return nil
 }
 
@@ -184,6 +194,7 @@ type ReconcileManager struct {
 	status          status.StatusManager
 	clusterDomain   string
 	licenseAPIReady *utils.ReadyFlag
+	tierWatchReady  *utils.ReadyFlag
 	usePSP          bool
 }
 
@@ -224,6 +235,16 @@ func (r *ReconcileManager) Reconcile(ctx context.Context, request reconcile.Requ
 	reqLogger.V(2).Info("Loaded config", "config", instance)
 	r.status.OnCRFound()
 
+	if err = render.ValidateManagerExposure(instance.Spec.Exposure); err != nil {
+		r.status.SetDegraded("Invalid Manager exposure", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	if err = autoscaling.Validate(instance.Spec.Autoscaling); err != nil {
+		r.status.SetDegraded("Invalid Manager autoscaling", err.Error())
+		return reconcile.Result{}, err
+	}
+
 	if !utils.IsAPIServerReady(r.client, reqLogger) {
 		r.status.SetDegraded("Waiting for Tigera API server to be ready", "")
 		return reconcile.Result{}, nil
@@ -265,6 +286,10 @@ func (r *ReconcileManager) Reconcile(ctx context.Context, request reconcile.Requ
 	}
 
 	svcDNSNames := append(dns.GetServiceDNSNames(render.ManagerServiceName, render.ManagerNamespace, r.clusterDomain), "localhost")
+	if exposure := instance.Spec.Exposure; exposure != nil && exposure.Hostname != "" {
+		// Users connect to the manager with this name when TLS is not terminated in front of it.
+		svcDNSNames = append(svcDNSNames, exposure.Hostname)
+	}
 	tlsSecret, err := certificateManager.GetOrCreateKeyPair(
 		r.client,
 		render.ManagerTLSSecretName,
@@ -428,8 +453,15 @@ func (r *ReconcileManager) Reconcile(ctx context.Context, request reconcile.Requ
 		}
 	}
 
+	networkPolicyState, err := utils.GetNetworkPolicyState(ctx, r.client, r.tierWatchReady, installation, instance.Spec.ComponentNetworkPolicy)
+	if err != nil {
+		log.Error(err, "Error querying allow-tigera tier")
+		r.status.SetDegraded("Error querying allow-tigera tier", err.Error())
+		return reconcile.Result{}, err
+	}
+
 	// Create a component handler to manage the rendered component.
-	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance)
+	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance, installation)
 
 	// Set replicas to 1 for management or managed clusters.
 	// TODO Remove after MCM tigera-manager HA deployment is supported.
@@ -455,7 +487,11 @@ func (r *ReconcileManager) Reconcile(ctx context.Context, request reconcile.Requ
 		ESLicenseType:           elasticLicenseType,
 		Replicas:                replicas,
 		ComplianceFeatureActive: installCompliance,
+		Exposure:                instance.Spec.Exposure,
+		LogLevel:                loglevel.Resolve(installation, instance.Spec.LogLevel),
+		Autoscaling:             instance.Spec.Autoscaling,
 		UsePSP:                  r.usePSP,
+		NetworkPolicyState:      networkPolicyState,
 	}
 
 	// Render the desired objects from the CRD and create or update them.
@@ -495,6 +531,10 @@ func (r *ReconcileManager) Reconcile(ctx context.Context, request reconcile.Requ
 	// Clear the degraded bit if we've reached this far.
 	r.status.ClearDegraded()
 	instance.Status.State = operatorv1.TigeraStatusReady
+	if instance.Status.URL, err = r.managerURL(ctx, instance.Spec.Exposure); err != nil {
+		r.status.SetDegraded("Error querying the manager service", err.Error())
+		return reconcile.Result{}, err
+	}
 	if r.status.IsAvailable() {
 		if err = r.client.Status().Update(ctx, instance); err != nil {
 			return reconcile.Result{}, err
@@ -503,3 +543,24 @@ func (r *ReconcileManager) Reconcile(ctx context.Context, request reconcile.Requ
 
 	return reconcile.Result{}, nil
 }
+
+// managerURL returns the URL that the manager is reachable at from outside of the cluster, or an empty string if it
+// is not exposed or the address is not known yet.
+func (r *ReconcileManager) managerURL(ctx context.Context, exposure *operatorv1.ManagerExposure) (string, error) {
+	if url := render.ManagerExposureURL(exposure); url != "" || exposure == nil || exposure.Type != operatorv1.ManagerExposureLoadBalancer {
+		return url, nil
+	}
+	svc := &corev1.Service{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: render.ManagerServiceName, Namespace: render.ManagerNamespace}, svc); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if url := render.ManagerLoadBalancerURL(ingress); url != "" {
+			return url, nil
+		}
+	}
+	return "", nil
+}
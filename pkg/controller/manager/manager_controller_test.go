@@ -41,8 +41,10 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -63,6 +65,7 @@ var _ = Describe("Manager controller tests", func() {
 		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
 		Expect(appsv1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
 		Expect(rbacv1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		Expect(netv1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
 		c = fake.NewClientBuilder().WithScheme(scheme).Build()
 		ctx = context.Background()
 		replicas = 2
@@ -98,6 +101,7 @@ var _ = Describe("Manager controller tests", func() {
 			mockStatus.On("AddCertificateSigningRequests", mock.Anything).Return()
 			mockStatus.On("RemoveCertificateSigningRequests", mock.Anything).Return()
 			mockStatus.On("AddCronJobs", mock.Anything)
+			mockStatus.On("SetPodSecurityRequirements", mock.Anything)
 			mockStatus.On("IsAvailable").Return(true)
 			mockStatus.On("OnCRFound").Return()
 			mockStatus.On("ClearDegraded")
@@ -225,6 +229,78 @@ var _ = Describe("Manager controller tests", func() {
 			Expect(secret.GetOwnerReferences()).To(HaveLen(1))
 		})
 
+		It("should expose the manager with a load balancer and report its URL", func() {
+			cr.Spec.Exposure = &operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureLoadBalancer}
+			Expect(c.Update(ctx, cr)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			svc := &corev1.Service{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: render.ManagerServiceName, Namespace: render.ManagerNamespace}, svc)).NotTo(HaveOccurred())
+			Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+			Expect(c.Get(ctx, utils.DefaultTSEEInstanceKey, cr)).NotTo(HaveOccurred())
+			Expect(cr.Status.URL).To(BeEmpty())
+
+			// The cloud provider assigns an address to the load balancer. The fake client does not keep the status of
+			// objects that are updated during a reconcile, so the URL is queried directly.
+			svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "192.0.2.10"}}
+			Expect(c.Status().Update(ctx, svc)).NotTo(HaveOccurred())
+			Expect(r.managerURL(ctx, cr.Spec.Exposure)).To(Equal("https://192.0.2.10:9443"))
+		})
+
+		It("should expose the manager with a TLSRoute", func() {
+			cr.Spec.Exposure = &operatorv1.ManagerExposure{
+				Type:     operatorv1.ManagerExposureGateway,
+				Hostname: "manager.example.com",
+				Gateway: &operatorv1.ManagerGateway{
+					RouteType:  operatorv1.ManagerGatewayTLSRoute,
+					ParentRefs: []operatorv1.ManagerGatewayParentRef{{Name: "public", Namespace: "gateways"}},
+				},
+			}
+			Expect(c.Update(ctx, cr)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			route := &unstructured.Unstructured{}
+			route.SetAPIVersion("gateway.networking.k8s.io/v1alpha2")
+			route.SetKind("TLSRoute")
+			Expect(c.Get(ctx, types.NamespacedName{Name: render.ManagerRouteName, Namespace: render.ManagerNamespace}, route)).NotTo(HaveOccurred())
+			hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+			Expect(hostnames).To(ConsistOf("manager.example.com"))
+			Expect(route.GetOwnerReferences()).To(HaveLen(1))
+
+			// The gateway passes TLS through, so the certificate of the manager must be valid for the hostname.
+			secret := &corev1.Secret{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: render.ManagerTLSSecretName, Namespace: common.OperatorNamespace()}, secret)).NotTo(HaveOccurred())
+			test.VerifyCert(secret, append(expectedDNSNames, "manager.example.com")...)
+
+			Expect(c.Get(ctx, utils.DefaultTSEEInstanceKey, cr)).NotTo(HaveOccurred())
+			Expect(cr.Status.URL).To(Equal("https://manager.example.com"))
+		})
+
+		It("should degrade when the exposure is invalid", func() {
+			cr.Spec.Exposure = &operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureIngress}
+			Expect(c.Update(ctx, cr)).NotTo(HaveOccurred())
+			mockStatus.On("SetDegraded", "Invalid Manager exposure", "hostname is required for the Ingress exposure type").Return()
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).Should(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", "Invalid Manager exposure", "hostname is required for the Ingress exposure type")
+		})
+
+		It("should degrade when the autoscaling is invalid", func() {
+			minReplicas := int32(3)
+			cr.Spec.Autoscaling = &operatorv1.Autoscaling{MinReplicas: &minReplicas, MaxReplicas: 2}
+			Expect(c.Update(ctx, cr)).NotTo(HaveOccurred())
+			mockStatus.On("SetDegraded", "Invalid Manager autoscaling", "minReplicas 3 is greater than maxReplicas 2").Return()
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).Should(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", "Invalid Manager autoscaling", "minReplicas 3 is greater than maxReplicas 2")
+		})
+
 		It("should not add OwnerReference to an user supplied manager TLS cert", func() {
 			// Create a manager cert secret.
 			dnsNames := []string{"manager.example.com", "192.168.10.22"}
@@ -298,6 +374,7 @@ var _ = Describe("Manager controller tests", func() {
 			mockStatus.On("AddDeployments", mock.Anything).Return()
 			mockStatus.On("AddStatefulSets", mock.Anything).Return()
 			mockStatus.On("AddCronJobs", mock.Anything)
+			mockStatus.On("SetPodSecurityRequirements", mock.Anything)
 			mockStatus.On("IsAvailable").Return(true)
 			mockStatus.On("OnCRFound").Return()
 			mockStatus.On("ClearDegraded")
@@ -61,9 +61,10 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	}
 
 	var prometheusReady = &utils.ReadyFlag{}
+	var tierWatchReady = &utils.ReadyFlag{}
 
 	// Create the reconciler
-	reconciler := newReconciler(mgr, opts, prometheusReady)
+	reconciler := newReconciler(mgr, opts, prometheusReady, tierWatchReady)
 
 	// Create a new controller
 	controller, err := controller.New("monitor-controller", mgr, controller.Options{Reconciler: reconciler})
@@ -78,17 +79,19 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	}
 
 	go waitToAddPrometheusWatch(controller, k8sClient, log, prometheusReady)
+	go utils.WaitToAddTierWatch(controller, k8sClient, log, tierWatchReady)
 
 	return add(mgr, controller)
 }
 
-func newReconciler(mgr manager.Manager, opts options.AddOptions, prometheusReady *utils.ReadyFlag) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, opts options.AddOptions, prometheusReady *utils.ReadyFlag, tierWatchReady *utils.ReadyFlag) reconcile.Reconciler {
 	r := &ReconcileMonitor{
 		client:          mgr.GetClient(),
 		scheme:          mgr.GetScheme(),
 		provider:        opts.DetectedProvider,
 		status:          status.New(mgr.GetClient(), "monitor", opts.KubernetesVersion),
 		prometheusReady: prometheusReady,
+		tierWatchReady:  tierWatchReady,
 		clusterDomain:   opts.ClusterDomain,
 	}
 
@@ -134,6 +137,11 @@ func add(mgr manager.Manager, c controller.Controller) error {
 		return fmt.Errorf("monitor-controller failed to watch resource: %w", err)
 	}
 
+	// The fluentd buffer alert uses the degraded threshold of the LogCollector.
+	if err = c.Watch(&source.Kind{Type: &operatorv1.LogCollector{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return fmt.Errorf("monitor-controller failed to watch LogCollector resource: %w", err)
+	}
+
 	return nil
 }
 
@@ -146,6 +154,7 @@ type ReconcileMonitor struct {
 	provider        operatorv1.Provider
 	status          status.StatusManager
 	prometheusReady *utils.ReadyFlag
+	tierWatchReady  *utils.ReadyFlag
 	clusterDomain   string
 }
 
@@ -266,7 +275,7 @@ func (r *ReconcileMonitor) Reconcile(ctx context.Context, request reconcile.Requ
 	}
 
 	// Create a component handler to manage the rendered component.
-	hdler := utils.NewComponentHandler(log, r.client, r.scheme, instance)
+	hdler := utils.NewComponentHandler(log, r.client, r.scheme, instance, install)
 
 	alertmanagerConfigSecret, createInOperatorNamespace, err := r.readAlertmanagerConfigSecret(ctx)
 	if err != nil {
@@ -274,6 +283,22 @@ func (r *ReconcileMonitor) Reconcile(ctx context.Context, request reconcile.Requ
 		return reconcile.Result{}, err
 	}
 
+	networkPolicyState, err := utils.GetNetworkPolicyState(ctx, r.client, r.tierWatchReady, install, instance.Spec.ComponentNetworkPolicy)
+	if err != nil {
+		r.setDegraded(reqLogger, err, "Error querying allow-tigera tier")
+		return reconcile.Result{}, err
+	}
+
+	logCollector, err := utils.GetLogCollector(ctx, r.client)
+	if err != nil {
+		r.setDegraded(reqLogger, err, "Error querying LogCollector")
+		return reconcile.Result{}, err
+	}
+	var fluentdBufferThreshold *int32
+	if logCollector != nil && logCollector.Spec.Buffer != nil {
+		fluentdBufferThreshold = logCollector.Spec.Buffer.DegradedThresholdPercent
+	}
+
 	monitorCfg := &monitor.Config{
 		Installation:             install,
 		PullSecrets:              pullSecrets,
@@ -283,6 +308,9 @@ func (r *ReconcileMonitor) Reconcile(ctx context.Context, request reconcile.Requ
 		ClientTLSSecret:          clientTLSSecret,
 		ClusterDomain:            r.clusterDomain,
 		TrustedCertBundle:        trustedBundle,
+		NetworkPolicyState:       networkPolicyState,
+
+		FluentdBufferThresholdPercent: fluentdBufferThreshold,
 	}
 
 	// Render prometheus component
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/mock"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // TODO use mockery to generate mock
@@ -72,6 +73,18 @@ func (m *MockStatus) SetWindowsUpgradeStatus(pending, inProgress, completed []st
 	m.Called(pending, inProgress, completed, err)
 }
 
+func (m *MockStatus) SetNodeHealth(health *NodeHealth) {
+	m.Called(health)
+}
+
+func (m *MockStatus) SetPodSecurityRequirements(objs []client.Object) {
+	m.Called(objs)
+}
+
+func (m *MockStatus) SetThreatFeedErrors(errs map[string][]string) {
+	m.Called(errs)
+}
+
 func (m *MockStatus) SetDegraded(reason, msg string) {
 	m.Called(reason, msg)
 }
@@ -18,12 +18,14 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	operator "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	batch "k8s.io/api/batch/v1beta1"
@@ -70,6 +72,9 @@ type StatusManager interface {
 	RemoveCronJobs(cjs ...types.NamespacedName)
 	RemoveCertificateSigningRequests(name string)
 	SetWindowsUpgradeStatus(pending, inProgress, completed []string, err error)
+	SetNodeHealth(health *NodeHealth)
+	SetPodSecurityRequirements(objs []client.Object)
+	SetThreatFeedErrors(errs map[string][]string)
 	SetDegraded(reason, msg string)
 	ClearDegraded()
 	IsAvailable() bool
@@ -97,6 +102,13 @@ type statusManager struct {
 	explicitDegradedReason string
 	// Track degraded state set by calicoWindowsUpgrader.
 	windowsUpgradeDegradedMsg string
+	// The health of the nodes, if reported.
+	nodeHealth *NodeHealth
+	// The pod security standards required by the workloads that need a less restrictive one than "restricted", keyed
+	// by kind/namespace/name. It is nil until workloads have been reported.
+	podSecurity map[string]podsecuritycontext.Requirement
+	// The errors of the failing threat feeds, keyed by feed name. It is nil until the threat feeds have been reported.
+	threatFeedErrors map[string][]string
 
 	// Keep track of currently calculated status.
 	progressing []string
@@ -178,6 +190,10 @@ func (m *statusManager) updateStatus() {
 		} else {
 			m.clearDegraded()
 		}
+
+		m.setNodesDegraded()
+		m.setPodSecurityRelaxed()
+		m.setThreatFeedsFailing()
 	} else {
 		log.V(2).WithName(m.component).Info("Status manager is not ready to report component statuses.")
 
@@ -325,32 +341,113 @@ func (w *windowsNodeUpgrades) progressingReason() string {
 		return ""
 	}
 
-	return fmt.Sprintf("Waiting for Calico for Windows to be upgraded: %v/%v nodes have been upgraded, %v in-progress", completed, total, inProgress)
+	nodes := []string{}
+	if inProgress > 0 {
+		nodes = append(nodes, fmt.Sprintf("in-progress: %v", strings.Join(w.nodesInProgress, ", ")))
+	}
+	if pending > 0 {
+		nodes = append(nodes, fmt.Sprintf("pending: %v", strings.Join(w.nodesPending, ", ")))
+	}
+	return fmt.Sprintf("Waiting for Calico for Windows to be upgraded: %v/%v nodes have been upgraded, %v in-progress (%v)", completed, total, inProgress, strings.Join(nodes, "; "))
 }
 
 // SetWindowsUpgradeStatus tells the status manager to monitor the upgrade
-// status of the given Windows node upgrades.
+// status of the given Windows node upgrades. A non-nil error degrades the status,
+// e.g. when the upgrade of some nodes failed. The nodes are left unchanged when
+// an error is given without any nodes.
 func (m *statusManager) SetWindowsUpgradeStatus(pending, inProgress, completed []string, err error) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
+	if err == nil || pending != nil || inProgress != nil || completed != nil {
+		m.windowsNodeUpgrades.nodesPending = pending
+		m.windowsNodeUpgrades.nodesInProgress = inProgress
+		m.windowsNodeUpgrades.nodesCompleted = completed
+	}
+
 	if err != nil {
 		m.windowsUpgradeDegradedMsg = err.Error()
 		return
 	}
-
-	m.windowsNodeUpgrades.nodesPending = pending
-	m.windowsNodeUpgrades.nodesInProgress = inProgress
-	m.windowsNodeUpgrades.nodesCompleted = completed
 	m.windowsUpgradeDegradedMsg = ""
 }
 
+// NodeHealth summarizes the problems that calico/node reports on individual nodes.
+type NodeHealth struct {
+	// BGPPeersDown are the nodes with BGP sessions that are not established.
+	BGPPeersDown []string
+	// BGPDaemonNotReady are the nodes whose BGP daemon is not ready.
+	BGPDaemonNotReady []string
+	// NotReady are the nodes whose calico-node is not ready, e.g. because Felix is not live or ready.
+	NotReady []string
+}
+
+// message returns a line for each kind of problem, listing the nodes that have it.
+func (h *NodeHealth) message() string {
+	lines := []string{}
+	add := func(nodes []string, singular, plural string) {
+		if len(nodes) == 1 {
+			lines = append(lines, fmt.Sprintf("1 node %s: %s", singular, nodes[0]))
+		} else if len(nodes) > 1 {
+			lines = append(lines, fmt.Sprintf("%d nodes %s: %s", len(nodes), plural, strings.Join(nodes, ", ")))
+		}
+	}
+	add(h.BGPPeersDown, "has BGP peers down", "have BGP peers down")
+	add(h.BGPDaemonNotReady, "has the BGP daemon not ready", "have the BGP daemon not ready")
+	add(h.NotReady, "has calico-node not ready", "have calico-node not ready")
+	return strings.Join(lines, "\n")
+}
+
+// SetNodeHealth tells the status manager the health of the nodes, which it reports in the NodesDegraded
+// condition. A nil health means the health of the nodes is unknown, and the condition is not updated.
+func (m *statusManager) SetNodeHealth(health *NodeHealth) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.nodeHealth = health
+}
+
+// SetThreatFeedErrors tells the status manager the errors of the threat feeds that fail to sync, keyed by feed name,
+// which it reports in the ThreatFeedsFailing condition. Failing threat feeds don't degrade the component.
+func (m *statusManager) SetThreatFeedErrors(errs map[string][]string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.threatFeedErrors = errs
+}
+
+// SetPodSecurityRequirements tells the status manager the pod security standards that the pods of the workloads in
+// objs need, which it reports in the PodSecurityRelaxed condition. Workloads that are not in objs keep their
+// previously reported requirements until they are removed.
+func (m *statusManager) SetPodSecurityRequirements(objs []client.Object) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, obj := range objs {
+		req, ok := podsecuritycontext.Check(obj)
+		if !ok {
+			continue
+		}
+		if m.podSecurity == nil {
+			m.podSecurity = map[string]podsecuritycontext.Requirement{}
+		}
+		key := podSecurityKey(req.Kind, types.NamespacedName{Namespace: req.Namespace, Name: req.Name})
+		if req.Level == operator.PodSecurityStandardRestricted {
+			delete(m.podSecurity, key)
+		} else {
+			m.podSecurity[key] = req
+		}
+	}
+}
+
+func podSecurityKey(kind string, key types.NamespacedName) string {
+	return kind + "/" + key.String()
+}
+
 // RemoveDaemonsets tells the status manager to stop monitoring the health of the given daemonsets
 func (m *statusManager) RemoveDaemonsets(dss ...types.NamespacedName) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 	for _, ds := range dss {
 		delete(m.daemonsets, ds.String())
+		delete(m.podSecurity, podSecurityKey("DaemonSet", ds))
 	}
 }
 
@@ -360,6 +457,7 @@ func (m *statusManager) RemoveDeployments(dps ...types.NamespacedName) {
 	defer m.lock.Unlock()
 	for _, dp := range dps {
 		delete(m.deployments, dp.String())
+		delete(m.podSecurity, podSecurityKey("Deployment", dp))
 	}
 }
 
@@ -369,6 +467,7 @@ func (m *statusManager) RemoveStatefulSets(sss ...types.NamespacedName) {
 	defer m.lock.Unlock()
 	for _, ss := range sss {
 		delete(m.statefulsets, ss.String())
+		delete(m.podSecurity, podSecurityKey("StatefulSet", ss))
 	}
 }
 
@@ -378,6 +477,7 @@ func (m *statusManager) RemoveCronJobs(cjs ...types.NamespacedName) {
 	defer m.lock.Unlock()
 	for _, cj := range cjs {
 		delete(m.cronjobs, cj.String())
+		delete(m.podSecurity, podSecurityKey("CronJob", cj))
 	}
 }
 
@@ -764,6 +864,70 @@ func (m *statusManager) setProgressing(reason, msg string) {
 	m.set(true, conditions...)
 }
 
+// setNodesDegraded sets the NodesDegraded condition from the reported node health. The condition
+// is left alone when no node health has been reported.
+func (m *statusManager) setNodesDegraded() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.nodeHealth == nil {
+		return
+	}
+	condition := operator.TigeraStatusCondition{Type: operator.ComponentNodesDegraded, Status: operator.ConditionFalse}
+	if msg := m.nodeHealth.message(); msg != "" {
+		condition.Status = operator.ConditionTrue
+		condition.Reason = "Some nodes are unhealthy"
+		condition.Message = msg
+	}
+	m.set(true, condition)
+}
+
+// setPodSecurityRelaxed sets the PodSecurityRelaxed condition from the reported pod security requirements. The
+// condition is left alone when no workloads have been reported.
+func (m *statusManager) setPodSecurityRelaxed() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.podSecurity == nil {
+		return
+	}
+	condition := operator.TigeraStatusCondition{Type: operator.ComponentPodSecurityRelaxed, Status: operator.ConditionFalse}
+	if len(m.podSecurity) != 0 {
+		lines := []string{}
+		for _, req := range m.podSecurity {
+			lines = append(lines, req.String())
+		}
+		sort.Strings(lines)
+		condition.Status = operator.ConditionTrue
+		condition.Reason = "Some workloads need a less restrictive pod security standard than restricted"
+		condition.Message = strings.Join(lines, "\n")
+	}
+	m.set(true, condition)
+}
+
+// setThreatFeedsFailing sets the ThreatFeedsFailing condition from the reported threat feed errors. The condition is
+// left alone when no threat feeds have been reported.
+func (m *statusManager) setThreatFeedsFailing() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.threatFeedErrors == nil {
+		return
+	}
+	condition := operator.TigeraStatusCondition{Type: operator.ComponentThreatFeedsFailing, Status: operator.ConditionFalse}
+	if len(m.threatFeedErrors) != 0 {
+		lines := []string{}
+		for name, errs := range m.threatFeedErrors {
+			lines = append(lines, fmt.Sprintf("%s: %s", name, strings.Join(errs, "; ")))
+		}
+		sort.Strings(lines)
+		condition.Status = operator.ConditionTrue
+		condition.Reason = "Some threat feeds are failing"
+		condition.Message = strings.Join(lines, "\n")
+	}
+	m.set(true, condition)
+}
+
 func (m *statusManager) clearDegraded() {
 	m.lock.Lock()
 	defer m.lock.Unlock()
@@ -22,6 +22,7 @@ import (
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
+	appsv1 "k8s.io/api/apps/v1"
 	certV1 "k8s.io/api/certificates/v1"
 	certV1beta1 "k8s.io/api/certificates/v1beta1"
 	corev1 "k8s.io/api/core/v1"
@@ -36,6 +37,7 @@ import (
 	operator "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
 	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 )
 
 var _ = Describe("Status reporting tests", func() {
@@ -453,6 +455,17 @@ var _ = Describe("Status reporting tests", func() {
 				Expect(sm.IsDegraded()).To(Equal(false))
 			})
 
+			It("should report the nodes whose upgrade failed", func() {
+				sm.SetWindowsUpgradeStatus([]string{"n3"}, []string{"n1", "n2"}, []string{}, fmt.Errorf("upgrade did not complete on nodes: n2"))
+				Expect(sm.IsDegraded()).To(Equal(true))
+				Expect(sm.degradedMessage()).To(Equal("upgrade did not complete on nodes: n2"))
+				Expect(sm.windowsNodeUpgrades.progressingReason()).To(ContainSubstring("(in-progress: n1, n2; pending: n3)"))
+
+				// An error without nodes leaves the nodes unchanged.
+				sm.SetWindowsUpgradeStatus(nil, nil, nil, fmt.Errorf("an error"))
+				Expect(sm.windowsNodeUpgrades.nodesInProgress).To(Equal([]string{"n1", "n2"}))
+			})
+
 			It("should be able to clear degraded", func() {
 				sm.SetWindowsUpgradeStatus(nil, nil, nil, fmt.Errorf("an error"))
 				Expect(sm.IsDegraded()).To(Equal(true))
@@ -463,26 +476,168 @@ var _ = Describe("Status reporting tests", func() {
 
 			It("should report the windows node upgrade status", func() {
 				sm.SetWindowsUpgradeStatus([]string{"n1", "n2", "n3"}, []string{}, []string{}, nil)
-				Expect(sm.windowsNodeUpgrades.progressingReason()).To(Equal("Waiting for Calico for Windows to be upgraded: 0/3 nodes have been upgraded, 0 in-progress"))
+				Expect(sm.windowsNodeUpgrades.progressingReason()).To(Equal("Waiting for Calico for Windows to be upgraded: 0/3 nodes have been upgraded, 0 in-progress (pending: n1, n2, n3)"))
 
 				sm.SetWindowsUpgradeStatus([]string{"n2", "n3"}, []string{"n1"}, []string{}, nil)
-				Expect(sm.windowsNodeUpgrades.progressingReason()).To(Equal("Waiting for Calico for Windows to be upgraded: 0/3 nodes have been upgraded, 1 in-progress"))
+				Expect(sm.windowsNodeUpgrades.progressingReason()).To(Equal("Waiting for Calico for Windows to be upgraded: 0/3 nodes have been upgraded, 1 in-progress (in-progress: n1; pending: n2, n3)"))
 
 				sm.SetWindowsUpgradeStatus([]string{"n3"}, []string{"n1", "n2"}, []string{}, nil)
-				Expect(sm.windowsNodeUpgrades.progressingReason()).To(Equal("Waiting for Calico for Windows to be upgraded: 0/3 nodes have been upgraded, 2 in-progress"))
+				Expect(sm.windowsNodeUpgrades.progressingReason()).To(Equal("Waiting for Calico for Windows to be upgraded: 0/3 nodes have been upgraded, 2 in-progress (in-progress: n1, n2; pending: n3)"))
 
 				sm.SetWindowsUpgradeStatus([]string{"n3"}, []string{"n2"}, []string{"n1"}, nil)
-				Expect(sm.windowsNodeUpgrades.progressingReason()).To(Equal("Waiting for Calico for Windows to be upgraded: 1/3 nodes have been upgraded, 1 in-progress"))
+				Expect(sm.windowsNodeUpgrades.progressingReason()).To(Equal("Waiting for Calico for Windows to be upgraded: 1/3 nodes have been upgraded, 1 in-progress (in-progress: n2; pending: n3)"))
 
 				sm.SetWindowsUpgradeStatus([]string{"n3"}, []string{}, []string{"n1", "n2"}, nil)
-				Expect(sm.windowsNodeUpgrades.progressingReason()).To(Equal("Waiting for Calico for Windows to be upgraded: 2/3 nodes have been upgraded, 0 in-progress"))
+				Expect(sm.windowsNodeUpgrades.progressingReason()).To(Equal("Waiting for Calico for Windows to be upgraded: 2/3 nodes have been upgraded, 0 in-progress (pending: n3)"))
 
 				sm.SetWindowsUpgradeStatus([]string{}, []string{"n3"}, []string{"n1", "n2"}, nil)
-				Expect(sm.windowsNodeUpgrades.progressingReason()).To(Equal("Waiting for Calico for Windows to be upgraded: 2/3 nodes have been upgraded, 1 in-progress"))
+				Expect(sm.windowsNodeUpgrades.progressingReason()).To(Equal("Waiting for Calico for Windows to be upgraded: 2/3 nodes have been upgraded, 1 in-progress (in-progress: n3)"))
 
 				sm.SetWindowsUpgradeStatus([]string{}, []string{}, []string{"n1", "n2", "n3"}, nil)
 				Expect(sm.windowsNodeUpgrades.progressingReason()).To(Equal(""))
 			})
 		})
+
+		Context("Node health", func() {
+			nodesDegraded := func() *operator.TigeraStatusCondition {
+				ts := &operator.TigeraStatus{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: "test-component"}, ts)).NotTo(HaveOccurred())
+				for _, c := range ts.Status.Conditions {
+					if c.Type == operator.ComponentNodesDegraded {
+						return &c
+					}
+				}
+				return nil
+			}
+
+			It("should list the unhealthy nodes in the message", func() {
+				health := &NodeHealth{
+					BGPPeersDown: []string{"n1"},
+					NotReady:     []string{"n2", "n3"},
+				}
+				Expect(health.message()).To(Equal("1 node has BGP peers down: n1\n2 nodes have calico-node not ready: n2, n3"))
+				Expect((&NodeHealth{}).message()).To(Equal(""))
+			})
+
+			It("should set the NodesDegraded condition without affecting Degraded", func() {
+				sm.ClearDegraded()
+				sm.SetNodeHealth(&NodeHealth{BGPDaemonNotReady: []string{"n1"}})
+				sm.setNodesDegraded()
+				Expect(sm.IsDegraded()).To(BeFalse())
+
+				c := nodesDegraded()
+				Expect(c).NotTo(BeNil())
+				Expect(c.Status).To(Equal(operator.ConditionTrue))
+				Expect(c.Reason).To(Equal("Some nodes are unhealthy"))
+				Expect(c.Message).To(Equal("1 node has the BGP daemon not ready: n1"))
+
+				By("clearing the condition once the nodes are healthy")
+				sm.SetNodeHealth(&NodeHealth{})
+				sm.setNodesDegraded()
+				c = nodesDegraded()
+				Expect(c.Status).To(Equal(operator.ConditionFalse))
+				Expect(c.Message).To(Equal(""))
+			})
+
+			It("should not set the NodesDegraded condition when the node health is unknown", func() {
+				sm.SetNodeHealth(nil)
+				sm.setNodesDegraded()
+				Expect(client.Get(ctx, types.NamespacedName{Name: "test-component"}, &operator.TigeraStatus{})).NotTo(Succeed())
+			})
+		})
+
+		Context("Pod security requirements", func() {
+			podSecurityRelaxed := func() *operator.TigeraStatusCondition {
+				ts := &operator.TigeraStatus{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: "test-component"}, ts)).NotTo(HaveOccurred())
+				for _, c := range ts.Status.Conditions {
+					if c.Type == operator.ComponentPodSecurityRelaxed {
+						return &c
+					}
+				}
+				return nil
+			}
+
+			daemonSet := func(name string, hostNetwork bool) *appsv1.DaemonSet {
+				return &appsv1.DaemonSet{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "NS1", Name: name},
+					Spec: appsv1.DaemonSetSpec{
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								HostNetwork: hostNetwork,
+								Containers:  []corev1.Container{{Name: "c", SecurityContext: podsecuritycontext.NewBaseContext()}},
+							},
+						},
+					},
+				}
+			}
+
+			It("should list the workloads that need a less restrictive pod security standard", func() {
+				sm.SetPodSecurityRequirements([]controllerRuntimeClient.Object{daemonSet("ds1", true), daemonSet("ds2", false), &corev1.ConfigMap{}})
+				sm.setPodSecurityRelaxed()
+
+				c := podSecurityRelaxed()
+				Expect(c).NotTo(BeNil())
+				Expect(c.Status).To(Equal(operator.ConditionTrue))
+				Expect(c.Message).To(Equal(`DaemonSet NS1/ds1 requires the "privileged" pod security standard: uses the host network`))
+
+				By("clearing the condition once the workload satisfies the restricted pod security standard")
+				sm.SetPodSecurityRequirements([]controllerRuntimeClient.Object{daemonSet("ds1", false)})
+				sm.setPodSecurityRelaxed()
+				c = podSecurityRelaxed()
+				Expect(c.Status).To(Equal(operator.ConditionFalse))
+				Expect(c.Message).To(Equal(""))
+
+				By("forgetting removed workloads")
+				sm.SetPodSecurityRequirements([]controllerRuntimeClient.Object{daemonSet("ds1", true)})
+				sm.RemoveDaemonsets(types.NamespacedName{Namespace: "NS1", Name: "ds1"})
+				sm.setPodSecurityRelaxed()
+				Expect(podSecurityRelaxed().Status).To(Equal(operator.ConditionFalse))
+			})
+
+			It("should not set the PodSecurityRelaxed condition when no workloads have been reported", func() {
+				sm.setPodSecurityRelaxed()
+				Expect(client.Get(ctx, types.NamespacedName{Name: "test-component"}, &operator.TigeraStatus{})).NotTo(Succeed())
+			})
+		})
+
+		Context("Threat feed errors", func() {
+			threatFeedsFailing := func() *operator.TigeraStatusCondition {
+				ts := &operator.TigeraStatus{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: "test-component"}, ts)).NotTo(HaveOccurred())
+				for _, c := range ts.Status.Conditions {
+					if c.Type == operator.ComponentThreatFeedsFailing {
+						return &c
+					}
+				}
+				return nil
+			}
+
+			It("should list the failing threat feeds without degrading the component", func() {
+				sm.SetThreatFeedErrors(map[string][]string{
+					"remote": {"PullFailed: 404 Not Found"},
+					"other":  {"PullFailed: timeout", "SyncFailed: conflict"},
+				})
+				sm.setThreatFeedsFailing()
+
+				c := threatFeedsFailing()
+				Expect(c).NotTo(BeNil())
+				Expect(c.Status).To(Equal(operator.ConditionTrue))
+				Expect(c.Message).To(Equal("other: PullFailed: timeout; SyncFailed: conflict\nremote: PullFailed: 404 Not Found"))
+				Expect(sm.IsDegraded()).To(BeFalse())
+
+				By("clearing the condition once the threat feeds recover")
+				sm.SetThreatFeedErrors(map[string][]string{})
+				sm.setThreatFeedsFailing()
+				c = threatFeedsFailing()
+				Expect(c.Status).To(Equal(operator.ConditionFalse))
+				Expect(c.Message).To(Equal(""))
+			})
+
+			It("should not set the ThreatFeedsFailing condition when no threat feeds have been reported", func() {
+				sm.setThreatFeedsFailing()
+				Expect(client.Get(ctx, types.NamespacedName{Name: "test-component"}, &operator.TigeraStatus{})).NotTo(Succeed())
+			})
+		})
 	})
 })
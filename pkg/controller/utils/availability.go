@@ -0,0 +1,181 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/logstorage/esgateway"
+)
+
+// zoneTopologyKey is the label of the zone of a node.
+const zoneTopologyKey = "topology.kubernetes.io/zone"
+
+// controlPlaneDeployments are the deployments of the control plane components whose availability is configured by
+// the ControlPlaneAvailability of the Installation.
+var controlPlaneDeployments = map[types.NamespacedName]operatorv1.ControlPlaneComponentName{
+	{Name: render.ApiServerServiceAccountName(operatorv1.Calico), Namespace: rmeta.APIServerNamespace(operatorv1.Calico)}:                                 operatorv1.ControlPlaneComponentAPIServer,
+	{Name: render.ApiServerServiceAccountName(operatorv1.TigeraSecureEnterprise), Namespace: rmeta.APIServerNamespace(operatorv1.TigeraSecureEnterprise)}: operatorv1.ControlPlaneComponentAPIServer,
+	{Name: "tigera-manager", Namespace: render.ManagerNamespace}:                                                                                          operatorv1.ControlPlaneComponentManager,
+	{Name: common.KubeControllersDeploymentName, Namespace: common.CalicoNamespace}:                                                                       operatorv1.ControlPlaneComponentKubeControllers,
+	{Name: esgateway.DeploymentName, Namespace: render.ElasticsearchNamespace}:                                                                            operatorv1.ControlPlaneComponentESGateway,
+	{Name: render.ComplianceServerName, Namespace: render.ComplianceNamespace}:                                                                            operatorv1.ControlPlaneComponentComplianceServer,
+	{Name: render.GuardianDeploymentName, Namespace: render.GuardianNamespace}:                                                                            operatorv1.ControlPlaneComponentGuardian,
+	{Name: render.DexObjectName, Namespace: render.DexNamespace}:                                                                                          operatorv1.ControlPlaneComponentDex,
+	{Name: render.PacketCaptureDeploymentName, Namespace: render.PacketCaptureNamespace}:                                                                  operatorv1.ControlPlaneComponentPacketCapture,
+}
+
+// applyControlPlaneAvailability protects the availability of the control plane deployments in objsToCreate that run
+// more than one replica. It adds topology spread constraints to those deployments and returns the objects to create
+// and delete with the PodDisruptionBudgets of the control plane deployments added. A component that renders its own
+// PodDisruptionBudget keeps it.
+func (c componentHandler) applyControlPlaneAvailability(ctx context.Context, objsToCreate, objsToDelete []client.Object) ([]client.Object, []client.Object, error) {
+	rendered := map[types.NamespacedName]bool{}
+	found := false
+	for _, objs := range [][]client.Object{objsToCreate, objsToDelete} {
+		for _, obj := range objs {
+			if _, ok := obj.(*apps.Deployment); ok {
+				if _, ok := controlPlaneDeployments[client.ObjectKeyFromObject(obj)]; ok {
+					found = true
+				}
+			}
+		}
+	}
+	for _, obj := range objsToCreate {
+		if _, ok := obj.(*policyv1.PodDisruptionBudget); ok {
+			rendered[client.ObjectKeyFromObject(obj)] = true
+		}
+	}
+	if !found {
+		return objsToCreate, objsToDelete, nil
+	}
+
+	var availability []operatorv1.ControlPlaneAvailability
+	_, installation, err := GetInstallation(ctx, c.client)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, nil, err
+	} else if err == nil {
+		availability = installation.ControlPlaneAvailability
+	}
+
+	var pdbsToCreate, pdbsToDelete []client.Object
+	for _, obj := range objsToCreate {
+		d, ok := obj.(*apps.Deployment)
+		if !ok {
+			continue
+		}
+		key := client.ObjectKeyFromObject(d)
+		name, ok := controlPlaneDeployments[key]
+		if !ok {
+			continue
+		}
+		cfg := controlPlaneAvailability(availability, name)
+
+		// The selector of the deployment is needed for the PodDisruptionBudget and the spread constraints.
+		setStandardSelectorAndLabels(d)
+		pdb := podDisruptionBudget(d, cfg.PodDisruptionBudget)
+		// A deployment that is scaled by a HorizontalPodAutoscaler may run more than one replica.
+		multiReplica := (d.Spec.Replicas != nil && *d.Spec.Replicas > 1) || d.Annotations[autoscaling.HorizontalPodAutoscalerAnnotation] != ""
+		if !multiReplica || (cfg.Mode != nil && *cfg.Mode == operatorv1.ControlPlaneAvailabilityDisabled) {
+			if !rendered[key] {
+				pdbsToDelete = append(pdbsToDelete, pdb)
+			}
+			continue
+		}
+		if !rendered[key] {
+			pdbsToCreate = append(pdbsToCreate, pdb)
+		}
+		if len(d.Spec.Template.Spec.TopologySpreadConstraints) == 0 {
+			d.Spec.Template.Spec.TopologySpreadConstraints = topologySpreadConstraints(d.Spec.Selector, cfg.TopologySpreadConstraints)
+		}
+	}
+	for _, obj := range objsToDelete {
+		if d, ok := obj.(*apps.Deployment); ok {
+			key := client.ObjectKeyFromObject(d)
+			if _, ok := controlPlaneDeployments[key]; ok && !rendered[key] {
+				pdbsToDelete = append(pdbsToDelete, podDisruptionBudget(d, nil))
+			}
+		}
+	}
+	return append(objsToCreate, pdbsToCreate...), append(objsToDelete, pdbsToDelete...), nil
+}
+
+// controlPlaneAvailability returns the availability configured for a component, which is empty if there is none.
+func controlPlaneAvailability(availability []operatorv1.ControlPlaneAvailability, name operatorv1.ControlPlaneComponentName) operatorv1.ControlPlaneAvailability {
+	for _, a := range availability {
+		if a.ComponentName == name {
+			return a
+		}
+	}
+	return operatorv1.ControlPlaneAvailability{ComponentName: name}
+}
+
+// podDisruptionBudget returns the PodDisruptionBudget of a deployment. One pod may be unavailable unless a budget is
+// configured.
+func podDisruptionBudget(d *apps.Deployment, budget *operatorv1.PodDisruptionBudgetSpec) *policyv1.PodDisruptionBudget {
+	pdb := &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{Kind: "PodDisruptionBudget", APIVersion: "policy/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      d.Name,
+			Namespace: d.Namespace,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: d.Spec.Selector.DeepCopy(),
+		},
+	}
+	if budget != nil {
+		pdb.Spec.MinAvailable = budget.MinAvailable
+		pdb.Spec.MaxUnavailable = budget.MaxUnavailable
+	} else {
+		maxUnavailable := intstr.FromInt(1)
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+	}
+	return pdb
+}
+
+// topologySpreadConstraints returns the configured constraints, which select the pods of the deployment unless they
+// specify a selector, or the default constraint, which spreads the pods across zones where possible.
+func topologySpreadConstraints(selector *metav1.LabelSelector, configured []v1.TopologySpreadConstraint) []v1.TopologySpreadConstraint {
+	if len(configured) == 0 {
+		return []v1.TopologySpreadConstraint{{
+			MaxSkew:           1,
+			TopologyKey:       zoneTopologyKey,
+			WhenUnsatisfiable: v1.ScheduleAnyway,
+			LabelSelector:     selector.DeepCopy(),
+		}}
+	}
+	constraints := make([]v1.TopologySpreadConstraint, len(configured))
+	for i := range configured {
+		configured[i].DeepCopyInto(&constraints[i])
+		if constraints[i].LabelSelector == nil {
+			constraints[i].LabelSelector = selector.DeepCopy()
+		}
+	}
+	return constraints
+}
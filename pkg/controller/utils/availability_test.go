@@ -0,0 +1,190 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/apis"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/status"
+	"github.com/tigera/operator/pkg/render"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+var _ = Describe("Control plane availability", func() {
+	var (
+		c       client.Client
+		ctx     context.Context
+		sm      status.StatusManager
+		handler ComponentHandler
+	)
+
+	managerKey := client.ObjectKey{Name: "tigera-manager", Namespace: render.ManagerNamespace}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(corev1.SchemeBuilder.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(apps.SchemeBuilder.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(policyv1.SchemeBuilder.AddToScheme(scheme)).NotTo(HaveOccurred())
+
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		ctx = context.Background()
+		sm = status.New(c, "fake-component", &common.VersionInfo{Major: 1, Minor: 19})
+		instance := &operatorv1.Manager{
+			TypeMeta:   metav1.TypeMeta{Kind: "Manager", APIVersion: "operator.tigera.io/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+		}
+		handler = NewComponentHandler(logf.Log.WithName("test_utils_logger"), c, scheme, instance, nil)
+	})
+
+	deployment := func(key client.ObjectKey, replicas int32) *apps.Deployment {
+		return &apps.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: apps.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: key.Name, Image: "example.com/" + key.Name}},
+					},
+				},
+			},
+		}
+	}
+
+	component := func(objs ...client.Object) *fakeComponent {
+		return &fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: objs}
+	}
+
+	createInstallation := func(availability ...operatorv1.ControlPlaneAvailability) {
+		Expect(c.Create(ctx, &operatorv1.Installation{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec:       operatorv1.InstallationSpec{ControlPlaneAvailability: availability},
+		})).NotTo(HaveOccurred())
+	}
+
+	It("protects control plane deployments with more than one replica by default", func() {
+		Expect(handler.CreateOrUpdateOrDelete(ctx, component(deployment(managerKey, 2)), sm)).NotTo(HaveOccurred())
+
+		d := &apps.Deployment{}
+		Expect(c.Get(ctx, managerKey, d)).NotTo(HaveOccurred())
+		selector := &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "tigera-manager"}}
+		Expect(d.Spec.Template.Spec.TopologySpreadConstraints).To(ConsistOf(corev1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     selector,
+		}))
+
+		pdb := &policyv1.PodDisruptionBudget{}
+		Expect(c.Get(ctx, managerKey, pdb)).NotTo(HaveOccurred())
+		Expect(pdb.Spec.Selector).To(Equal(selector))
+		Expect(pdb.Spec.MinAvailable).To(BeNil())
+		Expect(*pdb.Spec.MaxUnavailable).To(Equal(intstr.FromInt(1)))
+
+		By("removing the PodDisruptionBudget when the deployment is scaled down to one replica")
+		Expect(handler.CreateOrUpdateOrDelete(ctx, component(deployment(managerKey, 1)), sm)).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, managerKey, &policyv1.PodDisruptionBudget{})).NotTo(Succeed())
+	})
+
+	It("does not protect deployments that are not part of the control plane", func() {
+		key := client.ObjectKey{Name: "test-deployment", Namespace: "test-namespace"}
+		Expect(handler.CreateOrUpdateOrDelete(ctx, component(deployment(key, 2)), sm)).NotTo(HaveOccurred())
+
+		d := &apps.Deployment{}
+		Expect(c.Get(ctx, key, d)).NotTo(HaveOccurred())
+		Expect(d.Spec.Template.Spec.TopologySpreadConstraints).To(BeEmpty())
+		Expect(c.Get(ctx, key, &policyv1.PodDisruptionBudget{})).NotTo(Succeed())
+	})
+
+	It("applies the availability configured on the Installation", func() {
+		minAvailable := intstr.FromString("50%")
+		createInstallation(operatorv1.ControlPlaneAvailability{
+			ComponentName:       operatorv1.ControlPlaneComponentManager,
+			PodDisruptionBudget: &operatorv1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable},
+			TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+				MaxSkew:           2,
+				TopologyKey:       "kubernetes.io/hostname",
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+			}},
+		})
+		Expect(handler.CreateOrUpdateOrDelete(ctx, component(deployment(managerKey, 3)), sm)).NotTo(HaveOccurred())
+
+		d := &apps.Deployment{}
+		Expect(c.Get(ctx, managerKey, d)).NotTo(HaveOccurred())
+		Expect(d.Spec.Template.Spec.TopologySpreadConstraints).To(ConsistOf(corev1.TopologySpreadConstraint{
+			MaxSkew:           2,
+			TopologyKey:       "kubernetes.io/hostname",
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "tigera-manager"}},
+		}))
+
+		pdb := &policyv1.PodDisruptionBudget{}
+		Expect(c.Get(ctx, managerKey, pdb)).NotTo(HaveOccurred())
+		Expect(*pdb.Spec.MinAvailable).To(Equal(minAvailable))
+		Expect(pdb.Spec.MaxUnavailable).To(BeNil())
+	})
+
+	It("does not protect control plane deployments whose availability is disabled", func() {
+		disabled := operatorv1.ControlPlaneAvailabilityDisabled
+		createInstallation(operatorv1.ControlPlaneAvailability{
+			ComponentName: operatorv1.ControlPlaneComponentManager,
+			Mode:          &disabled,
+		})
+		Expect(handler.CreateOrUpdateOrDelete(ctx, component(deployment(managerKey, 2)), sm)).NotTo(HaveOccurred())
+
+		d := &apps.Deployment{}
+		Expect(c.Get(ctx, managerKey, d)).NotTo(HaveOccurred())
+		Expect(d.Spec.Template.Spec.TopologySpreadConstraints).To(BeEmpty())
+		Expect(c.Get(ctx, managerKey, &policyv1.PodDisruptionBudget{})).NotTo(Succeed())
+	})
+
+	It("keeps the PodDisruptionBudget rendered by a component and removes it with a deleted deployment", func() {
+		h := handler.(*componentHandler)
+		minAvailable := intstr.FromInt(2)
+		rendered := &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: managerKey.Name, Namespace: managerKey.Namespace},
+			Spec:       policyv1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable},
+		}
+		toCreate, toDelete, err := h.applyControlPlaneAvailability(ctx, []client.Object{deployment(managerKey, 3), rendered}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(toCreate).To(HaveLen(2))
+		Expect(toCreate[1]).To(BeIdenticalTo(rendered))
+		Expect(toDelete).To(BeEmpty())
+
+		toCreate, toDelete, err = h.applyControlPlaneAvailability(ctx, nil, []client.Object{deployment(managerKey, 3)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(toCreate).To(BeEmpty())
+		Expect(toDelete).To(HaveLen(2))
+		Expect(toDelete[1]).To(BeAssignableToTypeOf(&policyv1.PodDisruptionBudget{}))
+		Expect(toDelete[1].GetName()).To(Equal(managerKey.Name))
+	})
+})
@@ -18,7 +18,10 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -31,6 +34,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -40,31 +44,57 @@ import (
 
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/controller/status"
+	"github.com/tigera/operator/pkg/imageregistry"
+	"github.com/tigera/operator/pkg/imagesignature"
 	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 )
 
 type ComponentHandler interface {
 	CreateOrUpdateOrDelete(context.Context, render.Component, status.StatusManager) error
 }
 
+// ImageSignaturePublicKey is the key of the Secret configured in the ImageSignatureVerification of the Installation
+// that holds the public key.
+const ImageSignaturePublicKey = "cosign.pub"
+
+// imageSignatureVerificationTimeout bounds the time spent verifying the images of a component.
+const imageSignatureVerificationTimeout = 2 * time.Minute
+
+// imageVerifier is shared by all component handlers so that verification results are cached across controllers.
+var imageVerifier imagesignature.Verifier = imagesignature.NewCosignVerifier()
+
 // cr is allowed to be nil in the case we don't want to put ownership on a resource,
 // this is useful for CRD management so that they are not removed automatically.
-func NewComponentHandler(log logr.Logger, client client.Client, scheme *runtime.Scheme, cr metav1.Object) ComponentHandler {
+// installation is the Installation the component is rendered for; the signatures of the images of the component are
+// verified if it configures image signature verification. It may be nil for components without images.
+func NewComponentHandler(log logr.Logger, client client.Client, scheme *runtime.Scheme, cr metav1.Object, installation *operatorv1.InstallationSpec) ComponentHandler {
 	return &componentHandler{
-		client: client,
-		scheme: scheme,
-		cr:     cr,
-		log:    log,
+		client:       client,
+		scheme:       scheme,
+		cr:           cr,
+		log:          log,
+		installation: installation,
+		verifier:     imageVerifier,
+
+		podSecurityChecks: podSecurityChecks,
 	}
 }
 
 type componentHandler struct {
-	client client.Client
-	scheme *runtime.Scheme
-	cr     metav1.Object
-	log    logr.Logger
+	client       client.Client
+	scheme       *runtime.Scheme
+	cr           metav1.Object
+	log          logr.Logger
+	installation *operatorv1.InstallationSpec
+	verifier     imagesignature.Verifier
+
+	podSecurityChecks *podSecurityCheckCache
 }
 
 func (c componentHandler) CreateOrUpdateOrDelete(ctx context.Context, component render.Component, status status.StatusManager) error {
@@ -88,19 +118,23 @@ func (c componentHandler) CreateOrUpdateOrDelete(ctx context.Context, component
 	objsToCreate, objsToDelete := component.Objects()
 	osType := component.SupportedOSType()
 
-	for _, obj := range objsToCreate {
-		om, ok := obj.(metav1.ObjectMetaAccessor)
-		if !ok {
-			return fmt.Errorf("Object is not ObjectMetaAccessor")
-		}
+	if err := c.verifyImageSignatures(ctx, objsToCreate); err != nil {
+		return err
+	}
+
+	objsToCreate, objsToDelete, err := c.applyControlPlaneAvailability(ctx, objsToCreate, objsToDelete)
+	if err != nil {
+		return err
+	}
 
+	for _, obj := range objsToCreate {
 		// Add owner ref for controller owned resources,
 		switch obj.(type) {
 		case *v3.UISettings:
 			// Never add controller ref for UISettings since these are always GCd through the UISettingsGroup.
 		default:
 			if c.cr != nil {
-				if err := controllerutil.SetControllerReference(c.cr, om.GetObjectMeta(), c.scheme); err != nil {
+				if err := controllerutil.SetControllerReference(c.cr, obj, c.scheme); err != nil {
 					return err
 				}
 			}
@@ -119,6 +153,11 @@ func (c componentHandler) CreateOrUpdateOrDelete(ctx context.Context, component
 		// Make sure we have our standard selector and pod labels
 		setStandardSelectorAndLabels(obj)
 
+		// Make sure the pods will be admitted if the pod security standard of the namespace has been configured.
+		if err := c.validatePodSecurityStandard(ctx, obj); err != nil {
+			return err
+		}
+
 		// Keep track of some objects so we can report on their status.
 		switch obj.(type) {
 		case *apps.Deployment:
@@ -209,11 +248,13 @@ func (c componentHandler) CreateOrUpdateOrDelete(ctx context.Context, component
 		status.AddDeployments(deployments)
 		status.AddStatefulSets(statefulsets)
 		status.AddCronJobs(cronJobs)
+		status.SetPodSecurityRequirements(objsToCreate)
 	}
 
 	for _, obj := range objsToDelete {
 		err := c.client.Delete(ctx, obj)
-		if err != nil && !errors.IsNotFound(err) {
+		// An object cannot exist if its API is not installed, e.g. an optional CRD.
+		if err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
 			logCtx := ContextLoggerForResource(c.log, obj)
 			logCtx.Error(err, fmt.Sprintf("Error deleting object %v", obj))
 			return err
@@ -244,8 +285,9 @@ func (c componentHandler) CreateOrUpdateOrDelete(ctx context.Context, component
 
 // mergeState returns the object to pass to Update given the current and desired object states.
 func mergeState(desired client.Object, current runtime.Object) client.Object {
-	currentMeta := current.(metav1.ObjectMetaAccessor).GetObjectMeta()
-	desiredMeta := desired.(metav1.ObjectMetaAccessor).GetObjectMeta()
+	// Use the metadata accessors of the objects themselves so that unstructured objects are supported too.
+	currentMeta := current.(metav1.Object)
+	desiredMeta := metav1.Object(desired)
 
 	// Merge common metadata fields if not present on the desired state.
 	if desiredMeta.GetResourceVersion() == "" {
@@ -262,6 +304,18 @@ func mergeState(desired client.Object, current runtime.Object) client.Object {
 	// as-is.
 	currentAnnotations := mapExistsOrInitialize(currentMeta.GetAnnotations())
 	desiredAnnotations := mapExistsOrInitialize(desiredMeta.GetAnnotations())
+	if _, ok := desired.(*v1.Namespace); ok {
+		currentAnnotations = withoutKeys(currentAnnotations, func(k string) bool {
+			return k == render.PodSecurityStandardValidationAnnotation
+		})
+	}
+	if _, ok := desired.(*apps.Deployment); ok {
+		// Drop the autoscaling annotation once autoscaling is no longer configured, so that the operator takes back
+		// control of the replicas.
+		currentAnnotations = withoutKeys(currentAnnotations, func(k string) bool {
+			return k == autoscaling.HorizontalPodAutoscalerAnnotation
+		})
+	}
 	mergedAnnotations := mergeMaps(currentAnnotations, desiredAnnotations)
 	desiredMeta.SetAnnotations(mergedAnnotations)
 
@@ -269,6 +323,18 @@ func mergeState(desired client.Object, current runtime.Object) client.Object {
 	// as-is.
 	currentLabels := mapExistsOrInitialize(currentMeta.GetLabels())
 	desiredLabels := mapExistsOrInitialize(desiredMeta.GetLabels())
+	if _, ok := desired.(*v1.Namespace); ok {
+		// The pod security labels of our namespaces are configured through the Installation, so drop the ones that
+		// the operator set but no longer renders, e.g. an audit level that has been removed. Pod security labels
+		// that were added by the user are left alone.
+		operatorLabels := map[string]bool{}
+		for _, k := range strings.Split(currentAnnotations[render.PodSecurityLabelsAnnotation], ",") {
+			operatorLabels[k] = true
+		}
+		currentLabels = withoutKeys(currentLabels, func(k string) bool {
+			return strings.HasPrefix(k, render.PodSecurityLabelPrefix) && operatorLabels[k]
+		})
+	}
 	mergedLabels := mergeMaps(currentLabels, desiredLabels)
 	desiredMeta.SetLabels(mergedLabels)
 
@@ -297,8 +363,9 @@ func mergeState(desired client.Object, current runtime.Object) client.Object {
 		dd := desired.(*apps.Deployment)
 		// Only take the replica count if our desired count is nil so that
 		// any Deployments where we specify a replica count we will retain
-		// control over the count.
-		if dd.Spec.Replicas == nil {
+		// control over the count. The replicas of Deployments that are scaled
+		// by a HorizontalPodAutoscaler are left to the autoscaler.
+		if dd.Spec.Replicas == nil || dd.Annotations[autoscaling.HorizontalPodAutoscalerAnnotation] != "" {
 			dd.Spec.Replicas = cd.Spec.Replicas
 		}
 
@@ -418,6 +485,195 @@ func modifyPodSpec(obj client.Object, f func(*v1.PodSpec)) {
 	}
 }
 
+// verifyImageSignatures checks the signatures of the images of objs when image signature verification is configured
+// on the Installation, and returns an error listing the images that could not be verified. The images of objs are
+// replaced with references by the digests that were verified.
+func (c componentHandler) verifyImageSignatures(ctx context.Context, objs []client.Object) error {
+	if c.verifier == nil || c.installation == nil || c.installation.ImageSignatureVerification == nil {
+		return nil
+	}
+	installation := c.installation
+	images := map[string]bool{}
+	for _, obj := range objs {
+		modifyPodSpec(obj, func(spec *v1.PodSpec) {
+			for _, container := range spec.InitContainers {
+				images[container.Image] = true
+			}
+			for _, container := range spec.Containers {
+				images[container.Image] = true
+			}
+		})
+	}
+	if len(images) == 0 {
+		return nil
+	}
+
+	secret := &v1.Secret{}
+	key := client.ObjectKey{Name: installation.ImageSignatureVerification.PublicKeySecretName, Namespace: common.OperatorNamespace()}
+	if err := c.client.Get(ctx, key, secret); err != nil {
+		return fmt.Errorf("failed to get the image signature public key secret %s: %w", key, err)
+	}
+	publicKey, ok := secret.Data[ImageSignaturePublicKey]
+	if !ok {
+		return fmt.Errorf("image signature public key secret %s does not contain %s", key, ImageSignaturePublicKey)
+	}
+
+	// The signatures are stored next to the images, so they are fetched with the credentials used to pull the images.
+	pullSecrets, err := GetNetworkingPullSecrets(installation, c.client)
+	if err != nil {
+		return fmt.Errorf("failed to get the image pull secrets: %w", err)
+	}
+	keychain := imageregistry.Keychain{}
+	for _, s := range pullSecrets {
+		var err error
+		switch s.Type {
+		case v1.SecretTypeDockerConfigJson:
+			err = keychain.AddDockerConfig(s.Data[v1.DockerConfigJsonKey], false)
+		case v1.SecretTypeDockercfg:
+			err = keychain.AddDockerConfig(s.Data[v1.DockerConfigKey], true)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid image pull secret %s: %w", s.Name, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, imageSignatureVerificationTimeout)
+	defer cancel()
+
+	var failed []string
+	pinned := map[string]string{}
+	for image := range images {
+		ref, err := c.verifier.Verify(ctx, image, publicKey, keychain)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%s)", image, err))
+			continue
+		}
+		pinned[image] = ref
+	}
+	if len(failed) != 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("image signature verification failed for %s", strings.Join(failed, ", "))
+	}
+
+	// Reference the images by the verified digests, otherwise a tag could be moved to an unsigned image between the
+	// verification and the kubelet pulling it.
+	for _, obj := range objs {
+		modifyPodSpec(obj, func(spec *v1.PodSpec) {
+			for i := range spec.InitContainers {
+				spec.InitContainers[i].Image = pinned[spec.InitContainers[i].Image]
+			}
+			for i := range spec.Containers {
+				spec.Containers[i].Image = pinned[spec.Containers[i].Image]
+			}
+		})
+	}
+	return nil
+}
+
+// podSecurityViolation is part of the message with which pod security admission rejects a pod.
+const podSecurityViolation = "violates PodSecurity"
+
+// podSecurityChecks is shared by all component handlers so that a workload is only checked again once its pod
+// template or the enforce level of its namespace changes.
+var podSecurityChecks = newPodSecurityCheckCache()
+
+// podSecurityCheckCache holds the outcome of the last pod security check of each workload along with a hash of the
+// inputs of the check.
+type podSecurityCheckCache struct {
+	mu      sync.Mutex
+	results map[string]podSecurityCheck
+}
+
+type podSecurityCheck struct {
+	hash string
+	err  error
+}
+
+func newPodSecurityCheckCache() *podSecurityCheckCache {
+	return &podSecurityCheckCache{results: map[string]podSecurityCheck{}}
+}
+
+func (c *podSecurityCheckCache) get(key, hash string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.results[key]
+	if !ok || r.hash != hash {
+		return false, nil
+	}
+	return true, r.err
+}
+
+func (c *podSecurityCheckCache) set(key, hash string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[key] = podSecurityCheck{hash: hash, err: err}
+}
+
+// validatePodSecurityStandard checks that the pods of obj satisfy the enforce level of their namespace when that level
+// has been configured on the Installation. The check is a dry-run server-side apply of a pod built from the pod
+// template, which runs pod security admission without creating anything. The outcome is cached until the pod template
+// or the enforce level changes. If the check can't be run, e.g. because the operator isn't allowed to create pods, an
+// error is returned so that the component is degraded rather than the check silently passing. Such failures aren't
+// cached so that the check is run again on the next reconcile.
+func (c componentHandler) validatePodSecurityStandard(ctx context.Context, obj client.Object) error {
+	var template *v1.PodTemplateSpec
+	switch x := obj.(type) {
+	case *apps.Deployment:
+		template = &x.Spec.Template
+	case *apps.DaemonSet:
+		template = &x.Spec.Template
+	case *apps.StatefulSet:
+		template = &x.Spec.Template
+	case *batchv1.Job:
+		template = &x.Spec.Template
+	case *batchv1beta.CronJob:
+		template = &x.Spec.JobTemplate.Spec.Template
+	default:
+		return nil
+	}
+
+	ns := &v1.Namespace{}
+	if err := c.client.Get(ctx, client.ObjectKey{Name: obj.GetNamespace()}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if ns.Annotations[render.PodSecurityStandardValidationAnnotation] != "true" {
+		return nil
+	}
+
+	level := ns.Labels[render.PodSecurityLabelPrefix+"enforce"]
+	key := fmt.Sprintf("%T/%s/%s", obj, obj.GetNamespace(), obj.GetName())
+	hash := rmeta.AnnotationHash([]interface{}{level, ns.Labels[render.PodSecurityLabelPrefix+"enforce-version"], template})
+	if ok, err := c.podSecurityChecks.get(key, hash); ok {
+		return err
+	}
+
+	pod := &v1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        obj.GetName() + "-pod-security-check",
+			Namespace:   obj.GetNamespace(),
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+		},
+		Spec: *template.Spec.DeepCopy(),
+	}
+	err := c.client.Patch(ctx, pod, client.Apply, client.DryRunAll, client.FieldOwner("tigera-operator"), client.ForceOwnership)
+	if err != nil && !(apierrors.IsForbidden(err) && strings.Contains(err.Error(), podSecurityViolation)) {
+		return fmt.Errorf("unable to check the pods of %s %s/%s against the %q pod security standard configured for namespace %s: %w",
+			obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName(), level, obj.GetNamespace(), err)
+	}
+	if err != nil {
+		required, reasons := podsecuritycontext.Evaluate(&template.Spec)
+		err = fmt.Errorf("pods of %s %s/%s do not satisfy the %q pod security standard configured for namespace %s, they require the %q pod security standard (%s): %w",
+			obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName(), level, obj.GetNamespace(), required, strings.Join(reasons, "; "), err)
+	}
+	c.podSecurityChecks.set(key, hash, err)
+	return err
+}
+
 // setImagePullPolicy ensures that an image pull policy is set if not set already.
 func setImagePullPolicy(podSpec *v1.PodSpec) {
 	for i := range podSpec.Containers {
@@ -518,6 +774,17 @@ func mergeMaps(current, desired map[string]string) map[string]string {
 	return desired
 }
 
+// withoutKeys returns a copy of the map without the keys that match.
+func withoutKeys(m map[string]string, match func(string) bool) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		if !match(k) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
 func mapExistsOrInitialize(m map[string]string) map[string]string {
 	if m != nil {
 		return m
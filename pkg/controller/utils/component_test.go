@@ -17,6 +17,7 @@ package utils
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 
@@ -26,7 +27,9 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
@@ -42,7 +45,11 @@ import (
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
 	"github.com/tigera/operator/pkg/controller/status"
+	"github.com/tigera/operator/pkg/imageregistry"
+	"github.com/tigera/operator/pkg/imagesignature"
+	"github.com/tigera/operator/pkg/ptr"
 	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 
 	. "github.com/onsi/ginkgo"
@@ -90,7 +97,7 @@ var _ = Describe("Component handler tests", func() {
 			TypeMeta:   metav1.TypeMeta{Kind: "Manager", APIVersion: "operator.tigera.io/v1"},
 			ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
 		}
-		handler = NewComponentHandler(log, c, scheme, instance)
+		handler = NewComponentHandler(log, c, scheme, instance, nil)
 	})
 
 	It("merges daemonset template annotations and reconciles only operator added annotations", func() {
@@ -1151,8 +1158,298 @@ var _ = Describe("Component handler tests", func() {
 			Expect(*d.Spec.Selector).To(Equal(expectedSelector))
 		})
 	})
+
+	It("leaves the replicas of deployments that are scaled by a HorizontalPodAutoscaler to the autoscaler", func() {
+		key := client.ObjectKey{Name: "test-deployment", Namespace: "test-namespace"}
+		deployment := func(replicas int32, autoscaled bool) *apps.Deployment {
+			d := &apps.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec:       apps.DeploymentSpec{Replicas: &replicas},
+			}
+			if autoscaled {
+				d.Annotations = map[string]string{autoscaling.HorizontalPodAutoscalerAnnotation: key.Name}
+			}
+			return d
+		}
+		fc := func(d *apps.Deployment) *fakeComponent {
+			return &fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: []client.Object{d}}
+		}
+
+		Expect(handler.CreateOrUpdateOrDelete(ctx, fc(deployment(1, true)), sm)).NotTo(HaveOccurred())
+
+		By("keeping the replicas that the autoscaler set")
+		d := &apps.Deployment{}
+		Expect(c.Get(ctx, key, d)).NotTo(HaveOccurred())
+		d.Spec.Replicas = ptr.Int32ToPtr(4)
+		Expect(c.Update(ctx, d)).NotTo(HaveOccurred())
+		Expect(handler.CreateOrUpdateOrDelete(ctx, fc(deployment(1, true)), sm)).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, key, d)).NotTo(HaveOccurred())
+		Expect(*d.Spec.Replicas).To(Equal(int32(4)))
+
+		By("taking back control of the replicas once the deployment is no longer autoscaled")
+		Expect(handler.CreateOrUpdateOrDelete(ctx, fc(deployment(1, false)), sm)).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, key, d)).NotTo(HaveOccurred())
+		Expect(*d.Spec.Replicas).To(Equal(int32(1)))
+	})
+
+	Context("image signature verification", func() {
+		var verifier *fakeVerifier
+
+		BeforeEach(func() {
+			verifier = &fakeVerifier{unsigned: map[string]bool{}}
+			handler = &componentHandler{
+				client:   c,
+				scheme:   scheme,
+				cr:       instance,
+				log:      logf.Log.WithName("test_utils_logger"),
+				verifier: verifier,
+			}
+		})
+
+		fc := func() *fakeComponent {
+			return &fakeComponent{
+				supportedOSType: rmeta.OSTypeLinux,
+				objs: []client.Object{&apps.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-namespace"},
+					Spec: apps.DeploymentSpec{
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								InitContainers: []corev1.Container{{Name: "init", Image: "example.com/init@sha256:1"}},
+								Containers:     []corev1.Container{{Name: "test", Image: "example.com/test:v2"}},
+							},
+						},
+					},
+				}},
+			}
+		}
+
+		It("does not verify images unless it is configured on the Installation", func() {
+			Expect(handler.CreateOrUpdateOrDelete(ctx, fc(), sm)).NotTo(HaveOccurred())
+			Expect(verifier.verified).To(BeEmpty())
+		})
+
+		Context("when configured on the Installation", func() {
+			BeforeEach(func() {
+				handler.(*componentHandler).installation = &operatorv1.InstallationSpec{
+					ImagePullSecrets:           []corev1.LocalObjectReference{{Name: "pull-secret"}},
+					ImageSignatureVerification: &operatorv1.ImageSignatureVerification{PublicKeySecretName: "image-signing-key"},
+				}
+				Expect(c.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: common.OperatorNamespace()},
+					Type:       corev1.SecretTypeDockerConfigJson,
+					Data: map[string][]byte{
+						corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://example.com/v1/":{"auth":"dXNlcjpwYXNz"}}}`),
+					},
+				})).NotTo(HaveOccurred())
+			})
+
+			It("reports a missing public key", func() {
+				err := handler.CreateOrUpdateOrDelete(ctx, fc(), sm)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("failed to get the image signature public key secret"))
+			})
+
+			It("verifies the images of workloads before applying them", func() {
+				Expect(c.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "image-signing-key", Namespace: common.OperatorNamespace()},
+					Data:       map[string][]byte{ImageSignaturePublicKey: []byte("public-key")},
+				})).NotTo(HaveOccurred())
+
+				Expect(handler.CreateOrUpdateOrDelete(ctx, fc(), sm)).NotTo(HaveOccurred())
+				Expect(verifier.verified).To(ConsistOf("example.com/init@sha256:1", "example.com/test:v2"))
+				Expect(verifier.publicKey).To(Equal("public-key"))
+				Expect(verifier.keychain).To(Equal(imageregistry.Keychain{"example.com": {Username: "user", Password: "pass"}}))
+
+				By("referencing the images by the verified digests")
+				d := &apps.Deployment{}
+				Expect(c.Get(ctx, client.ObjectKey{Name: "test-deployment", Namespace: "test-namespace"}, d)).NotTo(HaveOccurred())
+				Expect(d.Spec.Template.Spec.InitContainers[0].Image).To(Equal("example.com/init@sha256:1"))
+				Expect(d.Spec.Template.Spec.Containers[0].Image).To(Equal("example.com/test@sha256:v2"))
+
+				By("rejecting workloads with unsigned images")
+				verifier.unsigned["example.com/test:v2"] = true
+				Expect(c.Delete(ctx, &apps.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-namespace"}})).NotTo(HaveOccurred())
+				err := handler.CreateOrUpdateOrDelete(ctx, fc(), sm)
+				Expect(err).To(MatchError("image signature verification failed for example.com/test:v2 (no signature found)"))
+				Expect(c.Get(ctx, client.ObjectKey{Name: "test-deployment", Namespace: "test-namespace"}, &apps.Deployment{})).NotTo(Succeed())
+			})
+		})
+	})
+
+	Context("pod security standards", func() {
+		var pc *podSecurityClient
+
+		BeforeEach(func() {
+			pc = &podSecurityClient{Client: c}
+			handler = &componentHandler{
+				client:            pc,
+				scheme:            scheme,
+				cr:                instance,
+				log:               logf.Log.WithName("test_utils_logger"),
+				verifier:          &fakeVerifier{},
+				podSecurityChecks: newPodSecurityCheckCache(),
+			}
+		})
+
+		deployment := func(image string) *apps.Deployment {
+			return &apps.Deployment{
+				TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-namespace"},
+				Spec: apps.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "test", Image: image}},
+						},
+					},
+				},
+			}
+		}
+
+		namespace := func(annotations map[string]string) *corev1.Namespace {
+			return &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-namespace",
+					Labels: map[string]string{
+						"pod-security.kubernetes.io/enforce":         "restricted",
+						"pod-security.kubernetes.io/enforce-version": "latest",
+					},
+					Annotations: annotations,
+				},
+			}
+		}
+
+		It("does not validate pods in namespaces without a configured pod security standard", func() {
+			Expect(c.Create(ctx, namespace(nil))).NotTo(HaveOccurred())
+			pc.reject = podSecurityViolationError("test-deployment-pod-security-check")
+
+			fc := &fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: []client.Object{deployment("test")}}
+			Expect(handler.CreateOrUpdateOrDelete(ctx, fc, sm)).NotTo(HaveOccurred())
+			Expect(pc.checkedPods).To(BeEmpty())
+		})
+
+		It("validates pods in namespaces with a configured pod security standard", func() {
+			Expect(c.Create(ctx, namespace(map[string]string{render.PodSecurityStandardValidationAnnotation: "true"}))).NotTo(HaveOccurred())
+
+			fc := &fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: []client.Object{deployment("test")}}
+			Expect(handler.CreateOrUpdateOrDelete(ctx, fc, sm)).NotTo(HaveOccurred())
+			Expect(pc.checkedPods).To(ConsistOf("test-namespace/test-deployment-pod-security-check"))
+
+			By("not checking the pods again until the pod template changes")
+			pc.reject = podSecurityViolationError("test-deployment-pod-security-check")
+			fc = &fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: []client.Object{deployment("test")}}
+			Expect(handler.CreateOrUpdateOrDelete(ctx, fc, sm)).NotTo(HaveOccurred())
+			Expect(pc.checkedPods).To(HaveLen(1))
+
+			By("rejecting pods that would not be admitted")
+			fc = &fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: []client.Object{deployment("test:v2")}}
+			err := handler.CreateOrUpdateOrDelete(ctx, fc, sm)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`do not satisfy the "restricted" pod security standard`))
+			Expect(err.Error()).To(ContainSubstring(`they require the "baseline" pod security standard (container "test" allows privilege escalation`))
+			Expect(pc.checkedPods).To(HaveLen(2))
+		})
+
+		It("fails when the check can't be run", func() {
+			Expect(c.Create(ctx, namespace(map[string]string{render.PodSecurityStandardValidationAnnotation: "true"}))).NotTo(HaveOccurred())
+			pc.reject = apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "test-deployment-pod-security-check",
+				fmt.Errorf("User \"system:serviceaccount:tigera-operator:tigera-operator\" cannot patch resource \"pods\""))
+
+			fc := &fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: []client.Object{deployment("test")}}
+			err := handler.CreateOrUpdateOrDelete(ctx, fc, sm)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`unable to check the pods of Deployment test-namespace/test-deployment against the "restricted" pod security standard`))
+
+			By("checking the pods again on the next reconcile")
+			pc.reject = nil
+			fc = &fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: []client.Object{deployment("test")}}
+			Expect(handler.CreateOrUpdateOrDelete(ctx, fc, sm)).NotTo(HaveOccurred())
+			Expect(pc.checkedPods).To(HaveLen(2))
+		})
+
+		It("removes pod security labels and annotations that are no longer rendered", func() {
+			current := namespace(map[string]string{
+				render.PodSecurityStandardValidationAnnotation: "true",
+				render.PodSecurityLabelsAnnotation:             "pod-security.kubernetes.io/audit,pod-security.kubernetes.io/enforce,pod-security.kubernetes.io/enforce-version",
+			})
+			current.Labels["pod-security.kubernetes.io/audit"] = "restricted"
+			current.Labels["pod-security.kubernetes.io/warn"] = "restricted"
+			current.Labels["extra"] = "extra-value"
+			Expect(c.Create(ctx, current)).NotTo(HaveOccurred())
+
+			fc := &fakeComponent{
+				supportedOSType: rmeta.OSTypeLinux,
+				objs: []client.Object{&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-namespace",
+						Labels: map[string]string{
+							"pod-security.kubernetes.io/enforce":         "privileged",
+							"pod-security.kubernetes.io/enforce-version": "latest",
+						},
+						Annotations: map[string]string{
+							render.PodSecurityLabelsAnnotation: "pod-security.kubernetes.io/enforce,pod-security.kubernetes.io/enforce-version",
+						},
+					},
+				}},
+			}
+			Expect(handler.CreateOrUpdateOrDelete(ctx, fc, sm)).NotTo(HaveOccurred())
+
+			ns := &corev1.Namespace{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "test-namespace"}, ns)).NotTo(HaveOccurred())
+			Expect(ns.Labels).To(Equal(map[string]string{
+				"extra":                              "extra-value",
+				"pod-security.kubernetes.io/enforce": "privileged",
+				"pod-security.kubernetes.io/enforce-version": "latest",
+				// Added by the user rather than the operator.
+				"pod-security.kubernetes.io/warn": "restricted",
+			}))
+			Expect(ns.Annotations).NotTo(HaveKey(render.PodSecurityStandardValidationAnnotation))
+		})
+	})
 })
 
+// fakeVerifier records the images it verifies and rejects those marked as unsigned. Images referenced by tag are
+// pinned to a digest named after the tag.
+type fakeVerifier struct {
+	unsigned  map[string]bool
+	verified  []string
+	publicKey string
+	keychain  imageregistry.Keychain
+}
+
+func (v *fakeVerifier) Verify(_ context.Context, image string, publicKey []byte, keychain imageregistry.Keychain) (string, error) {
+	v.verified = append(v.verified, image)
+	v.publicKey = string(publicKey)
+	v.keychain = keychain
+	if v.unsigned[image] {
+		return "", imagesignature.ErrNoSignature
+	}
+	if i := strings.LastIndex(image, ":"); i >= 0 && !strings.Contains(image, "@") {
+		return image[:i] + "@sha256:" + image[i+1:], nil
+	}
+	return image, nil
+}
+
+// podSecurityClient records the dry-run pods used to check pod security admission and, if reject is set, rejects
+// them with that error.
+type podSecurityClient struct {
+	client.Client
+	reject      error
+	checkedPods []string
+}
+
+func (c *podSecurityClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if _, ok := obj.(*corev1.Pod); !ok {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+	c.checkedPods = append(c.checkedPods, fmt.Sprintf("%s/%s", obj.GetNamespace(), obj.GetName()))
+	return c.reject
+}
+
+// podSecurityViolationError returns the error with which pod security admission rejects a pod.
+func podSecurityViolationError(name string) error {
+	return apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, name, fmt.Errorf("violates PodSecurity \"restricted:latest\""))
+}
+
 // A fake component that only returns ready and always creates the "test-namespace" Namespace.
 type fakeComponent struct {
 	objs            []client.Object
@@ -106,6 +106,10 @@ type ElasticsearchClientCreator func(client client.Client, ctx context.Context,
 
 type ElasticClient interface {
 	SetILMPolicies(context.Context, *operatorv1.LogStorage) error
+	SetSnapshotPolicy(context.Context, *operatorv1.LogStorage) error
+	GetSnapshotPolicyStatus(context.Context) (*SnapshotPolicyStatus, error)
+	RestoreSnapshot(ctx context.Context, snapshot string, indices []string) error
+	GetSnapshotRestoreProgress(ctx context.Context, snapshot string, indices []string) (int, int, error)
 }
 
 type esClient struct {
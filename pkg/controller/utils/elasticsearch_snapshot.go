@@ -0,0 +1,263 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/render"
+)
+
+const (
+	// SnapshotRepositoryName is the name of the snapshot repository, and of the snapshot lifecycle policy that
+	// takes snapshots to it, that are configured from the LogStorage.
+	SnapshotRepositoryName = "tigera-secure-snapshots"
+
+	// DefaultSnapshotIndices are the indices that are included in the snapshots.
+	DefaultSnapshotIndices = "tigera_secure_ee_*"
+
+	// snapshotName is the date math expression for the names of the snapshots. Elasticsearch appends a unique
+	// suffix to each name.
+	snapshotName = "<tigera-secure-snapshot-{now/d}>"
+)
+
+// SnapshotPolicyStatus holds the last successful and failed snapshots of the snapshot lifecycle policy.
+type SnapshotPolicyStatus struct {
+	LastSuccess *SnapshotInvocation
+	LastFailure *SnapshotInvocation
+}
+
+// SnapshotInvocation is a snapshot taken by the snapshot lifecycle policy.
+type SnapshotInvocation struct {
+	Snapshot string
+	Time     time.Time
+	Details  string
+}
+
+type slmPolicyResponse struct {
+	LastSuccess *slmInvocation `json:"last_success"`
+	LastFailure *slmInvocation `json:"last_failure"`
+}
+
+type slmInvocation struct {
+	SnapshotName string `json:"snapshot_name"`
+	Time         int64  `json:"time"`
+	Details      string `json:"details"`
+}
+
+type indexRecovery struct {
+	Shards []struct {
+		Type   string `json:"type"`
+		Stage  string `json:"stage"`
+		Source struct {
+			Repository string `json:"repository"`
+			Snapshot   string `json:"snapshot"`
+		} `json:"source"`
+	} `json:"shards"`
+}
+
+// SetSnapshotPolicy creates or updates the snapshot repository and the snapshot lifecycle policy from the
+// ElasticsearchSnapshots in LogStorage, or removes them if it is not set. Removing the repository does not
+// remove the snapshots that are stored in it.
+func (es *esClient) SetSnapshotPolicy(ctx context.Context, ls *operatorv1.LogStorage) error {
+	snapshots := ls.Spec.ElasticsearchSnapshots
+	if snapshots == nil {
+		return es.deleteSnapshotPolicy(ctx)
+	}
+
+	repoType, settings := snapshotRepositorySettings(snapshots.Repository)
+	if _, err := es.client.SnapshotCreateRepository(SnapshotRepositoryName).Type(repoType).Settings(settings).Do(ctx); err != nil {
+		return fmt.Errorf("failed to create or update the snapshot repository: %w", err)
+	}
+
+	_, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: http.MethodPut,
+		Path:   "/_slm/policy/" + SnapshotRepositoryName,
+		Body:   buildSnapshotPolicy(snapshots),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create or update the snapshot lifecycle policy: %w", err)
+	}
+	return nil
+}
+
+func (es *esClient) deleteSnapshotPolicy(ctx context.Context) error {
+	_, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method:       http.MethodDelete,
+		Path:         "/_slm/policy/" + SnapshotRepositoryName,
+		IgnoreErrors: []int{http.StatusNotFound},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete the snapshot lifecycle policy: %w", err)
+	}
+
+	if _, err := es.client.SnapshotDeleteRepository(SnapshotRepositoryName).Do(ctx); err != nil && !elastic.IsNotFound(err) {
+		return fmt.Errorf("failed to delete the snapshot repository: %w", err)
+	}
+	return nil
+}
+
+// GetSnapshotPolicyStatus returns the last successful and failed snapshots of the snapshot lifecycle policy, or
+// nil if the policy does not exist.
+func (es *esClient) GetSnapshotPolicyStatus(ctx context.Context) (*SnapshotPolicyStatus, error) {
+	res, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: http.MethodGet,
+		Path:   "/_slm/policy/" + SnapshotRepositoryName,
+	})
+	if err != nil {
+		if elastic.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get the snapshot lifecycle policy: %w", err)
+	}
+
+	policies := map[string]slmPolicyResponse{}
+	if err := json.Unmarshal(res.Body, &policies); err != nil {
+		return nil, fmt.Errorf("failed to parse the snapshot lifecycle policy: %w", err)
+	}
+	policy, ok := policies[SnapshotRepositoryName]
+	if !ok {
+		return nil, nil
+	}
+	return &SnapshotPolicyStatus{
+		LastSuccess: policy.LastSuccess.toSnapshotInvocation(),
+		LastFailure: policy.LastFailure.toSnapshotInvocation(),
+	}, nil
+}
+
+func (i *slmInvocation) toSnapshotInvocation() *SnapshotInvocation {
+	if i == nil {
+		return nil
+	}
+	return &SnapshotInvocation{
+		Snapshot: i.SnapshotName,
+		Time:     time.Unix(0, i.Time*int64(time.Millisecond)),
+		Details:  i.Details,
+	}
+}
+
+// RestoredIndexPrefix is the prefix of the names of the indices restored from a snapshot. The indices are restored
+// under new names, since an index cannot be restored over an open index and the indices of the snapshot are
+// usually still open.
+func RestoredIndexPrefix(snapshot string) string {
+	return fmt.Sprintf("restored-%s-", snapshot)
+}
+
+// RestoreSnapshot starts the restore of the indices of a snapshot in the snapshot repository, renamed with
+// RestoredIndexPrefix. It does not wait for the restore to complete, GetSnapshotRestoreProgress reports its progress.
+// The global cluster state and the aliases of the indices are not restored, so that the aliases of the current
+// indices are kept.
+func (es *esClient) RestoreSnapshot(ctx context.Context, snapshot string, indices []string) error {
+	_, err := es.client.SnapshotRestore(SnapshotRepositoryName, snapshot).
+		Indices(indices...).
+		RenamePattern("(.+)").
+		RenameReplacement(RestoredIndexPrefix(snapshot) + "$1").
+		IncludeGlobalState(false).
+		IncludeAliases(false).
+		WaitForCompletion(false).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot %s: %w", snapshot, err)
+	}
+	return nil
+}
+
+// GetSnapshotRestoreProgress returns the number of shards of the indices that are recovered from the snapshot, and
+// the total number of shards that are recovered from it.
+func (es *esClient) GetSnapshotRestoreProgress(ctx context.Context, snapshot string, indices []string) (int, int, error) {
+	restored := make([]string, 0, len(indices))
+	for _, index := range indices {
+		restored = append(restored, RestoredIndexPrefix(snapshot)+index)
+	}
+	params := url.Values{}
+	params.Set("ignore_unavailable", "true")
+	params.Set("allow_no_indices", "true")
+	res, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: http.MethodGet,
+		Path:   "/" + strings.Join(restored, ",") + "/_recovery",
+		Params: params,
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get the recovery of the restored indices: %w", err)
+	}
+
+	recoveries := map[string]indexRecovery{}
+	if err := json.Unmarshal(res.Body, &recoveries); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse the recovery of the restored indices: %w", err)
+	}
+
+	var done, total int
+	for _, recovery := range recoveries {
+		for _, shard := range recovery.Shards {
+			if shard.Type != "SNAPSHOT" || shard.Source.Repository != SnapshotRepositoryName || shard.Source.Snapshot != snapshot {
+				continue
+			}
+			total++
+			if shard.Stage == "DONE" {
+				done++
+			}
+		}
+	}
+	return done, total, nil
+}
+
+func snapshotRepositorySettings(repo operatorv1.SnapshotRepository) (string, map[string]interface{}) {
+	if repo.S3 != nil {
+		settings := map[string]interface{}{
+			"bucket": repo.S3.BucketName,
+			"client": "default",
+		}
+		if repo.S3.BasePath != "" {
+			settings["base_path"] = repo.S3.BasePath
+		}
+		return "s3", settings
+	}
+	return "fs", map[string]interface{}{"location": render.ElasticsearchSnapshotsPath}
+}
+
+func buildSnapshotPolicy(snapshots *operatorv1.ElasticsearchSnapshots) map[string]interface{} {
+	retention := map[string]interface{}{}
+	if r := snapshots.Retention; r != nil {
+		if r.ExpireAfterDays != nil {
+			retention["expire_after"] = fmt.Sprintf("%dd", *r.ExpireAfterDays)
+		}
+		if r.MinCount != nil {
+			retention["min_count"] = *r.MinCount
+		}
+		if r.MaxCount != nil {
+			retention["max_count"] = *r.MaxCount
+		}
+	}
+
+	return map[string]interface{}{
+		"schedule":   snapshots.Schedule,
+		"name":       snapshotName,
+		"repository": SnapshotRepositoryName,
+		"config": map[string]interface{}{
+			"indices":              []string{DefaultSnapshotIndices},
+			"include_global_state": false,
+		},
+		"retention": retention,
+	}
+}
@@ -23,16 +23,20 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	elastic "github.com/olivere/elastic/v7"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/resource"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
 )
 
 const (
 	baseURI   = "http://127.0.0.1:9200"
 	indexName = "tigera_secure_ee_test_index"
+	snapshot  = "tigera-secure-snapshot-2022.04.15-abc"
 )
 
 var newPolicies bool
@@ -91,6 +95,73 @@ var _ = Describe("Elasticsearch tests", func() {
 			Expect(err).To(BeNil())
 		})
 	})
+
+	Context("Snapshots", func() {
+		var (
+			eClient *esClient
+			ctx     context.Context
+		)
+		BeforeEach(func() {
+			client := &http.Client{
+				Transport: http.RoundTripper(&testRoundTripper{}),
+			}
+			eClient = mockElasticClient(client, baseURI)
+			ctx = context.Background()
+		})
+
+		It("creates the snapshot repository and policy", func() {
+			var expireAfterDays, minCount, maxCount int32 = 30, 5, 50
+			ls := &operatorv1.LogStorage{Spec: operatorv1.LogStorageSpec{
+				ElasticsearchSnapshots: &operatorv1.ElasticsearchSnapshots{
+					Repository: operatorv1.SnapshotRepository{
+						S3: &operatorv1.S3SnapshotRepository{BucketName: "backups", BasePath: "elasticsearch"},
+					},
+					Schedule: "0 30 1 * * ?",
+					Retention: &operatorv1.SnapshotRetention{
+						ExpireAfterDays: &expireAfterDays,
+						MinCount:        &minCount,
+						MaxCount:        &maxCount,
+					},
+				},
+			}}
+			Expect(eClient.SetSnapshotPolicy(ctx, ls)).NotTo(HaveOccurred())
+		})
+
+		It("uses the mounted volume for a PersistentVolumeClaim repository", func() {
+			repoType, settings := snapshotRepositorySettings(operatorv1.SnapshotRepository{
+				PersistentVolumeClaim: &operatorv1.PVCSnapshotRepository{ClaimName: "backups"},
+			})
+			Expect(repoType).To(Equal("fs"))
+			Expect(settings).To(Equal(map[string]interface{}{"location": "/usr/share/elasticsearch/snapshots"}))
+		})
+
+		It("removes the snapshot policy and repository when snapshots are not configured", func() {
+			Expect(eClient.SetSnapshotPolicy(ctx, &operatorv1.LogStorage{})).NotTo(HaveOccurred())
+		})
+
+		It("returns the last snapshots of the policy", func() {
+			status, err := eClient.GetSnapshotPolicyStatus(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(&SnapshotPolicyStatus{
+				LastSuccess: &SnapshotInvocation{Snapshot: snapshot, Time: time.Unix(1650000000, 0)},
+				LastFailure: &SnapshotInvocation{
+					Snapshot: "tigera-secure-snapshot-2022.04.14-def",
+					Time:     time.Unix(1649900000, 0),
+					Details:  "repository is read-only",
+				},
+			}))
+		})
+
+		It("restores a snapshot and reports its progress", func() {
+			indices := []string{"tigera_secure_ee_flows*", "tigera_secure_ee_dns*"}
+			Expect(eClient.RestoreSnapshot(ctx, snapshot, indices)).NotTo(HaveOccurred())
+
+			done, total, err := eClient.GetSnapshotRestoreProgress(ctx, snapshot, indices)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(done).To(Equal(2))
+			Expect(total).To(Equal(3))
+		})
+	})
 })
 
 type testRoundTripper struct {
@@ -125,10 +196,60 @@ func (t *testRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 				Request:    req,
 				Body:       mustOpen("test_files/02_get_policy.json"),
 			}, nil
+		case baseURI + "/_slm/policy/" + SnapshotRepositoryName:
+			return &http.Response{
+				StatusCode: 200,
+				Request:    req,
+				Body:       mustOpen("test_files/03_get_snapshot_policy.json"),
+			}, nil
+		case baseURI + "/restored-" + snapshot + "-tigera_secure_ee_flows*,restored-" + snapshot + "-tigera_secure_ee_dns*/_recovery?allow_no_indices=true&ignore_unavailable=true":
+			return &http.Response{
+				StatusCode: 200,
+				Request:    req,
+				Body:       mustOpen("test_files/03_get_recovery.json"),
+			}, nil
+		}
+	case "DELETE":
+		switch req.URL.String() {
+		case baseURI + "/_slm/policy/" + SnapshotRepositoryName:
+			return &http.Response{
+				StatusCode: 404,
+				Request:    req,
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+			}, nil
+		case baseURI + "/_snapshot/" + SnapshotRepositoryName:
+			return &http.Response{
+				StatusCode: 200,
+				Request:    req,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"acknowledged":true}`)),
+			}, nil
 		}
 	case "POST":
+		switch req.URL.String() {
+		case baseURI + "/_snapshot/" + SnapshotRepositoryName + "/" + snapshot + "/_restore?wait_for_completion=false":
+			expectBody(req, "test_files/03_restore_snapshot.json")
+			return &http.Response{
+				StatusCode: 200,
+				Request:    req,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"accepted":true}`)),
+			}, nil
+		}
 	case "PUT":
 		switch req.URL.String() {
+		case baseURI + "/_snapshot/" + SnapshotRepositoryName:
+			expectBody(req, "test_files/03_put_snapshot_repository.json")
+			return &http.Response{
+				StatusCode: 200,
+				Request:    req,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"acknowledged":true}`)),
+			}, nil
+		case baseURI + "/_slm/policy/" + SnapshotRepositoryName:
+			expectBody(req, "test_files/03_put_snapshot_policy.json")
+			return &http.Response{
+				StatusCode: 200,
+				Request:    req,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"acknowledged":true}`)),
+			}, nil
 		case baseURI + "/_ilm/policy/" + indexName + "_policy":
 			if newPolicies {
 				actualBody, err := ioutil.ReadAll(req.Body)
@@ -181,6 +302,14 @@ func (t *testRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	}, nil
 }
 
+func expectBody(req *http.Request, name string) {
+	actualBody, err := ioutil.ReadAll(req.Body)
+	Expect(err).To(BeNil())
+	expectedBody, err := ioutil.ReadFile(name)
+	Expect(err).To(BeNil())
+	Expect(actualBody).To(MatchJSON(expectedBody))
+}
+
 func mustOpen(name string) io.ReadCloser {
 	f, err := os.Open(name)
 	if err != nil {
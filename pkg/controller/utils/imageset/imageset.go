@@ -49,7 +49,8 @@ func AddImageSetWatch(c controller.Controller) error {
 	return c.Watch(&source.Kind{Type: &operator.ImageSet{}}, &handler.EnqueueRequestForObject{})
 }
 
-func getSetName(v operator.ProductVariant) string {
+// GetSetName returns the name of the ImageSet that is used for the specified variant.
+func GetSetName(v operator.ProductVariant) string {
 	if v == operator.TigeraSecureEnterprise {
 		return fmt.Sprintf("enterprise-%s", components.EnterpriseRelease)
 	}
@@ -73,7 +74,7 @@ func GetImageSet(ctx context.Context, cli client.Client, v operator.ProductVaria
 		return nil, nil
 	}
 
-	setName := getSetName(v)
+	setName := GetSetName(v)
 
 	for _, is := range isl.Items {
 		if is.Name == setName {
@@ -131,7 +132,14 @@ func ValidateImageSet(is *operator.ImageSet) error {
 		}
 	}
 
-	if len(unknownImages) == 0 && len(invalidDigests) == 0 {
+	invalidRegistries := []string{}
+	for _, img := range is.Spec.Images {
+		if img.Registry != "" && !strings.HasSuffix(img.Registry, "/") {
+			invalidRegistries = append(invalidRegistries, fmt.Sprintf("%s (%s)", img.Image, img.Registry))
+		}
+	}
+
+	if len(unknownImages) == 0 && len(invalidDigests) == 0 && len(invalidRegistries) == 0 {
 		return nil
 	}
 
@@ -144,6 +152,10 @@ func ValidateImageSet(is *operator.ImageSet) error {
 	if len(invalidDigests) != 0 {
 		errMsgs = append(errMsgs, fmt.Sprintf("bad digest images: %s", strings.Join(invalidDigests, ", ")))
 	}
+
+	if len(invalidRegistries) != 0 {
+		errMsgs = append(errMsgs, fmt.Sprintf("registries must end with a slash: %s", strings.Join(invalidRegistries, ", ")))
+	}
 	return fmt.Errorf("ImageSet %s: %s", is.Name, strings.Join(errMsgs, "; "))
 }
 
@@ -107,6 +107,22 @@ var _ = Describe("imageset tests", func() {
 			err = ApplyImageSet(context.Background(), c, v)
 			Expect(err).ToNot(BeNil())
 			Expect(err.Error()).To(ContainSubstring("bad digest images"))
+			c = fake.NewClientBuilder().WithScheme(kscheme.Scheme).WithObjects(
+				&operator.ImageSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: nm,
+					},
+					Spec: operator.ImageSetSpec{
+						Images: []operator.Image{
+							{Image: "calico/cni", Digest: "sha256:xxxxxxxxx", Registry: "mirror.example.com/"},
+							{Image: "calico/typha", Digest: "sha256:xxxxxxxxx", Registry: "mirror.example.com"},
+						},
+					},
+				},
+			).Build()
+			err = ApplyImageSet(context.Background(), c, v)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("registries must end with a slash: calico/typha (mirror.example.com)"))
 		},
 			Entry("Calico variant", operator.Calico),
 			Entry("Enterprise variant", operator.TigeraSecureEnterprise),
@@ -141,6 +141,52 @@ func OverrideInstallationSpec(cfg, override operatorv1.InstallationSpec) operato
 		inst.NonPrivileged = override.NonPrivileged
 	}
 
+	switch compareFields(inst.ComponentNetworkPolicy, override.ComponentNetworkPolicy) {
+	case BOnlySet, Different:
+		inst.ComponentNetworkPolicy = override.ComponentNetworkPolicy
+	}
+
+	switch compareFields(inst.PodSecurityStandards, override.PodSecurityStandards) {
+	case BOnlySet, Different:
+		inst.PodSecurityStandards = make([]operatorv1.NamespacePodSecurityStandard, len(override.PodSecurityStandards))
+		for i := range override.PodSecurityStandards {
+			override.PodSecurityStandards[i].DeepCopyInto(&inst.PodSecurityStandards[i])
+		}
+	}
+
+	switch compareFields(inst.ImageSignatureVerification, override.ImageSignatureVerification) {
+	case BOnlySet, Different:
+		inst.ImageSignatureVerification = override.ImageSignatureVerification.DeepCopy()
+	}
+
+	switch compareFields(inst.Logging, override.Logging) {
+	case BOnlySet, Different:
+		inst.Logging = override.Logging.DeepCopy()
+	}
+
+	switch compareFields(inst.ControlPlaneAvailability, override.ControlPlaneAvailability) {
+	case BOnlySet, Different:
+		inst.ControlPlaneAvailability = make([]operatorv1.ControlPlaneAvailability, len(override.ControlPlaneAvailability))
+		for i := range override.ControlPlaneAvailability {
+			override.ControlPlaneAvailability[i].DeepCopyInto(&inst.ControlPlaneAvailability[i])
+		}
+	}
+
+	switch compareFields(inst.WindowsNodeUpgrade, override.WindowsNodeUpgrade) {
+	case BOnlySet, Different:
+		inst.WindowsNodeUpgrade = override.WindowsNodeUpgrade.DeepCopy()
+	}
+
+	switch compareFields(inst.AWSSecurityGroups, override.AWSSecurityGroups) {
+	case BOnlySet, Different:
+		inst.AWSSecurityGroups = override.AWSSecurityGroups.DeepCopy()
+	}
+
+	switch compareFields(inst.PacketCapture, override.PacketCapture) {
+	case BOnlySet, Different:
+		inst.PacketCapture = override.PacketCapture.DeepCopy()
+	}
+
 	return inst
 }
 
@@ -503,6 +503,63 @@ var _ = Describe("Installation merge tests", func() {
 		Entry("Both set not matching", intPtr(1460), intPtr(8981), intPtr(8981)),
 	)
 
+	_componentNetworkPolicyEnabled := opv1.ComponentNetworkPolicyEnabled
+	_componentNetworkPolicyDisabled := opv1.ComponentNetworkPolicyDisabled
+	DescribeTable("merge ComponentNetworkPolicy", func(main, second, expect *opv1.ComponentNetworkPolicyType) {
+		m := opv1.InstallationSpec{ComponentNetworkPolicy: main}
+		s := opv1.InstallationSpec{ComponentNetworkPolicy: second}
+		inst := OverrideInstallationSpec(m, s)
+		Expect(inst.ComponentNetworkPolicy).To(Equal(expect))
+	},
+		Entry("Both unset", nil, nil, nil),
+		Entry("Main only set", &_componentNetworkPolicyEnabled, nil, &_componentNetworkPolicyEnabled),
+		Entry("Second only set", nil, &_componentNetworkPolicyDisabled, &_componentNetworkPolicyDisabled),
+		Entry("Both set equal", &_componentNetworkPolicyEnabled, &_componentNetworkPolicyEnabled, &_componentNetworkPolicyEnabled),
+		Entry("Both set not matching", &_componentNetworkPolicyEnabled, &_componentNetworkPolicyDisabled, &_componentNetworkPolicyDisabled),
+	)
+
+	_calicoSystemRestricted := opv1.NamespacePodSecurityStandard{
+		Namespace: "calico-system",
+		Enforce:   &opv1.PodSecurityStandardMode{Level: opv1.PodSecurityStandardRestricted},
+	}
+	_calicoSystemAudit := opv1.NamespacePodSecurityStandard{
+		Namespace: "calico-system",
+		Audit:     &opv1.PodSecurityStandardMode{Level: opv1.PodSecurityStandardBaseline, Version: "v1.25"},
+	}
+	DescribeTable("merge PodSecurityStandards", func(main, second, expect []opv1.NamespacePodSecurityStandard) {
+		m := opv1.InstallationSpec{PodSecurityStandards: main}
+		s := opv1.InstallationSpec{PodSecurityStandards: second}
+		inst := OverrideInstallationSpec(m, s)
+		Expect(inst.PodSecurityStandards).To(Equal(expect))
+	},
+		Entry("Both unset", nil, nil, nil),
+		Entry("Main only set",
+			[]opv1.NamespacePodSecurityStandard{_calicoSystemRestricted}, nil,
+			[]opv1.NamespacePodSecurityStandard{_calicoSystemRestricted}),
+		Entry("Second only set",
+			nil, []opv1.NamespacePodSecurityStandard{_calicoSystemAudit},
+			[]opv1.NamespacePodSecurityStandard{_calicoSystemAudit}),
+		Entry("Both set not matching",
+			[]opv1.NamespacePodSecurityStandard{_calicoSystemRestricted},
+			[]opv1.NamespacePodSecurityStandard{_calicoSystemAudit},
+			[]opv1.NamespacePodSecurityStandard{_calicoSystemAudit}),
+	)
+
+	_keyA := &opv1.ImageSignatureVerification{PublicKeySecretName: "key-a"}
+	_keyB := &opv1.ImageSignatureVerification{PublicKeySecretName: "key-b"}
+	DescribeTable("merge ImageSignatureVerification", func(main, second, expect *opv1.ImageSignatureVerification) {
+		m := opv1.InstallationSpec{ImageSignatureVerification: main}
+		s := opv1.InstallationSpec{ImageSignatureVerification: second}
+		inst := OverrideInstallationSpec(m, s)
+		Expect(inst.ImageSignatureVerification).To(Equal(expect))
+	},
+		Entry("Both unset", nil, nil, nil),
+		Entry("Main only set", _keyA, nil, _keyA),
+		Entry("Second only set", nil, _keyB, _keyB),
+		Entry("Both set equal", _keyA, _keyA, _keyA),
+		Entry("Both set not matching", _keyA, _keyB, _keyB),
+	)
+
 	DescribeTable("merge FlexVolumePath", func(main, second, expect string) {
 		m := opv1.InstallationSpec{}
 		s := opv1.InstallationSpec{}
@@ -603,6 +660,151 @@ var _ = Describe("Installation merge tests", func() {
 			[]opv1.ComponentResource{_typhaComp}),
 	)
 
+	_info := opv1.LogLevelInfo
+	_debug := opv1.LogLevelDebug
+	_logInfo := opv1.Logging{Level: &_info}
+	_logDebug := opv1.Logging{Level: &_debug}
+	DescribeTable("merge Logging", func(main, second, expect *opv1.Logging) {
+		m := opv1.InstallationSpec{}
+		s := opv1.InstallationSpec{}
+		if main != nil {
+			m.Logging = main
+		}
+		if second != nil {
+			s.Logging = second
+		}
+		inst := OverrideInstallationSpec(m, s)
+		if expect == nil {
+			Expect(inst.Logging).To(BeNil())
+		} else {
+			Expect(*inst.Logging).To(Equal(*expect))
+		}
+	},
+		Entry("Both unset", nil, nil, nil),
+		Entry("Main only set", &_logInfo, nil, &_logInfo),
+		Entry("Second only set", nil, &_logDebug, &_logDebug),
+		Entry("Both set equal", &_logInfo, &_logInfo, &_logInfo),
+		Entry("Both set not matching", &_logInfo, &_logDebug, &_logDebug),
+	)
+
+	_enabled := opv1.ControlPlaneAvailabilityEnabled
+	_disabled := opv1.ControlPlaneAvailabilityDisabled
+	_managerAvail := opv1.ControlPlaneAvailability{ComponentName: opv1.ControlPlaneComponentManager, Mode: &_enabled}
+	_dexAvail := opv1.ControlPlaneAvailability{ComponentName: opv1.ControlPlaneComponentDex, Mode: &_disabled}
+	DescribeTable("merge ControlPlaneAvailability", func(main, second, expect []opv1.ControlPlaneAvailability) {
+		m := opv1.InstallationSpec{}
+		s := opv1.InstallationSpec{}
+		if main != nil {
+			m.ControlPlaneAvailability = main
+		}
+		if second != nil {
+			s.ControlPlaneAvailability = second
+		}
+		inst := OverrideInstallationSpec(m, s)
+		if expect == nil {
+			Expect(inst.ControlPlaneAvailability).To(HaveLen(0))
+		} else {
+			Expect(inst.ControlPlaneAvailability).To(ConsistOf(expect))
+		}
+	},
+		Entry("Both unset", nil, nil, nil),
+		Entry("Main only set",
+			[]opv1.ControlPlaneAvailability{_managerAvail},
+			nil,
+			[]opv1.ControlPlaneAvailability{_managerAvail}),
+		Entry("Second only set",
+			nil,
+			[]opv1.ControlPlaneAvailability{_dexAvail},
+			[]opv1.ControlPlaneAvailability{_dexAvail}),
+		Entry("Both set equal",
+			[]opv1.ControlPlaneAvailability{_managerAvail},
+			[]opv1.ControlPlaneAvailability{_managerAvail},
+			[]opv1.ControlPlaneAvailability{_managerAvail}),
+		Entry("Both set not matching",
+			[]opv1.ControlPlaneAvailability{_managerAvail},
+			[]opv1.ControlPlaneAvailability{_dexAvail},
+			[]opv1.ControlPlaneAvailability{_dexAvail}),
+	)
+
+	_paused := &opv1.WindowsNodeUpgrade{Paused: true}
+	_canary := &opv1.WindowsNodeUpgrade{CanaryNodeSelector: map[string]string{"canary": "true"}}
+	DescribeTable("merge WindowsNodeUpgrade", func(main, second, expect *opv1.WindowsNodeUpgrade) {
+		m := opv1.InstallationSpec{}
+		s := opv1.InstallationSpec{}
+		if main != nil {
+			m.WindowsNodeUpgrade = main
+		}
+		if second != nil {
+			s.WindowsNodeUpgrade = second
+		}
+		inst := OverrideInstallationSpec(m, s)
+		if expect == nil {
+			Expect(inst.WindowsNodeUpgrade).To(BeNil())
+		} else {
+			Expect(*inst.WindowsNodeUpgrade).To(Equal(*expect))
+		}
+	},
+		Entry("Both unset", nil, nil, nil),
+		Entry("Main only set", _paused, nil, _paused),
+		Entry("Second only set", nil, _canary, _canary),
+		Entry("Both set equal", _paused, _paused, _paused),
+		Entry("Both set not matching", _paused, _canary, _canary),
+	)
+
+	_sgIDs := &opv1.AWSSecurityGroups{SecurityGroupIDs: []string{"sg-1234"}}
+	_sgSelectors := &opv1.AWSSecurityGroups{
+		SecurityGroupSelectors: []opv1.AWSSecurityGroupSelector{{Tags: map[string]string{"kubernetes.io/cluster/test": "owned"}}},
+	}
+	DescribeTable("merge AWSSecurityGroups", func(main, second, expect *opv1.AWSSecurityGroups) {
+		m := opv1.InstallationSpec{}
+		s := opv1.InstallationSpec{}
+		if main != nil {
+			m.AWSSecurityGroups = main
+		}
+		if second != nil {
+			s.AWSSecurityGroups = second
+		}
+		inst := OverrideInstallationSpec(m, s)
+		if expect == nil {
+			Expect(inst.AWSSecurityGroups).To(BeNil())
+		} else {
+			Expect(*inst.AWSSecurityGroups).To(Equal(*expect))
+		}
+	},
+		Entry("Both unset", nil, nil, nil),
+		Entry("Main only set", _sgIDs, nil, _sgIDs),
+		Entry("Second only set", nil, _sgSelectors, _sgSelectors),
+		Entry("Both set equal", _sgIDs, _sgIDs, _sgIDs),
+		Entry("Both set not matching", _sgIDs, _sgSelectors, _sgSelectors),
+	)
+
+	_pcapFileSize := int64(5000000)
+	_pcapFiles := int32(4)
+	_pcapSize := &opv1.PacketCaptureSpec{MaxFileSizeBytes: &_pcapFileSize}
+	_pcapFileCount := &opv1.PacketCaptureSpec{MaxFilesPerCapture: &_pcapFiles}
+	DescribeTable("merge PacketCapture", func(main, second, expect *opv1.PacketCaptureSpec) {
+		m := opv1.InstallationSpec{}
+		s := opv1.InstallationSpec{}
+		if main != nil {
+			m.PacketCapture = main
+		}
+		if second != nil {
+			s.PacketCapture = second
+		}
+		inst := OverrideInstallationSpec(m, s)
+		if expect == nil {
+			Expect(inst.PacketCapture).To(BeNil())
+		} else {
+			Expect(*inst.PacketCapture).To(Equal(*expect))
+		}
+	},
+		Entry("Both unset", nil, nil, nil),
+		Entry("Main only set", _pcapSize, nil, _pcapSize),
+		Entry("Second only set", nil, _pcapFileCount, _pcapFileCount),
+		Entry("Both set equal", _pcapSize, _pcapSize, _pcapSize),
+		Entry("Both set not matching", _pcapSize, _pcapFileCount, _pcapFileCount),
+	)
+
 	Context("all fields handled", func() {
 		var defaulted opv1.InstallationSpec
 		BeforeEach(func() {
@@ -41,6 +41,7 @@ import (
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/controller/k8sapi"
 	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 )
 
 const (
@@ -57,15 +58,13 @@ var OverlayInstanceKey = client.ObjectKey{Name: "overlay"}
 // ContextLoggerForResource provides a logger instance with context set for the provided object.
 func ContextLoggerForResource(log logr.Logger, obj client.Object) logr.Logger {
 	gvk := obj.GetObjectKind().GroupVersionKind()
-	name := obj.(metav1.ObjectMetaAccessor).GetObjectMeta().GetName()
-	namespace := obj.(metav1.ObjectMetaAccessor).GetObjectMeta().GetNamespace()
-	return log.WithValues("Name", name, "Namespace", namespace, "Kind", gvk.Kind)
+	return log.WithValues("Name", obj.GetName(), "Namespace", obj.GetNamespace(), "Kind", gvk.Kind)
 }
 
 // IgnoreObject returns true if the object has been marked as ignored by the user,
 // and returns false otherwise.
 func IgnoreObject(obj runtime.Object) bool {
-	a := obj.(metav1.ObjectMetaAccessor).GetObjectMeta().GetAnnotations()
+	a := obj.(metav1.Object).GetAnnotations()
 	if val, ok := a[unsupportedIgnoreAnnotation]; ok && val == "true" {
 		return true
 	}
@@ -123,6 +122,40 @@ func WaitToAddLicenseKeyWatch(controller controller.Controller, client kubernete
 	WaitToAddResourceWatch(controller, client, log, flag, &v3.LicenseKey{TypeMeta: metav1.TypeMeta{Kind: v3.KindLicenseKey}})
 }
 
+// WaitToAddTierWatch waits for the Tier API to be available, then adds a watch on tiers so that the controller
+// reconciles once the allow-tigera tier is created.
+func WaitToAddTierWatch(controller controller.Controller, client kubernetes.Interface, log logr.Logger, flag *ReadyFlag) {
+	WaitToAddResourceWatch(controller, client, log, flag, &v3.Tier{TypeMeta: metav1.TypeMeta{Kind: v3.KindTier}})
+}
+
+// GetNetworkPolicyState determines what a component should do with its policies in the allow-tigera tier. The
+// override from the component's CR takes precedence over the setting on the Installation, and policies are enabled
+// when neither is set. Policies are only rendered for Enterprise and only once the Tier API is available; they are
+// only created once the allow-tigera tier exists.
+func GetNetworkPolicyState(ctx context.Context, cli client.Client, tierWatchReady *ReadyFlag, installation *operatorv1.InstallationSpec, override *operatorv1.ComponentNetworkPolicyType) (networkpolicy.State, error) {
+	if installation == nil || installation.Variant != operatorv1.TigeraSecureEnterprise || tierWatchReady == nil || !tierWatchReady.IsReady() {
+		return networkpolicy.StateUnavailable, nil
+	}
+
+	mode := operatorv1.ComponentNetworkPolicyEnabled
+	if override != nil {
+		mode = *override
+	} else if installation.ComponentNetworkPolicy != nil {
+		mode = *installation.ComponentNetworkPolicy
+	}
+	if mode == operatorv1.ComponentNetworkPolicyDisabled {
+		return networkpolicy.StateDisabled, nil
+	}
+
+	if err := cli.Get(ctx, client.ObjectKey{Name: networkpolicy.TigeraComponentTierName}, &v3.Tier{}); err != nil {
+		if kerrors.IsNotFound(err) {
+			return networkpolicy.StateUnavailable, nil
+		}
+		return networkpolicy.StateUnavailable, err
+	}
+	return networkpolicy.StateEnabled, nil
+}
+
 // AddNamespacedWatch creates a watch on the given object. If a name and namespace are provided, then it will
 // use predicates to only return matching objects. If they are not, then all events of the provided kind
 // will be generated.
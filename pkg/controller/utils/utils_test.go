@@ -30,6 +30,7 @@ import (
 	opv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
 	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 
 	apps "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
@@ -201,3 +202,64 @@ func (m *fakeDiscovery) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav
 	args := m.Called()
 	return nil, args.Get(1).([]*metav1.APIResourceList), nil
 }
+
+var _ = Describe("Network policy state tests", func() {
+	var (
+		c              client.Client
+		ctx            context.Context
+		tierWatchReady *ReadyFlag
+		installation   *opv1.InstallationSpec
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		ctx = context.Background()
+		tierWatchReady = &ReadyFlag{}
+		tierWatchReady.MarkAsReady()
+		installation = &opv1.InstallationSpec{Variant: opv1.TigeraSecureEnterprise}
+		Expect(c.Create(ctx, networkpolicy.AllowTigeraTier())).NotTo(HaveOccurred())
+	})
+
+	It("enables policies by default", func() {
+		state, err := GetNetworkPolicyState(ctx, c, tierWatchReady, installation, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state).To(Equal(networkpolicy.StateEnabled))
+	})
+
+	It("is unavailable for Calico", func() {
+		installation.Variant = opv1.Calico
+		state, err := GetNetworkPolicyState(ctx, c, tierWatchReady, installation, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state).To(Equal(networkpolicy.StateUnavailable))
+	})
+
+	It("is unavailable until the tier API is watched", func() {
+		state, err := GetNetworkPolicyState(ctx, c, &ReadyFlag{}, installation, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state).To(Equal(networkpolicy.StateUnavailable))
+	})
+
+	It("is unavailable until the tier exists", func() {
+		Expect(c.Delete(ctx, networkpolicy.AllowTigeraTier())).NotTo(HaveOccurred())
+		state, err := GetNetworkPolicyState(ctx, c, tierWatchReady, installation, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state).To(Equal(networkpolicy.StateUnavailable))
+	})
+
+	DescribeTable("prefers the override from the component over the Installation", func(install, override *opv1.ComponentNetworkPolicyType, expected networkpolicy.State) {
+		installation.ComponentNetworkPolicy = install
+		state, err := GetNetworkPolicyState(ctx, c, tierWatchReady, installation, override)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state).To(Equal(expected))
+	},
+		Entry("disabled on the Installation", componentNetworkPolicyPtr(opv1.ComponentNetworkPolicyDisabled), nil, networkpolicy.StateDisabled),
+		Entry("disabled on the component", nil, componentNetworkPolicyPtr(opv1.ComponentNetworkPolicyDisabled), networkpolicy.StateDisabled),
+		Entry("enabled on the component", componentNetworkPolicyPtr(opv1.ComponentNetworkPolicyDisabled), componentNetworkPolicyPtr(opv1.ComponentNetworkPolicyEnabled), networkpolicy.StateEnabled),
+	)
+})
+
+func componentNetworkPolicyPtr(t opv1.ComponentNetworkPolicyType) *opv1.ComponentNetworkPolicyType {
+	return &t
+}
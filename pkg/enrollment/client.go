@@ -0,0 +1,120 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enrollment
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Endpoint is the enrollment endpoint of a management cluster.
+type Endpoint struct {
+	// Address is the host and port of the endpoint.
+	Address string
+	// CA is the PEM encoded tunnel CA certificate of the management cluster, which the endpoint is verified with.
+	CA []byte
+	// ServerName overrides the name the certificate of the endpoint is verified against.
+	ServerName string
+}
+
+// Client requests tunnel certificates from the enrollment endpoint of a management cluster.
+type Client interface {
+	// Enroll requests a certificate for the CSR with a bootstrap token.
+	Enroll(ctx context.Context, ep Endpoint, token string, csr []byte) (*Response, error)
+	// Renew requests a certificate for the CSR, authenticating with the current certificate of the managed cluster.
+	Renew(ctx context.Context, ep Endpoint, current tls.Certificate, csr []byte) (*Response, error)
+	// Confirm makes the renewed certificate the active certificate of the managed cluster, authenticating with it.
+	// It must be called once the renewed certificate has been stored.
+	Confirm(ctx context.Context, ep Endpoint, renewed tls.Certificate) error
+}
+
+// HTTPClient is the Client that sends requests over HTTPS.
+type HTTPClient struct {
+	Timeout time.Duration
+}
+
+// NewClient returns a Client.
+func NewClient() Client {
+	return &HTTPClient{Timeout: 30 * time.Second}
+}
+
+func (c *HTTPClient) Enroll(ctx context.Context, ep Endpoint, token string, csr []byte) (*Response, error) {
+	return c.post(ctx, ep, EnrollPath, nil, &Request{Token: token, CSR: string(csr)})
+}
+
+func (c *HTTPClient) Renew(ctx context.Context, ep Endpoint, current tls.Certificate, csr []byte) (*Response, error) {
+	return c.post(ctx, ep, RenewPath, &current, &Request{CSR: string(csr)})
+}
+
+func (c *HTTPClient) Confirm(ctx context.Context, ep Endpoint, renewed tls.Certificate) error {
+	_, err := c.post(ctx, ep, ConfirmPath, &renewed, &Request{})
+	return err
+}
+
+func (c *HTTPClient) post(ctx context.Context, ep Endpoint, path string, cert *tls.Certificate, req *Request) (*Response, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ep.CA) {
+		return nil, fmt.Errorf("the management cluster CA is not a PEM encoded certificate")
+	}
+	serverName := ep.ServerName
+	if serverName == "" {
+		serverName = DefaultServerName
+	}
+	tlsConfig := &tls.Config{
+		RootCAs:    pool,
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if cert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+	httpClient := &http.Client{
+		Timeout:   c.Timeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://"+ep.Address+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s failed: %s: %s", strings.TrimPrefix(path, "/"), resp.Status, strings.TrimSpace(string(msg)))
+	}
+	r := &Response{}
+	if err := json.NewDecoder(resp.Body).Decode(r); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return r, nil
+}
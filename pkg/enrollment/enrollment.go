@@ -0,0 +1,127 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package enrollment implements the pull-based registration of managed clusters with a management cluster. A managed
+// cluster enrolls with a bootstrap token, which is bound to the key of the first enrollment and deleted once the
+// managed cluster confirms its certificate, and receives a tunnel certificate signed by the tunnel CA of the
+// management cluster. Before the certificate expires, the managed cluster renews it by authenticating with it. A
+// renewed certificate only replaces the active certificate once the managed cluster confirms it by authenticating
+// with it, so that a managed cluster that fails to store the renewed certificate can keep using its current one.
+package enrollment
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultPort is the port the enrollment endpoint is served on, next to the tunnel port of voltron. Like the
+	// tunnel port, it is exposed to managed clusters by the user.
+	DefaultPort = 9450
+
+	// DefaultCertificateValidity is how long issued tunnel certificates are valid for by default.
+	DefaultCertificateValidity = 90 * 24 * time.Hour
+	// DefaultRenewBefore is how long before expiry tunnel certificates are renewed by default.
+	DefaultRenewBefore = 30 * 24 * time.Hour
+
+	// DefaultServerName is the name in the certificate of the tunnel, which the enrollment endpoint serves with.
+	DefaultServerName = "voltron"
+
+	// BootstrapTokenSecretType is the type of the secrets on the management cluster that hold the bootstrap tokens
+	// managed clusters enroll with.
+	BootstrapTokenSecretType = "operator.tigera.io/managed-cluster-bootstrap-token"
+	// The keys of the bootstrap token secrets.
+	BootstrapTokenClusterNameKey = "cluster-name"
+	BootstrapTokenKey            = "token"
+	BootstrapTokenExpirationKey  = "expiration"
+
+	// EnrolledKeyAnnotation is the annotation of a bootstrap token secret with the fingerprint of the public key that
+	// the token was used to enroll, which is the only key the token is accepted for from then on.
+	EnrolledKeyAnnotation = "operator.tigera.io/enrolled-key"
+
+	// The keys of the bootstrap secret on the managed cluster, which holds the token, the tunnel CA of the
+	// management cluster and the address its enrollment endpoint is exposed at.
+	BootstrapCAKey      = "ca.crt"
+	BootstrapAddressKey = "address"
+
+	// AddressAnnotation is the annotation of the tunnel secret of a managed cluster with the address of the
+	// enrollment endpoint that issued its certificate, which renews it.
+	AddressAnnotation = "operator.tigera.io/enrollment-address"
+
+	// FingerprintAnnotation is the annotation of the ManagedCluster with the fingerprint of the certificate that
+	// voltron accepts tunnels from.
+	FingerprintAnnotation = "certs.tigera.io/active-fingerprint"
+	// PendingFingerprintAnnotation is the annotation of the ManagedCluster with the fingerprint of a renewed
+	// certificate that the managed cluster has not confirmed yet.
+	PendingFingerprintAnnotation = "operator.tigera.io/pending-fingerprint"
+
+	// UnconfirmedAnnotation is the annotation of the tunnel secret of a managed cluster whose enrolled or renewed
+	// certificate has not been confirmed with the enrollment endpoint yet.
+	UnconfirmedAnnotation = "operator.tigera.io/enrollment-unconfirmed"
+
+	EnrollPath  = "/enroll"
+	RenewPath   = "/renew"
+	ConfirmPath = "/confirm"
+	HealthPath  = "/healthz"
+
+	keySizeBits = 2048
+)
+
+// Request is the body of enroll, renew and confirm requests.
+type Request struct {
+	// Token is the bootstrap token, only used to enroll.
+	Token string `json:"token,omitempty"`
+	// CSR is the PEM encoded certificate signing request for the key of the managed cluster.
+	CSR string `json:"csr"`
+}
+
+// Response is the body of successful enroll and renew responses. It is empty for confirm requests.
+type Response struct {
+	// Certificate is the PEM encoded tunnel certificate of the managed cluster.
+	Certificate string `json:"certificate"`
+	// CA is the PEM encoded tunnel CA certificate of the management cluster.
+	CA string `json:"ca"`
+}
+
+// NewKey returns a new PEM encoded private key and a PEM encoded certificate signing request for it.
+func NewKey() ([]byte, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, keySizeBits)
+	if err != nil {
+		return nil, nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var keyPEM, csrPEM bytes.Buffer
+	if err := pem.Encode(&keyPEM, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}); err != nil {
+		return nil, nil, err
+	}
+	if err := pem.Encode(&csrPEM, &pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}); err != nil {
+		return nil, nil, err
+	}
+	return keyPEM.Bytes(), csrPEM.Bytes(), nil
+}
+
+// Fingerprint returns the fingerprint of the certificate, as recorded in the FingerprintAnnotation.
+func Fingerprint(cert *x509.Certificate) string {
+	return fmt.Sprintf("%x", sha256.Sum256(cert.Raw))
+}
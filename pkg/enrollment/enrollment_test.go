@@ -0,0 +1,298 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enrollment_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/tigera/operator/pkg/apis"
+	"github.com/tigera/operator/pkg/enrollment"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+var _ = Describe("Managed cluster enrollment", func() {
+	var (
+		ctx      context.Context
+		cli      client.Client
+		tokens   *tokenClient
+		srv      *httptest.Server
+		caPEM    []byte
+		ep       enrollment.Endpoint
+		enroller enrollment.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).To(Succeed())
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		cli = fake.NewClientBuilder().WithScheme(scheme).Build()
+		tokens = &tokenClient{Client: cli}
+
+		tunnelSecret, err := certificatemanagement.CreateSelfSignedSecret("tunnel", "tigera-operator", "tigera-voltron", []string{"voltron"})
+		Expect(err).NotTo(HaveOccurred())
+		caPEM = tunnelSecret.Data[corev1.TLSCertKey]
+		cert, err := tls.X509KeyPair(caPEM, tunnelSecret.Data[corev1.TLSPrivateKeyKey])
+		Expect(err).NotTo(HaveOccurred())
+		ca, err := x509.ParseCertificate(cert.Certificate[0])
+		Expect(err).NotTo(HaveOccurred())
+
+		s := &enrollment.Server{
+			Client:    tokens,
+			Namespace: "tigera-manager",
+			CA:        ca,
+			CAKey:     cert.PrivateKey.(crypto.Signer),
+			Validity:  time.Hour,
+		}
+		pool := x509.NewCertPool()
+		pool.AddCert(ca)
+		srv = httptest.NewUnstartedServer(s.Handler())
+		srv.TLS = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			ClientAuth:   tls.VerifyClientCertIfGiven,
+			ClientCAs:    pool,
+		}
+		srv.StartTLS()
+
+		ep = enrollment.Endpoint{Address: strings.TrimPrefix(srv.URL, "https://"), CA: caPEM}
+		enroller = enrollment.NewClient()
+	})
+
+	AfterEach(func() {
+		srv.Close()
+	})
+
+	createToken := func(name, cluster, token string, expiration time.Time) {
+		data := map[string][]byte{
+			enrollment.BootstrapTokenClusterNameKey: []byte(cluster),
+			enrollment.BootstrapTokenKey:            []byte(token),
+		}
+		if !expiration.IsZero() {
+			data[enrollment.BootstrapTokenExpirationKey] = []byte(expiration.Format(time.RFC3339))
+		}
+		Expect(cli.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "tigera-manager"},
+			Type:       enrollment.BootstrapTokenSecretType,
+			Data:       data,
+		})).To(Succeed())
+	}
+
+	enroll := func(token string) (*enrollment.Response, []byte, error) {
+		key, csr, err := enrollment.NewKey()
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := enroller.Enroll(ctx, ep, token, csr)
+		return resp, key, err
+	}
+
+	It("should issue a certificate for the cluster of the token and register the cluster", func() {
+		createToken("token-a", "cluster-a", "secret-token", time.Now().Add(time.Hour))
+
+		resp, _, err := enroll("secret-token")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.CA).To(Equal(string(caPEM)))
+
+		cert, err := certificatemanagement.ParseCertificate([]byte(resp.Certificate))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cert.Subject.CommonName).To(Equal("cluster-a"))
+		Expect(cert.NotAfter).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+		Expect(cert.ExtKeyUsage).To(ConsistOf(x509.ExtKeyUsageClientAuth))
+
+		mc := &v3.ManagedCluster{}
+		Expect(cli.Get(ctx, client.ObjectKey{Name: "cluster-a"}, mc)).To(Succeed())
+		Expect(mc.Annotations).To(HaveKeyWithValue(enrollment.FingerprintAnnotation, enrollment.Fingerprint(cert)))
+
+		By("binding the token to the key it read it for")
+		Expect(tokens.updates).To(Equal(1))
+		token := &corev1.Secret{}
+		Expect(cli.Get(ctx, client.ObjectKey{Name: "token-a", Namespace: "tigera-manager"}, token)).To(Succeed())
+		Expect(token.Annotations).To(HaveKey(enrollment.EnrolledKeyAnnotation))
+
+		By("only accepting the token for other keys once")
+		_, _, err = enroll("secret-token")
+		Expect(err).To(MatchError(ContainSubstring("401")))
+	})
+
+	It("should issue a certificate again for the key the token was bound to", func() {
+		createToken("token-a", "cluster-a", "secret-token", time.Time{})
+		key, csr, err := enrollment.NewKey()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = enroller.Enroll(ctx, ep, "secret-token", csr)
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := enroller.Enroll(ctx, ep, "secret-token", csr)
+		Expect(err).NotTo(HaveOccurred())
+		cert, err := certificatemanagement.ParseCertificate([]byte(resp.Certificate))
+		Expect(err).NotTo(HaveOccurred())
+		mc := &v3.ManagedCluster{}
+		Expect(cli.Get(ctx, client.ObjectKey{Name: "cluster-a"}, mc)).To(Succeed())
+		Expect(mc.Annotations).To(HaveKeyWithValue(enrollment.FingerprintAnnotation, enrollment.Fingerprint(cert)))
+
+		By("deleting the token once the certificate is confirmed")
+		pair, err := tls.X509KeyPair([]byte(resp.Certificate), key)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enroller.Confirm(ctx, ep, pair)).To(Succeed())
+		err = cli.Get(ctx, client.ObjectKey{Name: "token-a", Namespace: "tigera-manager"}, &corev1.Secret{})
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		_, err = enroller.Enroll(ctx, ep, "secret-token", csr)
+		Expect(err).To(MatchError(ContainSubstring("invalid token")))
+	})
+
+	It("should reject invalid and expired tokens", func() {
+		createToken("token-a", "cluster-a", "expired-token", time.Now().Add(-time.Minute))
+
+		_, _, err := enroll("expired-token")
+		Expect(err).To(MatchError(ContainSubstring("the token has expired")))
+		_, _, err = enroll("unknown-token")
+		Expect(err).To(MatchError(ContainSubstring("invalid token")))
+		Expect(cli.Get(ctx, client.ObjectKey{Name: "cluster-a"}, &v3.ManagedCluster{})).NotTo(Succeed())
+	})
+
+	It("should not issue a certificate when another request used the token first", func() {
+		createToken("token-a", "cluster-a", "secret-token", time.Time{})
+		tokens.raced = true
+
+		_, _, err := enroll("secret-token")
+		Expect(err).To(MatchError(ContainSubstring("invalid token")))
+		Expect(cli.Get(ctx, client.ObjectKey{Name: "cluster-a"}, &v3.ManagedCluster{})).NotTo(Succeed())
+	})
+
+	It("should not bind the token with an invalid certificate signing request", func() {
+		createToken("token-a", "cluster-a", "secret-token", time.Time{})
+
+		_, err := enroller.Enroll(ctx, ep, "secret-token", []byte("not a csr"))
+		Expect(err).To(MatchError(ContainSubstring("invalid certificate signing request")))
+		token := &corev1.Secret{}
+		Expect(cli.Get(ctx, client.ObjectKey{Name: "token-a", Namespace: "tigera-manager"}, token)).To(Succeed())
+		Expect(token.Annotations).NotTo(HaveKey(enrollment.EnrolledKeyAnnotation))
+	})
+
+	It("should ignore tokens in secrets of other types", func() {
+		Expect(cli.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "tigera-manager"},
+			Data: map[string][]byte{
+				enrollment.BootstrapTokenClusterNameKey: []byte("cluster-a"),
+				enrollment.BootstrapTokenKey:            []byte("secret-token"),
+			},
+		})).To(Succeed())
+
+		_, _, err := enroll("secret-token")
+		Expect(err).To(MatchError(ContainSubstring("invalid token")))
+	})
+
+	It("should renew the active certificate of a managed cluster", func() {
+		createToken("token-a", "cluster-a", "secret-token", time.Time{})
+		resp, key, err := enroll("secret-token")
+		Expect(err).NotTo(HaveOccurred())
+		current, err := tls.X509KeyPair([]byte(resp.Certificate), key)
+		Expect(err).NotTo(HaveOccurred())
+		currentCert, err := certificatemanagement.ParseCertificate([]byte(resp.Certificate))
+		Expect(err).NotTo(HaveOccurred())
+
+		renew := func(with tls.Certificate) (tls.Certificate, *x509.Certificate, error) {
+			key, csr, err := enrollment.NewKey()
+			Expect(err).NotTo(HaveOccurred())
+			renewed, err := enroller.Renew(ctx, ep, with, csr)
+			if err != nil {
+				return tls.Certificate{}, nil, err
+			}
+			pair, err := tls.X509KeyPair([]byte(renewed.Certificate), key)
+			Expect(err).NotTo(HaveOccurred())
+			cert, err := certificatemanagement.ParseCertificate([]byte(renewed.Certificate))
+			Expect(err).NotTo(HaveOccurred())
+			return pair, cert, nil
+		}
+		fingerprints := func() map[string]string {
+			mc := &v3.ManagedCluster{}
+			Expect(cli.Get(ctx, client.ObjectKey{Name: "cluster-a"}, mc)).To(Succeed())
+			return mc.Annotations
+		}
+
+		_, lost, err := renew(current)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lost.Subject.CommonName).To(Equal("cluster-a"))
+
+		By("keeping the current certificate active until the renewed one is confirmed")
+		Expect(fingerprints()).To(HaveKeyWithValue(enrollment.FingerprintAnnotation, enrollment.Fingerprint(currentCert)))
+		Expect(fingerprints()).To(HaveKeyWithValue(enrollment.PendingFingerprintAnnotation, enrollment.Fingerprint(lost)))
+
+		By("renewing again with the current certificate when the renewed one was not stored")
+		renewed, renewedCert, err := renew(current)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fingerprints()).To(HaveKeyWithValue(enrollment.FingerprintAnnotation, enrollment.Fingerprint(currentCert)))
+		Expect(fingerprints()).To(HaveKeyWithValue(enrollment.PendingFingerprintAnnotation, enrollment.Fingerprint(renewedCert)))
+
+		By("activating the renewed certificate once it is confirmed")
+		Expect(enroller.Confirm(ctx, ep, renewed)).To(Succeed())
+		Expect(fingerprints()).To(HaveKeyWithValue(enrollment.FingerprintAnnotation, enrollment.Fingerprint(renewedCert)))
+		Expect(fingerprints()).NotTo(HaveKey(enrollment.PendingFingerprintAnnotation))
+		Expect(enroller.Confirm(ctx, ep, renewed)).To(Succeed())
+
+		By("rejecting the certificate that was replaced")
+		_, _, err = renew(current)
+		Expect(err).To(MatchError(ContainSubstring("not the active certificate")))
+		Expect(enroller.Confirm(ctx, ep, current)).To(MatchError(ContainSubstring("not the active certificate")))
+
+		By("confirming the pending certificate by renewing with it")
+		next, nextCert, err := renew(renewed)
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = renew(next)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fingerprints()).To(HaveKeyWithValue(enrollment.FingerprintAnnotation, enrollment.Fingerprint(nextCert)))
+		_, _, err = renew(renewed)
+		Expect(err).To(MatchError(ContainSubstring("not the active certificate")))
+	})
+
+	It("should require a client certificate to renew", func() {
+		_, csr, err := enrollment.NewKey()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = enroller.Renew(ctx, ep, tls.Certificate{}, csr)
+		Expect(err).To(MatchError(ContainSubstring("a client certificate is required")))
+	})
+})
+
+// tokenClient counts the updates of objects and, if raced is set, updates objects before the update it is asked to
+// do, as if another request had updated them first.
+type tokenClient struct {
+	client.Client
+	raced   bool
+	updates int
+}
+
+func (c *tokenClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.updates++
+	if c.raced {
+		if err := c.Client.Update(ctx, obj.DeepCopyObject().(client.Object)); err != nil {
+			return err
+		}
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
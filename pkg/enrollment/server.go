@@ -0,0 +1,389 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enrollment
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("enrollment")
+
+// Server issues tunnel certificates to managed clusters. Managed clusters enroll with a bootstrap token, which
+// registers them as a ManagedCluster, and renew their certificate by authenticating with it. Renewed certificates are
+// pending until the managed cluster confirms them; until then, the previous certificate stays active.
+type Server struct {
+	Client client.Client
+
+	// Namespace is the namespace of the bootstrap token secrets.
+	Namespace string
+
+	// CA and CAKey are the tunnel CA that signs the certificates.
+	CA    *x509.Certificate
+	CAKey crypto.Signer
+
+	// Validity is how long issued certificates are valid for.
+	Validity time.Duration
+}
+
+type httpError struct {
+	code int
+	msg  string
+}
+
+func (e *httpError) Error() string {
+	return e.msg
+}
+
+// Handler returns the handler of the enrollment endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(EnrollPath, s.handle(s.enroll))
+	mux.HandleFunc(RenewPath, s.handle(s.renew))
+	mux.HandleFunc(ConfirmPath, s.handle(s.confirm))
+	mux.HandleFunc(HealthPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+// Serve serves the enrollment endpoints on addr with the certificate until the context is done. Clients that
+// present a certificate must present one signed by the tunnel CA.
+func (s *Server) Serve(ctx context.Context, addr string, cert tls.Certificate) error {
+	pool := x509.NewCertPool()
+	pool.AddCert(s.CA)
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: s.Handler(),
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			ClientAuth:   tls.VerifyClientCertIfGiven,
+			ClientCAs:    pool,
+			MinVersion:   tls.VersionTLS12,
+		},
+	}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	if err := srv.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) handle(f func(ctx context.Context, r *http.Request, req *Request) (*Response, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		req := &Request{}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		resp, err := f(r.Context(), r, req)
+		if err != nil {
+			var herr *httpError
+			if errors.As(err, &herr) {
+				http.Error(w, herr.msg, herr.code)
+				return
+			}
+			log.Error(err, "Failed to handle request", "path", r.URL.Path)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// enroll issues a certificate to the managed cluster that the bootstrap token was created for. The token is bound to
+// the key of the first request that uses it, by recording the fingerprint of the key on its secret. Requests for the
+// same key are issued a certificate again, so that a managed cluster that did not receive or store its certificate can
+// retry, while requests for any other key are rejected. The token is deleted once the managed cluster confirms its
+// certificate.
+func (s *Server) enroll(ctx context.Context, _ *http.Request, req *Request) (*Response, error) {
+	token, err := s.bootstrapToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := string(token.Data[BootstrapTokenClusterNameKey])
+
+	// Check the request before binding the token, so that a malformed request does not use it up.
+	csr, err := parseCSR(req.CSR)
+	if err != nil {
+		return nil, err
+	}
+	keyFingerprint, err := publicKeyFingerprint(csr)
+	if err != nil {
+		return nil, err
+	}
+
+	if enrolled, ok := token.Annotations[EnrolledKeyAnnotation]; ok {
+		if enrolled != keyFingerprint {
+			return nil, &httpError{http.StatusUnauthorized, "invalid token"}
+		}
+	} else {
+		// The update is rejected if the token changed since it was read, so that concurrent requests with the same
+		// token cannot both bind it to their key.
+		if token.Annotations == nil {
+			token.Annotations = map[string]string{}
+		}
+		token.Annotations[EnrolledKeyAnnotation] = keyFingerprint
+		err = s.Client.Update(ctx, token)
+		if k8serrors.IsNotFound(err) || k8serrors.IsConflict(err) {
+			return nil, &httpError{http.StatusUnauthorized, "invalid token"}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	resp, cert, err := s.issue(csr, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setFingerprints(ctx, clusterName, Fingerprint(cert), ""); err != nil {
+		return nil, err
+	}
+	log.Info("Managed cluster enrolled", "cluster", clusterName)
+	return resp, nil
+}
+
+// renew issues a new certificate to the managed cluster that authenticated with its active or pending certificate.
+// The new certificate is pending until it is confirmed, so the certificate the managed cluster authenticated with
+// remains valid if it never receives or stores the new one. Authenticating with the pending certificate confirms it.
+func (s *Server) renew(ctx context.Context, r *http.Request, req *Request) (*Response, error) {
+	clusterName, fingerprint, err := s.authenticate(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := parseCSR(req.CSR)
+	if err != nil {
+		return nil, err
+	}
+	resp, cert, err := s.issue(csr, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setFingerprints(ctx, clusterName, fingerprint, Fingerprint(cert)); err != nil {
+		return nil, err
+	}
+	log.Info("Managed cluster certificate renewed", "cluster", clusterName)
+	return resp, nil
+}
+
+// confirm makes the pending certificate that the managed cluster authenticated with its active certificate, and deletes
+// the bootstrap tokens the managed cluster enrolled with. Confirming the active certificate again succeeds, so that
+// the managed cluster can retry a confirmation whose response it lost.
+func (s *Server) confirm(ctx context.Context, r *http.Request, _ *Request) (*Response, error) {
+	clusterName, fingerprint, err := s.authenticate(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setFingerprints(ctx, clusterName, fingerprint, ""); err != nil {
+		return nil, err
+	}
+	if err := s.deleteEnrolledTokens(ctx, clusterName); err != nil {
+		return nil, err
+	}
+	log.Info("Managed cluster certificate confirmed", "cluster", clusterName)
+	return &Response{}, nil
+}
+
+// authenticate returns the name of the managed cluster of the client certificate of the request, and the fingerprint
+// of the certificate, if it is the active or pending certificate of the managed cluster.
+func (s *Server) authenticate(ctx context.Context, r *http.Request) (string, string, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return "", "", &httpError{http.StatusUnauthorized, "a client certificate is required"}
+	}
+	cert := r.TLS.PeerCertificates[0]
+	clusterName := cert.Subject.CommonName
+
+	mc := &v3.ManagedCluster{}
+	if err := s.Client.Get(ctx, client.ObjectKey{Name: clusterName}, mc); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", "", &httpError{http.StatusForbidden, "unknown managed cluster"}
+		}
+		return "", "", err
+	}
+	fingerprint := Fingerprint(cert)
+	if fingerprint != mc.Annotations[FingerprintAnnotation] && fingerprint != mc.Annotations[PendingFingerprintAnnotation] {
+		return "", "", &httpError{http.StatusForbidden, "the certificate is not the active certificate of the managed cluster"}
+	}
+	return clusterName, fingerprint, nil
+}
+
+// bootstrapToken returns the secret of the token, if it is valid.
+func (s *Server) bootstrapToken(ctx context.Context, token string) (*corev1.Secret, error) {
+	if token == "" {
+		return nil, &httpError{http.StatusUnauthorized, "invalid token"}
+	}
+	secrets := &corev1.SecretList{}
+	if err := s.Client.List(ctx, secrets, client.InNamespace(s.Namespace)); err != nil {
+		return nil, err
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if secret.Type != BootstrapTokenSecretType || subtle.ConstantTimeCompare(secret.Data[BootstrapTokenKey], []byte(token)) != 1 {
+			continue
+		}
+		if len(secret.Data[BootstrapTokenClusterNameKey]) == 0 {
+			return nil, &httpError{http.StatusUnauthorized, "invalid token"}
+		}
+		if exp := secret.Data[BootstrapTokenExpirationKey]; len(exp) != 0 {
+			t, err := time.Parse(time.RFC3339, string(exp))
+			if err != nil || time.Now().After(t) {
+				return nil, &httpError{http.StatusUnauthorized, "the token has expired"}
+			}
+		}
+		return secret, nil
+	}
+	return nil, &httpError{http.StatusUnauthorized, "invalid token"}
+}
+
+// deleteEnrolledTokens deletes the bootstrap tokens of the managed cluster that were bound to a key by enrolling.
+func (s *Server) deleteEnrolledTokens(ctx context.Context, clusterName string) error {
+	secrets := &corev1.SecretList{}
+	if err := s.Client.List(ctx, secrets, client.InNamespace(s.Namespace)); err != nil {
+		return err
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if secret.Type != BootstrapTokenSecretType || string(secret.Data[BootstrapTokenClusterNameKey]) != clusterName {
+			continue
+		}
+		if _, ok := secret.Annotations[EnrolledKeyAnnotation]; !ok {
+			continue
+		}
+		if err := s.Client.Delete(ctx, secret); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseCSR parses a PEM encoded certificate signing request and checks its signature.
+func parseCSR(csrPEM string) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode([]byte(csrPEM))
+	if block == nil {
+		return nil, &httpError{http.StatusBadRequest, "invalid certificate signing request"}
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil || csr.CheckSignature() != nil {
+		return nil, &httpError{http.StatusBadRequest, "invalid certificate signing request"}
+	}
+	return csr, nil
+}
+
+// publicKeyFingerprint returns the fingerprint of the public key of the certificate signing request.
+func publicKeyFingerprint(csr *x509.CertificateRequest) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(csr.PublicKey)
+	if err != nil {
+		return "", &httpError{http.StatusBadRequest, "invalid certificate signing request"}
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(der)), nil
+}
+
+// issue signs a certificate for the managed cluster.
+func (s *Server) issue(csr *x509.CertificateRequest, clusterName string) (*Response, *x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		// The managed cluster is identified by the common name of its certificate.
+		Subject:     pkix.Name{CommonName: clusterName},
+		NotBefore:   now.Add(-5 * time.Minute),
+		NotAfter:    now.Add(s.Validity),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, s.CA, csr.PublicKey, s.CAKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sign certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var certPEM, caPEM bytes.Buffer
+	if err := pem.Encode(&certPEM, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+		return nil, nil, err
+	}
+	if err := pem.Encode(&caPEM, &pem.Block{Type: "CERTIFICATE", Bytes: s.CA.Raw}); err != nil {
+		return nil, nil, err
+	}
+	return &Response{Certificate: certPEM.String(), CA: caPEM.String()}, cert, nil
+}
+
+// setFingerprints records the fingerprints of the active certificate, which voltron accepts tunnels from, and of the
+// pending certificate on the ManagedCluster, creating it if needed. An empty pending fingerprint removes it.
+func (s *Server) setFingerprints(ctx context.Context, clusterName, active, pending string) error {
+	mc := &v3.ManagedCluster{}
+	err := s.Client.Get(ctx, client.ObjectKey{Name: clusterName}, mc)
+	if k8serrors.IsNotFound(err) {
+		mc = &v3.ManagedCluster{
+			TypeMeta: metav1.TypeMeta{Kind: v3.KindManagedCluster, APIVersion: v3.GroupVersionCurrent},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        clusterName,
+				Annotations: map[string]string{FingerprintAnnotation: active},
+			},
+		}
+		if pending != "" {
+			mc.Annotations[PendingFingerprintAnnotation] = pending
+		}
+		return s.Client.Create(ctx, mc)
+	} else if err != nil {
+		return err
+	}
+	if mc.Annotations == nil {
+		mc.Annotations = map[string]string{}
+	}
+	mc.Annotations[FingerprintAnnotation] = active
+	if pending != "" {
+		mc.Annotations[PendingFingerprintAnnotation] = pending
+	} else {
+		delete(mc.Annotations, PendingFingerprintAnnotation)
+	}
+	return s.Client.Update(ctx, mc)
+}
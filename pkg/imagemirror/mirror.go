@@ -0,0 +1,165 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package imagemirror produces what is needed to run the operator from a registry mirror, e.g. in air-gapped
+// clusters: the list of images to copy into the mirror, and an ImageSet that pins the images to the digests
+// that were mirrored.
+package imagemirror
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operator "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/controller/utils/imageset"
+)
+
+// Config describes the registry mirror that the images are copied to.
+type Config struct {
+	// Variant selects the images to mirror.
+	Variant operator.ProductVariant
+
+	// Registry, ImagePath and ImagePrefix have the same meaning as in the Installation that will use the mirror.
+	Registry    string
+	ImagePath   string
+	ImagePrefix string
+
+	// RegistryOverrides maps image names (e.g. calico/node), or image name prefixes ending with a slash
+	// (e.g. tigera/), to the registry they are mirrored to when the mirror splits images across registries.
+	RegistryOverrides map[string]string
+}
+
+// Image is an image that is copied from Source to Destination.
+type Image struct {
+	// Name is the image name without registry, tag or digest, as used in an ImageSet.
+	Name string
+	// Registry is set if the image is mirrored to a registry other than the one of the Config.
+	Registry    string
+	Source      string
+	Destination string
+}
+
+// Resolver returns the digest of an image.
+type Resolver interface {
+	Digest(ctx context.Context, img Image) (string, error)
+}
+
+// Images returns the images to mirror for the Config.
+func Images(cfg Config) ([]Image, error) {
+	registry := normalizeRegistry(cfg.Registry)
+	var images []Image
+	for _, c := range components.VariantComponents(cfg.Variant) {
+		src, err := components.GetReference(c, "", "", "", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		img := Image{Name: c.Image, Source: src}
+		if r := registryOverride(cfg.RegistryOverrides, c.Image); r != "" {
+			img.Registry = normalizeRegistry(r)
+		}
+		dstRegistry := registry
+		if img.Registry != "" {
+			dstRegistry = img.Registry
+		}
+		if img.Destination, err = components.GetReference(c, dstRegistry, cfg.ImagePath, cfg.ImagePrefix, nil); err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
+
+// WriteCopyList writes a line with the source and destination of each image, separated by a space. The list can be
+// fed to crane or skopeo, e.g. `xargs -n2 crane copy < list`.
+func WriteCopyList(w io.Writer, images []Image) error {
+	for _, img := range images {
+		if _, err := fmt.Fprintf(w, "%s %s\n", img.Source, img.Destination); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BuildImageSet returns the ImageSet for the variant with the digests of the images returned by the resolver.
+func BuildImageSet(ctx context.Context, variant operator.ProductVariant, images []Image, r Resolver) (*operator.ImageSet, error) {
+	is := &operator.ImageSet{
+		TypeMeta:   metav1.TypeMeta{Kind: "ImageSet", APIVersion: "operator.tigera.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: imageset.GetSetName(variant)},
+	}
+
+	var errMsgs []string
+	for _, img := range images {
+		digest, err := r.Digest(ctx, img)
+		if err != nil {
+			errMsgs = append(errMsgs, fmt.Sprintf("%s: %s", img.Destination, err))
+			continue
+		}
+		is.Spec.Images = append(is.Spec.Images, operator.Image{
+			Image:    img.Name,
+			Digest:   digest,
+			Registry: img.Registry,
+		})
+	}
+	if len(errMsgs) != 0 {
+		return nil, fmt.Errorf("failed to resolve image digests: %s", strings.Join(errMsgs, "; "))
+	}
+
+	if err := imageset.ValidateImageSet(is); err != nil {
+		return nil, err
+	}
+	return is, nil
+}
+
+// ParseRegistryOverrides parses a comma separated list of <image>=<registry> pairs.
+func ParseRegistryOverrides(s string) (map[string]string, error) {
+	overrides := map[string]string{}
+	if s == "" {
+		return overrides, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf("invalid registry override %q, expected <image>=<registry>", pair)
+		}
+		overrides[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return overrides, nil
+}
+
+// registryOverride returns the registry for the image, preferring an exact match over the longest matching prefix.
+func registryOverride(overrides map[string]string, image string) string {
+	if r, ok := overrides[image]; ok {
+		return r
+	}
+	var match, registry string
+	for prefix, r := range overrides {
+		if strings.HasSuffix(prefix, "/") && strings.HasPrefix(image, prefix) && len(prefix) > len(match) {
+			match, registry = prefix, r
+		}
+	}
+	return registry
+}
+
+func normalizeRegistry(registry string) string {
+	if registry == "" || strings.HasSuffix(registry, "/") {
+		return registry
+	}
+	return registry + "/"
+}
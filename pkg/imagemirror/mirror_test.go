@@ -0,0 +1,178 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagemirror
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	operator "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/imageregistry"
+)
+
+// fakeRegistry serves manifests the way a registry does, requiring a bearer token from its token endpoint.
+func fakeRegistry() *httptest.Server {
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token": "secret"}`)
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		// Reply with a digest derived from the repository so each image has a different one.
+		repo := strings.TrimPrefix(strings.Split(r.URL.Path, "/manifests/")[0], "/v2/")
+		if repo == "calico/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", "sha256:"+strings.ReplaceAll(repo, "/", "-"))
+	})
+	server = httptest.NewServer(mux)
+	return server
+}
+
+var _ = Describe("image mirror tests", func() {
+	It("should mirror the images of a variant", func() {
+		images, err := Images(Config{Variant: operator.Calico, Registry: "mirror.example.com:5000", ImagePath: "mirror"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(images).To(HaveLen(len(components.VariantComponents(operator.Calico))))
+		Expect(images[0]).To(Equal(Image{
+			Name:        "calico/cni",
+			Source:      fmt.Sprintf("docker.io/calico/cni:%s", components.ComponentCalicoCNI.Version),
+			Destination: fmt.Sprintf("mirror.example.com:5000/mirror/cni:%s", components.ComponentCalicoCNI.Version),
+		}))
+	})
+
+	It("should mirror images to the registries of the overrides", func() {
+		images, err := Images(Config{
+			Variant:  operator.TigeraSecureEnterprise,
+			Registry: "mirror.example.com/",
+			RegistryOverrides: map[string]string{
+				"tigera/":         "tigera.example.com",
+				"tigera/cnx-node": "node.example.com/",
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		byName := map[string]Image{}
+		for _, img := range images {
+			byName[img.Name] = img
+		}
+		Expect(byName["tigera/cnx-node"].Registry).To(Equal("node.example.com/"))
+		Expect(byName["tigera/cnx-node"].Destination).To(HavePrefix("node.example.com/tigera/cnx-node:"))
+		Expect(byName["tigera/cnx-manager"].Registry).To(Equal("tigera.example.com/"))
+		Expect(byName["calico/pod2daemon-flexvol"].Registry).To(Equal(""))
+		Expect(byName["calico/pod2daemon-flexvol"].Destination).To(HavePrefix("mirror.example.com/calico/pod2daemon-flexvol:"))
+	})
+
+	It("should write a copy list", func() {
+		var b bytes.Buffer
+		Expect(WriteCopyList(&b, []Image{
+			{Source: "docker.io/calico/node:v1", Destination: "mirror.example.com/calico/node:v1"},
+			{Source: "docker.io/calico/cni:v1", Destination: "mirror.example.com/calico/cni:v1"},
+		})).To(Succeed())
+		Expect(b.String()).To(Equal("docker.io/calico/node:v1 mirror.example.com/calico/node:v1\n" +
+			"docker.io/calico/cni:v1 mirror.example.com/calico/cni:v1\n"))
+	})
+
+	It("should parse registry overrides", func() {
+		overrides, err := ParseRegistryOverrides("tigera/=a.example.com/, calico/node=b.example.com/")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overrides).To(Equal(map[string]string{"tigera/": "a.example.com/", "calico/node": "b.example.com/"}))
+
+		_, err = ParseRegistryOverrides("calico/node")
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("resolving digests from a registry", func() {
+		var server *httptest.Server
+		var host string
+
+		BeforeEach(func() {
+			server = fakeRegistry()
+			u, err := url.Parse(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			host = u.Host
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should build an ImageSet with the digests of the mirrored images", func() {
+			images, err := Images(Config{
+				Variant:           operator.Calico,
+				Registry:          host,
+				RegistryOverrides: map[string]string{"calico/node": host + "/"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			is, err := BuildImageSet(context.Background(), operator.Calico, images, &RegistryResolver{Client: imageregistry.Client{Insecure: true}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(is.Name).To(Equal("calico-" + components.CalicoRelease))
+			Expect(is.Spec.Images).To(HaveLen(len(images)))
+			Expect(is.Spec.Images).To(ContainElement(operator.Image{Image: "calico/cni", Digest: "sha256:calico-cni"}))
+			Expect(is.Spec.Images).To(ContainElement(operator.Image{Image: "calico/node", Digest: "sha256:calico-node", Registry: host + "/"}))
+		})
+
+		It("should report the images that could not be resolved", func() {
+			images := []Image{
+				{Name: "calico/cni", Destination: host + "/calico/cni:v1"},
+				{Name: "calico/missing", Destination: host + "/calico/missing:v1"},
+			}
+			_, err := BuildImageSet(context.Background(), operator.Calico, images, &RegistryResolver{Client: imageregistry.Client{Insecure: true}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(host + "/calico/missing:v1: manifest not found"))
+		})
+	})
+
+	It("should resolve digests from an OCI image layout", func() {
+		dir, err := os.MkdirTemp("", "oci-layout")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		Expect(os.WriteFile(filepath.Join(dir, "index.json"), []byte(`{
+  "schemaVersion": 2,
+  "manifests": [
+    {"digest": "sha256:node", "annotations": {"org.opencontainers.image.ref.name": "mirror.example.com/calico/node:v1"}},
+    {"digest": "sha256:cni", "annotations": {"io.containerd.image.name": "docker.io/calico/cni:v1"}}
+  ]
+}`), 0644)).To(Succeed())
+
+		r, err := NewOCILayoutResolver(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Digest(context.Background(), Image{Source: "docker.io/calico/node:v1", Destination: "mirror.example.com/calico/node:v1"})).To(Equal("sha256:node"))
+		Expect(r.Digest(context.Background(), Image{Source: "docker.io/calico/cni:v1", Destination: "mirror.example.com/calico/cni:v1"})).To(Equal("sha256:cni"))
+		_, err = r.Digest(context.Background(), Image{Source: "docker.io/calico/typha:v1", Destination: "mirror.example.com/calico/typha:v1"})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,68 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagemirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Annotations that tools such as skopeo, crane and containerd use to name the manifests of an OCI image layout.
+const (
+	ociRefNameAnnotation          = "org.opencontainers.image.ref.name"
+	containerdImageNameAnnotation = "io.containerd.image.name"
+)
+
+// OCILayoutResolver resolves the digests of images from a local OCI image layout, e.g. one populated with
+// `skopeo copy docker://<source> oci:<dir>:<destination>`. The manifests in the layout must be named with either
+// the destination or the source reference of the image.
+type OCILayoutResolver struct {
+	index ociIndex
+}
+
+type ociIndex struct {
+	Manifests []struct {
+		Digest      string            `json:"digest"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"manifests"`
+}
+
+// NewOCILayoutResolver reads the index of the OCI image layout in dir.
+func NewOCILayoutResolver(dir string) (*OCILayoutResolver, error) {
+	b, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OCI image layout: %w", err)
+	}
+	r := &OCILayoutResolver{}
+	if err := json.Unmarshal(b, &r.index); err != nil {
+		return nil, fmt.Errorf("failed to parse OCI image layout index: %w", err)
+	}
+	return r, nil
+}
+
+// Digest returns the digest of the manifest named after the destination or source of the image.
+func (r *OCILayoutResolver) Digest(_ context.Context, img Image) (string, error) {
+	for _, ref := range []string{img.Destination, img.Source} {
+		for _, m := range r.index.Manifests {
+			if m.Annotations[ociRefNameAnnotation] == ref || m.Annotations[containerdImageNameAnnotation] == ref {
+				return m.Digest, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("image not found in OCI image layout")
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagemirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tigera/operator/pkg/imageregistry"
+)
+
+// RegistryResolver resolves the digests of images by querying the registry they have been mirrored to.
+type RegistryResolver struct {
+	Client imageregistry.Client
+}
+
+// Digest returns the digest of the destination of the image.
+func (r *RegistryResolver) Digest(ctx context.Context, img Image) (string, error) {
+	ref, err := imageregistry.ParseReference(img.Destination)
+	if err != nil {
+		return "", err
+	}
+	m, err := r.Client.Manifest(ctx, ref)
+	if errors.Is(err, imageregistry.ErrNotFound) {
+		return "", fmt.Errorf("manifest not found")
+	}
+	if err != nil {
+		return "", err
+	}
+	return m.Digest, nil
+}
@@ -0,0 +1,375 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package imageregistry is a minimal client of the container registry HTTP API v2, for fetching image manifests and
+// blobs.
+package imageregistry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when the registry does not have the requested manifest or blob.
+var ErrNotFound = errors.New("not found")
+
+// DefaultTimeout is the timeout of the requests of clients that don't have their own HTTP client.
+const DefaultTimeout = 30 * time.Second
+
+var defaultHTTPClient = &http.Client{Timeout: DefaultTimeout}
+
+// The manifest types accepted by default. Multi-arch indexes are preferred so that the digest is valid for
+// every platform.
+var ManifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Reference is a parsed image reference of the form <host>/<repository>:<tag> or <host>/<repository>@<digest>.
+type Reference struct {
+	Host       string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses an image reference. The reference must include a registry host, and a tag or digest.
+func ParseReference(ref string) (Reference, error) {
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) != 2 {
+		return Reference{}, fmt.Errorf("image reference %q does not include a registry", ref)
+	}
+	r := Reference{Host: parts[0], Repository: parts[1]}
+	if i := strings.Index(r.Repository, "@"); i >= 0 {
+		r.Repository, r.Digest = r.Repository[:i], r.Repository[i+1:]
+	} else if i := strings.LastIndex(r.Repository, ":"); i >= 0 {
+		r.Repository, r.Tag = r.Repository[:i], r.Repository[i+1:]
+	} else {
+		return Reference{}, fmt.Errorf("image reference %q does not include a tag or digest", ref)
+	}
+	return r, nil
+}
+
+// Identifier returns the digest of the reference if it has one, otherwise its tag.
+func (r Reference) Identifier() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// Manifest is a manifest fetched from a registry.
+type Manifest struct {
+	Digest    string
+	MediaType string
+	Body      []byte
+}
+
+// Auth is a username and password to authenticate to a registry with.
+type Auth struct {
+	Username string
+	Password string
+}
+
+// Keychain holds the credentials of registries by host.
+type Keychain map[string]Auth
+
+// dockerConfig is the format of the .dockerconfigjson key of kubernetes.io/dockerconfigjson secrets. The
+// .dockercfg key of kubernetes.io/dockercfg secrets only holds the auths.
+type dockerConfig struct {
+	Auths map[string]dockerAuth `json:"auths"`
+}
+
+type dockerAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+// AddDockerConfig adds the credentials of a .dockerconfigjson file, or of a legacy .dockercfg file if legacy is set,
+// to the keychain.
+func (k Keychain) AddDockerConfig(data []byte, legacy bool) error {
+	cfg := dockerConfig{}
+	if legacy {
+		if err := json.Unmarshal(data, &cfg.Auths); err != nil {
+			return fmt.Errorf("failed to parse docker config: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("failed to parse docker config: %w", err)
+	}
+
+	for server, a := range cfg.Auths {
+		if a.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(a.Auth)
+			if err != nil {
+				return fmt.Errorf("failed to decode the auth of %s: %w", server, err)
+			}
+			if parts := strings.SplitN(string(decoded), ":", 2); len(parts) == 2 {
+				a.Username, a.Password = parts[0], parts[1]
+			}
+		}
+		k[registryHost(server)] = Auth{Username: a.Username, Password: a.Password}
+	}
+	return nil
+}
+
+// registryHost returns the host of a registry as used in image references, given the server of a docker config, which
+// may be a URL.
+func registryHost(server string) string {
+	host := server
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.SplitN(host, "/", 2)[0]
+	switch host {
+	case "index.docker.io", "registry-1.docker.io":
+		return "docker.io"
+	}
+	return host
+}
+
+// Client fetches manifests and blobs from registries. Registries that require a bearer token are supported through
+// the token endpoint announced by the registry.
+type Client struct {
+	// HTTPClient is used for the requests to the registry. If not set, a client with DefaultTimeout is used.
+	HTTPClient *http.Client
+
+	// Insecure makes the client use plain HTTP, e.g. for a local registry.
+	Insecure bool
+
+	// Username and Password are used to authenticate to the registry, if set.
+	Username string
+	Password string
+
+	// Keychain holds the credentials used for registries when Username isn't set.
+	Keychain Keychain
+}
+
+// Manifest fetches the manifest that ref identifies. If no media types are given, ManifestMediaTypes are accepted.
+func (c *Client) Manifest(ctx context.Context, ref Reference, mediaTypes ...string) (*Manifest, error) {
+	if len(mediaTypes) == 0 {
+		mediaTypes = ManifestMediaTypes
+	}
+	resp, err := c.get(ctx, ref, "manifests/"+ref.Identifier(), strings.Join(mediaTypes, ", "))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{
+		Digest:    resp.Header.Get("Docker-Content-Digest"),
+		MediaType: resp.Header.Get("Content-Type"),
+		Body:      body,
+	}
+	if m.Digest == "" {
+		// The registry did not report the digest so compute it from the manifest.
+		m.Digest = fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+	}
+	return m, nil
+}
+
+// Blob fetches the blob with the digest from the repository of ref.
+func (c *Client) Blob(ctx context.Context, ref Reference, digest string) ([]byte, error) {
+	resp, err := c.get(ctx, ref, "blobs/"+digest, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func (c *Client) get(ctx context.Context, ref Reference, path, accept string) (*http.Response, error) {
+	host := ref.Host
+	// Docker Hub serves the registry API from a different host than the one in image references.
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+	scheme := "https"
+	if c.Insecure {
+		scheme = "http"
+	}
+	u := fmt.Sprintf("%s://%s/v2/%s/%s", scheme, host, ref.Repository, path)
+
+	resp, err := c.do(ctx, u, accept, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		auth, err := c.authorization(ctx, ref.Host, challenge)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = c.do(ctx, u, accept, auth); err != nil {
+			return nil, err
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	resp.Body.Close()
+	return nil, fmt.Errorf("unexpected status fetching %s: %s", path, resp.Status)
+}
+
+func (c *Client) do(ctx context.Context, u, accept, auth string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	return c.httpClient().Do(req)
+}
+
+// credentials returns the credentials for the registry at host.
+func (c *Client) credentials(host string) Auth {
+	if c.Username != "" {
+		return Auth{Username: c.Username, Password: c.Password}
+	}
+	return c.Keychain[host]
+}
+
+// authorization returns the Authorization header value that satisfies the WWW-Authenticate challenge of the registry.
+func (c *Client) authorization(ctx context.Context, host, challenge string) (string, error) {
+	creds := c.credentials(host)
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if creds.Username == "" {
+			return "", fmt.Errorf("registry requires credentials")
+		}
+		req, _ := http.NewRequest(http.MethodGet, "", nil)
+		req.SetBasicAuth(creds.Username, creds.Password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		realm, err := url.Parse(params["realm"])
+		if err != nil || params["realm"] == "" {
+			return "", fmt.Errorf("invalid token realm in challenge %q", challenge)
+		}
+		q := realm.Query()
+		for _, k := range []string{"service", "scope"} {
+			if params[k] != "" {
+				q.Set(k, params[k])
+			}
+		}
+		realm.RawQuery = q.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+		if err != nil {
+			return "", err
+		}
+		if creds.Username != "" {
+			req.SetBasicAuth(creds.Username, creds.Password)
+		}
+		resp, err := c.httpClient().Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("unexpected status fetching registry token: %s", resp.Status)
+		}
+
+		token := struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}{}
+		if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+			return "", fmt.Errorf("failed to decode registry token: %w", err)
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		return "Bearer " + token.Token, nil
+	}
+	return "", fmt.Errorf("unsupported registry authentication challenge %q", challenge)
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return defaultHTTPClient
+}
+
+// parseChallenge parses a WWW-Authenticate header, e.g. `Bearer realm="https://auth.example.com/token",service="x"`.
+// Parameter values may be quoted strings, which can contain commas and escaped characters, e.g. a scope of
+// `repository:calico/node:pull,push`.
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	if len(parts) != 2 {
+		return parts[0], params
+	}
+
+	s := parts[1]
+	for {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.Index(s, "=")
+		if eq < 0 {
+			return parts[0], params
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " ")
+
+		var value strings.Builder
+		if strings.HasPrefix(s, `"`) {
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteByte(s[i])
+			}
+			if i < len(s) {
+				// Skip the closing quote.
+				i++
+			}
+			s = s[i:]
+		} else {
+			end := strings.Index(s, ",")
+			if end < 0 {
+				end = len(s)
+			}
+			value.WriteString(strings.TrimSpace(s[:end]))
+			s = s[end:]
+		}
+		params[key] = value.String()
+	}
+}
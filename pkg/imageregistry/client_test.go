@@ -0,0 +1,53 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imageregistry
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("registry client tests", func() {
+	DescribeTable("parsing authentication challenges",
+		func(challenge, scheme string, params map[string]string) {
+			s, p := parseChallenge(challenge)
+			Expect(s).To(Equal(scheme))
+			Expect(p).To(Equal(params))
+		},
+		Entry("basic", `Basic realm="registry"`, "Basic", map[string]string{"realm": "registry"}),
+		Entry("no parameters", `Basic`, "Basic", map[string]string{}),
+		Entry("bearer",
+			`Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:calico/node:pull"`,
+			"Bearer", map[string]string{"realm": "https://auth.example.com/token", "service": "registry.example.com", "scope": "repository:calico/node:pull"}),
+		Entry("commas in quoted values",
+			`Bearer realm="https://auth.example.com/token", scope="repository:calico/node:pull,push", service=registry`,
+			"Bearer", map[string]string{"realm": "https://auth.example.com/token", "scope": "repository:calico/node:pull,push", "service": "registry"}),
+		Entry("escaped quotes", `Basic realm="the \"registry\""`, "Basic", map[string]string{"realm": `the "registry"`}),
+	)
+
+	It("should add the credentials of docker configs to the keychain", func() {
+		k := Keychain{}
+		Expect(k.AddDockerConfig([]byte(`{"auths": {"https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"}, "quay.io": {"username": "quser", "password": "qpass"}}}`), false)).To(Succeed())
+		Expect(k.AddDockerConfig([]byte(`{"registry.example.com:5000": {"auth": "bGVnYWN5OnNlY3JldA=="}}`), true)).To(Succeed())
+		Expect(k).To(Equal(Keychain{
+			"docker.io":                 {Username: "user", Password: "pass"},
+			"quay.io":                   {Username: "quser", Password: "qpass"},
+			"registry.example.com:5000": {Username: "legacy", Password: "secret"},
+		}))
+
+		Expect(k.AddDockerConfig([]byte(`{"auths": {"quay.io": {"auth": "!"}}}`), false)).To(HaveOccurred())
+	})
+})
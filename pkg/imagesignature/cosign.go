@@ -0,0 +1,248 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package imagesignature verifies cosign signatures of images with a public key.
+package imagesignature
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tigera/operator/pkg/imageregistry"
+)
+
+var (
+	// ErrNoSignature is returned for images that have no cosign signature.
+	ErrNoSignature = errors.New("no signature found")
+	// ErrInvalidSignature is returned for images whose signatures were not made with the public key.
+	ErrInvalidSignature = errors.New("no signature matches the public key")
+)
+
+const (
+	signatureAnnotation = "dev.cosignproject.cosign/signature"
+	ociManifestType     = "application/vnd.oci.image.manifest.v1+json"
+
+	// How long the digest that a tag resolves to is cached for.
+	tagCacheTTL = 5 * time.Minute
+	// How long errors reaching the registry are cached for, so that a failing registry isn't queried on every
+	// reconcile.
+	errorCacheTTL = time.Minute
+)
+
+// Verifier verifies the signature of an image, using the keychain to authenticate to its registry. It returns the
+// reference of the image by the digest that was verified, which must be used in place of the image so that a tag
+// moved after verification isn't pulled.
+type Verifier interface {
+	Verify(ctx context.Context, image string, publicKey []byte, keychain imageregistry.Keychain) (string, error)
+}
+
+// CosignVerifier verifies keyed cosign signatures stored in the registry next to the image. Verification results
+// are cached per digest and public key; errors reaching the registry are cached for a short time only so that they
+// are retried.
+type CosignVerifier struct {
+	Client imageregistry.Client
+
+	mu      sync.Mutex
+	results map[string]result
+	tags    map[string]resolvedTag
+}
+
+type result struct {
+	err error
+	// expires is zero for results that don't expire.
+	expires time.Time
+}
+
+func (r result) expired() bool {
+	return !r.expires.IsZero() && time.Now().After(r.expires)
+}
+
+type resolvedTag struct {
+	digest  string
+	err     error
+	expires time.Time
+}
+
+// cosign signature payloads, in the "simple signing" format.
+type payload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+type signatureManifest struct {
+	Layers []struct {
+		Digest      string            `json:"digest"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"layers"`
+}
+
+// NewCosignVerifier returns a verifier that fetches signatures from registries with the credentials of the keychain
+// passed to Verify.
+func NewCosignVerifier() *CosignVerifier {
+	return &CosignVerifier{
+		results: map[string]result{},
+		tags:    map[string]resolvedTag{},
+	}
+}
+
+// Verify checks that the image has a cosign signature made with the PEM encoded public key, and returns the
+// reference of the image by the digest that was verified. Images referenced by tag are verified at the digest that
+// the tag currently resolves to.
+func (v *CosignVerifier) Verify(ctx context.Context, image string, publicKey []byte, keychain imageregistry.Keychain) (string, error) {
+	pub, err := parsePublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	ref, err := imageregistry.ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+	client := v.Client
+	client.Keychain = keychain
+	if ref.Digest == "" {
+		if ref.Digest, err = v.resolveTag(ctx, &client, image, ref); err != nil {
+			return "", err
+		}
+	}
+	pinned := fmt.Sprintf("%s/%s@%s", ref.Host, ref.Repository, ref.Digest)
+
+	key := fmt.Sprintf("%s|%x", pinned, sha256.Sum256(publicKey))
+	v.mu.Lock()
+	r, ok := v.results[key]
+	v.mu.Unlock()
+	if ok && !r.expired() {
+		return pinnedOnSuccess(pinned, r.err)
+	}
+
+	r = result{err: v.verify(ctx, &client, ref, pub)}
+	if r.err != nil && !errors.Is(r.err, ErrNoSignature) && !errors.Is(r.err, ErrInvalidSignature) {
+		r.expires = time.Now().Add(errorCacheTTL)
+	}
+	v.mu.Lock()
+	v.results[key] = r
+	v.mu.Unlock()
+	return pinnedOnSuccess(pinned, r.err)
+}
+
+func pinnedOnSuccess(pinned string, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	return pinned, nil
+}
+
+func (v *CosignVerifier) resolveTag(ctx context.Context, client *imageregistry.Client, image string, ref imageregistry.Reference) (string, error) {
+	v.mu.Lock()
+	t, ok := v.tags[image]
+	v.mu.Unlock()
+	if ok && time.Now().Before(t.expires) {
+		return t.digest, t.err
+	}
+
+	t = resolvedTag{expires: time.Now().Add(tagCacheTTL)}
+	m, err := client.Manifest(ctx, ref)
+	if err != nil {
+		t.err = fmt.Errorf("failed to resolve digest: %w", err)
+		t.expires = time.Now().Add(errorCacheTTL)
+	} else {
+		t.digest = m.Digest
+	}
+	v.mu.Lock()
+	v.tags[image] = t
+	v.mu.Unlock()
+	return t.digest, t.err
+}
+
+func (v *CosignVerifier) verify(ctx context.Context, client *imageregistry.Client, ref imageregistry.Reference, pub crypto.PublicKey) error {
+	// cosign stores the signatures of an image in the same repository, tagged after the digest of the image.
+	sigRef := ref
+	sigRef.Digest = ""
+	sigRef.Tag = strings.Replace(ref.Digest, ":", "-", 1) + ".sig"
+	m, err := client.Manifest(ctx, sigRef, ociManifestType)
+	if errors.Is(err, imageregistry.ErrNotFound) {
+		return ErrNoSignature
+	}
+	if err != nil {
+		return err
+	}
+
+	sm := signatureManifest{}
+	if err := json.Unmarshal(m.Body, &sm); err != nil {
+		return fmt.Errorf("failed to parse signature manifest: %w", err)
+	}
+	for _, layer := range sm.Layers {
+		sig, err := base64.StdEncoding.DecodeString(layer.Annotations[signatureAnnotation])
+		if err != nil || len(sig) == 0 {
+			continue
+		}
+		body, err := client.Blob(ctx, sigRef, layer.Digest)
+		if err != nil {
+			return err
+		}
+		if fmt.Sprintf("sha256:%x", sha256.Sum256(body)) != layer.Digest || !verifySignature(pub, body, sig) {
+			continue
+		}
+
+		// The signature is valid, make sure it was made for this image.
+		p := payload{}
+		if err := json.Unmarshal(body, &p); err == nil && p.Critical.Image.DockerManifestDigest == ref.Digest {
+			return nil
+		}
+	}
+	if len(sm.Layers) == 0 {
+		return ErrNoSignature
+	}
+	return ErrInvalidSignature
+}
+
+func parsePublicKey(b []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("public key is not PEM encoded")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	return pub, nil
+}
+
+func verifySignature(pub crypto.PublicKey, data, sig []byte) bool {
+	digest := sha256.Sum256(data)
+	switch k := pub.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest[:], sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, data, sig)
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package imagesignature
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tigera/operator/pkg/imageregistry"
+)
+
+// fakeRegistry serves images and their cosign signatures.
+type fakeRegistry struct {
+	// Manifests and blobs by "<repository>/<tag or digest>".
+	manifests map[string][]byte
+	blobs     map[string][]byte
+	requests  int
+	// If set, requests must be authenticated with this username and password.
+	username, password string
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests++
+	if f.username != "" {
+		if u, p, ok := r.BasicAuth(); !ok || u != f.username || p != f.password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="fake registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	if parts := strings.SplitN(path, "/manifests/", 2); len(parts) == 2 {
+		if m, ok := f.manifests[parts[0]+"/"+parts[1]]; ok {
+			w.Header().Set("Docker-Content-Digest", digestOf(m))
+			_, _ = w.Write(m)
+			return
+		}
+	}
+	if parts := strings.SplitN(path, "/blobs/", 2); len(parts) == 2 {
+		if b, ok := f.blobs[parts[1]]; ok {
+			_, _ = w.Write(b)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+// push adds the image with the tag to the registry and returns its digest.
+func (f *fakeRegistry) push(repo, tag string) string {
+	image := []byte(fmt.Sprintf(`{"schemaVersion": 2, "tag": %q}`, tag))
+	digest := digestOf(image)
+	f.manifests[repo+"/"+tag] = image
+	f.manifests[repo+"/"+digest] = image
+	return digest
+}
+
+// sign adds a signature of the image with the digest, made with the key for the signed digest.
+func (f *fakeRegistry) sign(repo, digest string, key *ecdsa.PrivateKey, signedDigest string) {
+	payload := []byte(fmt.Sprintf(`{"critical": {"identity": {"docker-reference": %q}, "image": {"docker-manifest-digest": %q}, "type": "cosign container image signature"}}`, repo, signedDigest))
+	h := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, h[:])
+	Expect(err).NotTo(HaveOccurred())
+	f.blobs[digestOf(payload)] = payload
+
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"layers": []map[string]interface{}{{
+			"mediaType":   "application/vnd.dev.cosign.simplesigning.v1+json",
+			"digest":      digestOf(payload),
+			"annotations": map[string]string{signatureAnnotation: base64.StdEncoding.EncodeToString(sig)},
+		}},
+	})
+	Expect(err).NotTo(HaveOccurred())
+	f.manifests[repo+"/"+strings.Replace(digest, ":", "-", 1)+".sig"] = manifest
+}
+
+func digestOf(b []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+}
+
+func publicKeyPEM(key *ecdsa.PrivateKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+var _ = Describe("cosign verification tests", func() {
+	var registry *fakeRegistry
+	var server *httptest.Server
+	var host string
+	var key, otherKey *ecdsa.PrivateKey
+	var v *CosignVerifier
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		registry = &fakeRegistry{manifests: map[string][]byte{}, blobs: map[string][]byte{}}
+		server = httptest.NewServer(registry)
+		u, err := url.Parse(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		host = u.Host
+
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		otherKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		v = NewCosignVerifier()
+		v.Client = imageregistry.Client{Insecure: true}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should verify images signed with the key", func() {
+		digest := registry.push("calico/node", "v1")
+		registry.sign("calico/node", digest, key, digest)
+
+		pinned := fmt.Sprintf("%s/calico/node@%s", host, digest)
+		Expect(v.Verify(ctx, pinned, publicKeyPEM(key), nil)).To(Equal(pinned))
+
+		By("pinning images referenced by tag to the verified digest")
+		Expect(v.Verify(ctx, fmt.Sprintf("%s/calico/node:v1", host), publicKeyPEM(key), nil)).To(Equal(pinned))
+	})
+
+	It("should reject unsigned images", func() {
+		digest := registry.push("calico/node", "v1")
+
+		_, err := v.Verify(ctx, fmt.Sprintf("%s/calico/node@%s", host, digest), publicKeyPEM(key), nil)
+		Expect(err).To(MatchError(ErrNoSignature))
+	})
+
+	It("should reject images signed with another key", func() {
+		digest := registry.push("calico/node", "v1")
+		registry.sign("calico/node", digest, otherKey, digest)
+
+		_, err := v.Verify(ctx, fmt.Sprintf("%s/calico/node@%s", host, digest), publicKeyPEM(key), nil)
+		Expect(err).To(MatchError(ErrInvalidSignature))
+	})
+
+	It("should reject signatures made for another image", func() {
+		digest := registry.push("calico/node", "v1")
+		registry.sign("calico/node", digest, key, "sha256:other")
+
+		_, err := v.Verify(ctx, fmt.Sprintf("%s/calico/node@%s", host, digest), publicKeyPEM(key), nil)
+		Expect(err).To(MatchError(ErrInvalidSignature))
+	})
+
+	It("should cache verification results per digest", func() {
+		digest := registry.push("calico/node", "v1")
+		registry.sign("calico/node", digest, key, digest)
+		image := fmt.Sprintf("%s/calico/node@%s", host, digest)
+
+		Expect(v.Verify(ctx, image, publicKeyPEM(key), nil)).To(Equal(image))
+		requests := registry.requests
+		Expect(v.Verify(ctx, image, publicKeyPEM(key), nil)).To(Equal(image))
+		Expect(registry.requests).To(Equal(requests))
+
+		By("verifying again with a different key")
+		_, err := v.Verify(ctx, image, publicKeyPEM(otherKey), nil)
+		Expect(err).To(MatchError(ErrInvalidSignature))
+		Expect(registry.requests).To(BeNumerically(">", requests))
+	})
+
+	It("should only cache errors reaching the registry for a short time", func() {
+		digest := registry.push("calico/node", "v1")
+		image := fmt.Sprintf("%s/calico/node@%s", host, digest)
+		server.Close()
+
+		_, err := v.Verify(ctx, image, publicKeyPEM(key), nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err).NotTo(MatchError(ErrNoSignature))
+		Expect(v.results).To(HaveLen(1))
+		for _, r := range v.results {
+			Expect(r.err).To(Equal(err))
+			Expect(r.expires).To(BeTemporally("~", time.Now().Add(errorCacheTTL), time.Second))
+		}
+		_, err2 := v.Verify(ctx, image, publicKeyPEM(key), nil)
+		Expect(err2).To(Equal(err))
+	})
+
+	It("should authenticate with the credentials of the keychain", func() {
+		digest := registry.push("calico/node", "v1")
+		registry.sign("calico/node", digest, key, digest)
+		registry.username, registry.password = "user", "pass"
+		image := fmt.Sprintf("%s/calico/node@%s", host, digest)
+
+		_, err := v.Verify(ctx, image, publicKeyPEM(key), nil)
+		Expect(err).To(MatchError("registry requires credentials"))
+
+		keychain := imageregistry.Keychain{}
+		auth := base64.StdEncoding.EncodeToString([]byte("user:pass"))
+		Expect(keychain.AddDockerConfig([]byte(fmt.Sprintf(`{"auths": {"http://%s/v2/": {"auth": %q}}}`, host, auth)), false)).To(Succeed())
+		v = NewCosignVerifier()
+		v.Client = imageregistry.Client{Insecure: true}
+		Expect(v.Verify(ctx, image, publicKeyPEM(key), keychain)).To(Equal(image))
+	})
+
+	It("should reject invalid public keys", func() {
+		_, err := v.Verify(ctx, host+"/calico/node:v1", []byte("not a key"), nil)
+		Expect(err).To(MatchError("public key is not PEM encoded"))
+	})
+})
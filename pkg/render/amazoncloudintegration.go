@@ -28,8 +28,8 @@ import (
 
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/components"
-	"github.com/tigera/operator/pkg/ptr"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 )
 
 const (
@@ -105,7 +105,7 @@ func ConvertSecretToCredential(s *corev1.Secret) (*AmazonCredential, error) {
 
 func (c *amazonCloudIntegrationComponent) Objects() ([]client.Object, []client.Object) {
 	objs := []client.Object{
-		CreateNamespace(AmazonCloudIntegrationNamespace, c.cfg.Installation.KubernetesProvider, PSSRestricted),
+		CreateNamespace(AmazonCloudIntegrationNamespace, c.cfg.Installation, PSSRestricted),
 	}
 	secrets := secret.CopyToNamespace(AmazonCloudIntegrationNamespace, c.cfg.PullSecrets...)
 	objs = append(objs, secret.ToRuntimeObjects(secrets...)...)
@@ -297,10 +297,7 @@ func (c *amazonCloudIntegrationComponent) container() corev1.Container {
 		Image: c.image,
 		Env:   env,
 		// Needed for permissions to write to the audit log
-		SecurityContext: &corev1.SecurityContext{
-			RunAsNonRoot:             ptr.BoolToPtr(true),
-			AllowPrivilegeEscalation: ptr.BoolToPtr(false),
-		},
+		SecurityContext: podsecuritycontext.NewBaseContext(),
 		ReadinessProbe: &corev1.Probe{
 			Handler: corev1.Handler{
 				Exec: &corev1.ExecAction{
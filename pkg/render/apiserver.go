@@ -15,16 +15,20 @@
 package render
 
 import (
+	"crypto/tls"
 	"fmt"
 	"strings"
 
+	"github.com/ghodss/yaml"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	apiregv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -32,6 +36,8 @@ import (
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/controller/k8sapi"
 	"github.com/tigera/operator/pkg/ptr"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	"github.com/tigera/operator/pkg/render/common/podaffinity"
 	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
@@ -46,8 +52,23 @@ const (
 
 	auditLogsVolumeName   = "tigera-audit-logs"
 	auditPolicyVolumeName = "tigera-audit-policy"
+
+	auditPolicyHashAnnotation = "hash.operator.tigera.io/audit-policy"
 )
 
+// defaultAuditResources are the projectcalico.org resources whose changes are audit logged by default.
+var defaultAuditResources = []string{
+	"globalnetworkpolicies",
+	"networkpolicies",
+	"stagedglobalnetworkpolicies",
+	"stagednetworkpolicies",
+	"stagedkubernetesnetworkpolicies",
+	"globalnetworksets",
+	"networksets",
+	"tiers",
+	"hostendpoints",
+}
+
 // The following functions are helpers for determining resource names based on
 // the configured product variant.
 func ProjectCalicoApiServerTLSSecretName(v operatorv1.ProductVariant) string {
@@ -71,6 +92,41 @@ func ApiServerServiceAccountName(v operatorv1.ProductVariant) string {
 	return "tigera-apiserver"
 }
 
+// ValidateAPIServer validates the configuration of the API server in the APIServer CR.
+func ValidateAPIServer(spec *operatorv1.APIServerSpec) error {
+	if b := spec.PodDisruptionBudget; b != nil && (b.MinAvailable == nil) == (b.MaxUnavailable == nil) {
+		return fmt.Errorf("podDisruptionBudget must specify one of minAvailable and maxUnavailable")
+	}
+	if t := spec.TLS; t != nil && len(t.CipherSuites) > 0 {
+		if t.MinVersion != nil && *t.MinVersion == operatorv1.TLSVersion13 {
+			return fmt.Errorf("tls.cipherSuites cannot be specified when tls.minVersion is %s", operatorv1.TLSVersion13)
+		}
+		supported := map[string]bool{}
+		for _, cs := range tls.CipherSuites() {
+			supported[cs.Name] = true
+		}
+		for _, name := range t.CipherSuites {
+			if !supported[name] {
+				return fmt.Errorf("cipher suite %s is not supported", name)
+			}
+		}
+	}
+	if a := spec.Audit; a != nil {
+		for _, r := range a.Resources {
+			if r == "" {
+				return fmt.Errorf("audit.resources must not contain empty names")
+			}
+			if errs := validation.IsDNS1123Label(r); len(errs) > 0 {
+				return fmt.Errorf("audit.resources %q is not a valid resource name: %s", r, strings.Join(errs, ", "))
+			}
+		}
+	}
+	if err := autoscaling.Validate(spec.PacketCaptureAutoscaling); err != nil {
+		return fmt.Errorf("packetCaptureAutoscaling is invalid: %w", err)
+	}
+	return nil
+}
+
 func APIServer(cfg *APIServerConfiguration) (Component, error) {
 	return &apiServerComponent{
 		cfg: cfg,
@@ -90,6 +146,10 @@ type APIServerConfiguration struct {
 	Openshift                   bool
 	TunnelCASecret              certificatemanagement.KeyPairInterface
 
+	// APIServer is the spec of the APIServer CR, which configures the deployment of the API server. The defaults are
+	// used if it is nil.
+	APIServer *operatorv1.APIServerSpec
+
 	// Whether or not the cluster supports pod security policies.
 	UsePSP bool
 }
@@ -174,6 +234,12 @@ func (c *apiServerComponent) Objects() ([]client.Object, []client.Object) {
 		c.apiServerService(),
 	)
 
+	// The PodDisruptionBudget configured in the APIServer CR takes precedence over the one that the component handler
+	// creates for the control plane deployments.
+	if c.spec().PodDisruptionBudget != nil {
+		namespacedObjects = append(namespacedObjects, c.apiServerPodDisruptionBudget())
+	}
+
 	// Add in certificates for API server TLS.
 	if !c.cfg.TLSKeyPair.UseCertificateManagement() {
 		globalObjects = append(globalObjects, c.apiServiceRegistration(c.cfg.TLSKeyPair.GetCertificatePEM()))
@@ -183,7 +249,7 @@ func (c *apiServerComponent) Objects() ([]client.Object, []client.Object) {
 
 	// Global enterprise-only objects.
 	globalEnterpriseObjects := []client.Object{
-		CreateNamespace(rmeta.APIServerNamespace(operatorv1.TigeraSecureEnterprise), c.cfg.Installation.KubernetesProvider, PSSPrivileged),
+		CreateNamespace(rmeta.APIServerNamespace(operatorv1.TigeraSecureEnterprise), c.cfg.Installation, PSSPrivileged),
 		c.tigeraCustomResourcesClusterRole(),
 		c.tigeraCustomResourcesClusterRoleBinding(),
 		c.tierGetterClusterRole(),
@@ -205,7 +271,7 @@ func (c *apiServerComponent) Objects() ([]client.Object, []client.Object) {
 
 	// Global OSS-only objects.
 	globalCalicoObjects := []client.Object{
-		CreateNamespace(rmeta.APIServerNamespace(operatorv1.Calico), c.cfg.Installation.KubernetesProvider, PSSPrivileged),
+		CreateNamespace(rmeta.APIServerNamespace(operatorv1.Calico), c.cfg.Installation, PSSPrivileged),
 	}
 
 	// Compile the final arrays based on the variant.
@@ -662,6 +728,27 @@ func (c *apiServerComponent) webhookReaderClusterRoleBinding() (client.Object, c
 		}
 }
 
+// apiServerPodDisruptionBudget creates the PodDisruptionBudget of the API server that is configured in the APIServer CR.
+//
+// Both Calico and Calico Enterprise, different namespaces.
+func (c *apiServerComponent) apiServerPodDisruptionBudget() *policyv1.PodDisruptionBudget {
+	pdb := &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{Kind: "PodDisruptionBudget", APIVersion: "policy/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ApiServerServiceAccountName(c.cfg.Installation.Variant),
+			Namespace: rmeta.APIServerNamespace(c.cfg.Installation.Variant),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"apiserver": "true"}},
+		},
+	}
+	if b := c.spec().PodDisruptionBudget; b != nil {
+		pdb.Spec.MinAvailable = b.MinAvailable
+		pdb.Spec.MaxUnavailable = b.MaxUnavailable
+	}
+	return pdb
+}
+
 // apiServerService creates a service backed by the API server and - for enterprise - query server.
 //
 // Both Calico and Calico Enterprise, different namespaces.
@@ -678,7 +765,7 @@ func (c *apiServerComponent) apiServerService() *corev1.Service {
 					Name:       "apiserver",
 					Port:       443,
 					Protocol:   corev1.ProtocolTCP,
-					TargetPort: intstr.FromInt(apiServerPort),
+					TargetPort: intstr.FromInt(int(c.port())),
 				},
 			},
 			Selector: map[string]string{
@@ -729,6 +816,10 @@ func (c *apiServerComponent) apiServerDeployment() *appsv1.Deployment {
 	if c.cfg.ManagementCluster != nil {
 		annotations[c.cfg.TunnelCASecret.HashAnnotationKey()] = c.cfg.TunnelCASecret.HashAnnotationValue()
 	}
+	if c.cfg.Installation.Variant == operatorv1.TigeraSecureEnterprise {
+		// The API server only reads the audit policy when it starts.
+		annotations[auditPolicyHashAnnotation] = rmeta.AnnotationHash(c.auditPolicy())
+	}
 
 	d := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
@@ -740,7 +831,7 @@ func (c *apiServerComponent) apiServerDeployment() *appsv1.Deployment {
 			},
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: c.cfg.Installation.ControlPlaneReplicas,
+			Replicas: c.replicas(),
 			Strategy: appsv1.DeploymentStrategy{
 				Type: appsv1.RecreateDeploymentStrategyType,
 			},
@@ -773,7 +864,7 @@ func (c *apiServerComponent) apiServerDeployment() *appsv1.Deployment {
 		},
 	}
 
-	if c.cfg.Installation.ControlPlaneReplicas != nil && *c.cfg.Installation.ControlPlaneReplicas > 1 {
+	if replicas := c.replicas(); replicas != nil && *replicas > 1 {
 		d.Spec.Template.Spec.Affinity = podaffinity.NewPodAntiAffinity(name, rmeta.APIServerNamespace(c.cfg.Installation.Variant))
 	}
 
@@ -784,16 +875,50 @@ func (c *apiServerComponent) apiServerDeployment() *appsv1.Deployment {
 	return d
 }
 
+// spec returns the spec of the APIServer CR, which is empty if there is none.
+func (c *apiServerComponent) spec() *operatorv1.APIServerSpec {
+	if c.cfg.APIServer == nil {
+		return &operatorv1.APIServerSpec{}
+	}
+	return c.cfg.APIServer
+}
+
+// replicas returns the replicas of the APIServer CR, or the control plane replicas of the Installation.
+func (c *apiServerComponent) replicas() *int32 {
+	if r := c.spec().Replicas; r != nil {
+		return r
+	}
+	return c.cfg.Installation.ControlPlaneReplicas
+}
+
+// port returns the port that the API server listens on.
+func (c *apiServerComponent) port() int32 {
+	if p := c.spec().Port; p != nil {
+		return *p
+	}
+	return apiServerPort
+}
+
+// hostNetwork returns whether the API server runs in the host network namespace. The APIServer CR cannot turn off the
+// host network when it is required.
 func (c *apiServerComponent) hostNetwork() bool {
-	hostNetwork := c.cfg.ForceHostNetwork
-	if c.cfg.Installation.KubernetesProvider == operatorv1.ProviderEKS &&
-		c.cfg.Installation.CNI.Type == operatorv1.PluginCalico {
-		// Workaround the fact that webhooks don't work for non-host-networked pods
-		// when in this networking mode on EKS, because the control plane nodes don't run
-		// Calico.
-		hostNetwork = true
-	}
-	return hostNetwork
+	if c.cfg.ForceHostNetwork || APIServerRequiresHostNetwork(c.cfg.Installation) {
+		return true
+	}
+	if h := c.spec().HostNetwork; h != nil {
+		return *h
+	}
+	return false
+}
+
+// APIServerRequiresHostNetwork returns whether the API server must run in the host network namespace for the
+// Kubernetes API server to reach it.
+func APIServerRequiresHostNetwork(installation *operatorv1.InstallationSpec) bool {
+	// Workaround the fact that webhooks don't work for non-host-networked pods
+	// when in this networking mode on EKS, because the control plane nodes don't run
+	// Calico.
+	return installation.KubernetesProvider == operatorv1.ProviderEKS &&
+		installation.CNI != nil && installation.CNI.Type == operatorv1.PluginCalico
 }
 
 // apiServerContainer creates the API server container.
@@ -841,16 +966,13 @@ func (c *apiServerComponent) apiServerContainer() corev1.Container {
 		Args:  c.startUpArgs(),
 		Env:   env,
 		// Needed for permissions to write to the audit log
-		SecurityContext: &corev1.SecurityContext{
-			Privileged: &isPrivileged,
-			RunAsUser:  ptr.Int64ToPtr(0),
-		},
+		SecurityContext: podsecuritycontext.NewRootContext(isPrivileged),
 		VolumeMounts: volumeMounts,
 		LivenessProbe: &corev1.Probe{
 			Handler: corev1.Handler{
 				HTTPGet: &corev1.HTTPGetAction{
 					Path:   "/version",
-					Port:   intstr.FromInt(apiServerPort),
+					Port:   intstr.FromInt(int(c.port())),
 					Scheme: corev1.URISchemeHTTPS,
 				},
 			},
@@ -870,17 +992,29 @@ func (c *apiServerComponent) apiServerContainer() corev1.Container {
 			FailureThreshold:    5,
 		},
 	}
+	if r := c.spec().Resources; r != nil {
+		apiServer.Resources = *r
+	}
 
 	return apiServer
 }
 
 func (c *apiServerComponent) startUpArgs() []string {
 	args := []string{
-		fmt.Sprintf("--secure-port=%d", apiServerPort),
+		fmt.Sprintf("--secure-port=%d", c.port()),
 		fmt.Sprintf("--tls-private-key-file=%s", c.cfg.TLSKeyPair.VolumeMountKeyFilePath()),
 		fmt.Sprintf("--tls-cert-file=%s", c.cfg.TLSKeyPair.VolumeMountCertificateFilePath()),
 	}
 
+	if tls := c.spec().TLS; tls != nil {
+		if tls.MinVersion != nil {
+			args = append(args, fmt.Sprintf("--tls-min-version=%s", *tls.MinVersion))
+		}
+		if len(tls.CipherSuites) > 0 {
+			args = append(args, fmt.Sprintf("--tls-cipher-suites=%s", strings.Join(tls.CipherSuites, ",")))
+		}
+	}
+
 	if c.cfg.Installation.Variant == operatorv1.TigeraSecureEnterprise {
 		args = append(args,
 			"--audit-policy-file=/etc/tigera/audit/policy.conf",
@@ -905,7 +1039,7 @@ func (c *apiServerComponent) startUpArgs() []string {
 func (c *apiServerComponent) queryServerContainer() corev1.Container {
 	env := []corev1.EnvVar{
 		// Set queryserver logging to "info"
-		{Name: "LOGLEVEL", Value: "info"},
+		{Name: "LOGLEVEL", Value: loglevel.Logrus(loglevel.Resolve(c.cfg.Installation, nil), "info")},
 		{Name: "DATASTORE_TYPE", Value: "kubernetes"},
 	}
 
@@ -933,6 +1067,9 @@ func (c *apiServerComponent) queryServerContainer() corev1.Container {
 		},
 		SecurityContext: podsecuritycontext.NewBaseContext(),
 	}
+	if r := c.spec().QueryServerResources; r != nil {
+		container.Resources = *r
+	}
 	return container
 }
 
@@ -1029,7 +1166,7 @@ func (c *apiServerComponent) apiServerPodSecurityPolicy() (client.Object, client
 // Calico only.
 func (c *apiServerComponent) networkPolicy() *netv1.NetworkPolicy {
 	tcp := corev1.ProtocolTCP
-	p := intstr.FromInt(5443)
+	p := intstr.FromInt(int(c.port()))
 	return &netv1.NetworkPolicy{
 		TypeMeta:   metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "networking.k8s.io/v1"},
 		ObjectMeta: metav1.ObjectMeta{Name: "allow-apiserver", Namespace: rmeta.APIServerNamespace(c.cfg.Installation.Variant)},
@@ -1616,35 +1753,60 @@ func (c *apiServerComponent) uiSettingsPassthruClusterRolebinding() *rbacv1.Clus
 	}
 }
 
+// auditPolicy returns the audit policy of the API server for changes to the projectcalico.org resources that are
+// configured in the APIServer CR.
+func (c *apiServerComponent) auditPolicy() string {
+	level := operatorv1.APIServerAuditLevelRequestResponse
+	resources := defaultAuditResources
+	if a := c.spec().Audit; a != nil {
+		if a.Level != nil {
+			level = *a.Level
+		}
+		if len(a.Resources) > 0 {
+			resources = a.Resources
+		}
+	}
+
+	policy := auditPolicyConfig{
+		APIVersion: "audit.k8s.io/v1beta1",
+		Kind:       "Policy",
+		Rules: []auditPolicyRule{{
+			Level:      level,
+			OmitStages: []string{"RequestReceived"},
+			Verbs:      []string{"create", "patch", "update", "delete"},
+			Resources:  []auditGroupResources{{Group: "projectcalico.org", Resources: resources}},
+		}},
+	}
+	// Marshalling the policy does not fail, it only holds strings.
+	b, _ := yaml.Marshal(policy)
+	return string(b)
+}
+
+// auditPolicyConfig is the subset of the audit.k8s.io Policy that the operator renders. The k8s.io/apiserver types are not
+// a dependency of the operator.
+type auditPolicyConfig struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Rules      []auditPolicyRule `json:"rules"`
+}
+
+type auditPolicyRule struct {
+	Level      operatorv1.APIServerAuditLevel `json:"level"`
+	OmitStages []string                       `json:"omitStages"`
+	Verbs      []string                       `json:"verbs"`
+	Resources  []auditGroupResources          `json:"resources"`
+}
+
+type auditGroupResources struct {
+	Group     string   `json:"group"`
+	Resources []string `json:"resources"`
+}
+
 // auditPolicyConfigMap returns a configmap with contents to configure audit logging for
 // projectcalico.org/v3 APIs.
 //
 // Calico Enterprise only
 func (c *apiServerComponent) auditPolicyConfigMap() *corev1.ConfigMap {
-	const defaultAuditPolicy = `apiVersion: audit.k8s.io/v1beta1
-kind: Policy
-rules:
-- level: RequestResponse
-  omitStages:
-  - RequestReceived
-  verbs:
-  - create
-  - patch
-  - update
-  - delete
-  resources:
-  - group: projectcalico.org
-    resources:
-    - globalnetworkpolicies
-    - networkpolicies
-    - stagedglobalnetworkpolicies
-    - stagednetworkpolicies
-    - stagedkubernetesnetworkpolicies
-    - globalnetworksets
-    - networksets
-    - tiers
-    - hostendpoints`
-
 	return &corev1.ConfigMap{
 		TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
 		ObjectMeta: metav1.ObjectMeta{
@@ -1653,7 +1815,7 @@ rules:
 			Name:      auditPolicyVolumeName,
 		},
 		Data: map[string]string{
-			"config": defaultAuditPolicy,
+			"config": c.auditPolicy(),
 		},
 	}
 }
@@ -47,9 +47,12 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	apiregv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -851,6 +854,98 @@ var _ = Describe("API server rendering tests (Calico Enterprise)", func() {
 		Expect(deploy.Spec.Template.Spec.Affinity).NotTo(BeNil())
 		Expect(deploy.Spec.Template.Spec.Affinity).To(Equal(podaffinity.NewPodAntiAffinity("tigera-apiserver", "tigera-system")))
 	})
+
+	It("should render the configuration of the APIServer CR", func() {
+		var apiServerReplicas int32 = 3
+		var port int32 = 6443
+		hostNetwork := true
+		minVersion := operatorv1.TLSVersion12
+		auditLevel := operatorv1.APIServerAuditLevelMetadata
+		maxUnavailable := intstr.FromInt(1)
+		resources := &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+		}
+		queryServerResources := &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+		}
+		cfg.APIServer = &operatorv1.APIServerSpec{
+			Replicas:             &apiServerReplicas,
+			Resources:            resources,
+			QueryServerResources: queryServerResources,
+			PodDisruptionBudget:  &operatorv1.PodDisruptionBudgetSpec{MaxUnavailable: &maxUnavailable},
+			TLS: &operatorv1.APIServerTLS{
+				MinVersion:   &minVersion,
+				CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+			},
+			HostNetwork: &hostNetwork,
+			Port:        &port,
+			Audit: &operatorv1.APIServerAudit{
+				Level:     &auditLevel,
+				Resources: []string{"tiers", "globalnetworkpolicies"},
+			},
+		}
+		component, err := render.APIServer(cfg)
+		Expect(err).To(BeNil(), "Expected APIServer to create successfully %s", err)
+		objs, toDelete := component.Objects()
+
+		deploy := rtest.GetResource(objs, "tigera-apiserver", "tigera-system", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(*deploy.Spec.Replicas).To(Equal(apiServerReplicas))
+		Expect(deploy.Spec.Template.Spec.HostNetwork).To(BeTrue())
+		Expect(deploy.Spec.Template.Spec.DNSPolicy).To(Equal(corev1.DNSClusterFirstWithHostNet))
+		Expect(deploy.Spec.Template.Annotations).To(HaveKey("hash.operator.tigera.io/audit-policy"))
+
+		apiServer := rtest.GetContainer(deploy.Spec.Template.Spec.Containers, "tigera-apiserver")
+		Expect(apiServer.Resources).To(Equal(*resources))
+		Expect(apiServer.Args).To(ContainElements(
+			"--secure-port=6443",
+			"--tls-min-version=VersionTLS12",
+			"--tls-cipher-suites=TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+		))
+		Expect(apiServer.LivenessProbe.HTTPGet.Port.IntVal).To(Equal(port))
+		Expect(rtest.GetContainer(deploy.Spec.Template.Spec.Containers, "tigera-queryserver").Resources).To(Equal(*queryServerResources))
+
+		svc := rtest.GetResource(objs, "tigera-api", "tigera-system", "", "v1", "Service").(*corev1.Service)
+		Expect(svc.Spec.Ports[0].Port).To(BeEquivalentTo(443))
+		Expect(svc.Spec.Ports[0].TargetPort.IntVal).To(Equal(port))
+
+		pdb := rtest.GetResource(objs, "tigera-apiserver", "tigera-system", "policy", "v1", "PodDisruptionBudget").(*policyv1.PodDisruptionBudget)
+		Expect(*pdb.Spec.MaxUnavailable).To(Equal(maxUnavailable))
+		Expect(pdb.Spec.MinAvailable).To(BeNil())
+		Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"apiserver": "true"}))
+		Expect(rtest.GetResource(toDelete, "tigera-apiserver", "tigera-system", "policy", "v1", "PodDisruptionBudget")).To(BeNil())
+
+		cm := rtest.GetResource(objs, "tigera-audit-policy", "tigera-system", "", "v1", "ConfigMap").(*corev1.ConfigMap)
+		Expect(cm.Data["config"]).To(Equal(`apiVersion: audit.k8s.io/v1beta1
+kind: Policy
+rules:
+- level: Metadata
+  omitStages:
+  - RequestReceived
+  resources:
+  - group: projectcalico.org
+    resources:
+    - tiers
+    - globalnetworkpolicies
+  verbs:
+  - create
+  - patch
+  - update
+  - delete
+`))
+	})
+
+	It("should leave the PodDisruptionBudget to the component handler when it is not configured", func() {
+		component, err := render.APIServer(cfg)
+		Expect(err).To(BeNil(), "Expected APIServer to create successfully %s", err)
+		resources, toDelete := component.Objects()
+
+		Expect(rtest.GetResource(resources, "tigera-apiserver", "tigera-system", "policy", "v1", "PodDisruptionBudget")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, "tigera-apiserver", "tigera-system", "policy", "v1", "PodDisruptionBudget")).To(BeNil())
+
+		cm := rtest.GetResource(resources, "tigera-audit-policy", "tigera-system", "", "v1", "ConfigMap").(*corev1.ConfigMap)
+		Expect(cm.Data["config"]).To(HavePrefix("apiVersion: audit.k8s.io/v1beta1\nkind: Policy\nrules:\n- level: RequestResponse\n"))
+		Expect(cm.Data["config"]).To(ContainSubstring("\n    - tiers\n    - hostendpoints\n"))
+	})
 })
 
 func verifyAPIService(service *apiregv1.APIService, enterprise bool, clusterDomain string) {
@@ -1432,4 +1527,82 @@ var _ = Describe("API server rendering tests (Calico)", func() {
 		Expect(deploy.Spec.Template.Spec.Affinity).NotTo(BeNil())
 		Expect(deploy.Spec.Template.Spec.Affinity).To(Equal(podaffinity.NewPodAntiAffinity("calico-apiserver", "calico-apiserver")))
 	})
+
+	It("should keep the host network when the platform requires it", func() {
+		hostNetwork := false
+		cfg.Installation.KubernetesProvider = operatorv1.ProviderEKS
+		cfg.Installation.CNI = &operatorv1.CNISpec{Type: operatorv1.PluginCalico}
+		cfg.APIServer = &operatorv1.APIServerSpec{HostNetwork: &hostNetwork}
+
+		component, err := render.APIServer(cfg)
+		Expect(err).To(BeNil(), "Expected APIServer to create successfully %s", err)
+		resources, _ := component.Objects()
+
+		deploy := rtest.GetResource(resources, "calico-apiserver", "calico-apiserver", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.HostNetwork).To(BeTrue())
+	})
+
+	It("should not use the host network when it is disabled in the APIServer CR", func() {
+		hostNetwork := false
+		var port int32 = 7443
+		minAvailable := intstr.FromString("50%")
+		cfg.APIServer = &operatorv1.APIServerSpec{
+			HostNetwork:         &hostNetwork,
+			Port:                &port,
+			PodDisruptionBudget: &operatorv1.PodDisruptionBudgetSpec{MinAvailable: &minAvailable},
+		}
+
+		component, err := render.APIServer(cfg)
+		Expect(err).To(BeNil(), "Expected APIServer to create successfully %s", err)
+		resources, _ := component.Objects()
+
+		deploy := rtest.GetResource(resources, "calico-apiserver", "calico-apiserver", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.HostNetwork).To(BeFalse())
+		Expect(deploy.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--secure-port=7443"))
+
+		np := rtest.GetResource(resources, "allow-apiserver", "calico-apiserver", "networking.k8s.io", "v1", "NetworkPolicy").(*netv1.NetworkPolicy)
+		Expect(np.Spec.Ingress[0].Ports[0].Port.IntVal).To(Equal(port))
+
+		pdb := rtest.GetResource(resources, "calico-apiserver", "calico-apiserver", "policy", "v1", "PodDisruptionBudget").(*policyv1.PodDisruptionBudget)
+		Expect(*pdb.Spec.MinAvailable).To(Equal(minAvailable))
+	})
+})
+
+var _ = Describe("APIServer CR validation", func() {
+	one := intstr.FromInt(1)
+	tls13 := operatorv1.TLSVersion13
+	three := int32(3)
+	zero := int32(0)
+
+	DescribeTable("validates the APIServer CR", func(spec operatorv1.APIServerSpec, expectedErr string) {
+		err := render.ValidateAPIServer(&spec)
+		if expectedErr == "" {
+			Expect(err).NotTo(HaveOccurred())
+		} else {
+			Expect(err).To(MatchError(expectedErr))
+		}
+	},
+		Entry("empty", operatorv1.APIServerSpec{}, ""),
+		Entry("pdb with maxUnavailable", operatorv1.APIServerSpec{PodDisruptionBudget: &operatorv1.PodDisruptionBudgetSpec{MaxUnavailable: &one}}, ""),
+		Entry("pdb without a budget", operatorv1.APIServerSpec{PodDisruptionBudget: &operatorv1.PodDisruptionBudgetSpec{}},
+			"podDisruptionBudget must specify one of minAvailable and maxUnavailable"),
+		Entry("pdb with both budgets", operatorv1.APIServerSpec{PodDisruptionBudget: &operatorv1.PodDisruptionBudgetSpec{MinAvailable: &one, MaxUnavailable: &one}},
+			"podDisruptionBudget must specify one of minAvailable and maxUnavailable"),
+		Entry("supported cipher suite", operatorv1.APIServerSpec{TLS: &operatorv1.APIServerTLS{CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}}}, ""),
+		Entry("insecure cipher suite", operatorv1.APIServerSpec{TLS: &operatorv1.APIServerTLS{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}},
+			"cipher suite TLS_RSA_WITH_RC4_128_SHA is not supported"),
+		Entry("cipher suites with TLS 1.3", operatorv1.APIServerSpec{TLS: &operatorv1.APIServerTLS{MinVersion: &tls13, CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}}},
+			"tls.cipherSuites cannot be specified when tls.minVersion is VersionTLS13"),
+		Entry("empty audit resource", operatorv1.APIServerSpec{Audit: &operatorv1.APIServerAudit{Resources: []string{""}}},
+			"audit.resources must not contain empty names"),
+		Entry("invalid audit resource", operatorv1.APIServerSpec{Audit: &operatorv1.APIServerAudit{Resources: []string{"tiers\n- group: \"\""}}},
+			`audit.resources "tiers\n- group: \"\"" is not a valid resource name: a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`),
+		Entry("packet capture autoscaling", operatorv1.APIServerSpec{PacketCaptureAutoscaling: &operatorv1.Autoscaling{MinReplicas: &three, MaxReplicas: 3}}, ""),
+		Entry("packet capture autoscaling without maxReplicas", operatorv1.APIServerSpec{PacketCaptureAutoscaling: &operatorv1.Autoscaling{}},
+			"packetCaptureAutoscaling is invalid: maxReplicas must be at least 1"),
+		Entry("packet capture autoscaling with minReplicas above maxReplicas", operatorv1.APIServerSpec{PacketCaptureAutoscaling: &operatorv1.Autoscaling{MinReplicas: &three, MaxReplicas: 2}},
+			"packetCaptureAutoscaling is invalid: minReplicas 3 is greater than maxReplicas 2"),
+		Entry("packet capture autoscaling without a CPU target", operatorv1.APIServerSpec{PacketCaptureAutoscaling: &operatorv1.Autoscaling{MaxReplicas: 2, TargetCPUUtilizationPercentage: &zero}},
+			"packetCaptureAutoscaling is invalid: targetCPUUtilizationPercentage must be at least 1"),
+	)
 })
@@ -32,6 +32,7 @@ import (
 	"github.com/tigera/operator/pkg/ptr"
 	"github.com/tigera/operator/pkg/render"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/secret"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -270,11 +271,7 @@ func (c *component) containers() []corev1.Container {
 				{Name: CalicoLogsVolumeName, MountPath: "/var/log/calico"},
 				{Name: ModSecurityRulesetVolumeName, MountPath: "/etc/modsecurity-ruleset", ReadOnly: true},
 			},
-			SecurityContext: &corev1.SecurityContext{
-				Privileged: ptr.BoolToPtr(true),
-				RunAsUser:  ptr.Int64ToPtr(0),
-				RunAsGroup: ptr.Int64ToPtr(0),
-			},
+			SecurityContext: podsecuritycontext.NewRootContext(true),
 		}
 		containers = append(containers, dikastes)
 	}
@@ -0,0 +1,127 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package autoscaling renders the HorizontalPodAutoscalers of the components that can be scaled horizontally.
+package autoscaling
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+const (
+	// HorizontalPodAutoscalerAnnotation is set on deployments whose replicas are scaled by a HorizontalPodAutoscaler.
+	// Its value is the name of the HorizontalPodAutoscaler. The operator leaves the replicas of those deployments to
+	// the autoscaler.
+	HorizontalPodAutoscalerAnnotation = "operator.tigera.io/horizontal-pod-autoscaler"
+
+	defaultTargetCPUUtilizationPercentage int32 = 80
+)
+
+// Objects returns the HorizontalPodAutoscaler of a deployment to create when autoscaling is configured, and to delete
+// when it is not. A deployment that is autoscaled is annotated and starts with the minimum number of replicas.
+func Objects(d *appsv1.Deployment, spec *operatorv1.Autoscaling) (toCreate, toDelete []client.Object) {
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{Kind: "HorizontalPodAutoscaler", APIVersion: "autoscaling/v2beta2"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      d.Name,
+			Namespace: d.Namespace,
+		},
+	}
+	if spec == nil {
+		return nil, []client.Object{hpa}
+	}
+
+	minReplicas := int32(1)
+	if spec.MinReplicas != nil {
+		minReplicas = *spec.MinReplicas
+	}
+	hpa.Spec = autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+			Kind:       "Deployment",
+			Name:       d.Name,
+			APIVersion: "apps/v1",
+		},
+		MinReplicas: &minReplicas,
+		MaxReplicas: spec.MaxReplicas,
+	}
+	cpu, memory := spec.TargetCPUUtilizationPercentage, spec.TargetMemoryUtilizationPercentage
+	if cpu == nil && memory == nil {
+		target := defaultTargetCPUUtilizationPercentage
+		cpu = &target
+	}
+	if cpu != nil {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, utilizationMetric(corev1.ResourceCPU, *cpu))
+	}
+	if memory != nil {
+		hpa.Spec.Metrics = append(hpa.Spec.Metrics, utilizationMetric(corev1.ResourceMemory, *memory))
+	}
+
+	if d.Annotations == nil {
+		d.Annotations = map[string]string{}
+	}
+	d.Annotations[HorizontalPodAutoscalerAnnotation] = hpa.Name
+	d.Spec.Replicas = &minReplicas
+	return []client.Object{hpa}, nil
+}
+
+func utilizationMetric(name corev1.ResourceName, percentage int32) autoscalingv2beta2.MetricSpec {
+	return autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.ResourceMetricSourceType,
+		Resource: &autoscalingv2beta2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2beta2.MetricTarget{
+				Type:               autoscalingv2beta2.UtilizationMetricType,
+				AverageUtilization: &percentage,
+			},
+		},
+	}
+}
+
+// Validate validates the autoscaling of a component.
+func Validate(spec *operatorv1.Autoscaling) error {
+	if spec == nil {
+		return nil
+	}
+	if spec.MaxReplicas < 1 {
+		return fmt.Errorf("maxReplicas must be at least 1")
+	}
+	if spec.MinReplicas != nil {
+		if *spec.MinReplicas < 1 {
+			return fmt.Errorf("minReplicas must be at least 1")
+		}
+		if *spec.MinReplicas > spec.MaxReplicas {
+			return fmt.Errorf("minReplicas %d is greater than maxReplicas %d", *spec.MinReplicas, spec.MaxReplicas)
+		}
+	}
+	for _, t := range []struct {
+		name  string
+		value *int32
+	}{
+		{"targetCPUUtilizationPercentage", spec.TargetCPUUtilizationPercentage},
+		{"targetMemoryUtilizationPercentage", spec.TargetMemoryUtilizationPercentage},
+	} {
+		if t.value != nil && *t.value < 1 {
+			return fmt.Errorf("%s must be at least 1", t.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package loglevel resolves the log level of the components from the Installation and their custom resources, and
+// translates it into the formats that the components expect.
+package loglevel
+
+import (
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+// Resolve returns the log level of a component: Debug while temporary debug logging is active, otherwise the level
+// of the custom resource of the component, otherwise that of the Installation. It returns an empty level when none is
+// configured, in which case the component logs at its default level.
+func Resolve(installation *operatorv1.InstallationSpec, override *operatorv1.LogLevel) operatorv1.LogLevel {
+	var logging *operatorv1.Logging
+	if installation != nil {
+		logging = installation.Logging
+	}
+	if logging != nil && logging.TemporaryDebug != nil {
+		// The Installation controller removes the temporary debug logging once it has expired.
+		return operatorv1.LogLevelDebug
+	}
+	if override != nil {
+		return *override
+	}
+	if logging != nil && logging.Level != nil {
+		return *logging.Level
+	}
+	return ""
+}
+
+// Logrus returns the level in the format of the components that parse it with logrus, and of Typha, or def if the
+// level is empty.
+func Logrus(level operatorv1.LogLevel, def string) string {
+	switch level {
+	case operatorv1.LogLevelError:
+		return "error"
+	case operatorv1.LogLevelWarn:
+		return "warning"
+	case operatorv1.LogLevelInfo:
+		return "info"
+	case operatorv1.LogLevelDebug:
+		return "debug"
+	}
+	return def
+}
+
+// Fluentd returns the level in the format of fluentd, or def if the level is empty.
+func Fluentd(level operatorv1.LogLevel, def string) string {
+	if level == operatorv1.LogLevelWarn {
+		return "warn"
+	}
+	return Logrus(level, def)
+}
@@ -0,0 +1,354 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	"github.com/tigera/api/pkg/lib/numorstring"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tigera/operator/pkg/common"
+)
+
+const (
+	// TigeraComponentTierName is the tier that holds the policies for the components managed by the operator.
+	TigeraComponentTierName = "allow-tigera"
+	// TigeraComponentPolicyPrefix is the prefix required on the names of all policies in the allow-tigera tier.
+	TigeraComponentPolicyPrefix = TigeraComponentTierName + "."
+	// DefaultDenyPolicyName is the name of the policy that denies all traffic in a namespace that has not been allowed
+	// by the other policies in the allow-tigera tier.
+	DefaultDenyPolicyName = TigeraComponentPolicyPrefix + "default-deny"
+)
+
+// tierOrder places the allow-tigera tier ahead of user defined tiers.
+var tierOrder = 100.0
+
+var (
+	TCPProtocol = numorstring.ProtocolFromString(numorstring.ProtocolTCP)
+	UDPProtocol = numorstring.ProtocolFromString(numorstring.ProtocolUDP)
+)
+
+// KubeAPIServerEntityRule matches the kubernetes service in the default namespace.
+var KubeAPIServerEntityRule = v3.EntityRule{
+	Services: &v3.ServiceMatch{
+		Namespace: "default",
+		Name:      "kubernetes",
+	},
+}
+
+// Entity rules for the operator managed endpoints that many components talk to. They are defined here rather than
+// next to the components that render them so that any renderer can use them without an import cycle.
+var (
+	ElasticsearchEntityRule = v3.EntityRule{
+		NamespaceSelector: "projectcalico.org/name == 'tigera-elasticsearch'",
+		Selector:          "elasticsearch.k8s.elastic.co/cluster-name == 'tigera-secure'",
+		Ports:             Ports(9200),
+	}
+	ESGatewayEntityRule = CreateEntityRule("tigera-elasticsearch", "tigera-secure-es-gateway", 5554)
+	KibanaEntityRule    = v3.EntityRule{
+		NamespaceSelector: "projectcalico.org/name == 'tigera-kibana'",
+		Selector:          "kibana.k8s.elastic.co/name == 'tigera-secure'",
+		Ports:             Ports(5601),
+	}
+	DexEntityRule              = CreateEntityRule("tigera-dex", "tigera-dex", 5556)
+	ComplianceServerEntityRule = CreateEntityRule("tigera-compliance", "compliance-server", 5443)
+	PacketCaptureEntityRule    = CreateEntityRule("tigera-packetcapture", "tigera-packetcapture", 8444)
+	GuardianEntityRule         = CreateEntityRule("tigera-guardian", "tigera-guardian", 8080)
+	PrometheusEntityRule       = v3.EntityRule{
+		NamespaceSelector: PrometheusSourceEntityRule.NamespaceSelector,
+		Selector:          PrometheusSourceEntityRule.Selector,
+		Ports:             Ports(9095),
+	}
+	PrometheusSourceEntityRule = v3.EntityRule{
+		NamespaceSelector: fmt.Sprintf("projectcalico.org/name == '%s'", common.TigeraPrometheusNamespace),
+		Selector:          "prometheus == 'calico-node-prometheus'",
+	}
+	ManagerSourceEntityRule = CreateSourceEntityRule("tigera-manager", "tigera-manager")
+)
+
+// OperatorSourceEntityRule returns an entity rule matching the pods of the operator.
+func OperatorSourceEntityRule() v3.EntityRule {
+	return CreateSourceEntityRule(common.OperatorNamespace(), "tigera-operator")
+}
+
+// State describes what a component should do with its allow-tigera policies.
+type State int
+
+const (
+	// StateUnavailable means the allow-tigera tier can't be used, so policies are neither created nor removed.
+	StateUnavailable State = iota
+	// StateEnabled means the component's policies should be created.
+	StateEnabled
+	// StateDisabled means the tier is available but policies have been turned off, so any existing policies
+	// for the component are removed.
+	StateDisabled
+)
+
+// Split returns the given policies as objects to create or objects to delete, depending on the state.
+func (s State) Split(policies ...client.Object) (toCreate, toDelete []client.Object) {
+	switch s {
+	case StateEnabled:
+		return policies, nil
+	case StateDisabled:
+		return nil, policies
+	}
+	return nil, nil
+}
+
+// AllowTigeraTier returns the tier that holds the policies for operator managed components.
+func AllowTigeraTier() *v3.Tier {
+	return &v3.Tier{
+		TypeMeta:   metav1.TypeMeta{Kind: "Tier", APIVersion: "projectcalico.org/v3"},
+		ObjectMeta: metav1.ObjectMeta{Name: TigeraComponentTierName},
+		Spec: v3.TierSpec{
+			Order: &tierOrder,
+		},
+	}
+}
+
+// AllowTigeraDefaultDeny returns a policy that denies all traffic to and from the pods in the namespace that is not
+// allowed by another policy in the allow-tigera tier.
+func AllowTigeraDefaultDeny(namespace string) *v3.NetworkPolicy {
+	return &v3.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DefaultDenyPolicyName,
+			Namespace: namespace,
+		},
+		Spec: v3.NetworkPolicySpec{
+			Tier:     TigeraComponentTierName,
+			Selector: "all()",
+			Types:    []v3.PolicyType{v3.PolicyTypeIngress, v3.PolicyTypeEgress},
+		},
+	}
+}
+
+// AllowTigeraPolicy returns a policy in the allow-tigera tier that applies to the pods labelled with k8s-app=app in
+// the namespace. Traffic that doesn't match the ingress or egress rules is passed on to the default-deny policy.
+func AllowTigeraPolicy(app, namespace string, ingress, egress []v3.Rule) *v3.NetworkPolicy {
+	return AllowTigeraPolicyForSelector(app, namespace, fmt.Sprintf("k8s-app == '%s'", app), ingress, egress)
+}
+
+// AllowTigeraPolicyForSelector is like AllowTigeraPolicy but applies to the pods matching the given selector.
+func AllowTigeraPolicyForSelector(name, namespace, selector string, ingress, egress []v3.Rule) *v3.NetworkPolicy {
+	order := 1.0
+	return &v3.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TigeraComponentPolicyPrefix + name,
+			Namespace: namespace,
+		},
+		Spec: v3.NetworkPolicySpec{
+			Tier:     TigeraComponentTierName,
+			Order:    &order,
+			Selector: selector,
+			Types:    []v3.PolicyType{v3.PolicyTypeIngress, v3.PolicyTypeEgress},
+			Ingress:  ingress,
+			Egress:   egress,
+		},
+	}
+}
+
+// ServiceIngressRules returns rules allowing traffic from the source to the ports the service forwards to. The rules
+// apply to the pods behind the service, so the target ports are used where set.
+func ServiceIngressRules(svc *corev1.Service, source v3.EntityRule) []v3.Rule {
+	var tcp, udp []numorstring.Port
+	seen := map[string]bool{}
+	for _, p := range svc.Spec.Ports {
+		port := numorstring.SinglePort(uint16(p.Port))
+		switch p.TargetPort.Type {
+		case intstr.Int:
+			if p.TargetPort.IntVal != 0 {
+				port = numorstring.SinglePort(uint16(p.TargetPort.IntVal))
+			}
+		case intstr.String:
+			if p.TargetPort.StrVal != "" {
+				port = numorstring.NamedPort(p.TargetPort.StrVal)
+			}
+		}
+		key := string(p.Protocol) + "/" + port.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if p.Protocol == corev1.ProtocolUDP {
+			udp = append(udp, port)
+		} else {
+			tcp = append(tcp, port)
+		}
+	}
+
+	var rules []v3.Rule
+	if len(tcp) > 0 {
+		rules = append(rules, allowRule(TCPProtocol, source, v3.EntityRule{Ports: tcp}))
+	}
+	if len(udp) > 0 {
+		rules = append(rules, allowRule(UDPProtocol, source, v3.EntityRule{Ports: udp}))
+	}
+	return rules
+}
+
+// CreateEntityRule returns an entity rule matching the pods of the app in the namespace on the given ports.
+func CreateEntityRule(namespace, app string, ports ...uint16) v3.EntityRule {
+	rule := CreateSourceEntityRule(namespace, app)
+	rule.Ports = Ports(ports...)
+	return rule
+}
+
+// CreateSourceEntityRule returns an entity rule matching the pods of the app in the namespace.
+func CreateSourceEntityRule(namespace, app string) v3.EntityRule {
+	return v3.EntityRule{
+		Selector:          fmt.Sprintf("k8s-app == '%s'", app),
+		NamespaceSelector: fmt.Sprintf("projectcalico.org/name == '%s'", namespace),
+	}
+}
+
+// Ports converts the port numbers to a port list for an entity rule.
+func Ports(ports ...uint16) []numorstring.Port {
+	var p []numorstring.Port
+	for _, port := range ports {
+		p = append(p, numorstring.SinglePort(port))
+	}
+	return p
+}
+
+// AllowTCPRule returns a rule allowing TCP traffic to the destination.
+func AllowTCPRule(destination v3.EntityRule) v3.Rule {
+	return allowRule(TCPProtocol, v3.EntityRule{}, destination)
+}
+
+// AllowTCPFromRule returns a rule allowing TCP traffic from the source to the given ports.
+func AllowTCPFromRule(source v3.EntityRule, ports ...uint16) v3.Rule {
+	return allowRule(TCPProtocol, source, v3.EntityRule{Ports: Ports(ports...)})
+}
+
+// AllowKubeAPIServerRule returns an egress rule allowing traffic to the Kubernetes API server.
+func AllowKubeAPIServerRule() v3.Rule {
+	return AllowTCPRule(KubeAPIServerEntityRule)
+}
+
+// AllowDNSRules returns the egress rules pods need to resolve names using the cluster DNS service.
+func AllowDNSRules(openshift bool) []v3.Rule {
+	dest := v3.EntityRule{
+		NamespaceSelector: "projectcalico.org/name == 'kube-system'",
+		Selector:          "k8s-app == 'kube-dns'",
+		Ports:             Ports(53),
+	}
+	if openshift {
+		dest = v3.EntityRule{
+			NamespaceSelector: "projectcalico.org/name == 'openshift-dns'",
+			Selector:          "dns.operator.openshift.io/daemonset-dns == 'default'",
+			Ports:             Ports(5353),
+		}
+	}
+	return []v3.Rule{
+		allowRule(UDPProtocol, v3.EntityRule{}, dest),
+		allowRule(TCPProtocol, v3.EntityRule{}, dest),
+	}
+}
+
+// AllowAddressRule returns an egress rule allowing traffic to an address of the form host:port or a URL. URLs with
+// the udp scheme, as used by syslog endpoints, are allowed over UDP and all other addresses over TCP. IP addresses
+// are matched as nets, host names of in-cluster services (<service>.<namespace>.svc[.<cluster domain>]) as the
+// service and other host names as domains. If the address can't be parsed, traffic to any destination is allowed on
+// the port so that a misparsed address doesn't cut the component off.
+func AllowAddressRule(address string) v3.Rule {
+	protocol, host, port := splitAddress(address)
+	if svc := serviceMatch(host); svc != nil {
+		// Traffic to a service is load balanced to its endpoints, which domains don't match. Egress rules that match
+		// services can't have ports, the ports of the service are used instead.
+		return allowRule(protocol, v3.EntityRule{}, v3.EntityRule{Services: svc})
+	}
+	dest := v3.EntityRule{}
+	if port != 0 {
+		dest.Ports = Ports(port)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.To4() != nil {
+			dest.Nets = []string{ip.String() + "/32"}
+		} else {
+			dest.Nets = []string{ip.String() + "/128"}
+		}
+	} else if host != "" {
+		dest.Domains = []string{host}
+	}
+	return allowRule(protocol, v3.EntityRule{}, dest)
+}
+
+// AllowS3Rule returns an egress rule allowing traffic to S3 compatible storage at the endpoint, or to Amazon S3 if
+// no endpoint is set.
+func AllowS3Rule(endpoint string) v3.Rule {
+	if endpoint == "" {
+		return AllowTCPRule(v3.EntityRule{Domains: []string{"*.amazonaws.com"}, Ports: Ports(443)})
+	}
+	return AllowAddressRule(endpoint)
+}
+
+// serviceMatch returns the service that the host name of an in-cluster service refers to, or nil if the host isn't the
+// name of a service.
+func serviceMatch(host string) *v3.ServiceMatch {
+	parts := strings.Split(host, ".")
+	if len(parts) < 3 || parts[2] != "svc" || parts[0] == "" || parts[1] == "" {
+		return nil
+	}
+	return &v3.ServiceMatch{Name: parts[0], Namespace: parts[1]}
+}
+
+func splitAddress(address string) (numorstring.Protocol, string, uint16) {
+	protocol := TCPProtocol
+	defaultPort := ""
+	if u, err := url.Parse(address); err == nil && u.Host != "" {
+		address = u.Host
+		switch u.Scheme {
+		case "udp":
+			protocol = UDPProtocol
+		case "https":
+			defaultPort = "443"
+		case "http":
+			defaultPort = "80"
+		case "ldaps":
+			defaultPort = "636"
+		case "ldap":
+			defaultPort = "389"
+		}
+	}
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		host, portStr = address, defaultPort
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return protocol, host, 0
+	}
+	return protocol, host, uint16(port)
+}
+
+func allowRule(protocol numorstring.Protocol, source, destination v3.EntityRule) v3.Rule {
+	return v3.Rule{
+		Action:      v3.Allow,
+		Protocol:    &protocol,
+		Source:      source,
+		Destination: destination,
+	}
+}
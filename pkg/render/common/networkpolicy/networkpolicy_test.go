@@ -0,0 +1,125 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkpolicy
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	"github.com/tigera/api/pkg/lib/numorstring"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var _ = Describe("allow-tigera network policy helpers", func() {
+	DescribeTable("AllowAddressRule",
+		func(address string, protocol numorstring.Protocol, dest v3.EntityRule) {
+			Expect(AllowAddressRule(address)).To(Equal(v3.Rule{
+				Action:      v3.Allow,
+				Protocol:    &protocol,
+				Destination: dest,
+			}))
+		},
+		Entry("host and port", "logs.example.com:601", TCPProtocol, v3.EntityRule{Domains: []string{"logs.example.com"}, Ports: Ports(601)}),
+		Entry("IPv4 address", "10.0.0.1:514", TCPProtocol, v3.EntityRule{Nets: []string{"10.0.0.1/32"}, Ports: Ports(514)}),
+		Entry("IPv6 address", "[fd00::1]:514", TCPProtocol, v3.EntityRule{Nets: []string{"fd00::1/128"}, Ports: Ports(514)}),
+		Entry("https URL", "https://idp.example.com/dex", TCPProtocol, v3.EntityRule{Domains: []string{"idp.example.com"}, Ports: Ports(443)}),
+		Entry("ldap URL", "ldap://ldap.example.com", TCPProtocol, v3.EntityRule{Domains: []string{"ldap.example.com"}, Ports: Ports(389)}),
+		Entry("tcp syslog URL", "tcp://10.0.0.1:601", TCPProtocol, v3.EntityRule{Nets: []string{"10.0.0.1/32"}, Ports: Ports(601)}),
+		Entry("udp syslog URL", "udp://syslog.example.com:514", UDPProtocol, v3.EntityRule{Domains: []string{"syslog.example.com"}, Ports: Ports(514)}),
+		Entry("host without a port", "logs.example.com", TCPProtocol, v3.EntityRule{Domains: []string{"logs.example.com"}}),
+	)
+
+	It("should allow traffic to the target ports of a service by protocol", func() {
+		svc := &corev1.Service{
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{Name: "https", Port: 443, TargetPort: intstr.FromInt(8443), Protocol: corev1.ProtocolTCP},
+					{Name: "metrics", Port: 9081, Protocol: corev1.ProtocolTCP},
+					{Name: "named", Port: 9443, TargetPort: intstr.FromString("web")},
+					{Name: "https-alt", Port: 8443, TargetPort: intstr.FromInt(8443), Protocol: corev1.ProtocolTCP},
+					{Name: "syslog", Port: 514, Protocol: corev1.ProtocolUDP},
+				},
+			},
+		}
+		source := CreateSourceEntityRule("tigera-manager", "tigera-manager")
+		Expect(ServiceIngressRules(svc, source)).To(Equal([]v3.Rule{
+			{
+				Action:      v3.Allow,
+				Protocol:    &TCPProtocol,
+				Source:      source,
+				Destination: v3.EntityRule{Ports: []numorstring.Port{numorstring.SinglePort(8443), numorstring.SinglePort(9081), numorstring.NamedPort("web")}},
+			},
+			{
+				Action:      v3.Allow,
+				Protocol:    &UDPProtocol,
+				Source:      source,
+				Destination: v3.EntityRule{Ports: Ports(514)},
+			},
+		}))
+	})
+
+	It("should create entity rules for the pods of an app", func() {
+		Expect(CreateEntityRule("tigera-dex", "tigera-dex", 5556)).To(Equal(v3.EntityRule{
+			Selector:          "k8s-app == 'tigera-dex'",
+			NamespaceSelector: "projectcalico.org/name == 'tigera-dex'",
+			Ports:             Ports(5556),
+		}))
+	})
+
+	It("should allow traffic to Amazon S3 unless an endpoint is set", func() {
+		Expect(AllowS3Rule("").Destination).To(Equal(v3.EntityRule{Domains: []string{"*.amazonaws.com"}, Ports: Ports(443)}))
+		Expect(AllowS3Rule("https://minio.example.com:9000").Destination).To(Equal(v3.EntityRule{Domains: []string{"minio.example.com"}, Ports: Ports(9000)}))
+		Expect(AllowS3Rule("http://minio.minio.svc:9000").Destination).To(Equal(v3.EntityRule{Services: &v3.ServiceMatch{Name: "minio", Namespace: "minio"}}))
+		Expect(AllowS3Rule("http://minio.minio.svc.cluster.local:9000").Destination).To(Equal(v3.EntityRule{Services: &v3.ServiceMatch{Name: "minio", Namespace: "minio"}}))
+	})
+
+	It("should render policies in the allow-tigera tier", func() {
+		deny := AllowTigeraDefaultDeny("tigera-dex")
+		Expect(deny.Name).To(Equal(DefaultDenyPolicyName))
+		Expect(deny.Spec.Tier).To(Equal(TigeraComponentTierName))
+		Expect(deny.Spec.Selector).To(Equal("all()"))
+		Expect(deny.Spec.Ingress).To(BeEmpty())
+		Expect(deny.Spec.Egress).To(BeEmpty())
+
+		policy := AllowTigeraPolicy("tigera-dex", "tigera-dex", nil, AllowDNSRules(false))
+		Expect(policy.ObjectMeta).To(Equal(metav1.ObjectMeta{Name: "allow-tigera.tigera-dex", Namespace: "tigera-dex"}))
+		Expect(policy.Spec.Tier).To(Equal(TigeraComponentTierName))
+		Expect(policy.Spec.Selector).To(Equal("k8s-app == 'tigera-dex'"))
+		Expect(policy.Spec.Types).To(ConsistOf(v3.PolicyTypeIngress, v3.PolicyTypeEgress))
+		Expect(policy.Spec.Egress).To(HaveLen(2))
+	})
+
+	It("should use the OpenShift DNS service on OpenShift", func() {
+		for _, r := range AllowDNSRules(true) {
+			Expect(r.Destination.NamespaceSelector).To(Equal("projectcalico.org/name == 'openshift-dns'"))
+			Expect(r.Destination.Ports).To(Equal(Ports(5353)))
+		}
+	})
+
+	DescribeTable("State.Split",
+		func(state State, create, remove int) {
+			toCreate, toDelete := state.Split(AllowTigeraDefaultDeny("a"), AllowTigeraDefaultDeny("b"))
+			Expect(toCreate).To(HaveLen(create))
+			Expect(toDelete).To(HaveLen(remove))
+		},
+		Entry("enabled", StateEnabled, 2, 0),
+		Entry("disabled", StateDisabled, 0, 2),
+		Entry("unavailable", StateUnavailable, 0, 0),
+	)
+})
@@ -6,10 +6,34 @@ import (
 )
 
 // NewBaseContext returns the non root non privileged security context that most of the containers running should
-// be using.
+// be using. It satisfies the "restricted" pod security standard.
 func NewBaseContext() *corev1.SecurityContext {
 	return &corev1.SecurityContext{
 		RunAsNonRoot:             ptr.BoolToPtr(true),
 		AllowPrivilegeEscalation: ptr.BoolToPtr(false),
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+}
+
+// NewRootContext returns the security context for containers that need to run as the root user. Such containers
+// require at least a "baseline" pod security standard, or a "privileged" one if privileged is true.
+func NewRootContext(privileged bool) *corev1.SecurityContext {
+	return &corev1.SecurityContext{
+		Privileged: ptr.BoolToPtr(privileged),
+		RunAsUser:  ptr.Int64ToPtr(0),
+		RunAsGroup: ptr.Int64ToPtr(0),
+	}
+}
+
+// NewPrivilegedContext returns the security context for containers that need full access to the host. Such
+// containers require a "privileged" pod security standard.
+func NewPrivilegedContext() *corev1.SecurityContext {
+	return &corev1.SecurityContext{
+		Privileged: ptr.BoolToPtr(true),
 	}
 }
@@ -0,0 +1,240 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podsecuritycontext
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+// Requirement describes the pod security standard that the pods of a workload need from their namespace, and why
+// they do not satisfy a more restrictive one.
+type Requirement struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Level     operatorv1.PodSecurityStandardLevel
+	Reasons   []string
+}
+
+func (r Requirement) String() string {
+	return fmt.Sprintf("%s %s/%s requires the %q pod security standard: %s",
+		r.Kind, r.Namespace, r.Name, r.Level, strings.Join(r.Reasons, "; "))
+}
+
+// The capabilities that may be added under the "baseline" pod security standard.
+// https://kubernetes.io/docs/concepts/security/pod-security-standards/#baseline
+var baselineCapabilities = map[corev1.Capability]bool{
+	"AUDIT_WRITE":      true,
+	"CHOWN":            true,
+	"DAC_OVERRIDE":     true,
+	"FOWNER":           true,
+	"FSETID":           true,
+	"KILL":             true,
+	"MKNOD":            true,
+	"NET_BIND_SERVICE": true,
+	"SETFCAP":          true,
+	"SETGID":           true,
+	"SETPCAP":          true,
+	"SETUID":           true,
+	"SYS_CHROOT":       true,
+}
+
+// Report returns the requirements of the workloads in objs whose pods cannot run in a namespace with the
+// "restricted" pod security standard.
+func Report(objs []client.Object) []Requirement {
+	var reqs []Requirement
+	for _, obj := range objs {
+		if req, ok := Check(obj); ok && req.Level != operatorv1.PodSecurityStandardRestricted {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs
+}
+
+// Check returns the pod security standard that the pods of obj need from their namespace. ok is false if obj is not
+// a workload.
+func Check(obj client.Object) (req Requirement, ok bool) {
+	var kind string
+	var spec *corev1.PodSpec
+	switch x := obj.(type) {
+	case *appsv1.Deployment:
+		kind, spec = "Deployment", &x.Spec.Template.Spec
+	case *appsv1.DaemonSet:
+		kind, spec = "DaemonSet", &x.Spec.Template.Spec
+	case *appsv1.StatefulSet:
+		kind, spec = "StatefulSet", &x.Spec.Template.Spec
+	case *batchv1.Job:
+		kind, spec = "Job", &x.Spec.Template.Spec
+	case *batchv1beta.CronJob:
+		kind, spec = "CronJob", &x.Spec.JobTemplate.Spec.Template.Spec
+	default:
+		return Requirement{}, false
+	}
+
+	level, reasons := Evaluate(spec)
+	return Requirement{
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Level:     level,
+		Reasons:   reasons,
+	}, true
+}
+
+// Evaluate returns the most restrictive pod security standard that the pod spec satisfies, along with the reasons it
+// does not satisfy the next more restrictive standard.
+// https://kubernetes.io/docs/concepts/security/pod-security-standards/
+func Evaluate(spec *corev1.PodSpec) (operatorv1.PodSecurityStandardLevel, []string) {
+	if reasons := baselineViolations(spec); len(reasons) > 0 {
+		return operatorv1.PodSecurityStandardPrivileged, reasons
+	}
+	if reasons := restrictedViolations(spec); len(reasons) > 0 {
+		return operatorv1.PodSecurityStandardBaseline, reasons
+	}
+	return operatorv1.PodSecurityStandardRestricted, nil
+}
+
+func baselineViolations(spec *corev1.PodSpec) []string {
+	var reasons []string
+	if spec.HostNetwork {
+		reasons = append(reasons, "uses the host network")
+	}
+	if spec.HostPID {
+		reasons = append(reasons, "uses the host PID namespace")
+	}
+	if spec.HostIPC {
+		reasons = append(reasons, "uses the host IPC namespace")
+	}
+	for _, v := range spec.Volumes {
+		if v.HostPath != nil {
+			reasons = append(reasons, fmt.Sprintf("volume %q is a hostPath volume", v.Name))
+		}
+	}
+	if spec.SecurityContext != nil && isUnconfined(spec.SecurityContext.SeccompProfile) {
+		reasons = append(reasons, "pod seccomp profile is Unconfined")
+	}
+
+	for _, c := range containers(spec) {
+		if !spec.HostNetwork {
+			for _, p := range c.Ports {
+				if p.HostPort != 0 {
+					reasons = append(reasons, fmt.Sprintf("container %q uses host port %d", c.Name, p.HostPort))
+				}
+			}
+		}
+		sc := c.SecurityContext
+		if sc == nil {
+			continue
+		}
+		if sc.Privileged != nil && *sc.Privileged {
+			reasons = append(reasons, fmt.Sprintf("container %q is privileged", c.Name))
+		}
+		if sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				if !baselineCapabilities[capability] {
+					reasons = append(reasons, fmt.Sprintf("container %q adds capability %s", c.Name, capability))
+				}
+			}
+		}
+		if isUnconfined(sc.SeccompProfile) {
+			reasons = append(reasons, fmt.Sprintf("container %q seccomp profile is Unconfined", c.Name))
+		}
+		if sc.ProcMount != nil && *sc.ProcMount != corev1.DefaultProcMount {
+			reasons = append(reasons, fmt.Sprintf("container %q uses an unmasked proc mount", c.Name))
+		}
+	}
+	return reasons
+}
+
+func restrictedViolations(spec *corev1.PodSpec) []string {
+	var reasons []string
+	for _, v := range spec.Volumes {
+		if !isRestrictedVolume(v.VolumeSource) {
+			reasons = append(reasons, fmt.Sprintf("volume %q is of a type that is not allowed", v.Name))
+		}
+	}
+
+	podSC := spec.SecurityContext
+	if podSC == nil {
+		podSC = &corev1.PodSecurityContext{}
+	}
+	if podSC.RunAsUser != nil && *podSC.RunAsUser == 0 {
+		reasons = append(reasons, "pod runs as the root user")
+	}
+
+	for _, c := range containers(spec) {
+		sc := c.SecurityContext
+		if sc == nil {
+			sc = &corev1.SecurityContext{}
+		}
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			reasons = append(reasons, fmt.Sprintf("container %q allows privilege escalation", c.Name))
+		}
+		if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+			reasons = append(reasons, fmt.Sprintf("container %q runs as the root user", c.Name))
+		} else if !isTrue(sc.RunAsNonRoot) && !isTrue(podSC.RunAsNonRoot) {
+			reasons = append(reasons, fmt.Sprintf("container %q does not set runAsNonRoot", c.Name))
+		}
+		if sc.SeccompProfile == nil && podSC.SeccompProfile == nil {
+			reasons = append(reasons, fmt.Sprintf("container %q does not set a seccomp profile", c.Name))
+		}
+		if sc.Capabilities == nil || !dropsAll(sc.Capabilities.Drop) {
+			reasons = append(reasons, fmt.Sprintf("container %q does not drop all capabilities", c.Name))
+		}
+		if sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				if capability != "NET_BIND_SERVICE" {
+					reasons = append(reasons, fmt.Sprintf("container %q adds capability %s", c.Name, capability))
+				}
+			}
+		}
+	}
+	return reasons
+}
+
+func containers(spec *corev1.PodSpec) []corev1.Container {
+	return append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+}
+
+func isRestrictedVolume(v corev1.VolumeSource) bool {
+	return v.ConfigMap != nil || v.CSI != nil || v.DownwardAPI != nil || v.EmptyDir != nil || v.Ephemeral != nil ||
+		v.PersistentVolumeClaim != nil || v.Projected != nil || v.Secret != nil
+}
+
+func isUnconfined(p *corev1.SeccompProfile) bool {
+	return p != nil && p.Type == corev1.SeccompProfileTypeUnconfined
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+func dropsAll(caps []corev1.Capability) bool {
+	for _, capability := range caps {
+		if capability == "ALL" {
+			return true
+		}
+	}
+	return false
+}
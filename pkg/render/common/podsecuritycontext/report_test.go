@@ -0,0 +1,150 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package podsecuritycontext
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/ptr"
+)
+
+var _ = Describe("pod security standard evaluation", func() {
+	podSpec := func(sc *corev1.SecurityContext) *corev1.PodSpec {
+		return &corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "c", SecurityContext: sc}},
+			Volumes: []corev1.Volume{
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+				{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+		}
+	}
+
+	It("should accept the base context under the restricted standard", func() {
+		level, reasons := Evaluate(podSpec(NewBaseContext()))
+		Expect(level).To(Equal(operatorv1.PodSecurityStandardRestricted))
+		Expect(reasons).To(BeEmpty())
+	})
+
+	It("should require the baseline standard for the non privileged root context", func() {
+		level, reasons := Evaluate(podSpec(NewRootContext(false)))
+		Expect(level).To(Equal(operatorv1.PodSecurityStandardBaseline))
+		Expect(reasons).To(ConsistOf(
+			`container "c" allows privilege escalation`,
+			`container "c" runs as the root user`,
+			`container "c" does not set a seccomp profile`,
+			`container "c" does not drop all capabilities`,
+		))
+	})
+
+	It("should require the privileged standard for the privileged root context", func() {
+		level, reasons := Evaluate(podSpec(NewRootContext(true)))
+		Expect(level).To(Equal(operatorv1.PodSecurityStandardPrivileged))
+		Expect(reasons).To(ConsistOf(`container "c" is privileged`))
+	})
+
+	It("should require the privileged standard for the privileged context", func() {
+		level, reasons := Evaluate(podSpec(NewPrivilegedContext()))
+		Expect(level).To(Equal(operatorv1.PodSecurityStandardPrivileged))
+		Expect(reasons).To(ConsistOf(`container "c" is privileged`))
+	})
+
+	It("should require the baseline standard when a restricted container runs in a root pod", func() {
+		spec := podSpec(NewBaseContext())
+		spec.SecurityContext = &corev1.PodSecurityContext{RunAsUser: ptr.Int64ToPtr(0)}
+		level, reasons := Evaluate(spec)
+		Expect(level).To(Equal(operatorv1.PodSecurityStandardBaseline))
+		Expect(reasons).To(ConsistOf("pod runs as the root user"))
+	})
+
+	It("should require the privileged standard for host namespaces, host paths and extra capabilities", func() {
+		sc := NewBaseContext()
+		sc.Capabilities.Add = []corev1.Capability{"NET_ADMIN"}
+		spec := podSpec(sc)
+		spec.HostNetwork = true
+		spec.HostPID = true
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name:         "logs",
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/log/calico"}},
+		})
+		level, reasons := Evaluate(spec)
+		Expect(level).To(Equal(operatorv1.PodSecurityStandardPrivileged))
+		Expect(reasons).To(ConsistOf(
+			"uses the host network",
+			"uses the host PID namespace",
+			`volume "logs" is a hostPath volume`,
+			`container "c" adds capability NET_ADMIN`,
+		))
+	})
+
+	It("should only allow NET_BIND_SERVICE to be added under the restricted standard", func() {
+		sc := NewBaseContext()
+		sc.Capabilities.Add = []corev1.Capability{"NET_BIND_SERVICE"}
+		level, _ := Evaluate(podSpec(sc))
+		Expect(level).To(Equal(operatorv1.PodSecurityStandardRestricted))
+
+		sc.Capabilities.Add = []corev1.Capability{"CHOWN"}
+		level, reasons := Evaluate(podSpec(sc))
+		Expect(level).To(Equal(operatorv1.PodSecurityStandardBaseline))
+		Expect(reasons).To(ConsistOf(`container "c" adds capability CHOWN`))
+	})
+
+	Context("Report", func() {
+		It("should report the workloads that need more than the restricted standard", func() {
+			// The API server container runs as root so that it can write the audit log to the host.
+			apiServer := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "tigera-apiserver", Namespace: "tigera-system"},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "tigera-apiserver", SecurityContext: NewRootContext(false)}},
+					Volumes: []corev1.Volume{{
+						Name:         "tigera-audit-logs",
+						VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/log/calico/audit"}},
+					}},
+				}}},
+			}
+			job := &batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "ns"},
+				Spec:       batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: *podSpec(NewRootContext(false))}},
+			}
+			restricted := &appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "restricted", Namespace: "ns"},
+				Spec:       appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: *podSpec(NewBaseContext())}},
+			}
+			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "ns"}}
+
+			reqs := Report([]client.Object{apiServer, job, restricted, cm})
+			Expect(reqs).To(HaveLen(2))
+
+			Expect(reqs[0].Kind).To(Equal("Deployment"))
+			Expect(reqs[0].Namespace).To(Equal("tigera-system"))
+			Expect(reqs[0].Name).To(Equal("tigera-apiserver"))
+			Expect(reqs[0].Level).To(Equal(operatorv1.PodSecurityStandardPrivileged))
+			Expect(reqs[0].Reasons).To(ConsistOf(`volume "tigera-audit-logs" is a hostPath volume`))
+			Expect(reqs[0].String()).To(Equal(`Deployment tigera-system/tigera-apiserver requires the "privileged" pod security standard: ` +
+				`volume "tigera-audit-logs" is a hostPath volume`))
+
+			Expect(reqs[1].Kind).To(Equal("Job"))
+			Expect(reqs[1].Level).To(Equal(operatorv1.PodSecurityStandardBaseline))
+			Expect(reqs[1].Reasons).To(ContainElement(`container "c" runs as the root user`))
+		})
+	})
+})
@@ -80,8 +80,8 @@ func ExpectResourceInList(objs []client.Object, name, ns, group, version, kind s
 	elems := []expectedResource{}
 	for _, obj := range objs {
 		elems = append(elems, expectedResource{
-			Name:      obj.(metav1.ObjectMetaAccessor).GetObjectMeta().GetName(),
-			Namespace: obj.(metav1.ObjectMetaAccessor).GetObjectMeta().GetNamespace(),
+			Name:      obj.(metav1.Object).GetName(),
+			Namespace: obj.(metav1.Object).GetNamespace(),
 			GVK:       obj.GetObjectKind().GroupVersionKind(),
 		})
 	}
@@ -90,8 +90,8 @@ func ExpectResourceInList(objs []client.Object, name, ns, group, version, kind s
 
 func ExpectResource(resource runtime.Object, name, ns, group, version, kind string) {
 	gvk := schema.GroupVersionKind{Group: group, Version: version, Kind: kind}
-	actualName := resource.(metav1.ObjectMetaAccessor).GetObjectMeta().GetName()
-	actualNS := resource.(metav1.ObjectMetaAccessor).GetObjectMeta().GetNamespace()
+	actualName := resource.(metav1.Object).GetName()
+	actualNS := resource.(metav1.Object).GetNamespace()
 	ExpectWithOffset(1, actualName).To(Equal(name), fmt.Sprintf("Rendered %s resource in namespace %s has wrong name", kind, ns))
 	ExpectWithOffset(1, actualNS).To(Equal(ns), fmt.Sprintf("Rendered resource %s/%s has wrong namespace", kind, name))
 	ExpectWithOffset(1, resource.GetObjectKind().GroupVersionKind()).To(Equal(gvk), fmt.Sprintf("Rendered resource %s does not match expected GVK", name))
@@ -100,7 +100,7 @@ func ExpectResource(resource runtime.Object, name, ns, group, version, kind stri
 func GetResource(resources []client.Object, name, ns, group, version, kind string) client.Object {
 	for _, resource := range resources {
 		gvk := schema.GroupVersionKind{Group: group, Version: version, Kind: kind}
-		om := resource.(metav1.ObjectMetaAccessor).GetObjectMeta()
+		om := resource.(metav1.Object)
 		if name == om.GetName() &&
 			ns == om.GetNamespace() &&
 			gvk == resource.GetObjectKind().GroupVersionKind() {
@@ -120,7 +120,7 @@ func GetContainer(containers []v1.Container, name string) *v1.Container {
 }
 
 func ExpectGlobalReportType(resource runtime.Object, name string) {
-	actualName := resource.(metav1.ObjectMetaAccessor).GetObjectMeta().GetName()
+	actualName := resource.(metav1.Object).GetName()
 	Expect(actualName).To(Equal(name), "Rendered resource has wrong name")
 	gvk := schema.GroupVersionKind{Group: "projectcalico.org", Version: "v3", Kind: "GlobalReportType"}
 	Expect(resource.GetObjectKind().GroupVersionKind()).To(Equal(gvk), fmt.Sprintf("Rendered resource %s does not match expected GVK", name))
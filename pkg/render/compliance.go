@@ -16,6 +16,7 @@ package render
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	ocsv1 "github.com/openshift/api/security/v1"
@@ -24,14 +25,20 @@ import (
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/render/common/authentication"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
 	"github.com/tigera/operator/pkg/render/common/configmap"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/podsecuritypolicy"
 	"github.com/tigera/operator/pkg/render/common/secret"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -46,7 +53,24 @@ const (
 	ComplianceServerName      = "compliance-server"
 	ComplianceControllerName  = "compliance-controller"
 	ComplianceSnapshotterName = "compliance-snapshotter"
+	ComplianceBenchmarkerName = "compliance-benchmarker"
+	ComplianceReporterName    = "compliance-reporter"
 	ComplianceServerSAName    = "tigera-compliance-server"
+
+	// ComplianceReportLabel is set on the GlobalReports and custom GlobalReportTypes of the Compliance CR, so that
+	// the ones removed from the CR can be found.
+	ComplianceReportLabel      = "operator.tigera.io/compliance-report"
+	complianceReportLabelValue = "managed"
+
+	ComplianceReportExporterName   = "compliance-report-exporter"
+	ComplianceReportExporterSAName = "tigera-compliance-report-exporter"
+	// ComplianceReportExportCredentialsSecret is the copy, in the compliance namespace, of the secret with the
+	// credentials of the bucket that reports are exported to.
+	ComplianceReportExportCredentialsSecret = "tigera-compliance-report-export-credentials"
+	DefaultComplianceReportExportSchedule   = "0 * * * *"
+
+	complianceReportExportVolumeName = "report-export"
+	complianceReportExportMountPath  = "/report-export"
 )
 
 const (
@@ -81,8 +105,25 @@ type ComplianceConfiguration struct {
 	ClusterDomain               string
 	HasNoLicense                bool
 
+	// Compliance is the Compliance CR, which may define reports, custom report types and settings of the components.
+	Compliance *operatorv1.Compliance
+	// RemovedReports and RemovedReportTypes are the names of GlobalReports and GlobalReportTypes that were created for
+	// the Compliance CR but have since been removed from it.
+	RemovedReports     []string
+	RemovedReportTypes []string
+	// ReportExportCredentials is the secret, in the operator namespace, with the credentials of the bucket that
+	// reports are exported to. Only set when reports are exported to S3.
+	ReportExportCredentials *corev1.Secret
+	// LogLevel is the log level of the compliance components. The default of each component is used if it is empty.
+	LogLevel operatorv1.LogLevel
+	// ServerAutoscaling, if set, scales the replicas of the compliance server with a HorizontalPodAutoscaler.
+	ServerAutoscaling *operatorv1.Autoscaling
+
 	// Whether or not the cluster supports pod security policies.
 	UsePSP bool
+
+	// NetworkPolicyState determines whether the policies for compliance in the allow-tigera tier are created or removed.
+	NetworkPolicyState networkpolicy.State
 }
 
 type complianceComponent struct {
@@ -92,6 +133,7 @@ type complianceComponent struct {
 	serverImage      string
 	controllerImage  string
 	reporterImage    string
+	exporterImage    string
 }
 
 func (c *complianceComponent) ResolveImages(is *operatorv1.ImageSet) error {
@@ -126,6 +168,13 @@ func (c *complianceComponent) ResolveImages(is *operatorv1.ImageSet) error {
 		errMsgs = append(errMsgs, err.Error())
 	}
 
+	if c.reportExport() != nil {
+		c.exporterImage, err = components.GetReference(components.ComponentOperatorInit, reg, path, prefix, is)
+		if err != nil {
+			errMsgs = append(errMsgs, err.Error())
+		}
+	}
+
 	if len(errMsgs) != 0 {
 		return fmt.Errorf(strings.Join(errMsgs, ","))
 	}
@@ -138,7 +187,7 @@ func (c *complianceComponent) SupportedOSType() rmeta.OSType {
 
 func (c *complianceComponent) Objects() ([]client.Object, []client.Object) {
 	complianceObjs := append(
-		[]client.Object{CreateNamespace(ComplianceNamespace, c.cfg.Installation.KubernetesProvider, PSSPrivileged)},
+		[]client.Object{CreateNamespace(ComplianceNamespace, c.cfg.Installation, PSSPrivileged)},
 		secret.ToRuntimeObjects(secret.CopyToNamespace(ComplianceNamespace, c.cfg.PullSecrets...)...)...,
 	)
 	complianceObjs = append(complianceObjs,
@@ -168,7 +217,10 @@ func (c *complianceComponent) Objects() ([]client.Object, []client.Object) {
 		c.complianceGlobalReportNetworkAccess(),
 		c.complianceGlobalReportPolicyAudit(),
 		c.complianceGlobalReportCISBenchmark(),
-
+	)
+	complianceObjs = append(complianceObjs, c.complianceCustomReportTypes()...)
+	complianceObjs = append(complianceObjs, c.complianceGlobalReports()...)
+	complianceObjs = append(complianceObjs,
 		// We always need a sa and crb, whether a deployment of compliance-server is present or not.
 		// These two are used for rbac checks for managed clusters.
 		c.complianceServerServiceAccount(),
@@ -181,15 +233,29 @@ func (c *complianceComponent) Objects() ([]client.Object, []client.Object) {
 	}
 
 	var objsToDelete []client.Object
+	for _, name := range c.cfg.RemovedReports {
+		objsToDelete = append(objsToDelete, &v3.GlobalReport{TypeMeta: metav1.TypeMeta{Kind: "GlobalReport", APIVersion: "projectcalico.org/v3"}, ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	for _, name := range c.cfg.RemovedReportTypes {
+		objsToDelete = append(objsToDelete, &v3.GlobalReportType{TypeMeta: metav1.TypeMeta{Kind: "GlobalReportType", APIVersion: "projectcalico.org/v3"}, ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+
 	// Compliance server is only for Standalone or Management clusters
 	if c.cfg.ManagementClusterConnection == nil {
+		deployment := c.complianceServerDeployment()
+		autoscalingObjs, autoscalingObjsToDelete := autoscaling.Objects(deployment, c.cfg.ServerAutoscaling)
 		complianceObjs = append(complianceObjs,
 			c.complianceServerClusterRole(),
 			c.complianceServerService(),
-			c.complianceServerDeployment(),
+			deployment,
 		)
+		complianceObjs = append(complianceObjs, autoscalingObjs...)
+		objsToDelete = append(objsToDelete, autoscalingObjsToDelete...)
 	} else {
-		objsToDelete = append(objsToDelete, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: ComplianceServerName, Namespace: ComplianceNamespace}})
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: ComplianceServerName, Namespace: ComplianceNamespace}}
+		_, autoscalingObjsToDelete := autoscaling.Objects(deployment, nil)
+		objsToDelete = append(objsToDelete, deployment)
+		objsToDelete = append(objsToDelete, autoscalingObjsToDelete...)
 		if c.cfg.ManagementClusterConnection != nil { // This is a managed cluster
 			complianceObjs = append(complianceObjs,
 				c.complianceServerManagedClusterRole(),
@@ -197,6 +263,33 @@ func (c *complianceComponent) Objects() ([]client.Object, []client.Object) {
 		}
 	}
 
+	// The reports are exported from the compliance server, so they can only be exported where it runs.
+	if c.reportExport() != nil {
+		complianceObjs = append(complianceObjs,
+			c.complianceReportExporterServiceAccount(),
+			c.complianceReportExporterClusterRole(),
+			c.complianceReportExporterClusterRoleBinding(),
+			c.complianceReportExporterCronJob(),
+		)
+		if c.cfg.ReportExportCredentials != nil {
+			complianceObjs = append(complianceObjs, c.complianceReportExportCredentials())
+		} else {
+			objsToDelete = append(objsToDelete, &corev1.Secret{TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: ComplianceReportExportCredentialsSecret, Namespace: ComplianceNamespace}})
+		}
+		if !c.cfg.Openshift && c.cfg.UsePSP {
+			complianceObjs = append(complianceObjs, c.complianceReportExporterPodSecurityPolicy())
+		}
+	} else {
+		objsToDelete = append(objsToDelete,
+			&batchv1beta.CronJob{TypeMeta: metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1beta1"}, ObjectMeta: metav1.ObjectMeta{Name: ComplianceReportExporterName, Namespace: ComplianceNamespace}},
+			&corev1.Secret{TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: ComplianceReportExportCredentialsSecret, Namespace: ComplianceNamespace}},
+			&rbacv1.ClusterRoleBinding{TypeMeta: metav1.TypeMeta{Kind: "ClusterRoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"}, ObjectMeta: metav1.ObjectMeta{Name: ComplianceReportExporterSAName}},
+			&rbacv1.ClusterRole{TypeMeta: metav1.TypeMeta{Kind: "ClusterRole", APIVersion: "rbac.authorization.k8s.io/v1"}, ObjectMeta: metav1.ObjectMeta{Name: ComplianceReportExporterSAName}},
+			&corev1.ServiceAccount{TypeMeta: metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: ComplianceReportExporterSAName, Namespace: ComplianceNamespace}},
+			&policyv1beta1.PodSecurityPolicy{TypeMeta: metav1.TypeMeta{Kind: "PodSecurityPolicy", APIVersion: "policy/v1beta1"}, ObjectMeta: metav1.ObjectMeta{Name: ComplianceReportExporterName}},
+		)
+	}
+
 	if c.cfg.Openshift {
 		complianceObjs = append(complianceObjs, c.complianceBenchmarkerSecurityContextConstraints())
 	} else if c.cfg.UsePSP {
@@ -216,13 +309,143 @@ func (c *complianceComponent) Objects() ([]client.Object, []client.Object) {
 
 	complianceObjs = append(complianceObjs, secret.ToRuntimeObjects(secret.CopyToNamespace(ComplianceNamespace, c.cfg.ESSecrets...)...)...)
 
+	policies, policiesToDelete := c.cfg.NetworkPolicyState.Split(c.networkPolicies()...)
+	complianceObjs = append(complianceObjs, policies...)
+	objsToDelete = append(objsToDelete, policiesToDelete...)
+
 	if c.cfg.HasNoLicense {
-		return nil, complianceObjs
+		return nil, append(complianceObjs, objsToDelete...)
 	}
 
 	return complianceObjs, objsToDelete
 }
 
+// networkPolicies allows the compliance components to reach the Kubernetes API and Elasticsearch, either directly or
+// through guardian in a managed cluster, and the manager to reach the compliance server.
+func (c *complianceComponent) networkPolicies() []client.Object {
+	openshift := c.cfg.Installation.KubernetesProvider == operatorv1.ProviderOpenShift
+	egress := networkpolicy.AllowDNSRules(openshift)
+	egress = append(egress,
+		networkpolicy.AllowKubeAPIServerRule(),
+		networkpolicy.AllowTCPRule(networkpolicy.ESGatewayEntityRule),
+		networkpolicy.AllowTCPRule(networkpolicy.GuardianEntityRule),
+	)
+	controllerEgress := append(networkpolicy.AllowDNSRules(openshift), networkpolicy.AllowKubeAPIServerRule())
+
+	serverEgress := append([]v3.Rule{}, egress...)
+	if c.cfg.KeyValidatorConfig != nil {
+		serverEgress = append(serverEgress, networkpolicy.AllowTCPRule(networkpolicy.DexEntityRule))
+		if issuer := c.cfg.KeyValidatorConfig.Issuer(); issuer != "" {
+			serverEgress = append(serverEgress, networkpolicy.AllowAddressRule(issuer))
+		}
+	}
+
+	serverIngress := networkpolicy.ServiceIngressRules(c.complianceServerService(), networkpolicy.ManagerSourceEntityRule)
+	if c.reportExport() != nil {
+		// The exporter downloads the reports from the compliance server.
+		serverIngress = append(serverIngress, networkpolicy.ServiceIngressRules(c.complianceServerService(),
+			networkpolicy.CreateSourceEntityRule(ComplianceNamespace, ComplianceReportExporterName))...)
+	}
+
+	policies := []client.Object{
+		networkpolicy.AllowTigeraDefaultDeny(ComplianceNamespace),
+		networkpolicy.AllowTigeraPolicy(ComplianceControllerName, ComplianceNamespace, nil, controllerEgress),
+		networkpolicy.AllowTigeraPolicy(ComplianceSnapshotterName, ComplianceNamespace, nil, egress),
+		networkpolicy.AllowTigeraPolicy(ComplianceBenchmarkerName, ComplianceNamespace, nil, egress),
+		networkpolicy.AllowTigeraPolicy(ComplianceReporterName, ComplianceNamespace, nil, egress),
+		networkpolicy.AllowTigeraPolicy(ComplianceServerName, ComplianceNamespace, serverIngress, serverEgress),
+	}
+
+	if export := c.reportExport(); export != nil {
+		exporterEgress := append(networkpolicy.AllowDNSRules(openshift), networkpolicy.AllowTCPRule(networkpolicy.ComplianceServerEntityRule))
+		if export.S3 != nil {
+			exporterEgress = append(exporterEgress, networkpolicy.AllowS3Rule(export.S3.Endpoint))
+		}
+		policies = append(policies, networkpolicy.AllowTigeraPolicy(ComplianceReportExporterName, ComplianceNamespace, nil, exporterEgress))
+	}
+	return policies
+}
+
+func (c *complianceComponent) spec() operatorv1.ComplianceSpec {
+	if c.cfg.Compliance == nil {
+		return operatorv1.ComplianceSpec{}
+	}
+	return c.cfg.Compliance.Spec
+}
+
+// reportExport returns the export configuration of the reports, or nil if they are not exported.
+func (c *complianceComponent) reportExport() *operatorv1.ComplianceReportExport {
+	if c.cfg.ManagementClusterConnection != nil {
+		return nil
+	}
+	return c.spec().ReportExport
+}
+
+// ComplianceBuiltInReportTypeNames returns the names of the GlobalReportTypes that are always installed.
+func ComplianceBuiltInReportTypeNames() []string {
+	return []string{"inventory", "network-access", "policy-audit", "cis-benchmark"}
+}
+
+func (c *complianceComponent) complianceCustomReportTypes() []client.Object {
+	var objs []client.Object
+	for _, rt := range c.spec().ReportTypes {
+		var downloads []v3.ReportTemplate
+		for _, t := range rt.DownloadTemplates {
+			downloads = append(downloads, v3.ReportTemplate{Name: t.Name, Description: t.Description, Template: t.Template})
+		}
+		objs = append(objs, &v3.GlobalReportType{
+			TypeMeta: metav1.TypeMeta{Kind: "GlobalReportType", APIVersion: "projectcalico.org/v3"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: rt.Name,
+				Labels: map[string]string{
+					"global-report-type":  rt.Name,
+					ComplianceReportLabel: complianceReportLabelValue,
+				},
+			},
+			Spec: v3.ReportTypeSpec{
+				UISummaryTemplate: v3.ReportTemplate{
+					Name:        rt.UISummaryTemplate.Name,
+					Description: rt.UISummaryTemplate.Description,
+					Template:    rt.UISummaryTemplate.Template,
+				},
+				DownloadTemplates:          downloads,
+				IncludeEndpointData:        rt.IncludeEndpointData,
+				IncludeEndpointFlowLogData: rt.IncludeEndpointFlowLogData,
+				IncludeCISBenchmarkData:    rt.IncludeCISBenchmarkData,
+			},
+		})
+	}
+	return objs
+}
+
+func (c *complianceComponent) complianceGlobalReports() []client.Object {
+	var objs []client.Object
+	for _, r := range c.spec().Reports {
+		var endpoints *v3.EndpointsSelection
+		if r.EndpointSelector != "" || len(r.Namespaces) != 0 || r.NamespaceSelector != "" {
+			endpoints = &v3.EndpointsSelection{Selector: r.EndpointSelector}
+			if len(r.Namespaces) != 0 || r.NamespaceSelector != "" {
+				endpoints.Namespaces = &v3.NamesAndLabelsMatch{Names: r.Namespaces, Selector: r.NamespaceSelector}
+			}
+		}
+		objs = append(objs, &v3.GlobalReport{
+			TypeMeta: metav1.TypeMeta{Kind: "GlobalReport", APIVersion: "projectcalico.org/v3"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   r.Name,
+				Labels: map[string]string{ComplianceReportLabel: complianceReportLabelValue},
+			},
+			Spec: v3.ReportSpec{
+				ReportType:      r.ReportType,
+				Endpoints:       endpoints,
+				Schedule:        r.Schedule,
+				JobNodeSelector: r.JobNodeSelector,
+				Suspend:         r.Suspend,
+			},
+		})
+	}
+	return objs
+}
+
 func (c *complianceComponent) Ready() bool {
 	return true
 }
@@ -367,7 +590,7 @@ func (c *complianceComponent) complianceControllerClusterAdminClusterRoleBinding
 
 func (c *complianceComponent) complianceControllerDeployment() *appsv1.Deployment {
 	envVars := []corev1.EnvVar{
-		{Name: "LOG_LEVEL", Value: "info"},
+		{Name: "LOG_LEVEL", Value: loglevel.Logrus(c.cfg.LogLevel, "info")},
 		{Name: "TIGERA_COMPLIANCE_JOB_NAMESPACE", Value: ComplianceNamespace},
 		{Name: "TIGERA_COMPLIANCE_MAX_FAILED_JOBS_HISTORY", Value: "3"},
 		{Name: "TIGERA_COMPLIANCE_MAX_JOB_RETRIES", Value: "6"},
@@ -384,10 +607,11 @@ func (c *complianceComponent) complianceControllerDeployment() *appsv1.Deploymen
 			ImagePullSecrets:   secret.GetReferenceList(c.cfg.PullSecrets),
 			Containers: []corev1.Container{
 				relasticsearch.ContainerDecorate(corev1.Container{
-					Name:          ComplianceControllerName,
-					Image:         c.controllerImage,
-					Env:           envVars,
-					LivenessProbe: complianceLivenessProbe,
+					Name:            ComplianceControllerName,
+					Image:           c.controllerImage,
+					Env:             envVars,
+					LivenessProbe:   complianceLivenessProbe,
+					SecurityContext: podsecuritycontext.NewBaseContext(),
 					VolumeMounts: []corev1.VolumeMount{
 						c.cfg.TrustedBundle.VolumeMount(c.SupportedOSType()),
 					},
@@ -484,7 +708,7 @@ func (c *complianceComponent) complianceReporterPodTemplate() *corev1.PodTemplat
 	}
 
 	envVars := []corev1.EnvVar{
-		{Name: "LOG_LEVEL", Value: "warning"},
+		{Name: "LOG_LEVEL", Value: loglevel.Logrus(c.cfg.LogLevel, "warning")},
 		{Name: "TIGERA_COMPLIANCE_JOB_NAMESPACE", Value: ComplianceNamespace},
 	}
 	return &corev1.PodTemplate{
@@ -493,7 +717,7 @@ func (c *complianceComponent) complianceReporterPodTemplate() *corev1.PodTemplat
 			Name:      "tigera.io.report",
 			Namespace: ComplianceNamespace,
 			Labels: map[string]string{
-				"k8s-app": "compliance-reporter",
+				"k8s-app": ComplianceReporterName,
 			},
 		},
 		Template: corev1.PodTemplateSpec{
@@ -501,7 +725,7 @@ func (c *complianceComponent) complianceReporterPodTemplate() *corev1.PodTemplat
 				Name:      "tigera.io.report",
 				Namespace: ComplianceNamespace,
 				Labels: map[string]string{
-					"k8s-app": "compliance-reporter",
+					"k8s-app": ComplianceReporterName,
 				},
 			},
 			Spec: corev1.PodSpec{
@@ -512,13 +736,11 @@ func (c *complianceComponent) complianceReporterPodTemplate() *corev1.PodTemplat
 				Containers: []corev1.Container{
 					relasticsearch.ContainerDecorateIndexCreator(
 						relasticsearch.ContainerDecorate(corev1.Container{
-							Name:          "reporter",
-							Image:         c.reporterImage,
-							Env:           envVars,
-							LivenessProbe: complianceLivenessProbe,
-							SecurityContext: &corev1.SecurityContext{
-								Privileged: &privileged,
-							},
+							Name:            "reporter",
+							Image:           c.reporterImage,
+							Env:             envVars,
+							LivenessProbe:   complianceLivenessProbe,
+							SecurityContext: podsecuritycontext.NewRootContext(privileged),
 							VolumeMounts: []corev1.VolumeMount{
 								{MountPath: "/var/log/calico", Name: "var-log-calico"},
 								c.cfg.TrustedBundle.VolumeMount(c.SupportedOSType()),
@@ -551,6 +773,171 @@ func (c *complianceComponent) complianceReporterPodSecurityPolicy() *policyv1bet
 	return psp
 }
 
+func (c *complianceComponent) complianceReportExporterServiceAccount() *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ComplianceReportExporterSAName, Namespace: ComplianceNamespace},
+	}
+}
+
+func (c *complianceComponent) complianceReportExporterClusterRole() *rbacv1.ClusterRole {
+	rules := []rbacv1.PolicyRule{
+		{
+			// The compliance server only lists and serves the reports that the requester has access to.
+			APIGroups: []string{"projectcalico.org"},
+			Resources: []string{"globalreports"},
+			Verbs:     []string{"get", "list"},
+		},
+		{
+			APIGroups: []string{"projectcalico.org"},
+			Resources: []string{"globalreporttypes"},
+			Verbs:     []string{"get"},
+		},
+	}
+
+	if !c.cfg.Openshift {
+		// Allow access to the pod security policy in case this is enforced on the cluster
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{"policy"},
+			Resources:     []string{"podsecuritypolicies"},
+			Verbs:         []string{"use"},
+			ResourceNames: []string{ComplianceReportExporterName},
+		})
+	}
+	return &rbacv1.ClusterRole{
+		TypeMeta:   metav1.TypeMeta{Kind: "ClusterRole", APIVersion: "rbac.authorization.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ComplianceReportExporterSAName},
+		Rules:      rules,
+	}
+}
+
+func (c *complianceComponent) complianceReportExporterClusterRoleBinding() *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		TypeMeta:   metav1.TypeMeta{Kind: "ClusterRoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ComplianceReportExporterSAName},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     ComplianceReportExporterSAName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      ComplianceReportExporterSAName,
+				Namespace: ComplianceNamespace,
+			},
+		},
+	}
+}
+
+func (c *complianceComponent) complianceReportExportCredentials() *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ComplianceReportExportCredentialsSecret, Namespace: ComplianceNamespace},
+		Data:       c.cfg.ReportExportCredentials.Data,
+	}
+}
+
+func (c *complianceComponent) complianceReportExporterCronJob() *batchv1beta.CronJob {
+	export := c.reportExport()
+	schedule := export.Schedule
+	if schedule == "" {
+		schedule = DefaultComplianceReportExportSchedule
+	}
+
+	args := []string{
+		"--export-compliance-reports",
+		fmt.Sprintf("--export-server-url=https://%s.%s.svc.%s", ComplianceServiceName, ComplianceNamespace, c.cfg.ClusterDomain),
+		fmt.Sprintf("--export-ca-file=%s", c.cfg.TrustedBundle.MountPath()),
+	}
+	var env []corev1.EnvVar
+	annotations := c.cfg.TrustedBundle.HashAnnotations()
+	volumeMounts := []corev1.VolumeMount{c.cfg.TrustedBundle.VolumeMount(c.SupportedOSType())}
+	volumes := []corev1.Volume{c.cfg.TrustedBundle.Volume()}
+
+	if s3 := export.S3; s3 != nil {
+		args = append(args, fmt.Sprintf("--export-s3-bucket=%s", s3.Bucket))
+		if s3.Endpoint != "" {
+			args = append(args, fmt.Sprintf("--export-s3-endpoint=%s", s3.Endpoint))
+		}
+		if s3.Region != "" {
+			args = append(args, fmt.Sprintf("--export-s3-region=%s", s3.Region))
+		}
+		if s3.Path != "" {
+			args = append(args, fmt.Sprintf("--export-s3-path=%s", s3.Path))
+		}
+		if s3.ForcePathStyle {
+			args = append(args, "--export-s3-force-path-style")
+		}
+		env = append(env,
+			corev1.EnvVar{Name: "AWS_ACCESS_KEY_ID", ValueFrom: secret.GetEnvVarSource(ComplianceReportExportCredentialsSecret, "access-key-id", false)},
+			corev1.EnvVar{Name: "AWS_SECRET_ACCESS_KEY", ValueFrom: secret.GetEnvVarSource(ComplianceReportExportCredentialsSecret, "secret-access-key", false)},
+		)
+		if c.cfg.ReportExportCredentials != nil {
+			annotations["hash.operator.tigera.io/report-export-credentials"] = rmeta.AnnotationHash(c.cfg.ReportExportCredentials.Data)
+		}
+	}
+	if pvc := export.PersistentVolumeClaim; pvc != nil {
+		args = append(args, fmt.Sprintf("--export-dir=%s", path.Join(complianceReportExportMountPath, pvc.Path)))
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: complianceReportExportVolumeName, MountPath: complianceReportExportMountPath})
+		volumes = append(volumes, corev1.Volume{
+			Name: complianceReportExportVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.ClaimName},
+			},
+		})
+	}
+
+	return &batchv1beta.CronJob{
+		TypeMeta: metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1beta1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ComplianceReportExporterName,
+			Namespace: ComplianceNamespace,
+		},
+		Spec: batchv1beta.CronJobSpec{
+			Schedule: schedule,
+			// An export that is still running covers the reports that the next one would export.
+			ConcurrencyPolicy: batchv1beta.ForbidConcurrent,
+			JobTemplate: batchv1beta.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   ComplianceReportExporterName,
+					Labels: map[string]string{"k8s-app": ComplianceReportExporterName},
+				},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels:      map[string]string{"k8s-app": ComplianceReportExporterName},
+							Annotations: annotations,
+						},
+						Spec: corev1.PodSpec{
+							ServiceAccountName: ComplianceReportExporterSAName,
+							Tolerations:        append(c.cfg.Installation.ControlPlaneTolerations, rmeta.TolerateMaster),
+							NodeSelector:       c.cfg.Installation.ControlPlaneNodeSelector,
+							ImagePullSecrets:   secret.GetReferenceList(c.cfg.PullSecrets),
+							RestartPolicy:      corev1.RestartPolicyOnFailure,
+							Containers: []corev1.Container{{
+								Name:            ComplianceReportExporterName,
+								Image:           c.exporterImage,
+								Args:            args,
+								Env:             env,
+								SecurityContext: podsecuritycontext.NewBaseContext(),
+								VolumeMounts:    volumeMounts,
+							}},
+							Volumes: volumes,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (c *complianceComponent) complianceReportExporterPodSecurityPolicy() *policyv1beta1.PodSecurityPolicy {
+	psp := podsecuritypolicy.NewBasePolicy()
+	psp.GetObjectMeta().SetName(ComplianceReportExporterName)
+	return psp
+}
+
 func (c *complianceComponent) complianceServerServiceAccount() *corev1.ServiceAccount {
 	return &corev1.ServiceAccount{
 		TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
@@ -652,7 +1039,7 @@ func (c *complianceComponent) complianceServerService() *corev1.Service {
 
 func (c *complianceComponent) complianceServerDeployment() *appsv1.Deployment {
 	envVars := []corev1.EnvVar{
-		{Name: "LOG_LEVEL", Value: "info"},
+		{Name: "LOG_LEVEL", Value: loglevel.Logrus(c.cfg.LogLevel, "info")},
 		{Name: "TIGERA_COMPLIANCE_JOB_NAMESPACE", Value: ComplianceNamespace},
 		{Name: "MULTI_CLUSTER_FORWARDING_CA", Value: certificatemanagement.TrustedCertBundleMountPath},
 	}
@@ -678,9 +1065,10 @@ func (c *complianceComponent) complianceServerDeployment() *appsv1.Deployment {
 			InitContainers:     initContainers,
 			Containers: []corev1.Container{
 				relasticsearch.ContainerDecorate(corev1.Container{
-					Name:  ComplianceServerName,
-					Image: c.serverImage,
-					Env:   envVars,
+					Name:            ComplianceServerName,
+					Image:           c.serverImage,
+					Env:             envVars,
+					SecurityContext: podsecuritycontext.NewBaseContext(),
 					LivenessProbe: &corev1.Probe{
 						Handler: corev1.Handler{
 							HTTPGet: &corev1.HTTPGetAction{
@@ -833,11 +1221,20 @@ func (c *complianceComponent) complianceSnapshotterClusterRoleBinding() *rbacv1.
 
 func (c *complianceComponent) complianceSnapshotterDeployment() *appsv1.Deployment {
 	envVars := []corev1.EnvVar{
-		{Name: "LOG_LEVEL", Value: "info"},
+		{Name: "LOG_LEVEL", Value: loglevel.Logrus(c.cfg.LogLevel, "info")},
 		{Name: "TIGERA_COMPLIANCE_JOB_NAMESPACE", Value: ComplianceNamespace},
 		{Name: "TIGERA_COMPLIANCE_MAX_FAILED_JOBS_HISTORY", Value: "3"},
-		{Name: "TIGERA_COMPLIANCE_SNAPSHOT_HOUR", Value: "0"},
 	}
+	snapshotHour := int32(0)
+	if snapshotter := c.spec().Snapshotter; snapshotter != nil {
+		if snapshotter.Hour != nil {
+			snapshotHour = *snapshotter.Hour
+		}
+		if snapshotter.Interval != nil {
+			envVars = append(envVars, corev1.EnvVar{Name: "TIGERA_COMPLIANCE_SNAPSHOT_INTERVAL", Value: snapshotter.Interval.Duration.String()})
+		}
+	}
+	envVars = append(envVars, corev1.EnvVar{Name: "TIGERA_COMPLIANCE_SNAPSHOT_HOUR", Value: fmt.Sprint(snapshotHour)})
 
 	podTemplate := relasticsearch.DecorateAnnotations(&corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
@@ -852,10 +1249,11 @@ func (c *complianceComponent) complianceSnapshotterDeployment() *appsv1.Deployme
 			Containers: []corev1.Container{
 				relasticsearch.ContainerDecorateIndexCreator(
 					relasticsearch.ContainerDecorate(corev1.Container{
-						Name:          ComplianceSnapshotterName,
-						Image:         c.snapshotterImage,
-						Env:           envVars,
-						LivenessProbe: complianceLivenessProbe,
+						Name:            ComplianceSnapshotterName,
+						Image:           c.snapshotterImage,
+						Env:             envVars,
+						LivenessProbe:   complianceLivenessProbe,
+						SecurityContext: podsecuritycontext.NewBaseContext(),
 						VolumeMounts: []corev1.VolumeMount{
 							c.cfg.TrustedBundle.VolumeMount(c.SupportedOSType()),
 						},
@@ -948,7 +1346,7 @@ func (c *complianceComponent) complianceBenchmarkerClusterRoleBinding() *rbacv1.
 
 func (c *complianceComponent) complianceBenchmarkerDaemonSet() *appsv1.DaemonSet {
 	envVars := []corev1.EnvVar{
-		{Name: "LOG_LEVEL", Value: "info"},
+		{Name: "LOG_LEVEL", Value: loglevel.Logrus(c.cfg.LogLevel, "info")},
 		{Name: "NODENAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
 	}
 
@@ -995,6 +1393,15 @@ func (c *complianceComponent) complianceBenchmarkerDaemonSet() *appsv1.DaemonSet
 		})
 	}
 
+	tolerations := rmeta.TolerateAll
+	var nodeSelector map[string]string
+	if benchmarker := c.spec().Benchmarker; benchmarker != nil {
+		if benchmarker.Tolerations != nil {
+			tolerations = benchmarker.Tolerations
+		}
+		nodeSelector = benchmarker.NodeSelector
+	}
+
 	podTemplate := relasticsearch.DecorateAnnotations(&corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "compliance-benchmarker",
@@ -1003,16 +1410,18 @@ func (c *complianceComponent) complianceBenchmarkerDaemonSet() *appsv1.DaemonSet
 		Spec: corev1.PodSpec{
 			ServiceAccountName: "tigera-compliance-benchmarker",
 			HostPID:            true,
-			Tolerations:        rmeta.TolerateAll,
+			NodeSelector:       nodeSelector,
+			Tolerations:        tolerations,
 			ImagePullSecrets:   secret.GetReferenceList(c.cfg.PullSecrets),
 			Containers: []corev1.Container{
 				relasticsearch.ContainerDecorateIndexCreator(
 					relasticsearch.ContainerDecorate(corev1.Container{
-						Name:          "compliance-benchmarker",
-						Image:         c.benchmarkerImage,
-						Env:           envVars,
-						VolumeMounts:  volMounts,
-						LivenessProbe: complianceLivenessProbe,
+						Name:            "compliance-benchmarker",
+						Image:           c.benchmarkerImage,
+						Env:             envVars,
+						VolumeMounts:    volMounts,
+						LivenessProbe:   complianceLivenessProbe,
+						SecurityContext: podsecuritycontext.NewRootContext(false),
 					}, c.cfg.ESClusterConfig.ClusterName(), ElasticsearchComplianceBenchmarkerUserSecret, c.cfg.ClusterDomain, c.SupportedOSType()), c.cfg.ESClusterConfig.Replicas(), c.cfg.ESClusterConfig.Shards(),
 				),
 			},
@@ -16,10 +16,12 @@ package render_test
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
 	"github.com/tigera/operator/pkg/common"
@@ -28,12 +30,15 @@ import (
 	"github.com/tigera/operator/pkg/render"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
 	"github.com/tigera/operator/pkg/tls"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1beta "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -82,6 +87,36 @@ var _ = Describe("compliance rendering tests", func() {
 		}
 	})
 
+	It("should allow the manager to reach the compliance server when the allow-tigera policies are enabled", func() {
+		cfg.NetworkPolicyState = networkpolicy.StateEnabled
+		component, err := render.Compliance(cfg)
+		Expect(err).ShouldNot(HaveOccurred())
+		resources, toDelete := component.Objects()
+		Expect(toDelete).NotTo(ContainElement(BeAssignableToTypeOf(&v3.NetworkPolicy{})))
+
+		Expect(rtest.GetResource(resources, networkpolicy.DefaultDenyPolicyName, ns, "projectcalico.org", "v3", "NetworkPolicy")).NotTo(BeNil())
+		for _, name := range []string{"compliance-controller", "compliance-snapshotter", "compliance-benchmarker", "compliance-reporter"} {
+			Expect(rtest.GetResource(resources, "allow-tigera."+name, ns, "projectcalico.org", "v3", "NetworkPolicy")).NotTo(BeNil())
+		}
+		policy := rtest.GetResource(resources, "allow-tigera.compliance-server", ns, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+		Expect(policy.Spec.Ingress).To(ConsistOf(v3.Rule{
+			Action:      v3.Allow,
+			Protocol:    &networkpolicy.TCPProtocol,
+			Source:      networkpolicy.ManagerSourceEntityRule,
+			Destination: v3.EntityRule{Ports: networkpolicy.Ports(5443)},
+		}))
+		Expect(policy.Spec.Egress).To(ContainElement(networkpolicy.AllowTCPRule(networkpolicy.ESGatewayEntityRule)))
+	})
+
+	It("should delete the allow-tigera policies when they are disabled", func() {
+		cfg.NetworkPolicyState = networkpolicy.StateDisabled
+		component, err := render.Compliance(cfg)
+		Expect(err).ShouldNot(HaveOccurred())
+		resources, toDelete := component.Objects()
+		Expect(rtest.GetResource(resources, "allow-tigera.compliance-server", ns, "projectcalico.org", "v3", "NetworkPolicy")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, "allow-tigera.compliance-server", ns, "projectcalico.org", "v3", "NetworkPolicy")).NotTo(BeNil())
+	})
+
 	Context("Standalone cluster", func() {
 		It("should render all resources for a default configuration", func() {
 			component, err := render.Compliance(cfg)
@@ -415,6 +450,103 @@ var _ = Describe("compliance rendering tests", func() {
 		})
 	})
 
+	It("should configure the snapshotter and benchmarker from the Compliance CR", func() {
+		hour := int32(3)
+		t := corev1.Toleration{Key: "foo", Operator: corev1.TolerationOpExists}
+		cfg.Compliance = &operatorv1.Compliance{Spec: operatorv1.ComplianceSpec{
+			Snapshotter: &operatorv1.ComplianceSnapshotter{Interval: &metav1.Duration{Duration: 6 * time.Hour}, Hour: &hour},
+			Benchmarker: &operatorv1.ComplianceBenchmarker{NodeSelector: map[string]string{"cis": "true"}, Tolerations: []corev1.Toleration{t}},
+		}}
+		cfg.RemovedReports = []string{"old-report"}
+		cfg.RemovedReportTypes = []string{"old-type"}
+		component, err := render.Compliance(cfg)
+		Expect(err).ShouldNot(HaveOccurred())
+		resources, toDelete := component.Objects()
+
+		snapshotter := rtest.GetResource(resources, "compliance-snapshotter", ns, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(snapshotter.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "TIGERA_COMPLIANCE_SNAPSHOT_INTERVAL", Value: "6h0m0s"},
+			corev1.EnvVar{Name: "TIGERA_COMPLIANCE_SNAPSHOT_HOUR", Value: "3"},
+		))
+		benchmarker := rtest.GetResource(resources, "compliance-benchmarker", ns, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(benchmarker.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"cis": "true"}))
+		Expect(benchmarker.Spec.Template.Spec.Tolerations).To(Equal([]corev1.Toleration{t}))
+
+		Expect(rtest.GetResource(toDelete, "old-report", "", "projectcalico.org", "v3", "GlobalReport")).NotTo(BeNil())
+		Expect(rtest.GetResource(toDelete, "old-type", "", "projectcalico.org", "v3", "GlobalReportType")).NotTo(BeNil())
+	})
+
+	It("should render the report exporter for a PersistentVolumeClaim, but not on managed clusters", func() {
+		cfg.Compliance = &operatorv1.Compliance{Spec: operatorv1.ComplianceSpec{
+			ReportExport: &operatorv1.ComplianceReportExport{
+				PersistentVolumeClaim: &operatorv1.ComplianceReportExportPVC{ClaimName: "audit-reports", Path: "cluster-a"},
+			},
+		}}
+		component, err := render.Compliance(cfg)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(component.ResolveImages(nil)).To(Succeed())
+		resources, toDelete := component.Objects()
+
+		Expect(rtest.GetResource(resources, "tigera-compliance-report-exporter", ns, "", "v1", "ServiceAccount")).NotTo(BeNil())
+		Expect(rtest.GetResource(resources, "compliance-report-exporter", "", "policy", "v1beta1", "PodSecurityPolicy")).NotTo(BeNil())
+		role := rtest.GetResource(resources, "tigera-compliance-report-exporter", "", rbac, "v1", "ClusterRole").(*rbacv1.ClusterRole)
+		Expect(role.Rules).To(ContainElement(rbacv1.PolicyRule{
+			APIGroups: []string{"projectcalico.org"},
+			Resources: []string{"globalreports"},
+			Verbs:     []string{"get", "list"},
+		}))
+		Expect(rtest.GetResource(resources, "tigera-compliance-report-exporter", "", rbac, "v1", "ClusterRoleBinding")).NotTo(BeNil())
+		cj := rtest.GetResource(resources, "compliance-report-exporter", ns, "batch", "v1beta1", "CronJob").(*batchv1beta.CronJob)
+		Expect(cj.Spec.Schedule).To(Equal(render.DefaultComplianceReportExportSchedule))
+		Expect(cj.Spec.ConcurrencyPolicy).To(Equal(batchv1beta.ForbidConcurrent))
+		pod := cj.Spec.JobTemplate.Spec.Template.Spec
+		Expect(pod.ServiceAccountName).To(Equal("tigera-compliance-report-exporter"))
+		Expect(pod.Containers[0].Image).To(HavePrefix("testregistry.com/tigera/operator:"))
+		Expect(pod.Containers[0].Args).To(Equal([]string{
+			"--export-compliance-reports",
+			"--export-server-url=https://compliance.tigera-compliance.svc.cluster.local",
+			"--export-ca-file=/etc/pki/tls/certs/tigera-ca-bundle.crt",
+			"--export-dir=/report-export/cluster-a",
+		}))
+		Expect(pod.Containers[0].Env).To(BeEmpty())
+		Expect(pod.Volumes).To(ContainElement(corev1.Volume{
+			Name:         "report-export",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "audit-reports"}},
+		}))
+		Expect(rtest.GetResource(toDelete, "tigera-compliance-report-export-credentials", ns, "", "v1", "Secret")).NotTo(BeNil())
+
+		By("not exporting the reports of a managed cluster")
+		cfg.ManagementClusterConnection = &operatorv1.ManagementClusterConnection{}
+		component, err = render.Compliance(cfg)
+		Expect(err).ShouldNot(HaveOccurred())
+		resources, toDelete = component.Objects()
+		Expect(rtest.GetResource(resources, "compliance-report-exporter", ns, "batch", "v1beta1", "CronJob")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, "compliance-report-exporter", ns, "batch", "v1beta1", "CronJob")).NotTo(BeNil())
+		Expect(rtest.GetResource(toDelete, "compliance-report-exporter", "", "policy", "v1beta1", "PodSecurityPolicy")).NotTo(BeNil())
+	})
+
+	It("should allow the report exporter to reach the compliance server and S3", func() {
+		cfg.NetworkPolicyState = networkpolicy.StateEnabled
+		cfg.Compliance = &operatorv1.Compliance{Spec: operatorv1.ComplianceSpec{
+			ReportExport: &operatorv1.ComplianceReportExport{
+				S3: &operatorv1.ComplianceReportExportS3{Bucket: "reports", Endpoint: "http://minio.minio.svc:9000"},
+			},
+		}}
+		component, err := render.Compliance(cfg)
+		Expect(err).ShouldNot(HaveOccurred())
+		resources, _ := component.Objects()
+
+		server := rtest.GetResource(resources, "allow-tigera.compliance-server", ns, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+		Expect(server.Spec.Ingress).To(ContainElement(v3.Rule{
+			Action:      v3.Allow,
+			Protocol:    &networkpolicy.TCPProtocol,
+			Source:      networkpolicy.CreateSourceEntityRule(ns, "compliance-report-exporter"),
+			Destination: v3.EntityRule{Ports: networkpolicy.Ports(5443)},
+		}))
+		exporter := rtest.GetResource(resources, "allow-tigera.compliance-report-exporter", ns, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+		Expect(exporter.Spec.Egress).To(ContainElement(networkpolicy.AllowTCPRule(v3.EntityRule{Services: &v3.ServiceMatch{Name: "minio", Namespace: "minio"}})))
+	})
+
 	Context("Certificate management enabled", func() {
 		It("should render init containers and volume changes", func() {
 			ca, _ := tls.MakeCA(rmeta.DefaultOperatorCASignerName())
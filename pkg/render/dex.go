@@ -22,13 +22,17 @@ import (
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	"github.com/tigera/operator/pkg/render/common/podaffinity"
 	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/secret"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	"gopkg.in/yaml.v2"
 
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -43,6 +47,8 @@ const (
 	DexPort          = 5556
 	DexTLSSecretName = "tigera-dex-tls"
 	DexClientId      = "tigera-manager"
+
+	dexManagerURLAnnotation = "hash.operator.tigera.io/manager-url"
 )
 
 func Dex(cfg *DexComponentConfiguration) Component {
@@ -62,6 +68,16 @@ type DexComponentConfiguration struct {
 	ClusterDomain string
 	DeleteDex     bool
 	TLSKeyPair    certificatemanagement.KeyPairInterface
+
+	// ManagerURL is the URL that the manager is exposed at through the Manager CR, if any. Dex redirects users
+	// that log in through it back to it.
+	ManagerURL string
+
+	// Autoscaling, if set, scales the replicas of dex with a HorizontalPodAutoscaler.
+	Autoscaling *operatorv1.Autoscaling
+
+	// NetworkPolicyState determines whether the policies for dex in the allow-tigera tier are created or removed.
+	NetworkPolicyState networkpolicy.State
 }
 
 type dexComponent struct {
@@ -101,14 +117,17 @@ func (*dexComponent) SupportedOSType() rmeta.OSType {
 }
 
 func (c *dexComponent) Objects() ([]client.Object, []client.Object) {
+	deployment := c.deployment()
+	autoscalingObjs, autoscalingObjsToDelete := autoscaling.Objects(deployment, c.cfg.Autoscaling)
 	objs := []client.Object{
 		c.serviceAccount(),
-		c.deployment(),
+		deployment,
 		c.service(),
 		c.clusterRole(),
 		c.clusterRoleBinding(),
 		c.configMap(),
 	}
+	objs = append(objs, autoscalingObjs...)
 
 	// TODO Some of the secrets created in the operator namespace are created by the customer (i.e. oidc credentials)
 	// TODO so we can't just do a blanket delete of the secrets in the operator namespace. We need to refactor
@@ -124,11 +143,15 @@ func (c *dexComponent) Objects() ([]client.Object, []client.Object) {
 		objs = append(objs, certificatemanagement.CSRClusterRoleBinding(DexObjectName, DexNamespace))
 	}
 
+	policies, policiesToDelete := c.cfg.NetworkPolicyState.Split(
+		networkpolicy.AllowTigeraDefaultDeny(DexNamespace),
+		c.networkPolicy(),
+	)
 	if c.cfg.DeleteDex {
-		return nil, objs
+		return nil, append(append(objs, policies...), autoscalingObjsToDelete...)
 	}
 
-	return objs, nil
+	return append(objs, policies...), append(policiesToDelete, autoscalingObjsToDelete...)
 }
 
 func (c *dexComponent) Ready() bool {
@@ -184,13 +207,17 @@ func (c *dexComponent) clusterRoleBinding() client.Object {
 	}
 }
 
-func (c *dexComponent) deployment() client.Object {
+func (c *dexComponent) deployment() *appsv1.Deployment {
 	var initContainers []corev1.Container
 	if c.cfg.TLSKeyPair.UseCertificateManagement() {
 		initContainers = append(initContainers, c.cfg.TLSKeyPair.InitContainer(DexNamespace))
 	}
 	annotations := c.cfg.DexConfig.RequiredAnnotations()
 	annotations[c.cfg.TLSKeyPair.HashAnnotationKey()] = c.cfg.TLSKeyPair.HashAnnotationValue()
+	if c.cfg.ManagerURL != "" {
+		// Dex only reads its configuration on start up.
+		annotations[dexManagerURLAnnotation] = rmeta.AnnotationHash(c.cfg.ManagerURL)
+	}
 	d := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
 		ObjectMeta: metav1.ObjectMeta{
@@ -272,6 +299,19 @@ func (c *dexComponent) service() client.Object {
 	}
 }
 
+// networkPolicy allows the clients of dex to reach it, and dex to reach the upstream identity provider.
+func (c *dexComponent) networkPolicy() *v3.NetworkPolicy {
+	egress := networkpolicy.AllowDNSRules(c.cfg.Openshift)
+	egress = append(egress, networkpolicy.AllowKubeAPIServerRule())
+	for _, address := range c.cfg.DexConfig.UpstreamAddresses() {
+		egress = append(egress, networkpolicy.AllowAddressRule(address))
+	}
+	return networkpolicy.AllowTigeraPolicy(DexObjectName, DexNamespace,
+		networkpolicy.ServiceIngressRules(c.service().(*corev1.Service), v3.EntityRule{}),
+		egress,
+	)
+}
+
 // Perform a HTTP GET to determine if an endpoint is available.
 func (c *dexComponent) probe() *corev1.Probe {
 	return &corev1.Probe{
@@ -311,7 +351,7 @@ func (c *dexComponent) configMap() *corev1.ConfigMap {
 		"staticClients": []map[string]interface{}{
 			{
 				"id":           DexClientId,
-				"redirectURIs": c.cfg.DexConfig.RedirectURIs(),
+				"redirectURIs": c.redirectURIs(),
 				"name":         "Calico Enterprise Manager",
 				"secretEnv":    dexSecretEnv,
 			},
@@ -332,3 +372,28 @@ func (c *dexComponent) configMap() *corev1.ConfigMap {
 		},
 	}
 }
+
+// redirectURIs returns the URIs that dex may redirect users to after they log in, which include those of the URL
+// that the manager is exposed at.
+func (c *dexComponent) redirectURIs() []string {
+	uris := c.cfg.DexConfig.RedirectURIs()
+	if c.cfg.ManagerURL == "" {
+		return uris
+	}
+	for _, uri := range []string{
+		fmt.Sprintf("%s/login/oidc/callback", c.cfg.ManagerURL),
+		fmt.Sprintf("%s/tigera-kibana/api/security/oidc/callback", c.cfg.ManagerURL),
+	} {
+		found := false
+		for _, u := range uris {
+			if u == uri {
+				found = true
+				break
+			}
+		}
+		if !found {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
@@ -84,6 +84,8 @@ type DexConfig interface {
 	RequiredVolumeMounts() []corev1.VolumeMount
 	// RequiredVolumes returns volumes that the KeyValidatorConfig implementation requires.
 	RequiredVolumes() []corev1.Volume
+	// UpstreamAddresses returns the addresses of the identity provider that dex connects to.
+	UpstreamAddresses() []string
 	authentication.KeyValidatorConfig
 }
 
@@ -328,6 +330,21 @@ func (d *dexConfig) RequiredVolumes() []corev1.Volume {
 	return volumes
 }
 
+func (d *dexConfig) UpstreamAddresses() []string {
+	switch d.connectorType {
+	case connectorTypeOIDC:
+		return []string{d.authentication.Spec.OIDC.IssuerURL}
+	case connectorTypeGoogle:
+		// Dex queries the admin directory API for the groups of a user.
+		return []string{googleIssuer, "https://*.googleapis.com"}
+	case connectorTypeOpenshift:
+		return []string{d.authentication.Spec.Openshift.IssuerURL}
+	case connectorTypeLDAP:
+		return []string{d.authentication.Spec.LDAP.Host}
+	}
+	return nil
+}
+
 // AppendDexVolumeMount adds mount for ubi base image trusted cert location
 func (d *dexConfig) RequiredVolumeMounts() []corev1.VolumeMount {
 	volumeMounts := []corev1.VolumeMount{
@@ -29,11 +29,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/dns"
 	"github.com/tigera/operator/pkg/render"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	"github.com/tigera/operator/pkg/render/common/podaffinity"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
 )
@@ -160,6 +162,56 @@ var _ = Describe("dex rendering tests", func() {
 			Entry("custom cluster domain", "custom.internal"),
 		)
 
+		It("should allow dex to reach the identity provider when the allow-tigera policies are enabled", func() {
+			cfg.NetworkPolicyState = networkpolicy.StateEnabled
+			component := render.Dex(cfg)
+			resources, toDelete := component.Objects()
+			// Only the HorizontalPodAutoscaler of dex, which is not configured, is deleted.
+			Expect(toDelete).To(HaveLen(1))
+			Expect(rtest.GetResource(toDelete, render.DexObjectName, render.DexNamespace, "autoscaling", "v2beta2", "HorizontalPodAutoscaler")).NotTo(BeNil())
+
+			Expect(rtest.GetResource(resources, networkpolicy.DefaultDenyPolicyName, render.DexNamespace, "projectcalico.org", "v3", "NetworkPolicy")).NotTo(BeNil())
+			policy := rtest.GetResource(resources, "allow-tigera.tigera-dex", render.DexNamespace, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+			Expect(policy.Spec.Ingress).To(ConsistOf(v3.Rule{
+				Action:      v3.Allow,
+				Protocol:    &networkpolicy.TCPProtocol,
+				Destination: v3.EntityRule{Ports: networkpolicy.Ports(5556)},
+			}))
+			Expect(policy.Spec.Egress).To(ContainElement(v3.Rule{
+				Action:   v3.Allow,
+				Protocol: &networkpolicy.TCPProtocol,
+				Destination: v3.EntityRule{
+					Domains: []string{"example.com"},
+					Ports:   networkpolicy.Ports(443),
+				},
+			}))
+		})
+
+		It("should delete the allow-tigera policies when dex is removed", func() {
+			cfg.NetworkPolicyState = networkpolicy.StateEnabled
+			cfg.DeleteDex = true
+			component := render.Dex(cfg)
+			resources, toDelete := component.Objects()
+			Expect(resources).To(BeEmpty())
+			Expect(rtest.GetResource(toDelete, "allow-tigera.tigera-dex", render.DexNamespace, "projectcalico.org", "v3", "NetworkPolicy")).NotTo(BeNil())
+		})
+
+		It("should redirect to the URL that the manager is exposed at", func() {
+			cfg.ManagerURL = "https://manager.example.org"
+			component := render.Dex(cfg)
+			resources, _ := component.Objects()
+
+			cm, ok := rtest.GetResource(resources, render.DexObjectName, render.DexNamespace, "", "v1", "ConfigMap").(*corev1.ConfigMap)
+			Expect(ok).To(BeTrue())
+			Expect(cm.Data["config.yaml"]).To(ContainSubstring("https://example.com/login/oidc/callback"))
+			Expect(cm.Data["config.yaml"]).To(ContainSubstring("https://manager.example.org/login/oidc/callback"))
+			Expect(cm.Data["config.yaml"]).To(ContainSubstring("https://manager.example.org/tigera-kibana/api/security/oidc/callback"))
+
+			d, ok := rtest.GetResource(resources, render.DexObjectName, render.DexNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+			Expect(ok).To(BeTrue())
+			Expect(d.Spec.Template.Annotations).To(HaveKey("hash.operator.tigera.io/manager-url"))
+		})
+
 		It("should apply tolerations", func() {
 			t := corev1.Toleration{
 				Key:      "foo",
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"strconv"
 
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
@@ -29,7 +30,10 @@ import (
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/components"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/podsecuritypolicy"
 	"github.com/tigera/operator/pkg/render/common/resourcequota"
 	"github.com/tigera/operator/pkg/render/common/secret"
@@ -42,12 +46,15 @@ const (
 	FluentdFilterConfigMapName               = "fluentd-filters"
 	FluentdFilterFlowName                    = "flow"
 	FluentdFilterDNSName                     = "dns"
+	FluentdFilterL7Name                      = "l7"
+	FluentdFilterAuditName                   = "audit"
 	S3FluentdSecretName                      = "log-collector-s3-credentials"
 	S3KeyIdName                              = "key-id"
 	S3KeySecretName                          = "key-secret"
 	FluentdPrometheusTLSSecretName           = "tigera-fluentd-prometheus-tls"
 	FluentdMetricsService                    = "fluentd-metrics"
 	FluentdMetricsPort                       = "fluentd-metrics-port"
+	FluentdMetricsPortNumber                 = 9081
 	filterHashAnnotation                     = "hash.operator.tigera.io/fluentd-filters"
 	s3CredentialHashAnnotation               = "hash.operator.tigera.io/s3-credentials"
 	splunkCredentialHashAnnotation           = "hash.operator.tigera.io/splunk-credentials"
@@ -88,8 +95,10 @@ const (
 )
 
 type FluentdFilters struct {
-	Flow string
-	DNS  string
+	Flow  string
+	DNS   string
+	L7    string
+	Audit string
 }
 
 type S3Credential struct {
@@ -112,6 +121,7 @@ func Fluentd(cfg *FluentdConfiguration) Component {
 
 	return &fluentdComponent{
 		cfg:          cfg,
+		filters:      fluentdFilters(cfg),
 		probeTimeout: timeout,
 		probePeriod:  period,
 	}
@@ -141,13 +151,20 @@ type FluentdConfiguration struct {
 	OSType           rmeta.OSType
 	MetricsServerTLS certificatemanagement.KeyPairInterface
 	TrustedBundle    certificatemanagement.TrustedBundle
+	// LogLevel is the log level of fluentd and the log forwarders. The default of the image is used if it is empty.
+	LogLevel operatorv1.LogLevel
 
 	// Whether or not the cluster supports pod security policies.
 	UsePSP bool
+
+	// NetworkPolicyState determines whether the policies for fluentd in the allow-tigera tier are created or removed.
+	NetworkPolicyState networkpolicy.State
 }
 
 type fluentdComponent struct {
-	cfg          *FluentdConfiguration
+	cfg *FluentdConfiguration
+	// filters are the filters of the LogCollector and of the fluentd-filters ConfigMap combined.
+	filters      *FluentdFilters
 	image        string
 	probeTimeout int32
 	probePeriod  int32
@@ -224,7 +241,7 @@ func (c *fluentdComponent) path(path string) string {
 
 func (c *fluentdComponent) Objects() ([]client.Object, []client.Object) {
 	var objs, toDelete []client.Object
-	objs = append(objs, CreateNamespace(LogCollectorNamespace, c.cfg.Installation.KubernetesProvider, PSSPrivileged))
+	objs = append(objs, CreateNamespace(LogCollectorNamespace, c.cfg.Installation, PSSPrivileged))
 	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(LogCollectorNamespace, c.cfg.PullSecrets...)...)...)
 	objs = append(objs, c.metricsService())
 
@@ -241,7 +258,7 @@ func (c *fluentdComponent) Objects() ([]client.Object, []client.Object) {
 	if c.cfg.SplkCredential != nil {
 		objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(LogCollectorNamespace, c.splunkCredentialSecret()...)...)...)
 	}
-	if c.cfg.Filters != nil {
+	if c.filters != nil {
 		objs = append(objs, c.filtersConfigMap())
 	}
 	if c.cfg.EKSConfig != nil && c.cfg.OSType == rmeta.OSTypeLinux {
@@ -274,6 +291,19 @@ func (c *fluentdComponent) Objects() ([]client.Object, []client.Object) {
 	objs = append(objs, c.packetCaptureApiRole(), c.packetCaptureApiRoleBinding())
 	objs = append(objs, c.daemonset())
 
+	var policies []client.Object
+	if c.cfg.OSType == rmeta.OSTypeLinux {
+		// The Linux and Windows components share the namespace, so only one of them renders the default-deny.
+		policies = append(policies, networkpolicy.AllowTigeraDefaultDeny(LogCollectorNamespace))
+		if c.cfg.EKSConfig != nil {
+			policies = append(policies, c.eksLogForwarderNetworkPolicy())
+		}
+	}
+	policies = append(policies, c.networkPolicy())
+	policiesToCreate, policiesToDelete := c.cfg.NetworkPolicyState.Split(policies...)
+	objs = append(objs, policiesToCreate...)
+	toDelete = append(toDelete, policiesToDelete...)
+
 	return objs, toDelete
 }
 
@@ -281,6 +311,50 @@ func (c *fluentdComponent) Ready() bool {
 	return true
 }
 
+// networkPolicy allows Prometheus and the operator, which checks the usage of the buffers, to scrape fluentd and
+// fluentd to ship logs to Elasticsearch, either directly or through guardian in a managed cluster, and to any
+// additional log stores that have been configured.
+func (c *fluentdComponent) networkPolicy() *v3.NetworkPolicy {
+	egress := networkpolicy.AllowDNSRules(c.cfg.Installation.KubernetesProvider == operatorv1.ProviderOpenShift)
+	egress = append(egress,
+		networkpolicy.AllowKubeAPIServerRule(),
+		networkpolicy.AllowTCPRule(networkpolicy.ESGatewayEntityRule),
+		networkpolicy.AllowTCPRule(networkpolicy.GuardianEntityRule),
+	)
+	if stores := c.cfg.LogCollector.Spec.AdditionalStores; stores != nil {
+		if stores.S3 != nil {
+			egress = append(egress, networkpolicy.AllowTCPRule(v3.EntityRule{
+				Domains: []string{"*.amazonaws.com"},
+				Ports:   networkpolicy.Ports(443),
+			}))
+		}
+		if stores.Syslog != nil {
+			egress = append(egress, networkpolicy.AllowAddressRule(stores.Syslog.Endpoint))
+		}
+		if stores.Splunk != nil {
+			egress = append(egress, networkpolicy.AllowAddressRule(stores.Splunk.Endpoint))
+		}
+	}
+	ingress := networkpolicy.ServiceIngressRules(c.metricsService(), networkpolicy.PrometheusSourceEntityRule)
+	ingress = append(ingress, networkpolicy.ServiceIngressRules(c.metricsService(), networkpolicy.OperatorSourceEntityRule())...)
+	return networkpolicy.AllowTigeraPolicy(c.fluentdNodeName(), LogCollectorNamespace, ingress, egress)
+}
+
+// eksLogForwarderNetworkPolicy allows the forwarder to read audit logs from CloudWatch and write them to Elasticsearch.
+func (c *fluentdComponent) eksLogForwarderNetworkPolicy() *v3.NetworkPolicy {
+	egress := networkpolicy.AllowDNSRules(false)
+	egress = append(egress,
+		networkpolicy.AllowKubeAPIServerRule(),
+		networkpolicy.AllowTCPRule(networkpolicy.ESGatewayEntityRule),
+		networkpolicy.AllowTCPRule(networkpolicy.GuardianEntityRule),
+		networkpolicy.AllowTCPRule(v3.EntityRule{
+			Domains: []string{"*.amazonaws.com"},
+			Ports:   networkpolicy.Ports(443),
+		}),
+	)
+	return networkpolicy.AllowTigeraPolicy(eksLogForwarderName, LogCollectorNamespace, nil, egress)
+}
+
 func (c *fluentdComponent) fluentdResourceQuota() *corev1.ResourceQuota {
 	criticalPriorityClasses := []string{NodePriorityClassName}
 	return resourcequota.ResourceQuotaForPriorityClassScope(resourcequota.TigeraCriticalResourceQuotaName, LogCollectorNamespace, criticalPriorityClasses)
@@ -304,7 +378,7 @@ func (c *fluentdComponent) s3CredentialSecret() *corev1.Secret {
 }
 
 func (c *fluentdComponent) filtersConfigMap() *corev1.ConfigMap {
-	if c.cfg.Filters == nil {
+	if c.filters == nil {
 		return nil
 	}
 	return &corev1.ConfigMap{
@@ -314,8 +388,10 @@ func (c *fluentdComponent) filtersConfigMap() *corev1.ConfigMap {
 			Namespace: LogCollectorNamespace,
 		},
 		Data: map[string]string{
-			FluentdFilterFlowName: c.cfg.Filters.Flow,
-			FluentdFilterDNSName:  c.cfg.Filters.DNS,
+			FluentdFilterFlowName:  c.filters.Flow,
+			FluentdFilterDNSName:   c.filters.DNS,
+			FluentdFilterL7Name:    c.filters.L7,
+			FluentdFilterAuditName: c.filters.Audit,
 		},
 	}
 }
@@ -428,8 +504,8 @@ func (c *fluentdComponent) daemonset() *appsv1.DaemonSet {
 	if c.cfg.SplkCredential != nil {
 		annots[splunkCredentialHashAnnotation] = rmeta.AnnotationHash(c.cfg.SplkCredential)
 	}
-	if c.cfg.Filters != nil {
-		annots[filterHashAnnotation] = rmeta.AnnotationHash(c.cfg.Filters)
+	if c.filters != nil {
+		annots[filterHashAnnotation] = rmeta.AnnotationHash(c.filters)
 	}
 	var initContainers []corev1.Container
 	if c.cfg.MetricsServerTLS != nil && c.cfg.MetricsServerTLS.UseCertificateManagement() {
@@ -490,8 +566,8 @@ func (c *fluentdComponent) container() corev1.Container {
 		{MountPath: c.path("/var/log/calico"), Name: "var-log-calico"},
 		{MountPath: c.path("/etc/fluentd/elastic"), Name: certificatemanagement.TrustedCertConfigMapName},
 	}
-	if c.cfg.Filters != nil {
-		if c.cfg.Filters.Flow != "" {
+	if c.filters != nil {
+		if c.filters.Flow != "" {
 			volumeMounts = append(volumeMounts,
 				corev1.VolumeMount{
 					Name:      "fluentd-filters",
@@ -499,7 +575,7 @@ func (c *fluentdComponent) container() corev1.Container {
 					SubPath:   FluentdFilterFlowName,
 				})
 		}
-		if c.cfg.Filters.DNS != "" {
+		if c.filters.DNS != "" {
 			volumeMounts = append(volumeMounts,
 				corev1.VolumeMount{
 					Name:      "fluentd-filters",
@@ -507,6 +583,22 @@ func (c *fluentdComponent) container() corev1.Container {
 					SubPath:   FluentdFilterDNSName,
 				})
 		}
+		if c.filters.L7 != "" {
+			volumeMounts = append(volumeMounts,
+				corev1.VolumeMount{
+					Name:      "fluentd-filters",
+					MountPath: c.path("/etc/fluentd/l7-filters.conf"),
+					SubPath:   FluentdFilterL7Name,
+				})
+		}
+		if c.filters.Audit != "" {
+			volumeMounts = append(volumeMounts,
+				corev1.VolumeMount{
+					Name:      "fluentd-filters",
+					MountPath: c.path("/etc/fluentd/audit-filters.conf"),
+					SubPath:   FluentdFilterAuditName,
+				})
+		}
 	}
 
 	if c.cfg.SplkCredential != nil && len(c.cfg.SplkCredential.Certificate) != 0 {
@@ -517,6 +609,14 @@ func (c *fluentdComponent) container() corev1.Container {
 			})
 	}
 
+	if c.bufferVolume() != nil {
+		volumeMounts = append(volumeMounts,
+			corev1.VolumeMount{
+				Name:      FluentdBufferVolumeName,
+				MountPath: c.path(fluentdBufferPath),
+			})
+	}
+
 	volumeMounts = append(volumeMounts, c.cfg.TrustedBundle.VolumeMount(c.SupportedOSType()))
 
 	if c.cfg.MetricsServerTLS != nil {
@@ -533,14 +633,14 @@ func (c *fluentdComponent) container() corev1.Container {
 		Name:            "fluentd",
 		Image:           c.image,
 		Env:             envs,
-		SecurityContext: &corev1.SecurityContext{Privileged: &isPrivileged},
+		SecurityContext: podsecuritycontext.NewRootContext(isPrivileged),
 		VolumeMounts:    volumeMounts,
 		StartupProbe:    c.startup(),
 		LivenessProbe:   c.liveness(),
 		ReadinessProbe:  c.readiness(),
 		Ports: []corev1.ContainerPort{{
 			Name:          "metrics-port",
-			ContainerPort: 9081,
+			ContainerPort: FluentdMetricsPortNumber,
 		}},
 	}, c.cfg.ESClusterConfig.ClusterName(), ElasticsearchLogCollectorUserSecret, c.cfg.ClusterDomain, c.cfg.OSType)
 }
@@ -559,8 +659,8 @@ func (c *fluentdComponent) metricsService() *corev1.Service {
 			Ports: []corev1.ServicePort{
 				{
 					Name:       FluentdMetricsPort,
-					Port:       int32(9081),
-					TargetPort: intstr.FromInt(9081),
+					Port:       int32(FluentdMetricsPortNumber),
+					TargetPort: intstr.FromInt(FluentdMetricsPortNumber),
 					Protocol:   corev1.ProtocolTCP,
 				},
 			},
@@ -576,6 +676,9 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 		{Name: "FLUENTD_ES_SECURE", Value: "true"},
 		{Name: "NODENAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
 	}
+	if c.cfg.LogLevel != "" {
+		envs = append(envs, corev1.EnvVar{Name: "LOG_LEVEL", Value: loglevel.Fluentd(c.cfg.LogLevel, "")})
+	}
 
 	if c.cfg.LogCollector.Spec.AdditionalStores != nil {
 		s3 := c.cfg.LogCollector.Spec.AdditionalStores.S3
@@ -607,7 +710,7 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 				corev1.EnvVar{Name: "S3_BUCKET_NAME", Value: s3.BucketName},
 				corev1.EnvVar{Name: "AWS_REGION", Value: s3.Region},
 				corev1.EnvVar{Name: "S3_BUCKET_PATH", Value: s3.BucketPath},
-				corev1.EnvVar{Name: "S3_FLUSH_INTERVAL", Value: fluentdDefaultFlush},
+				corev1.EnvVar{Name: "S3_FLUSH_INTERVAL", Value: c.flushInterval()},
 			)
 		}
 		syslog := c.cfg.LogCollector.Spec.AdditionalStores.Syslog
@@ -617,7 +720,7 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 				corev1.EnvVar{Name: "SYSLOG_HOST", Value: host},
 				corev1.EnvVar{Name: "SYSLOG_PORT", Value: port},
 				corev1.EnvVar{Name: "SYSLOG_PROTOCOL", Value: proto},
-				corev1.EnvVar{Name: "SYSLOG_FLUSH_INTERVAL", Value: fluentdDefaultFlush},
+				corev1.EnvVar{Name: "SYSLOG_FLUSH_INTERVAL", Value: c.flushInterval()},
 				corev1.EnvVar{
 					Name: "SYSLOG_HOSTNAME",
 					ValueFrom: &corev1.EnvVarSource{
@@ -683,7 +786,7 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 				corev1.EnvVar{Name: "SPLUNK_HEC_HOST", Value: host},
 				corev1.EnvVar{Name: "SPLUNK_HEC_PORT", Value: port},
 				corev1.EnvVar{Name: "SPLUNK_PROTOCOL", Value: proto},
-				corev1.EnvVar{Name: "SPLUNK_FLUSH_INTERVAL", Value: fluentdDefaultFlush},
+				corev1.EnvVar{Name: "SPLUNK_FLUSH_INTERVAL", Value: c.flushInterval()},
 			)
 			if len(c.cfg.SplkCredential.Certificate) != 0 {
 				envs = append(envs,
@@ -693,15 +796,25 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 		}
 	}
 
-	if c.cfg.Filters != nil {
-		if c.cfg.Filters.Flow != "" {
+	envs = append(envs, c.bufferEnvVars()...)
+
+	if c.filters != nil {
+		if c.filters.Flow != "" {
 			envs = append(envs,
 				corev1.EnvVar{Name: "FLUENTD_FLOW_FILTERS", Value: "true"})
 		}
-		if c.cfg.Filters.DNS != "" {
+		if c.filters.DNS != "" {
 			envs = append(envs,
 				corev1.EnvVar{Name: "FLUENTD_DNS_FILTERS", Value: "true"})
 		}
+		if c.filters.L7 != "" {
+			envs = append(envs,
+				corev1.EnvVar{Name: "FLUENTD_L7_FILTERS", Value: "true"})
+		}
+		if c.filters.Audit != "" {
+			envs = append(envs,
+				corev1.EnvVar{Name: "FLUENTD_AUDIT_FILTERS", Value: "true"})
+		}
 	}
 
 	envs = append(envs,
@@ -783,7 +896,7 @@ func (c *fluentdComponent) volumes() []corev1.Volume {
 			},
 		},
 	}
-	if c.cfg.Filters != nil {
+	if c.filters != nil {
 		volumes = append(volumes,
 			corev1.Volume{
 				Name: "fluentd-filters",
@@ -811,6 +924,9 @@ func (c *fluentdComponent) volumes() []corev1.Volume {
 				},
 			})
 	}
+	if v := c.bufferVolume(); v != nil {
+		volumes = append(volumes, *v)
+	}
 	if c.cfg.MetricsServerTLS != nil {
 		volumes = append(volumes, c.cfg.MetricsServerTLS.Volume())
 	}
@@ -905,7 +1021,7 @@ func (c *fluentdComponent) eksLogForwarderDeployment() *appsv1.Deployment {
 
 	envVars := []corev1.EnvVar{
 		// Meta flags.
-		{Name: "LOG_LEVEL", Value: "info"},
+		{Name: "LOG_LEVEL", Value: loglevel.Fluentd(c.cfg.LogLevel, "info")},
 		{Name: "FLUENT_UID", Value: "0"},
 		// Use fluentd for EKS log forwarder.
 		{Name: "MANAGED_K8S", Value: "true"},
@@ -0,0 +1,174 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+const (
+	FluentdBufferVolumeName = "fluentd-buffers"
+	fluentdBufferPath       = "/var/lib/fluentd/buffers"
+)
+
+// fluentdOverflowActions maps the overflow actions of the LogCollector to those of fluentd.
+var fluentdOverflowActions = map[operatorv1.FluentdBufferOverflowAction]string{
+	operatorv1.FluentdBufferOverflowThrowException:  "throw_exception",
+	operatorv1.FluentdBufferOverflowBlock:           "block",
+	operatorv1.FluentdBufferOverflowDropOldestChunk: "drop_oldest_chunk",
+}
+
+func (c *fluentdComponent) buffer() *operatorv1.FluentdBufferSpec {
+	return c.cfg.LogCollector.Spec.Buffer
+}
+
+// flushInterval returns the flush interval of the outputs.
+func (c *fluentdComponent) flushInterval() string {
+	if b := c.buffer(); b != nil && b.FlushInterval != nil {
+		return fluentdDuration(b.FlushInterval)
+	}
+	return fluentdDefaultFlush
+}
+
+// bufferEnvVars returns the environment variables that configure the buffers of the outputs. The image uses its
+// defaults for those that are not set.
+func (c *fluentdComponent) bufferEnvVars() []corev1.EnvVar {
+	b := c.buffer()
+	if b == nil {
+		return nil
+	}
+	var envs []corev1.EnvVar
+	if b.FlushInterval != nil {
+		envs = append(envs, corev1.EnvVar{Name: "FLUENTD_FLUSH_INTERVAL", Value: fluentdDuration(b.FlushInterval)})
+	}
+	if b.ChunkLimitSize != nil {
+		envs = append(envs, corev1.EnvVar{Name: "FLUENTD_CHUNK_LIMIT_SIZE", Value: strconv.FormatInt(b.ChunkLimitSize.Value(), 10)})
+	}
+	if b.TotalLimitSize != nil {
+		envs = append(envs, corev1.EnvVar{Name: "FLUENTD_TOTAL_LIMIT_SIZE", Value: strconv.FormatInt(b.TotalLimitSize.Value(), 10)})
+	}
+	if b.RetryMaxInterval != nil {
+		envs = append(envs, corev1.EnvVar{Name: "FLUENTD_RETRY_MAX_INTERVAL", Value: fluentdDuration(b.RetryMaxInterval)})
+	}
+	if b.RetryTimeout != nil {
+		envs = append(envs, corev1.EnvVar{Name: "FLUENTD_RETRY_TIMEOUT", Value: fluentdDuration(b.RetryTimeout)})
+	}
+	if b.RetryForever != nil {
+		envs = append(envs, corev1.EnvVar{Name: "FLUENTD_RETRY_FOREVER", Value: strconv.FormatBool(*b.RetryForever)})
+	}
+	if b.OverflowAction != nil {
+		envs = append(envs, corev1.EnvVar{Name: "FLUENTD_OVERFLOW_ACTION", Value: fluentdOverflowActions[*b.OverflowAction]})
+	}
+	if b.Storage != nil {
+		envs = append(envs,
+			corev1.EnvVar{Name: "FLUENTD_BUFFER_TYPE", Value: "file"},
+			corev1.EnvVar{Name: "FLUENTD_BUFFER_PATH", Value: c.path(fluentdBufferPath)},
+		)
+	}
+	return envs
+}
+
+// bufferVolume returns the volume of the file-backed buffers, or nil if the logs are buffered in memory.
+func (c *fluentdComponent) bufferVolume() *corev1.Volume {
+	b := c.buffer()
+	if b == nil || b.Storage == nil {
+		return nil
+	}
+	if b.Storage.Type == operatorv1.FluentdBufferStorageEmptyDir {
+		return &corev1.Volume{
+			Name: FluentdBufferVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: b.Storage.SizeLimit},
+			},
+		}
+	}
+	dirOrCreate := corev1.HostPathDirectoryOrCreate
+	return &corev1.Volume{
+		Name: FluentdBufferVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				// The buffers are kept under the log directory, which the pod security policy already allows.
+				Path: c.volumeHostPath() + "/fluentd-buffers",
+				Type: &dirOrCreate,
+			},
+		},
+	}
+}
+
+// fluentdDuration formats a duration in whole seconds, which fluentd reads as a time.
+func fluentdDuration(d *metav1.Duration) string {
+	return fmt.Sprintf("%ds", int64(d.Duration/time.Second))
+}
+
+// ValidateLogCollectorBuffer validates the buffer configuration of the LogCollector.
+func ValidateLogCollectorBuffer(buffer *operatorv1.FluentdBufferSpec) error {
+	if buffer == nil {
+		return nil
+	}
+	for _, d := range []struct {
+		name string
+		d    *metav1.Duration
+	}{
+		{"flushInterval", buffer.FlushInterval},
+		{"retryMaxInterval", buffer.RetryMaxInterval},
+		{"retryTimeout", buffer.RetryTimeout},
+	} {
+		if d.d != nil && d.d.Duration < time.Second {
+			return fmt.Errorf("%s must be at least 1s", d.name)
+		}
+	}
+	if buffer.ChunkLimitSize != nil && buffer.ChunkLimitSize.Sign() <= 0 {
+		return fmt.Errorf("chunkLimitSize must be positive")
+	}
+	if buffer.TotalLimitSize != nil && buffer.TotalLimitSize.Sign() <= 0 {
+		return fmt.Errorf("totalLimitSize must be positive")
+	}
+	if buffer.ChunkLimitSize != nil && buffer.TotalLimitSize != nil && buffer.ChunkLimitSize.Cmp(*buffer.TotalLimitSize) > 0 {
+		return fmt.Errorf("chunkLimitSize %s is larger than totalLimitSize %s", buffer.ChunkLimitSize.String(), buffer.TotalLimitSize.String())
+	}
+	if buffer.RetryForever != nil && *buffer.RetryForever && buffer.RetryTimeout != nil {
+		return fmt.Errorf("retryTimeout cannot be set when retryForever is true")
+	}
+	if buffer.OverflowAction != nil {
+		if _, ok := fluentdOverflowActions[*buffer.OverflowAction]; !ok {
+			return fmt.Errorf("overflowAction %s is not supported", *buffer.OverflowAction)
+		}
+	}
+	if s := buffer.Storage; s != nil {
+		switch s.Type {
+		case operatorv1.FluentdBufferStorageHostPath:
+			if s.SizeLimit != nil {
+				return fmt.Errorf("sizeLimit is only supported for EmptyDir buffer storage")
+			}
+		case operatorv1.FluentdBufferStorageEmptyDir:
+			if s.SizeLimit != nil && buffer.TotalLimitSize != nil && s.SizeLimit.Cmp(*buffer.TotalLimitSize) < 0 {
+				return fmt.Errorf("sizeLimit %s is smaller than totalLimitSize %s", s.SizeLimit.String(), buffer.TotalLimitSize.String())
+			}
+		default:
+			return fmt.Errorf("buffer storage type %s is not supported", s.Type)
+		}
+	}
+	if t := buffer.DegradedThresholdPercent; t != nil && (*t < 1 || *t > 100) {
+		return fmt.Errorf("degradedThresholdPercent must be between 1 and 100")
+	}
+	return nil
+}
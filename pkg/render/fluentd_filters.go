@@ -0,0 +1,282 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+const defaultRedactionReplacement = "[REDACTED]"
+
+// logFilterType describes how the filters of the LogCollector apply to one type of log.
+type logFilterType struct {
+	name string
+	// tag is the fluentd tag of the logs.
+	tag string
+	// namespaceKeys are the record accessors of the namespaces of the endpoints of a log.
+	namespaceKeys []string
+	// labelMatchers return the record accessors and patterns that match an endpoint of a log having a label.
+	// Nil if the logs have no labels.
+	labelMatchers func(key, value string) [][2]string
+}
+
+var (
+	flowLogFilterType = logFilterType{
+		name:          "flows",
+		tag:           "flows",
+		namespaceKeys: []string{"source_namespace", "dest_namespace"},
+		// The labels of flow logs are a list of key=value strings.
+		labelMatchers: func(key, value string) [][2]string {
+			pattern := "/" + escapeSlashes(regexp.QuoteMeta(fmt.Sprintf("%q", key+"="+value))) + "/"
+			return [][2]string{
+				{"$['source_labels']['labels']", pattern},
+				{"$['dest_labels']['labels']", pattern},
+			}
+		},
+	}
+	dnsLogFilterType = logFilterType{
+		name:          "dns",
+		tag:           "dns",
+		namespaceKeys: []string{"client_namespace"},
+		labelMatchers: func(key, value string) [][2]string {
+			return [][2]string{
+				{fmt.Sprintf("$['client_labels']['%s']", key), "/^" + escapeSlashes(regexp.QuoteMeta(value)) + "$/"},
+			}
+		},
+	}
+	l7LogFilterType = logFilterType{
+		name:          "l7",
+		tag:           "l7",
+		namespaceKeys: []string{"src_namespace", "dest_namespace"},
+	}
+	auditLogFilterType = logFilterType{
+		name:          "audit",
+		tag:           "audit.**",
+		namespaceKeys: []string{"$['objectRef']['namespace']"},
+	}
+)
+
+// fluentdFilters returns the filters of each type of log, generated from the filters of the LogCollector followed by
+// the filters of the fluentd-filters ConfigMap. Nil if there are none.
+func fluentdFilters(cfg *FluentdConfiguration) *FluentdFilters {
+	var spec operatorv1.LogCollectorFilters
+	if cfg.LogCollector != nil && cfg.LogCollector.Spec.Filters != nil {
+		spec = *cfg.LogCollector.Spec.Filters
+	}
+	if cfg.Filters == nil && spec.Flows == nil && spec.DNS == nil && spec.L7 == nil && spec.Audit == nil {
+		return nil
+	}
+	raw := cfg.Filters
+	if raw == nil {
+		raw = &FluentdFilters{}
+	}
+	return &FluentdFilters{
+		Flow:  joinFilters(logFilterConfig(flowLogFilterType, spec.Flows), raw.Flow),
+		DNS:   joinFilters(logFilterConfig(dnsLogFilterType, spec.DNS), raw.DNS),
+		L7:    joinFilters(logFilterConfig(l7LogFilterType, spec.L7), raw.L7),
+		Audit: joinFilters(logFilterConfig(auditLogFilterType, spec.Audit), raw.Audit),
+	}
+}
+
+func joinFilters(generated, raw string) string {
+	if generated == "" || raw == "" {
+		return generated + raw
+	}
+	return generated + "\n" + raw
+}
+
+// logFilterConfig renders the fluentd filters of one type of log. Each filter drops or changes the logs
+// independently, so the order of the filters only matters for sampling, which is done last.
+func logFilterConfig(t logFilterType, f *operatorv1.LogFilter) string {
+	if f == nil {
+		return ""
+	}
+	var b strings.Builder
+
+	if len(f.IncludeNamespaces) != 0 {
+		pattern := namespacesPattern(f.IncludeNamespaces)
+		fmt.Fprintf(&b, "<filter %s>\n  @type grep\n  <or>\n", t.tag)
+		for _, key := range t.namespaceKeys {
+			fmt.Fprintf(&b, "    <regexp>\n      key %s\n      pattern %s\n    </regexp>\n", key, pattern)
+		}
+		b.WriteString("  </or>\n</filter>\n")
+	}
+	if len(f.ExcludeNamespaces) != 0 {
+		pattern := namespacesPattern(f.ExcludeNamespaces)
+		fmt.Fprintf(&b, "<filter %s>\n  @type grep\n", t.tag)
+		for _, key := range t.namespaceKeys {
+			fmt.Fprintf(&b, "  <exclude>\n    key %s\n    pattern %s\n  </exclude>\n", key, pattern)
+		}
+		b.WriteString("</filter>\n")
+	}
+	if len(f.IncludeLabels) != 0 && t.labelMatchers != nil {
+		// Every label must be on one of the endpoints, so each label is matched in its own filter.
+		for _, key := range sortedKeys(f.IncludeLabels) {
+			fmt.Fprintf(&b, "<filter %s>\n  @type grep\n  <or>\n", t.tag)
+			for _, m := range t.labelMatchers(key, f.IncludeLabels[key]) {
+				fmt.Fprintf(&b, "    <regexp>\n      key %s\n      pattern %s\n    </regexp>\n", m[0], m[1])
+			}
+			b.WriteString("  </or>\n</filter>\n")
+		}
+	}
+	if len(f.ExcludeLabels) != 0 && t.labelMatchers != nil {
+		fmt.Fprintf(&b, "<filter %s>\n  @type grep\n", t.tag)
+		for _, key := range sortedKeys(f.ExcludeLabels) {
+			for _, m := range t.labelMatchers(key, f.ExcludeLabels[key]) {
+				fmt.Fprintf(&b, "  <exclude>\n    key %s\n    pattern %s\n  </exclude>\n", m[0], m[1])
+			}
+		}
+		b.WriteString("</filter>\n")
+	}
+
+	var removed []string
+	var replaced []operatorv1.LogFieldRedaction
+	for _, r := range f.Redactions {
+		if r.Pattern == "" {
+			removed = append(removed, r.Field)
+		} else {
+			replaced = append(replaced, r)
+		}
+	}
+	if len(replaced) != 0 {
+		fmt.Fprintf(&b, "<filter %s>\n  @type record_transformer\n  enable_ruby true\n  <record>\n", t.tag)
+		for _, r := range replaced {
+			replacement := defaultRedactionReplacement
+			if r.Replacement != nil {
+				replacement = *r.Replacement
+			}
+			// The pattern and replacement are passed base64 encoded, so that no character of theirs can end the
+			// placeholder or the line.
+			fmt.Fprintf(&b, "    %[1]s ${record[\"%[1]s\"].nil? ? nil : record[\"%[1]s\"].to_s.gsub(Regexp.new(%[2]s), %[3]s)}\n",
+				r.Field, rubyBase64String(r.Pattern), rubyBase64String(replacement))
+		}
+		b.WriteString("  </record>\n</filter>\n")
+	}
+	if len(removed) != 0 {
+		fmt.Fprintf(&b, "<filter %s>\n  @type record_transformer\n  remove_keys %s\n</filter>\n", t.tag, strings.Join(removed, ","))
+	}
+
+	if f.SampleRate != nil && *f.SampleRate > 1 {
+		fmt.Fprintf(&b, "<filter %s>\n  @type sampling_filter\n  interval %d\n</filter>\n", t.tag, *f.SampleRate)
+	}
+	return b.String()
+}
+
+func namespacesPattern(namespaces []string) string {
+	quoted := make([]string, len(namespaces))
+	for i, ns := range namespaces {
+		quoted[i] = regexp.QuoteMeta(ns)
+	}
+	return "/^(?:" + strings.Join(quoted, "|") + ")$/"
+}
+
+// escapeSlashes escapes the slashes of a pattern, which fluentd reads between slashes.
+func escapeSlashes(pattern string) string {
+	return strings.ReplaceAll(pattern, "/", `\/`)
+}
+
+// rubyBase64String returns a ruby expression for the UTF-8 string s.
+func rubyBase64String(s string) string {
+	return fmt.Sprintf("'%s'.unpack('m')[0].force_encoding('UTF-8')", base64.StdEncoding.EncodeToString([]byte(s)))
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ValidateLogCollectorFilters validates the filters of the LogCollector, so that only valid fluentd configuration
+// is rolled out to fluentd.
+func ValidateLogCollectorFilters(filters *operatorv1.LogCollectorFilters) error {
+	if filters == nil {
+		return nil
+	}
+	for _, lf := range []struct {
+		t logFilterType
+		f *operatorv1.LogFilter
+	}{
+		{flowLogFilterType, filters.Flows},
+		{dnsLogFilterType, filters.DNS},
+		{l7LogFilterType, filters.L7},
+		{auditLogFilterType, filters.Audit},
+	} {
+		if err := validateLogFilter(lf.t, lf.f); err != nil {
+			return fmt.Errorf("invalid %s filter: %w", lf.t.name, err)
+		}
+	}
+	return nil
+}
+
+var logFieldName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+func validateLogFilter(t logFilterType, f *operatorv1.LogFilter) error {
+	if f == nil {
+		return nil
+	}
+	for _, ns := range append(append([]string{}, f.IncludeNamespaces...), f.ExcludeNamespaces...) {
+		if errs := validation.IsDNS1123Label(ns); len(errs) != 0 {
+			return fmt.Errorf("namespace %q is invalid: %s", ns, strings.Join(errs, ", "))
+		}
+	}
+	if (len(f.IncludeLabels) != 0 || len(f.ExcludeLabels) != 0) && t.labelMatchers == nil {
+		return fmt.Errorf("%s logs cannot be filtered by labels", t.name)
+	}
+	for _, labels := range []map[string]string{f.IncludeLabels, f.ExcludeLabels} {
+		for k, v := range labels {
+			if errs := validation.IsQualifiedName(k); len(errs) != 0 {
+				return fmt.Errorf("label key %q is invalid: %s", k, strings.Join(errs, ", "))
+			}
+			if errs := validation.IsValidLabelValue(v); len(errs) != 0 {
+				return fmt.Errorf("value %q of label %q is invalid: %s", v, k, strings.Join(errs, ", "))
+			}
+		}
+	}
+	if f.SampleRate != nil && *f.SampleRate < 1 {
+		return fmt.Errorf("sampleRate must be at least 1")
+	}
+
+	fields := map[string]bool{}
+	for _, r := range f.Redactions {
+		if !logFieldName.MatchString(r.Field) {
+			return fmt.Errorf("the name of redacted field %q may only contain letters, digits and underscores", r.Field)
+		}
+		if fields[r.Field] {
+			return fmt.Errorf("field %s is redacted more than once", r.Field)
+		}
+		fields[r.Field] = true
+		if r.Pattern == "" {
+			if r.Replacement != nil {
+				return fmt.Errorf("field %s is removed, so it cannot have a replacement", r.Field)
+			}
+			continue
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("the pattern of redacted field %s is invalid: %w", r.Field, err)
+		}
+	}
+	return nil
+}
@@ -15,9 +15,12 @@
 package render_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
 	"github.com/tigera/operator/pkg/common"
@@ -26,10 +29,13 @@ import (
 	"github.com/tigera/operator/pkg/render"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -641,6 +647,218 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 		Expect(envs).ToNot(ContainElement(corev1.EnvVar{Name: "FLUENTD_DNS_FILTERS", Value: "true"}))
 	})
 
+	It("should render the filters of the LogCollector before those of the ConfigMap", func() {
+		rate := int32(10)
+		empty := ""
+		cfg.Filters = &render.FluentdFilters{Flow: "flow-filter\n"}
+		cfg.LogCollector.Spec.Filters = &operatorv1.LogCollectorFilters{
+			Flows: &operatorv1.LogFilter{
+				ExcludeNamespaces: []string{"kube-system", "tigera-fluentd"},
+				IncludeLabels:     map[string]string{"app.kubernetes.io/name": "web"},
+				SampleRate:        &rate,
+			},
+			DNS: &operatorv1.LogFilter{
+				IncludeNamespaces: []string{"prod"},
+				Redactions:        []operatorv1.LogFieldRedaction{{Field: "qname", Pattern: `.*\.internal$`}},
+			},
+			L7: &operatorv1.LogFilter{
+				Redactions: []operatorv1.LogFieldRedaction{
+					{Field: "url", Pattern: `\?.*`, Replacement: &empty},
+					{Field: "user_agent"},
+				},
+			},
+		}
+		Expect(render.ValidateLogCollectorFilters(cfg.LogCollector.Spec.Filters)).To(Succeed())
+
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+		cm := rtest.GetResource(resources, "fluentd-filters", "tigera-fluentd", "", "v1", "ConfigMap").(*corev1.ConfigMap)
+		Expect(cm.Data["flow"]).To(Equal(`<filter flows>
+  @type grep
+  <exclude>
+    key source_namespace
+    pattern /^(?:kube-system|tigera-fluentd)$/
+  </exclude>
+  <exclude>
+    key dest_namespace
+    pattern /^(?:kube-system|tigera-fluentd)$/
+  </exclude>
+</filter>
+<filter flows>
+  @type grep
+  <or>
+    <regexp>
+      key $['source_labels']['labels']
+      pattern /"app\.kubernetes\.io\/name=web"/
+    </regexp>
+    <regexp>
+      key $['dest_labels']['labels']
+      pattern /"app\.kubernetes\.io\/name=web"/
+    </regexp>
+  </or>
+</filter>
+<filter flows>
+  @type sampling_filter
+  interval 10
+</filter>
+
+flow-filter
+`))
+		Expect(cm.Data["dns"]).To(Equal(`<filter dns>
+  @type grep
+  <or>
+    <regexp>
+      key client_namespace
+      pattern /^(?:prod)$/
+    </regexp>
+  </or>
+</filter>
+<filter dns>
+  @type record_transformer
+  enable_ruby true
+  <record>
+    qname ${record["qname"].nil? ? nil : record["qname"].to_s.gsub(Regexp.new('LipcLmludGVybmFsJA=='.unpack('m')[0].force_encoding('UTF-8')), 'W1JFREFDVEVEXQ=='.unpack('m')[0].force_encoding('UTF-8'))}
+  </record>
+</filter>
+`))
+		Expect(cm.Data["l7"]).To(ContainSubstring("url ${record[\"url\"].nil? ? nil : record[\"url\"].to_s.gsub(Regexp.new('XD8uKg=='.unpack('m')[0].force_encoding('UTF-8')), ''.unpack('m')[0].force_encoding('UTF-8'))}"))
+		Expect(cm.Data["l7"]).To(ContainSubstring("remove_keys user_agent\n"))
+		Expect(cm.Data["audit"]).To(BeEmpty())
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		envs := ds.Spec.Template.Spec.Containers[0].Env
+		Expect(envs).To(ContainElements(
+			corev1.EnvVar{Name: "FLUENTD_FLOW_FILTERS", Value: "true"},
+			corev1.EnvVar{Name: "FLUENTD_DNS_FILTERS", Value: "true"},
+			corev1.EnvVar{Name: "FLUENTD_L7_FILTERS", Value: "true"},
+		))
+		Expect(envs).NotTo(ContainElement(corev1.EnvVar{Name: "FLUENTD_AUDIT_FILTERS", Value: "true"}))
+		Expect(ds.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: "fluentd-filters", MountPath: "/etc/fluentd/l7-filters.conf", SubPath: "l7",
+		}))
+	})
+
+	It("should reject invalid filters", func() {
+		Expect(render.ValidateLogCollectorFilters(&operatorv1.LogCollectorFilters{
+			Audit: &operatorv1.LogFilter{IncludeLabels: map[string]string{"app": "web"}},
+		})).To(MatchError("invalid audit filter: audit logs cannot be filtered by labels"))
+		Expect(render.ValidateLogCollectorFilters(&operatorv1.LogCollectorFilters{
+			Flows: &operatorv1.LogFilter{ExcludeNamespaces: []string{"Not_A_Namespace"}},
+		})).To(MatchError(ContainSubstring("invalid flows filter: namespace \"Not_A_Namespace\" is invalid")))
+		Expect(render.ValidateLogCollectorFilters(&operatorv1.LogCollectorFilters{
+			DNS: &operatorv1.LogFilter{Redactions: []operatorv1.LogFieldRedaction{{Field: "qname", Pattern: "(unclosed"}}},
+		})).To(MatchError(ContainSubstring("the pattern of redacted field qname is invalid")))
+		Expect(render.ValidateLogCollectorFilters(&operatorv1.LogCollectorFilters{
+			L7: &operatorv1.LogFilter{Redactions: []operatorv1.LogFieldRedaction{{Field: "url}\n<filter>"}}},
+		})).To(MatchError(ContainSubstring("may only contain letters, digits and underscores")))
+	})
+
+	It("should render with buffer configuration", func() {
+		retryForever := true
+		overflow := operatorv1.FluentdBufferOverflowDropOldestChunk
+		chunkLimit := resource.MustParse("8Mi")
+		totalLimit := resource.MustParse("1Gi")
+		cfg.LogCollector.Spec.AdditionalStores = &operatorv1.AdditionalLogStoreSpec{
+			Syslog: &operatorv1.SyslogStoreSpec{Endpoint: "tcp://1.2.3.4:80"},
+		}
+		cfg.LogCollector.Spec.Buffer = &operatorv1.FluentdBufferSpec{
+			FlushInterval:    &metav1.Duration{Duration: 30 * time.Second},
+			ChunkLimitSize:   &chunkLimit,
+			TotalLimitSize:   &totalLimit,
+			RetryMaxInterval: &metav1.Duration{Duration: 5 * time.Minute},
+			RetryForever:     &retryForever,
+			OverflowAction:   &overflow,
+			Storage:          &operatorv1.FluentdBufferStorage{Type: operatorv1.FluentdBufferStorageHostPath},
+		}
+
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		envs := ds.Spec.Template.Spec.Containers[0].Env
+		Expect(envs).To(ContainElements(
+			corev1.EnvVar{Name: "FLUENTD_FLUSH_INTERVAL", Value: "30s"},
+			corev1.EnvVar{Name: "SYSLOG_FLUSH_INTERVAL", Value: "30s"},
+			corev1.EnvVar{Name: "FLUENTD_CHUNK_LIMIT_SIZE", Value: "8388608"},
+			corev1.EnvVar{Name: "FLUENTD_TOTAL_LIMIT_SIZE", Value: "1073741824"},
+			corev1.EnvVar{Name: "FLUENTD_RETRY_MAX_INTERVAL", Value: "300s"},
+			corev1.EnvVar{Name: "FLUENTD_RETRY_FOREVER", Value: "true"},
+			corev1.EnvVar{Name: "FLUENTD_OVERFLOW_ACTION", Value: "drop_oldest_chunk"},
+			corev1.EnvVar{Name: "FLUENTD_BUFFER_TYPE", Value: "file"},
+			corev1.EnvVar{Name: "FLUENTD_BUFFER_PATH", Value: "/var/lib/fluentd/buffers"},
+		))
+		for _, env := range envs {
+			Expect(env.Name).NotTo(Equal("FLUENTD_RETRY_TIMEOUT"))
+		}
+
+		dirOrCreate := corev1.HostPathDirectoryOrCreate
+		Expect(ds.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: render.FluentdBufferVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: "/var/log/calico/fluentd-buffers", Type: &dirOrCreate},
+			},
+		}))
+		Expect(ds.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      render.FluentdBufferVolumeName,
+			MountPath: "/var/lib/fluentd/buffers",
+		}))
+	})
+
+	It("should allow Prometheus and the operator to scrape fluentd", func() {
+		cfg.NetworkPolicyState = networkpolicy.StateEnabled
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+		policy := rtest.GetResource(resources, "allow-tigera.fluentd-node", "tigera-fluentd", "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+		Expect(policy.Spec.Ingress).To(ConsistOf(
+			v3.Rule{
+				Action:      v3.Allow,
+				Protocol:    &networkpolicy.TCPProtocol,
+				Source:      networkpolicy.PrometheusSourceEntityRule,
+				Destination: v3.EntityRule{Ports: networkpolicy.Ports(render.FluentdMetricsPortNumber)},
+			},
+			v3.Rule{
+				Action:      v3.Allow,
+				Protocol:    &networkpolicy.TCPProtocol,
+				Source:      networkpolicy.OperatorSourceEntityRule(),
+				Destination: v3.EntityRule{Ports: networkpolicy.Ports(render.FluentdMetricsPortNumber)},
+			},
+		))
+	})
+
+	It("should render file buffers on an emptyDir", func() {
+		sizeLimit := resource.MustParse("2Gi")
+		cfg.LogCollector.Spec.Buffer = &operatorv1.FluentdBufferSpec{
+			Storage: &operatorv1.FluentdBufferStorage{Type: operatorv1.FluentdBufferStorageEmptyDir, SizeLimit: &sizeLimit},
+		}
+
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name:         render.FluentdBufferVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &sizeLimit}},
+		}))
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "FLUENTD_BUFFER_TYPE", Value: "file"}))
+	})
+
+	It("should reject invalid buffer configuration", func() {
+		small := resource.MustParse("1Mi")
+		large := resource.MustParse("1Gi")
+		Expect(render.ValidateLogCollectorBuffer(&operatorv1.FluentdBufferSpec{
+			FlushInterval: &metav1.Duration{Duration: 100 * time.Millisecond},
+		})).To(MatchError("flushInterval must be at least 1s"))
+		Expect(render.ValidateLogCollectorBuffer(&operatorv1.FluentdBufferSpec{
+			ChunkLimitSize: &large,
+			TotalLimitSize: &small,
+		})).To(MatchError("chunkLimitSize 1Gi is larger than totalLimitSize 1Mi"))
+		Expect(render.ValidateLogCollectorBuffer(&operatorv1.FluentdBufferSpec{
+			Storage: &operatorv1.FluentdBufferStorage{Type: operatorv1.FluentdBufferStorageHostPath, SizeLimit: &large},
+		})).To(MatchError("sizeLimit is only supported for EmptyDir buffer storage"))
+		Expect(render.ValidateLogCollectorBuffer(&operatorv1.FluentdBufferSpec{
+			TotalLimitSize: &large,
+			Storage:        &operatorv1.FluentdBufferStorage{Type: operatorv1.FluentdBufferStorageEmptyDir, SizeLimit: &small},
+		})).To(MatchError("sizeLimit 1Mi is smaller than totalLimitSize 1Gi"))
+	})
+
 	It("should render with EKS Cloudwatch Log", func() {
 		expectedResources := []struct {
 			name    string
@@ -17,6 +17,9 @@
 package render
 
 import (
+	"strings"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -27,6 +30,7 @@ import (
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/components"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/secret"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
@@ -43,6 +47,24 @@ const (
 	GuardianServiceName            = "tigera-guardian"
 	GuardianVolumeName             = "tigera-guardian-certs"
 	GuardianSecretName             = "tigera-managed-cluster-connection"
+	GuardianBootstrapSecretName    = "tigera-managed-cluster-bootstrap"
+	GuardianEnrollmentSecretName   = "tigera-managed-cluster-enrollment"
+
+	// The keys of the tunnel secret.
+	GuardianTunnelCertKey   = "managed-cluster.crt"
+	GuardianTunnelKeyKey    = "managed-cluster.key"
+	GuardianManagementCAKey = "management-cluster.crt"
+
+	// GuardianEnrollmentCSRKey is the key of the certificate signing request in the secret that holds the key a
+	// managed cluster enrolls with until it has stored its certificate. The key is stored under GuardianTunnelKeyKey.
+	GuardianEnrollmentCSRKey = "managed-cluster.csr"
+
+	// The keys of the secret with the credentials of the proxy guardian connects through.
+	GuardianProxyUsernameKey = "username"
+	GuardianProxyPasswordKey = "password"
+
+	// GuardianProxyCredentialsSecretName is the name of the copy of the proxy credentials in the guardian namespace.
+	GuardianProxyCredentialsSecretName = "tigera-guardian-proxy-credentials"
 )
 
 func Guardian(cfg *GuardianConfiguration) Component {
@@ -59,6 +81,19 @@ type GuardianConfiguration struct {
 	Installation      *operatorv1.InstallationSpec
 	TunnelSecret      *corev1.Secret
 	TrustedCertBundle certificatemanagement.TrustedBundle
+
+	// TLSServerName, if set, overrides the server name guardian verifies the certificate of the management cluster against.
+	TLSServerName string
+	// LogLevel is the log level of guardian. Info is used if it is empty.
+	LogLevel operatorv1.LogLevel
+
+	// ProxyURL, if set, is the HTTP CONNECT proxy guardian reaches the management cluster through.
+	ProxyURL string
+	// ProxyCredentials, if set, is the secret in the operator namespace with the username and password for the proxy.
+	ProxyCredentials *corev1.Secret
+
+	// NetworkPolicyState determines whether the policies for guardian in the allow-tigera tier are created or removed.
+	NetworkPolicyState networkpolicy.State
 }
 
 type GuardianComponent struct {
@@ -80,8 +115,13 @@ func (c *GuardianComponent) SupportedOSType() rmeta.OSType {
 }
 
 func (c *GuardianComponent) Objects() ([]client.Object, []client.Object) {
+	policies, policiesToDelete := c.cfg.NetworkPolicyState.Split(
+		networkpolicy.AllowTigeraDefaultDeny(GuardianNamespace),
+		c.networkPolicy(),
+	)
+
 	objs := []client.Object{
-		CreateNamespace(GuardianNamespace, c.cfg.Installation.KubernetesProvider, PSSRestricted),
+		CreateNamespace(GuardianNamespace, c.cfg.Installation, PSSRestricted),
 	}
 	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(GuardianNamespace, c.cfg.PullSecrets...)...)...)
 	objs = append(objs,
@@ -92,8 +132,16 @@ func (c *GuardianComponent) Objects() ([]client.Object, []client.Object) {
 		c.service(),
 		secret.CopyToNamespace(GuardianNamespace, c.cfg.TunnelSecret)[0],
 		c.cfg.TrustedCertBundle.ConfigMap(GuardianNamespace),
+	)
+	toDelete := policiesToDelete
+	if c.cfg.ProxyCredentials != nil {
+		objs = append(objs, c.proxyCredentials())
+	} else {
+		toDelete = append(toDelete, &corev1.Secret{TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: GuardianProxyCredentialsSecretName, Namespace: GuardianNamespace}})
+	}
+	objs = append(objs,
 		// Add tigera-manager service account for impersonation
-		CreateNamespace(ManagerNamespace, c.cfg.Installation.KubernetesProvider, PSSRestricted),
+		CreateNamespace(ManagerNamespace, c.cfg.Installation, PSSRestricted),
 		managerServiceAccount(),
 		managerClusterRole(false, true, c.cfg.Openshift),
 		managerClusterRoleBinding(),
@@ -102,8 +150,17 @@ func (c *GuardianComponent) Objects() ([]client.Object, []client.Object) {
 		managerClusterWideTigeraLayer(),
 		managerClusterWideDefaultView(),
 	)
+	objs = append(objs, policies...)
 
-	return objs, nil
+	return objs, toDelete
+}
+
+// proxyCredentials returns the copy of the proxy credentials for guardian. The copy has a fixed name, rather than that
+// of the user's secret, so that it can be deleted once the proxy is no longer used.
+func (c *GuardianComponent) proxyCredentials() *corev1.Secret {
+	s := secret.CopyToNamespace(GuardianNamespace, c.cfg.ProxyCredentials)[0]
+	s.Name = GuardianProxyCredentialsSecretName
+	return s
 }
 
 func (c *GuardianComponent) Ready() bool {
@@ -144,6 +201,30 @@ func (c *GuardianComponent) service() *corev1.Service {
 	}
 }
 
+// networkPolicy allows the cluster's components to reach the management cluster through guardian, and guardian to
+// reach the management cluster and the services it proxies requests from the management cluster to.
+func (c *GuardianComponent) networkPolicy() *v3.NetworkPolicy {
+	egress := networkpolicy.AllowDNSRules(c.cfg.Openshift)
+	egress = append(egress,
+		networkpolicy.AllowKubeAPIServerRule(),
+		networkpolicy.AllowAddressRule(c.managementClusterAddress()),
+		networkpolicy.AllowTCPRule(networkpolicy.PacketCaptureEntityRule),
+		networkpolicy.AllowTCPRule(networkpolicy.PrometheusEntityRule),
+	)
+	return networkpolicy.AllowTigeraPolicy(GuardianName, GuardianNamespace,
+		networkpolicy.ServiceIngressRules(c.service(), v3.EntityRule{}),
+		egress,
+	)
+}
+
+// managementClusterAddress returns the address guardian connects to, which is the proxy if one is configured.
+func (c *GuardianComponent) managementClusterAddress() string {
+	if c.cfg.ProxyURL != "" {
+		return c.cfg.ProxyURL
+	}
+	return c.cfg.URL
+}
+
 func (c *GuardianComponent) serviceAccount() client.Object {
 	return &corev1.ServiceAccount{
 		TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
@@ -236,17 +317,49 @@ func (c *GuardianComponent) volumes() []corev1.Volume {
 }
 
 func (c *GuardianComponent) container() []corev1.Container {
+	logLevel := c.cfg.LogLevel
+	if logLevel == "" {
+		logLevel = operatorv1.LogLevelInfo
+	}
+	env := []corev1.EnvVar{
+		{Name: "GUARDIAN_PORT", Value: "9443"},
+		{Name: "GUARDIAN_LOGLEVEL", Value: strings.ToUpper(string(logLevel))},
+		{Name: "GUARDIAN_VOLTRON_URL", Value: c.cfg.URL},
+		{Name: "GUARDIAN_PACKET_CAPTURE_CA_BUNDLE_PATH", Value: c.cfg.TrustedCertBundle.MountPath()},
+		{Name: "GUARDIAN_PROMETHEUS_CA_BUNDLE_PATH", Value: c.cfg.TrustedCertBundle.MountPath()},
+	}
+	if c.cfg.TLSServerName != "" {
+		env = append(env, corev1.EnvVar{Name: "GUARDIAN_VOLTRON_SERVER_NAME", Value: c.cfg.TLSServerName})
+	}
+	if c.cfg.ProxyURL != "" {
+		env = append(env, corev1.EnvVar{Name: "GUARDIAN_PROXY_URL", Value: c.cfg.ProxyURL})
+	}
+	if c.cfg.ProxyCredentials != nil {
+		env = append(env,
+			corev1.EnvVar{Name: "GUARDIAN_PROXY_USERNAME", ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: GuardianProxyCredentialsSecretName,
+					},
+					Key: GuardianProxyUsernameKey,
+				},
+			}},
+			corev1.EnvVar{Name: "GUARDIAN_PROXY_PASSWORD", ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: GuardianProxyCredentialsSecretName,
+					},
+					Key: GuardianProxyPasswordKey,
+				},
+			}},
+		)
+	}
+
 	return []corev1.Container{
 		{
-			Name:  GuardianDeploymentName,
-			Image: c.image,
-			Env: []corev1.EnvVar{
-				{Name: "GUARDIAN_PORT", Value: "9443"},
-				{Name: "GUARDIAN_LOGLEVEL", Value: "INFO"},
-				{Name: "GUARDIAN_VOLTRON_URL", Value: c.cfg.URL},
-				{Name: "GUARDIAN_PACKET_CAPTURE_CA_BUNDLE_PATH", Value: c.cfg.TrustedCertBundle.MountPath()},
-				{Name: "GUARDIAN_PROMETHEUS_CA_BUNDLE_PATH", Value: c.cfg.TrustedCertBundle.MountPath()},
-			},
+			Name:         GuardianDeploymentName,
+			Image:        c.image,
+			Env:          env,
 			VolumeMounts: c.volumeMounts(),
 			LivenessProbe: &corev1.Probe{
 				Handler: corev1.Handler{
@@ -289,5 +402,8 @@ func (c *GuardianComponent) volumeMounts() []corev1.VolumeMount {
 func (c *GuardianComponent) annotations() map[string]string {
 	annotations := c.cfg.TrustedCertBundle.HashAnnotations()
 	annotations["hash.operator.tigera.io/tigera-managed-cluster-connection"] = rmeta.AnnotationHash(c.cfg.TunnelSecret.Data)
+	if c.cfg.ProxyCredentials != nil {
+		annotations["hash.operator.tigera.io/guardian-proxy-credentials"] = rmeta.AnnotationHash(c.cfg.ProxyCredentials.Data)
+	}
 	return annotations
 }
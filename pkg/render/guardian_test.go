@@ -18,6 +18,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
 	"github.com/tigera/operator/pkg/common"
@@ -25,6 +26,7 @@ import (
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
 	"github.com/tigera/operator/pkg/render"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	appsv1 "k8s.io/api/apps/v1"
@@ -38,6 +40,9 @@ import (
 var _ = Describe("Rendering tests", func() {
 	var g render.Component
 	var resources []client.Object
+	var toDelete []client.Object
+	var policyState networkpolicy.State
+	var modifyCfg func(*render.GuardianConfiguration)
 
 	var renderGuardian = func(i operatorv1.InstallationSpec) {
 		addr := "127.0.0.1:1234"
@@ -68,16 +73,22 @@ var _ = Describe("Rendering tests", func() {
 					Namespace: common.OperatorNamespace(),
 				},
 			}},
-			Installation:      &i,
-			TunnelSecret:      secret,
-			TrustedCertBundle: bundle,
+			Installation:       &i,
+			TunnelSecret:       secret,
+			TrustedCertBundle:  bundle,
+			NetworkPolicyState: policyState,
+		}
+		if modifyCfg != nil {
+			modifyCfg(cfg)
 		}
 		g = render.Guardian(cfg)
 		Expect(g.ResolveImages(nil)).To(BeNil())
-		resources, _ = g.Objects()
+		resources, toDelete = g.Objects()
 	}
 
 	BeforeEach(func() {
+		policyState = networkpolicy.StateUnavailable
+		modifyCfg = nil
 		renderGuardian(operatorv1.InstallationSpec{Registry: "my-reg/"})
 	})
 
@@ -116,6 +127,41 @@ var _ = Describe("Rendering tests", func() {
 		Expect(deployment.Spec.Template.Spec.Containers[0].Image).Should(Equal("my-reg/tigera/guardian:" + components.ComponentGuardian.Version))
 	})
 
+	It("should render the allow-tigera policies when they are enabled", func() {
+		policyState = networkpolicy.StateEnabled
+		renderGuardian(operatorv1.InstallationSpec{Registry: "my-reg/"})
+		Expect(toDelete).To(HaveLen(1))
+		rtest.ExpectResource(toDelete[0], render.GuardianProxyCredentialsSecretName, render.GuardianNamespace, "", "v1", "Secret")
+
+		rtest.ExpectResource(rtest.GetResource(resources, networkpolicy.DefaultDenyPolicyName, render.GuardianNamespace, "projectcalico.org", "v3", "NetworkPolicy"),
+			networkpolicy.DefaultDenyPolicyName, render.GuardianNamespace, "projectcalico.org", "v3", "NetworkPolicy")
+		policy := rtest.GetResource(resources, "allow-tigera.tigera-guardian", render.GuardianNamespace, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+		Expect(policy.Spec.Tier).To(Equal(networkpolicy.TigeraComponentTierName))
+		Expect(policy.Spec.Selector).To(Equal("k8s-app == 'tigera-guardian'"))
+		Expect(policy.Spec.Egress).To(ContainElement(v3.Rule{
+			Action:   v3.Allow,
+			Protocol: &networkpolicy.TCPProtocol,
+			Destination: v3.EntityRule{
+				Nets:  []string{"127.0.0.1/32"},
+				Ports: networkpolicy.Ports(1234),
+			},
+		}))
+		Expect(policy.Spec.Ingress).To(ConsistOf(v3.Rule{
+			Action:      v3.Allow,
+			Protocol:    &networkpolicy.TCPProtocol,
+			Destination: v3.EntityRule{Ports: networkpolicy.Ports(8080)},
+		}))
+	})
+
+	It("should delete the allow-tigera policies when they are disabled", func() {
+		policyState = networkpolicy.StateDisabled
+		renderGuardian(operatorv1.InstallationSpec{Registry: "my-reg/"})
+
+		Expect(rtest.GetResource(resources, "allow-tigera.tigera-guardian", render.GuardianNamespace, "projectcalico.org", "v3", "NetworkPolicy")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, "allow-tigera.tigera-guardian", render.GuardianNamespace, "projectcalico.org", "v3", "NetworkPolicy")).NotTo(BeNil())
+		Expect(rtest.GetResource(toDelete, networkpolicy.DefaultDenyPolicyName, render.GuardianNamespace, "projectcalico.org", "v3", "NetworkPolicy")).NotTo(BeNil())
+	})
+
 	It("should render controlPlaneTolerations", func() {
 		t := corev1.Toleration{
 			Key:      "foo",
@@ -128,4 +174,79 @@ var _ = Describe("Rendering tests", func() {
 		deployment := rtest.GetResource(resources, render.GuardianDeploymentName, render.GuardianNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
 		Expect(deployment.Spec.Template.Spec.Tolerations).Should(ContainElements(t, rmeta.TolerateCriticalAddonsOnly, rmeta.TolerateMaster))
 	})
+
+	It("should render the default log level and no proxy", func() {
+		deployment := rtest.GetResource(resources, render.GuardianDeploymentName, render.GuardianNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		env := deployment.Spec.Template.Spec.Containers[0].Env
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "GUARDIAN_LOGLEVEL", Value: "INFO"}))
+		for _, e := range env {
+			Expect(e.Name).NotTo(HavePrefix("GUARDIAN_PROXY_"))
+			Expect(e.Name).NotTo(Equal("GUARDIAN_VOLTRON_SERVER_NAME"))
+		}
+	})
+
+	It("should render the log level and TLS server name", func() {
+		modifyCfg = func(cfg *render.GuardianConfiguration) {
+			cfg.LogLevel = operatorv1.LogLevelDebug
+			cfg.TLSServerName = "voltron.example.com"
+		}
+		renderGuardian(operatorv1.InstallationSpec{Registry: "my-reg/"})
+
+		deployment := rtest.GetResource(resources, render.GuardianDeploymentName, render.GuardianNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		env := deployment.Spec.Template.Spec.Containers[0].Env
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "GUARDIAN_LOGLEVEL", Value: "DEBUG"}))
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "GUARDIAN_VOLTRON_SERVER_NAME", Value: "voltron.example.com"}))
+	})
+
+	It("should connect through the proxy with its credentials", func() {
+		policyState = networkpolicy.StateEnabled
+		modifyCfg = func(cfg *render.GuardianConfiguration) {
+			cfg.ProxyURL = "http://proxy.example.com:3128"
+			cfg.ProxyCredentials = &corev1.Secret{
+				TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "proxy-creds", Namespace: common.OperatorNamespace()},
+				Data: map[string][]byte{
+					render.GuardianProxyUsernameKey: []byte("user"),
+					render.GuardianProxyPasswordKey: []byte("pass"),
+				},
+			}
+		}
+		renderGuardian(operatorv1.InstallationSpec{Registry: "my-reg/"})
+
+		rtest.ExpectResource(rtest.GetResource(resources, render.GuardianProxyCredentialsSecretName, render.GuardianNamespace, "", "v1", "Secret"),
+			render.GuardianProxyCredentialsSecretName, render.GuardianNamespace, "", "v1", "Secret")
+		Expect(rtest.GetResource(resources, "proxy-creds", render.GuardianNamespace, "", "v1", "Secret")).To(BeNil())
+
+		Expect(toDelete).To(BeEmpty())
+
+		deployment := rtest.GetResource(resources, render.GuardianDeploymentName, render.GuardianNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deployment.Annotations).To(HaveKey("hash.operator.tigera.io/guardian-proxy-credentials"))
+		env := deployment.Spec.Template.Spec.Containers[0].Env
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "GUARDIAN_PROXY_URL", Value: "http://proxy.example.com:3128"}))
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "GUARDIAN_PROXY_PASSWORD", ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: render.GuardianProxyCredentialsSecretName},
+				Key:                  render.GuardianProxyPasswordKey,
+			},
+		}}))
+
+		// Guardian only needs to reach the proxy, not the management cluster.
+		policy := rtest.GetResource(resources, "allow-tigera.tigera-guardian", render.GuardianNamespace, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+		Expect(policy.Spec.Egress).To(ContainElement(v3.Rule{
+			Action:   v3.Allow,
+			Protocol: &networkpolicy.TCPProtocol,
+			Destination: v3.EntityRule{
+				Domains: []string{"proxy.example.com"},
+				Ports:   networkpolicy.Ports(3128),
+			},
+		}))
+		Expect(policy.Spec.Egress).NotTo(ContainElement(v3.Rule{
+			Action:   v3.Allow,
+			Protocol: &networkpolicy.TCPProtocol,
+			Destination: v3.EntityRule{
+				Nets:  []string{"127.0.0.1/32"},
+				Ports: networkpolicy.Ports(1234),
+			},
+		}))
+	})
 })
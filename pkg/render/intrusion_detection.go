@@ -34,11 +34,16 @@ import (
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/dns"
+	"github.com/tigera/operator/pkg/render/common/configmap"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rkibana "github.com/tigera/operator/pkg/render/common/kibana"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/podsecuritypolicy"
 	"github.com/tigera/operator/pkg/render/common/secret"
+	"github.com/tigera/operator/pkg/threatfeed"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	"github.com/tigera/operator/pkg/url"
 )
@@ -56,12 +61,23 @@ const (
 	IntrusionDetectionControllerName   = "intrusion-detection-controller"
 
 	ADJobPodTemplateBaseName     = "tigera.io.detectors"
-	adDetectorPrefixName         = "tigera.io.detector."
+	ADDetectorPrefixName         = "tigera.io.detector."
 	adDetectorServiceAccountName = "anomaly-detectors"
 	adDetectionJobsDefaultPeriod = 15 * time.Minute
 	ADResourceGroup              = "detectors.tigera.io"
 	ADDetectorsModelResourceName = "models"
 
+	// UserGlobalAlertTemplateLabel labels the user defined GlobalAlertTemplates that the operator installs, so that
+	// they can be deleted once they are removed from their ConfigMap.
+	UserGlobalAlertTemplateLabel = "operator.tigera.io/user-global-alert-template"
+
+	// ThreatFeedLabel labels the GlobalThreatFeeds of the IntrusionDetection CR, and the copies of the ConfigMaps and
+	// secrets that they read from.
+	ThreatFeedLabel             = "operator.tigera.io/threat-feed"
+	threatFeedsContainerName    = "threat-feeds"
+	threatFeedVolumeNamePrefix  = "threat-feed-"
+	threatFeedLabelValueManaged = "managed"
+
 	ADAPIObjectName     = "anomaly-detection-api"
 	ADAPIObjectPortName = "anomaly-detection-api-https"
 	ADAPITLSSecretName  = "anomaly-detection-api-tls"
@@ -91,14 +107,45 @@ type IntrusionDetectionConfiguration struct {
 	TrustedCertBundle     certificatemanagement.TrustedBundle
 	ADAPIServerCertSecret certificatemanagement.KeyPairInterface
 
+	// IntrusionDetection selects the built-in alert templates and anomaly detectors. All of them are installed if it
+	// is nil.
+	IntrusionDetection *operatorv1.IntrusionDetection
+	// UserGlobalAlertTemplates are installed alongside the built-in alert templates.
+	UserGlobalAlertTemplates []*v3.GlobalAlertTemplate
+	// RemovedUserGlobalAlertTemplates are the names of user defined GlobalAlertTemplates that were removed from their
+	// ConfigMap.
+	RemovedUserGlobalAlertTemplates []string
+	// AnomalyDetectionGlobalAlerts are the GlobalAlerts that run anomaly detectors. Those that run a detector that is
+	// disabled by the IntrusionDetection CR are deleted.
+	AnomalyDetectionGlobalAlerts []*v3.GlobalAlert
+
+	// ThreatFeedConfigMaps and ThreatFeedSecrets are the ConfigMaps that hold threat feeds and the secrets that hold
+	// the values of their headers, which are copied to the intrusion detection namespace.
+	ThreatFeedConfigMaps []*corev1.ConfigMap
+	ThreatFeedSecrets    []*corev1.Secret
+	// RemovedThreatFeeds are the names of GlobalThreatFeeds that were removed from the IntrusionDetection CR.
+	RemovedThreatFeeds []string
+	// RemovedThreatFeedConfigMaps and RemovedThreatFeedSecrets are the names of the copies in the intrusion detection
+	// namespace of ConfigMaps and secrets that no threat feed reads from anymore.
+	RemovedThreatFeedConfigMaps []string
+	RemovedThreatFeedSecrets    []string
+	// LogLevel is the log level of the intrusion detection components. The default of each component is used if it
+	// is empty.
+	LogLevel operatorv1.LogLevel
+
 	// Whether or not the cluster supports pod security policies.
 	UsePSP bool
+
+	// NetworkPolicyState determines whether the policies for intrusion detection in the allow-tigera tier are created
+	// or removed.
+	NetworkPolicyState networkpolicy.State
 }
 
 type intrusionDetectionComponent struct {
 	cfg               *IntrusionDetectionConfiguration
 	jobInstallerImage string
 	controllerImage   string
+	threatFeedsImage  string
 	adDetectorsImage  string
 	adAPIImage        string
 }
@@ -121,6 +168,13 @@ func (c *intrusionDetectionComponent) ResolveImages(is *operatorv1.ImageSet) err
 		errMsgs = append(errMsgs, err.Error())
 	}
 
+	if len(c.threatFeedConfigMaps()) != 0 {
+		c.threatFeedsImage, err = components.GetReference(components.ComponentOperatorInit, reg, path, prefix, is)
+		if err != nil {
+			errMsgs = append(errMsgs, err.Error())
+		}
+	}
+
 	c.adDetectorsImage, err = components.GetReference(components.ComponentAnomalyDetectionJobs, reg, path, prefix, is)
 	if err != nil {
 		errMsgs = append(errMsgs, err.Error())
@@ -149,7 +203,7 @@ func (c *intrusionDetectionComponent) Objects() ([]client.Object, []client.Objec
 		pss = PSSPrivileged
 	}
 	objs := []client.Object{
-		CreateNamespace(IntrusionDetectionNamespace, c.cfg.Installation.KubernetesProvider, PodSecurityStandard(pss)),
+		CreateNamespace(IntrusionDetectionNamespace, c.cfg.Installation, PodSecurityStandard(pss)),
 	}
 	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(IntrusionDetectionNamespace, c.cfg.PullSecrets...)...)...)
 
@@ -164,7 +218,12 @@ func (c *intrusionDetectionComponent) Objects() ([]client.Object, []client.Objec
 	)
 
 	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(IntrusionDetectionNamespace, c.cfg.ESSecrets...)...)...)
-	objs = append(objs, c.globalAlertTemplates()...)
+	alertTemplates, toDelete := c.alertTemplates()
+	objs = append(objs, alertTemplates...)
+
+	threatFeedObjs, threatFeedsToDelete := c.threatFeedObjects()
+	objs = append(objs, threatFeedObjs...)
+	toDelete = append(toDelete, threatFeedsToDelete...)
 
 	// AD Related deployment only for management/standalone cluster
 	if !c.cfg.ManagedCluster {
@@ -202,17 +261,65 @@ func (c *intrusionDetectionComponent) Objects() ([]client.Object, []client.Objec
 		}
 	}
 
+	policies, policiesToDelete := c.cfg.NetworkPolicyState.Split(c.networkPolicies()...)
+	objs = append(objs, policies...)
+	toDelete = append(toDelete, policiesToDelete...)
+
 	if c.cfg.HasNoLicense {
-		return nil, objs
+		return nil, append(objs, toDelete...)
 	}
 
-	return objs, nil
+	return objs, toDelete
 }
 
 func (c *intrusionDetectionComponent) Ready() bool {
 	return true
 }
 
+// networkPolicies allows the intrusion detection controller, whose label the anomaly detector pods share, to reach
+// Elasticsearch, the anomaly detection API and the threat feeds, and the installer job to set up Elasticsearch and
+// Kibana through es-gateway.
+func (c *intrusionDetectionComponent) networkPolicies() []client.Object {
+	openshift := c.cfg.Installation.KubernetesProvider == operatorv1.ProviderOpenShift
+
+	controllerEgress := networkpolicy.AllowDNSRules(openshift)
+	controllerEgress = append(controllerEgress,
+		networkpolicy.AllowKubeAPIServerRule(),
+		networkpolicy.AllowTCPRule(networkpolicy.ESGatewayEntityRule),
+		networkpolicy.AllowTCPRule(networkpolicy.GuardianEntityRule),
+		networkpolicy.AllowTCPRule(networkpolicy.CreateEntityRule(IntrusionDetectionNamespace, ADAPIObjectName, adAPIPort)),
+		// GlobalThreatFeeds and alert webhooks can be served from any host.
+		networkpolicy.AllowTCPRule(v3.EntityRule{Ports: networkpolicy.Ports(80, 443)}),
+	)
+	if c.cfg.IntrusionDetection != nil {
+		for _, f := range c.cfg.IntrusionDetection.Spec.ThreatFeeds {
+			if f.ConfigMap == nil && f.URL != "" {
+				controllerEgress = append(controllerEgress, networkpolicy.AllowAddressRule(f.URL))
+			}
+		}
+	}
+
+	policies := []client.Object{
+		networkpolicy.AllowTigeraDefaultDeny(IntrusionDetectionNamespace),
+		networkpolicy.AllowTigeraPolicy(IntrusionDetectionControllerName, IntrusionDetectionNamespace, nil, controllerEgress),
+	}
+
+	if !c.cfg.ManagedCluster {
+		adAPIEgress := append(networkpolicy.AllowDNSRules(openshift), networkpolicy.AllowKubeAPIServerRule())
+		// The installer reaches Kibana through the es-gateway service, which forwards to the es-gateway pods.
+		installerEgress := append(networkpolicy.AllowDNSRules(openshift), networkpolicy.AllowTCPRule(networkpolicy.ESGatewayEntityRule))
+		policies = append(policies,
+			networkpolicy.AllowTigeraPolicy(ADAPIObjectName, IntrusionDetectionNamespace,
+				networkpolicy.ServiceIngressRules(c.adAPIService(), networkpolicy.CreateSourceEntityRule(IntrusionDetectionNamespace, IntrusionDetectionControllerName)),
+				adAPIEgress,
+			),
+			networkpolicy.AllowTigeraPolicyForSelector(IntrusionDetectionInstallerJobName, IntrusionDetectionNamespace,
+				fmt.Sprintf("job-name == '%s'", IntrusionDetectionInstallerJobName), nil, installerEgress),
+		)
+	}
+	return policies
+}
+
 func (c *intrusionDetectionComponent) intrusionDetectionElasticsearchJob() *batchv1.Job {
 	podTemplate := relasticsearch.DecorateAnnotations(&corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
@@ -512,6 +619,13 @@ func (c *intrusionDetectionComponent) deploymentPodTemplate() *corev1.PodTemplat
 		container.Env = append(container.Env, envVars...)
 	}
 
+	containers := []corev1.Container{container}
+	// Threat feeds in ConfigMaps are served to the controller by a sidecar.
+	if len(c.threatFeedConfigMaps()) != 0 {
+		containers = append(containers, c.threatFeedsContainer())
+		volumes = append(volumes, c.threatFeedVolumes()...)
+	}
+
 	return relasticsearch.DecorateAnnotations(&corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        IntrusionDetectionName,
@@ -523,10 +637,8 @@ func (c *intrusionDetectionComponent) deploymentPodTemplate() *corev1.PodTemplat
 			NodeSelector:       c.cfg.Installation.ControlPlaneNodeSelector,
 			ServiceAccountName: IntrusionDetectionName,
 			ImagePullSecrets:   ps,
-			Containers: []corev1.Container{
-				container,
-			},
-			Volumes: volumes,
+			Containers:         containers,
+			Volumes:            volumes,
 		},
 	}, c.cfg.ESClusterConfig, c.cfg.ESSecrets).(*corev1.PodTemplateSpec)
 }
@@ -542,8 +654,11 @@ func (c *intrusionDetectionComponent) intrusionDetectionControllerContainer() co
 			Value: c.cfg.TrustedCertBundle.MountPath(),
 		},
 	}
+	if c.cfg.LogLevel != "" {
+		envs = append(envs, corev1.EnvVar{Name: "LOG_LEVEL", Value: loglevel.Logrus(c.cfg.LogLevel, "")})
+	}
 
-	privileged := false
+	sc := podsecuritycontext.NewBaseContext()
 
 	// If syslog forwarding is enabled then set the necessary ENV var and volume mount to
 	// write logs for Fluentd.
@@ -555,11 +670,9 @@ func (c *intrusionDetectionComponent) intrusionDetectionControllerContainer() co
 			corev1.EnvVar{Name: "IDS_ENABLE_EVENT_FORWARDING", Value: "true"},
 		)
 		volumeMounts = append(volumeMounts, syslogEventsForwardingVolumeMount())
-		// On OpenShift, if we need the volume mount to hostpath volume for syslog forwarding,
-		// then ID controller needs privileged access to write event logs to that volume
-		if c.cfg.Openshift {
-			privileged = true
-		}
+		// The event logs are written to a hostpath volume as root. On OpenShift the ID controller also needs
+		// privileged access to write to that volume.
+		sc = podsecuritycontext.NewRootContext(c.cfg.Openshift)
 	}
 
 	return corev1.Container{
@@ -578,10 +691,8 @@ func (c *intrusionDetectionComponent) intrusionDetectionControllerContainer() co
 			},
 			InitialDelaySeconds: 5,
 		},
-		SecurityContext: &corev1.SecurityContext{
-			Privileged: &privileged,
-		},
-		VolumeMounts: volumeMounts,
+		SecurityContext: sc,
+		VolumeMounts:    volumeMounts,
 	}
 }
 
@@ -612,6 +723,293 @@ func syslogEventsForwardingVolumeMount() corev1.VolumeMount {
 	}
 }
 
+// threatFeedConfigMaps returns the threat feeds of the IntrusionDetection CR that are kept in ConfigMaps.
+func (c *intrusionDetectionComponent) threatFeedConfigMaps() []operatorv1.ThreatFeed {
+	if c.cfg.IntrusionDetection == nil {
+		return nil
+	}
+	var feeds []operatorv1.ThreatFeed
+	for _, f := range c.cfg.IntrusionDetection.Spec.ThreatFeeds {
+		if f.ConfigMap != nil {
+			feeds = append(feeds, f)
+		}
+	}
+	return feeds
+}
+
+// threatFeedObjects returns the GlobalThreatFeeds of the IntrusionDetection CR with the copies of the ConfigMaps and
+// secrets they read from, and the objects of the threat feeds that were removed.
+func (c *intrusionDetectionComponent) threatFeedObjects() ([]client.Object, []client.Object) {
+	var objs, toDelete []client.Object
+	for _, cm := range configmap.CopyToNamespace(IntrusionDetectionNamespace, c.cfg.ThreatFeedConfigMaps...) {
+		cm.Labels = map[string]string{ThreatFeedLabel: threatFeedLabelValueManaged}
+		objs = append(objs, cm)
+	}
+	for _, s := range secret.CopyToNamespace(IntrusionDetectionNamespace, c.cfg.ThreatFeedSecrets...) {
+		s.Labels = map[string]string{ThreatFeedLabel: threatFeedLabelValueManaged}
+		objs = append(objs, s)
+	}
+	objs = append(objs, c.threatFeeds()...)
+
+	for _, name := range c.cfg.RemovedThreatFeeds {
+		toDelete = append(toDelete, &v3.GlobalThreatFeed{
+			TypeMeta:   metav1.TypeMeta{Kind: v3.KindGlobalThreatFeed, APIVersion: v3.GroupVersionCurrent},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		})
+	}
+	for _, name := range c.cfg.RemovedThreatFeedConfigMaps {
+		toDelete = append(toDelete, &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: IntrusionDetectionNamespace},
+		})
+	}
+	for _, name := range c.cfg.RemovedThreatFeedSecrets {
+		toDelete = append(toDelete, &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: IntrusionDetectionNamespace},
+		})
+	}
+	return objs, toDelete
+}
+
+// threatFeeds returns the GlobalThreatFeeds of the threat feeds of the IntrusionDetection CR.
+func (c *intrusionDetectionComponent) threatFeeds() []client.Object {
+	if c.cfg.IntrusionDetection == nil {
+		return nil
+	}
+	var objs []client.Object
+	for _, f := range c.cfg.IntrusionDetection.Spec.ThreatFeeds {
+		content := v3.ThreatFeedContentIPset
+		if f.Content != nil && *f.Content == operatorv1.ThreatFeedContentDomainNameSet {
+			content = v3.ThreatFeedContentDomainNameSet
+		}
+		pull := &v3.Pull{
+			HTTP: &v3.HTTPPull{
+				Format: v3.ThreatFeedFormat{NewlineDelimited: &v3.ThreatFeedFormatNewlineDelimited{}},
+				URL:    f.URL,
+			},
+		}
+		if f.ConfigMap != nil {
+			pull.HTTP.URL = fmt.Sprintf("http://127.0.0.1:%d/%s", threatfeed.DefaultPort, f.Name)
+		}
+		if f.PullPeriod != nil {
+			pull.Period = f.PullPeriod.Duration.String()
+		}
+		for _, h := range f.Headers {
+			header := v3.HTTPHeader{Name: h.Name, Value: h.Value}
+			if h.SecretKeyRef != nil {
+				header.ValueFrom = &v3.HTTPHeaderSource{SecretKeyRef: h.SecretKeyRef.DeepCopy()}
+			}
+			pull.HTTP.Headers = append(pull.HTTP.Headers, header)
+		}
+
+		feed := &v3.GlobalThreatFeed{
+			TypeMeta: metav1.TypeMeta{Kind: v3.KindGlobalThreatFeed, APIVersion: v3.GroupVersionCurrent},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   f.Name,
+				Labels: map[string]string{ThreatFeedLabel: threatFeedLabelValueManaged},
+			},
+			Spec: v3.GlobalThreatFeedSpec{
+				Content: content,
+				Pull:    pull,
+			},
+		}
+		if f.GlobalNetworkSet != nil {
+			feed.Spec.GlobalNetworkSet = &v3.GlobalNetworkSetSync{Labels: f.GlobalNetworkSet.Labels}
+		}
+		objs = append(objs, feed)
+	}
+	return objs
+}
+
+// threatFeedsContainer serves the threat feeds that are kept in ConfigMaps to the controller.
+func (c *intrusionDetectionComponent) threatFeedsContainer() corev1.Container {
+	var mounts []corev1.VolumeMount
+	for _, f := range c.threatFeedConfigMaps() {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      threatFeedVolumeNamePrefix + f.Name,
+			MountPath: fmt.Sprintf("%s/%s", threatfeed.DefaultDir, f.Name),
+			ReadOnly:  true,
+		})
+	}
+	return corev1.Container{
+		Name:  threatFeedsContainerName,
+		Image: c.threatFeedsImage,
+		Args: []string{
+			fmt.Sprintf("--serve-threat-feeds=127.0.0.1:%d", threatfeed.DefaultPort),
+			fmt.Sprintf("--threat-feeds-dir=%s", threatfeed.DefaultDir),
+		},
+		VolumeMounts:    mounts,
+		SecurityContext: podsecuritycontext.NewBaseContext(),
+	}
+}
+
+func (c *intrusionDetectionComponent) threatFeedVolumes() []corev1.Volume {
+	var volumes []corev1.Volume
+	for _, f := range c.threatFeedConfigMaps() {
+		volumes = append(volumes, corev1.Volume{
+			Name: threatFeedVolumeNamePrefix + f.Name,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: f.ConfigMap.LocalObjectReference,
+					Items:                []corev1.KeyToPath{{Key: f.ConfigMap.Key, Path: threatfeed.ContentFile}},
+				},
+			},
+		})
+	}
+	return volumes
+}
+
+// IntrusionDetectionBuiltInGlobalAlertTemplateNames returns the names of the built-in GlobalAlertTemplates.
+func IntrusionDetectionBuiltInGlobalAlertTemplateNames() []string {
+	var names []string
+	for _, t := range (&intrusionDetectionComponent{}).globalAlertTemplates() {
+		names = append(names, t.GetName())
+	}
+	return names
+}
+
+// alertTemplates returns the GlobalAlertTemplates to install, and the built-in templates that are disabled by the
+// IntrusionDetection CR along with the GlobalAlerts that run the anomaly detectors it disables.
+func (c *intrusionDetectionComponent) alertTemplates() ([]client.Object, []client.Object) {
+	var spec operatorv1.IntrusionDetectionSpec
+	if c.cfg.IntrusionDetection != nil {
+		spec = c.cfg.IntrusionDetection.Spec
+	}
+	disabled := map[string]bool{}
+	for _, name := range spec.DisabledGlobalAlertTemplates {
+		disabled[name] = true
+	}
+
+	var objs, toDelete []client.Object
+	for _, obj := range c.globalAlertTemplates() {
+		t := obj.(*v3.GlobalAlertTemplate)
+		if t.Spec.Type == v3.GlobalAlertTypeAnomalyDetection {
+			detector, enabled := c.anomalyDetector(t.Spec.Detector.Name)
+			if !enabled {
+				toDelete = append(toDelete, t)
+				continue
+			}
+			if detector != nil && detector.DetectionPeriod != nil {
+				t.Spec.Period = detector.DetectionPeriod
+			} else if spec.AnomalyDetection != nil && spec.AnomalyDetection.DetectionPeriod != nil {
+				t.Spec.Period = spec.AnomalyDetection.DetectionPeriod
+			}
+		} else if disabled[t.Name] {
+			toDelete = append(toDelete, t)
+			continue
+		}
+		objs = append(objs, t)
+	}
+
+	for _, t := range c.cfg.UserGlobalAlertTemplates {
+		objs = append(objs, t)
+	}
+	for _, name := range c.cfg.RemovedUserGlobalAlertTemplates {
+		toDelete = append(toDelete, &v3.GlobalAlertTemplate{
+			TypeMeta:   metav1.TypeMeta{Kind: v3.KindGlobalAlertTemplate, APIVersion: v3.GroupVersionCurrent},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		})
+	}
+	for _, a := range c.cfg.AnomalyDetectionGlobalAlerts {
+		if a.Spec.Detector == nil {
+			continue
+		}
+		if _, enabled := c.anomalyDetector(a.Spec.Detector.Name); !enabled {
+			toDelete = append(toDelete, &v3.GlobalAlert{
+				TypeMeta:   metav1.TypeMeta{Kind: v3.KindGlobalAlert, APIVersion: v3.GroupVersionCurrent},
+				ObjectMeta: metav1.ObjectMeta{Name: a.Name},
+			})
+		}
+	}
+	return objs, toDelete
+}
+
+// anomalyDetector returns whether the built-in anomaly detector is enabled, and the settings that the
+// IntrusionDetection CR overrides for it, if any.
+func (c *intrusionDetectionComponent) anomalyDetector(name string) (*operatorv1.AnomalyDetector, bool) {
+	if c.cfg.IntrusionDetection == nil || c.cfg.IntrusionDetection.Spec.AnomalyDetection == nil {
+		return nil, true
+	}
+	detectors := c.cfg.IntrusionDetection.Spec.AnomalyDetection.Detectors
+	if len(detectors) == 0 {
+		return nil, true
+	}
+	for i := range detectors {
+		if detectors[i].Name == name {
+			return &detectors[i], true
+		}
+	}
+	return nil, false
+}
+
+// adDetectorThreshold is the environment variable of the anomaly detection jobs that holds the threshold of a
+// detector, and the thresholds for a low and a high sensitivity. A medium sensitivity keeps the default threshold of
+// the jobs.
+type adDetectorThreshold struct {
+	env       string
+	low, high string
+}
+
+// adDetectorThresholds are the thresholds of the anomaly detectors that have a configurable sensitivity.
+var adDetectorThresholds = map[string]adDetectorThreshold{
+	"ip_sweep":         {env: "AD_ip_sweep_threshold", low: "64", high: "16"},
+	"port_scan":        {env: "AD_port_scan_threshold", low: "1000", high: "250"},
+	"process_restarts": {env: "AD_ProcessRestarts_threshold", low: "8", high: "2"},
+	"dns_latency":      {env: "AD_DnsLatency_IsolationForest_score_threshold", low: "0.85", high: "0.7"},
+	"l7_latency":       {env: "AD_L7Latency_IsolationForest_score_threshold", low: "0.85", high: "0.7"},
+}
+
+// AnomalyDetectorHasSensitivity returns whether the sensitivity of the built-in anomaly detector is configurable.
+func AnomalyDetectorHasSensitivity(name string) bool {
+	_, ok := adDetectorThresholds[name]
+	return ok
+}
+
+// adTrainingEnvVars returns the training cadence of the anomaly detectors configured in the IntrusionDetection CR.
+func (c *intrusionDetectionComponent) adTrainingEnvVars() []corev1.EnvVar {
+	if c.cfg.IntrusionDetection == nil || c.cfg.IntrusionDetection.Spec.AnomalyDetection == nil {
+		return nil
+	}
+	period := c.cfg.IntrusionDetection.Spec.AnomalyDetection.TrainingPeriod
+	if period == nil {
+		return nil
+	}
+	return []corev1.EnvVar{{Name: "AD_train_interval_minutes", Value: strconv.Itoa(int(period.Duration.Minutes()))}}
+}
+
+// adSensitivityEnvVars returns the thresholds of the anomaly detectors for the sensitivities configured in the
+// IntrusionDetection CR.
+func (c *intrusionDetectionComponent) adSensitivityEnvVars() []corev1.EnvVar {
+	if c.cfg.IntrusionDetection == nil || c.cfg.IntrusionDetection.Spec.AnomalyDetection == nil {
+		return nil
+	}
+	ad := c.cfg.IntrusionDetection.Spec.AnomalyDetection
+
+	var envs []corev1.EnvVar
+	for _, name := range []string{"ip_sweep", "port_scan", "process_restarts", "dns_latency", "l7_latency"} {
+		detector, enabled := c.anomalyDetector(name)
+		if !enabled {
+			continue
+		}
+		sensitivity := ad.Sensitivity
+		if detector != nil && detector.Sensitivity != nil {
+			sensitivity = detector.Sensitivity
+		}
+		if sensitivity == nil {
+			continue
+		}
+		threshold := adDetectorThresholds[name]
+		switch *sensitivity {
+		case operatorv1.AnomalyDetectorSensitivityLow:
+			envs = append(envs, corev1.EnvVar{Name: threshold.env, Value: threshold.low})
+		case operatorv1.AnomalyDetectorSensitivityHigh:
+			envs = append(envs, corev1.EnvVar{Name: threshold.env, Value: threshold.high})
+		}
+	}
+	return envs
+}
+
 func (c *intrusionDetectionComponent) globalAlertTemplates() []client.Object {
 	globalAlertTemplates := []client.Object{
 		&v3.GlobalAlertTemplate{
@@ -853,7 +1251,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "dga",
+				Name: ADDetectorPrefixName + "dga",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -872,7 +1270,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "http-connection-spike",
+				Name: ADDetectorPrefixName + "http-connection-spike",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -891,7 +1289,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "http-response-codes",
+				Name: ADDetectorPrefixName + "http-response-codes",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -910,7 +1308,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "http-verbs",
+				Name: ADDetectorPrefixName + "http-verbs",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -929,7 +1327,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "ip-sweep",
+				Name: ADDetectorPrefixName + "ip-sweep",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -946,7 +1344,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "port-scan",
+				Name: ADDetectorPrefixName + "port-scan",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -963,7 +1361,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "generic-dns",
+				Name: ADDetectorPrefixName + "generic-dns",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -980,7 +1378,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "time-series-dns",
+				Name: ADDetectorPrefixName + "time-series-dns",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -997,7 +1395,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "generic-flows",
+				Name: ADDetectorPrefixName + "generic-flows",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -1014,7 +1412,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "time-series-flows",
+				Name: ADDetectorPrefixName + "time-series-flows",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -1031,7 +1429,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "generic-l7",
+				Name: ADDetectorPrefixName + "generic-l7",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -1048,7 +1446,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "dns-latency",
+				Name: ADDetectorPrefixName + "dns-latency",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -1065,7 +1463,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "l7-bytes",
+				Name: ADDetectorPrefixName + "l7-bytes",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -1082,7 +1480,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "l7-latency",
+				Name: ADDetectorPrefixName + "l7-latency",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -1099,7 +1497,7 @@ func (c *intrusionDetectionComponent) adJobsGlobalertTemplates() []client.Object
 				APIVersion: "projectcalico.org/v3",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name: adDetectorPrefixName + "process-restarts",
+				Name: ADDetectorPrefixName + "process-restarts",
 			},
 			Spec: v3.GlobalAlertSpec{
 				Type:        v3.GlobalAlertTypeAnomalyDetection,
@@ -1314,7 +1712,7 @@ func (c *intrusionDetectionComponent) adAPIDeployment() *appsv1.Deployment {
 							Name:  ADAPIObjectName,
 							Image: c.adAPIImage,
 							Env: []corev1.EnvVar{
-								{Name: "LOG_LEVEL", Value: "info"},
+								{Name: "LOG_LEVEL", Value: loglevel.Logrus(c.cfg.LogLevel, "info")},
 								{Name: "STORAGE_PATH", Value: adAPIStorageVolumePath},
 								{Name: "TLS_KEY", Value: c.cfg.ADAPIServerCertSecret.VolumeMountKeyFilePath()},
 								{Name: "TLS_CERT", Value: c.cfg.ADAPIServerCertSecret.VolumeMountCertificateFilePath()},
@@ -1434,13 +1832,59 @@ func (c *intrusionDetectionComponent) adDetectorPodTemplates() []client.Object {
 	trainingJobPodTemplate := c.getBaseADDetectorsPodTemplate(ADJobPodTemplateBaseName + ".training")
 	detecionADJobPodTemplate := c.getBaseADDetectorsPodTemplate(ADJobPodTemplateBaseName + ".detection")
 
+	trainingContainer := &trainingJobPodTemplate.Template.Spec.Containers[0]
+	trainingContainer.Env = append(trainingContainer.Env, c.adTrainingEnvVars()...)
+	detectionContainer := &detecionADJobPodTemplate.Template.Spec.Containers[0]
+	detectionContainer.Env = append(detectionContainer.Env, c.adSensitivityEnvVars()...)
+
 	return []client.Object{&trainingJobPodTemplate, &detecionADJobPodTemplate}
 }
 
 func (c *intrusionDetectionComponent) getBaseADDetectorsPodTemplate(podTemplateName string) corev1.PodTemplate {
-	privileged := false
+	sc := podsecuritycontext.NewBaseContext()
 	if c.cfg.Openshift {
-		privileged = true
+		sc = podsecuritycontext.NewRootContext(true)
+	}
+
+	envs := []corev1.EnvVar{
+		{
+			Name: "ELASTIC_HOST",
+			// static index 2 refres to - <svc_name>.<ns>.svc format
+			Value: dns.GetServiceDNSNames(ESGatewayServiceName, ElasticsearchNamespace, c.cfg.ClusterDomain)[2],
+		},
+		{
+			Name:  "ELASTIC_PORT",
+			Value: strconv.Itoa(ElasticsearchDefaultPort),
+		},
+		{
+			Name:      "ELASTIC_USER",
+			ValueFrom: secret.GetEnvVarSource(ElasticsearchADJobUserSecret, "username", false),
+		},
+		{
+			Name:      "ELASTIC_PASSWORD",
+			ValueFrom: secret.GetEnvVarSource(ElasticsearchADJobUserSecret, "password", false),
+		},
+		{
+			Name: "MODEL_STORAGE_API_HOST",
+			// static index 2 refres to - <svc_name>.<ns>.svc format
+			Value: dns.GetServiceDNSNames(ADAPIObjectName, IntrusionDetectionNamespace, c.cfg.ClusterDomain)[2],
+		},
+		{
+			Name:  "MODEL_STORAGE_API_PORT",
+			Value: strconv.Itoa(adAPIPort),
+		},
+		{
+			Name:  "MODEL_STORAGE_CLIENT_CERT",
+			Value: c.cfg.ADAPIServerCertSecret.VolumeMountCertificateFilePath(),
+		},
+		{
+			Name:      "MODEL_STORAGE_API_TOKEN",
+			ValueFrom: secret.GetEnvVarSource(adDetectorServiceAccountName, "token", false),
+		},
+		{
+			Name:  "ES_CA_CERT",
+			Value: "/certs/es-ca.pem",
+		},
 	}
 
 	return corev1.PodTemplate{
@@ -1482,51 +1926,10 @@ func (c *intrusionDetectionComponent) getBaseADDetectorsPodTemplate(podTemplateN
 				Tolerations:        append(c.cfg.Installation.ControlPlaneTolerations, rmeta.TolerateMaster),
 				Containers: []corev1.Container{
 					{
-						Name:  "adjobs",
-						Image: c.adDetectorsImage,
-						SecurityContext: &corev1.SecurityContext{
-							Privileged: &privileged,
-						},
-						Env: []corev1.EnvVar{
-							{
-								Name: "ELASTIC_HOST",
-								// static index 2 refres to - <svc_name>.<ns>.svc format
-								Value: dns.GetServiceDNSNames(ESGatewayServiceName, ElasticsearchNamespace, c.cfg.ClusterDomain)[2],
-							},
-							{
-								Name:  "ELASTIC_PORT",
-								Value: strconv.Itoa(ElasticsearchDefaultPort),
-							},
-							{
-								Name:      "ELASTIC_USER",
-								ValueFrom: secret.GetEnvVarSource(ElasticsearchADJobUserSecret, "username", false),
-							},
-							{
-								Name:      "ELASTIC_PASSWORD",
-								ValueFrom: secret.GetEnvVarSource(ElasticsearchADJobUserSecret, "password", false),
-							},
-							{
-								Name: "MODEL_STORAGE_API_HOST",
-								// static index 2 refres to - <svc_name>.<ns>.svc format
-								Value: dns.GetServiceDNSNames(ADAPIObjectName, IntrusionDetectionNamespace, c.cfg.ClusterDomain)[2],
-							},
-							{
-								Name:  "MODEL_STORAGE_API_PORT",
-								Value: strconv.Itoa(adAPIPort),
-							},
-							{
-								Name:  "MODEL_STORAGE_CLIENT_CERT",
-								Value: c.cfg.ADAPIServerCertSecret.VolumeMountCertificateFilePath(),
-							},
-							{
-								Name:      "MODEL_STORAGE_API_TOKEN",
-								ValueFrom: secret.GetEnvVarSource(adDetectorServiceAccountName, "token", false),
-							},
-							{
-								Name:  "ES_CA_CERT",
-								Value: "/certs/es-ca.pem",
-							},
-						},
+						Name:            "adjobs",
+						Image:           c.adDetectorsImage,
+						SecurityContext: sc,
+						Env:             envs,
 						VolumeMounts: []corev1.VolumeMount{
 							{
 								Name:      "es-certs",
@@ -15,8 +15,11 @@
 package render_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
@@ -24,12 +27,14 @@ import (
 	"github.com/tigera/operator/pkg/render"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -429,6 +434,24 @@ var _ = Describe("Intrusion Detection rendering tests", func() {
 		}
 	})
 
+	It("should allow the installer to reach Kibana through the es-gateway pods when the allow-tigera policies are enabled", func() {
+		cfg.NetworkPolicyState = networkpolicy.StateEnabled
+		component := render.IntrusionDetection(cfg)
+		resources, toDelete := component.Objects()
+		Expect(toDelete).To(BeEmpty())
+
+		Expect(rtest.GetResource(resources, networkpolicy.DefaultDenyPolicyName, render.IntrusionDetectionNamespace, "projectcalico.org", "v3", "NetworkPolicy")).NotTo(BeNil())
+		Expect(rtest.GetResource(resources, "allow-tigera.intrusion-detection-controller", render.IntrusionDetectionNamespace, "projectcalico.org", "v3", "NetworkPolicy")).NotTo(BeNil())
+		policy := rtest.GetResource(resources, "allow-tigera.intrusion-detection-es-job-installer", render.IntrusionDetectionNamespace, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+		Expect(policy.Spec.Selector).To(Equal("job-name == 'intrusion-detection-es-job-installer'"))
+		// Policy applies after the service is resolved, so the es-gateway pod port must be used rather than the
+		// Kibana port of the service.
+		Expect(policy.Spec.Egress).To(ContainElement(networkpolicy.AllowTCPRule(networkpolicy.ESGatewayEntityRule)))
+		for _, r := range policy.Spec.Egress {
+			Expect(r.Destination.Ports).NotTo(Equal(networkpolicy.Ports(5601)))
+		}
+	})
+
 	It("should apply controlPlaneNodeSelector correctly", func() {
 		cfg.Installation = &operatorv1.InstallationSpec{
 			ControlPlaneNodeSelector: map[string]string{"foo": "bar"},
@@ -459,4 +482,161 @@ var _ = Describe("Intrusion Detection rendering tests", func() {
 		Expect(idc.Spec.Template.Spec.Tolerations).To(ConsistOf(t))
 		Expect(job.Spec.Template.Spec.Tolerations).To(ConsistOf(t))
 	})
+
+	It("should install the selected alert templates and anomaly detectors", func() {
+		cfg.IntrusionDetection = &operatorv1.IntrusionDetection{
+			Spec: operatorv1.IntrusionDetectionSpec{
+				DisabledGlobalAlertTemplates: []string{"policy.pod", "network.ssh"},
+				AnomalyDetection: &operatorv1.AnomalyDetectionSpec{
+					DetectionPeriod: &metav1.Duration{Duration: 30 * time.Minute},
+					Detectors: []operatorv1.AnomalyDetector{
+						{Name: "dga"},
+						{Name: "port_scan", DetectionPeriod: &metav1.Duration{Duration: 5 * time.Minute}},
+					},
+				},
+			},
+		}
+		cfg.UserGlobalAlertTemplates = []*v3.GlobalAlertTemplate{{
+			TypeMeta:   metav1.TypeMeta{Kind: "GlobalAlertTemplate", APIVersion: "projectcalico.org/v3"},
+			ObjectMeta: metav1.ObjectMeta{Name: "user.template"},
+			Spec:       v3.GlobalAlertSpec{Description: "user template", Severity: 50, DataSet: "flows"},
+		}}
+		adAlert := func(name, detector string) *v3.GlobalAlert {
+			return &v3.GlobalAlert{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec: v3.GlobalAlertSpec{
+					Type:     v3.GlobalAlertTypeAnomalyDetection,
+					Detector: &v3.DetectorParams{Name: detector},
+				},
+			}
+		}
+		cfg.AnomalyDetectionGlobalAlerts = []*v3.GlobalAlert{
+			adAlert("my-dga", "dga"),
+			adAlert("my-ip-sweep", "ip_sweep"),
+		}
+		component := render.IntrusionDetection(cfg)
+		Expect(component.ResolveImages(nil)).To(BeNil())
+		resources, toDelete := component.Objects()
+
+		templates := map[string]*v3.GlobalAlertTemplate{}
+		for _, r := range resources {
+			if t, ok := r.(*v3.GlobalAlertTemplate); ok {
+				templates[t.Name] = t
+			}
+		}
+		Expect(templates).To(HaveKey("policy.globalnetworkpolicy"))
+		Expect(templates).To(HaveKey("user.template"))
+		Expect(templates).NotTo(HaveKey("policy.pod"))
+		Expect(templates).NotTo(HaveKey("network.ssh"))
+		Expect(templates).NotTo(HaveKey("tigera.io.detector.ip-sweep"))
+		Expect(templates["tigera.io.detector.dga"].Spec.Period.Duration).To(Equal(30 * time.Minute))
+		Expect(templates["tigera.io.detector.port-scan"].Spec.Period.Duration).To(Equal(5 * time.Minute))
+
+		var deleted []string
+		for _, r := range toDelete {
+			deleted = append(deleted, r.GetName())
+		}
+		Expect(deleted).To(ContainElements("policy.pod", "network.ssh", "tigera.io.detector.ip-sweep", "tigera.io.detector.l7-latency"))
+		Expect(deleted).NotTo(ContainElements("tigera.io.detector.dga", "tigera.io.detector.port-scan"))
+
+		By("deleting the GlobalAlerts that run the disabled detectors")
+		rtest.ExpectResource(rtest.GetResource(toDelete, "my-ip-sweep", "", "projectcalico.org", "v3", "GlobalAlert"),
+			"my-ip-sweep", "", "projectcalico.org", "v3", "GlobalAlert")
+		Expect(rtest.GetResource(toDelete, "my-dga", "", "projectcalico.org", "v3", "GlobalAlert")).To(BeNil())
+	})
+
+	It("should set the training cadence and the sensitivity of the anomaly detectors", func() {
+		low, high := operatorv1.AnomalyDetectorSensitivityLow, operatorv1.AnomalyDetectorSensitivityHigh
+		cfg.IntrusionDetection = &operatorv1.IntrusionDetection{
+			Spec: operatorv1.IntrusionDetectionSpec{
+				AnomalyDetection: &operatorv1.AnomalyDetectionSpec{
+					TrainingPeriod: &metav1.Duration{Duration: 12 * time.Hour},
+					Sensitivity:    &high,
+					Detectors: []operatorv1.AnomalyDetector{
+						{Name: "dga"},
+						{Name: "ip_sweep"},
+						{Name: "port_scan", Sensitivity: &low},
+					},
+				},
+			},
+		}
+		component := render.IntrusionDetection(cfg)
+		Expect(component.ResolveImages(nil)).To(BeNil())
+		resources, _ := component.Objects()
+
+		training := rtest.GetResource(resources, render.ADJobPodTemplateBaseName+".training", render.IntrusionDetectionNamespace, "", "v1", "PodTemplate").(*corev1.PodTemplate)
+		Expect(training.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "AD_train_interval_minutes", Value: "720"}))
+		Expect(envNames(training.Template.Spec.Containers[0].Env)).NotTo(ContainElement("AD_port_scan_threshold"))
+
+		detection := rtest.GetResource(resources, render.ADJobPodTemplateBaseName+".detection", render.IntrusionDetectionNamespace, "", "v1", "PodTemplate").(*corev1.PodTemplate)
+		env := detection.Template.Spec.Containers[0].Env
+		Expect(env).To(ContainElements(
+			corev1.EnvVar{Name: "AD_ip_sweep_threshold", Value: "16"},
+			corev1.EnvVar{Name: "AD_port_scan_threshold", Value: "1000"},
+		))
+		Expect(envNames(env)).NotTo(ContainElement("AD_ProcessRestarts_threshold"))
+		Expect(envNames(env)).NotTo(ContainElement("AD_train_interval_minutes"))
+	})
+
+	It("should delete the user defined alert templates that were removed", func() {
+		cfg.RemovedUserGlobalAlertTemplates = []string{"removed.template"}
+		component := render.IntrusionDetection(cfg)
+		Expect(component.ResolveImages(nil)).To(BeNil())
+		_, toDelete := component.Objects()
+
+		rtest.ExpectResource(rtest.GetResource(toDelete, "removed.template", "", "projectcalico.org", "v3", "GlobalAlertTemplate"),
+			"removed.template", "", "projectcalico.org", "v3", "GlobalAlertTemplate")
+	})
+
+	It("should serve threat feeds in ConfigMaps to the controller", func() {
+		cfg.IntrusionDetection = &operatorv1.IntrusionDetection{
+			Spec: operatorv1.IntrusionDetectionSpec{
+				ThreatFeeds: []operatorv1.ThreatFeed{
+					{Name: "remote", URL: "https://feeds.example.com/ips"},
+					{Name: "local", ConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "feeds"}, Key: "blocklist"}},
+				},
+			},
+		}
+		cfg.ThreatFeedConfigMaps = []*corev1.ConfigMap{{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "feeds", Namespace: "tigera-operator"},
+		}}
+		cfg.RemovedThreatFeeds = []string{"old"}
+		cfg.RemovedThreatFeedConfigMaps = []string{"old-feeds"}
+		cfg.RemovedThreatFeedSecrets = []string{"old-token"}
+		component := render.IntrusionDetection(cfg)
+		Expect(component.ResolveImages(nil)).To(BeNil())
+		resources, toDelete := component.Objects()
+
+		feeds := rtest.GetResource(resources, "feeds", render.IntrusionDetectionNamespace, "", "v1", "ConfigMap").(*corev1.ConfigMap)
+		Expect(feeds.Labels).To(HaveKey(render.ThreatFeedLabel))
+		Expect(rtest.GetResource(toDelete, "old-feeds", render.IntrusionDetectionNamespace, "", "v1", "ConfigMap")).NotTo(BeNil())
+		Expect(rtest.GetResource(toDelete, "old-token", render.IntrusionDetectionNamespace, "", "v1", "Secret")).NotTo(BeNil())
+		local := rtest.GetResource(resources, "local", "", "projectcalico.org", "v3", "GlobalThreatFeed").(*v3.GlobalThreatFeed)
+		Expect(local.Spec.Pull.HTTP.URL).To(Equal("http://127.0.0.1:8089/local"))
+		Expect(rtest.GetResource(toDelete, "old", "", "projectcalico.org", "v3", "GlobalThreatFeed")).NotTo(BeNil())
+
+		idc := rtest.GetResource(resources, "intrusion-detection-controller", render.IntrusionDetectionNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(idc.Spec.Template.Spec.Containers).To(HaveLen(2))
+		sidecar := idc.Spec.Template.Spec.Containers[1]
+		Expect(sidecar.Args).To(ConsistOf("--serve-threat-feeds=127.0.0.1:8089", "--threat-feeds-dir=/threat-feeds"))
+		Expect(sidecar.VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: "threat-feed-local", MountPath: "/threat-feeds/local", ReadOnly: true}))
+		Expect(idc.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: "threat-feed-local",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "feeds"},
+					Items:                []corev1.KeyToPath{{Key: "blocklist", Path: "feed"}},
+				},
+			},
+		}))
+	})
 })
+
+func envNames(envs []corev1.EnvVar) []string {
+	var names []string
+	for _, env := range envs {
+		names = append(names, env.Name)
+	}
+	return names
+}
@@ -15,6 +15,9 @@
 package dpi
 
 import (
+	"fmt"
+	"sort"
+
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/components"
@@ -22,6 +25,7 @@ import (
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	"github.com/tigera/operator/pkg/render/common/meta"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/secret"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -39,6 +43,9 @@ const (
 	DefaultMemoryRequest          = "100Mi"
 	DefaultCPULimit               = "1"
 	DefaultCPURequest             = "100m"
+
+	// NodePoolLabel is set on the DaemonSets of the node pools to the name of the pool.
+	NodePoolLabel = "operator.tigera.io/dpi-node-pool"
 )
 
 type DPIConfig struct {
@@ -52,6 +59,9 @@ type DPIConfig struct {
 	ESSecrets          []*corev1.Secret
 	ESClusterConfig    *relasticsearch.ClusterConfig
 	ClusterDomain      string
+
+	// RemovedNodePools are the names of node pools that still have a DaemonSet but are no longer configured.
+	RemovedNodePools []string
 }
 
 func DPI(cfg *DPIConfig) render.Component {
@@ -80,9 +90,9 @@ func (d *dpiComponent) ResolveImages(is *operatorv1.ImageSet) error {
 func (d *dpiComponent) Objects() (objsToCreate, objsToDelete []client.Object) {
 	var toCreate, toDelete []client.Object
 	if d.cfg.HasNoLicense {
-		toDelete = append(toDelete, render.CreateNamespace(DeepPacketInspectionNamespace, d.cfg.Installation.KubernetesProvider, render.PSSPrivileged))
+		toDelete = append(toDelete, render.CreateNamespace(DeepPacketInspectionNamespace, d.cfg.Installation, render.PSSPrivileged))
 	} else {
-		toCreate = append(toCreate, render.CreateNamespace(DeepPacketInspectionNamespace, d.cfg.Installation.KubernetesProvider, render.PSSPrivileged))
+		toCreate = append(toCreate, render.CreateNamespace(DeepPacketInspectionNamespace, d.cfg.Installation, render.PSSPrivileged))
 	}
 	if d.cfg.HasNoDPIResource || d.cfg.HasNoLicense {
 		toDelete = append(toDelete, &corev1.Secret{
@@ -94,8 +104,8 @@ func (d *dpiComponent) Objects() (objsToCreate, objsToDelete []client.Object) {
 			d.dpiServiceAccount(),
 			d.dpiClusterRole(),
 			d.dpiClusterRoleBinding(),
-			d.dpiDaemonset(),
 		)
+		toDelete = append(toDelete, d.dpiDaemonsets()...)
 	} else {
 		toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(DeepPacketInspectionNamespace, d.cfg.ESSecrets...)...)...)
 		toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(DeepPacketInspectionNamespace, d.cfg.PullSecrets...)...)...)
@@ -103,12 +113,112 @@ func (d *dpiComponent) Objects() (objsToCreate, objsToDelete []client.Object) {
 			d.dpiServiceAccount(),
 			d.dpiClusterRole(),
 			d.dpiClusterRoleBinding(),
-			d.dpiDaemonset(),
 		)
+		toCreate = append(toCreate, d.dpiDaemonsets()...)
+	}
+	for _, pool := range d.cfg.RemovedNodePools {
+		toDelete = append(toDelete, &appsv1.DaemonSet{
+			TypeMeta:   metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: NodePoolDaemonSetName(pool), Namespace: DeepPacketInspectionNamespace},
+		})
 	}
 	return toCreate, toDelete
 }
 
+// NodePoolDaemonSetName returns the name of the DaemonSet of a node pool.
+func NodePoolDaemonSetName(pool string) string {
+	return fmt.Sprintf("%s-%s", DeepPacketInspectionName, pool)
+}
+
+// dpiDaemonsets returns the DaemonSet for the nodes outside of the node pools, followed by a DaemonSet per node pool.
+func (d *dpiComponent) dpiDaemonsets() []client.Object {
+	spec := d.dpiSpec()
+	defaultResources := *d.cfg.IntrusionDetection.Spec.ComponentResources[0].ResourceRequirements
+	ds := d.dpiDaemonset(DeepPacketInspectionName, defaultResources)
+	if affinity := nodePoolsAntiAffinity(spec.NodePools); affinity != nil {
+		ds.Spec.Template.Spec.Affinity = affinity
+	}
+	objs := []client.Object{ds}
+
+	for _, pool := range spec.NodePools {
+		// Pools without their own resources use the resources of the DeepPacketInspection component.
+		resources := defaultResources
+		if pool.ResourceRequirements != nil {
+			resources = *pool.ResourceRequirements
+		}
+		poolDS := d.dpiDaemonset(NodePoolDaemonSetName(pool.Name), resources)
+		poolDS.Labels = map[string]string{NodePoolLabel: pool.Name}
+		for k, v := range pool.NodeSelector {
+			if poolDS.Spec.Template.Spec.NodeSelector == nil {
+				poolDS.Spec.Template.Spec.NodeSelector = map[string]string{}
+			}
+			poolDS.Spec.Template.Spec.NodeSelector[k] = v
+		}
+		objs = append(objs, poolDS)
+	}
+	return objs
+}
+
+// nodePoolsAntiAffinity returns the node affinity that keeps pods off the nodes of all the node pools. A node is
+// outside of a pool if it misses at least one of the labels of the pool, so each pool adds a NotIn requirement per
+// label to the terms, and the required terms are the combinations of a label per pool. Requirements on the same label
+// are merged into one, so that pools that select their nodes by a single label do not add terms.
+func nodePoolsAntiAffinity(pools []operatorv1.DeepPacketInspectionNodePool) *corev1.Affinity {
+	if len(pools) == 0 {
+		return nil
+	}
+	terms := [][]corev1.NodeSelectorRequirement{nil}
+	for _, pool := range pools {
+		keys := make([]string, 0, len(pool.NodeSelector))
+		for k := range pool.NodeSelector {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var next [][]corev1.NodeSelectorRequirement
+		for _, term := range terms {
+			for _, k := range keys {
+				next = append(next, withNotIn(term, k, pool.NodeSelector[k]))
+			}
+		}
+		terms = next
+	}
+
+	selector := &corev1.NodeSelector{}
+	for _, term := range terms {
+		selector.NodeSelectorTerms = append(selector.NodeSelectorTerms, corev1.NodeSelectorTerm{MatchExpressions: term})
+	}
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: selector},
+	}
+}
+
+// withNotIn returns a copy of the requirements that also excludes the value of the label, sorted by label.
+func withNotIn(term []corev1.NodeSelectorRequirement, key, value string) []corev1.NodeSelectorRequirement {
+	reqs := make([]corev1.NodeSelectorRequirement, 0, len(term)+1)
+	merged := false
+	for _, req := range term {
+		if req.Key == key {
+			req.Values = append(append([]string{}, req.Values...), value)
+			sort.Strings(req.Values)
+			merged = true
+		}
+		reqs = append(reqs, req)
+	}
+	if !merged {
+		reqs = append(reqs, corev1.NodeSelectorRequirement{Key: key, Operator: corev1.NodeSelectorOpNotIn, Values: []string{value}})
+		sort.Slice(reqs, func(i, j int) bool { return reqs[i].Key < reqs[j].Key })
+	}
+	return reqs
+}
+
+func (d *dpiComponent) dpiSpec() operatorv1.DeepPacketInspectionSpec {
+	if d.cfg.IntrusionDetection.Spec.DeepPacketInspection == nil {
+		return operatorv1.DeepPacketInspectionSpec{}
+	}
+	return *d.cfg.IntrusionDetection.Spec.DeepPacketInspection
+}
+
 func (d *dpiComponent) Ready() bool {
 	return true
 }
@@ -117,19 +227,33 @@ func (d *dpiComponent) SupportedOSType() meta.OSType {
 	return rmeta.OSTypeLinux
 }
 
-func (d *dpiComponent) dpiDaemonset() *appsv1.DaemonSet {
+func (d *dpiComponent) dpiDaemonset(name string, resources corev1.ResourceRequirements) *appsv1.DaemonSet {
 	var terminationGracePeriod int64 = 0
 	var initContainers []corev1.Container
 	if d.cfg.TyphaNodeTLS.NodeSecret.UseCertificateManagement() {
 		initContainers = append(initContainers, d.cfg.TyphaNodeTLS.NodeSecret.InitContainer(DeepPacketInspectionNamespace))
 	}
 
+	spec := d.dpiSpec()
+	tolerations := rmeta.TolerateAll
+	if spec.Tolerations != nil {
+		tolerations = spec.Tolerations
+	}
+	var nodeSelector map[string]string
+	for k, v := range spec.NodeSelector {
+		if nodeSelector == nil {
+			nodeSelector = map[string]string{}
+		}
+		nodeSelector[k] = v
+	}
+
 	podTemplate := relasticsearch.DecorateAnnotations(&corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Annotations: d.dpiAnnotations(),
 		},
 		Spec: corev1.PodSpec{
-			Tolerations:                   rmeta.TolerateAll,
+			NodeSelector:                  nodeSelector,
+			Tolerations:                   tolerations,
 			ImagePullSecrets:              secret.GetReferenceList(d.cfg.PullSecrets),
 			ServiceAccountName:            DeepPacketInspectionName,
 			TerminationGracePeriodSeconds: &terminationGracePeriod,
@@ -137,14 +261,14 @@ func (d *dpiComponent) dpiDaemonset() *appsv1.DaemonSet {
 			// Adjust DNS policy so we can access in-cluster services.
 			DNSPolicy:      corev1.DNSClusterFirstWithHostNet,
 			InitContainers: initContainers,
-			Containers:     []corev1.Container{d.dpiContainer()},
+			Containers:     []corev1.Container{d.dpiContainer(resources)},
 			Volumes:        d.dpiVolumes(),
 		},
 	}, d.cfg.ESClusterConfig, d.cfg.ESSecrets).(*corev1.PodTemplateSpec)
 	return &appsv1.DaemonSet{
 		TypeMeta: metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      DeepPacketInspectionName,
+			Name:      name,
 			Namespace: DeepPacketInspectionNamespace,
 		},
 		Spec: appsv1.DaemonSetSpec{
@@ -153,7 +277,7 @@ func (d *dpiComponent) dpiDaemonset() *appsv1.DaemonSet {
 	}
 }
 
-func (d *dpiComponent) dpiContainer() corev1.Container {
+func (d *dpiComponent) dpiContainer(resources corev1.ResourceRequirements) corev1.Container {
 	privileged := false
 	// On OpenShift Snort needs privileged access to access host network
 	if d.cfg.Openshift {
@@ -161,15 +285,13 @@ func (d *dpiComponent) dpiContainer() corev1.Container {
 	}
 
 	dpiContainer := corev1.Container{
-		Name:         DeepPacketInspectionName,
-		Image:        d.dpiImage,
-		Resources:    *d.cfg.IntrusionDetection.Spec.ComponentResources[0].ResourceRequirements,
-		Env:          d.dpiEnvVars(),
-		VolumeMounts: d.dpiVolumeMounts(),
-		SecurityContext: &corev1.SecurityContext{
-			Privileged: &privileged,
-		},
-		ReadinessProbe: d.dpiReadinessProbes(),
+		Name:            DeepPacketInspectionName,
+		Image:           d.dpiImage,
+		Resources:       resources,
+		Env:             d.dpiEnvVars(),
+		VolumeMounts:    d.dpiVolumeMounts(),
+		SecurityContext: podsecuritycontext.NewRootContext(privileged),
+		ReadinessProbe:  d.dpiReadinessProbes(),
 	}
 
 	return relasticsearch.ContainerDecorateIndexCreator(
@@ -24,6 +24,7 @@ import (
 	"github.com/tigera/operator/pkg/dns"
 	"github.com/tigera/operator/pkg/render"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
 	"github.com/tigera/operator/pkg/render/intrusiondetection/dpi"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
@@ -340,6 +341,63 @@ var _ = Describe("DPI rendering tests", func() {
 			rtest.ExpectResource(createResources[i], expectedRes.name, expectedRes.ns, expectedRes.group, expectedRes.version, expectedRes.kind)
 		}
 	})
+
+	It("should scope deep packet inspection to the configured nodes and node pools", func() {
+		poolLimit := resource.MustParse("4Gi")
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DeepPacketInspection = &operatorv1.DeepPacketInspectionSpec{
+			NodeSelector: map[string]string{"dpi": "enabled"},
+			Tolerations:  []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "dpi", Effect: corev1.TaintEffectNoSchedule}},
+			NodePools: []operatorv1.DeepPacketInspectionNodePool{
+				{
+					Name:                 "large",
+					NodeSelector:         map[string]string{"pool": "large"},
+					ResourceRequirements: &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: poolLimit}},
+				},
+				{Name: "edge", NodeSelector: map[string]string{"zone": "edge", "tier": "small"}},
+				{Name: "medium", NodeSelector: map[string]string{"pool": "medium"}},
+			},
+		}
+
+		component := dpi.DPI(&dpi.DPIConfig{
+			IntrusionDetection: ids2,
+			Installation:       &operatorv1.InstallationSpec{Registry: "testregistry.com/"},
+			TyphaNodeTLS:       typhaNodeTLS,
+			PullSecrets:        pullSecrets,
+			ESClusterConfig:    esConfigMap,
+			ClusterDomain:      dns.DefaultClusterDomain,
+			RemovedNodePools:   []string{"old"},
+		})
+
+		resources, deleteResources := component.Objects()
+
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"dpi": "enabled"}))
+		Expect(ds.Spec.Template.Spec.Tolerations).To(Equal(ids2.Spec.DeepPacketInspection.Tolerations))
+		Expect(ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(Equal([]corev1.NodeSelectorTerm{
+			{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "pool", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"large", "medium"}},
+				{Key: "tier", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"small"}},
+			}},
+			{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "pool", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"large", "medium"}},
+				{Key: "zone", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"edge"}},
+			}},
+		}))
+		Expect(*ds.Spec.Template.Spec.Containers[0].Resources.Limits.Memory()).To(Equal(resource.MustParse(dpi.DefaultMemoryLimit)))
+
+		large := rtest.GetResource(resources, "tigera-dpi-large", dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(large.Labels).To(HaveKeyWithValue(dpi.NodePoolLabel, "large"))
+		Expect(large.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"dpi": "enabled", "pool": "large"}))
+		Expect(large.Spec.Template.Spec.Affinity).To(BeNil())
+		Expect(large.Spec.Template.Spec.Containers[0].Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceMemory: poolLimit}))
+
+		edge := rtest.GetResource(resources, "tigera-dpi-edge", dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(edge.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"dpi": "enabled", "zone": "edge", "tier": "small"}))
+		Expect(edge.Spec.Template.Spec.Containers[0].Resources).To(Equal(ds.Spec.Template.Spec.Containers[0].Resources))
+
+		Expect(rtest.GetResource(deleteResources, "tigera-dpi-old", dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet")).NotTo(BeNil())
+	})
 })
 
 func validateDPIComponents(resources []client.Object, openshift bool) {
@@ -365,15 +423,5 @@ func validateDPIComponents(resources []client.Object, openshift bool) {
 	Expect(dpiDaemonSet.Spec.Template.Spec.NodeSelector).To(BeNil())
 
 	Expect(dpiDaemonSet.Spec.Template.Spec.Containers[0].VolumeMounts).Should(ContainElements(expectedVolumeMounts))
-	if !openshift {
-		privileged := false
-		Expect(dpiDaemonSet.Spec.Template.Spec.Containers[0].SecurityContext).Should(Equal(&corev1.SecurityContext{
-			Privileged: &privileged,
-		}))
-	} else {
-		privileged := true
-		Expect(dpiDaemonSet.Spec.Template.Spec.Containers[0].SecurityContext).Should(Equal(&corev1.SecurityContext{
-			Privileged: &privileged,
-		}))
-	}
+	Expect(dpiDaemonSet.Spec.Template.Spec.Containers[0].SecurityContext).Should(Equal(podsecuritycontext.NewRootContext(openshift)))
 }
@@ -15,8 +15,10 @@
 package kubecontrollers
 
 import (
+	"net"
 	"strings"
 
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
@@ -31,7 +33,10 @@ import (
 	"github.com/tigera/operator/pkg/controller/k8sapi"
 	"github.com/tigera/operator/pkg/render"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/podsecuritypolicy"
 	"github.com/tigera/operator/pkg/render/common/secret"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
@@ -85,6 +90,10 @@ type KubeControllersConfiguration struct {
 
 	// Whether or not the cluster supports pod security policies.
 	UsePSP bool
+
+	// NetworkPolicyState determines whether the policies for kube-controllers in the allow-tigera tier are created or
+	// removed.
+	NetworkPolicyState networkpolicy.State
 }
 
 func NewCalicoKubeControllers(cfg *KubeControllersConfiguration) *kubeControllersComponent {
@@ -230,6 +239,16 @@ func (c *kubeControllersComponent) Objects() ([]client.Object, []client.Object)
 		objectsToDelete = append(objectsToDelete, c.prometheusService())
 	}
 
+	// The default-deny policy for calico-system is owned by the calico kube-controllers, the only other pods in the
+	// namespace run in the host network namespace.
+	policies := []client.Object{c.networkPolicy()}
+	if c.kubeControllerName == KubeController {
+		policies = append(policies, networkpolicy.AllowTigeraDefaultDeny(common.CalicoNamespace))
+	}
+	policiesToCreate, policiesToDelete := c.cfg.NetworkPolicyState.Split(policies...)
+	objectsToCreate = append(objectsToCreate, policiesToCreate...)
+	objectsToDelete = append(objectsToDelete, policiesToDelete...)
+
 	if c.cfg.Terminating {
 		objectsToDelete = append(objectsToDelete, objectsToCreate...)
 		objectsToCreate = nil
@@ -238,6 +257,31 @@ func (c *kubeControllersComponent) Objects() ([]client.Object, []client.Object)
 	return objectsToCreate, objectsToDelete
 }
 
+// networkPolicy allows Prometheus to scrape kube-controllers, and kube-controllers to reach the API server, the
+// es-gateway for the Elasticsearch kube-controllers and, for the Calico Enterprise kube-controllers, the API servers
+// of the remote clusters it federates services from.
+func (c *kubeControllersComponent) networkPolicy() *v3.NetworkPolicy {
+	egress := networkpolicy.AllowDNSRules(c.cfg.Installation.KubernetesProvider == operatorv1.ProviderOpenShift)
+	egress = append(egress, networkpolicy.AllowKubeAPIServerRule())
+	if c.cfg.K8sServiceEp.Host != "" {
+		// kube-controllers reaches the API server directly rather than through the kubernetes service.
+		egress = append(egress, networkpolicy.AllowAddressRule(net.JoinHostPort(c.cfg.K8sServiceEp.Host, c.cfg.K8sServiceEp.Port)))
+	}
+	if c.kubeControllerName == EsKubeController {
+		egress = append(egress, networkpolicy.AllowTCPRule(networkpolicy.ESGatewayEntityRule))
+	} else if c.cfg.Installation.Variant == operatorv1.TigeraSecureEnterprise {
+		// The addresses of the remote clusters are only known from the RemoteClusterConfigurations, so the API
+		// servers are matched by the ports they listen on.
+		egress = append(egress, networkpolicy.AllowTCPRule(v3.EntityRule{Ports: networkpolicy.Ports(443, 6443)}))
+	}
+
+	var ingress []v3.Rule
+	if c.cfg.MetricsPort != 0 {
+		ingress = networkpolicy.ServiceIngressRules(c.prometheusService(), networkpolicy.PrometheusSourceEntityRule)
+	}
+	return networkpolicy.AllowTigeraPolicy(c.kubeControllerName, common.CalicoNamespace, ingress, egress)
+}
+
 func (c *kubeControllersComponent) Ready() bool {
 	return true
 }
@@ -405,6 +449,10 @@ func (c *kubeControllersComponent) controllersDeployment() *appsv1.Deployment {
 
 	env = append(env, c.cfg.K8sServiceEp.EnvVars(false, c.cfg.Installation.KubernetesProvider)...)
 
+	if level := loglevel.Resolve(c.cfg.Installation, nil); level != "" {
+		env = append(env, corev1.EnvVar{Name: "LOG_LEVEL", Value: loglevel.Logrus(level, "")})
+	}
+
 	if c.cfg.Installation.Variant == operatorv1.TigeraSecureEnterprise {
 		if c.kubeControllerName == EsKubeController {
 			if c.cfg.EnabledESOIDCWorkaround {
@@ -428,10 +476,11 @@ func (c *kubeControllersComponent) controllersDeployment() *appsv1.Deployment {
 	}
 
 	container := corev1.Container{
-		Name:      c.kubeControllerName,
-		Image:     c.image,
-		Env:       env,
-		Resources: c.kubeControllersResources(),
+		Name:            c.kubeControllerName,
+		Image:           c.image,
+		Env:             env,
+		Resources:       c.kubeControllersResources(),
+		SecurityContext: podsecuritycontext.NewBaseContext(),
 		ReadinessProbe: &corev1.Probe{
 			PeriodSeconds: int32(10),
 			Handler: corev1.Handler{
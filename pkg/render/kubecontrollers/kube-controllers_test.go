@@ -19,6 +19,7 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
@@ -29,6 +30,7 @@ import (
 	"github.com/tigera/operator/pkg/dns"
 	"github.com/tigera/operator/pkg/render"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
 	"github.com/tigera/operator/pkg/render/kubecontrollers"
 	"github.com/tigera/operator/pkg/render/testutils"
@@ -610,4 +612,32 @@ var _ = Describe("kube-controllers rendering tests", func() {
 		deployment := depResource.(*appsv1.Deployment)
 		rtest.ExpectNoK8sServiceEpEnvVars(deployment.Spec.Template.Spec)
 	})
+
+	It("should render explicit egress rules in the allow-tigera policy", func() {
+		instance.Variant = operatorv1.TigeraSecureEnterprise
+		cfg.NetworkPolicyState = networkpolicy.StateEnabled
+		cfg.K8sServiceEp = k8sapi.ServiceEndpoint{Host: "10.0.0.1", Port: "6443"}
+
+		component := kubecontrollers.NewCalicoKubeControllers(&cfg)
+		Expect(component.ResolveImages(nil)).To(BeNil())
+		resources, _ := component.Objects()
+
+		policy := rtest.GetResource(resources, "allow-tigera.calico-kube-controllers", common.CalicoNamespace, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+		Expect(policy.Spec.Egress).To(ContainElements(
+			networkpolicy.AllowKubeAPIServerRule(),
+			v3.Rule{
+				Action:      v3.Allow,
+				Protocol:    &networkpolicy.TCPProtocol,
+				Destination: v3.EntityRule{Nets: []string{"10.0.0.1/32"}, Ports: networkpolicy.Ports(6443)},
+			},
+			v3.Rule{
+				Action:      v3.Allow,
+				Protocol:    &networkpolicy.TCPProtocol,
+				Destination: v3.EntityRule{Ports: networkpolicy.Ports(443, 6443)},
+			},
+		))
+		for _, rule := range policy.Spec.Egress {
+			Expect(rule.Action).To(Equal(v3.Allow))
+		}
+	})
 })
@@ -27,6 +27,7 @@ import (
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
 
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	"gopkg.in/inf.v0"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
@@ -46,7 +47,9 @@ import (
 	"github.com/tigera/operator/pkg/ptr"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	"github.com/tigera/operator/pkg/render/common/podaffinity"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/podsecuritypolicy"
 	"github.com/tigera/operator/pkg/render/common/secret"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
@@ -122,6 +125,12 @@ const (
 
 	TimeFilter         = "_g=(time:(from:now-24h,to:now))"
 	FlowsDashboardName = "Tigera Secure EE Flow Logs"
+
+	// ElasticsearchSnapshotS3CredentialSecret holds the credentials of the S3 snapshot repository. It is read from the
+	// operator namespace and copied into the Elasticsearch namespace.
+	ElasticsearchSnapshotS3CredentialSecret = "elasticsearch-snapshot-s3-credentials"
+	// ElasticsearchSnapshotsPath is where a PersistentVolumeClaim snapshot repository is mounted in the Elasticsearch pods.
+	ElasticsearchSnapshotsPath = "/usr/share/elasticsearch/snapshots"
 )
 
 const (
@@ -138,6 +147,8 @@ const (
 	caVolumeName = "elasticsearch-certs"
 )
 
+const snapshotsVolumeName = "elasticsearch-snapshots"
+
 var log = logf.Log.WithName("render")
 
 // LogStorage renders the components necessary for kibana and elasticsearch
@@ -170,8 +181,16 @@ type ElasticsearchConfiguration struct {
 	TrustedBundle               certificatemanagement.TrustedBundle
 	UnusedTLSSecret             *corev1.Secret
 
+	// SnapshotS3Credential is used by Elasticsearch to access the S3 snapshot repository when
+	// LogStorage.ElasticsearchSnapshots.Repository.S3 is set.
+	SnapshotS3Credential *S3Credential
+
 	// Whether or not the cluster supports pod security policies.
 	UsePSP bool
+
+	// NetworkPolicyState determines whether the policies for Elasticsearch, Kibana and the curator in the
+	// allow-tigera tier are created or removed.
+	NetworkPolicyState networkpolicy.State
 }
 
 type elasticsearchComponent struct {
@@ -253,7 +272,7 @@ func (es *elasticsearchComponent) Objects() ([]client.Object, []client.Object) {
 
 		// ECK CRs
 		toCreate = append(toCreate,
-			CreateNamespace(ECKOperatorNamespace, es.cfg.Installation.KubernetesProvider, PSSRestricted),
+			CreateNamespace(ECKOperatorNamespace, es.cfg.Installation, PSSRestricted),
 		)
 
 		toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(ECKOperatorNamespace, es.cfg.PullSecrets...)...)...)
@@ -288,7 +307,7 @@ func (es *elasticsearchComponent) Objects() ([]client.Object, []client.Object) {
 		toCreate = append(toCreate, es.eckOperatorStatefulSet())
 
 		// Elasticsearch CRs
-		toCreate = append(toCreate, CreateNamespace(ElasticsearchNamespace, es.cfg.Installation.KubernetesProvider, PSSPrivileged))
+		toCreate = append(toCreate, CreateNamespace(ElasticsearchNamespace, es.cfg.Installation, PSSPrivileged))
 
 		if len(es.cfg.PullSecrets) > 0 {
 			toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(ElasticsearchNamespace, es.cfg.PullSecrets...)...)...)
@@ -301,10 +320,16 @@ func (es *elasticsearchComponent) Objects() ([]client.Object, []client.Object) {
 		toCreate = append(toCreate, es.elasticsearchServiceAccount())
 		toCreate = append(toCreate, es.cfg.ClusterConfig.ConfigMap())
 
+		if es.snapshotS3Repository() != nil {
+			toCreate = append(toCreate, es.snapshotS3CredentialSecret())
+		} else {
+			toDelete = append(toDelete, es.snapshotS3CredentialSecret())
+		}
+
 		toCreate = append(toCreate, es.elasticsearchCluster())
 
 		// Kibana CRs
-		toCreate = append(toCreate, CreateNamespace(KibanaNamespace, es.cfg.Installation.KubernetesProvider, PSSRestricted))
+		toCreate = append(toCreate, CreateNamespace(KibanaNamespace, es.cfg.Installation, PSSRestricted))
 		toCreate = append(toCreate, es.kibanaServiceAccount())
 
 		if len(es.cfg.PullSecrets) > 0 {
@@ -339,6 +364,16 @@ func (es *elasticsearchComponent) Objects() ([]client.Object, []client.Object) {
 		toCreate = append(toCreate, es.oidcUserRole())
 		toCreate = append(toCreate, es.oidcUserRoleBinding())
 
+		policies, policiesToDelete := es.cfg.NetworkPolicyState.Split(
+			networkpolicy.AllowTigeraDefaultDeny(ElasticsearchNamespace),
+			networkpolicy.AllowTigeraDefaultDeny(KibanaNamespace),
+			es.elasticsearchNetworkPolicy(),
+			es.kibanaNetworkPolicy(),
+			es.curatorNetworkPolicy(),
+		)
+		toCreate = append(toCreate, policies...)
+		toDelete = append(toDelete, policiesToDelete...)
+
 		// If we converted from a ManagedCluster to a Standalone or Management then we need to delete the elasticsearch
 		// service as it differs between these cluster types
 		if es.cfg.ESService != nil && es.cfg.ESService.Spec.Type == corev1.ServiceTypeExternalName {
@@ -350,7 +385,7 @@ func (es *elasticsearchComponent) Objects() ([]client.Object, []client.Object) {
 		}
 	} else {
 		toCreate = append(toCreate,
-			CreateNamespace(ElasticsearchNamespace, es.cfg.Installation.KubernetesProvider, PSSPrivileged),
+			CreateNamespace(ElasticsearchNamespace, es.cfg.Installation, PSSPrivileged),
 			es.elasticsearchExternalService(),
 		)
 	}
@@ -495,12 +530,9 @@ func (es elasticsearchComponent) podTemplate() corev1.PodTemplateSpec {
 
 	// https://www.elastic.co/guide/en/elasticsearch/reference/current/vm-max-map-count.html
 	initOSSettingsContainer := corev1.Container{
-		Name: "elastic-internal-init-os-settings",
-		SecurityContext: &corev1.SecurityContext{
-			Privileged: ptr.BoolToPtr(true),
-			RunAsUser:  ptr.Int64ToPtr(0),
-		},
-		Image: es.esImage,
+		Name:            "elastic-internal-init-os-settings",
+		SecurityContext: podsecuritycontext.NewRootContext(true),
+		Image:           es.esImage,
 		Command: []string{
 			"/bin/sh",
 		},
@@ -606,6 +638,18 @@ func (es elasticsearchComponent) podTemplate() corev1.PodTemplateSpec {
 		)
 	}
 
+	if claim := es.snapshotPVCRepository(); claim != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: snapshotsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim.ClaimName},
+			},
+		})
+		esContainer.VolumeMounts = append(esContainer.VolumeMounts,
+			corev1.VolumeMount{MountPath: ElasticsearchSnapshotsPath, Name: snapshotsVolumeName},
+		)
+	}
+
 	// Init container that logs the SELinux context of the `/usr/share/elasticsearch` folder.
 	// This init container is added as a workaround for a bug where Elasticsearch fails to starts when
 	// under some scenarios Kuberentes starts the main container before all the init containers have
@@ -697,9 +741,51 @@ func (es elasticsearchComponent) elasticsearchCluster() *esv1.Elasticsearch {
 		},
 	}
 
+	// The credentials of the S3 snapshot repository are added to the Elasticsearch keystore.
+	if es.snapshotS3Repository() != nil {
+		elasticsearch.Spec.SecureSettings = []cmnv1.SecretSource{{
+			SecretName: ElasticsearchSnapshotS3CredentialSecret,
+			Entries: []cmnv1.KeyToPath{
+				{Key: S3KeyIdName, Path: "s3.client.default.access_key"},
+				{Key: S3KeySecretName, Path: "s3.client.default.secret_key"},
+			},
+		}}
+	}
+
 	return elasticsearch
 }
 
+func (es elasticsearchComponent) snapshotS3Repository() *operatorv1.S3SnapshotRepository {
+	if es.cfg.LogStorage.Spec.ElasticsearchSnapshots == nil {
+		return nil
+	}
+	return es.cfg.LogStorage.Spec.ElasticsearchSnapshots.Repository.S3
+}
+
+func (es elasticsearchComponent) snapshotPVCRepository() *operatorv1.PVCSnapshotRepository {
+	if es.cfg.LogStorage.Spec.ElasticsearchSnapshots == nil {
+		return nil
+	}
+	return es.cfg.LogStorage.Spec.ElasticsearchSnapshots.Repository.PersistentVolumeClaim
+}
+
+func (es elasticsearchComponent) snapshotS3CredentialSecret() *corev1.Secret {
+	s := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ElasticsearchSnapshotS3CredentialSecret,
+			Namespace: ElasticsearchNamespace,
+		},
+	}
+	if es.cfg.SnapshotS3Credential != nil {
+		s.Data = map[string][]byte{
+			S3KeyIdName:     es.cfg.SnapshotS3Credential.KeyId,
+			S3KeySecretName: es.cfg.SnapshotS3Credential.KeySecret,
+		}
+	}
+	return s
+}
+
 // Determine the recommended JVM heap size as a string (with appropriate unit suffix) based on
 // the given resource.Quantity.
 //
@@ -875,6 +961,17 @@ func (es elasticsearchComponent) nodeSetTemplate(pvcTemplate corev1.PersistentVo
 		config["xpack.security.http.ssl.certificate_authorities"] = []string{"/usr/share/elasticsearch/config/http-certs/ca.crt"}
 	}
 
+	if s3 := es.snapshotS3Repository(); s3 != nil {
+		if s3.Endpoint != "" {
+			config["s3.client.default.endpoint"] = s3.Endpoint
+		}
+		if s3.PathStyleAccess {
+			config["s3.client.default.path_style_access"] = true
+		}
+	} else if es.snapshotPVCRepository() != nil {
+		config["path.repo"] = []string{ElasticsearchSnapshotsPath}
+	}
+
 	return esv1.NodeSet{
 		// This is configuration that ends up in /usr/share/elasticsearch/config/elasticsearch.yml on the Elastic container.
 		Config: &cmnv1.Config{
@@ -1301,8 +1398,6 @@ func (es elasticsearchComponent) kibanaCR() *kbv1.Kibana {
 }
 
 func (es elasticsearchComponent) curatorCronJob() *batchv1beta.CronJob {
-	f := false
-	t := true
 	elasticCuratorLivenessProbe := &corev1.Probe{
 		Handler: corev1.Handler{
 			Exec: &corev1.ExecAction{
@@ -1349,14 +1444,11 @@ func (es elasticsearchComponent) curatorCronJob() *batchv1beta.CronJob {
 							Tolerations:  es.cfg.Installation.ControlPlaneTolerations,
 							Containers: []corev1.Container{
 								relasticsearch.ContainerDecorate(corev1.Container{
-									Name:          EsCuratorName,
-									Image:         es.curatorImage,
-									Env:           es.curatorEnvVars(),
-									LivenessProbe: elasticCuratorLivenessProbe,
-									SecurityContext: &corev1.SecurityContext{
-										RunAsNonRoot:             &t,
-										AllowPrivilegeEscalation: &f,
-									},
+									Name:            EsCuratorName,
+									Image:           es.curatorImage,
+									Env:             es.curatorEnvVars(),
+									LivenessProbe:   elasticCuratorLivenessProbe,
+									SecurityContext: podsecuritycontext.NewBaseContext(),
 									VolumeMounts: []corev1.VolumeMount{
 										es.cfg.TrustedBundle.VolumeMount(es.SupportedOSType()),
 									},
@@ -1631,3 +1723,44 @@ func overridePvcRequirements(defaultReq corev1.ResourceRequirements, userOverrid
 	}
 	return updatedReq
 }
+
+// elasticsearchNetworkPolicy allows the Elasticsearch nodes to talk to each other and the components that front or
+// manage Elasticsearch to reach its HTTP port.
+func (es elasticsearchComponent) elasticsearchNetworkPolicy() *v3.NetworkPolicy {
+	esNodes := v3.EntityRule{
+		NamespaceSelector: networkpolicy.ElasticsearchEntityRule.NamespaceSelector,
+		Selector:          networkpolicy.ElasticsearchEntityRule.Selector,
+	}
+	ingress := []v3.Rule{
+		networkpolicy.AllowTCPFromRule(networkpolicy.CreateSourceEntityRule(ElasticsearchNamespace, "tigera-secure-es-gateway"), 9200),
+		networkpolicy.AllowTCPFromRule(networkpolicy.CreateSourceEntityRule(ElasticsearchNamespace, "tigera-elasticsearch-metrics"), 9200),
+		networkpolicy.AllowTCPFromRule(v3.EntityRule{NamespaceSelector: fmt.Sprintf("projectcalico.org/name == '%s'", ECKOperatorNamespace)}, 9200),
+		networkpolicy.AllowTCPFromRule(v3.EntityRule{
+			NamespaceSelector: networkpolicy.KibanaEntityRule.NamespaceSelector,
+			Selector:          networkpolicy.KibanaEntityRule.Selector,
+		}, 9200),
+		networkpolicy.AllowTCPFromRule(esNodes, 9200, 9300),
+	}
+	esNodes.Ports = networkpolicy.Ports(9300)
+	egress := networkpolicy.AllowDNSRules(es.cfg.Provider == operatorv1.ProviderOpenShift)
+	egress = append(egress, networkpolicy.AllowTCPRule(esNodes))
+	return networkpolicy.AllowTigeraPolicyForSelector("elasticsearch-access", ElasticsearchNamespace, esNodes.Selector, ingress, egress)
+}
+
+// kibanaNetworkPolicy allows the es-gateway and the ECK operator to reach Kibana, and Kibana to reach Elasticsearch.
+func (es elasticsearchComponent) kibanaNetworkPolicy() *v3.NetworkPolicy {
+	ingress := []v3.Rule{
+		networkpolicy.AllowTCPFromRule(networkpolicy.CreateSourceEntityRule(ElasticsearchNamespace, "tigera-secure-es-gateway"), 5601),
+		networkpolicy.AllowTCPFromRule(v3.EntityRule{NamespaceSelector: fmt.Sprintf("projectcalico.org/name == '%s'", ECKOperatorNamespace)}, 5601),
+	}
+	egress := networkpolicy.AllowDNSRules(es.cfg.Provider == operatorv1.ProviderOpenShift)
+	egress = append(egress, networkpolicy.AllowTCPRule(networkpolicy.ElasticsearchEntityRule))
+	return networkpolicy.AllowTigeraPolicyForSelector("kibana-access", KibanaNamespace, networkpolicy.KibanaEntityRule.Selector, ingress, egress)
+}
+
+// curatorNetworkPolicy allows the curator to reach Elasticsearch through the es-gateway.
+func (es elasticsearchComponent) curatorNetworkPolicy() *v3.NetworkPolicy {
+	egress := networkpolicy.AllowDNSRules(es.cfg.Provider == operatorv1.ProviderOpenShift)
+	egress = append(egress, networkpolicy.AllowTCPRule(networkpolicy.ESGatewayEntityRule))
+	return networkpolicy.AllowTigeraPolicy(EsCuratorName, ElasticsearchNamespace, nil, egress)
+}
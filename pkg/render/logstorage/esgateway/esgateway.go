@@ -18,7 +18,9 @@ import (
 	"fmt"
 	"strings"
 
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
 	"github.com/tigera/operator/pkg/render/common/elasticsearch"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -30,8 +32,11 @@ import (
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	"github.com/tigera/operator/pkg/render/common/podaffinity"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/secret"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 )
@@ -73,6 +78,12 @@ type Config struct {
 	TrustedBundle              certificatemanagement.TrustedBundle
 	ClusterDomain              string
 	EsAdminUserName            string
+
+	// Autoscaling, if set, scales the replicas of the es-gateway with a HorizontalPodAutoscaler.
+	Autoscaling *operatorv1.Autoscaling
+
+	// NetworkPolicyState determines whether the policy for the es-gateway in the allow-tigera tier is created or removed.
+	NetworkPolicyState networkpolicy.State
 }
 
 func (e *esGateway) ResolveImages(is *operatorv1.ImageSet) error {
@@ -104,7 +115,14 @@ func (e *esGateway) Objects() (toCreate, toDelete []client.Object) {
 	toCreate = append(toCreate, e.esGatewayRole())
 	toCreate = append(toCreate, e.esGatewayRoleBinding())
 	toCreate = append(toCreate, e.esGatewayServiceAccount())
-	toCreate = append(toCreate, e.esGatewayDeployment())
+	deployment := e.esGatewayDeployment()
+	autoscalingObjs, autoscalingObjsToDelete := autoscaling.Objects(deployment, e.cfg.Autoscaling)
+	toCreate = append(toCreate, deployment)
+	toCreate = append(toCreate, autoscalingObjs...)
+	toDelete = append(toDelete, autoscalingObjsToDelete...)
+	policies, policiesToDelete := e.cfg.NetworkPolicyState.Split(e.esGatewayNetworkPolicy())
+	toCreate = append(toCreate, policies...)
+	toDelete = append(toDelete, policiesToDelete...)
 	// The following secret is used by the kube controllers and sent to managed clusters. It is also used by manifests in our docs.
 	if e.cfg.ESGatewayKeyPair.UseCertificateManagement() {
 		toCreate = append(toCreate, render.CreateCertificateSecret(e.cfg.Installation.CertificateManagement.CACert, elasticsearch.PublicCertSecret, common.OperatorNamespace()))
@@ -164,7 +182,7 @@ func (e esGateway) esGatewayRoleBinding() *rbacv1.RoleBinding {
 
 func (e esGateway) esGatewayDeployment() *appsv1.Deployment {
 	envVars := []corev1.EnvVar{
-		{Name: "ES_GATEWAY_LOG_LEVEL", Value: "INFO"},
+		{Name: "ES_GATEWAY_LOG_LEVEL", Value: loglevel.Logrus(loglevel.Resolve(e.cfg.Installation, nil), "INFO")},
 		{Name: "ES_GATEWAY_ELASTIC_ENDPOINT", Value: ElasticsearchHTTPSEndpoint},
 		{Name: "ES_GATEWAY_KIBANA_ENDPOINT", Value: KibanaHTTPSEndpoint},
 		{Name: "ES_GATEWAY_HTTPS_CERT", Value: e.cfg.ESGatewayKeyPair.VolumeMountCertificateFilePath()},
@@ -216,10 +234,11 @@ func (e esGateway) esGatewayDeployment() *appsv1.Deployment {
 			InitContainers:     initContainers,
 			Containers: []corev1.Container{
 				{
-					Name:         DeploymentName,
-					Image:        e.esGatewayImage,
-					Env:          envVars,
-					VolumeMounts: volumeMounts,
+					Name:            DeploymentName,
+					Image:           e.esGatewayImage,
+					Env:             envVars,
+					VolumeMounts:    volumeMounts,
+					SecurityContext: podsecuritycontext.NewBaseContext(),
 					ReadinessProbe: &corev1.Probe{
 						Handler: corev1.Handler{
 							HTTPGet: &corev1.HTTPGetAction{
@@ -295,3 +314,18 @@ func (e esGateway) esGatewayService() *corev1.Service {
 		},
 	}
 }
+
+// esGatewayNetworkPolicy allows any client of Elasticsearch or Kibana to reach the es-gateway, and the es-gateway to
+// reach Elasticsearch and Kibana.
+func (e esGateway) esGatewayNetworkPolicy() *v3.NetworkPolicy {
+	egress := networkpolicy.AllowDNSRules(e.cfg.Installation.KubernetesProvider == operatorv1.ProviderOpenShift)
+	egress = append(egress,
+		networkpolicy.AllowKubeAPIServerRule(),
+		networkpolicy.AllowTCPRule(networkpolicy.ElasticsearchEntityRule),
+		networkpolicy.AllowTCPRule(networkpolicy.KibanaEntityRule),
+	)
+	return networkpolicy.AllowTigeraPolicy(DeploymentName, render.ElasticsearchNamespace,
+		networkpolicy.ServiceIngressRules(e.esGatewayService(), v3.EntityRule{}),
+		egress,
+	)
+}
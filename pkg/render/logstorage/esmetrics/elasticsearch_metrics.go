@@ -17,6 +17,7 @@ package esmetrics
 import (
 	"fmt"
 
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -29,6 +30,8 @@ import (
 	"github.com/tigera/operator/pkg/render"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/secret"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 )
@@ -53,6 +56,10 @@ type Config struct {
 	ClusterDomain        string
 	ServerTLS            certificatemanagement.KeyPairInterface
 	TrustedBundle        certificatemanagement.TrustedBundle
+
+	// NetworkPolicyState determines whether the policy for the metrics exporter in the allow-tigera tier is created or
+	// removed.
+	NetworkPolicyState networkpolicy.State
 }
 
 type elasticsearchMetrics struct {
@@ -81,7 +88,10 @@ func (e *elasticsearchMetrics) Objects() (objsToCreate, objsToDelete []client.Ob
 	)
 	toCreate = append(toCreate, e.metricsService(), e.metricsDeployment(), e.serviceAccount())
 
-	return toCreate, objsToDelete
+	policies, policiesToDelete := e.cfg.NetworkPolicyState.Split(e.networkPolicy())
+	toCreate = append(toCreate, policies...)
+
+	return toCreate, policiesToDelete
 }
 
 func (e elasticsearchMetrics) serviceAccount() *corev1.ServiceAccount {
@@ -102,6 +112,19 @@ func (e *elasticsearchMetrics) SupportedOSType() rmeta.OSType {
 	return rmeta.OSTypeLinux
 }
 
+// networkPolicy allows Prometheus to scrape the metrics exporter, and the exporter to reach Elasticsearch.
+func (e *elasticsearchMetrics) networkPolicy() *v3.NetworkPolicy {
+	egress := networkpolicy.AllowDNSRules(e.cfg.Installation.KubernetesProvider == operatorv1.ProviderOpenShift)
+	egress = append(egress,
+		networkpolicy.AllowTCPRule(networkpolicy.ESGatewayEntityRule),
+		networkpolicy.AllowTCPRule(networkpolicy.ElasticsearchEntityRule),
+	)
+	return networkpolicy.AllowTigeraPolicy(ElasticsearchMetricsName, render.ElasticsearchNamespace,
+		networkpolicy.ServiceIngressRules(e.metricsService(), networkpolicy.PrometheusSourceEntityRule),
+		egress,
+	)
+}
+
 func (e *elasticsearchMetrics) metricsService() *corev1.Service {
 	return &corev1.Service{
 		TypeMeta: metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
@@ -169,6 +192,7 @@ func (e elasticsearchMetrics) metricsDeployment() *appsv1.Deployment {
 									e.cfg.ServerTLS.VolumeMount(e.SupportedOSType()),
 									e.cfg.TrustedBundle.VolumeMount(e.SupportedOSType()),
 								},
+								SecurityContext: podsecuritycontext.NewBaseContext(),
 							}, render.DefaultElasticsearchClusterName, ElasticsearchMetricsSecret,
 							e.cfg.ClusterDomain, e.SupportedOSType(),
 						),
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 
+	cmnv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
 	"github.com/tigera/operator/pkg/apis"
@@ -173,7 +174,9 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				createResources, deleteResources := component.Objects()
 
 				compareResources(createResources, expectedCreateResources)
-				compareResources(deleteResources, []resourceTestObj{})
+				compareResources(deleteResources, []resourceTestObj{
+					{render.ElasticsearchSnapshotS3CredentialSecret, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+				})
 
 				resultES := rtest.GetResource(createResources, render.ElasticsearchName, render.ElasticsearchNamespace,
 					"elasticsearch.k8s.elastic.co", "v1", "Elasticsearch").(*esv1.Elasticsearch)
@@ -253,6 +256,7 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				}
 
 				expectedDeleteResources := []resourceTestObj{
+					{render.ElasticsearchSnapshotS3CredentialSecret, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
 					{render.ElasticsearchServiceName, render.ElasticsearchNamespace, &corev1.Service{}, nil},
 					{render.KibanaServiceName, render.KibanaNamespace, &corev1.Service{}, nil},
 				}
@@ -323,7 +327,9 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				createResources, deleteResources := component.Objects()
 
 				compareResources(createResources, expectedCreateResources)
-				compareResources(deleteResources, []resourceTestObj{})
+				compareResources(deleteResources, []resourceTestObj{
+					{render.ElasticsearchSnapshotS3CredentialSecret, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+				})
 
 				resultES := rtest.GetResource(createResources, render.ElasticsearchName, render.ElasticsearchNamespace,
 					"elasticsearch.k8s.elastic.co", "v1", "Elasticsearch").(*esv1.Elasticsearch)
@@ -412,7 +418,9 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				}))
 
 				compareResources(createResources, expectedCreateResources)
-				compareResources(deleteResources, []resourceTestObj{})
+				compareResources(deleteResources, []resourceTestObj{
+					{render.ElasticsearchSnapshotS3CredentialSecret, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+				})
 			})
 		})
 
@@ -499,6 +507,68 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 			Expect(x["publicBaseUrl"]).To(Equal("https://test.domain.com/tigera-kibana"))
 		})
 
+		Context("Elasticsearch snapshots", func() {
+			It("should configure an S3 snapshot repository", func() {
+				cfg.LogStorage.Spec.ElasticsearchSnapshots = &operatorv1.ElasticsearchSnapshots{
+					Repository: operatorv1.SnapshotRepository{
+						S3: &operatorv1.S3SnapshotRepository{
+							BucketName:      "backups",
+							Endpoint:        "minio.example.com:9000",
+							PathStyleAccess: true,
+						},
+					},
+				}
+				cfg.SnapshotS3Credential = &render.S3Credential{KeyId: []byte("id"), KeySecret: []byte("secret")}
+
+				createResources, deleteResources := render.LogStorage(cfg).Objects()
+
+				s := rtest.GetResource(createResources, render.ElasticsearchSnapshotS3CredentialSecret, render.ElasticsearchNamespace, "", "v1", "Secret")
+				Expect(s).NotTo(BeNil())
+				Expect(s.(*corev1.Secret).Data).To(Equal(map[string][]byte{"key-id": []byte("id"), "key-secret": []byte("secret")}))
+				Expect(rtest.GetResource(deleteResources, render.ElasticsearchSnapshotS3CredentialSecret, render.ElasticsearchNamespace, "", "v1", "Secret")).To(BeNil())
+
+				es := getElasticsearch(createResources)
+				Expect(es.Spec.SecureSettings).To(Equal([]cmnv1.SecretSource{{
+					SecretName: render.ElasticsearchSnapshotS3CredentialSecret,
+					Entries: []cmnv1.KeyToPath{
+						{Key: "key-id", Path: "s3.client.default.access_key"},
+						{Key: "key-secret", Path: "s3.client.default.secret_key"},
+					},
+				}}))
+				config := es.Spec.NodeSets[0].Config.Data
+				Expect(config["s3.client.default.endpoint"]).To(Equal("minio.example.com:9000"))
+				Expect(config["s3.client.default.path_style_access"]).To(BeTrue())
+				Expect(config).NotTo(HaveKey("path.repo"))
+			})
+
+			It("should mount a PersistentVolumeClaim snapshot repository", func() {
+				cfg.LogStorage.Spec.ElasticsearchSnapshots = &operatorv1.ElasticsearchSnapshots{
+					Repository: operatorv1.SnapshotRepository{
+						PersistentVolumeClaim: &operatorv1.PVCSnapshotRepository{ClaimName: "backups"},
+					},
+				}
+
+				createResources, deleteResources := render.LogStorage(cfg).Objects()
+
+				Expect(rtest.GetResource(deleteResources, render.ElasticsearchSnapshotS3CredentialSecret, render.ElasticsearchNamespace, "", "v1", "Secret")).NotTo(BeNil())
+
+				es := getElasticsearch(createResources)
+				Expect(es.Spec.SecureSettings).To(BeEmpty())
+				Expect(es.Spec.NodeSets[0].Config.Data["path.repo"]).To(Equal([]string{"/usr/share/elasticsearch/snapshots"}))
+				podSpec := es.Spec.NodeSets[0].PodTemplate.Spec
+				Expect(podSpec.Volumes).To(ContainElement(corev1.Volume{
+					Name: "elasticsearch-snapshots",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "backups"},
+					},
+				}))
+				Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name:      "elasticsearch-snapshots",
+					MountPath: "/usr/share/elasticsearch/snapshots",
+				}))
+			})
+		})
+
 		Context("ECKOperator memory requests/limits", func() {
 			When("LogStorage Spec contains an entry for ECKOperator in ComponentResources", func() {
 				It("should set matching memory requests/limits in the elastic-operator StatefulSet.Spec manager container", func() {
@@ -25,12 +25,16 @@ import (
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/enrollment"
 	"github.com/tigera/operator/pkg/render/common/authentication"
 	tigerakvc "github.com/tigera/operator/pkg/render/common/authentication/tigera/key_validator_config"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
 	"github.com/tigera/operator/pkg/render/common/configmap"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rkibana "github.com/tigera/operator/pkg/render/common/kibana"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	"github.com/tigera/operator/pkg/render/common/podaffinity"
 	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/podsecuritypolicy"
@@ -76,6 +80,13 @@ const (
 	VoltronTunnelSecretName  = "tigera-management-cluster-connection"
 	defaultVoltronPort       = "9443"
 	defaultTunnelVoltronPort = "9449"
+
+	// The enrollment endpoint that managed clusters obtain their tunnel certificates from runs next to voltron.
+	ManagerEnrollmentName = "tigera-enrollment"
+	ManagerEnrollmentRole = "tigera-manager-enrollment"
+	// ManagerEnrollmentNamespace holds the bootstrap token secrets, and nothing else, so that the enrollment endpoint
+	// does not have access to the other secrets of the manager.
+	ManagerEnrollmentNamespace = "tigera-managed-cluster-enrollment"
 )
 
 func Manager(cfg *ManagerConfiguration) (Component, error) {
@@ -119,17 +130,28 @@ type ManagerConfiguration struct {
 	Replicas                *int32
 	ComplianceFeatureActive bool
 
+	// Exposure configures how the manager UI is exposed outside of the cluster.
+	Exposure *operatorv1.ManagerExposure
+	// LogLevel is the log level of voltron. Info is used if it is empty.
+	LogLevel operatorv1.LogLevel
+	// Autoscaling, if set, scales the replicas of the manager with a HorizontalPodAutoscaler.
+	Autoscaling *operatorv1.Autoscaling
+
 	// Whether or not the cluster supports pod security policies.
 	UsePSP bool
+
+	// NetworkPolicyState determines whether the policies for the manager in the allow-tigera tier are created or removed.
+	NetworkPolicyState networkpolicy.State
 }
 
 type managerComponent struct {
-	cfg            *ManagerConfiguration
-	tlsSecrets     []*corev1.Secret
-	tlsAnnotations map[string]string
-	managerImage   string
-	proxyImage     string
-	esProxyImage   string
+	cfg             *ManagerConfiguration
+	tlsSecrets      []*corev1.Secret
+	tlsAnnotations  map[string]string
+	managerImage    string
+	proxyImage      string
+	esProxyImage    string
+	enrollmentImage string
 }
 
 func (c *managerComponent) ResolveImages(is *operatorv1.ImageSet) error {
@@ -153,6 +175,13 @@ func (c *managerComponent) ResolveImages(is *operatorv1.ImageSet) error {
 		errMsgs = append(errMsgs, err.Error())
 	}
 
+	if c.enrollmentEnabled() {
+		c.enrollmentImage, err = components.GetReference(components.ComponentOperatorInit, reg, path, prefix, is)
+		if err != nil {
+			errMsgs = append(errMsgs, err.Error())
+		}
+	}
+
 	if len(errMsgs) != 0 {
 		return fmt.Errorf(strings.Join(errMsgs, ","))
 	}
@@ -165,7 +194,7 @@ func (c *managerComponent) SupportedOSType() rmeta.OSType {
 
 func (c *managerComponent) Objects() ([]client.Object, []client.Object) {
 	objs := []client.Object{
-		CreateNamespace(ManagerNamespace, c.cfg.Installation.KubernetesProvider, PSSRestricted),
+		CreateNamespace(ManagerNamespace, c.cfg.Installation, PSSRestricted),
 	}
 	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(ManagerNamespace, c.cfg.PullSecrets...)...)...)
 
@@ -182,6 +211,8 @@ func (c *managerComponent) Objects() ([]client.Object, []client.Object) {
 	objs = append(objs,
 		c.managerService(),
 	)
+	exposureObjs, exposureObjsToDelete := c.exposureObjects()
+	objs = append(objs, exposureObjs...)
 
 	// If we're running on openshift, we need to add in an SCC.
 	if c.cfg.Openshift {
@@ -191,12 +222,38 @@ func (c *managerComponent) Objects() ([]client.Object, []client.Object) {
 		objs = append(objs, c.managerPodSecurityPolicy())
 	}
 	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(ManagerNamespace, c.cfg.ESSecrets...)...)...)
-	objs = append(objs, c.managerDeployment())
+	deployment := c.managerDeployment()
+	autoscalingObjs, autoscalingObjsToDelete := autoscaling.Objects(deployment, c.cfg.Autoscaling)
+	objs = append(objs, deployment)
+	objs = append(objs, autoscalingObjs...)
 	if c.cfg.KeyValidatorConfig != nil {
 		objs = append(objs, configmap.ToRuntimeObjects(c.cfg.KeyValidatorConfig.RequiredConfigMaps(ManagerNamespace)...)...)
 	}
 
-	return objs, nil
+	policies, policiesToDelete := c.cfg.NetworkPolicyState.Split(
+		networkpolicy.AllowTigeraDefaultDeny(ManagerNamespace),
+		c.networkPolicy(),
+	)
+	objs = append(objs, policies...)
+
+	var objsToDelete []client.Object
+	enrollmentObjs := []client.Object{
+		c.enrollmentRole(),
+		c.enrollmentRoleBinding(),
+		c.enrollmentClusterRole(),
+		c.enrollmentClusterRoleBinding(),
+	}
+	if c.enrollmentEnabled() {
+		// The namespace is not deleted when enrollment is disabled, since it holds the tokens that users created.
+		objs = append(objs, CreateNamespace(ManagerEnrollmentNamespace, c.cfg.Installation, PSSRestricted))
+		objs = append(objs, enrollmentObjs...)
+	} else {
+		objsToDelete = append(objsToDelete, enrollmentObjs...)
+	}
+	objsToDelete = append(objsToDelete, autoscalingObjsToDelete...)
+	objsToDelete = append(objsToDelete, exposureObjsToDelete...)
+
+	return objs, append(objsToDelete, policiesToDelete...)
 }
 
 func (c *managerComponent) Ready() bool {
@@ -231,6 +288,10 @@ func (c *managerComponent) managerDeployment() *appsv1.Deployment {
 		},
 	}, c.cfg.ESClusterConfig, c.cfg.ESSecrets).(*corev1.PodTemplateSpec)
 
+	if c.enrollmentEnabled() {
+		podTemplate.Spec.Containers = append(podTemplate.Spec.Containers, c.enrollmentContainer())
+	}
+
 	if c.cfg.Replicas != nil && *c.cfg.Replicas > 1 {
 		podTemplate.Spec.Affinity = podaffinity.NewPodAntiAffinity("tigera-manager", ManagerNamespace)
 	}
@@ -396,7 +457,7 @@ func (c *managerComponent) managerProxyContainer() corev1.Container {
 	env := []corev1.EnvVar{
 		{Name: "VOLTRON_PORT", Value: defaultVoltronPort},
 		{Name: "VOLTRON_COMPLIANCE_ENDPOINT", Value: fmt.Sprintf("https://compliance.%s.svc.%s", ComplianceNamespace, c.cfg.ClusterDomain)},
-		{Name: "VOLTRON_LOGLEVEL", Value: "Info"},
+		{Name: "VOLTRON_LOGLEVEL", Value: loglevel.Logrus(c.cfg.LogLevel, "Info")},
 		{Name: "VOLTRON_KIBANA_ENDPOINT", Value: rkibana.HTTPSEndpoint(c.SupportedOSType(), c.cfg.ClusterDomain)},
 		{Name: "VOLTRON_KIBANA_BASE_PATH", Value: fmt.Sprintf("/%s/", KibanaBasePath)},
 		{Name: "VOLTRON_KIBANA_CA_BUNDLE_PATH", Value: c.cfg.TrustedCertBundle.MountPath()},
@@ -477,7 +538,7 @@ func (c *managerComponent) managerTolerations() []corev1.Toleration {
 
 // managerService returns the service exposing the Tigera Secure web app.
 func (c *managerComponent) managerService() *corev1.Service {
-	return &corev1.Service{
+	svc := &corev1.Service{
 		TypeMeta: metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "tigera-manager",
@@ -496,6 +557,145 @@ func (c *managerComponent) managerService() *corev1.Service {
 			},
 		},
 	}
+	c.exposeService(svc)
+	return svc
+}
+
+// networkPolicy allows users to reach the manager UI, guardians of managed clusters to open tunnels when this is a
+// management cluster, and the manager to reach the backends it proxies requests to.
+func (c *managerComponent) networkPolicy() *v3.NetworkPolicy {
+	ingress := networkpolicy.ServiceIngressRules(c.managerService(), v3.EntityRule{})
+	if c.cfg.ManagementCluster != nil {
+		tunnelPort, _ := strconv.Atoi(defaultTunnelVoltronPort)
+		ingress = append(ingress, networkpolicy.AllowTCPFromRule(v3.EntityRule{}, uint16(tunnelPort)))
+	}
+	if c.enrollmentEnabled() {
+		ingress = append(ingress, networkpolicy.AllowTCPFromRule(v3.EntityRule{}, enrollment.DefaultPort))
+	}
+
+	egress := networkpolicy.AllowDNSRules(c.cfg.Openshift)
+	egress = append(egress,
+		networkpolicy.AllowKubeAPIServerRule(),
+		networkpolicy.AllowTCPRule(networkpolicy.ESGatewayEntityRule),
+		networkpolicy.AllowTCPRule(networkpolicy.KibanaEntityRule),
+		networkpolicy.AllowTCPRule(networkpolicy.ComplianceServerEntityRule),
+		networkpolicy.AllowTCPRule(networkpolicy.PacketCaptureEntityRule),
+		networkpolicy.AllowTCPRule(networkpolicy.PrometheusEntityRule),
+		networkpolicy.AllowTCPRule(networkpolicy.DexEntityRule),
+	)
+	if c.cfg.KeyValidatorConfig != nil && c.cfg.KeyValidatorConfig.Issuer() != "" {
+		// The manager fetches the signing keys of the identity provider to validate tokens.
+		egress = append(egress, networkpolicy.AllowAddressRule(c.cfg.KeyValidatorConfig.Issuer()))
+	}
+	return networkpolicy.AllowTigeraPolicy(ManagerServiceName, ManagerNamespace, ingress, egress)
+}
+
+// enrollmentEnabled returns whether managed clusters can enroll with this management cluster.
+func (c *managerComponent) enrollmentEnabled() bool {
+	return c.cfg.ManagementCluster != nil && c.cfg.ManagementCluster.Spec.Enrollment != nil
+}
+
+// enrollmentContainer returns the container that serves the enrollment endpoint. It uses the tunnel keypair both to
+// serve and to sign the certificates of managed clusters.
+func (c *managerComponent) enrollmentContainer() corev1.Container {
+	validity := enrollment.DefaultCertificateValidity
+	if v := c.cfg.ManagementCluster.Spec.Enrollment.CertificateValidity; v != nil {
+		validity = v.Duration
+	}
+	return corev1.Container{
+		Name:  ManagerEnrollmentName,
+		Image: c.enrollmentImage,
+		Args: []string{
+			"--managed-cluster-enrollment",
+			fmt.Sprintf("--enrollment-address=:%d", enrollment.DefaultPort),
+			fmt.Sprintf("--enrollment-cert=%s", c.cfg.TunnelSecret.VolumeMountCertificateFilePath()),
+			fmt.Sprintf("--enrollment-key=%s", c.cfg.TunnelSecret.VolumeMountKeyFilePath()),
+			fmt.Sprintf("--enrollment-namespace=%s", ManagerEnrollmentNamespace),
+			fmt.Sprintf("--enrollment-certificate-validity=%s", validity),
+		},
+		Ports: []corev1.ContainerPort{{Name: "enrollment", ContainerPort: enrollment.DefaultPort}},
+		ReadinessProbe: &corev1.Probe{
+			Handler: corev1.Handler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path:   enrollment.HealthPath,
+					Port:   intstr.FromInt(enrollment.DefaultPort),
+					Scheme: corev1.URISchemeHTTPS,
+				},
+			},
+			PeriodSeconds: 10,
+		},
+		VolumeMounts:    []corev1.VolumeMount{c.cfg.TunnelSecret.VolumeMount(c.SupportedOSType())},
+		SecurityContext: podsecuritycontext.NewBaseContext(),
+	}
+}
+
+// enrollmentRole allows the enrollment endpoint to bind the bootstrap tokens in the enrollment namespace to the keys
+// of managed clusters, and to delete them once they are used.
+func (c *managerComponent) enrollmentRole() *rbacv1.Role {
+	return &rbacv1.Role{
+		TypeMeta:   metav1.TypeMeta{Kind: "Role", APIVersion: "rbac.authorization.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ManagerEnrollmentRole, Namespace: ManagerEnrollmentNamespace},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{"secrets"},
+				Verbs:     []string{"list", "update", "delete"},
+			},
+		},
+	}
+}
+
+func (c *managerComponent) enrollmentRoleBinding() *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		TypeMeta:   metav1.TypeMeta{Kind: "RoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ManagerEnrollmentRole, Namespace: ManagerEnrollmentNamespace},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "Role",
+			Name:     ManagerEnrollmentRole,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      ManagerServiceAccount,
+				Namespace: ManagerNamespace,
+			},
+		},
+	}
+}
+
+// enrollmentClusterRole allows the enrollment endpoint to register managed clusters.
+func (c *managerComponent) enrollmentClusterRole() *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		TypeMeta:   metav1.TypeMeta{Kind: "ClusterRole", APIVersion: "rbac.authorization.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ManagerEnrollmentRole},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"projectcalico.org"},
+				Resources: []string{"managedclusters"},
+				Verbs:     []string{"get", "create", "update"},
+			},
+		},
+	}
+}
+
+func (c *managerComponent) enrollmentClusterRoleBinding() *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		TypeMeta:   metav1.TypeMeta{Kind: "ClusterRoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ManagerEnrollmentRole},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     ManagerEnrollmentRole,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      ManagerServiceAccount,
+				Namespace: ManagerNamespace,
+			},
+		},
+	}
 }
 
 // managerServiceAccount creates the serviceaccount used by the Tigera Secure web app.
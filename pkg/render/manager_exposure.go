@@ -0,0 +1,302 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+const (
+	ManagerIngressName            = "tigera-manager"
+	ManagerRouteName              = "tigera-manager"
+	ManagerBackendCAConfigMapName = "tigera-manager-backend-ca"
+
+	// The Gateway API versions that the routes are rendered with. TLSRoute and BackendTLSPolicy are only available
+	// in the experimental channel of the Gateway API.
+	gatewayHTTPRouteAPIVersion        = "gateway.networking.k8s.io/v1beta1"
+	gatewayTLSRouteAPIVersion         = "gateway.networking.k8s.io/v1alpha2"
+	gatewayBackendTLSPolicyAPIVersion = "gateway.networking.k8s.io/v1alpha3"
+
+	nginxBackendProtocolAnnotation = "nginx.ingress.kubernetes.io/backend-protocol"
+)
+
+// ManagerExposureURL returns the URL that users reach the manager at through the exposure of the Manager CR, or an
+// empty string if the exposure does not determine it, e.g. the address of a load balancer that has no hostname.
+func ManagerExposureURL(exposure *operatorv1.ManagerExposure) string {
+	if exposure == nil || exposure.Hostname == "" {
+		return ""
+	}
+	switch exposure.Type {
+	case operatorv1.ManagerExposureIngress, operatorv1.ManagerExposureGateway:
+		return "https://" + exposure.Hostname
+	case operatorv1.ManagerExposureLoadBalancer:
+		return "https://" + net.JoinHostPort(exposure.Hostname, strconv.Itoa(managerPort))
+	case operatorv1.ManagerExposureNodePort:
+		if exposure.NodePort != nil {
+			return "https://" + net.JoinHostPort(exposure.Hostname, strconv.Itoa(int(*exposure.NodePort)))
+		}
+	}
+	return ""
+}
+
+// ManagerLoadBalancerURL returns the URL of the manager for the address of its load balancer.
+func ManagerLoadBalancerURL(ingress corev1.LoadBalancerIngress) string {
+	addr := ingress.Hostname
+	if addr == "" {
+		addr = ingress.IP
+	}
+	if addr == "" {
+		return ""
+	}
+	return "https://" + net.JoinHostPort(addr, strconv.Itoa(managerPort))
+}
+
+// ValidateManagerExposure validates the exposure of the Manager CR.
+func ValidateManagerExposure(exposure *operatorv1.ManagerExposure) error {
+	if exposure == nil {
+		return nil
+	}
+	t := exposure.Type
+	switch t {
+	case operatorv1.ManagerExposureClusterIP, operatorv1.ManagerExposureLoadBalancer, operatorv1.ManagerExposureNodePort:
+	case operatorv1.ManagerExposureIngress, operatorv1.ManagerExposureGateway:
+		if exposure.Hostname == "" {
+			return fmt.Errorf("hostname is required for the %s exposure type", t)
+		}
+	default:
+		return fmt.Errorf("exposure type %s is not supported", t)
+	}
+	if exposure.LoadBalancer != nil && t != operatorv1.ManagerExposureLoadBalancer {
+		return fmt.Errorf("loadBalancer is only supported for the LoadBalancer exposure type")
+	}
+	if exposure.NodePort != nil && t != operatorv1.ManagerExposureNodePort {
+		return fmt.Errorf("nodePort is only supported for the NodePort exposure type")
+	}
+	if exposure.Ingress != nil && t != operatorv1.ManagerExposureIngress {
+		return fmt.Errorf("ingress is only supported for the Ingress exposure type")
+	}
+	if t != operatorv1.ManagerExposureGateway {
+		if exposure.Gateway != nil {
+			return fmt.Errorf("gateway is only supported for the Gateway exposure type")
+		}
+		return nil
+	}
+	if exposure.Gateway == nil || len(exposure.Gateway.ParentRefs) == 0 {
+		return fmt.Errorf("gateway.parentRefs is required for the Gateway exposure type")
+	}
+	switch exposure.Gateway.RouteType {
+	case "", operatorv1.ManagerGatewayHTTPRoute, operatorv1.ManagerGatewayTLSRoute:
+	default:
+		return fmt.Errorf("gateway route type %s is not supported", exposure.Gateway.RouteType)
+	}
+	return nil
+}
+
+func (c *managerComponent) exposureType() operatorv1.ManagerExposureType {
+	if c.cfg.Exposure == nil {
+		return operatorv1.ManagerExposureClusterIP
+	}
+	return c.cfg.Exposure.Type
+}
+
+func (c *managerComponent) gatewayRouteType() operatorv1.ManagerGatewayRouteType {
+	if c.exposureType() != operatorv1.ManagerExposureGateway || c.cfg.Exposure.Gateway.RouteType == "" {
+		return operatorv1.ManagerGatewayHTTPRoute
+	}
+	return c.cfg.Exposure.Gateway.RouteType
+}
+
+// exposeService configures the type of the service of the manager for the exposure.
+func (c *managerComponent) exposeService(svc *corev1.Service) {
+	switch c.exposureType() {
+	case operatorv1.ManagerExposureLoadBalancer:
+		svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+		if lb := c.cfg.Exposure.LoadBalancer; lb != nil {
+			svc.Annotations = lb.Annotations
+			svc.Spec.LoadBalancerSourceRanges = lb.SourceRanges
+		}
+	case operatorv1.ManagerExposureNodePort:
+		svc.Spec.Type = corev1.ServiceTypeNodePort
+		if c.cfg.Exposure.NodePort != nil {
+			svc.Spec.Ports[0].NodePort = *c.cfg.Exposure.NodePort
+		}
+	default:
+		svc.Spec.Type = corev1.ServiceTypeClusterIP
+	}
+}
+
+// exposureObjects returns the objects that expose the manager outside of the service, and those of the other
+// exposure types to delete.
+func (c *managerComponent) exposureObjects() ([]client.Object, []client.Object) {
+	ingress := &netv1.Ingress{
+		TypeMeta:   metav1.TypeMeta{Kind: "Ingress", APIVersion: "networking.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ManagerIngressName, Namespace: ManagerNamespace},
+	}
+	httpRoute := gatewayObject(gatewayHTTPRouteAPIVersion, "HTTPRoute", ManagerRouteName)
+	backendTLSPolicy := gatewayObject(gatewayBackendTLSPolicyAPIVersion, "BackendTLSPolicy", ManagerRouteName)
+	backendCA := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ManagerBackendCAConfigMapName, Namespace: ManagerNamespace},
+	}
+	tlsRoute := gatewayObject(gatewayTLSRouteAPIVersion, "TLSRoute", ManagerRouteName)
+
+	switch c.exposureType() {
+	case operatorv1.ManagerExposureIngress:
+		return []client.Object{c.managerIngress()}, []client.Object{httpRoute, backendTLSPolicy, backendCA, tlsRoute}
+	case operatorv1.ManagerExposureGateway:
+		if c.gatewayRouteType() == operatorv1.ManagerGatewayTLSRoute {
+			return []client.Object{c.managerTLSRoute()}, []client.Object{ingress, httpRoute, backendTLSPolicy, backendCA}
+		}
+		return []client.Object{c.managerHTTPRoute(), c.managerBackendCA(), c.managerBackendTLSPolicy()}, []client.Object{ingress, tlsRoute}
+	default:
+		return nil, []client.Object{ingress, httpRoute, backendTLSPolicy, backendCA, tlsRoute}
+	}
+}
+
+// managerIngress returns the Ingress of the manager. The manager only serves HTTPS, so the NGINX ingress controller
+// is told to connect to it with HTTPS unless the user configured it differently.
+func (c *managerComponent) managerIngress() *netv1.Ingress {
+	exposure := c.cfg.Exposure
+	annotations := map[string]string{nginxBackendProtocolAnnotation: "HTTPS"}
+	ing := &netv1.Ingress{
+		TypeMeta: metav1.TypeMeta{Kind: "Ingress", APIVersion: "networking.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ManagerIngressName,
+			Namespace: ManagerNamespace,
+		},
+	}
+	pathType := netv1.PathTypePrefix
+	ing.Spec.Rules = []netv1.IngressRule{{
+		Host: exposure.Hostname,
+		IngressRuleValue: netv1.IngressRuleValue{
+			HTTP: &netv1.HTTPIngressRuleValue{
+				Paths: []netv1.HTTPIngressPath{{
+					Path:     "/",
+					PathType: &pathType,
+					Backend: netv1.IngressBackend{
+						Service: &netv1.IngressServiceBackend{
+							Name: ManagerServiceName,
+							Port: netv1.ServiceBackendPort{Number: managerPort},
+						},
+					},
+				}},
+			},
+		},
+	}}
+	if cfg := exposure.Ingress; cfg != nil {
+		for k, v := range cfg.Annotations {
+			annotations[k] = v
+		}
+		ing.Spec.IngressClassName = cfg.IngressClassName
+		if cfg.TLSSecretName != "" {
+			ing.Spec.TLS = []netv1.IngressTLS{{Hosts: []string{exposure.Hostname}, SecretName: cfg.TLSSecretName}}
+		}
+	}
+	ing.Annotations = annotations
+	return ing
+}
+
+// managerHTTPRoute returns the HTTPRoute of the manager. The gateway terminates TLS and connects to the manager with
+// HTTPS as configured by the BackendTLSPolicy.
+func (c *managerComponent) managerHTTPRoute() *unstructured.Unstructured {
+	route := gatewayObject(gatewayHTTPRouteAPIVersion, "HTTPRoute", ManagerRouteName)
+	route.Object["spec"] = c.gatewayRouteSpec()
+	return route
+}
+
+// managerTLSRoute returns the TLSRoute of the manager. The gateway passes TLS through to the manager, which presents
+// its own certificate.
+func (c *managerComponent) managerTLSRoute() *unstructured.Unstructured {
+	route := gatewayObject(gatewayTLSRouteAPIVersion, "TLSRoute", ManagerRouteName)
+	route.Object["spec"] = c.gatewayRouteSpec()
+	return route
+}
+
+// managerBackendTLSPolicy tells the gateway to connect to the manager with HTTPS and to verify its certificate.
+func (c *managerComponent) managerBackendTLSPolicy() *unstructured.Unstructured {
+	policy := gatewayObject(gatewayBackendTLSPolicyAPIVersion, "BackendTLSPolicy", ManagerRouteName)
+	policy.Object["spec"] = map[string]interface{}{
+		"targetRefs": []interface{}{
+			map[string]interface{}{"group": "", "kind": "Service", "name": ManagerServiceName},
+		},
+		"validation": map[string]interface{}{
+			"caCertificateRefs": []interface{}{
+				map[string]interface{}{"group": "", "kind": "ConfigMap", "name": ManagerBackendCAConfigMapName},
+			},
+			"hostname": fmt.Sprintf("%s.%s.svc", ManagerServiceName, ManagerNamespace),
+		},
+	}
+	return policy
+}
+
+// managerBackendCA returns the ConfigMap with the issuer of the certificate of the manager, in the format that
+// BackendTLSPolicy expects.
+func (c *managerComponent) managerBackendCA() *corev1.ConfigMap {
+	ca := c.cfg.TLSKeyPair.GetCertificatePEM()
+	if issuer := c.cfg.TLSKeyPair.GetIssuer(); issuer != nil {
+		ca = issuer.GetCertificatePEM()
+	}
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ManagerBackendCAConfigMapName, Namespace: ManagerNamespace},
+		Data:       map[string]string{"ca.crt": string(ca)},
+	}
+}
+
+func (c *managerComponent) gatewayRouteSpec() map[string]interface{} {
+	var parentRefs []interface{}
+	for _, ref := range c.cfg.Exposure.Gateway.ParentRefs {
+		parentRef := map[string]interface{}{"name": ref.Name}
+		if ref.Namespace != "" {
+			parentRef["namespace"] = ref.Namespace
+		}
+		if ref.SectionName != "" {
+			parentRef["sectionName"] = ref.SectionName
+		}
+		parentRefs = append(parentRefs, parentRef)
+	}
+	return map[string]interface{}{
+		"parentRefs": parentRefs,
+		"hostnames":  []interface{}{c.cfg.Exposure.Hostname},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{"name": ManagerServiceName, "port": int64(managerPort)},
+				},
+			},
+		},
+	}
+}
+
+// gatewayObject returns a Gateway API object in the manager namespace. The operator does not depend on the Gateway
+// API types, so the objects are unstructured.
+func gatewayObject(apiVersion, kind, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace(ManagerNamespace)
+	return obj
+}
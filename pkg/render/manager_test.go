@@ -16,13 +16,19 @@ package render_test
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -35,6 +41,7 @@ import (
 	"github.com/tigera/operator/pkg/dns"
 	"github.com/tigera/operator/pkg/render"
 	"github.com/tigera/operator/pkg/render/common/authentication"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	"github.com/tigera/operator/pkg/render/common/podaffinity"
@@ -122,6 +129,59 @@ var _ = Describe("Tigera Secure Manager rendering tests", func() {
 		Expect(voltron.Env).To(ContainElement(corev1.EnvVar{Name: "VOLTRON_ENABLE_COMPLIANCE", Value: "false"}))
 	})
 
+	It("should set the log level of voltron", func() {
+		resources := renderObjects(renderConfig{installation: installation})
+		voltron := rtest.GetResource(resources, "tigera-manager", render.ManagerNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment).Spec.Template.Spec.Containers[2]
+		Expect(voltron.Env).To(ContainElement(corev1.EnvVar{Name: "VOLTRON_LOGLEVEL", Value: "Info"}))
+
+		resources = renderObjects(renderConfig{installation: installation, logLevel: operatorv1.LogLevelError})
+		voltron = rtest.GetResource(resources, "tigera-manager", render.ManagerNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment).Spec.Template.Spec.Containers[2]
+		Expect(voltron.Env).To(ContainElement(corev1.EnvVar{Name: "VOLTRON_LOGLEVEL", Value: "error"}))
+	})
+
+	It("should scale the manager with a HorizontalPodAutoscaler when autoscaling is configured", func() {
+		resources, toDelete := renderManagerComponent(renderConfig{installation: installation}).Objects()
+		Expect(rtest.GetResource(resources, "tigera-manager", render.ManagerNamespace, "autoscaling", "v2beta2", "HorizontalPodAutoscaler")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, "tigera-manager", render.ManagerNamespace, "autoscaling", "v2beta2", "HorizontalPodAutoscaler")).NotTo(BeNil())
+		d := rtest.GetResource(resources, "tigera-manager", render.ManagerNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(d.Annotations).NotTo(HaveKey(autoscaling.HorizontalPodAutoscalerAnnotation))
+
+		minReplicas := int32(2)
+		memory := int32(70)
+		resources, toDelete = renderManagerComponent(renderConfig{
+			installation: installation,
+			autoscaling: &operatorv1.Autoscaling{
+				MinReplicas:                       &minReplicas,
+				MaxReplicas:                       5,
+				TargetMemoryUtilizationPercentage: &memory,
+			},
+		}).Objects()
+		Expect(rtest.GetResource(toDelete, "tigera-manager", render.ManagerNamespace, "autoscaling", "v2beta2", "HorizontalPodAutoscaler")).To(BeNil())
+		d = rtest.GetResource(resources, "tigera-manager", render.ManagerNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(d.Annotations).To(HaveKeyWithValue(autoscaling.HorizontalPodAutoscalerAnnotation, "tigera-manager"))
+		Expect(*d.Spec.Replicas).To(Equal(minReplicas))
+
+		hpa := rtest.GetResource(resources, "tigera-manager", render.ManagerNamespace, "autoscaling", "v2beta2", "HorizontalPodAutoscaler").(*autoscalingv2beta2.HorizontalPodAutoscaler)
+		Expect(hpa.Spec.ScaleTargetRef).To(Equal(autoscalingv2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: "tigera-manager", APIVersion: "apps/v1"}))
+		Expect(*hpa.Spec.MinReplicas).To(Equal(minReplicas))
+		Expect(hpa.Spec.MaxReplicas).To(Equal(int32(5)))
+		Expect(hpa.Spec.Metrics).To(ConsistOf(autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.ResourceMetricSourceType,
+			Resource: &autoscalingv2beta2.ResourceMetricSource{
+				Name:   corev1.ResourceMemory,
+				Target: autoscalingv2beta2.MetricTarget{Type: autoscalingv2beta2.UtilizationMetricType, AverageUtilization: &memory},
+			},
+		}))
+
+		By("targeting a CPU utilization of 80% by default")
+		resources, _ = renderManagerComponent(renderConfig{installation: installation, autoscaling: &operatorv1.Autoscaling{MaxReplicas: 3}}).Objects()
+		hpa = rtest.GetResource(resources, "tigera-manager", render.ManagerNamespace, "autoscaling", "v2beta2", "HorizontalPodAutoscaler").(*autoscalingv2beta2.HorizontalPodAutoscaler)
+		Expect(*hpa.Spec.MinReplicas).To(Equal(int32(1)))
+		Expect(hpa.Spec.Metrics).To(HaveLen(1))
+		Expect(hpa.Spec.Metrics[0].Resource.Name).To(Equal(corev1.ResourceCPU))
+		Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(Equal(int32(80)))
+	})
+
 	It("should ensure cnx policy recommendation support is always set to true", func() {
 		resources := renderObjects(renderConfig{oidc: false, managementCluster: nil, installation: installation})
 		Expect(len(resources)).To(Equal(expectedResourcesNumber))
@@ -463,6 +523,64 @@ var _ = Describe("Tigera Secure Manager rendering tests", func() {
 		Expect(deployment.Spec.Template.Spec.Tolerations).To(ContainElements(t, rmeta.TolerateMaster, rmeta.TolerateCriticalAddonsOnly))
 	})
 
+	It("should render the managed cluster enrollment endpoint when it is enabled", func() {
+		resources := renderObjects(renderConfig{
+			oidc: false,
+			managementCluster: &operatorv1.ManagementCluster{Spec: operatorv1.ManagementClusterSpec{
+				Enrollment: &operatorv1.ManagedClusterEnrollment{CertificateValidity: &metav1.Duration{Duration: 48 * time.Hour}},
+			}},
+			installation: installation,
+		})
+
+		rtest.ExpectResource(rtest.GetResource(resources, render.ManagerEnrollmentNamespace, "", "", "v1", "Namespace"),
+			render.ManagerEnrollmentNamespace, "", "", "v1", "Namespace")
+		role := rtest.GetResource(resources, render.ManagerEnrollmentRole, render.ManagerEnrollmentNamespace, "rbac.authorization.k8s.io", "v1", "Role").(*rbacv1.Role)
+		Expect(role.Rules).To(ConsistOf(rbacv1.PolicyRule{
+			APIGroups: []string{""},
+			Resources: []string{"secrets"},
+			Verbs:     []string{"list", "update", "delete"},
+		}))
+		rtest.ExpectResource(rtest.GetResource(resources, render.ManagerEnrollmentRole, render.ManagerEnrollmentNamespace, "rbac.authorization.k8s.io", "v1", "RoleBinding"),
+			render.ManagerEnrollmentRole, render.ManagerEnrollmentNamespace, "rbac.authorization.k8s.io", "v1", "RoleBinding")
+		Expect(rtest.GetResource(resources, render.ManagerEnrollmentRole, render.ManagerNamespace, "rbac.authorization.k8s.io", "v1", "Role")).To(BeNil())
+
+		clusterRole := rtest.GetResource(resources, render.ManagerEnrollmentRole, "", "rbac.authorization.k8s.io", "v1", "ClusterRole").(*rbacv1.ClusterRole)
+		Expect(clusterRole.Rules).To(ConsistOf(rbacv1.PolicyRule{
+			APIGroups: []string{"projectcalico.org"},
+			Resources: []string{"managedclusters"},
+			Verbs:     []string{"get", "create", "update"},
+		}))
+		rtest.ExpectResource(rtest.GetResource(resources, render.ManagerEnrollmentRole, "", "rbac.authorization.k8s.io", "v1", "ClusterRoleBinding"),
+			render.ManagerEnrollmentRole, "", "rbac.authorization.k8s.io", "v1", "ClusterRoleBinding")
+
+		deployment := rtest.GetResource(resources, "tigera-manager", render.ManagerNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(4))
+		enrollmentContainer := deployment.Spec.Template.Spec.Containers[3]
+		Expect(enrollmentContainer.Name).To(Equal(render.ManagerEnrollmentName))
+		Expect(enrollmentContainer.Image).To(HaveSuffix(fmt.Sprintf("%s:%s", components.ComponentOperatorInit.Image, components.ComponentOperatorInit.Version)))
+		Expect(enrollmentContainer.Args).To(ConsistOf(
+			"--managed-cluster-enrollment",
+			"--enrollment-address=:9450",
+			"--enrollment-cert=/tigera-management-cluster-connection/tls.crt",
+			"--enrollment-key=/tigera-management-cluster-connection/tls.key",
+			"--enrollment-namespace=tigera-managed-cluster-enrollment",
+			"--enrollment-certificate-validity=48h0m0s",
+		))
+		Expect(enrollmentContainer.VolumeMounts).To(ConsistOf(corev1.VolumeMount{
+			Name:      render.VoltronTunnelSecretName,
+			MountPath: "/tigera-management-cluster-connection",
+			ReadOnly:  true,
+		}))
+	})
+
+	It("should not render the managed cluster enrollment endpoint by default", func() {
+		resources := renderObjects(renderConfig{oidc: false, managementCluster: &operatorv1.ManagementCluster{}, installation: installation})
+
+		Expect(rtest.GetResource(resources, render.ManagerEnrollmentRole, render.ManagerEnrollmentNamespace, "rbac.authorization.k8s.io", "v1", "Role")).To(BeNil())
+		deployment := rtest.GetResource(resources, "tigera-manager", render.ManagerNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(3))
+	})
+
 	It("should render all resources for certificate management", func() {
 		ca, _ := tls.MakeCA(rmeta.DefaultOperatorCASignerName())
 		cert, _, _ := ca.Config.GetPEMBytes()
@@ -538,6 +656,166 @@ var _ = Describe("Tigera Secure Manager rendering tests", func() {
 		Expect(deploy.Spec.Template.Spec.Affinity).NotTo(BeNil())
 		Expect(deploy.Spec.Template.Spec.Affinity).To(Equal(podaffinity.NewPodAntiAffinity("tigera-manager", render.ManagerNamespace)))
 	})
+
+	Context("exposure", func() {
+		var host = "manager.example.com"
+
+		getService := func(resources []client.Object) *corev1.Service {
+			svc, ok := rtest.GetResource(resources, render.ManagerServiceName, render.ManagerNamespace, "", "v1", "Service").(*corev1.Service)
+			Expect(ok).To(BeTrue())
+			return svc
+		}
+
+		It("should only render a ClusterIP service by default", func() {
+			toCreate, toDelete := renderManagerComponent(renderConfig{installation: installation}).Objects()
+			Expect(getService(toCreate).Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+			Expect(rtest.GetResource(toDelete, render.ManagerIngressName, render.ManagerNamespace, "networking.k8s.io", "v1", "Ingress")).NotTo(BeNil())
+			Expect(rtest.GetResource(toDelete, render.ManagerRouteName, render.ManagerNamespace, "gateway.networking.k8s.io", "v1beta1", "HTTPRoute")).NotTo(BeNil())
+			Expect(rtest.GetResource(toDelete, render.ManagerRouteName, render.ManagerNamespace, "gateway.networking.k8s.io", "v1alpha2", "TLSRoute")).NotTo(BeNil())
+		})
+
+		It("should render a LoadBalancer service", func() {
+			resources := renderObjects(renderConfig{installation: installation, exposure: &operatorv1.ManagerExposure{
+				Type: operatorv1.ManagerExposureLoadBalancer,
+				LoadBalancer: &operatorv1.ManagerLoadBalancer{
+					Annotations:  map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"},
+					SourceRanges: []string{"10.0.0.0/8"},
+				},
+			}})
+			svc := getService(resources)
+			Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+			Expect(svc.Annotations).To(HaveKeyWithValue("service.beta.kubernetes.io/aws-load-balancer-internal", "true"))
+			Expect(svc.Spec.LoadBalancerSourceRanges).To(ConsistOf("10.0.0.0/8"))
+		})
+
+		It("should render a NodePort service", func() {
+			nodePort := int32(30443)
+			resources := renderObjects(renderConfig{installation: installation, exposure: &operatorv1.ManagerExposure{
+				Type:     operatorv1.ManagerExposureNodePort,
+				NodePort: &nodePort,
+			}})
+			svc := getService(resources)
+			Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeNodePort))
+			Expect(svc.Spec.Ports[0].NodePort).To(Equal(nodePort))
+		})
+
+		It("should render an Ingress", func() {
+			class := "nginx"
+			toCreate, toDelete := renderManagerComponent(renderConfig{installation: installation, exposure: &operatorv1.ManagerExposure{
+				Type:     operatorv1.ManagerExposureIngress,
+				Hostname: host,
+				Ingress: &operatorv1.ManagerIngress{
+					IngressClassName: &class,
+					TLSSecretName:    "manager-ingress-tls",
+					Annotations:      map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"},
+				},
+			}}).Objects()
+			Expect(getService(toCreate).Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+
+			ing, ok := rtest.GetResource(toCreate, render.ManagerIngressName, render.ManagerNamespace, "networking.k8s.io", "v1", "Ingress").(*netv1.Ingress)
+			Expect(ok).To(BeTrue())
+			Expect(ing.Annotations).To(Equal(map[string]string{
+				"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS",
+				"cert-manager.io/cluster-issuer":               "letsencrypt",
+			}))
+			Expect(ing.Spec.IngressClassName).To(Equal(&class))
+			Expect(ing.Spec.TLS).To(ConsistOf(netv1.IngressTLS{Hosts: []string{host}, SecretName: "manager-ingress-tls"}))
+			Expect(ing.Spec.Rules).To(HaveLen(1))
+			Expect(ing.Spec.Rules[0].Host).To(Equal(host))
+			Expect(ing.Spec.Rules[0].HTTP.Paths).To(HaveLen(1))
+			Expect(ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service).To(Equal(&netv1.IngressServiceBackend{
+				Name: render.ManagerServiceName,
+				Port: netv1.ServiceBackendPort{Number: 9443},
+			}))
+			Expect(rtest.GetResource(toDelete, render.ManagerIngressName, render.ManagerNamespace, "networking.k8s.io", "v1", "Ingress")).To(BeNil())
+		})
+
+		It("should render an HTTPRoute with a BackendTLSPolicy", func() {
+			toCreate, toDelete := renderManagerComponent(renderConfig{installation: installation, exposure: &operatorv1.ManagerExposure{
+				Type:     operatorv1.ManagerExposureGateway,
+				Hostname: host,
+				Gateway: &operatorv1.ManagerGateway{
+					ParentRefs: []operatorv1.ManagerGatewayParentRef{{Name: "public", Namespace: "gateways", SectionName: "https"}},
+				},
+			}}).Objects()
+
+			route, ok := rtest.GetResource(toCreate, render.ManagerRouteName, render.ManagerNamespace, "gateway.networking.k8s.io", "v1beta1", "HTTPRoute").(*unstructured.Unstructured)
+			Expect(ok).To(BeTrue())
+			Expect(route.Object["spec"]).To(Equal(map[string]interface{}{
+				"parentRefs": []interface{}{map[string]interface{}{"name": "public", "namespace": "gateways", "sectionName": "https"}},
+				"hostnames":  []interface{}{host},
+				"rules": []interface{}{map[string]interface{}{
+					"backendRefs": []interface{}{map[string]interface{}{"name": render.ManagerServiceName, "port": int64(9443)}},
+				}},
+			}))
+
+			policy, ok := rtest.GetResource(toCreate, render.ManagerRouteName, render.ManagerNamespace, "gateway.networking.k8s.io", "v1alpha3", "BackendTLSPolicy").(*unstructured.Unstructured)
+			Expect(ok).To(BeTrue())
+			hostname, _, _ := unstructured.NestedString(policy.Object, "spec", "validation", "hostname")
+			Expect(hostname).To(Equal("tigera-manager.tigera-manager.svc"))
+
+			ca, ok := rtest.GetResource(toCreate, render.ManagerBackendCAConfigMapName, render.ManagerNamespace, "", "v1", "ConfigMap").(*corev1.ConfigMap)
+			Expect(ok).To(BeTrue())
+			Expect(ca.Data).To(HaveKey("ca.crt"))
+
+			Expect(rtest.GetResource(toDelete, render.ManagerIngressName, render.ManagerNamespace, "networking.k8s.io", "v1", "Ingress")).NotTo(BeNil())
+			Expect(rtest.GetResource(toDelete, render.ManagerRouteName, render.ManagerNamespace, "gateway.networking.k8s.io", "v1alpha2", "TLSRoute")).NotTo(BeNil())
+			Expect(rtest.GetResource(toDelete, render.ManagerRouteName, render.ManagerNamespace, "gateway.networking.k8s.io", "v1beta1", "HTTPRoute")).To(BeNil())
+		})
+
+		It("should render a TLSRoute", func() {
+			toCreate, toDelete := renderManagerComponent(renderConfig{installation: installation, exposure: &operatorv1.ManagerExposure{
+				Type:     operatorv1.ManagerExposureGateway,
+				Hostname: host,
+				Gateway: &operatorv1.ManagerGateway{
+					RouteType:  operatorv1.ManagerGatewayTLSRoute,
+					ParentRefs: []operatorv1.ManagerGatewayParentRef{{Name: "public"}},
+				},
+			}}).Objects()
+
+			route, ok := rtest.GetResource(toCreate, render.ManagerRouteName, render.ManagerNamespace, "gateway.networking.k8s.io", "v1alpha2", "TLSRoute").(*unstructured.Unstructured)
+			Expect(ok).To(BeTrue())
+			parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+			Expect(parentRefs).To(Equal([]interface{}{map[string]interface{}{"name": "public"}}))
+			Expect(rtest.GetResource(toCreate, render.ManagerRouteName, render.ManagerNamespace, "gateway.networking.k8s.io", "v1alpha3", "BackendTLSPolicy")).To(BeNil())
+
+			Expect(rtest.GetResource(toDelete, render.ManagerRouteName, render.ManagerNamespace, "gateway.networking.k8s.io", "v1beta1", "HTTPRoute")).NotTo(BeNil())
+			Expect(rtest.GetResource(toDelete, render.ManagerRouteName, render.ManagerNamespace, "gateway.networking.k8s.io", "v1alpha3", "BackendTLSPolicy")).NotTo(BeNil())
+		})
+
+		It("should return the URL of the exposure", func() {
+			nodePort := int32(30443)
+			Expect(render.ManagerExposureURL(nil)).To(Equal(""))
+			Expect(render.ManagerExposureURL(&operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureIngress, Hostname: host})).To(Equal("https://manager.example.com"))
+			Expect(render.ManagerExposureURL(&operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureGateway, Hostname: host})).To(Equal("https://manager.example.com"))
+			Expect(render.ManagerExposureURL(&operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureLoadBalancer, Hostname: host})).To(Equal("https://manager.example.com:9443"))
+			Expect(render.ManagerExposureURL(&operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureLoadBalancer})).To(Equal(""))
+			Expect(render.ManagerExposureURL(&operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureNodePort, Hostname: host, NodePort: &nodePort})).To(Equal("https://manager.example.com:30443"))
+			Expect(render.ManagerExposureURL(&operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureNodePort, Hostname: host})).To(Equal(""))
+			Expect(render.ManagerLoadBalancerURL(corev1.LoadBalancerIngress{IP: "192.0.2.10"})).To(Equal("https://192.0.2.10:9443"))
+			Expect(render.ManagerLoadBalancerURL(corev1.LoadBalancerIngress{IP: "2001:db8::1"})).To(Equal("https://[2001:db8::1]:9443"))
+		})
+
+		DescribeTable("should validate the exposure", func(exposure *operatorv1.ManagerExposure, expectedErr string) {
+			err := render.ValidateManagerExposure(exposure)
+			if expectedErr == "" {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(expectedErr))
+			}
+		},
+			Entry("no exposure", nil, ""),
+			Entry("load balancer", &operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureLoadBalancer, LoadBalancer: &operatorv1.ManagerLoadBalancer{}}, ""),
+			Entry("ingress without hostname", &operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureIngress},
+				"hostname is required for the Ingress exposure type"),
+			Entry("node port with a load balancer", &operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureNodePort, LoadBalancer: &operatorv1.ManagerLoadBalancer{}},
+				"loadBalancer is only supported for the LoadBalancer exposure type"),
+			Entry("gateway without parent refs", &operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureGateway, Hostname: "manager.example.com", Gateway: &operatorv1.ManagerGateway{}},
+				"gateway.parentRefs is required for the Gateway exposure type"),
+			Entry("gateway config with an ingress", &operatorv1.ManagerExposure{Type: operatorv1.ManagerExposureIngress, Hostname: "manager.example.com", Gateway: &operatorv1.ManagerGateway{}},
+				"gateway is only supported for the Gateway exposure type"),
+		)
+	})
 })
 
 type renderConfig struct {
@@ -545,9 +823,17 @@ type renderConfig struct {
 	managementCluster       *operatorv1.ManagementCluster
 	installation            *operatorv1.InstallationSpec
 	complianceFeatureActive bool
+	exposure                *operatorv1.ManagerExposure
+	logLevel                operatorv1.LogLevel
+	autoscaling             *operatorv1.Autoscaling
 }
 
 func renderObjects(roc renderConfig) []client.Object {
+	resources, _ := renderManagerComponent(roc).Objects()
+	return resources
+}
+
+func renderManagerComponent(roc renderConfig) render.Component {
 	var dexCfg authentication.KeyValidatorConfig
 	if roc.oidc {
 		authentication := &operatorv1.Authentication{
@@ -592,11 +878,13 @@ func renderObjects(roc renderConfig) []client.Object {
 		ESLicenseType:           render.ElasticsearchLicenseTypeEnterpriseTrial,
 		Replicas:                roc.installation.ControlPlaneReplicas,
 		ComplianceFeatureActive: roc.complianceFeatureActive,
+		Exposure:                roc.exposure,
+		LogLevel:                roc.logLevel,
+		Autoscaling:             roc.autoscaling,
 		UsePSP:                  true,
 	}
 	component, err := render.Manager(cfg)
 	Expect(err).To(BeNil(), "Expected Manager to create successfully %s", err)
 	Expect(component.ResolveImages(nil)).To(BeNil())
-	resources, _ := component.Objects()
-	return resources
+	return component
 }
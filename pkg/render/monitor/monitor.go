@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"strings"
 
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/components"
@@ -27,6 +28,8 @@ import (
 	"github.com/tigera/operator/pkg/render/common/authentication"
 	"github.com/tigera/operator/pkg/render/common/configmap"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/secret"
 	"github.com/tigera/operator/pkg/render/logstorage/esmetrics"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
@@ -66,6 +69,10 @@ const (
 	AlertmanagerConfigSecret = "alertmanager-calico-node-alertmanager"
 
 	PrometheusServiceAccountName = "prometheus"
+
+	// DefaultFluentdBufferAlertThresholdPercent is the buffer usage above which the FluentdBufferNearlyFull alert
+	// fires when the LogCollector has no degraded threshold.
+	DefaultFluentdBufferAlertThresholdPercent = 80
 )
 
 func Monitor(cfg *Config) render.Component {
@@ -84,6 +91,14 @@ type Config struct {
 	ClientTLSSecret          certificatemanagement.KeyPairInterface
 	ClusterDomain            string
 	TrustedCertBundle        certificatemanagement.TrustedBundle
+
+	// NetworkPolicyState determines whether the policies for Prometheus and Alertmanager in the allow-tigera tier are
+	// created or removed.
+	NetworkPolicyState networkpolicy.State
+
+	// FluentdBufferThresholdPercent is the degraded threshold of the fluentd buffers of the LogCollector, which the
+	// FluentdBufferNearlyFull alert fires above. DefaultFluentdBufferAlertThresholdPercent is used if it is nil.
+	FluentdBufferThresholdPercent *int32
 }
 
 type monitorComponent struct {
@@ -128,7 +143,7 @@ func (mc *monitorComponent) SupportedOSType() rmeta.OSType {
 
 func (mc *monitorComponent) Objects() ([]client.Object, []client.Object) {
 	toCreate := []client.Object{
-		render.CreateNamespace(common.TigeraPrometheusNamespace, mc.cfg.Installation.KubernetesProvider, render.PSSRestricted),
+		render.CreateNamespace(common.TigeraPrometheusNamespace, mc.cfg.Installation, render.PSSRestricted),
 	}
 
 	// Create role and role bindings first.
@@ -171,9 +186,89 @@ func (mc *monitorComponent) Objects() ([]client.Object, []client.Object) {
 	// Remove the pod monitor that existed prior to v1.25.
 	toDelete = append(toDelete, &monitoringv1.PodMonitor{ObjectMeta: metav1.ObjectMeta{Name: FluentdMetrics, Namespace: common.TigeraPrometheusNamespace}})
 
+	policies, policiesToDelete := mc.cfg.NetworkPolicyState.Split(
+		networkpolicy.AllowTigeraDefaultDeny(common.TigeraPrometheusNamespace),
+		mc.prometheusNetworkPolicy(),
+		mc.alertmanagerNetworkPolicy(),
+		mc.prometheusOperatorNetworkPolicy(),
+	)
+	toCreate = append(toCreate, policies...)
+	toDelete = append(toDelete, policiesToDelete...)
+
 	return toCreate, toDelete
 }
 
+// prometheusNetworkPolicy allows the manager to query Prometheus, and Prometheus to scrape the metrics of calico-node,
+// fluentd and Elasticsearch and send alerts to Alertmanager.
+func (mc *monitorComponent) prometheusNetworkPolicy() *v3.NetworkPolicy {
+	egress := networkpolicy.AllowDNSRules(mc.cfg.Installation.KubernetesProvider == operatorv1.ProviderOpenShift)
+	egress = append(egress,
+		networkpolicy.AllowKubeAPIServerRule(),
+		networkpolicy.AllowTCPRule(mc.alertmanagerEntityRule(9093)),
+		// calico-node uses the host network, so its metrics are matched by the endpoints of its metrics service.
+		networkpolicy.AllowTCPRule(v3.EntityRule{
+			Services: &v3.ServiceMatch{Name: render.CalicoNodeMetricsService, Namespace: common.CalicoNamespace},
+		}),
+		networkpolicy.AllowTCPRule(v3.EntityRule{
+			NamespaceSelector: fmt.Sprintf("projectcalico.org/name == '%s'", render.LogCollectorNamespace),
+			Ports:             networkpolicy.Ports(render.FluentdMetricsPortNumber),
+		}),
+		networkpolicy.AllowTCPRule(networkpolicy.CreateEntityRule(render.ElasticsearchNamespace, esmetrics.ElasticsearchMetricsName, 9081)),
+	)
+	if mc.cfg.KeyValidatorConfig != nil {
+		egress = append(egress, networkpolicy.AllowTCPRule(networkpolicy.DexEntityRule))
+		if issuer := mc.cfg.KeyValidatorConfig.Issuer(); issuer != "" {
+			egress = append(egress, networkpolicy.AllowAddressRule(issuer))
+		}
+	}
+	return networkpolicy.AllowTigeraPolicyForSelector(CalicoNodePrometheus, common.TigeraPrometheusNamespace,
+		networkpolicy.PrometheusSourceEntityRule.Selector,
+		networkpolicy.ServiceIngressRules(mc.prometheusHTTPAPIService(), networkpolicy.ManagerSourceEntityRule),
+		egress,
+	)
+}
+
+// alertmanagerNetworkPolicy allows Prometheus to send alerts to Alertmanager, the Alertmanager replicas to form a
+// cluster, and Alertmanager to notify any receiver.
+func (mc *monitorComponent) alertmanagerNetworkPolicy() *v3.NetworkPolicy {
+	prometheus := v3.EntityRule{Selector: networkpolicy.PrometheusSourceEntityRule.Selector}
+	alertmanagers := v3.EntityRule{Selector: mc.alertmanagerEntityRule(0).Selector}
+	ingress := networkpolicy.ServiceIngressRules(mc.alertmanagerService(), prometheus)
+	ingress = append(ingress,
+		networkpolicy.AllowTCPFromRule(alertmanagers, 9094),
+		v3.Rule{
+			Action:      v3.Allow,
+			Protocol:    &networkpolicy.UDPProtocol,
+			Source:      alertmanagers,
+			Destination: v3.EntityRule{Ports: networkpolicy.Ports(9094)},
+		},
+	)
+	// Receivers can be configured anywhere, so all egress is allowed.
+	egress := []v3.Rule{{Action: v3.Allow}}
+	return networkpolicy.AllowTigeraPolicyForSelector(CalicoNodeAlertmanager, common.TigeraPrometheusNamespace,
+		alertmanagers.Selector, ingress, egress)
+}
+
+// prometheusOperatorNetworkPolicy allows the prometheus-operator, which runs in the same namespace, to reach the
+// Kubernetes API.
+func (mc *monitorComponent) prometheusOperatorNetworkPolicy() *v3.NetworkPolicy {
+	egress := networkpolicy.AllowDNSRules(mc.cfg.Installation.KubernetesProvider == operatorv1.ProviderOpenShift)
+	egress = append(egress, networkpolicy.AllowKubeAPIServerRule())
+	return networkpolicy.AllowTigeraPolicyForSelector("prometheus-operator", common.TigeraPrometheusNamespace,
+		"operator == 'prometheus'", nil, egress)
+}
+
+func (mc *monitorComponent) alertmanagerEntityRule(port uint16) v3.EntityRule {
+	rule := v3.EntityRule{
+		NamespaceSelector: fmt.Sprintf("projectcalico.org/name == '%s'", common.TigeraPrometheusNamespace),
+		Selector:          fmt.Sprintf("alertmanager == '%s'", CalicoNodeAlertmanager),
+	}
+	if port != 0 {
+		rule.Ports = networkpolicy.Ports(port)
+	}
+	return rule
+}
+
 func (mc *monitorComponent) clusterRole() client.Object {
 	rules := []rbacv1.PolicyRule{
 		{
@@ -236,10 +331,37 @@ func (mc *monitorComponent) alertmanager() *monitoringv1.Alertmanager {
 			Version:          components.ComponentCoreOSAlertmanager.Version,
 			Tolerations:      mc.cfg.Installation.ControlPlaneTolerations,
 			NodeSelector:     mc.cfg.Installation.ControlPlaneNodeSelector,
+			SecurityContext:  podSecurityContext(),
+			Containers:       securityContextPatches("alertmanager", "config-reloader"),
 		},
 	}
 }
 
+// podSecurityContext returns the pod security context of Prometheus and Alertmanager. It keeps the user and file
+// system group that the Prometheus operator uses by default, which the images expect, and adds the seccomp profile
+// required by the "restricted" pod security standard.
+func podSecurityContext() *corev1.PodSecurityContext {
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot: ptr.BoolToPtr(true),
+		RunAsUser:    ptr.Int64ToPtr(1000),
+		FSGroup:      ptr.Int64ToPtr(2000),
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+}
+
+// securityContextPatches returns containers that set the security context of the named containers that the
+// Prometheus operator generates. The Prometheus operator merges containers in the spec into the generated containers
+// with the same name.
+func securityContextPatches(names ...string) []corev1.Container {
+	var containers []corev1.Container
+	for _, name := range names {
+		containers = append(containers, corev1.Container{Name: name, SecurityContext: podsecuritycontext.NewBaseContext()})
+	}
+	return containers
+}
+
 func (mc *monitorComponent) alertmanagerService() *corev1.Service {
 	return &corev1.Service{
 		TypeMeta: metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
@@ -333,10 +455,12 @@ func (mc *monitorComponent) prometheus() *monitoringv1.Prometheus {
 			Volumes:            volumes,
 			VolumeMounts:       volumeMounts,
 			InitContainers:     initContainers,
-			Containers: []corev1.Container{
+			SecurityContext:    podSecurityContext(),
+			Containers: append([]corev1.Container{
 				{
-					Name:  "authn-proxy",
-					Image: mc.prometheusServiceImage,
+					Name:            "authn-proxy",
+					Image:           mc.prometheusServiceImage,
+					SecurityContext: podsecuritycontext.NewBaseContext(),
 					Ports: []corev1.ContainerPort{
 						{
 							ContainerPort: PrometheusProxyPort,
@@ -363,7 +487,7 @@ func (mc *monitorComponent) prometheus() *monitoringv1.Prometheus {
 						},
 					},
 				},
-			},
+			}, securityContextPatches("prometheus", "config-reloader")...),
 			// ListenLocal makes the Prometheus server listen on loopback, so that it
 			// does not bind against the Pod IP. This forces traffic to go through the authn-proxy.
 			ListenLocal:            true,
@@ -486,6 +610,10 @@ func (mc *monitorComponent) prometheusHTTPAPIService() *corev1.Service {
 }
 
 func (mc *monitorComponent) prometheusRule() *monitoringv1.PrometheusRule {
+	bufferThreshold := int32(DefaultFluentdBufferAlertThresholdPercent)
+	if mc.cfg.FluentdBufferThresholdPercent != nil {
+		bufferThreshold = *mc.cfg.FluentdBufferThresholdPercent
+	}
 	return &monitoringv1.PrometheusRule{
 		TypeMeta: metav1.TypeMeta{Kind: monitoringv1.PrometheusRuleKind, APIVersion: MonitoringAPIVersion},
 		ObjectMeta: metav1.ObjectMeta{
@@ -512,6 +640,21 @@ func (mc *monitorComponent) prometheusRule() *monitoringv1.PrometheusRule {
 						},
 					},
 				},
+				{
+					Name: "fluentd.rules",
+					Rules: []monitoringv1.Rule{
+						{
+							Alert:  "FluentdBufferNearlyFull",
+							Expr:   intstr.FromString(fmt.Sprintf("100 - fluentd_output_status_buffer_available_space_ratio > %d", bufferThreshold)),
+							For:    "5m",
+							Labels: map[string]string{"severity": "warning"},
+							Annotations: map[string]string{
+								"summary":     "Node {{$labels.node}} - Fluentd buffer nearly full",
+								"description": fmt.Sprintf("The buffer of fluentd output {{$labels.plugin_id}} on node {{$labels.node}} has been more than %d%% full for 5 minutes. Logs will be dropped or blocked when it is full.", bufferThreshold),
+							},
+						},
+					},
+				},
 			},
 		},
 	}
@@ -608,6 +751,15 @@ func (mc *monitorComponent) serviceMonitorFluentd() *monitoringv1.ServiceMonitor
 					ScrapeTimeout: "5s",
 					Scheme:        "https",
 					TLSConfig:     mc.tlsConfig(render.FluentdPrometheusTLSSecretName),
+					// The buffer metrics of fluentd are per pod. Label them with the node, so that a backlog can be
+					// traced to the node whose logs are not sent.
+					RelabelConfigs: []*monitoringv1.RelabelConfig{
+						{
+							SourceLabels: []string{"__meta_kubernetes_pod_node_name"},
+							TargetLabel:  "node",
+							Action:       "replace",
+						},
+					},
 				},
 			},
 		},
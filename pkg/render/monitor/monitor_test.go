@@ -38,6 +38,7 @@ import (
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/components"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
 	"github.com/tigera/operator/pkg/render/monitor"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
@@ -246,6 +247,11 @@ var _ = Describe("monitor rendering tests", func() {
 		Expect(servicemonitorObj.Spec.Endpoints[0].Interval).To(Equal("5s"))
 		Expect(servicemonitorObj.Spec.Endpoints[0].Port).To(Equal("fluentd-metrics-port"))
 		Expect(servicemonitorObj.Spec.Endpoints[0].ScrapeTimeout).To(Equal("5s"))
+		Expect(servicemonitorObj.Spec.Endpoints[0].RelabelConfigs).To(ConsistOf(&monitoringv1.RelabelConfig{
+			SourceLabels: []string{"__meta_kubernetes_pod_node_name"},
+			TargetLabel:  "node",
+			Action:       "replace",
+		}))
 
 		// PrometheusRule
 		prometheusruleObj, ok := rtest.GetResource(toCreate, monitor.TigeraPrometheusDPRate, common.TigeraPrometheusNamespace, "monitoring.coreos.com", "v1", monitoringv1.PrometheusRuleKind).(*monitoringv1.PrometheusRule)
@@ -253,7 +259,7 @@ var _ = Describe("monitor rendering tests", func() {
 		Expect(prometheusruleObj.ObjectMeta.Labels).To(HaveLen(2))
 		Expect(prometheusruleObj.ObjectMeta.Labels["prometheus"]).To(Equal("calico-node-prometheus"))
 		Expect(prometheusruleObj.ObjectMeta.Labels["role"]).To(Equal("tigera-prometheus-rules"))
-		Expect(prometheusruleObj.Spec.Groups).To(HaveLen(1))
+		Expect(prometheusruleObj.Spec.Groups).To(HaveLen(2))
 		Expect(prometheusruleObj.Spec.Groups[0].Name).To(Equal("calico.rules"))
 		Expect(prometheusruleObj.Spec.Groups[0].Rules).To(HaveLen(1))
 		Expect(prometheusruleObj.Spec.Groups[0].Rules[0].Alert).To(Equal("DeniedPacketsRate"))
@@ -261,6 +267,11 @@ var _ = Describe("monitor rendering tests", func() {
 		Expect(prometheusruleObj.Spec.Groups[0].Rules[0].Labels["severity"]).To(Equal("critical"))
 		Expect(prometheusruleObj.Spec.Groups[0].Rules[0].Annotations["summary"]).To(Equal("Instance {{$labels.instance}} - Large rate of packets denied"))
 		Expect(prometheusruleObj.Spec.Groups[0].Rules[0].Annotations["description"]).To(Equal("{{$labels.instance}} with calico-node pod {{$labels.pod}} has been denying packets at a fast rate {{$labels.sourceIp}} by policy {{$labels.policy}}."))
+		Expect(prometheusruleObj.Spec.Groups[1].Name).To(Equal("fluentd.rules"))
+		Expect(prometheusruleObj.Spec.Groups[1].Rules).To(HaveLen(1))
+		Expect(prometheusruleObj.Spec.Groups[1].Rules[0].Alert).To(Equal("FluentdBufferNearlyFull"))
+		Expect(prometheusruleObj.Spec.Groups[1].Rules[0].Expr).To(Equal(intstr.FromString("100 - fluentd_output_status_buffer_available_space_ratio > 80")))
+		Expect(prometheusruleObj.Spec.Groups[1].Rules[0].For).To(Equal("5m"))
 
 		// ServiceMonitor
 		servicemonitorObj, ok = rtest.GetResource(toCreate, monitor.CalicoNodeMonitor, common.TigeraPrometheusNamespace, "monitoring.coreos.com", "v1", monitoringv1.ServiceMonitorsKind).(*monitoringv1.ServiceMonitor)
@@ -330,6 +341,18 @@ var _ = Describe("monitor rendering tests", func() {
 		Expect(rolebindingObj.Subjects[0].Namespace).To(Equal(common.OperatorNamespace()))
 	})
 
+	It("Should alert on the fluentd buffers above the degraded threshold of the LogCollector", func() {
+		threshold := int32(60)
+		cfg.FluentdBufferThresholdPercent = &threshold
+		component := monitor.Monitor(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+		toCreate, _ := component.Objects()
+
+		rule := rtest.GetResource(toCreate, monitor.TigeraPrometheusDPRate, common.TigeraPrometheusNamespace, "monitoring.coreos.com", "v1", monitoringv1.PrometheusRuleKind).(*monitoringv1.PrometheusRule)
+		Expect(rule.Spec.Groups[1].Rules[0].Expr).To(Equal(intstr.FromString("100 - fluentd_output_status_buffer_available_space_ratio > 60")))
+		Expect(rule.Spec.Groups[1].Rules[0].Annotations["description"]).To(ContainSubstring("more than 60% full"))
+	})
+
 	It("Should render Prometheus resources when Dex is enabled", func() {
 		authentication := &operatorv1.Authentication{
 			Spec: operatorv1.AuthenticationSpec{
@@ -390,8 +413,14 @@ var _ = Describe("monitor rendering tests", func() {
 		Expect(ok).To(BeTrue())
 		prometheusCom := components.ComponentPrometheus
 		Expect(*prometheusObj.Spec.Image).To(Equal(fmt.Sprintf("%s%s:%s", components.TigeraRegistry, prometheusCom.Image, prometheusCom.Version)))
-		Expect(prometheusObj.Spec.Containers).To(HaveLen(1))
+		Expect(prometheusObj.Spec.Containers).To(HaveLen(3))
 		proxy := prometheusObj.Spec.Containers[0]
+		Expect(proxy.SecurityContext).To(Equal(podsecuritycontext.NewBaseContext()))
+		for _, c := range prometheusObj.Spec.Containers[1:] {
+			Expect(c.Name).To(BeElementOf("prometheus", "config-reloader"))
+			Expect(c.SecurityContext).To(Equal(podsecuritycontext.NewBaseContext()))
+		}
+		Expect(prometheusObj.Spec.SecurityContext.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeRuntimeDefault))
 		Expect(proxy.Env).To(ConsistOf([]corev1.EnvVar{
 			{
 				Name:      "PROMETHEUS_ENDPOINT_URL",
@@ -15,6 +15,9 @@
 package render
 
 import (
+	"sort"
+	"strings"
+
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	"github.com/tigera/operator/pkg/render/common/secret"
 	corev1 "k8s.io/api/core/v1"
@@ -53,11 +56,11 @@ func (c *namespaceComponent) SupportedOSType() rmeta.OSType {
 
 func (c *namespaceComponent) Objects() ([]client.Object, []client.Object) {
 	ns := []client.Object{
-		CreateNamespace(common.CalicoNamespace, c.cfg.Installation.KubernetesProvider, PSSPrivileged),
+		CreateNamespace(common.CalicoNamespace, c.cfg.Installation, PSSPrivileged),
 	}
 	if c.cfg.Installation.Variant == operatorv1.TigeraSecureEnterprise {
 		// We need to always have ns tigera-dex even when the Authentication CR is not present, so policies can be added to this namespace.
-		ns = append(ns, CreateNamespace(DexObjectName, c.cfg.Installation.KubernetesProvider, PSSRestricted))
+		ns = append(ns, CreateNamespace(DexObjectName, c.cfg.Installation, PSSRestricted))
 	}
 	if len(c.cfg.PullSecrets) > 0 {
 		ns = append(ns, secret.ToRuntimeObjects(secret.CopyToNamespace(common.CalicoNamespace, c.cfg.PullSecrets...)...)...)
@@ -80,9 +83,23 @@ const (
 	PSSPrivileged = "privileged"
 	PSSBaseline   = "baseline"
 	PSSRestricted = "restricted"
+
+	// PodSecurityStandardValidationAnnotation is set on namespaces whose enforce level has been configured on the
+	// Installation, so that the pods rendered into them are checked against that level before they are applied.
+	PodSecurityStandardValidationAnnotation = "operator.tigera.io/validate-pod-security-standard"
+
+	// PodSecurityLabelsAnnotation lists the pod security labels that the operator set on a namespace, so that the
+	// ones it no longer renders can be removed without touching labels that were added by the user.
+	PodSecurityLabelsAnnotation = "operator.tigera.io/pod-security-labels"
+
+	// PodSecurityLabelPrefix is the prefix of the namespace labels that configure pod security admission.
+	PodSecurityLabelPrefix = "pod-security.kubernetes.io/"
 )
 
-func CreateNamespace(name string, provider operatorv1.Provider, pss PodSecurityStandard) *corev1.Namespace {
+// CreateNamespace returns a namespace for operator managed components. The pss level is the one required by the
+// components that run in the namespace; it can be overridden, along with the audit and warn levels, through the
+// PodSecurityStandards of the Installation.
+func CreateNamespace(name string, installation *operatorv1.InstallationSpec, pss PodSecurityStandard) *corev1.Namespace {
 	ns := &corev1.Namespace{
 		TypeMeta: metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
 		ObjectMeta: metav1.ObjectMeta{
@@ -96,10 +113,21 @@ func CreateNamespace(name string, provider operatorv1.Provider, pss PodSecurityS
 
 	// Add in labels for configuring pod security standards.
 	// https://kubernetes.io/docs/concepts/security/pod-security-standards/
-	ns.Labels["pod-security.kubernetes.io/enforce"] = string(pss)
-	ns.Labels["pod-security.kubernetes.io/enforce-version"] = "latest"
+	enforce := &operatorv1.PodSecurityStandardMode{Level: operatorv1.PodSecurityStandardLevel(pss)}
+	var audit, warn *operatorv1.PodSecurityStandardMode
+	if cfg := namespacePodSecurityStandard(installation, name); cfg != nil {
+		if cfg.Enforce != nil {
+			enforce = cfg.Enforce
+			ns.Annotations[PodSecurityStandardValidationAnnotation] = "true"
+		}
+		audit, warn = cfg.Audit, cfg.Warn
+	}
+	setPodSecurityLabels(ns.Labels, "enforce", enforce)
+	setPodSecurityLabels(ns.Labels, "audit", audit)
+	setPodSecurityLabels(ns.Labels, "warn", warn)
+	ns.Annotations[PodSecurityLabelsAnnotation] = podSecurityLabelKeys(ns.Labels)
 
-	switch provider {
+	switch installation.KubernetesProvider {
 	case operatorv1.ProviderOpenShift:
 		ns.Labels["openshift.io/run-level"] = "0"
 		ns.Annotations["openshift.io/node-selector"] = ""
@@ -108,3 +136,35 @@ func CreateNamespace(name string, provider operatorv1.Provider, pss PodSecurityS
 	}
 	return ns
 }
+
+func namespacePodSecurityStandard(installation *operatorv1.InstallationSpec, namespace string) *operatorv1.NamespacePodSecurityStandard {
+	for i := range installation.PodSecurityStandards {
+		if installation.PodSecurityStandards[i].Namespace == namespace {
+			return &installation.PodSecurityStandards[i]
+		}
+	}
+	return nil
+}
+
+func setPodSecurityLabels(labels map[string]string, mode string, cfg *operatorv1.PodSecurityStandardMode) {
+	if cfg == nil {
+		return
+	}
+	version := cfg.Version
+	if version == "" {
+		version = "latest"
+	}
+	labels[PodSecurityLabelPrefix+mode] = string(cfg.Level)
+	labels[PodSecurityLabelPrefix+mode+"-version"] = version
+}
+
+func podSecurityLabelKeys(labels map[string]string) string {
+	var keys []string
+	for k := range labels {
+		if strings.HasPrefix(k, PodSecurityLabelPrefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
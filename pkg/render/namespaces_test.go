@@ -90,4 +90,51 @@ var _ = Describe("Namespace rendering tests", func() {
 		Expect(meta.GetLabels()["openshift.io/run-level"]).To(Equal("0"))
 		Expect(meta.GetAnnotations()["openshift.io/node-selector"]).To(Equal(""))
 	})
+
+	It("should render the default pod security standard", func() {
+		component := render.Namespaces(cfg)
+		resources, _ := component.Objects()
+		meta := resources[0].(metav1.ObjectMetaAccessor).GetObjectMeta()
+		Expect(meta.GetLabels()["pod-security.kubernetes.io/enforce"]).To(Equal("privileged"))
+		Expect(meta.GetLabels()["pod-security.kubernetes.io/enforce-version"]).To(Equal("latest"))
+		Expect(meta.GetLabels()).NotTo(HaveKey("pod-security.kubernetes.io/audit"))
+		Expect(meta.GetLabels()).NotTo(HaveKey("pod-security.kubernetes.io/warn"))
+		Expect(meta.GetAnnotations()).NotTo(HaveKey(render.PodSecurityStandardValidationAnnotation))
+	})
+
+	It("should render the pod security standards configured on the Installation", func() {
+		cfg.Installation.Variant = operatorv1.TigeraSecureEnterprise
+		cfg.Installation.PodSecurityStandards = []operatorv1.NamespacePodSecurityStandard{
+			{
+				Namespace: "calico-system",
+				Enforce:   &operatorv1.PodSecurityStandardMode{Level: operatorv1.PodSecurityStandardBaseline, Version: "v1.24"},
+				Warn:      &operatorv1.PodSecurityStandardMode{Level: operatorv1.PodSecurityStandardRestricted},
+			},
+			{
+				Namespace: "tigera-dex",
+				Audit:     &operatorv1.PodSecurityStandardMode{Level: operatorv1.PodSecurityStandardRestricted, Version: "v1.25"},
+			},
+		}
+		component := render.Namespaces(cfg)
+		resources, _ := component.Objects()
+		Expect(len(resources)).To(Equal(2))
+
+		meta := resources[0].(metav1.ObjectMetaAccessor).GetObjectMeta()
+		Expect(meta.GetLabels()["pod-security.kubernetes.io/enforce"]).To(Equal("baseline"))
+		Expect(meta.GetLabels()["pod-security.kubernetes.io/enforce-version"]).To(Equal("v1.24"))
+		Expect(meta.GetLabels()["pod-security.kubernetes.io/warn"]).To(Equal("restricted"))
+		Expect(meta.GetLabels()["pod-security.kubernetes.io/warn-version"]).To(Equal("latest"))
+		Expect(meta.GetLabels()).NotTo(HaveKey("pod-security.kubernetes.io/audit"))
+		Expect(meta.GetAnnotations()[render.PodSecurityStandardValidationAnnotation]).To(Equal("true"))
+		Expect(meta.GetAnnotations()[render.PodSecurityLabelsAnnotation]).To(Equal(
+			"pod-security.kubernetes.io/enforce,pod-security.kubernetes.io/enforce-version,pod-security.kubernetes.io/warn,pod-security.kubernetes.io/warn-version"))
+
+		// Only the audit level is configured for tigera-dex so the enforce level is left at its default.
+		meta = resources[1].(metav1.ObjectMetaAccessor).GetObjectMeta()
+		Expect(meta.GetLabels()["pod-security.kubernetes.io/enforce"]).To(Equal("restricted"))
+		Expect(meta.GetLabels()["pod-security.kubernetes.io/enforce-version"]).To(Equal("latest"))
+		Expect(meta.GetLabels()["pod-security.kubernetes.io/audit"]).To(Equal("restricted"))
+		Expect(meta.GetLabels()["pod-security.kubernetes.io/audit-version"]).To(Equal("v1.25"))
+		Expect(meta.GetAnnotations()).NotTo(HaveKey(render.PodSecurityStandardValidationAnnotation))
+	})
 })
@@ -17,6 +17,7 @@ package render
 import (
 	"fmt"
 	"net"
+	"path"
 	"sort"
 	"strconv"
 	"strings"
@@ -29,6 +30,7 @@ import (
 	"github.com/tigera/operator/pkg/ptr"
 	"github.com/tigera/operator/pkg/render/common/configmap"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/podsecuritypolicy"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	appsv1 "k8s.io/api/apps/v1"
@@ -951,14 +953,12 @@ func (c *nodeComponent) cniContainer() corev1.Container {
 	}
 
 	return corev1.Container{
-		Name:         "install-cni",
-		Image:        c.cniImage,
-		Command:      []string{"/opt/cni/bin/install"},
-		Env:          cniEnv,
-		VolumeMounts: cniVolumeMounts,
-		SecurityContext: &corev1.SecurityContext{
-			Privileged: ptr.BoolToPtr(true),
-		},
+		Name:            "install-cni",
+		Image:           c.cniImage,
+		Command:         []string{"/opt/cni/bin/install"},
+		Env:             cniEnv,
+		VolumeMounts:    cniVolumeMounts,
+		SecurityContext: podsecuritycontext.NewPrivilegedContext(),
 	}
 }
 
@@ -970,12 +970,10 @@ func (c *nodeComponent) flexVolumeContainer() corev1.Container {
 	}
 
 	return corev1.Container{
-		Name:         "flexvol-driver",
-		Image:        c.flexvolImage,
-		VolumeMounts: flexVolumeMounts,
-		SecurityContext: &corev1.SecurityContext{
-			Privileged: ptr.BoolToPtr(true),
-		},
+		Name:            "flexvol-driver",
+		Image:           c.flexvolImage,
+		VolumeMounts:    flexVolumeMounts,
+		SecurityContext: podsecuritycontext.NewPrivilegedContext(),
 	}
 }
 
@@ -1001,13 +999,11 @@ func (c *nodeComponent) bpffsInitContainer() corev1.Container {
 	}
 
 	return corev1.Container{
-		Name:         "mount-bpffs",
-		Image:        c.nodeImage,
-		VolumeMounts: mounts,
-		SecurityContext: &corev1.SecurityContext{
-			Privileged: ptr.BoolToPtr(true),
-		},
-		Command: []string{CalicoNodeObjectName, "-init"},
+		Name:            "mount-bpffs",
+		Image:           c.nodeImage,
+		VolumeMounts:    mounts,
+		SecurityContext: podsecuritycontext.NewPrivilegedContext(),
+		Command:         []string{CalicoNodeObjectName, "-init"},
 	}
 }
 
@@ -1051,7 +1047,7 @@ func (c *nodeComponent) cniEnvvars() []corev1.EnvVar {
 // nodeContainer creates the main node container.
 func (c *nodeComponent) nodeContainer() corev1.Container {
 	lp, rp := c.nodeLivenessReadinessProbes()
-	sc := &corev1.SecurityContext{Privileged: ptr.BoolToPtr(true)}
+	sc := podsecuritycontext.NewPrivilegedContext()
 	if c.runAsNonPrivileged() {
 		uid := int64(999)
 		guid := int64(0)
@@ -1437,6 +1433,23 @@ func (c *nodeComponent) nodeEnvVars() []corev1.EnvVar {
 				corev1.EnvVar{Name: "FELIX_PROMETHEUSREPORTERCAFILE", Value: c.cfg.TLS.TrustedBundle.MountPath()},
 			)
 		}
+
+		if pc := c.cfg.Installation.PacketCapture; pc != nil {
+			// The capture directory stays under /var/log/calico, which fluentd mounts for the packet capture API to
+			// read the files from.
+			if pc.StorageDirectory != "" {
+				extraNodeEnv = append(extraNodeEnv, corev1.EnvVar{Name: "FELIX_CAPTUREDIR", Value: path.Join("/var/log/calico", pc.StorageDirectory)})
+			}
+			if pc.MaxFileSizeBytes != nil {
+				extraNodeEnv = append(extraNodeEnv, corev1.EnvVar{Name: "FELIX_CAPTUREMAXSIZEBYTES", Value: fmt.Sprintf("%d", *pc.MaxFileSizeBytes)})
+			}
+			if pc.MaxFilesPerCapture != nil {
+				extraNodeEnv = append(extraNodeEnv, corev1.EnvVar{Name: "FELIX_CAPTUREMAXFILES", Value: fmt.Sprintf("%d", *pc.MaxFilesPerCapture)})
+			}
+			if pc.RotationPeriod != nil {
+				extraNodeEnv = append(extraNodeEnv, corev1.EnvVar{Name: "FELIX_CAPTUREROTATIONSECONDS", Value: fmt.Sprintf("%d", int64(pc.RotationPeriod.Seconds()))})
+			}
+		}
 		nodeEnv = append(nodeEnv, extraNodeEnv...)
 	}
 
@@ -1610,7 +1623,6 @@ func (c *nodeComponent) nodePodSecurityPolicy() *policyv1beta1.PodSecurityPolicy
 // hostPathInitContainer creates an init container that changes the permissions on hostPath volumes
 // so that they can be written to by a non-root container.
 func (c *nodeComponent) hostPathInitContainer() corev1.Container {
-	rootUID := int64(0)
 	mounts := []corev1.VolumeMount{
 		{
 			MountPath: "/var/run",
@@ -1635,11 +1647,9 @@ func (c *nodeComponent) hostPathInitContainer() corev1.Container {
 		Env: []corev1.EnvVar{
 			{Name: "NODE_USER_ID", Value: "999"},
 		},
-		VolumeMounts: mounts,
-		SecurityContext: &corev1.SecurityContext{
-			RunAsUser: &rootUID,
-		},
-		Command: []string{"sh", "-c", "calico-node -hostpath-init"},
+		VolumeMounts:    mounts,
+		SecurityContext: podsecuritycontext.NewRootContext(false),
+		Command:         []string{"sh", "-c", "calico-node -hostpath-init"},
 	}
 }
 
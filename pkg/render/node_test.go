@@ -17,6 +17,7 @@ package render_test
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -27,6 +28,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -40,6 +42,7 @@ import (
 	"github.com/tigera/operator/pkg/controller/k8sapi"
 	"github.com/tigera/operator/pkg/render"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
 	tls2 "github.com/tigera/operator/pkg/tls"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
@@ -708,6 +711,24 @@ var _ = Describe("Node rendering tests", func() {
 		verifyProbesAndLifecycle(ds, false, true)
 	})
 
+	It("should report why calico-node requires a privileged namespace", func() {
+		component := render.Node(&cfg)
+		Expect(component.ResolveImages(nil)).To(BeNil())
+		resources, _ := component.Objects()
+
+		reqs := podsecuritycontext.Report(resources)
+		Expect(reqs).To(HaveLen(1))
+		Expect(reqs[0].Kind).To(Equal("DaemonSet"))
+		Expect(reqs[0].Name).To(Equal(common.NodeDaemonSetName))
+		Expect(reqs[0].Level).To(Equal(operatorv1.PodSecurityStandardPrivileged))
+		Expect(reqs[0].Reasons).To(ContainElements(
+			"uses the host network",
+			`volume "lib-modules" is a hostPath volume`,
+			`container "calico-node" is privileged`,
+			`container "install-cni" is privileged`,
+		))
+	})
+
 	It("should render all resources with the appropriate permissions when running as non-privileged", func() {
 		expectedResources := []struct {
 			name    string
@@ -2221,6 +2242,29 @@ var _ = Describe("Node rendering tests", func() {
 		Expect(ds.Spec.Template.Annotations["prometheus.io/port"]).To(Equal("1234"))
 	})
 
+	It("should configure the storage and rotation of the capture files if PacketCapture is set", func() {
+		maxFileSize := int64(5000000)
+		maxFiles := int32(4)
+		defaultInstance.Variant = operatorv1.TigeraSecureEnterprise
+		defaultInstance.PacketCapture = &operatorv1.PacketCaptureSpec{
+			StorageDirectory:   "captures/pcap",
+			MaxFileSizeBytes:   &maxFileSize,
+			MaxFilesPerCapture: &maxFiles,
+			RotationPeriod:     &metav1.Duration{Duration: 30 * time.Minute},
+		}
+		component := render.Node(&cfg)
+		Expect(component.ResolveImages(nil)).To(BeNil())
+		resources, _ := component.Objects()
+
+		ds := rtest.GetResource(resources, "calico-node", "calico-system", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "FELIX_CAPTUREDIR", Value: "/var/log/calico/captures/pcap"},
+			corev1.EnvVar{Name: "FELIX_CAPTUREMAXSIZEBYTES", Value: "5000000"},
+			corev1.EnvVar{Name: "FELIX_CAPTUREMAXFILES", Value: "4"},
+			corev1.EnvVar{Name: "FELIX_CAPTUREROTATIONSECONDS", Value: "1800"},
+		))
+	})
+
 	It("should not render a FlexVolume container if FlexVolumePath is set to None", func() {
 		defaultInstance.FlexVolumePath = "None"
 		component := render.Node(&cfg)
@@ -22,12 +22,16 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/ptr"
 	"github.com/tigera/operator/pkg/render/common/authentication"
+	"github.com/tigera/operator/pkg/render/common/autoscaling"
 	"github.com/tigera/operator/pkg/render/common/configmap"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/secret"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
@@ -56,6 +60,11 @@ type PacketCaptureApiConfiguration struct {
 	ServerCertSecret   certificatemanagement.KeyPairInterface
 	TrustedBundle      certificatemanagement.TrustedBundle
 	ClusterDomain      string
+	// Autoscaling, if set, scales the replicas of the packet capture API with a HorizontalPodAutoscaler.
+	Autoscaling *operatorv1.Autoscaling
+	// NetworkPolicyState determines whether the policies for the packet capture API in the allow-tigera tier are
+	// created or removed.
+	NetworkPolicyState networkpolicy.State
 }
 
 type packetCaptureApiComponent struct {
@@ -88,17 +97,20 @@ func (pc *packetCaptureApiComponent) SupportedOSType() rmeta.OSType {
 
 func (pc *packetCaptureApiComponent) Objects() ([]client.Object, []client.Object) {
 	objs := []client.Object{
-		CreateNamespace(PacketCaptureNamespace, pc.cfg.Installation.KubernetesProvider, PSSRestricted),
+		CreateNamespace(PacketCaptureNamespace, pc.cfg.Installation, PSSRestricted),
 	}
 	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(PacketCaptureNamespace, pc.cfg.PullSecrets...)...)...)
 
+	deployment := pc.deployment()
+	autoscalingObjs, autoscalingObjsToDelete := autoscaling.Objects(deployment, pc.cfg.Autoscaling)
 	objs = append(objs,
 		pc.serviceAccount(),
 		pc.clusterRole(),
 		pc.clusterRoleBinding(),
-		pc.deployment(),
+		deployment,
 		pc.service(),
 	)
+	objs = append(objs, autoscalingObjs...)
 
 	if pc.cfg.KeyValidatorConfig != nil {
 		objs = append(objs, secret.ToRuntimeObjects(pc.cfg.KeyValidatorConfig.RequiredSecrets(PacketCaptureNamespace)...)...)
@@ -108,7 +120,31 @@ func (pc *packetCaptureApiComponent) Objects() ([]client.Object, []client.Object
 	if pc.cfg.TrustedBundle != nil {
 		objs = append(objs, pc.cfg.TrustedBundle.ConfigMap(PacketCaptureNamespace))
 	}
-	return objs, nil
+
+	policies, policiesToDelete := pc.cfg.NetworkPolicyState.Split(
+		networkpolicy.AllowTigeraDefaultDeny(PacketCaptureNamespace),
+		pc.networkPolicy(),
+	)
+	objs = append(objs, policies...)
+
+	return objs, append(autoscalingObjsToDelete, policiesToDelete...)
+}
+
+// networkPolicy allows the manager to reach the packet capture API, and the API to reach the Kubernetes API, through
+// which it fetches the capture files from the nodes, and the identity provider that issues the tokens it validates.
+func (pc *packetCaptureApiComponent) networkPolicy() *v3.NetworkPolicy {
+	egress := networkpolicy.AllowDNSRules(pc.cfg.Openshift)
+	egress = append(egress, networkpolicy.AllowKubeAPIServerRule())
+	if pc.cfg.KeyValidatorConfig != nil {
+		egress = append(egress, networkpolicy.AllowTCPRule(networkpolicy.DexEntityRule))
+		if issuer := pc.cfg.KeyValidatorConfig.Issuer(); issuer != "" {
+			egress = append(egress, networkpolicy.AllowAddressRule(issuer))
+		}
+	}
+	return networkpolicy.AllowTigeraPolicy(PacketCaptureName, PacketCaptureNamespace,
+		networkpolicy.ServiceIngressRules(pc.service(), networkpolicy.ManagerSourceEntityRule),
+		egress,
+	)
 }
 
 func (pc *packetCaptureApiComponent) Ready() bool {
@@ -199,7 +235,7 @@ func (pc *packetCaptureApiComponent) clusterRoleBinding() client.Object {
 	}
 }
 
-func (pc *packetCaptureApiComponent) deployment() client.Object {
+func (pc *packetCaptureApiComponent) deployment() *appsv1.Deployment {
 	return &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
 		ObjectMeta: metav1.ObjectMeta{
@@ -244,7 +280,7 @@ func (pc *packetCaptureApiComponent) container() corev1.Container {
 		pc.cfg.ServerCertSecret.VolumeMount(pc.SupportedOSType()),
 	}
 	env := []corev1.EnvVar{
-		{Name: "PACKETCAPTURE_API_LOG_LEVEL", Value: "Info"},
+		{Name: "PACKETCAPTURE_API_LOG_LEVEL", Value: loglevel.Logrus(loglevel.Resolve(pc.cfg.Installation, nil), "Info")},
 		{Name: "PACKETCAPTURE_API_HTTPS_KEY", Value: pc.cfg.ServerCertSecret.VolumeMountKeyFilePath()},
 		{Name: "PACKETCAPTURE_API_HTTPS_CERT", Value: pc.cfg.ServerCertSecret.VolumeMountCertificateFilePath()},
 	}
@@ -29,6 +29,7 @@ import (
 	"github.com/tigera/operator/pkg/render"
 	"github.com/tigera/operator/pkg/render/common/authentication"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
 	"github.com/tigera/operator/pkg/tls"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
@@ -189,6 +190,12 @@ var _ = Describe("Rendering tests for PacketCapture API component", func() {
 				SecurityContext: &corev1.SecurityContext{
 					RunAsNonRoot:             ptr.BoolToPtr(true),
 					AllowPrivilegeEscalation: ptr.BoolToPtr(false),
+					Capabilities: &corev1.Capabilities{
+						Drop: []corev1.Capability{"ALL"},
+					},
+					SeccompProfile: &corev1.SeccompProfile{
+						Type: corev1.SeccompProfileTypeRuntimeDefault,
+					},
 				},
 				ReadinessProbe: &corev1.Probe{
 					Handler: corev1.Handler{
@@ -323,6 +330,12 @@ var _ = Describe("Rendering tests for PacketCapture API component", func() {
 		checkPacketCaptureResources(resources, false, false)
 	})
 
+	It("should render pods that satisfy the restricted pod security standard", func() {
+		var resources = renderPacketCapture(defaultInstallation, nil)
+
+		Expect(podsecuritycontext.Report(resources)).To(BeEmpty())
+	})
+
 	It("should render controlPlaneTolerations", func() {
 		t := corev1.Toleration{
 			Key:      "foo",
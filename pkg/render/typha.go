@@ -31,7 +31,9 @@ import (
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/controller/k8sapi"
 	"github.com/tigera/operator/pkg/controller/migration"
+	"github.com/tigera/operator/pkg/render/common/loglevel"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/podsecuritycontext"
 	"github.com/tigera/operator/pkg/render/common/podsecuritypolicy"
 )
 
@@ -438,14 +440,15 @@ func (c *typhaComponent) typhaContainer() corev1.Container {
 	lp, rp := c.livenessReadinessProbes()
 
 	return corev1.Container{
-		Name:           "calico-typha",
-		Image:          c.typhaImage,
-		Resources:      c.typhaResources(),
-		Env:            c.typhaEnvVars(),
-		VolumeMounts:   c.typhaVolumeMounts(),
-		Ports:          c.typhaPorts(),
-		LivenessProbe:  lp,
-		ReadinessProbe: rp,
+		Name:            "calico-typha",
+		Image:           c.typhaImage,
+		Resources:       c.typhaResources(),
+		Env:             c.typhaEnvVars(),
+		VolumeMounts:    c.typhaVolumeMounts(),
+		Ports:           c.typhaPorts(),
+		LivenessProbe:   lp,
+		ReadinessProbe:  rp,
+		SecurityContext: podsecuritycontext.NewBaseContext(),
 	}
 }
 
@@ -457,7 +460,7 @@ func (c *typhaComponent) typhaResources() corev1.ResourceRequirements {
 // typhaEnvVars creates the typha's envvars.
 func (c *typhaComponent) typhaEnvVars() []corev1.EnvVar {
 	typhaEnv := []corev1.EnvVar{
-		{Name: "TYPHA_LOGSEVERITYSCREEN", Value: "info"},
+		{Name: "TYPHA_LOGSEVERITYSCREEN", Value: loglevel.Logrus(loglevel.Resolve(c.cfg.Installation, nil), "info")},
 		{Name: "TYPHA_LOGFILEPATH", Value: "none"},
 		{Name: "TYPHA_LOGSEVERITYSYS", Value: "none"},
 		{Name: "TYPHA_CONNECTIONREBALANCINGMODE", Value: "kubernetes"},
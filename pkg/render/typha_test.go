@@ -16,6 +16,7 @@ package render_test
 
 import (
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -235,6 +236,23 @@ var _ = Describe("Typha rendering tests", func() {
 		Expect(passed).To(Equal(true), "Typha healthport configuration missing an expected field")
 	})
 
+	It("should set the log level of typha from the Installation", func() {
+		typhaEnv := func() []corev1.EnvVar {
+			resources, _ := render.Typha(&cfg).Objects()
+			deployment := rtest.GetResource(resources, "calico-typha", "calico-system", "apps", "v1", "Deployment").(*appsv1.Deployment)
+			return rtest.GetContainer(deployment.Spec.Template.Spec.Containers, "calico-typha").Env
+		}
+		rtest.ExpectEnv(typhaEnv(), "TYPHA_LOGSEVERITYSCREEN", "info")
+
+		warn := operatorv1.LogLevelWarn
+		installation.Logging = &operatorv1.Logging{Level: &warn}
+		rtest.ExpectEnv(typhaEnv(), "TYPHA_LOGSEVERITYSCREEN", "warning")
+
+		// Temporary debug logging takes precedence over the configured level.
+		installation.Logging.TemporaryDebug = &operatorv1.TemporaryDebugLogging{Duration: metav1.Duration{Duration: time.Hour}}
+		rtest.ExpectEnv(typhaEnv(), "TYPHA_LOGSEVERITYSCREEN", "debug")
+	})
+
 	It("should render resourcerequirements", func() {
 		rr := &corev1.ResourceRequirements{
 			Requests: corev1.ResourceList{
@@ -0,0 +1,85 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package threatfeed serves the contents of threat feeds that are kept in ConfigMaps, so that the intrusion detection
+// controller can pull them like any other threat feed.
+package threatfeed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPort is the port threat feeds are served on, to the intrusion detection controller in the same pod.
+	DefaultPort = 8089
+
+	// DefaultDir is the directory the ConfigMaps of the threat feeds are mounted in.
+	DefaultDir = "/threat-feeds"
+
+	// ContentFile is the file of the feed content in the directory of a feed.
+	ContentFile = "feed"
+)
+
+// Handler serves the content of the feed in <dir>/<name>/feed on /<name>.
+func Handler(dir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		// Only serve the feeds themselves, never anything else from the file system.
+		if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+			http.NotFound(w, r)
+			return
+		}
+		path := filepath.Join(dir, name, ContentFile)
+		f, err := os.Open(path)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil || fi.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		http.ServeContent(w, r, name, fi.ModTime(), f)
+	})
+}
+
+// Serve serves the threat feeds in dir on addr until the context is done.
+func Serve(ctx context.Context, addr, dir string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           Handler(dir),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package threatfeed_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tigera/operator/pkg/threatfeed"
+)
+
+var _ = Describe("Threat feed server", func() {
+	var srv *httptest.Server
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "threat-feeds")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(dir, "blocklist"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "blocklist", threatfeed.ContentFile), []byte("1.2.3.4\n5.6.7.8\n"), 0o644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "secret"), []byte("not a feed"), 0o644)).To(Succeed())
+		srv = httptest.NewServer(threatfeed.Handler(dir))
+	})
+
+	AfterEach(func() {
+		srv.Close()
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(srv.URL + path)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp.StatusCode, string(body)
+	}
+
+	It("should serve the content of a feed", func() {
+		code, body := get("/blocklist")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("1.2.3.4\n5.6.7.8\n"))
+	})
+
+	It("should only serve feeds", func() {
+		for _, path := range []string{"/", "/unknown", "/secret", "/blocklist/feed", "/../blocklist", "/.."} {
+			code, _ := get(path)
+			Expect(code).To(Equal(http.StatusNotFound), path)
+		}
+	})
+
+	It("should reject writes", func() {
+		resp, err := http.Post(srv.URL+"/blocklist", "text/plain", nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+	})
+})